@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/osmanmertacar/sosyal/backend/internal/api"
 	"github.com/osmanmertacar/sosyal/backend/internal/config"
 	"github.com/osmanmertacar/sosyal/backend/internal/database"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
 )
 
 func main() {
@@ -23,6 +28,9 @@ func main() {
 	log.Printf("Environment: %s", cfg.Server.Environment)
 	log.Printf("Server will listen on: %s", cfg.GetServerAddress())
 
+	// Log platform API requests and responses, with credentials redacted, at debug level
+	httpclient.SetDebug(cfg.Log.Level == "debug")
+
 	// Initialize database
 	db, err := database.New(cfg.Database.Path)
 	if err != nil {
@@ -34,26 +42,39 @@ func main() {
 		}
 	}()
 
+	// Cancelled on SIGINT/SIGTERM; interrupts posts that are being published
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Setup HTTP router
-	router := api.SetupRouter(cfg, db)
+	router, postService := api.SetupRouter(ctx, cfg, db)
 
-	// Setup graceful shutdown
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
+	// Start server
+	addr := fmt.Sprintf(":%s", cfg.Server.Port)
+	srv := &http.Server{Addr: addr, Handler: router}
 
-		log.Println("Shutting down server...")
-		os.Exit(0)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
 	}()
 
-	// Start server
-	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	log.Printf("Server started successfully on %s", addr)
 	log.Printf("Health check available at: http://%s/health", cfg.GetServerAddress())
 	log.Printf("API base URL: http://%s/api/v1", cfg.GetServerAddress())
 
-	if err := router.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Setup graceful shutdown
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	// Give in-flight requests, and then posts recording their interruption, time to finish
+	// before the database is closed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	if err := postService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down post service: %v", err)
 	}
 }
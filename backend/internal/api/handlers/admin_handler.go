@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platform"
+)
+
+// AdminHandler serves the operator endpoints
+type AdminHandler struct {
+	platformRegistry *platform.PlatformRegistry
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(platformRegistry *platform.PlatformRegistry) *AdminHandler {
+	return &AdminHandler{
+		platformRegistry: platformRegistry,
+	}
+}
+
+// GetPlatformHealth returns the circuit breaker state and recent error rate of every platform
+func (h *AdminHandler) GetPlatformHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"platforms": h.platformRegistry.Health(),
+	})
+}
+
+// ResetPlatformBreaker closes a platform's circuit breaker, releasing the posts it holds
+func (h *AdminHandler) ResetPlatformBreaker(c *gin.Context) {
+	platformType := models.Platform(c.Param("platform"))
+
+	breaker := h.platformRegistry.Breaker(platformType)
+	if breaker == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Platform not registered",
+		})
+		return
+	}
+
+	breaker.Reset()
+	log.Printf("%s circuit breaker reset by admin", platformType)
+
+	c.JSON(http.StatusOK, breaker.Health())
+}
+
+// GetHTTPMetrics returns the request counters of the clients that talk to platforms and media hosts
+func (h *AdminHandler) GetHTTPMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"clients": httpclient.Metrics(),
+	})
+}
@@ -80,7 +80,7 @@ func (h *AuthHandler) TikTokCallback(c *gin.Context) {
 	}
 
 	// Exchange code for tokens and create user session
-	jwtToken, user, err := h.authService.HandleCallback(code, state)
+	jwtToken, user, err := h.authService.HandleCallback(c.Request.Context(), code, state)
 	if err != nil {
 		log.Printf("Failed to handle callback: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
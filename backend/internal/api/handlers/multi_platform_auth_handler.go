@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,9 +13,15 @@ import (
 	"github.com/osmanmertacar/sosyal/backend/internal/api/middleware"
 	"github.com/osmanmertacar/sosyal/backend/internal/config"
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
 	"github.com/osmanmertacar/sosyal/backend/internal/services/platform"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
+// oauthCallbackTimeout is the deadline of the platform calls made while starting an OAuth flow or
+// handling its callback
+const oauthCallbackTimeout = 30 * time.Second
+
 type MultiPlatformAuthHandler struct {
 	config                 *config.Config
 	platformRegistry       *platform.PlatformRegistry
@@ -21,6 +29,8 @@ type MultiPlatformAuthHandler struct {
 	tokenRepo              *models.TokenRepository
 	platformConnectionRepo *models.PlatformConnectionRepository
 	oauthSessionRepo       *models.OAuthSessionRepository
+	facebookPageRepo       *models.FacebookPageRepository
+	quotaTracker           *services.QuotaTracker
 }
 
 // NewMultiPlatformAuthHandler creates a new multi-platform auth handler
@@ -31,6 +41,8 @@ func NewMultiPlatformAuthHandler(
 	tokenRepo *models.TokenRepository,
 	platformConnectionRepo *models.PlatformConnectionRepository,
 	oauthSessionRepo *models.OAuthSessionRepository,
+	facebookPageRepo *models.FacebookPageRepository,
+	quotaTracker *services.QuotaTracker,
 ) *MultiPlatformAuthHandler {
 	return &MultiPlatformAuthHandler{
 		config:                 cfg,
@@ -39,6 +51,8 @@ func NewMultiPlatformAuthHandler(
 		tokenRepo:              tokenRepo,
 		platformConnectionRepo: platformConnectionRepo,
 		oauthSessionRepo:       oauthSessionRepo,
+		facebookPageRepo:       facebookPageRepo,
+		quotaTracker:           quotaTracker,
 	}
 }
 
@@ -57,10 +71,50 @@ func (h *MultiPlatformAuthHandler) InstagramLogin(c *gin.Context) {
 	h.handlePlatformLogin(c, models.PlatformInstagram)
 }
 
+// ThreadsLogin initiates the Threads OAuth flow
+func (h *MultiPlatformAuthHandler) ThreadsLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformThreads)
+}
+
+// FacebookLogin initiates the Facebook Login flow for Facebook Pages
+func (h *MultiPlatformAuthHandler) FacebookLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformFacebook)
+}
+
+// YouTubeLogin initiates the Google OAuth flow for YouTube
+func (h *MultiPlatformAuthHandler) YouTubeLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformYouTube)
+}
+
+// LinkedInLogin initiates the LinkedIn 3-legged OAuth flow
+func (h *MultiPlatformAuthHandler) LinkedInLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformLinkedIn)
+}
+
+// MastodonLogin initiates the OAuth flow on the Mastodon instance given in the instance query parameter
+func (h *MultiPlatformAuthHandler) MastodonLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformMastodon)
+}
+
+// PinterestLogin initiates the Pinterest OAuth flow
+func (h *MultiPlatformAuthHandler) PinterestLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformPinterest)
+}
+
+// RedditLogin initiates the Reddit OAuth flow
+func (h *MultiPlatformAuthHandler) RedditLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformReddit)
+}
+
+// MockLogin initiates the fake OAuth flow of the mock platform
+func (h *MultiPlatformAuthHandler) MockLogin(c *gin.Context) {
+	h.handlePlatformLogin(c, models.PlatformMock)
+}
+
 // handlePlatformLogin is a generic handler for initiating OAuth flow for any platform
 func (h *MultiPlatformAuthHandler) handlePlatformLogin(c *gin.Context, platformType models.Platform) {
 	// Get platform service
-	rawService, err := h.platformRegistry.Get(platformType)
+	platformService, err := h.platformRegistry.Get(platformType)
 	if err != nil {
 		log.Printf("Platform %s not supported: %v", platformType, err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -69,16 +123,6 @@ func (h *MultiPlatformAuthHandler) handlePlatformLogin(c *gin.Context, platformT
 		return
 	}
 
-	// Type assert to platform.PlatformService
-	platformService, ok := rawService.(platform.PlatformService)
-	if !ok {
-		log.Printf("Platform %s service does not implement PlatformService", platformType)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid platform service",
-		})
-		return
-	}
-
 	// Check if user is already logged in (check for JWT token in Authorization header)
 	var loggedInUserID *int64
 	userID, err := middleware.GetUserID(c)
@@ -91,7 +135,38 @@ func (h *MultiPlatformAuthHandler) handlePlatformLogin(c *gin.Context, platformT
 	}
 
 	// Generate OAuth URL
-	authResp, err := platformService.GenerateAuthURL()
+	// Platforms whose accounts live on instances start the flow on the instance the user entered
+	var authResp platform.AuthURLResponse
+	if platformService.Capabilities().InstanceRequired {
+		authenticator, ok := platformService.(platformapi.InstanceAuthenticator)
+		if !ok {
+			log.Printf("Platform %s requires an instance but cannot start a flow on one", platformType)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to generate authorization URL",
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), oauthCallbackTimeout)
+		defer cancel()
+
+		authResp, err = authenticator.GenerateInstanceAuthURL(ctx, c.Query("instance"))
+		if errors.Is(err, platformapi.ErrInvalidInstance) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to start %s OAuth on instance %q: %v", platformType, c.Query("instance"), err)
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "Failed to connect to the instance",
+			})
+			return
+		}
+	} else {
+		authResp, err = platformService.GenerateAuthURL()
+	}
 	if err != nil {
 		log.Printf("Failed to generate %s auth URL: %v", platformType, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -107,6 +182,7 @@ func (h *MultiPlatformAuthHandler) handlePlatformLogin(c *gin.Context, platformT
 		CodeVerifier: authResp.CodeVerifier, // Empty for TikTok, populated for X
 		Platform:     platformType,
 		UserID:       loggedInUserID, // nil for new users, user_id for logged-in users
+		InstanceURL:  authResp.InstanceURL,
 		ExpiresAt:    expiresAt,
 	}
 
@@ -126,6 +202,215 @@ func (h *MultiPlatformAuthHandler) handlePlatformLogin(c *gin.Context, platformT
 	})
 }
 
+// PasswordLoginRequest is the body of a login with credentials the user entered
+type PasswordLoginRequest struct {
+	Identifier string `json:"identifier" binding:"required"` // Handle or email, the chat of a Telegram bot, or a webhook URL
+	Password   string `json:"password" binding:"required"`   // App password, not the account password, a Telegram bot token, or a webhook secret
+}
+
+// BlueskyLogin connects a Bluesky account with its handle and an app password
+func (h *MultiPlatformAuthHandler) BlueskyLogin(c *gin.Context) {
+	h.handlePasswordLogin(c, models.PlatformBluesky)
+}
+
+// TelegramLogin connects a Telegram channel or group with its ID or username and the token of a
+// bot that may post to it, to the account of the logged-in user
+func (h *MultiPlatformAuthHandler) TelegramLogin(c *gin.Context) {
+	h.handlePasswordLogin(c, models.PlatformTelegram)
+}
+
+// WebhookLogin connects an endpoint of the user's own systems with its URL and the secret
+// deliveries are signed with, to the account of the logged-in user
+func (h *MultiPlatformAuthHandler) WebhookLogin(c *gin.Context) {
+	h.handlePasswordLogin(c, models.PlatformWebhook)
+}
+
+// handlePasswordLogin connects the account of a platform that logs in with credentials instead of
+// an OAuth redirect, and returns a session token like the OAuth callbacks do
+// Destinations that are connect-only are connected to the logged-in user, without a session token
+func (h *MultiPlatformAuthHandler) handlePasswordLogin(c *gin.Context, platformType models.Platform) {
+	var req PasswordLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	platformService, err := h.platformRegistry.Get(platformType)
+	if err != nil {
+		log.Printf("Platform %s not supported: %v", platformType, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Platform %s is not configured", platformType),
+		})
+		return
+	}
+	authenticator, ok := platformService.(platformapi.PasswordAuthenticator)
+	if !ok || !platformService.Capabilities().PasswordLogin {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Platform %s doesn't support logging in with a password", platformType),
+		})
+		return
+	}
+	connectOnly := platformService.Capabilities().ConnectOnly
+	userID, _ := middleware.GetUserID(c)
+	if connectOnly && userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oauthCallbackTimeout)
+	defer cancel()
+
+	// The identifier isn't logged: webhook URLs often carry a secret token
+	tokenResp, err := authenticator.CreateSession(ctx, req.Identifier, req.Password)
+	if errors.Is(err, platformapi.ErrInvalidCredentials) {
+		c.JSON(invalidCredentialsStatus(connectOnly), gin.H{
+			"error":   invalidCredentialsMessage(platformType),
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to log in to %s: %v", platformType, err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": fmt.Sprintf("Failed to log in to %s", platformService.Capabilities().DisplayName),
+		})
+		return
+	}
+
+	// The calls of per-server platforms go to the server the account lives on
+	ctx = platformapi.WithInstance(ctx, tokenResp.InstanceURL)
+	userInfo, err := platformService.GetUserInfo(ctx, tokenResp.AccessToken)
+	if err != nil {
+		log.Printf("Failed to get %s user info: %v", platformType, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user information",
+		})
+		return
+	}
+
+	if connectOnly {
+		h.connectDestination(c, platformType, userID, userInfo, tokenResp, tokenResp.InstanceURL)
+		return
+	}
+
+	// Logged-in users connect the account to their existing account
+	jwtToken, ok := h.connectAccount(c, platformType, userID, userInfo, tokenResp, tokenResp.InstanceURL)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": jwtToken,
+	})
+}
+
+// WebhookLoginRequest is the body of a connection with an incoming webhook the user created
+type WebhookLoginRequest struct {
+	WebhookURL string `json:"webhook_url" binding:"required"`
+	Name       string `json:"name" binding:"max=100"` // Shown instead of the webhook's own name
+}
+
+// DiscordLogin connects a Discord channel with one of its incoming webhooks, to the account of the
+// logged-in user
+func (h *MultiPlatformAuthHandler) DiscordLogin(c *gin.Context) {
+	h.handleWebhookLogin(c, models.PlatformDiscord)
+}
+
+// SlackLogin connects a Slack channel with one of its incoming webhooks, to the account of the
+// logged-in user
+func (h *MultiPlatformAuthHandler) SlackLogin(c *gin.Context) {
+	h.handleWebhookLogin(c, models.PlatformSlack)
+}
+
+// handleWebhookLogin connects the channel of an incoming webhook, which is verified first, to the
+// account of the logged-in user
+// A webhook URL doesn't identify a user, so it never creates a user or signs one in
+func (h *MultiPlatformAuthHandler) handleWebhookLogin(c *gin.Context, platformType models.Platform) {
+	var req WebhookLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	platformService, err := h.platformRegistry.Get(platformType)
+	if err != nil {
+		log.Printf("Platform %s not supported: %v", platformType, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Platform %s is not configured", platformType),
+		})
+		return
+	}
+	authenticator, ok := platformService.(platformapi.WebhookAuthenticator)
+	if !ok || !platformService.Capabilities().WebhookLogin {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Platform %s doesn't support connecting a webhook", platformType),
+		})
+		return
+	}
+	userID, _ := middleware.GetUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oauthCallbackTimeout)
+	defer cancel()
+
+	// The webhook URL is a credential, so it is never logged
+	tokenResp, err := authenticator.ConnectWebhook(ctx, req.WebhookURL)
+	if errors.Is(err, platformapi.ErrInvalidCredentials) {
+		c.JSON(invalidCredentialsStatus(true), gin.H{
+			"error":   invalidCredentialsMessage(platformType),
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to connect %s webhook: %v", platformType, err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": fmt.Sprintf("Failed to connect to %s", platformService.Capabilities().DisplayName),
+		})
+		return
+	}
+
+	userInfo, err := platformService.GetUserInfo(ctx, tokenResp.AccessToken)
+	if err != nil {
+		log.Printf("Failed to get %s webhook info: %v", platformType, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user information",
+		})
+		return
+	}
+	if req.Name != "" {
+		userInfo.DisplayName = req.Name
+	}
+
+	h.connectDestination(c, platformType, userID, userInfo, tokenResp, "")
+}
+
+// invalidCredentialsStatus is the status of a response to credentials a platform rejected
+// Users connecting a destination are logged in, so it isn't 401, which would log them out
+func invalidCredentialsStatus(connectOnly bool) int {
+	if connectOnly {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusUnauthorized
+}
+
+// invalidCredentialsMessage is what users are told when a platform rejects the credentials they entered
+func invalidCredentialsMessage(platformType models.Platform) string {
+	switch platformType {
+	case models.PlatformTelegram:
+		return "Invalid chat or bot token, or the bot may not post to the chat"
+	case models.PlatformDiscord, models.PlatformSlack:
+		return "Invalid webhook URL, or the webhook was deleted"
+	case models.PlatformWebhook:
+		return "Invalid endpoint URL or secret, or the endpoint rejected the test delivery"
+	default:
+		return "Invalid handle or app password"
+	}
+}
+
 // TikTokCallback handles the OAuth callback from TikTok
 func (h *MultiPlatformAuthHandler) TikTokCallback(c *gin.Context) {
 	h.handlePlatformCallback(c, models.PlatformTikTok)
@@ -141,6 +426,46 @@ func (h *MultiPlatformAuthHandler) InstagramCallback(c *gin.Context) {
 	h.handlePlatformCallback(c, models.PlatformInstagram)
 }
 
+// ThreadsCallback handles the OAuth callback from Threads
+func (h *MultiPlatformAuthHandler) ThreadsCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformThreads)
+}
+
+// FacebookCallback handles the OAuth callback from Facebook Login
+func (h *MultiPlatformAuthHandler) FacebookCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformFacebook)
+}
+
+// YouTubeCallback handles the OAuth callback from Google for YouTube
+func (h *MultiPlatformAuthHandler) YouTubeCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformYouTube)
+}
+
+// LinkedInCallback handles the OAuth callback from LinkedIn
+func (h *MultiPlatformAuthHandler) LinkedInCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformLinkedIn)
+}
+
+// PinterestCallback handles the OAuth callback from Pinterest
+func (h *MultiPlatformAuthHandler) PinterestCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformPinterest)
+}
+
+// RedditCallback handles the OAuth callback from Reddit
+func (h *MultiPlatformAuthHandler) RedditCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformReddit)
+}
+
+// MastodonCallback handles the OAuth callback from a Mastodon instance
+func (h *MultiPlatformAuthHandler) MastodonCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformMastodon)
+}
+
+// MockCallback handles the callback of the mock platform's fake OAuth flow
+func (h *MultiPlatformAuthHandler) MockCallback(c *gin.Context) {
+	h.handlePlatformCallback(c, models.PlatformMock)
+}
+
 // handlePlatformCallback is a generic handler for OAuth callback from any platform
 func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platformType models.Platform) {
 	// Get authorization code and state from query params
@@ -178,7 +503,7 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 	}
 
 	// Get platform service
-	rawService, err := h.platformRegistry.Get(platformType)
+	platformService, err := h.platformRegistry.Get(platformType)
 	if err != nil {
 		log.Printf("Platform %s not found: %v", platformType, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -187,23 +512,19 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 		return
 	}
 
-	// Type assert to platform.PlatformService
-	platformService, ok := rawService.(platform.PlatformService)
-	if !ok {
-		log.Printf("Platform %s service does not implement PlatformService", platformType)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Invalid platform service",
-		})
-		return
-	}
-
 	// Exchange code for tokens (with PKCE code_verifier for X)
 	additionalParams := make(map[string]string)
 	if oauthSession.CodeVerifier != "" {
 		additionalParams["code_verifier"] = oauthSession.CodeVerifier
 	}
 
-	tokenResp, err := platformService.ExchangeCodeForTokens(code, additionalParams)
+	// The platform calls are bounded so a slow platform doesn't hold the browser redirect forever
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oauthCallbackTimeout)
+	defer cancel()
+	// The calls of per-instance platforms go to the instance the flow was started on
+	ctx = platformapi.WithInstance(ctx, oauthSession.InstanceURL)
+
+	tokenResp, err := platformService.ExchangeCodeForTokens(ctx, code, additionalParams)
 	if err != nil {
 		log.Printf("Failed to exchange code for %s tokens: %v", platformType, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -218,7 +539,7 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 	}
 
 	// Get user info from platform
-	userInfo, err := platformService.GetUserInfo(tokenResp.AccessToken)
+	userInfo, err := platformService.GetUserInfo(ctx, tokenResp.AccessToken)
 	if err != nil {
 		log.Printf("Failed to get %s user info: %v", platformType, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -227,15 +548,30 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 		return
 	}
 
-	// Get user ID from oauth session (stored when user initiated OAuth flow)
-	var userID int64
+	// Connect the account to the user who started the flow, if they were logged in
+	var loggedInUserID int64
 	if oauthSession.UserID != nil {
-		// User was logged in when they started OAuth - connect platform to their account
-		userID = *oauthSession.UserID
-		log.Printf("✓ Adding %s connection to existing user %d", platformType, userID)
+		loggedInUserID = *oauthSession.UserID
 	}
 
-	if userID == 0 {
+	jwtToken, ok := h.connectAccount(c, platformType, loggedInUserID, userInfo, tokenResp, oauthSession.InstanceURL)
+	if !ok {
+		return
+	}
+
+	// Redirect to frontend with token
+	frontendURL := h.config.Server.FrontendURL + "/callback?token=" + jwtToken
+	c.Redirect(http.StatusTemporaryRedirect, frontendURL)
+}
+
+// connectAccount stores the platform account of userInfo and its tokens, creating a user for it
+// unless userID is the logged-in user to connect it to, and returns a session JWT of the user
+// Writes the error response and returns false if anything fails
+func (h *MultiPlatformAuthHandler) connectAccount(c *gin.Context, platformType models.Platform, userID int64, userInfo *platform.UserInfo, tokenResp *platform.TokenResponse, instanceURL string) (string, bool) {
+	if userID != 0 {
+		// The user was logged in when they connected the account
+		log.Printf("✓ Adding %s connection to existing user %d", platformType, userID)
+	} else {
 		log.Printf("No existing session, checking if %s user %s already exists in database...", platformType, userInfo.PlatformUserID)
 		// Check if this platform user already exists
 		existingUser, err := h.userRepo.GetByPlatformUserID(platformType, userInfo.PlatformUserID)
@@ -261,13 +597,55 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": "Failed to create user account",
 				})
-				return
+				return "", false
 			}
 			userID = user.ID
 			log.Printf("Created new user %d for %s", userID, platformType)
 		}
 	}
 
+	if !h.saveConnection(c, platformType, userID, userInfo, tokenResp, instanceURL) {
+		return "", false
+	}
+
+	// Generate JWT session token for frontend
+	user, _ := h.userRepo.GetByID(userID)
+	jwtToken, err := h.createJWTSession(user)
+	if err != nil {
+		log.Printf("Failed to create JWT session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create session",
+		})
+		return "", false
+	}
+
+	log.Printf("%s authentication successful for user %d", platformType, userID)
+	return jwtToken, true
+}
+
+// connectDestination connects a destination that doesn't identify a user, like a Telegram channel
+// or a channel webhook, to the logged-in user userID and writes the response
+// Unlike connectAccount it never creates a user or a session
+func (h *MultiPlatformAuthHandler) connectDestination(c *gin.Context, platformType models.Platform, userID int64, userInfo *platform.UserInfo, tokenResp *platform.TokenResponse, instanceURL string) {
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	if !h.saveConnection(c, platformType, userID, userInfo, tokenResp, instanceURL) {
+		return
+	}
+
+	log.Printf("✓ Connected %s destination to user %d", platformType, userID)
+	c.JSON(http.StatusOK, gin.H{
+		"platform":     platformType,
+		"username":     userInfo.Username,
+		"display_name": userInfo.DisplayName,
+	})
+}
+
+// saveConnection stores the platform connection of userID and its tokens
+// Writes the error response and returns false if anything fails
+func (h *MultiPlatformAuthHandler) saveConnection(c *gin.Context, platformType models.Platform, userID int64, userInfo *platform.UserInfo, tokenResp *platform.TokenResponse, instanceURL string) bool {
 	// Create or update platform connection
 	platformConnection := &models.PlatformConnection{
 		UserID:         userID,
@@ -284,7 +662,7 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to save platform connection",
 		})
-		return
+		return false
 	}
 
 	// Save tokens to database
@@ -295,6 +673,7 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 		AccessToken:  tokenResp.AccessToken,
 		RefreshToken: tokenResp.RefreshToken,
 		ExpiresAt:    expiresAt,
+		InstanceURL:  instanceURL,
 	}
 
 	if err := h.tokenRepo.CreateOrUpdateForPlatform(token); err != nil {
@@ -302,25 +681,10 @@ func (h *MultiPlatformAuthHandler) handlePlatformCallback(c *gin.Context, platfo
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to save authentication tokens",
 		})
-		return
+		return false
 	}
 
-	// Generate JWT session token for frontend
-	user, _ := h.userRepo.GetByID(userID)
-	jwtToken, err := h.createJWTSession(user)
-	if err != nil {
-		log.Printf("Failed to create JWT session: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create session",
-		})
-		return
-	}
-
-	log.Printf("%s authentication successful for user %d", platformType, userID)
-
-	// Redirect to frontend with token
-	frontendURL := h.config.Server.FrontendURL + "/callback?token=" + jwtToken
-	c.Redirect(http.StatusTemporaryRedirect, frontendURL)
+	return true
 }
 
 // GetConnectedPlatforms returns all platforms connected by the current user
@@ -347,6 +711,12 @@ func (h *MultiPlatformAuthHandler) GetConnectedPlatforms(c *gin.Context) {
 	// Format response
 	platformsData := make([]gin.H, 0, len(connections))
 	for _, conn := range connections {
+		// Remaining publishing budget, null if nothing is known about the platform's limits
+		quota, err := h.quotaTracker.Quota(userID, conn.Platform)
+		if err != nil {
+			log.Printf("Failed to get %s quota for user %d: %v", conn.Platform, userID, err)
+		}
+
 		platformsData = append(platformsData, gin.H{
 			"platform":     conn.Platform,
 			"username":     conn.Username,
@@ -355,6 +725,7 @@ func (h *MultiPlatformAuthHandler) GetConnectedPlatforms(c *gin.Context) {
 			"is_active":    conn.IsActive,
 			"connected_at": conn.ConnectedAt,
 			"last_used_at": conn.LastUsedAt,
+			"quota":        quota,
 		})
 	}
 
@@ -400,6 +771,13 @@ func (h *MultiPlatformAuthHandler) DisconnectPlatform(c *gin.Context) {
 		log.Printf("Failed to delete tokens: %v", err)
 	}
 
+	// Facebook page tokens don't expire, so they go with the user token they were obtained with
+	if platformType == models.PlatformFacebook {
+		if err := h.facebookPageRepo.DeleteByUserID(userID); err != nil {
+			log.Printf("Failed to delete Facebook pages: %v", err)
+		}
+	}
+
 	log.Printf("User %d disconnected from %s", userID, platformType)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/osmanmertacar/sosyal/backend/internal/api/middleware"
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
 	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 type MultiPlatformPostHandler struct {
@@ -28,26 +30,23 @@ func NewMultiPlatformPostHandler(
 	}
 }
 
-// TikTokSettings represents TikTok-specific post settings (required by TikTok UX Guidelines)
-type TikTokSettings struct {
-	Title          string `json:"title,omitempty"`         // Video title
-	PrivacyLevel   string `json:"privacy_level,omitempty"` // PUBLIC_TO_EVERYONE, MUTUAL_FOLLOW_FRIENDS, FOLLOWER_OF_CREATOR, SELF_ONLY
-	AllowComment   bool   `json:"allow_comment"`           // Allow comments (default: false)
-	AllowDuet      bool   `json:"allow_duet"`              // Allow duet (default: false)
-	AllowStitch    bool   `json:"allow_stitch"`            // Allow stitch (default: false)
-	IsBrandContent bool   `json:"is_brand_content"`        // Promoting own brand
-	IsBrandOrganic bool   `json:"is_brand_organic"`        // Paid partnership
-	AutoAddMusic   bool   `json:"auto_add_music"`          // Auto-add music (for TikTok photo posts)
-	DirectPost     bool   `json:"direct_post"`             // Direct Post (true) vs Send to Inbox (false)
-}
-
 // CreatePostRequest represents the request to create a post on multiple platforms
 type CreateMultiPlatformPostRequest struct {
-	Platforms      []string        `json:"platforms" binding:"required"` // ["tiktok", "x"]
-	MediaURL       string          `json:"media_url"`                    // Primary video/image URL (for single media)
-	MediaURLs      []string        `json:"media_urls"`                   // Multiple media URLs (for carousel/multi-image)
-	Caption        string          `json:"caption"`                      // Post text/caption
-	TikTokSettings *TikTokSettings `json:"tiktok_settings,omitempty"`    // TikTok-specific settings
+	Platforms []string `json:"platforms" binding:"required"` // ["tiktok", "x"]
+	MediaURL  string   `json:"media_url"`                    // Primary video/image URL (for single media)
+	MediaURLs []string `json:"media_urls"`                   // Multiple media URLs (for carousel/multi-image)
+	Caption   string   `json:"caption"`                      // Post text/caption
+
+	// Settings holds platform-specific settings, e.g. {"tiktok": {"privacy_level": "SELF_ONLY"}}
+	// The accepted fields of each platform are listed in GET /api/v1/platforms
+	Settings map[string]platformapi.Settings `json:"settings,omitempty"`
+
+	// TikTokSettings is the old name of settings.tiktok, still accepted for older clients
+	TikTokSettings platformapi.Settings `json:"tiktok_settings,omitempty"`
+
+	// PlatformMedia selects different media per platform, e.g. {"tiktok": [...], "x": [...]}
+	// Platforms without an entry use media_url/media_urls
+	PlatformMedia map[string][]string `json:"platform_media,omitempty"`
 }
 
 // CreatePost creates a new post on one or more platforms
@@ -59,23 +58,112 @@ func (h *MultiPlatformPostHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
+	serviceReq, ok := h.bindPostRequest(c)
+	if !ok {
+		return
+	}
+
+	// Create posts
+	resp, err := h.postService.CreateMultiPlatformPost(c.Request.Context(), userID, serviceReq)
+	if err != nil {
+		var mediaErr *services.MediaValidationError
+		if errors.As(err, &mediaErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "violations": mediaErr.Violations})
+			return
+		}
+		var settingsErr *services.SettingsValidationError
+		if errors.As(err, &settingsErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "settings_errors": settingsErr.Errors})
+			return
+		}
+		log.Printf("Failed to create posts for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Format response
+	postList := make([]gin.H, 0, len(resp.Posts))
+	for _, post := range resp.Posts {
+		postData := gin.H{
+			"id":             post.ID,
+			"publication_id": post.PublicationID,
+			"platform":       post.Platform,
+			"media_url":      post.VideoURL,
+			"caption":        post.Caption,
+			"status":         post.Status,
+			"created_at":     post.CreatedAt,
+		}
+		postList = append(postList, postData)
+	}
+
+	log.Printf("Created %d posts for user %d across %d platforms", len(resp.Posts), userID, len(serviceReq.Platforms))
+
+	response := gin.H{
+		"publication_id": resp.PublicationID,
+		"posts":          postList,
+		"message":        "Posts created and are being processed",
+	}
+
+	if len(resp.Errors) > 0 {
+		response["errors"] = resp.Errors
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ValidatePost probes the media of a post request and checks it against each platform's limits
+// Accepts the same body as CreatePost but does not create any posts
+func (h *MultiPlatformPostHandler) ValidatePost(c *gin.Context) {
+	// Get user ID from context
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	serviceReq, ok := h.bindPostRequest(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.postService.ValidateMultiPlatformPost(c.Request.Context(), userID, serviceReq)
+	if err != nil {
+		var settingsErr *services.SettingsValidationError
+		if errors.As(err, &settingsErr) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "settings_errors": settingsErr.Errors})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// bindPostRequest parses and validates a post request body
+// Writes an error response and returns false if the request is invalid
+func (h *MultiPlatformPostHandler) bindPostRequest(c *gin.Context) (services.CreateMultiPlatformPostRequest, bool) {
 	// Parse request body
 	var req CreateMultiPlatformPostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
-		return
+		return services.CreateMultiPlatformPostRequest{}, false
 	}
 
 	// Validate request
 	if len(req.Platforms) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one platform must be specified"})
-		return
+		return services.CreateMultiPlatformPostRequest{}, false
 	}
 
-	// Check that at least one media URL is provided
+	// Check that every platform that requires media has it, either its own or the shared media
 	if req.MediaURL == "" && len(req.MediaURLs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "media_url or media_urls is required"})
-		return
+		for _, p := range req.Platforms {
+			if len(req.PlatformMedia[p]) == 0 && h.postService.RequiresMedia(models.Platform(p)) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("media_url or media_urls is required when platform_media has no entry for %s", p)})
+				return services.CreateMultiPlatformPostRequest{}, false
+			}
+		}
 	}
 
 	// If media_url is provided but media_urls is empty, use media_url as the single item
@@ -87,7 +175,21 @@ func (h *MultiPlatformPostHandler) CreatePost(c *gin.Context) {
 	for i, mediaURL := range req.MediaURLs {
 		if err := services.ValidateMediaURL(mediaURL); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid media_url at index %d: %s", i, err.Error())})
-			return
+			return services.CreateMultiPlatformPostRequest{}, false
+		}
+	}
+
+	var platformMedia map[models.Platform][]string
+	if len(req.PlatformMedia) > 0 {
+		platformMedia = make(map[models.Platform][]string, len(req.PlatformMedia))
+		for p, mediaURLs := range req.PlatformMedia {
+			for i, mediaURL := range mediaURLs {
+				if err := services.ValidateMediaURL(mediaURL); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid platform_media.%s at index %d: %s", p, i, err.Error())})
+					return services.CreateMultiPlatformPostRequest{}, false
+				}
+			}
+			platformMedia[models.Platform(p)] = mediaURLs
 		}
 	}
 
@@ -99,61 +201,28 @@ func (h *MultiPlatformPostHandler) CreatePost(c *gin.Context) {
 
 	// Create post service request
 	serviceReq := services.CreateMultiPlatformPostRequest{
-		Platforms: platforms,
-		MediaURL:  req.MediaURL,
-		MediaURLs: req.MediaURLs,
-		Caption:   req.Caption,
-	}
-
-	// Add TikTok settings if provided
-	if req.TikTokSettings != nil {
-		serviceReq.TikTokSettings = &services.TikTokSettings{
-			Title:          req.TikTokSettings.Title,
-			PrivacyLevel:   req.TikTokSettings.PrivacyLevel,
-			AllowComment:   req.TikTokSettings.AllowComment,
-			AllowDuet:      req.TikTokSettings.AllowDuet,
-			AllowStitch:    req.TikTokSettings.AllowStitch,
-			IsBrandContent: req.TikTokSettings.IsBrandContent,
-			IsBrandOrganic: req.TikTokSettings.IsBrandOrganic,
-			AutoAddMusic:   req.TikTokSettings.AutoAddMusic,
-			DirectPost:     req.TikTokSettings.DirectPost,
-		}
-	}
-
-	// Create posts
-	resp, err := h.postService.CreateMultiPlatformPost(userID, serviceReq)
-	if err != nil {
-		log.Printf("Failed to create posts for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Format response
-	postList := make([]gin.H, 0, len(resp.Posts))
-	for _, post := range resp.Posts {
-		postData := gin.H{
-			"id":         post.ID,
-			"platform":   post.Platform,
-			"media_url":  post.VideoURL,
-			"caption":    post.Caption,
-			"status":     post.Status,
-			"created_at": post.CreatedAt,
+		Platforms:     platforms,
+		MediaURL:      req.MediaURL,
+		MediaURLs:     req.MediaURLs,
+		Caption:       req.Caption,
+		PlatformMedia: platformMedia,
+	}
+
+	// Settings are validated by each platform in the service
+	serviceReq.Settings = make(map[models.Platform]platformapi.Settings, len(req.Settings))
+	for p, settings := range req.Settings {
+		serviceReq.Settings[models.Platform(p)] = settings
+	}
+	// Older clients send tiktok_settings, and sent it even when TikTok wasn't selected
+	if _, ok := serviceReq.Settings[models.PlatformTikTok]; !ok && req.TikTokSettings != nil {
+		for _, p := range platforms {
+			if p == models.PlatformTikTok {
+				serviceReq.Settings[models.PlatformTikTok] = req.TikTokSettings
+			}
 		}
-		postList = append(postList, postData)
-	}
-
-	log.Printf("Created %d posts for user %d across %d platforms", len(resp.Posts), userID, len(req.Platforms))
-
-	response := gin.H{
-		"posts":   postList,
-		"message": "Posts created and are being processed",
-	}
-
-	if len(resp.Errors) > 0 {
-		response["errors"] = resp.Errors
 	}
 
-	c.JSON(http.StatusCreated, response)
+	return serviceReq, true
 }
 
 // GetPosts retrieves all posts for the authenticated user
@@ -186,8 +255,13 @@ func (h *MultiPlatformPostHandler) GetPosts(c *gin.Context) {
 		platformFilter = models.Platform(platform)
 	}
 
-	// Get posts
-	posts, err := h.postService.GetUserPosts(userID, platformFilter, limit, offset)
+	// Get posts, either the posts of one publication or a page of all posts
+	var posts []*models.Post
+	if publicationID := c.Query("publication_id"); publicationID != "" {
+		posts, err = h.postService.GetPublicationPosts(userID, publicationID)
+	} else {
+		posts, err = h.postService.GetUserPosts(userID, platformFilter, limit, offset)
+	}
 	if err != nil {
 		log.Printf("Failed to get posts for user %d: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve posts"})
@@ -220,36 +294,15 @@ func (h *MultiPlatformPostHandler) GetPosts(c *gin.Context) {
 			"created_at":  post.CreatedAt,
 		}
 
-		// Add platform-specific post ID and URL
+		if post.PublicationID != "" {
+			postData["publication_id"] = post.PublicationID
+		}
+
 		if post.PlatformPostID != "" {
 			postData["platform_post_id"] = post.PlatformPostID
-
-			// Generate platform-specific URLs
-			switch post.Platform {
-			case models.PlatformTikTok:
-				if post.Status == models.PostStatusPublished {
-					username := usernameByPlatform[post.Platform]
-					if username == "" {
-						break
-					}
-					mediaPathMap := map[string]string{
-						"video":    "video",
-						"image":    "photo",
-						"carousel": "photo",
-					}
-					path := mediaPathMap[post.MediaType]
-					if path == "" {
-						path = "video"
-					}
-					postData["share_url"] = "https://www.tiktok.com/@" + username + "/" + path + "/" + post.PlatformPostID
-				}
-			case models.PlatformX:
-				postData["share_url"] = "https://twitter.com/i/web/status/" + post.PlatformPostID
-			case models.PlatformInstagram:
-				// Instagram post ID is the actual media ID, no URL construction needed
-				// The ShareURL from the API response should be stored separately if needed
-				postData["share_url"] = "https://www.instagram.com/p/" + post.PlatformPostID
-			}
+		}
+		if shareURL := postShareURL(post, usernameByPlatform[post.Platform]); shareURL != "" {
+			postData["share_url"] = shareURL
 		}
 
 		// Legacy TikTok field for backward compatibility
@@ -262,7 +315,9 @@ func (h *MultiPlatformPostHandler) GetPosts(c *gin.Context) {
 		}
 
 		if post.ErrorMessage != "" {
+			postData["error_code"] = post.ErrorCode
 			postData["error_message"] = post.ErrorMessage
+			postData["error_detail"] = post.ErrorDetail
 		}
 
 		postList = append(postList, postData)
@@ -311,32 +366,21 @@ func (h *MultiPlatformPostHandler) GetPost(c *gin.Context) {
 		"created_at": post.CreatedAt,
 	}
 
+	if post.PublicationID != "" {
+		postData["publication_id"] = post.PublicationID
+	}
+
+	// Per-platform media, including processed variants
+	if len(post.MediaItems) > 0 {
+		postData["media_items"] = post.MediaItems
+	}
+
 	if post.PlatformPostID != "" {
 		postData["platform_post_id"] = post.PlatformPostID
+	}
 
-		// Generate platform-specific URLs
-		switch post.Platform {
-		case models.PlatformTikTok:
-			if post.Status == models.PostStatusPublished {
-				conn, err := h.platformConnectionRepo.GetByUserIDAndPlatform(userID, models.PlatformTikTok)
-				if err == nil && conn != nil && conn.Username != "" {
-					mediaPathMap := map[string]string{
-						"video":    "video",
-						"image":    "photo",
-						"carousel": "photo",
-					}
-					path := mediaPathMap[post.MediaType]
-					if path == "" {
-						path = "video"
-					}
-					postData["share_url"] = "https://www.tiktok.com/@" + conn.Username + "/" + path + "/" + post.PlatformPostID
-				}
-			}
-		case models.PlatformX:
-			postData["share_url"] = "https://twitter.com/i/web/status/" + post.PlatformPostID
-		case models.PlatformInstagram:
-			postData["share_url"] = "https://www.instagram.com/p/" + post.PlatformPostID
-		}
+	if shareURL := postShareURL(post, h.shareUsername(userID, post)); shareURL != "" {
+		postData["share_url"] = shareURL
 	}
 
 	if post.PublishedAt != nil {
@@ -344,7 +388,9 @@ func (h *MultiPlatformPostHandler) GetPost(c *gin.Context) {
 	}
 
 	if post.ErrorMessage != "" {
+		postData["error_code"] = post.ErrorCode
 		postData["error_message"] = post.ErrorMessage
+		postData["error_detail"] = post.ErrorDetail
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -384,21 +430,22 @@ func (h *MultiPlatformPostHandler) GetPostStatus(c *gin.Context) {
 	}
 
 	if post.ErrorMessage != "" {
+		response["error_code"] = post.ErrorCode
 		response["error_message"] = post.ErrorMessage
+		response["error_detail"] = post.ErrorDetail
+	}
+
+	// Upload/processing progress while the platform is still working on the post
+	if post.Status == models.PostStatusProcessing && post.ProgressStage != "" {
+		response["progress_stage"] = post.ProgressStage
+		response["progress_percent"] = post.ProgressPercent
 	}
 
 	if post.PlatformPostID != "" {
 		response["platform_post_id"] = post.PlatformPostID
-
-		// Generate platform-specific URLs
-		switch post.Platform {
-		case models.PlatformTikTok:
-			response["share_url"] = "https://www.tiktok.com/@user/video/" + post.PlatformPostID
-		case models.PlatformX:
-			response["share_url"] = "https://twitter.com/i/web/status/" + post.PlatformPostID
-		case models.PlatformInstagram:
-			response["share_url"] = "https://www.instagram.com/p/" + post.PlatformPostID
-		}
+	}
+	if shareURL := postShareURL(post, h.shareUsername(userID, post)); shareURL != "" {
+		response["share_url"] = shareURL
 	}
 
 	if post.PublishedAt != nil {
@@ -407,3 +454,156 @@ func (h *MultiPlatformPostHandler) GetPostStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// CancelPost stops a post that is still being prepared or uploaded
+// Returns 409 if the post already finished or was handed to the platform
+func (h *MultiPlatformPostHandler) CancelPost(c *gin.Context) {
+	// Get user ID from context
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	// Get post ID from URL
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if _, err := h.postService.GetPostByID(postID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	post, err := h.postService.CancelPost(postID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotCancellable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to cancel post %d: %v", postID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel post"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":            post.ID,
+		"platform":      post.Platform,
+		"status":        post.Status,
+		"error_message": post.ErrorMessage,
+	})
+}
+
+// DeletePost deletes a published post from its platform
+func (h *MultiPlatformPostHandler) DeletePost(c *gin.Context) {
+	// Get user ID from context
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	// Get post ID from URL
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := h.postService.GetPostByID(postID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	deleted, err := h.postService.DeletePost(c.Request.Context(), postID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrPostNotDeletable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Failed to delete post %d: %v", postID, err)
+		platformErr := platformapi.ClassifyError(post.Platform, err)
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":      platformErr.UserMessage(),
+			"error_code": platformErr.Code,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":       deleted.ID,
+		"platform": deleted.Platform,
+		"status":   deleted.Status,
+	})
+}
+
+// shareUsername returns the account username that links to post are built with
+// Only TikTok and Mastodon links need it, and only when the platform returned no link itself
+func (h *MultiPlatformPostHandler) shareUsername(userID int64, post *models.Post) string {
+	if (post.Platform != models.PlatformTikTok && post.Platform != models.PlatformMastodon) || post.ShareURL != "" {
+		return ""
+	}
+	conn, err := h.platformConnectionRepo.GetByUserIDAndPlatform(userID, post.Platform)
+	if err != nil || conn == nil {
+		return ""
+	}
+	return conn.Username
+}
+
+// postShareURL returns the link to a published post: the one the platform returned when the post
+// was published or, for posts published before links were stored, one built from the post's ID
+// username is the username of the account the post was published with, which TikTok and Mastodon
+// links need
+// Telegram, Discord and Slack messages and webhook entries can't be linked without the stored link
+func postShareURL(post *models.Post, username string) string {
+	if post.ShareURL != "" {
+		return post.ShareURL
+	}
+	if post.PlatformPostID == "" {
+		return ""
+	}
+
+	switch post.Platform {
+	case models.PlatformTikTok:
+		if post.Status != models.PostStatusPublished || username == "" {
+			return ""
+		}
+		path := "video"
+		if post.MediaType == "image" || post.MediaType == "carousel" {
+			path = "photo"
+		}
+		return "https://www.tiktok.com/@" + username + "/" + path + "/" + post.PlatformPostID
+	case models.PlatformX:
+		return "https://twitter.com/i/web/status/" + post.PlatformPostID
+	case models.PlatformInstagram:
+		return "https://www.instagram.com/p/" + post.PlatformPostID
+	case models.PlatformLinkedIn:
+		// LinkedIn post IDs are share or ugcPost URNs
+		return "https://www.linkedin.com/feed/update/" + post.PlatformPostID
+	case models.PlatformMastodon:
+		// Mastodon statuses live on the account's instance, which is part of the username
+		return services.MastodonStatusURL(username, post.PlatformPostID)
+	case models.PlatformBluesky:
+		// Bluesky post IDs are at:// URIs, which name the author's DID
+		return services.BlueskyPostURL(post.PlatformPostID)
+	case models.PlatformYouTube:
+		return services.YouTubeShortURL(post.PlatformPostID)
+	case models.PlatformFacebook:
+		// Facebook post IDs are the page's ID and the post's or video's ID
+		if post.MediaType == "video" {
+			if pageID, videoID, err := services.SplitFacebookPostID(post.PlatformPostID); err == nil {
+				return services.FacebookVideoURL(pageID, videoID)
+			}
+		}
+		return services.FacebookPostURL(post.PlatformPostID)
+	case models.PlatformPinterest:
+		return services.PinterestPinURL(post.PlatformPostID)
+	case models.PlatformReddit:
+		return services.RedditPostURL(post.PlatformPostID)
+	}
+	// Threads permalinks use a shortcode, not the media ID posts are stored with
+	return ""
+}
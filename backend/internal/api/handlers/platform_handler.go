@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platform"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+type PlatformHandler struct {
+	config           *config.Config
+	platformRegistry *platform.PlatformRegistry
+}
+
+// NewPlatformHandler creates a new platform handler
+func NewPlatformHandler(cfg *config.Config, platformRegistry *platform.PlatformRegistry) *PlatformHandler {
+	return &PlatformHandler{
+		config:           cfg,
+		platformRegistry: platformRegistry,
+	}
+}
+
+// PlatformInfo is a platform's capabilities plus whether this server can post to it
+type PlatformInfo struct {
+	platformapi.Capabilities
+	Configured bool `json:"configured"` // Credentials for the platform are set in the server config
+}
+
+// ListPlatforms returns every known platform with its capabilities
+func (h *PlatformHandler) ListPlatforms(c *gin.Context) {
+	capabilities := h.platformRegistry.Capabilities()
+
+	platforms := make([]PlatformInfo, 0, len(capabilities))
+	for _, caps := range capabilities {
+		platforms = append(platforms, PlatformInfo{
+			Capabilities: caps,
+			Configured:   h.config.IsPlatformConfigured(string(caps.Platform)),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"platforms": platforms,
+	})
+}
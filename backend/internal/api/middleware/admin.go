@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware only lets requests through that carry the admin API key as a bearer token
+func AdminMiddleware(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid admin API key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/osmanmertacar/sosyal/backend/internal/api/handlers"
 	"github.com/osmanmertacar/sosyal/backend/internal/api/middleware"
@@ -9,10 +13,13 @@ import (
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
 	"github.com/osmanmertacar/sosyal/backend/internal/services"
 	"github.com/osmanmertacar/sosyal/backend/internal/services/platform"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 // SetupRouter sets up the HTTP router with all routes
-func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
+// Also returns the post service, which has to be shut down after the HTTP server
+// ctx lives as long as the server; cancelling it interrupts posts that are being published
+func SetupRouter(ctx context.Context, cfg *config.Config, db *database.DB) (*gin.Engine, *services.MultiPlatformPostService) {
 	// Set Gin mode based on environment
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
@@ -27,21 +34,23 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 	router.Use(middleware.SecurityHeaders())
 	router.Use(middleware.CORS(cfg.CORS.AllowedOrigins))
 
-	// Health check endpoint (no auth required)
-	router.GET("/health", func(c *gin.Context) {
-		if err := db.Health(); err != nil {
-			c.JSON(500, gin.H{"status": "unhealthy", "error": err.Error()})
-			return
-		}
-		c.JSON(200, gin.H{"status": "healthy"})
-	})
-
 	// Initialize repositories
 	userRepo := models.NewUserRepository(db.DB)
 	tokenRepo := models.NewTokenRepository(db.DB)
 	postRepo := models.NewPostRepository(db.DB)
 	platformConnectionRepo := models.NewPlatformConnectionRepository(db.DB)
 	oauthSessionRepo := models.NewOAuthSessionRepository(db.DB)
+	postMediaItemRepo := models.NewPostMediaItemRepository(db.DB)
+	mastodonAppRepo := models.NewMastodonAppRepository(db.DB)
+	facebookPageRepo := models.NewFacebookPageRepository(db.DB)
+
+	// Apply configured per-platform media size limits before anything is posted
+	services.ApplyMediaSizeLimits(cfg.Media.MaxSizes)
+
+	// Processed media variants are served from local disk so platforms can fetch them
+	mediaStore := services.NewMediaStore(cfg.Media.StorageDir, cfg.GetMediaBaseURL())
+	router.Static("/media", mediaStore.Dir())
+	imageProcessor := services.NewImageProcessor(mediaStore, cfg.Media.ImageFitMode)
 
 	// Initialize platform registry
 	platformRegistry := platform.NewPlatformRegistry()
@@ -55,33 +64,129 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 
 	// Initialize X platform services (if configured)
 	if cfg.X.ClientID != "" && cfg.X.ClientSecret != "" {
-		xPlatform := platform.NewXPlatformService(
-			cfg.X.ClientID,
-			cfg.X.ClientSecret,
-			cfg.X.RedirectURI,
-		)
+		xPlatform := platform.NewXPlatformService(cfg.X)
 		platformRegistry.Register(xPlatform)
 	}
 
 	// Initialize Instagram platform services (if configured)
 	if cfg.Instagram.AppID != "" && cfg.Instagram.AppSecret != "" {
-		instagramPlatform := platform.NewInstagramPlatformService(
-			cfg.Instagram.AppID,
-			cfg.Instagram.AppSecret,
-			cfg.Instagram.RedirectURI,
-		)
+		instagramPlatform := platform.NewInstagramPlatformService(cfg.Instagram)
 		platformRegistry.Register(instagramPlatform)
 	}
 
+	// Initialize Threads platform services (if configured)
+	if cfg.Threads.AppID != "" && cfg.Threads.AppSecret != "" {
+		platformRegistry.Register(platform.NewThreadsPlatformService(cfg.Threads))
+	}
+
+	// Initialize Facebook platform services (if configured)
+	// The app credentials may be Instagram's, so Facebook is enabled by its redirect URI
+	var facebookPlatform *platform.FacebookPlatformService
+	if cfg.IsPlatformConfigured("facebook") {
+		facebookPlatform = platform.NewFacebookPlatformService(cfg.Facebook, facebookPageRepo)
+		platformRegistry.Register(facebookPlatform)
+	}
+
+	// Initialize YouTube platform services (if configured)
+	if cfg.YouTube.ClientID != "" && cfg.YouTube.ClientSecret != "" {
+		platformRegistry.Register(platform.NewYouTubePlatformService(cfg.YouTube))
+	}
+
+	// Initialize LinkedIn platform services (if configured)
+	var linkedinPlatform *platform.LinkedInPlatformService
+	if cfg.LinkedIn.ClientID != "" && cfg.LinkedIn.ClientSecret != "" {
+		linkedinPlatform = platform.NewLinkedInPlatformService(cfg.LinkedIn)
+		platformRegistry.Register(linkedinPlatform)
+	}
+
+	// Initialize Mastodon platform services (if configured)
+	// The app registers itself on each instance users log in with, so no credentials are needed
+	if cfg.Mastodon.RedirectURI != "" {
+		platformRegistry.Register(platform.NewMastodonPlatformService(cfg.Mastodon, mastodonAppRepo))
+	}
+
+	// Initialize Bluesky platform services (if enabled)
+	// Accounts log in with app passwords, so no app credentials are needed
+	if cfg.Bluesky.Enabled {
+		platformRegistry.Register(platform.NewBlueskyPlatformService(cfg.Bluesky))
+	}
+
+	// Initialize Pinterest platform services (if configured)
+	var pinterestPlatform *platform.PinterestPlatformService
+	if cfg.Pinterest.AppID != "" && cfg.Pinterest.AppSecret != "" {
+		pinterestPlatform = platform.NewPinterestPlatformService(cfg.Pinterest)
+		platformRegistry.Register(pinterestPlatform)
+	}
+
+	// Initialize Reddit platform services (if configured)
+	var redditPlatform *platform.RedditPlatformService
+	if cfg.Reddit.ClientID != "" && cfg.Reddit.ClientSecret != "" {
+		redditPlatform = platform.NewRedditPlatformService(cfg.Reddit)
+		platformRegistry.Register(redditPlatform)
+	}
+
+	// Initialize Telegram platform services (if enabled)
+	// Chats are connected with the token of a bot the user created, so no app credentials are needed
+	if cfg.Telegram.Enabled {
+		platformRegistry.Register(platform.NewTelegramPlatformService(cfg.Telegram))
+	}
+
+	// Initialize Discord and Slack platform services (if enabled)
+	// Channels are connected with an incoming webhook the user created
+	if cfg.Discord.Enabled {
+		platformRegistry.Register(platform.NewDiscordPlatformService(cfg.Discord))
+	}
+	if cfg.Slack.Enabled {
+		platformRegistry.Register(platform.NewSlackPlatformService(cfg.Slack))
+	}
+
+	// Initialize the webhook platform service (if enabled)
+	// Endpoints are connected with their URL and the secret deliveries are signed with
+	if cfg.Webhook.Enabled {
+		platformRegistry.Register(platform.NewWebhookPlatformService(cfg.Webhook))
+	}
+
+	// Register the sandbox platform for local development and demos (if enabled)
+	if cfg.Mock.Enabled {
+		platformRegistry.Register(platform.NewMockPlatformService(cfg.Mock))
+	}
+
+	// Health check endpoint (no auth required)
+	// A platform whose circuit breaker is open makes the server degraded, not unhealthy:
+	// posts to the other platforms still go through
+	router.GET("/health", func(c *gin.Context) {
+		if err := db.Health(); err != nil {
+			c.JSON(500, gin.H{"status": "unhealthy", "error": err.Error()})
+			return
+		}
+		status := "healthy"
+		platformHealth := platformRegistry.Health()
+		for _, health := range platformHealth {
+			if health.State != platformapi.BreakerClosed {
+				status = "degraded"
+			}
+		}
+		c.JSON(200, gin.H{"status": status, "platforms": platformHealth})
+	})
+
 	// (postService kept for potential backward compatibility if needed)
 
+	// Publishing budgets of connected accounts, shared by posting and the connections API
+	quotaTracker := services.NewQuotaTracker(postRepo, platformRegistry)
+
 	// Initialize multi-platform post service
 	multiPlatformPostService := services.NewMultiPlatformPostService(
+		ctx,
 		postRepo,
 		tokenRepo,
 		platformConnectionRepo,
 		platformRegistry,
+		postMediaItemRepo,
+		imageProcessor,
+		quotaTracker,
 	)
+	// Pick up the posts the server was still working on when it last stopped
+	multiPlatformPostService.ResumePosts()
 
 	// Initialize handlers
 	multiPlatformAuthHandler := handlers.NewMultiPlatformAuthHandler(
@@ -91,15 +196,22 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 		tokenRepo,
 		platformConnectionRepo,
 		oauthSessionRepo,
+		facebookPageRepo,
+		quotaTracker,
 	)
 	multiPlatformPostHandler := handlers.NewMultiPlatformPostHandler(
 		multiPlatformPostService,
 		platformConnectionRepo,
 	)
+	platformHandler := handlers.NewPlatformHandler(cfg, platformRegistry)
+	adminHandler := handlers.NewAdminHandler(platformRegistry)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
+		// Platform capabilities (public, the frontend needs them before login)
+		v1.GET("/platforms", platformHandler.ListPlatforms)
+
 		// Auth routes (no auth middleware required, but will use it if present)
 		auth := v1.Group("/auth")
 		auth.Use(middleware.OptionalAuthMiddleware(cfg.JWT.Secret))
@@ -111,6 +223,23 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 			auth.GET("/x/callback", multiPlatformAuthHandler.XCallback)
 			auth.GET("/instagram/login", multiPlatformAuthHandler.InstagramLogin)
 			auth.GET("/instagram/callback", multiPlatformAuthHandler.InstagramCallback)
+			auth.GET("/threads/login", multiPlatformAuthHandler.ThreadsLogin)
+			auth.GET("/threads/callback", multiPlatformAuthHandler.ThreadsCallback)
+			auth.GET("/facebook/login", multiPlatformAuthHandler.FacebookLogin)
+			auth.GET("/facebook/callback", multiPlatformAuthHandler.FacebookCallback)
+			auth.GET("/youtube/login", multiPlatformAuthHandler.YouTubeLogin)
+			auth.GET("/youtube/callback", multiPlatformAuthHandler.YouTubeCallback)
+			auth.GET("/linkedin/login", multiPlatformAuthHandler.LinkedInLogin)
+			auth.GET("/linkedin/callback", multiPlatformAuthHandler.LinkedInCallback)
+			auth.GET("/mastodon/login", multiPlatformAuthHandler.MastodonLogin)
+			auth.GET("/mastodon/callback", multiPlatformAuthHandler.MastodonCallback)
+			auth.POST("/bluesky/login", multiPlatformAuthHandler.BlueskyLogin)
+			auth.GET("/pinterest/login", multiPlatformAuthHandler.PinterestLogin)
+			auth.GET("/pinterest/callback", multiPlatformAuthHandler.PinterestCallback)
+			auth.GET("/reddit/login", multiPlatformAuthHandler.RedditLogin)
+			auth.GET("/reddit/callback", multiPlatformAuthHandler.RedditCallback)
+			auth.GET("/mock/login", multiPlatformAuthHandler.MockLogin)
+			auth.GET("/mock/callback", multiPlatformAuthHandler.MockCallback)
 			auth.POST("/logout", multiPlatformAuthHandler.Logout)
 		}
 
@@ -123,6 +252,13 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 			protected.GET("/auth/platforms", multiPlatformAuthHandler.GetConnectedPlatforms)
 			protected.DELETE("/auth/platforms/:platform", multiPlatformAuthHandler.DisconnectPlatform)
 
+			// Destinations that don't identify a user are connected to the logged-in user's
+			// account; anyone can add a bot to a chat or create a webhook, so they never sign in
+			protected.POST("/auth/telegram/login", multiPlatformAuthHandler.TelegramLogin)
+			protected.POST("/auth/discord/login", multiPlatformAuthHandler.DiscordLogin)
+			protected.POST("/auth/slack/login", multiPlatformAuthHandler.SlackLogin)
+			protected.POST("/auth/webhook/login", multiPlatformAuthHandler.WebhookLogin)
+
 			// TikTok-specific routes
 			protected.GET("/tiktok/creator-info", func(c *gin.Context) {
 				userID, err := middleware.GetUserID(c)
@@ -137,7 +273,7 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 					return
 				}
 
-				creatorInfo, err := tiktokService.GetCreatorInfo(token.AccessToken)
+				creatorInfo, err := tiktokService.GetCreatorInfo(c.Request.Context(), token.AccessToken)
 				if err != nil {
 					c.JSON(500, gin.H{"error": "Failed to fetch creator info from TikTok"})
 					return
@@ -154,16 +290,238 @@ func SetupRouter(cfg *config.Config, db *database.DB) *gin.Engine {
 				})
 			})
 
+			// LinkedIn-specific routes
+			if linkedinPlatform != nil {
+				// Organization pages the member can post as, for the organization_id setting
+				protected.GET("/linkedin/organizations", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformLinkedIn)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "LinkedIn account not connected"})
+						return
+					}
+
+					organizations, err := linkedinPlatform.ListOrganizations(c.Request.Context(), token.AccessToken)
+					if err != nil {
+						log.Printf("Failed to fetch LinkedIn organizations of user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to fetch organizations from LinkedIn"})
+						return
+					}
+
+					c.JSON(200, gin.H{"organizations": organizations})
+				})
+			}
+
+			// Facebook-specific routes
+			if facebookPlatform != nil {
+				// Pages the user can post as, and whether each one is connected
+				protected.GET("/facebook/pages", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformFacebook)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Facebook account not connected"})
+						return
+					}
+
+					pages, err := facebookPlatform.ListPages(c.Request.Context(), userID, token.AccessToken)
+					if err != nil {
+						log.Printf("Failed to fetch Facebook pages of user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to fetch pages from Facebook"})
+						return
+					}
+
+					c.JSON(200, gin.H{"pages": pages})
+				})
+
+				// Choose the pages to post as, replacing the pages connected before
+				protected.PUT("/facebook/pages", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					var req struct {
+						PageIDs []string `json:"page_ids"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(400, gin.H{"error": "Invalid request body"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformFacebook)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Facebook account not connected"})
+						return
+					}
+
+					pages, err := facebookPlatform.ConnectPages(c.Request.Context(), userID, token.AccessToken, req.PageIDs)
+					if errors.Is(err, platform.ErrFacebookPageNotAvailable) {
+						c.JSON(400, gin.H{"error": err.Error()})
+						return
+					}
+					if err != nil {
+						log.Printf("Failed to connect Facebook pages of user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to connect Facebook pages"})
+						return
+					}
+
+					c.JSON(200, gin.H{"pages": pages})
+				})
+			}
+
+			// Pinterest-specific routes
+			if pinterestPlatform != nil {
+				// Boards of the account, for the board_id setting
+				protected.GET("/pinterest/boards", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformPinterest)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Pinterest account not connected"})
+						return
+					}
+
+					boards, err := pinterestPlatform.ListBoards(c.Request.Context(), token.AccessToken)
+					if err != nil {
+						log.Printf("Failed to fetch Pinterest boards of user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to fetch boards from Pinterest"})
+						return
+					}
+
+					c.JSON(200, gin.H{"boards": boards})
+				})
+
+				// Create a board while setting up the connection
+				protected.POST("/pinterest/boards", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					var req struct {
+						Name        string `json:"name" binding:"required,max=50"`
+						Description string `json:"description" binding:"max=500"`
+						Privacy     string `json:"privacy" binding:"omitempty,oneof=PUBLIC SECRET"`
+					}
+					if err := c.ShouldBindJSON(&req); err != nil {
+						c.JSON(400, gin.H{"error": "Invalid request body"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformPinterest)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Pinterest account not connected"})
+						return
+					}
+
+					board, err := pinterestPlatform.CreateBoard(c.Request.Context(), token.AccessToken, req.Name, req.Description, req.Privacy)
+					if err != nil {
+						log.Printf("Failed to create Pinterest board for user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to create board on Pinterest"})
+						return
+					}
+
+					c.JSON(201, gin.H{"board": board})
+				})
+			}
+
+			// Reddit-specific routes
+			if redditPlatform != nil {
+				// Subreddits the account is subscribed to, for the subreddit setting
+				protected.GET("/reddit/subreddits", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformReddit)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Reddit account not connected"})
+						return
+					}
+
+					subreddits, err := redditPlatform.ListSubreddits(c.Request.Context(), token.AccessToken)
+					if err != nil {
+						log.Printf("Failed to fetch Reddit subreddits of user %d: %v", userID, err)
+						c.JSON(500, gin.H{"error": "Failed to fetch subreddits from Reddit"})
+						return
+					}
+
+					c.JSON(200, gin.H{"subreddits": subreddits})
+				})
+
+				// Post flairs of a subreddit, for the flair_id setting
+				protected.GET("/reddit/subreddits/:name/flairs", func(c *gin.Context) {
+					userID, err := middleware.GetUserID(c)
+					if err != nil {
+						c.JSON(401, gin.H{"error": "Not authenticated"})
+						return
+					}
+
+					name := services.NormalizeSubreddit(c.Param("name"))
+					if !services.IsRedditSubredditName(name) {
+						c.JSON(400, gin.H{"error": "Invalid subreddit name"})
+						return
+					}
+
+					token, err := tokenRepo.GetByUserIDAndPlatform(userID, models.PlatformReddit)
+					if err != nil {
+						c.JSON(404, gin.H{"error": "Reddit account not connected"})
+						return
+					}
+
+					flairs, err := redditPlatform.ListFlairs(c.Request.Context(), token.AccessToken, name)
+					if err != nil {
+						log.Printf("Failed to fetch flairs of r/%s for user %d: %v", name, userID, err)
+						c.JSON(500, gin.H{"error": "Failed to fetch flairs from Reddit"})
+						return
+					}
+
+					c.JSON(200, gin.H{"flairs": flairs})
+				})
+			}
+
 			// Post routes - using multi-platform handler
 			posts := protected.Group("/posts")
 			{
 				posts.POST("", multiPlatformPostHandler.CreatePost)
+				posts.POST("/validate", multiPlatformPostHandler.ValidatePost)
 				posts.GET("", multiPlatformPostHandler.GetPosts)
 				posts.GET("/:id", multiPlatformPostHandler.GetPost)
 				posts.GET("/:id/status", multiPlatformPostHandler.GetPostStatus)
+				posts.POST("/:id/cancel", multiPlatformPostHandler.CancelPost)
+				posts.DELETE("/:id", multiPlatformPostHandler.DeletePost)
+			}
+		}
+
+		// Admin routes (require the admin API key, disabled without one)
+		if cfg.Admin.APIKey != "" {
+			admin := v1.Group("/admin")
+			admin.Use(middleware.AdminMiddleware(cfg.Admin.APIKey))
+			{
+				admin.GET("/platforms/health", adminHandler.GetPlatformHealth)
+				admin.POST("/platforms/:platform/reset", adminHandler.ResetPlatformBreaker)
+				admin.GET("/http/metrics", adminHandler.GetHTTPMetrics)
 			}
 		}
 	}
 
-	return router
+	return router, multiPlatformPostService
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,10 +15,25 @@ type Config struct {
 	TikTok    TikTokConfig
 	X         XConfig
 	Instagram InstagramConfig
+	Threads   ThreadsConfig
+	Facebook  FacebookConfig
+	YouTube   YouTubeConfig
+	LinkedIn  LinkedInConfig
+	Mastodon  MastodonConfig
+	Bluesky   BlueskyConfig
+	Pinterest PinterestConfig
+	Reddit    RedditConfig
+	Telegram  TelegramConfig
+	Discord   DiscordConfig
+	Slack     SlackConfig
+	Webhook   WebhookConfig
+	Mock      MockConfig
 	Database  DatabaseConfig
 	JWT       JWTConfig
 	CORS      CORSConfig
 	Log       LogConfig
+	Media     MediaConfig
+	Admin     AdminConfig
 }
 
 type ServerConfig struct {
@@ -32,18 +48,189 @@ type TikTokConfig struct {
 	ClientSecret string
 	RedirectURI  string
 	Scopes       []string
+
+	// Base URLs of the TikTok endpoints, overridable to point at a fake server
+	AuthBaseURL string // Authorization page (www.tiktok.com)
+	APIBaseURL  string // Open API (open.tiktokapis.com)
 }
 
 type XConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURI  string
+
+	// Base URLs of the X endpoints, overridable to point at a fake server
+	AuthBaseURL   string // Authorization page (twitter.com)
+	APIBaseURL    string // OAuth token, users and tweets API (api.twitter.com)
+	UploadBaseURL string // Media upload API (api.x.com)
 }
 
 type InstagramConfig struct {
 	AppID       string
 	AppSecret   string
 	RedirectURI string
+
+	// Base URLs of the Instagram endpoints, overridable to point at a fake server
+	AuthBaseURL  string // Authorization page (www.instagram.com)
+	APIBaseURL   string // Short-lived token exchange (api.instagram.com)
+	GraphBaseURL string // Graph API (graph.instagram.com)
+}
+
+// ThreadsConfig configures posting to Threads
+// Threads needs its own app credentials, even when the Meta app also uses Instagram
+type ThreadsConfig struct {
+	AppID       string
+	AppSecret   string
+	RedirectURI string
+
+	// Base URLs of the Threads endpoints, overridable to point at a fake server
+	AuthBaseURL  string // Authorization page (threads.net)
+	GraphBaseURL string // Token exchange and Graph API (graph.threads.net)
+}
+
+// FacebookConfig configures publishing to Facebook Pages with Facebook Login
+// The app ID and secret default to Instagram's, since both use the same Meta app
+type FacebookConfig struct {
+	AppID        string
+	AppSecret    string
+	RedirectURI  string
+	Scopes       []string
+	GraphVersion string // Graph API version, e.g. v23.0
+
+	// Base URLs of the Facebook endpoints, overridable to point at a fake server
+	AuthBaseURL  string // Login dialog (www.facebook.com)
+	GraphBaseURL string // Token exchange and Graph API (graph.facebook.com)
+}
+
+// YouTubeConfig configures uploading Shorts to YouTube with a Google OAuth client
+type YouTubeConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+
+	// Base URLs of the Google endpoints, overridable to point at a fake server
+	AuthBaseURL  string // Authorization page (accounts.google.com)
+	TokenBaseURL string // Token exchange and refresh (oauth2.googleapis.com)
+	APIBaseURL   string // Data API and resumable uploads (www.googleapis.com)
+}
+
+type LinkedInConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string // Add r_organization_admin and w_organization_social to post as organization pages
+	APIVersion   string   // Version of the versioned REST API in YYYYMM form, sent as LinkedIn-Version
+
+	// Base URLs of the LinkedIn endpoints, overridable to point at a fake server
+	AuthBaseURL string // Authorization page and token exchange (www.linkedin.com)
+	APIBaseURL  string // REST and userinfo API (api.linkedin.com)
+}
+
+// MastodonConfig configures posting to Mastodon instances
+// Mastodon has no central developer portal: the app registers itself on every instance a user
+// logs in with, so only the redirect URI is needed up front
+type MastodonConfig struct {
+	RedirectURI string
+	ClientName  string   // Name of the app shown on the instance's authorization page
+	Website     string   // Link shown with the app name, optional
+	Scopes      []string // Granular scopes need Mastodon 3.5 or later
+
+	// AllowedInstances limits logins to these instance hosts; empty allows any public instance
+	AllowedInstances []string
+	// AllowPrivateInstances allows instances on private networks and plain http, for local
+	// development against a test instance
+	AllowPrivateInstances bool
+}
+
+// BlueskyConfig configures posting to Bluesky
+// Accounts are connected with an app password, so there are no client credentials; the
+// account's own PDS is learned when it logs in
+type BlueskyConfig struct {
+	Enabled bool
+	PDSURL  string // Server accounts log in on (bsky.social); overridable to point at a local PDS
+
+	// AllowPrivatePDS allows accounts whose PDS is on a private network or plain http, for local
+	// development against a test PDS
+	AllowPrivatePDS bool
+}
+
+// PinterestConfig configures posting pins to Pinterest boards
+type PinterestConfig struct {
+	AppID       string
+	AppSecret   string
+	RedirectURI string
+	Scopes      []string
+
+	// Base URLs of the Pinterest endpoints, overridable to point at a fake server
+	AuthBaseURL string // Authorization page (www.pinterest.com)
+	APIBaseURL  string // v5 API, token exchange and media registration (api.pinterest.com)
+}
+
+// RedditConfig configures submitting posts to subreddits
+type RedditConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Scopes       []string
+	// UserAgent identifies the app to Reddit, which throttles generic user agents, e.g.
+	// web:sosyal:1.0 (by /u/yourname)
+	UserAgent string
+
+	// Base URLs of the Reddit endpoints, overridable to point at a fake server
+	AuthBaseURL string // Authorization page and token exchange (www.reddit.com)
+	APIBaseURL  string // OAuth API (oauth.reddit.com)
+}
+
+// TelegramConfig configures posting to Telegram channels and groups
+// Users connect a chat with the token of their own bot, which has to be allowed to post in it,
+// so there are no app credentials
+type TelegramConfig struct {
+	Enabled    bool
+	APIBaseURL string // Bot API (api.telegram.org); overridable to point at a fake or a local Bot API server
+}
+
+// DiscordConfig configures posting to Discord channels through their incoming webhooks
+type DiscordConfig struct {
+	Enabled bool
+	// WebhookBaseURL is the prefix webhook URLs must start with (https://discord.com/api/webhooks),
+	// so the server only ever calls Discord with the URLs users enter
+	WebhookBaseURL string
+}
+
+// SlackConfig configures posting to Slack channels through their incoming webhooks
+type SlackConfig struct {
+	Enabled bool
+	// WebhookBaseURL is the prefix webhook URLs must start with (https://hooks.slack.com/services),
+	// so the server only ever calls Slack with the URLs users enter
+	WebhookBaseURL string
+}
+
+// WebhookConfig configures posting to endpoints of the users' own systems, like a website CMS
+// Users connect an endpoint with its URL and a secret the deliveries are signed with, so there
+// are no app credentials
+type WebhookConfig struct {
+	Enabled bool
+
+	// AllowedHosts limits endpoints to these hosts; empty allows any public host
+	// Listed hosts may be on private networks, so internal systems can be reached
+	AllowedHosts []string
+	// AllowPrivateURLs allows endpoints on any private network and over plain http, for local
+	// development
+	AllowPrivateURLs bool
+}
+
+// MockConfig configures the sandbox platform used for local development and demos
+type MockConfig struct {
+	Enabled         bool
+	RedirectURI     string
+	ProcessingDelay time.Duration // How long the mock platform takes to process a video
+}
+
+// AdminConfig protects the operator endpoints under /api/v1/admin
+// They are disabled unless an API key is set
+type AdminConfig struct {
+	APIKey string
 }
 
 type DatabaseConfig struct {
@@ -63,11 +250,33 @@ type LogConfig struct {
 	Level string
 }
 
+type MediaConfig struct {
+	StorageDir    string // Directory where processed media variants are written
+	PublicBaseURL string // Public URL the /media route is reachable at (platforms fetch media from here)
+	ImageFitMode  string // crop or pad, used when an image is outside a platform's aspect ratio range
+
+	// MaxSizes overrides the built-in per-platform file size limits, keyed by platform name
+	// Set with MEDIA_MAX_<PLATFORM>_IMAGE_MB and MEDIA_MAX_<PLATFORM>_VIDEO_MB
+	MaxSizes map[string]MediaSizeLimit
+}
+
+// MediaSizeLimit is the maximum media file size accepted for a platform, in bytes
+// Zero means the built-in limit applies
+type MediaSizeLimit struct {
+	ImageBytes int64
+	VideoBytes int64
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
+	mediaMaxSizes, err := loadMediaSizeLimits()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
 			Port:        getEnv("SERVER_PORT", "8080"),
@@ -80,16 +289,110 @@ func Load() (*Config, error) {
 			ClientSecret: getEnv("TIKTOK_CLIENT_SECRET", ""),
 			RedirectURI:  getEnv("TIKTOK_REDIRECT_URI", ""),
 			Scopes:       strings.Split(getEnv("TIKTOK_SCOPES", "user.info.basic,video.publish"), ","),
+			AuthBaseURL:  getBaseURL("TIKTOK_AUTH_BASE_URL", "https://www.tiktok.com"),
+			APIBaseURL:   getBaseURL("TIKTOK_API_BASE_URL", "https://open.tiktokapis.com"),
 		},
 		X: XConfig{
-			ClientID:     getEnv("X_CLIENT_ID", ""),
-			ClientSecret: getEnv("X_CLIENT_SECRET", ""),
-			RedirectURI:  getEnv("X_REDIRECT_URI", ""),
+			ClientID:      getEnv("X_CLIENT_ID", ""),
+			ClientSecret:  getEnv("X_CLIENT_SECRET", ""),
+			RedirectURI:   getEnv("X_REDIRECT_URI", ""),
+			AuthBaseURL:   getBaseURL("X_AUTH_BASE_URL", "https://twitter.com"),
+			APIBaseURL:    getBaseURL("X_API_BASE_URL", "https://api.twitter.com"),
+			UploadBaseURL: getBaseURL("X_UPLOAD_BASE_URL", "https://api.x.com"),
 		},
 		Instagram: InstagramConfig{
-			AppID:       getEnv("INSTAGRAM_APP_ID", ""),
-			AppSecret:   getEnv("INSTAGRAM_APP_SECRET", ""),
-			RedirectURI: getEnv("INSTAGRAM_REDIRECT_URI", ""),
+			AppID:        getEnv("INSTAGRAM_APP_ID", ""),
+			AppSecret:    getEnv("INSTAGRAM_APP_SECRET", ""),
+			RedirectURI:  getEnv("INSTAGRAM_REDIRECT_URI", ""),
+			AuthBaseURL:  getBaseURL("INSTAGRAM_AUTH_BASE_URL", "https://www.instagram.com"),
+			APIBaseURL:   getBaseURL("INSTAGRAM_API_BASE_URL", "https://api.instagram.com"),
+			GraphBaseURL: getBaseURL("INSTAGRAM_GRAPH_BASE_URL", "https://graph.instagram.com"),
+		},
+		Threads: ThreadsConfig{
+			AppID:        getEnv("THREADS_APP_ID", ""),
+			AppSecret:    getEnv("THREADS_APP_SECRET", ""),
+			RedirectURI:  getEnv("THREADS_REDIRECT_URI", ""),
+			AuthBaseURL:  getBaseURL("THREADS_AUTH_BASE_URL", "https://threads.net"),
+			GraphBaseURL: getBaseURL("THREADS_GRAPH_BASE_URL", "https://graph.threads.net"),
+		},
+		Facebook: FacebookConfig{
+			AppID:        getEnv("FACEBOOK_APP_ID", getEnv("INSTAGRAM_APP_ID", "")),
+			AppSecret:    getEnv("FACEBOOK_APP_SECRET", getEnv("INSTAGRAM_APP_SECRET", "")),
+			RedirectURI:  getEnv("FACEBOOK_REDIRECT_URI", ""),
+			Scopes:       strings.Split(getEnv("FACEBOOK_SCOPES", "pages_show_list,pages_read_engagement,pages_manage_posts"), ","),
+			GraphVersion: getEnv("FACEBOOK_GRAPH_VERSION", "v23.0"),
+			AuthBaseURL:  getBaseURL("FACEBOOK_AUTH_BASE_URL", "https://www.facebook.com"),
+			GraphBaseURL: getBaseURL("FACEBOOK_GRAPH_BASE_URL", "https://graph.facebook.com"),
+		},
+		YouTube: YouTubeConfig{
+			ClientID:     getEnv("YOUTUBE_CLIENT_ID", ""),
+			ClientSecret: getEnv("YOUTUBE_CLIENT_SECRET", ""),
+			RedirectURI:  getEnv("YOUTUBE_REDIRECT_URI", ""),
+			Scopes:       strings.Split(getEnv("YOUTUBE_SCOPES", "https://www.googleapis.com/auth/youtube.upload,https://www.googleapis.com/auth/youtube.readonly"), ","),
+			AuthBaseURL:  getBaseURL("YOUTUBE_AUTH_BASE_URL", "https://accounts.google.com"),
+			TokenBaseURL: getBaseURL("YOUTUBE_TOKEN_BASE_URL", "https://oauth2.googleapis.com"),
+			APIBaseURL:   getBaseURL("YOUTUBE_API_BASE_URL", "https://www.googleapis.com"),
+		},
+		LinkedIn: LinkedInConfig{
+			ClientID:     getEnv("LINKEDIN_CLIENT_ID", ""),
+			ClientSecret: getEnv("LINKEDIN_CLIENT_SECRET", ""),
+			RedirectURI:  getEnv("LINKEDIN_REDIRECT_URI", ""),
+			Scopes:       strings.Split(getEnv("LINKEDIN_SCOPES", "openid,profile,email,w_member_social"), ","),
+			APIVersion:   getEnv("LINKEDIN_API_VERSION", "202509"),
+			AuthBaseURL:  getBaseURL("LINKEDIN_AUTH_BASE_URL", "https://www.linkedin.com"),
+			APIBaseURL:   getBaseURL("LINKEDIN_API_BASE_URL", "https://api.linkedin.com"),
+		},
+		Mastodon: MastodonConfig{
+			RedirectURI:           getEnv("MASTODON_REDIRECT_URI", ""),
+			ClientName:            getEnv("MASTODON_CLIENT_NAME", "Sosyal"),
+			Website:               getEnv("MASTODON_WEBSITE", ""),
+			Scopes:                strings.Split(getEnv("MASTODON_SCOPES", "read:accounts,write:statuses,write:media"), ","),
+			AllowedInstances:      splitList(getEnv("MASTODON_ALLOWED_INSTANCES", "")),
+			AllowPrivateInstances: getEnv("MASTODON_ALLOW_PRIVATE_INSTANCES", "false") == "true",
+		},
+		Bluesky: BlueskyConfig{
+			Enabled:         getEnv("BLUESKY_ENABLED", "false") == "true",
+			PDSURL:          getBaseURL("BLUESKY_PDS_URL", "https://bsky.social"),
+			AllowPrivatePDS: getEnv("BLUESKY_ALLOW_PRIVATE_PDS", "false") == "true",
+		},
+		Pinterest: PinterestConfig{
+			AppID:       getEnv("PINTEREST_APP_ID", ""),
+			AppSecret:   getEnv("PINTEREST_APP_SECRET", ""),
+			RedirectURI: getEnv("PINTEREST_REDIRECT_URI", ""),
+			Scopes:      strings.Split(getEnv("PINTEREST_SCOPES", "user_accounts:read,boards:read,boards:write,pins:read,pins:write"), ","),
+			AuthBaseURL: getBaseURL("PINTEREST_AUTH_BASE_URL", "https://www.pinterest.com"),
+			APIBaseURL:  getBaseURL("PINTEREST_API_BASE_URL", "https://api.pinterest.com"),
+		},
+		Reddit: RedditConfig{
+			ClientID:     getEnv("REDDIT_CLIENT_ID", ""),
+			ClientSecret: getEnv("REDDIT_CLIENT_SECRET", ""),
+			RedirectURI:  getEnv("REDDIT_REDIRECT_URI", ""),
+			Scopes:       strings.Split(getEnv("REDDIT_SCOPES", "identity,submit,read,flair,mysubreddits"), ","),
+			UserAgent:    getEnv("REDDIT_USER_AGENT", "web:sosyal:1.0"),
+			AuthBaseURL:  getBaseURL("REDDIT_AUTH_BASE_URL", "https://www.reddit.com"),
+			APIBaseURL:   getBaseURL("REDDIT_API_BASE_URL", "https://oauth.reddit.com"),
+		},
+		Telegram: TelegramConfig{
+			Enabled:    getEnv("TELEGRAM_ENABLED", "false") == "true",
+			APIBaseURL: getBaseURL("TELEGRAM_API_BASE_URL", "https://api.telegram.org"),
+		},
+		Discord: DiscordConfig{
+			Enabled:        getEnv("DISCORD_ENABLED", "false") == "true",
+			WebhookBaseURL: getBaseURL("DISCORD_WEBHOOK_BASE_URL", "https://discord.com/api/webhooks"),
+		},
+		Slack: SlackConfig{
+			Enabled:        getEnv("SLACK_ENABLED", "false") == "true",
+			WebhookBaseURL: getBaseURL("SLACK_WEBHOOK_BASE_URL", "https://hooks.slack.com/services"),
+		},
+		Webhook: WebhookConfig{
+			Enabled:          getEnv("WEBHOOK_ENABLED", "false") == "true",
+			AllowedHosts:     splitList(getEnv("WEBHOOK_ALLOWED_HOSTS", "")),
+			AllowPrivateURLs: getEnv("WEBHOOK_ALLOW_PRIVATE_URLS", "false") == "true",
+		},
+		Mock: MockConfig{
+			Enabled:         getEnv("MOCK_PLATFORM_ENABLED", "false") == "true",
+			RedirectURI:     getEnv("MOCK_REDIRECT_URI", "http://localhost:8080/api/v1/auth/mock/callback"),
+			ProcessingDelay: parseDurationDefault(getEnv("MOCK_PLATFORM_DELAY", "5s"), 5*time.Second),
 		},
 		Database: DatabaseConfig{
 			Path: getEnv("DATABASE_PATH", "./data/sosyal.db"),
@@ -104,6 +407,15 @@ func Load() (*Config, error) {
 		Log: LogConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
+		Media: MediaConfig{
+			StorageDir:    getEnv("MEDIA_STORAGE_DIR", "./data/media"),
+			PublicBaseURL: strings.TrimSuffix(getEnv("MEDIA_PUBLIC_BASE_URL", ""), "/"),
+			ImageFitMode:  getEnv("MEDIA_IMAGE_FIT_MODE", "crop"),
+			MaxSizes:      mediaMaxSizes,
+		},
+		Admin: AdminConfig{
+			APIKey: getEnv("ADMIN_API_KEY", ""),
+		},
 	}
 
 	// Validate required fields
@@ -117,12 +429,30 @@ func Load() (*Config, error) {
 // Validate checks if required configuration fields are set
 func (c *Config) Validate() error {
 	// Check if at least one platform is configured
-	hasTikTok := c.TikTok.ClientKey != "" && c.TikTok.ClientSecret != "" && c.TikTok.RedirectURI != ""
-	hasX := c.X.ClientID != "" && c.X.ClientSecret != "" && c.X.RedirectURI != ""
-	hasInstagram := c.Instagram.AppID != "" && c.Instagram.AppSecret != "" && c.Instagram.RedirectURI != ""
+	hasTikTok := c.IsPlatformConfigured("tiktok")
+	hasX := c.IsPlatformConfigured("x")
+	hasInstagram := c.IsPlatformConfigured("instagram")
+	hasThreads := c.IsPlatformConfigured("threads")
+	hasFacebook := c.IsPlatformConfigured("facebook")
+	hasYouTube := c.IsPlatformConfigured("youtube")
+	hasLinkedIn := c.IsPlatformConfigured("linkedin")
+	hasMastodon := c.IsPlatformConfigured("mastodon")
+	hasBluesky := c.IsPlatformConfigured("bluesky")
+	hasPinterest := c.IsPlatformConfigured("pinterest")
+	hasReddit := c.IsPlatformConfigured("reddit")
+	hasTelegram := c.IsPlatformConfigured("telegram")
+	hasDiscord := c.IsPlatformConfigured("discord")
+	hasSlack := c.IsPlatformConfigured("slack")
+	hasWebhook := c.IsPlatformConfigured("webhook")
+	hasMock := c.IsPlatformConfigured("mock")
+
+	if !hasTikTok && !hasX && !hasInstagram && !hasThreads && !hasFacebook && !hasYouTube && !hasLinkedIn && !hasMastodon && !hasBluesky && !hasPinterest && !hasReddit && !hasTelegram && !hasDiscord && !hasSlack && !hasWebhook && !hasMock {
+		return fmt.Errorf("at least one platform (TikTok, X, Instagram, Threads, Facebook, YouTube, LinkedIn, Mastodon, Bluesky, Pinterest, Reddit, Telegram, Discord, Slack or Webhook) must be fully configured, or MOCK_PLATFORM_ENABLED set to true")
+	}
 
-	if !hasTikTok && !hasX && !hasInstagram {
-		return fmt.Errorf("at least one platform (TikTok, X, or Instagram) must be fully configured")
+	// The mock platform accepts any post without publishing it, so it must never reach users
+	if hasMock && c.IsProduction() {
+		return fmt.Errorf("MOCK_PLATFORM_ENABLED must not be set in production")
 	}
 
 	// Validate TikTok config if any TikTok field is set
@@ -164,6 +494,128 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate Threads config if any Threads field is set
+	if c.Threads.AppID != "" || c.Threads.AppSecret != "" || c.Threads.RedirectURI != "" {
+		if c.Threads.AppID == "" {
+			return fmt.Errorf("THREADS_APP_ID is required when Threads is configured")
+		}
+		if c.Threads.AppSecret == "" {
+			return fmt.Errorf("THREADS_APP_SECRET is required when Threads is configured")
+		}
+		if c.Threads.RedirectURI == "" {
+			return fmt.Errorf("THREADS_REDIRECT_URI is required when Threads is configured")
+		}
+	}
+
+	// Validate Facebook config if its redirect URI is set; the app credentials alone may be
+	// Instagram's, so they don't enable Facebook
+	if c.Facebook.RedirectURI != "" {
+		if c.Facebook.AppID == "" {
+			return fmt.Errorf("FACEBOOK_APP_ID or INSTAGRAM_APP_ID is required when Facebook is configured")
+		}
+		if c.Facebook.AppSecret == "" {
+			return fmt.Errorf("FACEBOOK_APP_SECRET or INSTAGRAM_APP_SECRET is required when Facebook is configured")
+		}
+	}
+
+	// Validate YouTube config if any YouTube field is set
+	if c.YouTube.ClientID != "" || c.YouTube.ClientSecret != "" || c.YouTube.RedirectURI != "" {
+		if c.YouTube.ClientID == "" {
+			return fmt.Errorf("YOUTUBE_CLIENT_ID is required when YouTube is configured")
+		}
+		if c.YouTube.ClientSecret == "" {
+			return fmt.Errorf("YOUTUBE_CLIENT_SECRET is required when YouTube is configured")
+		}
+		if c.YouTube.RedirectURI == "" {
+			return fmt.Errorf("YOUTUBE_REDIRECT_URI is required when YouTube is configured")
+		}
+	}
+
+	// Validate LinkedIn config if any LinkedIn field is set
+	if c.LinkedIn.ClientID != "" || c.LinkedIn.ClientSecret != "" || c.LinkedIn.RedirectURI != "" {
+		if c.LinkedIn.ClientID == "" {
+			return fmt.Errorf("LINKEDIN_CLIENT_ID is required when LinkedIn is configured")
+		}
+		if c.LinkedIn.ClientSecret == "" {
+			return fmt.Errorf("LINKEDIN_CLIENT_SECRET is required when LinkedIn is configured")
+		}
+		if c.LinkedIn.RedirectURI == "" {
+			return fmt.Errorf("LINKEDIN_REDIRECT_URI is required when LinkedIn is configured")
+		}
+	}
+
+	// Validate Mastodon config if any instance restriction is set
+	if (len(c.Mastodon.AllowedInstances) > 0 || c.Mastodon.AllowPrivateInstances) && c.Mastodon.RedirectURI == "" {
+		return fmt.Errorf("MASTODON_REDIRECT_URI is required when Mastodon is configured")
+	}
+
+	// Private instances would let users make the server call into its own network
+	if c.Mastodon.AllowPrivateInstances && c.IsProduction() {
+		return fmt.Errorf("MASTODON_ALLOW_PRIVATE_INSTANCES must not be set in production")
+	}
+
+	// Validate Bluesky config if it is enabled
+	if hasBluesky && c.Bluesky.PDSURL == "" {
+		return fmt.Errorf("BLUESKY_PDS_URL is required when Bluesky is enabled")
+	}
+	if c.Bluesky.AllowPrivatePDS && c.IsProduction() {
+		return fmt.Errorf("BLUESKY_ALLOW_PRIVATE_PDS must not be set in production")
+	}
+
+	// Validate Pinterest config if any Pinterest field is set
+	if c.Pinterest.AppID != "" || c.Pinterest.AppSecret != "" || c.Pinterest.RedirectURI != "" {
+		if c.Pinterest.AppID == "" {
+			return fmt.Errorf("PINTEREST_APP_ID is required when Pinterest is configured")
+		}
+		if c.Pinterest.AppSecret == "" {
+			return fmt.Errorf("PINTEREST_APP_SECRET is required when Pinterest is configured")
+		}
+		if c.Pinterest.RedirectURI == "" {
+			return fmt.Errorf("PINTEREST_REDIRECT_URI is required when Pinterest is configured")
+		}
+	}
+
+	// Validate Reddit config if any Reddit field is set
+	if c.Reddit.ClientID != "" || c.Reddit.ClientSecret != "" || c.Reddit.RedirectURI != "" {
+		if c.Reddit.ClientID == "" {
+			return fmt.Errorf("REDDIT_CLIENT_ID is required when Reddit is configured")
+		}
+		if c.Reddit.ClientSecret == "" {
+			return fmt.Errorf("REDDIT_CLIENT_SECRET is required when Reddit is configured")
+		}
+		if c.Reddit.RedirectURI == "" {
+			return fmt.Errorf("REDDIT_REDIRECT_URI is required when Reddit is configured")
+		}
+		if c.Reddit.UserAgent == "" {
+			return fmt.Errorf("REDDIT_USER_AGENT is required when Reddit is configured")
+		}
+	}
+
+	// Validate the base URLs of the platforms connected without app credentials
+	if hasTelegram && c.Telegram.APIBaseURL == "" {
+		return fmt.Errorf("TELEGRAM_API_BASE_URL is required when Telegram is enabled")
+	}
+	if hasDiscord && c.Discord.WebhookBaseURL == "" {
+		return fmt.Errorf("DISCORD_WEBHOOK_BASE_URL is required when Discord is enabled")
+	}
+	if hasSlack && c.Slack.WebhookBaseURL == "" {
+		return fmt.Errorf("SLACK_WEBHOOK_BASE_URL is required when Slack is enabled")
+	}
+
+	// Private endpoints would let users make the server call into its own network; internal
+	// systems are allowed by listing their hosts instead
+	if c.Webhook.AllowPrivateURLs && c.IsProduction() {
+		return fmt.Errorf("WEBHOOK_ALLOW_PRIVATE_URLS must not be set in production")
+	}
+
+	if c.Media.ImageFitMode != "crop" && c.Media.ImageFitMode != "pad" {
+		return fmt.Errorf("MEDIA_IMAGE_FIT_MODE must be either crop or pad")
+	}
+
+	if c.Admin.APIKey != "" && len(c.Admin.APIKey) < 32 {
+		return fmt.Errorf("ADMIN_API_KEY must be at least 32 characters long")
+	}
+
 	// JWT is always required
 	if c.JWT.Secret == "" {
 		return fmt.Errorf("JWT_SECRET is required")
@@ -184,15 +636,128 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getBaseURL gets a base URL from an environment variable without its trailing slash
+func getBaseURL(key, defaultValue string) string {
+	return strings.TrimSuffix(getEnv(key, defaultValue), "/")
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadMediaSizeLimits reads MEDIA_MAX_<PLATFORM>_<IMAGE|VIDEO>_MB variables
+func loadMediaSizeLimits() (map[string]MediaSizeLimit, error) {
+	limits := make(map[string]MediaSizeLimit)
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, "MEDIA_MAX_") || !strings.HasSuffix(key, "_MB") {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "MEDIA_MAX_"), "_MB")
+		platform, kind, ok := cutLast(name, "_")
+		if !ok || platform == "" || (kind != "IMAGE" && kind != "VIDEO") {
+			return nil, fmt.Errorf("%s: expected MEDIA_MAX_<PLATFORM>_IMAGE_MB or MEDIA_MAX_<PLATFORM>_VIDEO_MB", key)
+		}
+
+		megabytes, err := strconv.ParseFloat(value, 64)
+		if err != nil || megabytes <= 0 {
+			return nil, fmt.Errorf("%s must be a positive number of megabytes", key)
+		}
+		size := int64(megabytes * 1024 * 1024)
+
+		platform = strings.ToLower(platform)
+		limit := limits[platform]
+		if kind == "IMAGE" {
+			limit.ImageBytes = size
+		} else {
+			limit.VideoBytes = size
+		}
+		limits[platform] = limit
+	}
+
+	return limits, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
 // parseDuration parses a duration string, returns 24h as default on error
 func parseDuration(s string) time.Duration {
+	return parseDurationDefault(s, 24*time.Hour)
+}
+
+// parseDurationDefault parses a duration string, returns defaultValue on error
+func parseDurationDefault(s string, defaultValue time.Duration) time.Duration {
 	duration, err := time.ParseDuration(s)
 	if err != nil {
-		return 24 * time.Hour
+		return defaultValue
 	}
 	return duration
 }
 
+// GetMediaBaseURL returns the public base URL processed media is served from
+// Falls back to the server address when MEDIA_PUBLIC_BASE_URL is not set
+func (c *Config) GetMediaBaseURL() string {
+	if c.Media.PublicBaseURL != "" {
+		return c.Media.PublicBaseURL
+	}
+	return fmt.Sprintf("http://%s", c.GetServerAddress())
+}
+
+// IsPlatformConfigured reports whether all credentials of a platform are set
+// Returns false for platforms the configuration doesn't know about
+func (c *Config) IsPlatformConfigured(platform string) bool {
+	switch platform {
+	case "tiktok":
+		return c.TikTok.ClientKey != "" && c.TikTok.ClientSecret != "" && c.TikTok.RedirectURI != ""
+	case "x":
+		return c.X.ClientID != "" && c.X.ClientSecret != "" && c.X.RedirectURI != ""
+	case "instagram":
+		return c.Instagram.AppID != "" && c.Instagram.AppSecret != "" && c.Instagram.RedirectURI != ""
+	case "threads":
+		return c.Threads.AppID != "" && c.Threads.AppSecret != "" && c.Threads.RedirectURI != ""
+	case "facebook":
+		return c.Facebook.AppID != "" && c.Facebook.AppSecret != "" && c.Facebook.RedirectURI != ""
+	case "youtube":
+		return c.YouTube.ClientID != "" && c.YouTube.ClientSecret != "" && c.YouTube.RedirectURI != ""
+	case "linkedin":
+		return c.LinkedIn.ClientID != "" && c.LinkedIn.ClientSecret != "" && c.LinkedIn.RedirectURI != ""
+	case "mastodon":
+		return c.Mastodon.RedirectURI != ""
+	case "bluesky":
+		return c.Bluesky.Enabled
+	case "pinterest":
+		return c.Pinterest.AppID != "" && c.Pinterest.AppSecret != "" && c.Pinterest.RedirectURI != ""
+	case "reddit":
+		return c.Reddit.ClientID != "" && c.Reddit.ClientSecret != "" && c.Reddit.RedirectURI != ""
+	case "telegram":
+		return c.Telegram.Enabled
+	case "discord":
+		return c.Discord.Enabled
+	case "slack":
+		return c.Slack.Enabled
+	case "webhook":
+		return c.Webhook.Enabled
+	case "mock":
+		return c.Mock.Enabled
+	}
+	return false
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Environment == "development"
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 )
@@ -16,7 +17,8 @@ func (db *DB) RunMigrations() error {
 		createPlatformConnectionsTable,
 		createOAuthSessionsTable,
 		createPostMediaItemsTable,
-		createIndexes,
+		createMastodonAppsTable,
+		createFacebookPagesTable,
 	}
 
 	for i, migration := range migrations {
@@ -25,10 +27,84 @@ func (db *DB) RunMigrations() error {
 		}
 	}
 
+	// Columns added after a table was first created; CREATE TABLE IF NOT EXISTS
+	// does not touch existing databases, so these are added separately
+	for _, col := range addedColumns {
+		if err := db.addColumnIfMissing(col.table, col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	// Indexes last, since they may reference added columns
+	if _, err := db.Exec(createIndexes); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// addedColumn describes a column added to an existing table
+type addedColumn struct {
+	table      string
+	name       string
+	definition string
+}
+
+// addedColumns lists columns that must also exist on databases created by older versions
+var addedColumns = []addedColumn{
+	{"post_media_items", "processed_url", "TEXT"},
+	{"post_media_items", "processed_width", "INTEGER"},
+	{"post_media_items", "processed_height", "INTEGER"},
+	{"post_media_items", "processed_size", "INTEGER"},
+	{"posts", "progress_stage", "TEXT"},
+	{"posts", "progress_percent", "INTEGER NOT NULL DEFAULT 0"},
+	{"posts", "publication_id", "TEXT"},
+	{"posts", "error_code", "TEXT"},
+	{"posts", "error_detail", "TEXT"},
+	{"posts", "deferred_until", "TIMESTAMP"},
+	{"posts", "share_url", "TEXT"},
+	{"posts", "settings", "TEXT"},
+	{"oauth_sessions", "instance_url", "TEXT"},
+	{"tokens", "instance_url", "TEXT"},
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func (db *DB) addColumnIfMissing(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	log.Printf("Added column %s.%s", table, column)
+	return nil
+}
+
 const createUsersTable = `
 CREATE TABLE IF NOT EXISTS users (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -54,6 +130,7 @@ CREATE TABLE IF NOT EXISTS tokens (
     scope TEXT,
     platform TEXT DEFAULT 'tiktok',
     code_verifier TEXT,
+    instance_url TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -64,15 +141,23 @@ const createPostsTable = `
 CREATE TABLE IF NOT EXISTS posts (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     user_id INTEGER NOT NULL,
+    publication_id TEXT,
     tiktok_post_id TEXT,
     platform_post_id TEXT,
+    share_url TEXT,
     video_url TEXT NOT NULL,
     caption TEXT,
     platform TEXT DEFAULT 'tiktok',
     media_type TEXT DEFAULT 'video',
     status TEXT DEFAULT 'pending',
     direct_post BOOLEAN DEFAULT TRUE,
+    error_code TEXT,
     error_message TEXT,
+    error_detail TEXT,
+    progress_stage TEXT,
+    progress_percent INTEGER NOT NULL DEFAULT 0,
+    deferred_until TIMESTAMP,
+    settings TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     published_at TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -83,6 +168,7 @@ const createIndexes = `
 CREATE INDEX IF NOT EXISTS idx_tokens_user_id ON tokens(user_id);
 CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
 CREATE INDEX IF NOT EXISTS idx_posts_status ON posts(status);
+CREATE INDEX IF NOT EXISTS idx_posts_publication ON posts(publication_id);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_users_platform_user ON users(platform, platform_user_id);
 CREATE INDEX IF NOT EXISTS idx_tokens_user_platform ON tokens(user_id, platform);
 CREATE INDEX IF NOT EXISTS idx_posts_user_platform ON posts(user_id, platform);
@@ -92,6 +178,7 @@ CREATE INDEX IF NOT EXISTS idx_platform_connections_platform ON platform_connect
 CREATE INDEX IF NOT EXISTS idx_oauth_sessions_state ON oauth_sessions(state);
 CREATE INDEX IF NOT EXISTS idx_oauth_sessions_expires ON oauth_sessions(expires_at);
 CREATE INDEX IF NOT EXISTS idx_post_media_items_post ON post_media_items(post_id);
+CREATE INDEX IF NOT EXISTS idx_facebook_pages_facebook_user ON facebook_pages(facebook_user_id);
 `
 
 // Create post_media_items table for multiple media per post (carousel support)
@@ -103,6 +190,10 @@ CREATE TABLE IF NOT EXISTS post_media_items (
     media_type TEXT NOT NULL,
     position INTEGER NOT NULL DEFAULT 0,
     platform_media_id TEXT,
+    processed_url TEXT,
+    processed_width INTEGER,
+    processed_height INTEGER,
+    processed_size INTEGER,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
 );
@@ -134,8 +225,40 @@ CREATE TABLE IF NOT EXISTS oauth_sessions (
     code_verifier TEXT,
     platform TEXT NOT NULL,
     user_id INTEGER,
+    instance_url TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     expires_at TIMESTAMP NOT NULL,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 `
+
+// Create mastodon_apps table for the OAuth app registered on each Mastodon instance
+const createMastodonAppsTable = `
+CREATE TABLE IF NOT EXISTS mastodon_apps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    instance_url TEXT UNIQUE NOT NULL,
+    client_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scopes TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// Create facebook_pages table for the Facebook Pages users connected and their page tokens
+const createFacebookPagesTable = `
+CREATE TABLE IF NOT EXISTS facebook_pages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    facebook_user_id TEXT NOT NULL,
+    page_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    category TEXT,
+    picture_url TEXT,
+    access_token TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, page_id)
+);
+`
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FacebookPage is a Facebook Page a user connected for publishing
+// Facebook posts as the page with its own access token, which is stored here; page tokens
+// obtained with a long-lived user token don't expire
+type FacebookPage struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	FacebookUserID string    `json:"facebook_user_id"` // The Facebook user the page token was issued to
+	PageID         string    `json:"page_id"`
+	Name           string    `json:"name"`
+	Category       string    `json:"category"`
+	PictureURL     string    `json:"picture_url"`
+	AccessToken    string    `json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type FacebookPageRepository struct {
+	DB *sql.DB
+}
+
+// NewFacebookPageRepository creates a new Facebook page repository
+func NewFacebookPageRepository(db *sql.DB) *FacebookPageRepository {
+	return &FacebookPageRepository{DB: db}
+}
+
+// GetByUserID retrieves the pages a user connected, ordered by name
+func (r *FacebookPageRepository) GetByUserID(userID int64) ([]*FacebookPage, error) {
+	query := `
+		SELECT id, user_id, facebook_user_id, page_id, name, category, picture_url, access_token, created_at, updated_at
+		FROM facebook_pages WHERE user_id = ? ORDER BY name, page_id
+	`
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get facebook pages: %w", err)
+	}
+	defer rows.Close()
+
+	var pages []*FacebookPage
+	for rows.Next() {
+		page := &FacebookPage{}
+		if err := rows.Scan(
+			&page.ID, &page.UserID, &page.FacebookUserID, &page.PageID, &page.Name, &page.Category,
+			&page.PictureURL, &page.AccessToken, &page.CreatedAt, &page.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan facebook page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get facebook pages: %w", err)
+	}
+	return pages, nil
+}
+
+// GetByFacebookUserID retrieves the pages connected with a Facebook user's tokens
+// A Facebook account connected by several users has its pages listed once per user
+func (r *FacebookPageRepository) GetByFacebookUserID(facebookUserID string) ([]*FacebookPage, error) {
+	query := `
+		SELECT id, user_id, facebook_user_id, page_id, name, category, picture_url, access_token, created_at, updated_at
+		FROM facebook_pages WHERE facebook_user_id = ? ORDER BY updated_at DESC
+	`
+	rows, err := r.DB.Query(query, facebookUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get facebook pages: %w", err)
+	}
+	defer rows.Close()
+
+	var pages []*FacebookPage
+	seen := make(map[string]bool)
+	for rows.Next() {
+		page := &FacebookPage{}
+		if err := rows.Scan(
+			&page.ID, &page.UserID, &page.FacebookUserID, &page.PageID, &page.Name, &page.Category,
+			&page.PictureURL, &page.AccessToken, &page.CreatedAt, &page.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan facebook page: %w", err)
+		}
+		// Keep the most recently stored token of each page
+		if seen[page.PageID] {
+			continue
+		}
+		seen[page.PageID] = true
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get facebook pages: %w", err)
+	}
+	return pages, nil
+}
+
+// ReplaceForUser replaces the pages a user connected with pages
+func (r *FacebookPageRepository) ReplaceForUser(userID int64, pages []*FacebookPage) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM facebook_pages WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete facebook pages: %w", err)
+	}
+
+	query := `
+		INSERT INTO facebook_pages (user_id, facebook_user_id, page_id, name, category, picture_url, access_token, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	for _, page := range pages {
+		result, err := tx.Exec(query, userID, page.FacebookUserID, page.PageID, page.Name, page.Category, page.PictureURL, page.AccessToken, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create facebook page: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		page.ID = id
+		page.UserID = userID
+		page.CreatedAt = now
+		page.UpdatedAt = now
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit facebook pages: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUserID deletes the pages a user connected
+func (r *FacebookPageRepository) DeleteByUserID(userID int64) error {
+	if _, err := r.DB.Exec("DELETE FROM facebook_pages WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete facebook pages: %w", err)
+	}
+	return nil
+}
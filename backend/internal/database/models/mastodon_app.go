@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MastodonApp is the OAuth app registered on a Mastodon instance
+// Every instance issues its own client credentials, so the app is registered the first time a
+// user logs in with the instance and reused afterwards
+type MastodonApp struct {
+	ID           int64     `json:"id"`
+	InstanceURL  string    `json:"instance_url"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"-"`
+	RedirectURI  string    `json:"redirect_uri"`
+	Scopes       string    `json:"scopes"` // Space-separated, as registered
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type MastodonAppRepository struct {
+	DB *sql.DB
+}
+
+// NewMastodonAppRepository creates a new Mastodon app repository
+func NewMastodonAppRepository(db *sql.DB) *MastodonAppRepository {
+	return &MastodonAppRepository{DB: db}
+}
+
+// GetByInstance retrieves the app registered on an instance
+// Returns nil without an error if no app is registered there yet
+func (r *MastodonAppRepository) GetByInstance(instanceURL string) (*MastodonApp, error) {
+	query := `
+		SELECT id, instance_url, client_id, client_secret, redirect_uri, scopes, created_at
+		FROM mastodon_apps WHERE instance_url = ?
+	`
+	app := &MastodonApp{}
+	err := r.DB.QueryRow(query, instanceURL).Scan(
+		&app.ID, &app.InstanceURL, &app.ClientID, &app.ClientSecret, &app.RedirectURI, &app.Scopes, &app.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mastodon app: %w", err)
+	}
+	return app, nil
+}
+
+// Save stores the app registered on an instance, replacing an earlier registration
+func (r *MastodonAppRepository) Save(app *MastodonApp) error {
+	query := `
+		INSERT INTO mastodon_apps (instance_url, client_id, client_secret, redirect_uri, scopes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(instance_url) DO UPDATE SET
+			client_id = excluded.client_id,
+			client_secret = excluded.client_secret,
+			redirect_uri = excluded.redirect_uri,
+			scopes = excluded.scopes,
+			created_at = excluded.created_at
+	`
+	now := time.Now()
+	if _, err := r.DB.Exec(query, app.InstanceURL, app.ClientID, app.ClientSecret, app.RedirectURI, app.Scopes, now); err != nil {
+		return fmt.Errorf("failed to save mastodon app: %w", err)
+	}
+	app.CreatedAt = now
+	return nil
+}
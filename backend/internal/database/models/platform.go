@@ -7,7 +7,21 @@ const (
 	PlatformTikTok    Platform = "tiktok"
 	PlatformX         Platform = "x"
 	PlatformInstagram Platform = "instagram"
+	PlatformThreads   Platform = "threads"
+	PlatformFacebook  Platform = "facebook"
 	PlatformYouTube   Platform = "youtube"
+	PlatformLinkedIn  Platform = "linkedin"
+	PlatformMastodon  Platform = "mastodon"
+	PlatformBluesky   Platform = "bluesky"
+	PlatformPinterest Platform = "pinterest"
+	PlatformReddit    Platform = "reddit"
+	PlatformTelegram  Platform = "telegram" // A channel or group a bot posts to
+	PlatformDiscord   Platform = "discord"  // A channel's incoming webhook
+	PlatformSlack     Platform = "slack"    // A channel's incoming webhook
+	PlatformWebhook   Platform = "webhook"  // An endpoint of the user's own systems, sent signed deliveries
+
+	// PlatformMock is the sandbox platform for local development and demos
+	PlatformMock Platform = "mock"
 )
 
 // String returns the string representation of the platform
@@ -18,7 +32,7 @@ func (p Platform) String() string {
 // IsValid checks if the platform is valid
 func (p Platform) IsValid() bool {
 	switch p {
-	case PlatformTikTok, PlatformX, PlatformInstagram, PlatformYouTube:
+	case PlatformTikTok, PlatformX, PlatformInstagram, PlatformThreads, PlatformFacebook, PlatformYouTube, PlatformLinkedIn, PlatformMastodon, PlatformBluesky, PlatformPinterest, PlatformReddit, PlatformTelegram, PlatformDiscord, PlatformSlack, PlatformWebhook, PlatformMock:
 		return true
 	default:
 		return false
@@ -3,6 +3,8 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,22 +16,35 @@ const (
 	PostStatusPublished   PostStatus = "published"
 	PostStatusSentToInbox PostStatus = "sent_to_inbox"
 	PostStatusFailed      PostStatus = "failed"
+	PostStatusCancelled   PostStatus = "cancelled"
+	PostStatusDeferred    PostStatus = "deferred" // Waiting for the platform's rate limit or posting cap to reset, or for the platform to recover
+	PostStatusDeleted     PostStatus = "deleted"  // Published, then deleted from the platform by the user
 )
 
 type Post struct {
-	ID             int64      `json:"id"`
-	UserID         int64      `json:"user_id"`
-	Platform       Platform   `json:"platform"`
-	TikTokPostID   string     `json:"tiktok_post_id,omitempty"` // Deprecated: Use PlatformPostID
-	PlatformPostID string     `json:"platform_post_id,omitempty"`
-	VideoURL       string     `json:"video_url"`
-	Caption        string     `json:"caption"`
-	MediaType      string     `json:"media_type"` // video, image, text
-	Status         PostStatus `json:"status"`
-	DirectPost     *bool      `json:"direct_post,omitempty"` // true = Direct Post, false = Send to Inbox
-	ErrorMessage   string     `json:"error_message,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	PublishedAt    *time.Time `json:"published_at,omitempty"`
+	ID              int64      `json:"id"`
+	UserID          int64      `json:"user_id"`
+	PublicationID   string     `json:"publication_id,omitempty"` // Groups the per-platform posts of one publication
+	Platform        Platform   `json:"platform"`
+	TikTokPostID    string     `json:"tiktok_post_id,omitempty"` // Deprecated: Use PlatformPostID
+	PlatformPostID  string     `json:"platform_post_id,omitempty"`
+	ShareURL        string     `json:"share_url,omitempty"` // Link to the published post, as the platform reported it
+	VideoURL        string     `json:"video_url"`
+	Caption         string     `json:"caption"`
+	MediaType       string     `json:"media_type"` // video, image, text
+	Status          PostStatus `json:"status"`
+	DirectPost      *bool      `json:"direct_post,omitempty"`    // true = Direct Post, false = Send to Inbox
+	ErrorCode       string     `json:"error_code,omitempty"`     // Stable classification of a failure, see platformapi.ErrorCode
+	ErrorMessage    string     `json:"error_message,omitempty"`  // Human-readable message for end users
+	ErrorDetail     string     `json:"error_detail,omitempty"`   // The platform's own error, for debugging
+	ProgressStage   string     `json:"progress_stage,omitempty"` // uploading, processing
+	ProgressPercent int        `json:"progress_percent"`
+	DeferredUntil   *time.Time `json:"deferred_until,omitempty"` // When a deferred post will be tried again
+	Settings        string     `json:"-"`                        // Validated platform settings as JSON, to resume the post after a restart
+	CreatedAt       time.Time  `json:"created_at"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+
+	MediaItems []*PostMediaItem `json:"media_items,omitempty"` // Loaded on demand, not stored in the posts table
 }
 
 type PostRepository struct {
@@ -44,15 +59,31 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 // Create creates a new post
 func (r *PostRepository) Create(post *Post) error {
 	query := `
-		INSERT INTO posts (user_id, video_url, caption, status, direct_post, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (user_id, publication_id, platform, video_url, caption, media_type, status, direct_post, settings, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
 	directPost := true // default to direct post
 	if post.DirectPost != nil {
 		directPost = *post.DirectPost
 	}
-	result, err := r.DB.Exec(query, post.UserID, post.VideoURL, post.Caption, post.Status, directPost, now)
+	var publicationID sql.NullString
+	if post.PublicationID != "" {
+		publicationID = sql.NullString{String: post.PublicationID, Valid: true}
+	}
+	platform := post.Platform
+	if platform == "" {
+		platform = PlatformTikTok
+	}
+	mediaType := post.MediaType
+	if mediaType == "" {
+		mediaType = "video"
+	}
+	var settings sql.NullString
+	if post.Settings != "" {
+		settings = sql.NullString{String: post.Settings, Valid: true}
+	}
+	result, err := r.DB.Exec(query, post.UserID, publicationID, platform, post.VideoURL, post.Caption, mediaType, post.Status, directPost, settings, now)
 	if err != nil {
 		return fmt.Errorf("failed to create post: %w", err)
 	}
@@ -70,17 +101,17 @@ func (r *PostRepository) Create(post *Post) error {
 // GetByID retrieves a post by ID
 func (r *PostRepository) GetByID(id int64) (*Post, error) {
 	query := `
-		SELECT id, user_id, platform, tiktok_post_id, platform_post_id, video_url, caption, media_type, status, direct_post, error_message, created_at, published_at
+		SELECT id, user_id, publication_id, platform, tiktok_post_id, platform_post_id, share_url, video_url, caption, media_type, status, direct_post, error_code, error_message, error_detail, progress_stage, progress_percent, deferred_until, created_at, published_at
 		FROM posts WHERE id = ?
 	`
 	post := &Post{}
-	var platform, tiktokPostID, platformPostID, mediaType, errorMessage sql.NullString
-	var publishedAt sql.NullTime
+	var publicationID, platform, tiktokPostID, platformPostID, shareURL, mediaType, errorCode, errorMessage, errorDetail, progressStage sql.NullString
+	var publishedAt, deferredUntil sql.NullTime
 	var directPost sql.NullBool
 
 	err := r.DB.QueryRow(query, id).Scan(
-		&post.ID, &post.UserID, &platform, &tiktokPostID, &platformPostID, &post.VideoURL, &post.Caption,
-		&mediaType, &post.Status, &directPost, &errorMessage, &post.CreatedAt, &publishedAt,
+		&post.ID, &post.UserID, &publicationID, &platform, &tiktokPostID, &platformPostID, &shareURL, &post.VideoURL, &post.Caption,
+		&mediaType, &post.Status, &directPost, &errorCode, &errorMessage, &errorDetail, &progressStage, &post.ProgressPercent, &deferredUntil, &post.CreatedAt, &publishedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("post not found")
@@ -92,12 +123,18 @@ func (r *PostRepository) GetByID(id int64) (*Post, error) {
 	if platform.Valid {
 		post.Platform = Platform(platform.String)
 	}
+	if publicationID.Valid {
+		post.PublicationID = publicationID.String
+	}
 	if tiktokPostID.Valid {
 		post.TikTokPostID = tiktokPostID.String
 	}
 	if platformPostID.Valid {
 		post.PlatformPostID = platformPostID.String
 	}
+	if shareURL.Valid {
+		post.ShareURL = shareURL.String
+	}
 	if mediaType.Valid {
 		post.MediaType = mediaType.String
 	}
@@ -105,12 +142,24 @@ func (r *PostRepository) GetByID(id int64) (*Post, error) {
 		post.DirectPost = new(bool)
 		*post.DirectPost = directPost.Bool
 	}
+	if errorCode.Valid {
+		post.ErrorCode = errorCode.String
+	}
 	if errorMessage.Valid {
 		post.ErrorMessage = errorMessage.String
 	}
+	if errorDetail.Valid {
+		post.ErrorDetail = errorDetail.String
+	}
+	if progressStage.Valid {
+		post.ProgressStage = progressStage.String
+	}
 	if publishedAt.Valid {
 		post.PublishedAt = &publishedAt.Time
 	}
+	if deferredUntil.Valid {
+		post.DeferredUntil = &deferredUntil.Time
+	}
 
 	return post, nil
 }
@@ -118,7 +167,7 @@ func (r *PostRepository) GetByID(id int64) (*Post, error) {
 // GetByUserID retrieves all posts for a user
 func (r *PostRepository) GetByUserID(userID int64, limit, offset int) ([]*Post, error) {
 	query := `
-		SELECT id, user_id, platform, tiktok_post_id, platform_post_id, video_url, caption, media_type, status, direct_post, error_message, created_at, published_at
+		SELECT id, user_id, publication_id, platform, tiktok_post_id, platform_post_id, share_url, video_url, caption, media_type, status, direct_post, error_code, error_message, error_detail, progress_stage, progress_percent, deferred_until, created_at, published_at
 		FROM posts
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -133,13 +182,13 @@ func (r *PostRepository) GetByUserID(userID int64, limit, offset int) ([]*Post,
 	var posts []*Post
 	for rows.Next() {
 		post := &Post{}
-		var platform, tiktokPostID, platformPostID, mediaType, errorMessage sql.NullString
-		var publishedAt sql.NullTime
+		var publicationID, platform, tiktokPostID, platformPostID, shareURL, mediaType, errorCode, errorMessage, errorDetail, progressStage sql.NullString
+		var publishedAt, deferredUntil sql.NullTime
 		var directPost sql.NullBool
 
 		err := rows.Scan(
-			&post.ID, &post.UserID, &platform, &tiktokPostID, &platformPostID, &post.VideoURL, &post.Caption,
-			&mediaType, &post.Status, &directPost, &errorMessage, &post.CreatedAt, &publishedAt,
+			&post.ID, &post.UserID, &publicationID, &platform, &tiktokPostID, &platformPostID, &shareURL, &post.VideoURL, &post.Caption,
+			&mediaType, &post.Status, &directPost, &errorCode, &errorMessage, &errorDetail, &progressStage, &post.ProgressPercent, &deferredUntil, &post.CreatedAt, &publishedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
@@ -148,12 +197,18 @@ func (r *PostRepository) GetByUserID(userID int64, limit, offset int) ([]*Post,
 		if platform.Valid {
 			post.Platform = Platform(platform.String)
 		}
+		if publicationID.Valid {
+			post.PublicationID = publicationID.String
+		}
 		if tiktokPostID.Valid {
 			post.TikTokPostID = tiktokPostID.String
 		}
 		if platformPostID.Valid {
 			post.PlatformPostID = platformPostID.String
 		}
+		if shareURL.Valid {
+			post.ShareURL = shareURL.String
+		}
 		if directPost.Valid {
 			post.DirectPost = new(bool)
 			*post.DirectPost = directPost.Bool
@@ -161,12 +216,24 @@ func (r *PostRepository) GetByUserID(userID int64, limit, offset int) ([]*Post,
 		if mediaType.Valid {
 			post.MediaType = mediaType.String
 		}
+		if errorCode.Valid {
+			post.ErrorCode = errorCode.String
+		}
 		if errorMessage.Valid {
 			post.ErrorMessage = errorMessage.String
 		}
+		if errorDetail.Valid {
+			post.ErrorDetail = errorDetail.String
+		}
+		if progressStage.Valid {
+			post.ProgressStage = progressStage.String
+		}
 		if publishedAt.Valid {
 			post.PublishedAt = &publishedAt.Time
 		}
+		if deferredUntil.Valid {
+			post.DeferredUntil = &deferredUntil.Time
+		}
 
 		posts = append(posts, post)
 	}
@@ -192,11 +259,157 @@ func (r *PostRepository) UpdateStatus(id int64, status PostStatus, errorMessage
 	return nil
 }
 
+// MarkFailed marks a post as failed with a classified error
+func (r *PostRepository) MarkFailed(id int64, errorCode, errorMessage, errorDetail string) error {
+	query := `
+		UPDATE posts
+		SET status = ?, error_code = ?, error_message = ?, error_detail = ?
+		WHERE id = ?
+	`
+	_, err := r.DB.Exec(query, PostStatusFailed, errorCode, errorMessage, errorDetail, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark post as failed: %w", err)
+	}
+	return nil
+}
+
+// MarkDeferred marks a post as waiting until the given time, with a message saying why
+func (r *PostRepository) MarkDeferred(id int64, until time.Time, message string) error {
+	query := `
+		UPDATE posts
+		SET status = ?, deferred_until = ?, error_message = ?
+		WHERE id = ?
+	`
+	_, err := r.DB.Exec(query, PostStatusDeferred, until, message, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark post as deferred: %w", err)
+	}
+	return nil
+}
+
+// MarkResumed moves a deferred post back to processing
+func (r *PostRepository) MarkResumed(id int64) error {
+	query := `
+		UPDATE posts
+		SET status = ?, deferred_until = NULL, error_message = NULL
+		WHERE id = ?
+	`
+	_, err := r.DB.Exec(query, PostStatusProcessing, id)
+	if err != nil {
+		return fmt.Errorf("failed to resume post: %w", err)
+	}
+	return nil
+}
+
+// GetByStatus retrieves the posts of all users that have one of the given statuses, oldest first
+// Only the fields needed to resume a post are loaded: ID, user, platform, video URL, status,
+// deferred until and settings
+func (r *PostRepository) GetByStatus(statuses ...PostStatus) ([]*Post, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(statuses))
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args[i] = status
+	}
+	query := `
+		SELECT id, user_id, platform, video_url, status, deferred_until, settings
+		FROM posts
+		WHERE status IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY id
+	`
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post := &Post{}
+		var platform, settings sql.NullString
+		var deferredUntil sql.NullTime
+		if err := rows.Scan(&post.ID, &post.UserID, &platform, &post.VideoURL, &post.Status, &deferredUntil, &settings); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		if platform.Valid {
+			post.Platform = Platform(platform.String)
+		}
+		if deferredUntil.Valid {
+			post.DeferredUntil = &deferredUntil.Time
+		}
+		if settings.Valid {
+			post.Settings = settings.String
+		}
+		posts = append(posts, post)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// GetPublishTimes returns when the posts a user published or sent to the inbox of a platform
+// since the given time reached the platform, oldest first
+// Posts sent to the inbox have no publish time, so their creation time is used; deleted posts
+// still count, platforms count them against their caps too
+func (r *PostRepository) GetPublishTimes(userID int64, platform Platform, since time.Time) ([]time.Time, error) {
+	query := `
+		SELECT created_at, published_at
+		FROM posts
+		WHERE user_id = ? AND platform = ? AND status IN (?, ?, ?) AND (published_at >= ? OR created_at >= ?)
+	`
+	rows, err := r.DB.Query(query, userID, platform, PostStatusPublished, PostStatusSentToInbox, PostStatusDeleted, since, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query publish times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var createdAt time.Time
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&createdAt, &publishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan publish time: %w", err)
+		}
+		at := createdAt
+		if publishedAt.Valid {
+			at = publishedAt.Time
+		}
+		if !at.Before(since) {
+			times = append(times, at)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating publish times: %w", err)
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// UpdateProgress records the upload or processing progress of a post
+func (r *PostRepository) UpdateProgress(id int64, stage string, percent int) error {
+	query := `
+		UPDATE posts
+		SET progress_stage = ?, progress_percent = ?
+		WHERE id = ?
+	`
+	_, err := r.DB.Exec(query, stage, percent, id)
+	if err != nil {
+		return fmt.Errorf("failed to update post progress: %w", err)
+	}
+	return nil
+}
+
 // MarkPublished marks a post as published
 func (r *PostRepository) MarkPublished(id int64, tiktokPostID string) error {
 	query := `
 		UPDATE posts
-		SET status = ?, tiktok_post_id = ?, published_at = ?, error_message = NULL
+		SET status = ?, tiktok_post_id = ?, published_at = ?, error_code = NULL, error_message = NULL, error_detail = NULL
 		WHERE id = ?
 	`
 	now := time.Now()
@@ -217,6 +430,44 @@ func (r *PostRepository) Delete(id int64) error {
 	return nil
 }
 
+// GetByPublicationID retrieves the per-platform posts of a publication
+func (r *PostRepository) GetByPublicationID(userID int64, publicationID string) ([]*Post, error) {
+	query := `
+		SELECT id
+		FROM posts
+		WHERE user_id = ? AND publication_id = ?
+		ORDER BY id ASC
+	`
+	rows, err := r.DB.Query(query, userID, publicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posts: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	posts := make([]*Post, 0, len(ids))
+	for _, id := range ids {
+		post, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
 // CountByUserID counts posts for a user
 func (r *PostRepository) CountByUserID(userID int64) (int, error) {
 	query := "SELECT COUNT(*) FROM posts WHERE user_id = ?"
@@ -231,7 +482,7 @@ func (r *PostRepository) CountByUserID(userID int64) (int, error) {
 // GetByUserIDAndPlatform retrieves all posts for a user and specific platform
 func (r *PostRepository) GetByUserIDAndPlatform(userID int64, platform Platform, limit, offset int) ([]*Post, error) {
 	query := `
-		SELECT id, user_id, platform, tiktok_post_id, platform_post_id, video_url, caption, media_type, status, direct_post, error_message, created_at, published_at
+		SELECT id, user_id, publication_id, platform, tiktok_post_id, platform_post_id, share_url, video_url, caption, media_type, status, direct_post, error_code, error_message, error_detail, progress_stage, progress_percent, deferred_until, created_at, published_at
 		FROM posts
 		WHERE user_id = ? AND platform = ?
 		ORDER BY created_at DESC
@@ -246,18 +497,21 @@ func (r *PostRepository) GetByUserIDAndPlatform(userID int64, platform Platform,
 	var posts []*Post
 	for rows.Next() {
 		post := &Post{}
-		var tiktokPostID, platformPostID, errorMessage sql.NullString
-		var publishedAt sql.NullTime
+		var publicationID, tiktokPostID, platformPostID, shareURL, errorCode, errorMessage, errorDetail, progressStage sql.NullString
+		var publishedAt, deferredUntil sql.NullTime
 		var directPost sql.NullBool
 
 		err := rows.Scan(
-			&post.ID, &post.UserID, &post.Platform, &tiktokPostID, &platformPostID, &post.VideoURL, &post.Caption,
-			&post.MediaType, &post.Status, &directPost, &errorMessage, &post.CreatedAt, &publishedAt,
+			&post.ID, &post.UserID, &publicationID, &post.Platform, &tiktokPostID, &platformPostID, &shareURL, &post.VideoURL, &post.Caption,
+			&post.MediaType, &post.Status, &directPost, &errorCode, &errorMessage, &errorDetail, &progressStage, &post.ProgressPercent, &deferredUntil, &post.CreatedAt, &publishedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
 
+		if publicationID.Valid {
+			post.PublicationID = publicationID.String
+		}
 		if tiktokPostID.Valid {
 			post.TikTokPostID = tiktokPostID.String
 		}
@@ -268,12 +522,27 @@ func (r *PostRepository) GetByUserIDAndPlatform(userID int64, platform Platform,
 		if platformPostID.Valid {
 			post.PlatformPostID = platformPostID.String
 		}
+		if shareURL.Valid {
+			post.ShareURL = shareURL.String
+		}
+		if errorCode.Valid {
+			post.ErrorCode = errorCode.String
+		}
 		if errorMessage.Valid {
 			post.ErrorMessage = errorMessage.String
 		}
+		if errorDetail.Valid {
+			post.ErrorDetail = errorDetail.String
+		}
+		if progressStage.Valid {
+			post.ProgressStage = progressStage.String
+		}
 		if publishedAt.Valid {
 			post.PublishedAt = &publishedAt.Time
 		}
+		if deferredUntil.Valid {
+			post.DeferredUntil = &deferredUntil.Time
+		}
 
 		posts = append(posts, post)
 	}
@@ -285,15 +554,20 @@ func (r *PostRepository) GetByUserIDAndPlatform(userID int64, platform Platform,
 	return posts, nil
 }
 
-// MarkPublishedWithPlatform marks a post as published with platform-specific post ID
-func (r *PostRepository) MarkPublishedWithPlatform(id int64, platformPostID string) error {
+// MarkPublishedWithPlatform marks a post as published with platform-specific post ID and the
+// link to the post the platform returned, which may be empty
+func (r *PostRepository) MarkPublishedWithPlatform(id int64, platformPostID, shareURL string) error {
 	query := `
 		UPDATE posts
-		SET status = ?, platform_post_id = ?, published_at = ?, error_message = NULL
+		SET status = ?, platform_post_id = ?, share_url = ?, published_at = ?, error_code = NULL, error_message = NULL, error_detail = NULL
 		WHERE id = ?
 	`
+	var storedShareURL sql.NullString
+	if shareURL != "" {
+		storedShareURL = sql.NullString{String: shareURL, Valid: true}
+	}
 	now := time.Now()
-	_, err := r.DB.Exec(query, PostStatusPublished, platformPostID, now, id)
+	_, err := r.DB.Exec(query, PostStatusPublished, platformPostID, storedShareURL, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to mark post as published: %w", err)
 	}
@@ -304,7 +578,7 @@ func (r *PostRepository) MarkPublishedWithPlatform(id int64, platformPostID stri
 func (r *PostRepository) MarkSentToInboxWithPlatform(id int64, platformPostID string) error {
 	query := `
 		UPDATE posts
-		SET status = ?, platform_post_id = ?, error_message = NULL
+		SET status = ?, platform_post_id = ?, error_code = NULL, error_message = NULL, error_detail = NULL
 		WHERE id = ?
 	`
 	_, err := r.DB.Exec(query, PostStatusSentToInbox, platformPostID, id)
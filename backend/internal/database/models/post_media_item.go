@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostMediaItem represents a single media file attached to a post
+// ProcessedURL is set when the original was resized or re-encoded to fit the post's platform
+type PostMediaItem struct {
+	ID              int64     `json:"id"`
+	PostID          int64     `json:"post_id"`
+	MediaURL        string    `json:"media_url"`
+	MediaType       string    `json:"media_type"`
+	Position        int       `json:"position"`
+	PlatformMediaID string    `json:"platform_media_id,omitempty"`
+	ProcessedURL    string    `json:"processed_url,omitempty"`
+	ProcessedWidth  int       `json:"processed_width,omitempty"`
+	ProcessedHeight int       `json:"processed_height,omitempty"`
+	ProcessedSize   int64     `json:"processed_size,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type PostMediaItemRepository struct {
+	DB *sql.DB
+}
+
+// NewPostMediaItemRepository creates a new post media item repository
+func NewPostMediaItemRepository(db *sql.DB) *PostMediaItemRepository {
+	return &PostMediaItemRepository{DB: db}
+}
+
+// Create creates a new post media item
+func (r *PostMediaItemRepository) Create(item *PostMediaItem) error {
+	query := `
+		INSERT INTO post_media_items (post_id, media_url, media_type, position, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	result, err := r.DB.Exec(query, item.PostID, item.MediaURL, item.MediaType, item.Position, now)
+	if err != nil {
+		return fmt.Errorf("failed to create post media item: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	item.ID = id
+	item.CreatedAt = now
+	return nil
+}
+
+// GetByPostID retrieves all media items of a post ordered by position
+func (r *PostMediaItemRepository) GetByPostID(postID int64) ([]*PostMediaItem, error) {
+	query := `
+		SELECT id, post_id, media_url, media_type, position, platform_media_id, processed_url, processed_width, processed_height, processed_size, created_at
+		FROM post_media_items
+		WHERE post_id = ?
+		ORDER BY position ASC
+	`
+	rows, err := r.DB.Query(query, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post media items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*PostMediaItem
+	for rows.Next() {
+		item := &PostMediaItem{}
+		var platformMediaID, processedURL sql.NullString
+		var processedWidth, processedHeight, processedSize sql.NullInt64
+
+		err := rows.Scan(
+			&item.ID, &item.PostID, &item.MediaURL, &item.MediaType, &item.Position, &platformMediaID,
+			&processedURL, &processedWidth, &processedHeight, &processedSize, &item.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post media item: %w", err)
+		}
+
+		if platformMediaID.Valid {
+			item.PlatformMediaID = platformMediaID.String
+		}
+		if processedURL.Valid {
+			item.ProcessedURL = processedURL.String
+		}
+		if processedWidth.Valid {
+			item.ProcessedWidth = int(processedWidth.Int64)
+		}
+		if processedHeight.Valid {
+			item.ProcessedHeight = int(processedHeight.Int64)
+		}
+		if processedSize.Valid {
+			item.ProcessedSize = processedSize.Int64
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post media items: %w", err)
+	}
+
+	return items, nil
+}
+
+// UpdateProcessed stores the processed variant of a media item
+func (r *PostMediaItemRepository) UpdateProcessed(id int64, processedURL string, width, height int, size int64) error {
+	query := `
+		UPDATE post_media_items
+		SET processed_url = ?, processed_width = ?, processed_height = ?, processed_size = ?
+		WHERE id = ?
+	`
+	_, err := r.DB.Exec(query, processedURL, width, height, size, id)
+	if err != nil {
+		return fmt.Errorf("failed to update processed media: %w", err)
+	}
+	return nil
+}
+
+// UpdatePlatformMediaID stores the ID the platform assigned to an uploaded media item
+func (r *PostMediaItemRepository) UpdatePlatformMediaID(id int64, platformMediaID string) error {
+	query := "UPDATE post_media_items SET platform_media_id = ? WHERE id = ?"
+	_, err := r.DB.Exec(query, platformMediaID, id)
+	if err != nil {
+		return fmt.Errorf("failed to update platform media id: %w", err)
+	}
+	return nil
+}
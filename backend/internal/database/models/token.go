@@ -16,6 +16,7 @@ type Token struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 	Scope        string    `json:"scope"`
 	CodeVerifier string    `json:"code_verifier,omitempty"` // For PKCE (X platform)
+	InstanceURL  string    `json:"instance_url,omitempty"`  // Server that issued the token, for platforms without a central one (Mastodon)
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -32,13 +33,13 @@ func NewTokenRepository(db *sql.DB) *TokenRepository {
 // Create creates a new token
 func (r *TokenRepository) Create(token *Token) error {
 	query := `
-		INSERT INTO tokens (user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO tokens (user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, instance_url, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
 	result, err := r.DB.Exec(query,
 		token.UserID, token.Platform, token.AccessToken, token.RefreshToken,
-		token.TokenType, token.ExpiresAt, token.Scope, token.CodeVerifier, now, now,
+		token.TokenType, token.ExpiresAt, token.Scope, token.CodeVerifier, token.InstanceURL, now, now,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create token: %w", err)
@@ -58,7 +59,7 @@ func (r *TokenRepository) Create(token *Token) error {
 // GetByUserID retrieves the most recent token for a user
 func (r *TokenRepository) GetByUserID(userID int64) (*Token, error) {
 	query := `
-		SELECT id, user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, created_at, updated_at
+		SELECT id, user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, COALESCE(instance_url, ''), created_at, updated_at
 		FROM tokens
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -67,7 +68,7 @@ func (r *TokenRepository) GetByUserID(userID int64) (*Token, error) {
 	token := &Token{}
 	err := r.DB.QueryRow(query, userID).Scan(
 		&token.ID, &token.UserID, &token.Platform, &token.AccessToken, &token.RefreshToken,
-		&token.TokenType, &token.ExpiresAt, &token.Scope, &token.CodeVerifier, &token.CreatedAt, &token.UpdatedAt,
+		&token.TokenType, &token.ExpiresAt, &token.Scope, &token.CodeVerifier, &token.InstanceURL, &token.CreatedAt, &token.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("token not found for user")
@@ -82,13 +83,13 @@ func (r *TokenRepository) GetByUserID(userID int64) (*Token, error) {
 func (r *TokenRepository) Update(token *Token) error {
 	query := `
 		UPDATE tokens
-		SET platform = ?, access_token = ?, refresh_token = ?, expires_at = ?, scope = ?, code_verifier = ?, updated_at = ?
+		SET platform = ?, access_token = ?, refresh_token = ?, expires_at = ?, scope = ?, code_verifier = ?, instance_url = ?, updated_at = ?
 		WHERE id = ?
 	`
 	now := time.Now()
 	_, err := r.DB.Exec(query,
 		token.Platform, token.AccessToken, token.RefreshToken, token.ExpiresAt,
-		token.Scope, token.CodeVerifier, now, token.ID,
+		token.Scope, token.CodeVerifier, token.InstanceURL, now, token.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update token: %w", err)
@@ -140,7 +141,7 @@ func (r *TokenRepository) CreateOrUpdate(token *Token) error {
 // GetByUserIDAndPlatform retrieves the most recent token for a user and platform
 func (r *TokenRepository) GetByUserIDAndPlatform(userID int64, platform Platform) (*Token, error) {
 	query := `
-		SELECT id, user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, created_at, updated_at
+		SELECT id, user_id, platform, access_token, refresh_token, token_type, expires_at, scope, code_verifier, COALESCE(instance_url, ''), created_at, updated_at
 		FROM tokens
 		WHERE user_id = ? AND platform = ?
 		ORDER BY created_at DESC
@@ -149,7 +150,7 @@ func (r *TokenRepository) GetByUserIDAndPlatform(userID int64, platform Platform
 	token := &Token{}
 	err := r.DB.QueryRow(query, userID, platform).Scan(
 		&token.ID, &token.UserID, &token.Platform, &token.AccessToken, &token.RefreshToken,
-		&token.TokenType, &token.ExpiresAt, &token.Scope, &token.CodeVerifier, &token.CreatedAt, &token.UpdatedAt,
+		&token.TokenType, &token.ExpiresAt, &token.Scope, &token.CodeVerifier, &token.InstanceURL, &token.CreatedAt, &token.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("token not found for user and platform")
@@ -0,0 +1,504 @@
+package fakeplatform
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// Limits of the fake PDS, matching bsky.social
+const (
+	blueskyCharacterLimit = 300               // Graphemes; the fake counts runes
+	blueskyImageBlobLimit = 1000 * 1000       // Bytes of an image blob
+	blueskyBlobLimit      = 100 * 1000 * 1000 // Bytes of any blob
+	blueskyWriteLimit     = 1666              // Record creations per hour
+	blueskyAccessLifetime = 2 * 60 * 60       // Seconds
+)
+
+// BlueskyBlob is a blob uploaded with com.atproto.repo.uploadBlob
+type BlueskyBlob struct {
+	CID      string
+	MimeType string
+	Data     []byte
+}
+
+// BlueskyPost is a post record created with com.atproto.repo.createRecord
+type BlueskyPost struct {
+	URI    string
+	CID    string
+	Text   string
+	Langs  []string
+	Facets []BlueskyFacet
+	Images []BlueskyEmbeddedMedia // app.bsky.embed.images
+	Video  *BlueskyEmbeddedMedia  // app.bsky.embed.video
+	Reply  *BlueskyReply
+
+	record map[string]interface{}
+}
+
+// BlueskyFacet is one facet of a post with a single feature
+type BlueskyFacet struct {
+	ByteStart int
+	ByteEnd   int
+	Type      string // app.bsky.richtext.facet#mention, #link or #tag
+	DID       string
+	URI       string
+	Tag       string
+}
+
+// BlueskyEmbeddedMedia is an image or video embedded in a post
+type BlueskyEmbeddedMedia struct {
+	CID    string
+	Alt    string
+	Width  int // From aspectRatio, 0 if it wasn't given
+	Height int
+}
+
+// BlueskyReply is the thread a reply belongs to
+type BlueskyReply struct {
+	RootURI   string
+	ParentURI string
+}
+
+// BlueskyURL returns the URL of the fake PDS, the server accounts log in on
+func (s *Server) BlueskyURL() string {
+	return s.URL
+}
+
+// BlueskyPosts returns the posts created on Bluesky in order
+func (s *Server) BlueskyPosts() []BlueskyPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]BlueskyPost, 0, len(s.blueskyPosts))
+	for _, post := range s.blueskyPosts {
+		result = append(result, *post)
+	}
+	return result
+}
+
+// BlueskyBlob returns the blob with the given CID
+func (s *Server) BlueskyBlob(cid string) (BlueskyBlob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blob, ok := s.blueskyBlobs[cid]
+	if !ok {
+		return BlueskyBlob{}, false
+	}
+	return *blob, true
+}
+
+// registerBluesky adds the XRPC endpoints of a Bluesky PDS to mux
+func (s *Server) registerBluesky(mux *http.ServeMux) {
+	mux.HandleFunc("POST /xrpc/com.atproto.server.createSession", s.handle(OpBlueskySession, s.blueskyCreateSession))
+	mux.HandleFunc("POST /xrpc/com.atproto.server.refreshSession", s.handle(OpBlueskySession, s.blueskyRefreshSession))
+	mux.HandleFunc("GET /xrpc/com.atproto.server.getSession", s.handle(OpBlueskySession, s.blueskyGetSession))
+	mux.HandleFunc("GET /xrpc/app.bsky.actor.getProfile", s.handle(OpBlueskyProfile, s.blueskyGetProfile))
+	mux.HandleFunc("GET /xrpc/com.atproto.identity.resolveHandle", s.handle(OpBlueskyResolveHandle, s.blueskyResolveHandle))
+	mux.HandleFunc("POST /xrpc/com.atproto.repo.uploadBlob", s.handle(OpBlueskyUploadBlob, s.blueskyUploadBlob))
+	mux.HandleFunc("POST /xrpc/com.atproto.repo.createRecord", s.handle(OpBlueskyCreateRecord, s.blueskyCreateRecord))
+	mux.HandleFunc("GET /xrpc/app.bsky.feed.getPosts", s.handle(OpBlueskyGetPosts, s.blueskyGetPosts))
+}
+
+// writeBlueskyError writes an error in the format of XRPC
+func writeBlueskyError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, map[string]string{"error": name, "message": message})
+}
+
+// blueskyAuthorized checks the bearer token, writing an XRPC error if it is invalid
+func (s *Server) blueskyAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformBluesky, bearerToken(r)) {
+		writeBlueskyError(w, http.StatusBadRequest, "ExpiredToken", "Token has expired")
+		return false
+	}
+	return true
+}
+
+// blueskyJWTLocked returns a token shaped like a PDS JWT, with the account's DID and an expiry
+// The fake doesn't verify signatures, so the signature is a placeholder; s.mu must be held
+func (s *Server) blueskyJWTLocked(scope string, lifetime time.Duration) string {
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	header := encode(map[string]string{"alg": "ES256K", "typ": "at+jwt"})
+	payload := encode(map[string]interface{}{
+		"scope": scope,
+		"sub":   BlueskyDID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(lifetime).Unix(),
+		"jti":   fmt.Sprintf("fake-%d", s.newIDLocked()),
+	})
+	return header + "." + payload + ".fake-signature"
+}
+
+// blueskySessionLocked issues a new session of the fake account; s.mu must be held
+func (s *Server) blueskySessionLocked() map[string]interface{} {
+	accessJwt := s.blueskyJWTLocked("com.atproto.appPass", blueskyAccessLifetime*time.Second)
+	refreshJwt := s.blueskyJWTLocked("com.atproto.refresh", 90*24*time.Hour)
+	s.accessTokens[accessJwt] = models.PlatformBluesky
+	s.refreshTokens[refreshJwt] = models.PlatformBluesky
+
+	return map[string]interface{}{
+		"accessJwt":  accessJwt,
+		"refreshJwt": refreshJwt,
+		"handle":     BlueskyHandle,
+		"did":        BlueskyDID,
+		"didDoc":     s.blueskyDIDDoc(),
+		"active":     true,
+	}
+}
+
+// blueskyDIDDoc returns the DID document of the fake account, naming the fake as its PDS
+func (s *Server) blueskyDIDDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"@context":    []string{"https://www.w3.org/ns/did/v1"},
+		"id":          BlueskyDID,
+		"alsoKnownAs": []string{"at://" + BlueskyHandle},
+		"service": []map[string]string{{
+			"id":              "#atproto_pds",
+			"type":            "AtprotoPersonalDataServer",
+			"serviceEndpoint": s.URL,
+		}},
+	}
+}
+
+// blueskyCreateSession logs in with the handle, DID or email of the fake account and its app password
+func (s *Server) blueskyCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Input must be a JSON object")
+		return
+	}
+	knownIdentifier := req.Identifier == BlueskyHandle || req.Identifier == BlueskyDID || req.Identifier == BlueskyEmail
+	if !knownIdentifier || req.Password != BlueskyAppPassword {
+		writeBlueskyError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.blueskySessionLocked())
+}
+
+// blueskyRefreshSession exchanges a refresh token for a new session; refresh tokens are single-use
+func (s *Server) blueskyRefreshSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refreshJwt := bearerToken(r)
+	if refreshJwt == "" || s.refreshTokens[refreshJwt] != models.PlatformBluesky {
+		writeBlueskyError(w, http.StatusBadRequest, "ExpiredToken", "Token has been revoked")
+		return
+	}
+	delete(s.refreshTokens, refreshJwt)
+	writeJSON(w, http.StatusOK, s.blueskySessionLocked())
+}
+
+// blueskyGetSession returns the account of the access token
+func (s *Server) blueskyGetSession(w http.ResponseWriter, r *http.Request) {
+	if !s.blueskyAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"handle": BlueskyHandle,
+		"did":    BlueskyDID,
+		"email":  BlueskyEmail,
+		"didDoc": s.blueskyDIDDoc(),
+		"active": true,
+	})
+}
+
+// blueskyGetProfile returns the profile of the fake account
+func (s *Server) blueskyGetProfile(w http.ResponseWriter, r *http.Request) {
+	if !s.blueskyAuthorized(w, r) {
+		return
+	}
+	actor := r.URL.Query().Get("actor")
+	if actor != BlueskyDID && actor != BlueskyHandle {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"did":         BlueskyDID,
+		"handle":      BlueskyHandle,
+		"displayName": BlueskyDisplayName,
+		"avatar":      "https://cdn.bsky.app/img/avatar/plain/" + BlueskyDID + "/fake@jpeg",
+	})
+}
+
+// blueskyResolveHandle returns the DID of the fake account or of BlueskyFriendHandle
+func (s *Server) blueskyResolveHandle(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("handle") {
+	case BlueskyHandle:
+		writeJSON(w, http.StatusOK, map[string]string{"did": BlueskyDID})
+	case BlueskyFriendHandle:
+		writeJSON(w, http.StatusOK, map[string]string{"did": BlueskyFriendDID})
+	default:
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Unable to resolve handle")
+	}
+}
+
+// blueskyUploadBlob stores the raw request body as a blob
+func (s *Server) blueskyUploadBlob(w http.ResponseWriter, r *http.Request) {
+	if !s.blueskyAuthorized(w, r) {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, blueskyBlobLimit+1))
+	if err != nil {
+		return
+	}
+	if len(data) > blueskyBlobLimit {
+		writeBlueskyError(w, http.StatusRequestEntityTooLarge, "BlobTooLarge", "This file is too large")
+		return
+	}
+	mimeType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") && !strings.HasPrefix(mimeType, "video/") {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidMimeType", "Wrong type of file")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blob := &BlueskyBlob{
+		CID:      fmt.Sprintf("bafkreifake%d", s.newIDLocked()),
+		MimeType: mimeType,
+		Data:     data,
+	}
+	s.blueskyBlobs[blob.CID] = blob
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"blob": map[string]interface{}{
+			"$type":    "blob",
+			"ref":      map[string]string{"$link": blob.CID},
+			"mimeType": blob.MimeType,
+			"size":     len(blob.Data),
+		},
+	})
+}
+
+// blueskyPostRecord is the part of an app.bsky.feed.post record the fake validates
+type blueskyPostRecord struct {
+	Type      string   `json:"$type"`
+	Text      string   `json:"text"`
+	CreatedAt string   `json:"createdAt"`
+	Langs     []string `json:"langs"`
+	Facets    []struct {
+		Index struct {
+			ByteStart int `json:"byteStart"`
+			ByteEnd   int `json:"byteEnd"`
+		} `json:"index"`
+		Features []struct {
+			Type string `json:"$type"`
+			DID  string `json:"did"`
+			URI  string `json:"uri"`
+			Tag  string `json:"tag"`
+		} `json:"features"`
+	} `json:"facets"`
+	Reply *struct {
+		Root   blueskyStrongRef `json:"root"`
+		Parent blueskyStrongRef `json:"parent"`
+	} `json:"reply"`
+	Embed *struct {
+		Type   string `json:"$type"`
+		Images []struct {
+			Image       blueskyBlobRef      `json:"image"`
+			Alt         *string             `json:"alt"`
+			AspectRatio *blueskyAspectRatio `json:"aspectRatio"`
+		} `json:"images"`
+		Video       *blueskyBlobRef     `json:"video"`
+		Alt         string              `json:"alt"`
+		AspectRatio *blueskyAspectRatio `json:"aspectRatio"`
+	} `json:"embed"`
+}
+
+type blueskyStrongRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+type blueskyBlobRef struct {
+	Ref struct {
+		Link string `json:"$link"`
+	} `json:"ref"`
+	MimeType string `json:"mimeType"`
+}
+
+type blueskyAspectRatio struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// blueskyCreateRecord validates and stores a post record in the repository of the fake account
+func (s *Server) blueskyCreateRecord(w http.ResponseWriter, r *http.Request) {
+	if !s.blueskyAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		Repo       string                 `json:"repo"`
+		Collection string                 `json:"collection"`
+		Record     map[string]interface{} `json:"record"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Input must be a JSON object")
+		return
+	}
+	if req.Repo != BlueskyDID && req.Repo != BlueskyHandle {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid repo")
+		return
+	}
+	if req.Collection != "app.bsky.feed.post" {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "The fake only accepts app.bsky.feed.post records")
+		return
+	}
+
+	data, _ := json.Marshal(req.Record)
+	var record blueskyPostRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Type != req.Collection || record.CreatedAt == "" {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/createdAt must be a valid datetime")
+		return
+	}
+	if utf8.RuneCountInString(record.Text) > blueskyCharacterLimit {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("Invalid app.bsky.feed.post record: Record/text must not be longer than %d graphemes", blueskyCharacterLimit))
+		return
+	}
+	if len(record.Langs) > 3 {
+		writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/langs must not have more than 3 elements")
+		return
+	}
+
+	post := &BlueskyPost{Text: record.Text, Langs: record.Langs, record: req.Record}
+	for _, facet := range record.Facets {
+		if facet.Index.ByteStart < 0 || facet.Index.ByteEnd > len(record.Text) || facet.Index.ByteStart >= facet.Index.ByteEnd || len(facet.Features) != 1 {
+			writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/facets has an invalid byte slice")
+			return
+		}
+		feature := facet.Features[0]
+		post.Facets = append(post.Facets, BlueskyFacet{
+			ByteStart: facet.Index.ByteStart,
+			ByteEnd:   facet.Index.ByteEnd,
+			Type:      feature.Type,
+			DID:       feature.DID,
+			URI:       feature.URI,
+			Tag:       feature.Tag,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if embed := record.Embed; embed != nil {
+		switch embed.Type {
+		case "app.bsky.embed.images":
+			if len(embed.Images) == 0 || len(embed.Images) > 4 {
+				writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/embed/images must have between 1 and 4 elements")
+				return
+			}
+			for _, image := range embed.Images {
+				blob, ok := s.blueskyBlobs[image.Image.Ref.Link]
+				if !ok || !strings.HasPrefix(blob.MimeType, "image/") || len(blob.Data) > blueskyImageBlobLimit || image.Alt == nil {
+					writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/embed/images has an invalid image")
+					return
+				}
+				post.Images = append(post.Images, blueskyEmbeddedMedia(blob.CID, *image.Alt, image.AspectRatio))
+			}
+		case "app.bsky.embed.video":
+			if embed.Video == nil {
+				writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/embed/video is required")
+				return
+			}
+			blob, ok := s.blueskyBlobs[embed.Video.Ref.Link]
+			if !ok || !strings.HasPrefix(blob.MimeType, "video/") {
+				writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/embed/video has an invalid video")
+				return
+			}
+			video := blueskyEmbeddedMedia(blob.CID, embed.Alt, embed.AspectRatio)
+			post.Video = &video
+		default:
+			writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/embed has an unsupported type")
+			return
+		}
+	}
+
+	if reply := record.Reply; reply != nil {
+		for _, ref := range []blueskyStrongRef{reply.Root, reply.Parent} {
+			target := s.blueskyPostLocked(ref.URI)
+			if target == nil || target.CID != ref.CID {
+				writeBlueskyError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/reply references an unknown post")
+				return
+			}
+		}
+		post.Reply = &BlueskyReply{RootURI: reply.Root.URI, ParentURI: reply.Parent.URI}
+	}
+
+	id := s.newIDLocked()
+	post.URI = fmt.Sprintf("at://%s/app.bsky.feed.post/3lfake%d", BlueskyDID, id)
+	post.CID = fmt.Sprintf("bafyreifake%d", id)
+	s.blueskyPosts = append(s.blueskyPosts, post)
+
+	w.Header().Set("RateLimit-Limit", fmt.Sprintf("%d", blueskyWriteLimit))
+	w.Header().Set("RateLimit-Remaining", fmt.Sprintf("%d", blueskyWriteLimit-len(s.blueskyPosts)))
+	w.Header().Set("RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+	w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=3600", blueskyWriteLimit))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"uri":              post.URI,
+		"cid":              post.CID,
+		"validationStatus": "valid",
+	})
+}
+
+// blueskyEmbeddedMedia converts an embedded image or video
+func blueskyEmbeddedMedia(cid, alt string, aspectRatio *blueskyAspectRatio) BlueskyEmbeddedMedia {
+	media := BlueskyEmbeddedMedia{CID: cid, Alt: alt}
+	if aspectRatio != nil {
+		media.Width, media.Height = aspectRatio.Width, aspectRatio.Height
+	}
+	return media
+}
+
+// blueskyPostLocked returns the post with the given URI, nil if there is none; s.mu must be held
+// Posts can be referenced with the handle of the fake account in place of its DID
+func (s *Server) blueskyPostLocked(uri string) *BlueskyPost {
+	uri = strings.Replace(uri, "at://"+BlueskyHandle+"/", "at://"+BlueskyDID+"/", 1)
+	for _, post := range s.blueskyPosts {
+		if post.URI == uri {
+			return post
+		}
+	}
+	return nil
+}
+
+// blueskyGetPosts returns the views of the posts with the given URIs, leaving out unknown ones
+func (s *Server) blueskyGetPosts(w http.ResponseWriter, r *http.Request) {
+	if !s.blueskyAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]map[string]interface{}, 0)
+	for _, uri := range r.URL.Query()["uris"] {
+		post := s.blueskyPostLocked(uri)
+		if post == nil {
+			continue
+		}
+		posts = append(posts, map[string]interface{}{
+			"uri":       post.URI,
+			"cid":       post.CID,
+			"author":    map[string]string{"did": BlueskyDID, "handle": BlueskyHandle},
+			"record":    post.record,
+			"indexedAt": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"posts": posts})
+}
@@ -0,0 +1,233 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// discordPrefix is where the fake's webhooks are served, so their routes don't clash with other platforms
+const discordPrefix = "/discord/api/webhooks"
+
+// Limits of the fake webhooks, matching Discord servers without boosts
+const (
+	discordContentLimit    = 2000             // Characters of a message
+	discordAttachmentLimit = 10 * 1024 * 1024 // Bytes of an attachment
+	discordFilesLimit      = 10               // Attachments of a message
+)
+
+// DiscordMessage is a message sent with the fake webhook
+type DiscordMessage struct {
+	ID          string
+	Content     string
+	Username    string
+	AvatarURL   string
+	Flags       int
+	Parse       []string // Mention types allowed to ping, nil if allowed_mentions wasn't given
+	Attachments []DiscordAttachment
+	Deleted     bool
+}
+
+// DiscordAttachment is a file uploaded with a message
+type DiscordAttachment struct {
+	Filename    string
+	ContentType string
+	Size        int
+}
+
+// DiscordWebhookURL returns the URL of the fake channel's webhook
+func (s *Server) DiscordWebhookURL() string {
+	return s.URL + discordPrefix + "/" + DiscordWebhookID + "/" + DiscordWebhookToken
+}
+
+// DiscordMessages returns the messages sent with the webhook, in order, including deleted ones
+func (s *Server) DiscordMessages() []DiscordMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]DiscordMessage, 0, len(s.discordMessages))
+	for _, message := range s.discordMessages {
+		result = append(result, *message)
+	}
+	return result
+}
+
+// DeleteDiscordWebhook deletes the fake webhook, as if it was removed from the channel's integrations
+func (s *Server) DeleteDiscordWebhook() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discordWebhookDeleted = true
+}
+
+// registerDiscord registers the webhook endpoints
+func (s *Server) registerDiscord(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+discordPrefix+"/{id}/{token}", s.handle(OpDiscordWebhook, s.discordGetWebhook))
+	mux.HandleFunc("POST "+discordPrefix+"/{id}/{token}", s.handle(OpDiscordExecute, s.discordExecute))
+	mux.HandleFunc("DELETE "+discordPrefix+"/{id}/{token}/messages/{message}", s.handle(OpDiscordDeleteMessage, s.discordDeleteMessage))
+}
+
+// writeDiscordError writes a Discord error
+func writeDiscordError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{"code": code, "message": message})
+}
+
+// discordAuthorized checks the webhook ID and token in the path, writing an error if they are wrong
+func (s *Server) discordAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	deleted := s.discordWebhookDeleted
+	s.mu.Unlock()
+
+	if r.PathValue("id") != DiscordWebhookID || deleted {
+		writeDiscordError(w, http.StatusNotFound, 10015, "Unknown Webhook")
+		return false
+	}
+	if r.PathValue("token") != DiscordWebhookToken {
+		writeDiscordError(w, http.StatusUnauthorized, 50027, "Invalid Webhook Token")
+		return false
+	}
+	return true
+}
+
+// discordGetWebhook serves GET /webhooks/{id}/{token}
+func (s *Server) discordGetWebhook(w http.ResponseWriter, r *http.Request) {
+	if !s.discordAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":         DiscordWebhookID,
+		"type":       1,
+		"name":       DiscordWebhookName,
+		"avatar":     DiscordWebhookAvatar,
+		"channel_id": DiscordChannelID,
+		"guild_id":   DiscordGuildID,
+		"token":      DiscordWebhookToken,
+	})
+}
+
+// discordExecute serves POST /webhooks/{id}/{token}, with a JSON or multipart body
+func (s *Server) discordExecute(w http.ResponseWriter, r *http.Request) {
+	if !s.discordAuthorized(w, r) {
+		return
+	}
+
+	var payload struct {
+		Content         string `json:"content"`
+		Username        string `json:"username"`
+		AvatarURL       string `json:"avatar_url"`
+		Flags           int    `json:"flags"`
+		AllowedMentions *struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+		Attachments []struct {
+			ID       int    `json:"id"`
+			Filename string `json:"filename"`
+		} `json:"attachments"`
+	}
+	message := &DiscordMessage{}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+				return
+			}
+
+			switch name := part.FormName(); {
+			case name == "payload_json":
+				if err := json.NewDecoder(part).Decode(&payload); err != nil {
+					writeDiscordError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.")
+					return
+				}
+			case strings.HasPrefix(name, "files["):
+				size, err := io.Copy(io.Discard, io.LimitReader(part, discordAttachmentLimit+1))
+				if err != nil {
+					writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body")
+					return
+				}
+				if size > discordAttachmentLimit {
+					writeDiscordError(w, http.StatusRequestEntityTooLarge, 40005, "Request entity too large")
+					return
+				}
+				message.Attachments = append(message.Attachments, DiscordAttachment{
+					Filename:    part.FileName(),
+					ContentType: part.Header.Get("Content-Type"),
+					Size:        int(size),
+				})
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeDiscordError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.")
+		return
+	}
+
+	if payload.Content == "" && len(message.Attachments) == 0 {
+		writeDiscordError(w, http.StatusBadRequest, 50006, "Cannot send an empty message")
+		return
+	}
+	if utf8.RuneCountInString(payload.Content) > discordContentLimit {
+		writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body: content must be 2000 or fewer in length.")
+		return
+	}
+	if len(message.Attachments) > discordFilesLimit {
+		writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body: too many attachments.")
+		return
+	}
+	if len(payload.Attachments) != len(message.Attachments) {
+		writeDiscordError(w, http.StatusBadRequest, 50035, "Invalid Form Body: attachments don't match the uploaded files.")
+		return
+	}
+
+	message.Content = payload.Content
+	message.Username = payload.Username
+	message.AvatarURL = payload.AvatarURL
+	message.Flags = payload.Flags
+	if payload.AllowedMentions != nil {
+		message.Parse = append([]string{}, payload.AllowedMentions.Parse...)
+	}
+
+	s.mu.Lock()
+	message.ID = fmt.Sprintf("14%017d", s.newIDLocked())
+	s.discordMessages = append(s.discordMessages, message)
+	s.mu.Unlock()
+
+	// Without wait=true Discord answers 204 with no message
+	if r.URL.Query().Get("wait") != "true" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":         message.ID,
+		"channel_id": DiscordChannelID,
+		"content":    message.Content,
+		"webhook_id": DiscordWebhookID,
+	})
+}
+
+// discordDeleteMessage serves DELETE /webhooks/{id}/{token}/messages/{message}
+func (s *Server) discordDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	if !s.discordAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, message := range s.discordMessages {
+		if message.ID == r.PathValue("message") && !message.Deleted {
+			message.Deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeDiscordError(w, http.StatusNotFound, 10008, "Unknown Message")
+}
@@ -0,0 +1,464 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// facebookPrefix is the path the fake Facebook endpoints are served under, so they don't collide
+// with the Instagram Graph API, whose paths they mirror
+const facebookPrefix = "/facebook"
+
+// facebookGraphVersion is the Graph API version the fake serves
+const facebookGraphVersion = "v23.0"
+
+// Scheduled posts must be published between 10 minutes and 30 days after they are created
+const (
+	facebookMinScheduleDelay = 10 * time.Minute
+	facebookMaxScheduleDelay = 30 * 24 * time.Hour
+)
+
+// facebookPage is a page the fake Facebook user has a role on
+type facebookPage struct {
+	id       string
+	name     string
+	category string
+	tasks    []string
+}
+
+// facebookPages are the pages of the fake Facebook user
+var facebookPages = []facebookPage{
+	{FacebookPageID, FacebookPageName, "Bakery", []string{"ANALYZE", "ADVERTISE", "MODERATE", "CREATE_CONTENT", "MANAGE"}},
+	{FacebookSecondPageID, FacebookSecondPageName, "Coffee Shop", []string{"ANALYZE", "CREATE_CONTENT"}},
+	{FacebookAnalystPageID, FacebookAnalystPageName, "Restaurant", []string{"ANALYZE"}},
+}
+
+// FacebookPhoto is a photo uploaded with POST /{page-id}/photos
+type FacebookPhoto struct {
+	ID        string
+	PageID    string
+	URL       string
+	Published bool // Unpublished photos can be attached to a post
+	Temporary bool // Temporary photos can be attached to a scheduled post
+}
+
+// FacebookPost is a post created with POST /{page-id}/feed or /{page-id}/photos
+type FacebookPost struct {
+	ID                   string // {page-id}_{post-id}
+	PageID               string
+	Message              string
+	Link                 string
+	PhotoURLs            []string
+	Published            bool
+	ScheduledPublishTime int64  // Unix time, 0 if the post was published immediately
+	AccessToken          string // Page token the post was created with
+}
+
+// FacebookVideo is a video uploaded with POST /{page-id}/videos
+type FacebookVideo struct {
+	ID                   string
+	PageID               string
+	FileURL              string
+	Description          string
+	Title                string
+	ScheduledPublishTime int64
+
+	processing Processing
+	polls      int
+}
+
+// FacebookPosts returns the feed and photo posts created on Facebook pages in order
+func (s *Server) FacebookPosts() []FacebookPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]FacebookPost, 0, len(s.facebookPosts))
+	for _, post := range s.facebookPosts {
+		result = append(result, *post)
+	}
+	return result
+}
+
+// FacebookVideos returns the videos uploaded to Facebook pages in order
+func (s *Server) FacebookVideos() []FacebookVideo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]FacebookVideo, 0, len(s.facebookVideos))
+	for _, video := range s.facebookVideos {
+		result = append(result, *video)
+	}
+	return result
+}
+
+// registerFacebook adds the Facebook endpoints to mux
+// The login dialog and the Graph API are both served under the Graph API version
+func (s *Server) registerFacebook(mux *http.ServeMux) {
+	api := facebookPrefix + "/" + facebookGraphVersion
+	codeToken := s.handle(OpFacebookToken, s.facebookToken)
+	longLivedToken := s.handle(OpFacebookLongLivedToken, s.facebookLongLivedToken)
+
+	mux.HandleFunc("GET "+api+"/dialog/oauth", s.handle(OpFacebookAuthorize, s.facebookAuthorize))
+	// Codes and tokens are exchanged at the same endpoint, told apart by grant_type
+	mux.HandleFunc("GET "+api+"/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") == "fb_exchange_token" {
+			longLivedToken(w, r)
+			return
+		}
+		codeToken(w, r)
+	})
+	mux.HandleFunc("GET "+api+"/me", s.handle(OpFacebookUserInfo, s.facebookUserInfo))
+	mux.HandleFunc("GET "+api+"/me/accounts", s.handle(OpFacebookPages, s.facebookAccounts))
+	mux.HandleFunc("POST "+api+"/{id}/feed", s.handle(OpFacebookFeed, s.facebookFeed))
+	mux.HandleFunc("POST "+api+"/{id}/photos", s.handle(OpFacebookPhotos, s.facebookPhotoUpload))
+	mux.HandleFunc("POST "+api+"/{id}/videos", s.handle(OpFacebookVideos, s.facebookVideoUpload))
+	mux.HandleFunc("GET "+api+"/{id}", s.handle(OpFacebookObject, s.facebookObject))
+}
+
+// facebookUserAuthorized checks that the access_token parameter is a user token and writes a
+// Graph API error if it is not
+func (s *Server) facebookUserAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformFacebook, r.FormValue("access_token")) {
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+		return false
+	}
+	return true
+}
+
+// facebookPageAuthorizedLocked checks that the access_token parameter is the token of the page
+// with pageID and writes a Graph API error if it is not; s.mu must be held
+func (s *Server) facebookPageAuthorizedLocked(w http.ResponseWriter, r *http.Request, pageID string) bool {
+	accessToken := r.FormValue("access_token")
+	tokenPageID, ok := s.facebookPageTokens[accessToken]
+	switch {
+	case ok && tokenPageID == pageID:
+		return true
+	case ok || s.accessTokens[accessToken] == models.PlatformFacebook:
+		writeInstagramError(w, http.StatusForbidden, 200, "(#200) The page access token of the page is required to publish as the page")
+	default:
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+	}
+	return false
+}
+
+// facebookAuthorize approves the login dialog and redirects back with a code
+func (s *Server) facebookAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformFacebook)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	scopes := strings.Split(query.Get("scope"), ",")
+	if !contains(scopes, "pages_show_list") || !contains(scopes, "pages_manage_posts") {
+		http.Error(w, "the pages_show_list and pages_manage_posts permissions are required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformFacebook, ""), query.Get("state"))
+}
+
+// facebookToken exchanges an authorization code for a short-lived user token
+func (s *Server) facebookToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformFacebook)
+	if r.FormValue("client_id") != app.id || r.FormValue("client_secret") != app.secret {
+		writeInstagramError(w, http.StatusBadRequest, 1, "Error validating client secret")
+		return
+	}
+	if _, ok := s.takeCode(models.PlatformFacebook, r.FormValue("code")); !ok || r.FormValue("redirect_uri") != app.redirectURI {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid verification code format")
+		return
+	}
+
+	accessToken, _ := s.IssueToken(models.PlatformFacebook)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   facebookShortLifetime,
+	})
+}
+
+// facebookLongLivedToken exchanges a user token for a long-lived one
+// Exchanging a long-lived token again is how Facebook user tokens are extended
+func (s *Server) facebookLongLivedToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformFacebook)
+	if r.FormValue("client_id") != app.id || r.FormValue("client_secret") != app.secret {
+		writeInstagramError(w, http.StatusBadRequest, 1, "Error validating client secret")
+		return
+	}
+	if !s.validToken(models.PlatformFacebook, r.FormValue("fb_exchange_token")) {
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+		return
+	}
+
+	// The exchanged token stays valid until it expires
+	accessToken, _ := s.IssueToken(models.PlatformFacebook)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   facebookLongLifetime,
+	})
+}
+
+// facebookUserInfo returns the fake Facebook user
+func (s *Server) facebookUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.facebookUserAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      FacebookUserID,
+		"name":    FacebookUserName,
+		"picture": facebookPicture(s.URL + "/media/facebook-avatar.jpg"),
+	})
+}
+
+// facebookAccounts lists the pages of the fake user, each with a new page token
+func (s *Server) facebookAccounts(w http.ResponseWriter, r *http.Request) {
+	if !s.facebookUserAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]map[string]interface{}, 0, len(facebookPages))
+	for _, page := range facebookPages {
+		pageToken := fmt.Sprintf("facebook-page-%s-%d", page.id, s.newIDLocked())
+		s.facebookPageTokens[pageToken] = page.id
+		data = append(data, map[string]interface{}{
+			"id":           page.id,
+			"name":         page.name,
+			"category":     page.category,
+			"access_token": pageToken,
+			"picture":      facebookPicture(s.URL + "/media/facebook-page-" + page.id + ".jpg"),
+			"tasks":        page.tasks,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": data,
+		"paging": map[string]interface{}{
+			"cursors": map[string]string{"before": "fake-before", "after": "fake-after"},
+		},
+	})
+}
+
+// facebookFeed creates a text, link or multi-photo post
+func (s *Server) facebookFeed(w http.ResponseWriter, r *http.Request) {
+	pageID := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.facebookPageAuthorizedLocked(w, r, pageID) {
+		return
+	}
+
+	post := &FacebookPost{
+		PageID:      pageID,
+		Message:     r.PostFormValue("message"),
+		Link:        r.PostFormValue("link"),
+		AccessToken: r.FormValue("access_token"),
+	}
+	scheduled, ok := facebookSchedule(w, r)
+	if !ok {
+		return
+	}
+	post.ScheduledPublishTime = scheduled
+	post.Published = r.PostFormValue("published") != "false"
+
+	for i := 0; ; i++ {
+		attachment := r.PostFormValue(fmt.Sprintf("attached_media[%d]", i))
+		if attachment == "" {
+			break
+		}
+		var media struct {
+			MediaFBID string `json:"media_fbid"`
+		}
+		if err := json.Unmarshal([]byte(attachment), &media); err != nil {
+			writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Invalid attached_media[%d]", i))
+			return
+		}
+		photo, ok := s.facebookPhotos[media.MediaFBID]
+		if !ok || photo.PageID != pageID || photo.Published {
+			writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Photo %s is not an unpublished photo of the page", media.MediaFBID))
+			return
+		}
+		if scheduled != 0 && !photo.Temporary {
+			writeInstagramError(w, http.StatusBadRequest, 100, "The photos of a scheduled post must be uploaded with temporary=true")
+			return
+		}
+		post.PhotoURLs = append(post.PhotoURLs, photo.URL)
+	}
+	if post.Link != "" && len(post.PhotoURLs) > 0 {
+		writeInstagramError(w, http.StatusBadRequest, 100, "A post can't have both a link and attached media")
+		return
+	}
+	if post.Message == "" && post.Link == "" && len(post.PhotoURLs) == 0 {
+		writeInstagramError(w, http.StatusBadRequest, 100, "The post is empty")
+		return
+	}
+
+	post.ID = fmt.Sprintf("%s_%d", pageID, s.newIDLocked())
+	s.facebookPosts = append(s.facebookPosts, post)
+	writeJSON(w, http.StatusOK, map[string]string{"id": post.ID})
+}
+
+// facebookPhotoUpload uploads a photo, publishing it as a post unless published is false
+func (s *Server) facebookPhotoUpload(w http.ResponseWriter, r *http.Request) {
+	pageID := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.facebookPageAuthorizedLocked(w, r, pageID) {
+		return
+	}
+
+	photo := &FacebookPhoto{
+		PageID:    pageID,
+		URL:       r.PostFormValue("url"),
+		Published: r.PostFormValue("published") != "false",
+		Temporary: r.PostFormValue("temporary") == "true",
+	}
+	if photo.URL == "" {
+		writeInstagramError(w, http.StatusBadRequest, 324, "Requires upload file")
+		return
+	}
+	scheduled, ok := facebookSchedule(w, r)
+	if !ok {
+		return
+	}
+	photo.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.facebookPhotos[photo.ID] = photo
+
+	// An unpublished photo without a schedule waits to be attached to a post
+	if !photo.Published && scheduled == 0 {
+		writeJSON(w, http.StatusOK, map[string]string{"id": photo.ID})
+		return
+	}
+
+	post := &FacebookPost{
+		ID:                   fmt.Sprintf("%s_%d", pageID, s.newIDLocked()),
+		PageID:               pageID,
+		Message:              r.PostFormValue("message"),
+		PhotoURLs:            []string{photo.URL},
+		Published:            photo.Published,
+		ScheduledPublishTime: scheduled,
+		AccessToken:          r.FormValue("access_token"),
+	}
+	s.facebookPosts = append(s.facebookPosts, post)
+	if scheduled != 0 {
+		// Scheduled photos have no post ID until they are published
+		writeJSON(w, http.StatusOK, map[string]string{"id": photo.ID})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": photo.ID, "post_id": post.ID})
+}
+
+// facebookVideoUpload creates a video from file_url, which is then processed
+func (s *Server) facebookVideoUpload(w http.ResponseWriter, r *http.Request) {
+	pageID := r.PathValue("id")
+	processing := s.currentProcessing(models.PlatformFacebook)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.facebookPageAuthorizedLocked(w, r, pageID) {
+		return
+	}
+
+	video := &FacebookVideo{
+		PageID:      pageID,
+		FileURL:     r.PostFormValue("file_url"),
+		Description: r.PostFormValue("description"),
+		Title:       r.PostFormValue("title"),
+		processing:  processing,
+	}
+	if video.FileURL == "" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Requires file_url or an uploaded file")
+		return
+	}
+	scheduled, ok := facebookSchedule(w, r)
+	if !ok {
+		return
+	}
+	video.ScheduledPublishTime = scheduled
+
+	video.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.facebookVideos = append(s.facebookVideos, video)
+	writeJSON(w, http.StatusOK, map[string]string{"id": video.ID})
+}
+
+// facebookObject returns the status of a video, or the ID of any other object
+func (s *Server) facebookObject(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, video := range s.facebookVideos {
+		if video.ID != id {
+			continue
+		}
+		if !s.facebookPageAuthorizedLocked(w, r, video.PageID) {
+			return
+		}
+
+		video.polls++
+		status := map[string]interface{}{"video_status": "ready"}
+		switch {
+		case video.polls <= video.processing.Polls:
+			status = map[string]interface{}{"video_status": "processing", "processing_phase": map[string]string{"status": "in_progress"}}
+		case video.processing.FailReason != "":
+			status = map[string]interface{}{
+				"video_status": "error",
+				"processing_phase": map[string]interface{}{
+					"status": "error",
+					"errors": []map[string]interface{}{{"code": 1363008, "message": video.processing.FailReason}},
+				},
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": status})
+		return
+	}
+
+	accessToken := r.FormValue("access_token")
+	if s.accessTokens[accessToken] != models.PlatformFacebook && s.facebookPageTokens[accessToken] == "" {
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+		return
+	}
+	for _, post := range s.facebookPosts {
+		if post.ID == id {
+			writeJSON(w, http.StatusOK, map[string]string{"id": id})
+			return
+		}
+	}
+	writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Object with ID '%s' does not exist", id))
+}
+
+// facebookSchedule reads the scheduled_publish_time of a post, writing an error if it is invalid
+// Returns 0 for posts that are published immediately
+func facebookSchedule(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	value := r.PostFormValue("scheduled_publish_time")
+	if value == "" {
+		return 0, true
+	}
+	scheduled, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid scheduled_publish_time")
+		return 0, false
+	}
+	if r.PostFormValue("published") != "false" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "A scheduled post must be created with published=false")
+		return 0, false
+	}
+	if delay := time.Until(time.Unix(scheduled, 0)); delay < facebookMinScheduleDelay || delay > facebookMaxScheduleDelay {
+		writeInstagramError(w, http.StatusBadRequest, 100, "The specified scheduled publish time is invalid")
+		return 0, false
+	}
+	return scheduled, true
+}
+
+// facebookPicture returns the picture field of a user or page
+func facebookPicture(url string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]string{"url": url}}
+}
@@ -0,0 +1,324 @@
+package fakeplatform
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// Instagram container status codes
+const (
+	instagramInProgress = "IN_PROGRESS"
+	instagramFinished   = "FINISHED"
+	instagramError      = "ERROR"
+)
+
+// InstagramContainer is a media container created with POST /{ig-user-id}/media
+type InstagramContainer struct {
+	ID             string
+	MediaType      string // IMAGE, VIDEO, REELS or CAROUSEL
+	MediaURL       string
+	Caption        string
+	IsCarouselItem bool
+	Children       []string
+
+	processing Processing
+	polls      int
+}
+
+// InstagramPost is a container published with POST /{ig-user-id}/media_publish
+type InstagramPost struct {
+	ID          string
+	ContainerID string
+	MediaType   string
+	Caption     string
+	MediaURLs   []string
+	Permalink   string
+}
+
+// InstagramPosts returns the posts published on Instagram in order
+func (s *Server) InstagramPosts() []InstagramPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]InstagramPost, 0, len(s.igPosts))
+	for _, post := range s.igPosts {
+		result = append(result, *post)
+	}
+	return result
+}
+
+// registerInstagram adds the Instagram endpoints to mux
+func (s *Server) registerInstagram(mux *http.ServeMux) {
+	mux.HandleFunc("GET /oauth/authorize", s.handle(OpInstagramAuthorize, s.instagramAuthorize))
+	mux.HandleFunc("POST /oauth/access_token", s.handle(OpInstagramToken, s.instagramToken))
+	mux.HandleFunc("GET /access_token", s.handle(OpInstagramLongLivedToken, s.instagramLongLivedToken))
+	mux.HandleFunc("GET /refresh_access_token", s.handle(OpInstagramRefreshToken, s.instagramRefreshToken))
+	mux.HandleFunc("GET /me", s.handle(OpInstagramUserInfo, s.instagramUserInfo))
+	mux.HandleFunc("POST /{id}/media", s.handle(OpInstagramCreateContainer, s.instagramCreateContainer))
+	mux.HandleFunc("POST /{id}/media_publish", s.handle(OpInstagramPublish, s.instagramPublish))
+	mux.HandleFunc("GET /{id}", s.handle(OpInstagramObject, s.instagramObject))
+}
+
+// writeInstagramError writes an error in the format of the Graph API
+func writeInstagramError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"message":    message,
+			"type":       "OAuthException",
+			"code":       code,
+			"fbtrace_id": "fake",
+		},
+	})
+}
+
+// instagramAuthorized checks the access_token parameter and writes a Graph API error if it is not valid
+func (s *Server) instagramAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformInstagram, r.FormValue("access_token")) {
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+		return false
+	}
+	return true
+}
+
+// instagramAuthorize approves the authorization request and redirects back with a code
+func (s *Server) instagramAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformInstagram)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformInstagram, ""), query.Get("state"))
+}
+
+// instagramToken exchanges an authorization code for a short-lived token
+func (s *Server) instagramToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformInstagram)
+	if r.PostFormValue("client_id") != app.id || r.PostFormValue("client_secret") != app.secret {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error_type": "OAuthException", "code": 400, "error_message": "Invalid client_id or client_secret"})
+		return
+	}
+	if _, ok := s.takeCode(models.PlatformInstagram, r.PostFormValue("code")); !ok ||
+		r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != app.redirectURI {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error_type": "OAuthException", "code": 400, "error_message": "This authorization code has been used"})
+		return
+	}
+
+	accessToken, _ := s.IssueToken(models.PlatformInstagram)
+	userID, _ := strconv.ParseInt(InstagramUserID, 10, 64)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"user_id":      userID,
+	})
+}
+
+// instagramLongLivedToken exchanges a short-lived token for a long-lived one
+func (s *Server) instagramLongLivedToken(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("grant_type") != "ig_exchange_token" || r.FormValue("client_secret") != s.client(models.PlatformInstagram).secret {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid grant_type or client_secret")
+		return
+	}
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+
+	accessToken, _ := s.IssueToken(models.PlatformInstagram)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   instagramLongLifetime,
+	})
+}
+
+// instagramRefreshToken extends a long-lived token
+// Instagram has no separate refresh token; the long-lived token itself is refreshed
+func (s *Server) instagramRefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("grant_type") != "ig_refresh_token" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid grant_type")
+		return
+	}
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+
+	s.RevokeToken(r.FormValue("access_token"))
+	accessToken, _ := s.IssueToken(models.PlatformInstagram)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   instagramLongLifetime,
+	})
+}
+
+// instagramUserInfo returns the fake business account
+func (s *Server) instagramUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"id":           InstagramUserID,
+		"username":     InstagramUsername,
+		"account_type": "BUSINESS",
+	})
+}
+
+// instagramCreateContainer creates an image, video, reel or carousel container
+func (s *Server) instagramCreateContainer(w http.ResponseWriter, r *http.Request) {
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+	if r.PathValue("id") != InstagramUserID {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Unsupported post request. Object does not exist")
+		return
+	}
+
+	container := &InstagramContainer{
+		MediaType:      r.PostFormValue("media_type"),
+		Caption:        r.PostFormValue("caption"),
+		IsCarouselItem: r.PostFormValue("is_carousel_item") == "true",
+	}
+
+	switch container.MediaType {
+	case "":
+		container.MediaType = "IMAGE"
+		container.MediaURL = r.PostFormValue("image_url")
+	case "VIDEO", "REELS", "STORIES":
+		container.MediaURL = r.PostFormValue("video_url")
+		container.processing = s.currentProcessing(models.PlatformInstagram)
+	case "CAROUSEL":
+		container.Children = strings.Split(r.PostFormValue("children"), ",")
+	default:
+		writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Invalid media_type %s", container.MediaType))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if container.MediaType == "CAROUSEL" {
+		if len(container.Children) < 2 || len(container.Children) > 10 {
+			writeInstagramError(w, http.StatusBadRequest, 100, "A carousel needs between 2 and 10 children")
+			return
+		}
+		for _, childID := range container.Children {
+			child, ok := s.igContainers[childID]
+			if !ok || !child.IsCarouselItem {
+				writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Child %s is not a carousel item", childID))
+				return
+			}
+		}
+	} else if container.MediaURL == "" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "The media URL is required")
+		return
+	}
+
+	container.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.igContainers[container.ID] = container
+	writeJSON(w, http.StatusOK, map[string]string{"id": container.ID})
+}
+
+// instagramObject returns fields of a container or published post
+func (s *Server) instagramObject(w http.ResponseWriter, r *http.Request) {
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	fields := strings.Split(r.FormValue("fields"), ",")
+	result := map[string]string{"id": id}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if container, ok := s.igContainers[id]; ok {
+		container.polls++
+		status, message := s.instagramStatusLocked(container)
+		if contains(fields, "status_code") {
+			result["status_code"] = status
+		}
+		if contains(fields, "error_message") && message != "" {
+			result["error_message"] = message
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	for _, post := range s.igPosts {
+		if post.ID == id {
+			if contains(fields, "permalink") {
+				result["permalink"] = post.Permalink
+			}
+			writeJSON(w, http.StatusOK, result)
+			return
+		}
+	}
+	writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Object with ID '%s' does not exist", id))
+}
+
+// instagramStatusLocked returns the status code of a container; s.mu must be held
+// Images are ready immediately, videos follow their Processing and carousels wait for their children
+func (s *Server) instagramStatusLocked(container *InstagramContainer) (string, string) {
+	switch container.MediaType {
+	case "IMAGE":
+		return instagramFinished, ""
+	case "CAROUSEL":
+		for _, childID := range container.Children {
+			child := s.igContainers[childID]
+			if status, message := s.instagramStatusLocked(child); status != instagramFinished {
+				return status, message
+			}
+		}
+		return instagramFinished, ""
+	}
+
+	switch {
+	case container.polls <= container.processing.Polls:
+		return instagramInProgress, ""
+	case container.processing.FailReason != "":
+		return instagramError, container.processing.FailReason
+	default:
+		return instagramFinished, ""
+	}
+}
+
+// instagramPublish publishes a finished container
+func (s *Server) instagramPublish(w http.ResponseWriter, r *http.Request) {
+	if !s.instagramAuthorized(w, r) {
+		return
+	}
+	if r.PathValue("id") != InstagramUserID {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Unsupported post request. Object does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	container, ok := s.igContainers[r.PostFormValue("creation_id")]
+	if !ok || container.IsCarouselItem {
+		writeInstagramError(w, http.StatusBadRequest, 100, "The creation_id is not a publishable container")
+		return
+	}
+	if status, _ := s.instagramStatusLocked(container); status != instagramFinished {
+		writeInstagramError(w, http.StatusBadRequest, 9007, "The media is not ready for publishing, please wait for a moment")
+		return
+	}
+
+	post := &InstagramPost{
+		ContainerID: container.ID,
+		MediaType:   container.MediaType,
+		Caption:     container.Caption,
+	}
+	if container.MediaType == "CAROUSEL" {
+		for _, childID := range container.Children {
+			post.MediaURLs = append(post.MediaURLs, s.igContainers[childID].MediaURL)
+		}
+	} else {
+		post.MediaURLs = []string{container.MediaURL}
+	}
+	post.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	post.Permalink = fmt.Sprintf("https://www.instagram.com/p/fake%s/", post.ID)
+	s.igPosts = append(s.igPosts, post)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": post.ID})
+}
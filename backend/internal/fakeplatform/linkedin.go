@@ -0,0 +1,607 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// linkedinVideoPartSize is the size of the parts LinkedIn splits video uploads into
+const linkedinVideoPartSize = 4 * 1024 * 1024
+
+// LinkedIn image and video statuses
+const (
+	linkedinWaitingUpload    = "WAITING_UPLOAD"
+	linkedinProcessing       = "PROCESSING"
+	linkedinAvailable        = "AVAILABLE"
+	linkedinProcessingFailed = "PROCESSING_FAILED"
+)
+
+// LinkedInMedia is an image or video registered with initializeUpload
+type LinkedInMedia struct {
+	URN         string // urn:li:image:... or urn:li:video:...
+	Owner       string
+	Size        int64    // Announced size of a video
+	Parts       [][]byte // Uploaded bytes; images have a single part
+	UploadToken string
+	Finalized   bool // Images are finalized by their upload
+
+	processing Processing
+	polls      int
+}
+
+// Data returns the uploaded bytes in part order
+func (m LinkedInMedia) Data() []byte {
+	var data []byte
+	for _, part := range m.Parts {
+		data = append(data, part...)
+	}
+	return data
+}
+
+// LinkedInPost is a post created with POST /rest/posts
+type LinkedInPost struct {
+	URN        string
+	Author     string
+	Commentary string
+	Visibility string
+	MediaURNs  []string // Single media or multi-image, in order
+	MediaTitle string
+	Article    *LinkedInArticle
+}
+
+// LinkedInArticle is the article content of a post
+type LinkedInArticle struct {
+	Source      string
+	Title       string
+	Description string
+	Thumbnail   string
+}
+
+// LinkedInPosts returns the posts created on LinkedIn in order
+func (s *Server) LinkedInPosts() []LinkedInPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]LinkedInPost, 0, len(s.linkedinPosts))
+	for _, post := range s.linkedinPosts {
+		result = append(result, *post)
+	}
+	return result
+}
+
+// LinkedInMedia returns the image or video with the given URN
+func (s *Server) LinkedInMedia(urn string) (LinkedInMedia, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.linkedinMedia[urn]
+	if !ok {
+		return LinkedInMedia{}, false
+	}
+	result := *media
+	result.Parts = append([][]byte(nil), media.Parts...)
+	return result, true
+}
+
+// registerLinkedIn adds the LinkedIn endpoints to mux
+func (s *Server) registerLinkedIn(mux *http.ServeMux) {
+	mux.HandleFunc("GET /oauth/v2/authorization", s.handle(OpLinkedInAuthorize, s.linkedinAuthorize))
+	mux.HandleFunc("POST /oauth/v2/accessToken", s.handle(OpLinkedInToken, s.linkedinToken))
+	mux.HandleFunc("GET /v2/userinfo", s.handle(OpLinkedInUserInfo, s.linkedinUserInfo))
+	mux.HandleFunc("GET /rest/organizationAcls", s.handle(OpLinkedInOrganizations, s.linkedinOrganizationACLs))
+	mux.HandleFunc("GET /rest/organizations/{id}", s.handle(OpLinkedInOrganizations, s.linkedinOrganization))
+	mux.HandleFunc("POST /rest/images", s.handle(OpLinkedInMediaInit, s.linkedinImageAction))
+	mux.HandleFunc("POST /rest/videos", s.handle(OpLinkedInMediaInit, s.linkedinVideoAction))
+	mux.HandleFunc("PUT /linkedin-upload/{urn}/{part}", s.handle(OpLinkedInMediaUpload, s.linkedinUpload))
+	mux.HandleFunc("GET /rest/images/{urn}", s.handle(OpLinkedInMediaStatus, s.linkedinMediaStatus))
+	mux.HandleFunc("GET /rest/videos/{urn}", s.handle(OpLinkedInMediaStatus, s.linkedinMediaStatus))
+	mux.HandleFunc("POST /rest/posts", s.handle(OpLinkedInPost, s.linkedinPost))
+}
+
+// writeLinkedInError writes an error in the format of the LinkedIn REST API
+func writeLinkedInError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"status":           status,
+		"serviceErrorCode": 100,
+		"code":             code,
+		"message":          message,
+	})
+}
+
+// linkedinAuthorized checks the bearer token and, for the versioned API, the version headers
+// Writes a LinkedIn error if the request would be rejected
+func (s *Server) linkedinAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformLinkedIn, bearerToken(r)) {
+		writeLinkedInError(w, http.StatusUnauthorized, "INVALID_ACCESS_TOKEN", "Invalid access token")
+		return false
+	}
+	if strings.HasPrefix(r.URL.Path, "/rest/") {
+		if r.Header.Get("LinkedIn-Version") == "" {
+			writeLinkedInError(w, http.StatusBadRequest, "VERSION_MISSING", "A version must be present. Please specify a version by adding the LinkedIn-Version header")
+			return false
+		}
+		if r.Header.Get("X-Restli-Protocol-Version") != "2.0.0" {
+			writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Rest.li protocol version 2.0.0 is required")
+			return false
+		}
+	}
+	return true
+}
+
+// linkedinAuthorize approves the authorization request and redirects back with a code
+func (s *Server) linkedinAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformLinkedIn)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	if query.Get("scope") == "" {
+		http.Error(w, "scope is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformLinkedIn, ""), query.Get("state"))
+}
+
+// linkedinToken exchanges authorization codes and refresh tokens
+func (s *Server) linkedinToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformLinkedIn)
+	if r.PostFormValue("client_id") != app.id || r.PostFormValue("client_secret") != app.secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "Client authentication failed"})
+		return
+	}
+
+	var accessToken, refreshToken string
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		if _, ok := s.takeCode(models.PlatformLinkedIn, r.PostFormValue("code")); !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "Unable to retrieve access token: authorization code not found"})
+			return
+		}
+		accessToken, refreshToken = s.IssueToken(models.PlatformLinkedIn)
+	case "refresh_token":
+		var ok bool
+		accessToken, refreshToken, ok = s.takeRefreshToken(models.PlatformLinkedIn, r.PostFormValue("refresh_token"))
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "The provided refresh token is invalid"})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "grant_type is not supported"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":             accessToken,
+		"expires_in":               linkedinTokenLifetime,
+		"refresh_token":            refreshToken,
+		"refresh_token_expires_in": linkedinRefreshLifetime,
+		"scope":                    "email,openid,profile,r_organization_admin,w_member_social,w_organization_social",
+	})
+}
+
+// linkedinUserInfo returns the fake member's OpenID Connect profile
+func (s *Server) linkedinUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            LinkedInMemberID,
+		"name":           LinkedInMemberName,
+		"picture":        s.URL + "/media/avatar.jpg",
+		"email":          "member@example.com",
+		"email_verified": true,
+	})
+}
+
+// linkedinOrganizationACLs lists the member's roles: administrator of LinkedInOrganizationID
+// and analyst of LinkedInAnalystOrganizationID, who cannot post
+func (s *Server) linkedinOrganizationACLs(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+	if r.URL.Query().Get("q") != "roleAssignee" {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Unsupported finder")
+		return
+	}
+
+	member := "urn:li:person:" + LinkedInMemberID
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"elements": []map[string]string{
+			{"roleAssignee": member, "state": "APPROVED", "role": "ADMINISTRATOR", "organization": "urn:li:organization:" + LinkedInOrganizationID},
+			{"roleAssignee": member, "state": "APPROVED", "role": "ANALYST", "organization": "urn:li:organization:" + LinkedInAnalystOrganizationID},
+		},
+	})
+}
+
+// linkedinOrganization returns an organization's name
+func (s *Server) linkedinOrganization(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	names := map[string]string{
+		LinkedInOrganizationID:        LinkedInOrganizationName,
+		LinkedInAnalystOrganizationID: "Fake Analyst Organization",
+	}
+	name, ok := names[id]
+	if !ok {
+		writeLinkedInError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("Organization %s not found", id))
+		return
+	}
+	id64, _ := strconv.ParseInt(id, 10, 64)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":            id64,
+		"localizedName": name,
+		"vanityName":    strings.ToLower(strings.ReplaceAll(name, " ", "-")),
+	})
+}
+
+// linkedinCanPostAs reports whether the fake member may create content owned by urn
+func linkedinCanPostAs(urn string) bool {
+	return urn == "urn:li:person:"+LinkedInMemberID || urn == "urn:li:organization:"+LinkedInOrganizationID
+}
+
+// linkedinImageAction registers an image upload
+func (s *Server) linkedinImageAction(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+	if r.URL.Query().Get("action") != "initializeUpload" {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Unsupported action")
+		return
+	}
+
+	var req struct {
+		InitializeUploadRequest struct {
+			Owner string `json:"owner"`
+		} `json:"initializeUploadRequest"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Malformed request body")
+		return
+	}
+	if !linkedinCanPostAs(req.InitializeUploadRequest.Owner) {
+		writeLinkedInError(w, http.StatusForbidden, "ACCESS_DENIED", "Not enough permissions to upload as "+req.InitializeUploadRequest.Owner)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media := &LinkedInMedia{
+		URN:        fmt.Sprintf("urn:li:image:D4E%d", s.newIDLocked()),
+		Owner:      req.InitializeUploadRequest.Owner,
+		Parts:      make([][]byte, 1),
+		processing: s.processing[models.PlatformLinkedIn],
+	}
+	s.linkedinMedia[media.URN] = media
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"value": map[string]interface{}{
+			"uploadUrlExpiresAt": 0,
+			"uploadUrl":          s.linkedinUploadURL(media.URN, 0),
+			"image":              media.URN,
+		},
+	})
+}
+
+// linkedinVideoAction registers or finalizes a video upload
+func (s *Server) linkedinVideoAction(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+
+	switch r.URL.Query().Get("action") {
+	case "initializeUpload":
+		s.linkedinVideoInit(w, r)
+	case "finalizeUpload":
+		s.linkedinVideoFinalize(w, r)
+	default:
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Unsupported action")
+	}
+}
+
+// linkedinVideoInit registers a video upload and returns one upload URL per part
+func (s *Server) linkedinVideoInit(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InitializeUploadRequest struct {
+			Owner         string `json:"owner"`
+			FileSizeBytes int64  `json:"fileSizeBytes"`
+		} `json:"initializeUploadRequest"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.InitializeUploadRequest.FileSizeBytes <= 0 {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "fileSizeBytes is required")
+		return
+	}
+	if !linkedinCanPostAs(req.InitializeUploadRequest.Owner) {
+		writeLinkedInError(w, http.StatusForbidden, "ACCESS_DENIED", "Not enough permissions to upload as "+req.InitializeUploadRequest.Owner)
+		return
+	}
+
+	size := req.InitializeUploadRequest.FileSizeBytes
+	parts := int((size + linkedinVideoPartSize - 1) / linkedinVideoPartSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media := &LinkedInMedia{
+		URN:        fmt.Sprintf("urn:li:video:C5F%d", s.newIDLocked()),
+		Owner:      req.InitializeUploadRequest.Owner,
+		Size:       size,
+		Parts:      make([][]byte, parts),
+		processing: s.processing[models.PlatformLinkedIn],
+	}
+	if parts > 1 {
+		media.UploadToken = fmt.Sprintf("fake-upload-token-%d", s.newIDLocked())
+	}
+	s.linkedinMedia[media.URN] = media
+
+	instructions := make([]map[string]interface{}, 0, parts)
+	for i := 0; i < parts; i++ {
+		first := int64(i) * linkedinVideoPartSize
+		last := first + linkedinVideoPartSize - 1
+		if last >= size {
+			last = size - 1
+		}
+		instructions = append(instructions, map[string]interface{}{
+			"uploadUrl": s.linkedinUploadURL(media.URN, i),
+			"firstByte": first,
+			"lastByte":  last,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"value": map[string]interface{}{
+			"uploadUrlsExpireAt": 0,
+			"video":              media.URN,
+			"uploadInstructions": instructions,
+			"uploadToken":        media.UploadToken,
+		},
+	})
+}
+
+// linkedinVideoFinalize completes a video upload once every part has arrived
+func (s *Server) linkedinVideoFinalize(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FinalizeUploadRequest struct {
+			Video           string   `json:"video"`
+			UploadToken     string   `json:"uploadToken"`
+			UploadedPartIDs []string `json:"uploadedPartIds"`
+		} `json:"finalizeUploadRequest"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Malformed request body")
+		return
+	}
+	finalize := req.FinalizeUploadRequest
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.linkedinMedia[finalize.Video]
+	if !ok || !strings.HasPrefix(media.URN, "urn:li:video:") {
+		writeLinkedInError(w, http.StatusNotFound, "NOT_FOUND", "Video not found")
+		return
+	}
+	if finalize.UploadToken != media.UploadToken {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Invalid upload token")
+		return
+	}
+	if len(finalize.UploadedPartIDs) != len(media.Parts) {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", fmt.Sprintf("Expected %d uploaded part IDs, got %d", len(media.Parts), len(finalize.UploadedPartIDs)))
+		return
+	}
+	for i, partID := range finalize.UploadedPartIDs {
+		if partID != linkedinPartETag(media.URN, i) {
+			writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", fmt.Sprintf("Part %d has not been uploaded", i+1))
+			return
+		}
+	}
+	if int64(len(media.Data())) != media.Size {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Uploaded size does not match fileSizeBytes")
+		return
+	}
+
+	media.Finalized = true
+	w.WriteHeader(http.StatusOK)
+}
+
+// linkedinUploadURL returns the upload URL of one part of an image or video
+func (s *Server) linkedinUploadURL(urn string, part int) string {
+	return fmt.Sprintf("%s/linkedin-upload/%s/%d", s.URL, urn, part)
+}
+
+// linkedinPartETag is the ETag returned for an uploaded part
+func linkedinPartETag(urn string, part int) string {
+	return fmt.Sprintf("etag-%s-%d", strings.ReplaceAll(urn, ":", "-"), part)
+}
+
+// linkedinUpload receives the bytes of an image or of one video part
+// Image uploads need the access token, like on LinkedIn; video part URLs are pre-signed
+func (s *Server) linkedinUpload(w http.ResponseWriter, r *http.Request) {
+	urn := r.PathValue("urn")
+	part, err := strconv.Atoi(r.PathValue("part"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if strings.HasPrefix(urn, "urn:li:image:") && !s.linkedinAuthorized(w, r) {
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.linkedinMedia[urn]
+	if !ok || part < 0 || part >= len(media.Parts) {
+		http.NotFound(w, r)
+		return
+	}
+	if len(data) == 0 {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Empty upload")
+		return
+	}
+
+	media.Parts[part] = data
+	if strings.HasPrefix(urn, "urn:li:image:") {
+		media.Finalized = true
+	}
+	w.Header().Set("ETag", `"`+linkedinPartETag(urn, part)+`"`)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// linkedinMediaStatus returns the processing status of an image or video
+func (s *Server) linkedinMediaStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.linkedinMedia[r.PathValue("urn")]
+	if !ok {
+		writeLinkedInError(w, http.StatusNotFound, "NOT_FOUND", "Media not found")
+		return
+	}
+
+	media.polls++
+	result := map[string]interface{}{"id": media.URN, "owner": media.Owner}
+	status, reason := linkedinStatusLocked(media)
+	result["status"] = status
+	if reason != "" {
+		result["processingFailureReason"] = reason
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// linkedinStatusLocked returns the status of an image or video; s.mu must be held
+// Media is processed after its upload completes, following its Processing
+func linkedinStatusLocked(media *LinkedInMedia) (string, string) {
+	switch {
+	case !media.Finalized:
+		return linkedinWaitingUpload, ""
+	case media.polls <= media.processing.Polls:
+		return linkedinProcessing, ""
+	case media.processing.FailReason != "":
+		return linkedinProcessingFailed, media.processing.FailReason
+	default:
+		return linkedinAvailable, ""
+	}
+}
+
+// linkedinPost creates a post
+func (s *Server) linkedinPost(w http.ResponseWriter, r *http.Request) {
+	if !s.linkedinAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		Author         string `json:"author"`
+		Commentary     string `json:"commentary"`
+		Visibility     string `json:"visibility"`
+		LifecycleState string `json:"lifecycleState"`
+		Distribution   *struct {
+			FeedDistribution string `json:"feedDistribution"`
+		} `json:"distribution"`
+		Content *struct {
+			Media *struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"media"`
+			MultiImage *struct {
+				Images []struct {
+					ID string `json:"id"`
+				} `json:"images"`
+			} `json:"multiImage"`
+			Article *struct {
+				Source      string `json:"source"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				Thumbnail   string `json:"thumbnail"`
+			} `json:"article"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeLinkedInError(w, http.StatusBadRequest, "ILLEGAL_ARGUMENT", "Malformed request body")
+		return
+	}
+	if req.Distribution == nil || req.LifecycleState != "PUBLISHED" {
+		writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", "distribution and lifecycleState PUBLISHED are required")
+		return
+	}
+	if req.Visibility != "PUBLIC" && req.Visibility != "CONNECTIONS" {
+		writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", "Invalid visibility")
+		return
+	}
+	if !linkedinCanPostAs(req.Author) {
+		writeLinkedInError(w, http.StatusForbidden, "ACCESS_DENIED", "Not enough permissions to post as "+req.Author)
+		return
+	}
+
+	post := &LinkedInPost{
+		Author:     req.Author,
+		Commentary: req.Commentary,
+		Visibility: req.Visibility,
+	}
+	if content := req.Content; content != nil {
+		switch {
+		case content.Media != nil:
+			post.MediaURNs = []string{content.Media.ID}
+			post.MediaTitle = content.Media.Title
+		case content.MultiImage != nil:
+			if len(content.MultiImage.Images) < 2 || len(content.MultiImage.Images) > 20 {
+				writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", "A multi-image post needs between 2 and 20 images")
+				return
+			}
+			for _, image := range content.MultiImage.Images {
+				post.MediaURNs = append(post.MediaURNs, image.ID)
+			}
+		case content.Article != nil:
+			if content.Article.Source == "" || content.Article.Title == "" {
+				writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", "An article needs a source and a title")
+				return
+			}
+			post.Article = &LinkedInArticle{
+				Source:      content.Article.Source,
+				Title:       content.Article.Title,
+				Description: content.Article.Description,
+				Thumbnail:   content.Article.Thumbnail,
+			}
+			if post.Article.Thumbnail != "" {
+				post.MediaURNs = []string{post.Article.Thumbnail}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, urn := range post.MediaURNs {
+		media, ok := s.linkedinMedia[urn]
+		if !ok {
+			writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", fmt.Sprintf("Media %s does not exist", urn))
+			return
+		}
+		if media.Owner != req.Author {
+			writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", fmt.Sprintf("Media %s is not owned by the author", urn))
+			return
+		}
+		if status, _ := linkedinStatusLocked(media); status != linkedinAvailable {
+			writeLinkedInError(w, http.StatusUnprocessableEntity, "ILLEGAL_ARGUMENT", fmt.Sprintf("Media %s is not available: %s", urn, status))
+			return
+		}
+	}
+
+	post.URN = fmt.Sprintf("urn:li:share:%d", s.newIDLocked())
+	s.linkedinPosts = append(s.linkedinPosts, post)
+
+	w.Header().Set("x-restli-id", post.URN)
+	w.WriteHeader(http.StatusCreated)
+}
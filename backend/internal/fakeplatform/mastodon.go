@@ -0,0 +1,443 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// mastodonCharacterLimit is the default status length limit of Mastodon instances
+const mastodonCharacterLimit = 500
+
+// MastodonApp is an app registered with POST /api/v1/apps
+type MastodonApp struct {
+	ClientID     string
+	ClientSecret string
+	Name         string
+	Website      string
+	RedirectURI  string
+	Scopes       string
+}
+
+// MastodonMedia is a media attachment uploaded with POST /api/v2/media
+type MastodonMedia struct {
+	ID          string
+	ContentType string
+	FileName    string
+	Data        []byte
+	Description string
+	Focus       string
+
+	processing Processing
+	polls      int
+}
+
+// MastodonStatus is a status created with POST /api/v1/statuses
+type MastodonStatus struct {
+	ID             string
+	Text           string
+	MediaIDs       []string
+	Visibility     string
+	SpoilerText    string
+	Sensitive      bool
+	Language       string
+	IdempotencyKey string
+}
+
+// MastodonURL returns the base URL of the fake Mastodon instance
+// The instance is served separately from the other platforms, like a real instance would be
+func (s *Server) MastodonURL() string {
+	return s.mastodon.URL
+}
+
+// MastodonApps returns the apps registered on the fake instance
+func (s *Server) MastodonApps() []MastodonApp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]MastodonApp, 0, len(s.mastodonApps))
+	for _, app := range s.mastodonApps {
+		result = append(result, *app)
+	}
+	return result
+}
+
+// MastodonStatuses returns the statuses created on Mastodon in order
+func (s *Server) MastodonStatuses() []MastodonStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]MastodonStatus, 0, len(s.mastodonStatuses))
+	for _, status := range s.mastodonStatuses {
+		result = append(result, *status)
+	}
+	return result
+}
+
+// MastodonMedia returns the media attachment with the given ID
+func (s *Server) MastodonMedia(id string) (MastodonMedia, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.mastodonMedia[id]
+	if !ok {
+		return MastodonMedia{}, false
+	}
+	return *media, true
+}
+
+// registerMastodon adds the endpoints of a Mastodon instance to mux
+func (s *Server) registerMastodon(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/apps", s.handle(OpMastodonApps, s.mastodonRegisterApp))
+	mux.HandleFunc("GET /oauth/authorize", s.handle(OpMastodonAuthorize, s.mastodonAuthorize))
+	mux.HandleFunc("POST /oauth/token", s.handle(OpMastodonToken, s.mastodonToken))
+	mux.HandleFunc("GET /api/v1/accounts/verify_credentials", s.handle(OpMastodonAccount, s.mastodonAccount))
+	mux.HandleFunc("POST /api/v2/media", s.handle(OpMastodonMediaUpload, s.mastodonMediaUpload))
+	mux.HandleFunc("GET /api/v1/media/{id}", s.handle(OpMastodonMediaStatus, s.mastodonMediaStatus))
+	mux.HandleFunc("POST /api/v1/statuses", s.handle(OpMastodonStatus, s.mastodonCreateStatus))
+	mux.HandleFunc("GET /api/v1/statuses/{id}", s.handle(OpMastodonStatus, s.mastodonGetStatus))
+}
+
+// writeMastodonError writes an error in the format of the Mastodon API
+func writeMastodonError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// mastodonAuthorized checks the bearer token, writing a Mastodon error if it is invalid
+func (s *Server) mastodonAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformMastodon, bearerToken(r)) {
+		writeMastodonError(w, http.StatusUnauthorized, "The access token is invalid")
+		return false
+	}
+	return true
+}
+
+// mastodonRegisterApp registers an app and returns its client credentials
+func (s *Server) mastodonRegisterApp(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ClientName   string `json:"client_name"`
+		RedirectURIs string `json:"redirect_uris"`
+		Scopes       string `json:"scopes"`
+		Website      string `json:"website"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMastodonError(w, http.StatusBadRequest, "Malformed request body")
+		return
+	}
+	if req.ClientName == "" || req.RedirectURIs == "" {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Application name can't be blank, Redirect URI can't be blank")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.newIDLocked()
+	app := &MastodonApp{
+		ClientID:     fmt.Sprintf("fake-mastodon-client-%d", id),
+		ClientSecret: fmt.Sprintf("fake-mastodon-secret-%d", id),
+		Name:         req.ClientName,
+		Website:      req.Website,
+		RedirectURI:  req.RedirectURIs,
+		Scopes:       req.Scopes,
+	}
+	s.mastodonApps[app.ClientID] = app
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":            fmt.Sprintf("%d", id),
+		"name":          app.Name,
+		"website":       app.Website,
+		"redirect_uri":  app.RedirectURI,
+		"client_id":     app.ClientID,
+		"client_secret": app.ClientSecret,
+	})
+}
+
+// mastodonApp returns the registered app with the given client ID
+func (s *Server) mastodonApp(clientID string) (MastodonApp, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	app, ok := s.mastodonApps[clientID]
+	if !ok {
+		return MastodonApp{}, false
+	}
+	return *app, true
+}
+
+// mastodonAuthorize approves the authorization request of a registered app and redirects back with a code
+func (s *Server) mastodonAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app, ok := s.mastodonApp(query.Get("client_id"))
+	if !ok || query.Get("redirect_uri") != app.RedirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	for _, scope := range strings.Fields(query.Get("scope")) {
+		if !contains(strings.Fields(app.Scopes), scope) {
+			http.Error(w, "The requested scope is invalid, unknown, or malformed.", http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	code := fmt.Sprintf("%s-code-%d", models.PlatformMastodon, s.newIDLocked())
+	s.codes[code] = authCode{platform: models.PlatformMastodon, clientID: app.ClientID}
+	s.mu.Unlock()
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), code, query.Get("state"))
+}
+
+// mastodonToken exchanges an authorization code for an access token that never expires
+func (s *Server) mastodonToken(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.mastodonApp(r.PostFormValue("client_id"))
+	if !ok || r.PostFormValue("client_secret") != app.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "Client authentication failed due to unknown client, no client authentication included, or unsupported authentication method."})
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "The authorization grant type is not supported by the authorization server."})
+		return
+	}
+
+	auth, ok := s.takeCode(models.PlatformMastodon, r.PostFormValue("code"))
+	if !ok || auth.clientID != app.ClientID || r.PostFormValue("redirect_uri") != app.RedirectURI {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "The provided authorization grant is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client."})
+		return
+	}
+
+	accessToken, _ := s.IssueToken(models.PlatformMastodon)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"scope":        app.Scopes,
+		"created_at":   time.Now().Unix(),
+	})
+}
+
+// mastodonAccount returns the fake account
+func (s *Server) mastodonAccount(w http.ResponseWriter, r *http.Request) {
+	if !s.mastodonAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           MastodonAccountID,
+		"username":     MastodonUsername,
+		"acct":         MastodonUsername,
+		"display_name": MastodonDisplayName,
+		"avatar":       s.mastodon.URL + "/avatars/original/missing.png",
+		"url":          s.mastodon.URL + "/@" + MastodonUsername,
+	})
+}
+
+// mastodonMediaUpload stores an uploaded file
+// Images are processed right away unless processing polls are configured; videos always
+// answer 202 and are processed in the background, like on a real instance
+func (s *Server) mastodonMediaUpload(w http.ResponseWriter, r *http.Request) {
+	if !s.mastodonAuthorized(w, r) {
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: File can't be blank")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "video/") {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: File content type is invalid")
+		return
+	}
+	if len(r.FormValue("description")) > 1500 {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Description is too long (maximum is 1500 characters)")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media := &MastodonMedia{
+		ID:          fmt.Sprintf("%d", s.newIDLocked()),
+		ContentType: contentType,
+		FileName:    header.Filename,
+		Data:        data,
+		Description: r.FormValue("description"),
+		Focus:       r.FormValue("focus"),
+		processing:  s.processing[models.PlatformMastodon],
+	}
+	s.mastodonMedia[media.ID] = media
+
+	status := http.StatusOK
+	if strings.HasPrefix(contentType, "video/") || media.processing.Polls > 0 || media.processing.FailReason != "" {
+		status = http.StatusAccepted
+	}
+	writeJSON(w, status, s.mastodonAttachmentLocked(media, status == http.StatusOK))
+}
+
+// mastodonMediaStatus answers 206 while a media attachment is processing and 200 once it is ready
+func (s *Server) mastodonMediaStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.mastodonAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.mastodonMedia[r.PathValue("id")]
+	if !ok {
+		writeMastodonError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+
+	media.polls++
+	switch {
+	case media.polls <= media.processing.Polls:
+		writeJSON(w, http.StatusPartialContent, s.mastodonAttachmentLocked(media, false))
+	case media.processing.FailReason != "":
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: "+media.processing.FailReason)
+	default:
+		writeJSON(w, http.StatusOK, s.mastodonAttachmentLocked(media, true))
+	}
+}
+
+// mastodonAttachmentLocked returns the JSON of a media attachment; s.mu must be held
+func (s *Server) mastodonAttachmentLocked(media *MastodonMedia, processed bool) map[string]interface{} {
+	mediaType := "image"
+	if strings.HasPrefix(media.ContentType, "video/") {
+		mediaType = "video"
+	}
+	var mediaURL interface{}
+	if processed {
+		mediaURL = fmt.Sprintf("%s/system/media_attachments/%s/%s", s.mastodon.URL, media.ID, media.FileName)
+	}
+	return map[string]interface{}{
+		"id":          media.ID,
+		"type":        mediaType,
+		"url":         mediaURL,
+		"description": media.Description,
+	}
+}
+
+// mastodonMediaReadyLocked reports whether a media attachment has been processed; s.mu must be held
+func mastodonMediaReadyLocked(media *MastodonMedia) bool {
+	if !strings.HasPrefix(media.ContentType, "video/") && media.processing.Polls == 0 && media.processing.FailReason == "" {
+		return true
+	}
+	return media.polls > media.processing.Polls && media.processing.FailReason == ""
+}
+
+// mastodonCreateStatus publishes a status
+// Requests repeating an Idempotency-Key return the status created by the first one
+func (s *Server) mastodonCreateStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.mastodonAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		Status      string   `json:"status"`
+		MediaIDs    []string `json:"media_ids"`
+		Visibility  string   `json:"visibility"`
+		SpoilerText string   `json:"spoiler_text"`
+		Sensitive   bool     `json:"sensitive"`
+		Language    string   `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMastodonError(w, http.StatusBadRequest, "Malformed request body")
+		return
+	}
+	if req.Status == "" && len(req.MediaIDs) == 0 {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Text can't be blank")
+		return
+	}
+	if utf8.RuneCountInString(req.Status)+utf8.RuneCountInString(req.SpoilerText) > mastodonCharacterLimit {
+		writeMastodonError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Validation failed: Text character limit of %d exceeded", mastodonCharacterLimit))
+		return
+	}
+	if len(req.MediaIDs) > 4 {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Media attachments limit of 4 exceeded")
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = "public"
+	}
+	if !contains([]string{"public", "unlisted", "private", "direct"}, req.Visibility) {
+		writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Visibility is not included in the list")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey != "" {
+		for _, status := range s.mastodonStatuses {
+			if status.IdempotencyKey == idempotencyKey {
+				writeJSON(w, http.StatusOK, s.mastodonStatusJSON(status))
+				return
+			}
+		}
+	}
+
+	for _, id := range req.MediaIDs {
+		media, ok := s.mastodonMedia[id]
+		if !ok {
+			writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Media attachment "+id+" does not exist")
+			return
+		}
+		if !mastodonMediaReadyLocked(media) {
+			writeMastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Media attachment "+id+" is still being processed")
+			return
+		}
+	}
+
+	status := &MastodonStatus{
+		ID:             fmt.Sprintf("11%d", s.newIDLocked()),
+		Text:           req.Status,
+		MediaIDs:       req.MediaIDs,
+		Visibility:     req.Visibility,
+		SpoilerText:    req.SpoilerText,
+		Sensitive:      req.Sensitive || req.SpoilerText != "",
+		Language:       req.Language,
+		IdempotencyKey: idempotencyKey,
+	}
+	s.mastodonStatuses = append(s.mastodonStatuses, status)
+
+	w.Header().Set("X-RateLimit-Limit", "300")
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", 300-len(s.mastodonStatuses)))
+	w.Header().Set("X-RateLimit-Reset", time.Now().Add(3*time.Hour).UTC().Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, s.mastodonStatusJSON(status))
+}
+
+// mastodonGetStatus returns a status
+func (s *Server) mastodonGetStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.mastodonAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, status := range s.mastodonStatuses {
+		if status.ID == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, s.mastodonStatusJSON(status))
+			return
+		}
+	}
+	writeMastodonError(w, http.StatusNotFound, "Record not found")
+}
+
+// mastodonStatusJSON returns the JSON of a status
+func (s *Server) mastodonStatusJSON(status *MastodonStatus) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           status.ID,
+		"url":          fmt.Sprintf("%s/@%s/%s", s.mastodon.URL, MastodonUsername, status.ID),
+		"uri":          fmt.Sprintf("%s/users/%s/statuses/%s", s.mastodon.URL, MastodonUsername, status.ID),
+		"visibility":   status.Visibility,
+		"spoiler_text": status.SpoilerText,
+		"sensitive":    status.Sensitive,
+	}
+}
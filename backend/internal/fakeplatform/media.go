@@ -0,0 +1,139 @@
+package fakeplatform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"time"
+)
+
+// mp4Timescale is the timescale of the movie and media headers of sample videos
+const mp4Timescale = 1000
+
+// mediaFile is a file served under /media/
+type mediaFile struct {
+	contentType string
+	data        []byte
+}
+
+// AddMedia serves data under /media/name and returns its URL
+// Range requests are supported, like on the CDNs media is normally hosted on
+func (s *Server) AddMedia(name, contentType string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.media[name] = mediaFile{contentType: contentType, data: data}
+	return s.URL + "/media/" + name
+}
+
+// serveMedia serves a file added with AddMedia
+func (s *Server) serveMedia(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	file, ok := s.media[r.PathValue("name")]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	http.ServeContent(w, r, r.PathValue("name"), time.Time{}, bytes.NewReader(file.data))
+}
+
+// SampleJPEG returns a JPEG image of the given size
+func SampleJPEG(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80})
+	return buf.Bytes()
+}
+
+// SampleMP4 returns an MP4 with a single H.264 video track of the given size and duration
+// Only the metadata the media prober reads is real; padding bytes of filler follow in mdat
+// so uploads can be made large enough to need several chunks
+func SampleMP4(width, height int, duration time.Duration, padding int) []byte {
+	ticks := uint32(duration.Milliseconds())
+
+	// ISO base media file format boxes, see ISO/IEC 14496-12
+	mvhd := fullBox("mvhd", 0, concat(
+		u32(0), u32(0), // creation and modification time
+		u32(mp4Timescale), u32(ticks),
+		u32(0x00010000), u16(0x0100), make([]byte, 10), // rate, volume, reserved
+		identityMatrix(),
+		make([]byte, 24), // pre_defined
+		u32(2),           // next_track_ID
+	))
+	tkhd := fullBox("tkhd", 0x000003, concat(
+		u32(0), u32(0), // creation and modification time
+		u32(1), u32(0), u32(ticks), // track_ID, reserved, duration
+		make([]byte, 8),        // reserved
+		u16(0), u16(0), u16(0), // layer, alternate_group, volume
+		u16(0), // reserved
+		identityMatrix(),
+		u32(uint32(width)<<16), u32(uint32(height)<<16),
+	))
+	mdhd := fullBox("mdhd", 0, concat(
+		u32(0), u32(0),
+		u32(mp4Timescale), u32(ticks),
+		u16(0x55c4), u16(0), // language "und", pre_defined
+	))
+	hdlr := fullBox("hdlr", 0, concat(
+		u32(0), []byte("vide"), make([]byte, 12), []byte("VideoHandler\x00"),
+	))
+	avc1 := box("avc1", concat(
+		make([]byte, 6), u16(1), // reserved, data_reference_index
+		make([]byte, 16), // pre_defined and reserved
+		u16(uint16(width)), u16(uint16(height)),
+		u32(0x00480000), u32(0x00480000), // 72 dpi
+		u32(0), u16(1), // reserved, frame_count
+		make([]byte, 32),         // compressorname
+		u16(0x0018), u16(0xffff), // depth, pre_defined
+	))
+	stsd := fullBox("stsd", 0, concat(u32(1), avc1))
+	stbl := box("stbl", stsd)
+	minf := box("minf", stbl)
+	mdia := box("mdia", concat(mdhd, hdlr, minf))
+	trak := box("trak", concat(tkhd, mdia))
+	moov := box("moov", concat(mvhd, trak))
+	ftyp := box("ftyp", concat([]byte("isom"), u32(0x200), []byte("isomiso2avc1mp41")))
+
+	return concat(ftyp, moov, box("mdat", make([]byte, padding)))
+}
+
+// box encodes an MP4 box
+func box(boxType string, payload []byte) []byte {
+	return concat(u32(uint32(8+len(payload))), []byte(boxType), payload)
+}
+
+// fullBox encodes an MP4 box with version 0 and the given flags
+func fullBox(boxType string, flags uint32, payload []byte) []byte {
+	return box(boxType, concat(u32(flags&0xffffff), payload))
+}
+
+// identityMatrix is the transformation matrix of an unrotated track
+func identityMatrix() []byte {
+	return concat(
+		u32(0x00010000), u32(0), u32(0),
+		u32(0), u32(0x00010000), u32(0),
+		u32(0), u32(0), u32(0x40000000),
+	)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
@@ -0,0 +1,537 @@
+package fakeplatform
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// pinterestPrefix is the path the fake Pinterest endpoints are served under, so the authorization
+// page at /oauth/ doesn't collide with the OAuth endpoints of the other platforms
+const pinterestPrefix = "/pinterest"
+
+// pinterestMaxUploadSize is the largest video the fake accepts at its upload URL
+const pinterestMaxUploadSize = 64 * 1024 * 1024
+
+// Statuses of Pinterest media registered with POST /v5/media
+const (
+	pinterestRegistered = "registered"
+	pinterestProcessing = "processing"
+	pinterestSucceeded  = "succeeded"
+	pinterestFailed     = "failed"
+)
+
+// PinterestBoard is a board of the fake Pinterest account
+type PinterestBoard struct {
+	ID          string
+	Name        string
+	Description string
+	Privacy     string
+	Owner       string // Username of the owner
+}
+
+// PinterestMedia is a video registered with POST /v5/media
+type PinterestMedia struct {
+	ID   string
+	Data []byte // Uploaded bytes; nil until the upload arrives
+
+	uploadKey  string // The upload has to carry the key it was registered with
+	processing Processing
+	polls      int
+}
+
+// PinterestPin is a pin created with POST /v5/pins
+type PinterestPin struct {
+	ID          string
+	BoardID     string
+	Title       string
+	Description string
+	Link        string
+	AltText     string
+
+	SourceType    string // image_url or video_id
+	ImageURL      string
+	MediaID       string
+	CoverImageURL string
+}
+
+// PinterestPins returns the pins created on Pinterest in order
+func (s *Server) PinterestPins() []PinterestPin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]PinterestPin, 0, len(s.pinterestPins))
+	for _, pin := range s.pinterestPins {
+		result = append(result, *pin)
+	}
+	return result
+}
+
+// PinterestBoards returns the boards of the fake account, including the ones created through the API
+func (s *Server) PinterestBoards() []PinterestBoard {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]PinterestBoard, 0, len(s.pinterestBoards))
+	for _, board := range s.pinterestBoards {
+		if board.Owner == PinterestUsername {
+			result = append(result, *board)
+		}
+	}
+	return result
+}
+
+// PinterestMedia returns the video with the given media ID
+func (s *Server) PinterestMedia(mediaID string) (PinterestMedia, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.pinterestMedia[mediaID]
+	if !ok {
+		return PinterestMedia{}, false
+	}
+	return *media, true
+}
+
+// pinterestBoards returns the boards the fake starts with: two of the fake account, and a board
+// of another account it can't save pins to
+func pinterestBoards() []*PinterestBoard {
+	return []*PinterestBoard{
+		{ID: PinterestBoardID, Name: PinterestBoardName, Description: "Everything we sell", Privacy: "PUBLIC", Owner: PinterestUsername},
+		{ID: PinterestSecretBoardID, Name: PinterestSecretBoardName, Privacy: "SECRET", Owner: PinterestUsername},
+		{ID: PinterestOtherBoardID, Name: "Someone Else's Board", Privacy: "PUBLIC", Owner: "someone_else"},
+	}
+}
+
+// registerPinterest adds the Pinterest endpoints to mux
+func (s *Server) registerPinterest(mux *http.ServeMux) {
+	api := pinterestPrefix + "/v5"
+	mux.HandleFunc("GET "+pinterestPrefix+"/oauth/{$}", s.handle(OpPinterestAuthorize, s.pinterestAuthorize))
+	mux.HandleFunc("POST "+api+"/oauth/token", s.handle(OpPinterestToken, s.pinterestToken))
+	mux.HandleFunc("GET "+api+"/user_account", s.handle(OpPinterestUserAccount, s.pinterestUserAccount))
+	mux.HandleFunc("GET "+api+"/boards", s.handle(OpPinterestBoards, s.pinterestListBoards))
+	mux.HandleFunc("POST "+api+"/boards", s.handle(OpPinterestBoards, s.pinterestCreateBoard))
+	mux.HandleFunc("POST "+api+"/media", s.handle(OpPinterestMediaRegister, s.pinterestRegisterMedia))
+	mux.HandleFunc("POST "+pinterestPrefix+"/upload/{id}", s.handle(OpPinterestMediaUpload, s.pinterestUpload))
+	mux.HandleFunc("GET "+api+"/media/{id}", s.handle(OpPinterestMediaStatus, s.pinterestMediaStatus))
+	mux.HandleFunc("POST "+api+"/pins", s.handle(OpPinterestPin, s.pinterestCreatePin))
+}
+
+// writePinterestError writes an error in the format of the Pinterest v5 API
+func writePinterestError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"code":    code,
+		"message": message,
+	})
+}
+
+// pinterestAuthorized checks the bearer token and writes a Pinterest error if it is not valid
+func (s *Server) pinterestAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformPinterest, bearerToken(r)) {
+		writePinterestError(w, http.StatusUnauthorized, 2, "Authentication failed.")
+		return false
+	}
+	return true
+}
+
+// pinterestAuthorize approves the authorization request and redirects back with a code
+func (s *Server) pinterestAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformPinterest)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	scopes := strings.Split(query.Get("scope"), ",")
+	if !contains(scopes, "boards:read") || !contains(scopes, "pins:write") {
+		http.Error(w, "the boards:read and pins:write scopes are required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformPinterest, ""), query.Get("state"))
+}
+
+// pinterestToken exchanges authorization codes and refresh tokens
+// The app authenticates with HTTP Basic auth; refreshing keeps the refresh token, like Pinterest
+// does for apps without continuous refresh
+func (s *Server) pinterestToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformPinterest)
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != app.id || secret != app.secret {
+		writePinterestError(w, http.StatusUnauthorized, 2, "Authentication failed.")
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		if _, ok := s.takeCode(models.PlatformPinterest, r.PostFormValue("code")); !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writePinterestError(w, http.StatusBadRequest, 1, "Invalid authorization code")
+			return
+		}
+		accessToken, refreshToken := s.IssueToken(models.PlatformPinterest)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token":             accessToken,
+			"refresh_token":            refreshToken,
+			"response_type":            "authorization_code",
+			"token_type":               "bearer",
+			"expires_in":               pinterestTokenLifetime,
+			"refresh_token_expires_in": pinterestRefreshLifetime,
+			"scope":                    "boards:read,boards:write,pins:read,pins:write,user_accounts:read",
+		})
+	case "refresh_token":
+		s.mu.Lock()
+		valid := s.refreshTokens[r.PostFormValue("refresh_token")] == models.PlatformPinterest
+		var accessToken string
+		if valid {
+			accessToken = fmt.Sprintf("%s-access-%d", models.PlatformPinterest, s.newIDLocked())
+			s.accessTokens[accessToken] = models.PlatformPinterest
+		}
+		s.mu.Unlock()
+		if !valid {
+			writePinterestError(w, http.StatusBadRequest, 1, "Invalid refresh token")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token":  accessToken,
+			"response_type": "refresh_token",
+			"token_type":    "bearer",
+			"expires_in":    pinterestTokenLifetime,
+			"scope":         "boards:read,boards:write,pins:read,pins:write,user_accounts:read",
+		})
+	default:
+		writePinterestError(w, http.StatusBadRequest, 1, "Unsupported grant_type")
+	}
+}
+
+// pinterestUserAccount returns the fake business account
+func (s *Server) pinterestUserAccount(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":            PinterestUserID,
+		"username":      PinterestUsername,
+		"business_name": PinterestBusinessName,
+		"account_type":  "BUSINESS",
+		"profile_image": s.URL + "/media/pinterest-avatar.jpg",
+		"website_url":   "https://shop.example.com",
+	})
+}
+
+// pinterestListBoards lists the boards of the fake account, a page_size at a time
+// The bookmark of the next page is the index of its first board
+func (s *Server) pinterestListBoards(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	pageSize := 25
+	if value := query.Get("page_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > 250 {
+			writePinterestError(w, http.StatusBadRequest, 1, "page_size must be between 1 and 250")
+			return
+		}
+		pageSize = size
+	}
+	start := 0
+	if bookmark := query.Get("bookmark"); bookmark != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(bookmark)
+		if err == nil {
+			start, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil {
+			writePinterestError(w, http.StatusBadRequest, 1, "Invalid bookmark")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var owned []*PinterestBoard
+	for _, board := range s.pinterestBoards {
+		if board.Owner == PinterestUsername {
+			owned = append(owned, board)
+		}
+	}
+
+	items := []map[string]interface{}{}
+	end := min(start+pageSize, len(owned))
+	for i := start; i < end; i++ {
+		items = append(items, pinterestBoardJSON(owned[i]))
+	}
+	var bookmark interface{}
+	if end < len(owned) {
+		bookmark = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items":    items,
+		"bookmark": bookmark,
+	})
+}
+
+// pinterestCreateBoard creates a board of the fake account
+func (s *Server) pinterestCreateBoard(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Privacy     string `json:"privacy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		writePinterestError(w, http.StatusBadRequest, 1, "name is required")
+		return
+	}
+	if req.Privacy == "" {
+		req.Privacy = "PUBLIC"
+	}
+	if req.Privacy != "PUBLIC" && req.Privacy != "SECRET" && req.Privacy != "PROTECTED" {
+		writePinterestError(w, http.StatusBadRequest, 1, "Invalid privacy")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	board := &PinterestBoard{
+		ID:          strconv.FormatInt(900000000000000000+s.newIDLocked(), 10),
+		Name:        req.Name,
+		Description: req.Description,
+		Privacy:     req.Privacy,
+		Owner:       PinterestUsername,
+	}
+	s.pinterestBoards = append(s.pinterestBoards, board)
+	writeJSON(w, http.StatusCreated, pinterestBoardJSON(board))
+}
+
+// pinterestBoardJSON returns a board the way the v5 API does
+func pinterestBoardJSON(board *PinterestBoard) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          board.ID,
+		"name":        board.Name,
+		"description": board.Description,
+		"privacy":     board.Privacy,
+		"owner":       map[string]string{"username": board.Owner},
+		"pin_count":   0,
+	}
+}
+
+// pinterestRegisterMedia registers a video upload and returns the pre-signed upload URL
+func (s *Server) pinterestRegisterMedia(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		MediaType string `json:"media_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MediaType != "video" {
+		writePinterestError(w, http.StatusBadRequest, 1, "media_type must be video")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media := &PinterestMedia{
+		ID:         strconv.FormatInt(5000000000000000000+s.newIDLocked(), 10),
+		processing: s.processing[models.PlatformPinterest],
+	}
+	media.uploadKey = "uploads/fake/" + media.ID
+	s.pinterestMedia[media.ID] = media
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"media_id":   media.ID,
+		"media_type": "video",
+		"upload_url": s.URL + pinterestPrefix + "/upload/" + media.ID,
+		"upload_parameters": map[string]string{
+			"key":              media.uploadKey,
+			"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+			"x-amz-credential": "fake-credential",
+			"x-amz-date":       "20260101T000000Z",
+			"x-amz-signature":  "fake-signature",
+			"policy":           "fake-policy",
+			"Content-Type":     "multipart/form-data",
+		},
+	})
+}
+
+// pinterestUpload receives a video at its pre-signed upload URL, like S3 does: the form fields
+// of the registration come first, then the file
+func (s *Server) pinterestUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(pinterestMaxUploadSize); err != nil {
+		http.Error(w, "request body is not valid multipart form data", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.pinterestMedia[r.PathValue("id")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.FormValue("key") != media.uploadKey || r.FormValue("x-amz-signature") == "" || r.FormValue("policy") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>Invalid according to Policy</Message></Error>", http.StatusForbidden)
+		return
+	}
+	if len(data) == 0 {
+		http.Error(w, "<Error><Code>EntityTooSmall</Code></Error>", http.StatusBadRequest)
+		return
+	}
+
+	media.Data = data
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pinterestMediaStatus returns the processing status of a registered video
+func (s *Server) pinterestMediaStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.pinterestMedia[r.PathValue("id")]
+	if !ok {
+		writePinterestError(w, http.StatusNotFound, 1, "Media not found")
+		return
+	}
+
+	media.polls++
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"media_id":   media.ID,
+		"media_type": "video",
+		"status":     pinterestStatusLocked(media),
+	})
+}
+
+// pinterestStatusLocked returns the status of a video; s.mu must be held
+// Videos are processed after their upload arrives, following their Processing
+func pinterestStatusLocked(media *PinterestMedia) string {
+	switch {
+	case media.Data == nil:
+		return pinterestRegistered
+	case media.polls <= media.processing.Polls:
+		return pinterestProcessing
+	case media.processing.FailReason != "":
+		return pinterestFailed
+	default:
+		return pinterestSucceeded
+	}
+}
+
+// pinterestCreatePin creates an image or video pin on a board of the fake account
+func (s *Server) pinterestCreatePin(w http.ResponseWriter, r *http.Request) {
+	if !s.pinterestAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		BoardID     string `json:"board_id"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Link        string `json:"link"`
+		AltText     string `json:"alt_text"`
+		MediaSource struct {
+			SourceType             string   `json:"source_type"`
+			URL                    string   `json:"url"`
+			MediaID                string   `json:"media_id"`
+			CoverImageURL          string   `json:"cover_image_url"`
+			CoverImageKeyFrameTime *float64 `json:"cover_image_key_frame_time"`
+		} `json:"media_source"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writePinterestError(w, http.StatusBadRequest, 1, "Invalid request body")
+		return
+	}
+	for field, limit := range map[string]struct {
+		value string
+		max   int
+	}{"title": {req.Title, 100}, "description": {req.Description, 500}, "alt_text": {req.AltText, 500}} {
+		if utf8.RuneCountInString(limit.value) > limit.max {
+			writePinterestError(w, http.StatusBadRequest, 1, fmt.Sprintf("%s must be at most %d characters", field, limit.max))
+			return
+		}
+	}
+
+	pin := &PinterestPin{
+		BoardID:     req.BoardID,
+		Title:       req.Title,
+		Description: req.Description,
+		Link:        req.Link,
+		AltText:     req.AltText,
+		SourceType:  req.MediaSource.SourceType,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var board *PinterestBoard
+	for _, b := range s.pinterestBoards {
+		if b.ID == req.BoardID {
+			board = b
+		}
+	}
+	if board == nil {
+		writePinterestError(w, http.StatusNotFound, 1, "Board not found.")
+		return
+	}
+	if board.Owner != PinterestUsername {
+		writePinterestError(w, http.StatusForbidden, 29, "You are not permitted to access that resource.")
+		return
+	}
+
+	switch req.MediaSource.SourceType {
+	case "image_url":
+		if req.MediaSource.URL == "" {
+			writePinterestError(w, http.StatusBadRequest, 1, "media_source.url is required")
+			return
+		}
+		pin.ImageURL = req.MediaSource.URL
+	case "video_id":
+		media, ok := s.pinterestMedia[req.MediaSource.MediaID]
+		if !ok {
+			writePinterestError(w, http.StatusBadRequest, 1, "Media not found")
+			return
+		}
+		if status := pinterestStatusLocked(media); status != pinterestSucceeded {
+			writePinterestError(w, http.StatusBadRequest, 2903, fmt.Sprintf("Video upload is not ready: %s", status))
+			return
+		}
+		if req.MediaSource.CoverImageURL == "" && req.MediaSource.CoverImageKeyFrameTime == nil {
+			writePinterestError(w, http.StatusBadRequest, 1, "A video pin needs cover_image_url or cover_image_key_frame_time")
+			return
+		}
+		pin.MediaID = media.ID
+		pin.CoverImageURL = req.MediaSource.CoverImageURL
+	default:
+		writePinterestError(w, http.StatusBadRequest, 1, "Unsupported media_source.source_type")
+		return
+	}
+
+	pin.ID = strconv.FormatInt(800000000000000000+s.newIDLocked(), 10)
+	s.pinterestPins = append(s.pinterestPins, pin)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":          pin.ID,
+		"board_id":    pin.BoardID,
+		"title":       pin.Title,
+		"description": pin.Description,
+		"link":        pin.Link,
+		"alt_text":    pin.AltText,
+	})
+}
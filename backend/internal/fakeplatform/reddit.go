@@ -0,0 +1,766 @@
+package fakeplatform
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// redditPrefix is the path the fake Reddit endpoints are served under, so its /api routes don't
+// collide with the other platforms
+// Reddit serves the authorization page and the token endpoint from www.reddit.com and the API
+// from oauth.reddit.com; the fake serves both
+const redditPrefix = "/reddit"
+
+// redditMaxUploadSize is the largest file the fake media store accepts
+const redditMaxUploadSize = 64 * 1024 * 1024
+
+// redditMaxTitleLength is the longest title Reddit accepts in any subreddit
+const redditMaxTitleLength = 300
+
+// RedditPost is a post submitted with POST /api/submit
+type RedditPost struct {
+	ID        string
+	Subreddit string
+	Kind      string // self, link, image or video
+	Title     string
+	Text      string // Body of a self post
+	URL       string // Link of a link post, or the uploaded media of an image or video post
+	FlairID   string
+	FlairText string
+	NSFW      bool
+	Spoiler   bool
+
+	MediaType      string // MIME type of the uploaded image or video
+	VideoPosterURL string
+	Comments       []string // Comments of the account on the post, in order
+
+	createdAt  time.Time
+	processing Processing // Image and video posts appear once processed
+	polls      int
+}
+
+// RedditAsset is a file uploaded to the fake media store
+type RedditAsset struct {
+	ID       string
+	MIMEType string
+	URL      string // Where the media store keeps the file
+	Data     []byte // Uploaded bytes; nil until the upload arrives
+
+	key string // The upload has to carry the key it was registered with
+}
+
+// redditSubreddit is a subreddit of the fake, with its post requirements and flairs
+type redditSubreddit struct {
+	name              string
+	subredditType     string // public or restricted
+	submissionType    string // any, link or self
+	allowImages       bool
+	allowVideos       bool
+	subscribed        bool
+	titleMinLength    int
+	titleBlacklist    []string
+	bodyRestriction   string // none, required or notAllowed
+	domainBlacklist   []string
+	isFlairRequired   bool
+	flairs            []redditFlair
+	canAssignFlair    bool
+	userIsContributor bool
+}
+
+// redditFlair is a post flair template of a fake subreddit
+type redditFlair struct {
+	id           string
+	text         string
+	textEditable bool
+	modOnly      bool
+}
+
+// RedditPosts returns the posts submitted to Reddit in order
+func (s *Server) RedditPosts() []RedditPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]RedditPost, 0, len(s.redditPosts))
+	for _, post := range s.redditPosts {
+		copied := *post
+		copied.Comments = append([]string(nil), post.Comments...)
+		result = append(result, copied)
+	}
+	return result
+}
+
+// redditSubreddits returns the subreddits the fake starts with
+func redditSubreddits() map[string]*redditSubreddit {
+	subreddits := []*redditSubreddit{
+		{
+			name: RedditSubreddit, subredditType: "public", submissionType: "any", allowImages: true, allowVideos: true,
+			subscribed: true, titleMinLength: RedditTitleMinLength, titleBlacklist: []string{"clickbait"},
+			bodyRestriction: "none", domainBlacklist: []string{RedditBlockedDomain}, canAssignFlair: true,
+			flairs: []redditFlair{
+				{id: RedditFlairID, text: "Question"},
+				{id: RedditEditableFlairID, text: "Discussion", textEditable: true},
+				{id: RedditModFlairID, text: "Announcement", modOnly: true},
+			},
+		},
+		{
+			name: RedditTextOnlySubreddit, subredditType: "public", submissionType: "self",
+			subscribed: true, bodyRestriction: "required",
+		},
+		{
+			name: RedditFlairRequiredSubreddit, subredditType: "public", submissionType: "any", allowImages: true,
+			subscribed: true, bodyRestriction: "none", isFlairRequired: true, canAssignFlair: true,
+			flairs: []redditFlair{{id: RedditRequiredFlairID, text: "Showcase"}},
+		},
+		{
+			name: RedditRestrictedSubreddit, subredditType: "restricted", submissionType: "any", allowImages: true, allowVideos: true,
+			bodyRestriction: "none",
+		},
+	}
+
+	result := make(map[string]*redditSubreddit, len(subreddits))
+	for _, subreddit := range subreddits {
+		result[strings.ToLower(subreddit.name)] = subreddit
+	}
+	return result
+}
+
+// registerReddit adds the Reddit endpoints to mux
+func (s *Server) registerReddit(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+redditPrefix+"/api/v1/authorize", s.handle(OpRedditAuthorize, s.redditAuthorize))
+	mux.HandleFunc("POST "+redditPrefix+"/api/v1/access_token", s.handle(OpRedditToken, s.redditToken))
+	mux.HandleFunc("GET "+redditPrefix+"/api/v1/me", s.handle(OpRedditMe, s.redditMe))
+	mux.HandleFunc("GET "+redditPrefix+"/subreddits/mine/subscriber", s.handle(OpRedditSubreddits, s.redditSubscribed))
+	mux.HandleFunc("GET "+redditPrefix+"/r/{name}/about", s.handle(OpRedditAbout, s.redditAbout))
+	mux.HandleFunc("GET "+redditPrefix+"/api/v1/{name}/post_requirements", s.handle(OpRedditPostRequirements, s.redditPostRequirements))
+	mux.HandleFunc("GET "+redditPrefix+"/r/{name}/api/link_flair_v2", s.handle(OpRedditFlairs, s.redditFlairs))
+	mux.HandleFunc("POST "+redditPrefix+"/api/media/asset.json", s.handle(OpRedditMediaAsset, s.redditMediaAsset))
+	mux.HandleFunc("POST "+redditPrefix+"/media-store", s.handle(OpRedditMediaUpload, s.redditUpload))
+	mux.HandleFunc("POST "+redditPrefix+"/api/submit", s.handle(OpRedditSubmit, s.redditSubmit))
+	mux.HandleFunc("GET "+redditPrefix+"/user/{name}/submitted", s.handle(OpRedditSubmitted, s.redditSubmitted))
+	mux.HandleFunc("POST "+redditPrefix+"/api/comment", s.handle(OpRedditComment, s.redditComment))
+}
+
+// writeRedditError writes an error in the format of the Reddit API
+func writeRedditError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"message": message,
+		"error":   status,
+	})
+}
+
+// writeRedditFormErrors answers a form posted with api_type=json with errors, like Reddit does: with status 200
+func writeRedditFormErrors(w http.ResponseWriter, name, message, field string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"json": map[string]interface{}{"errors": [][]string{{name, message, field}}},
+	})
+}
+
+// redditAuthorized checks the user agent and the bearer token and writes a Reddit error if
+// either is not valid; authorized responses carry the rate limit headers
+func (s *Server) redditAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !redditUserAgentValid(r) {
+		writeRedditError(w, http.StatusTooManyRequests, "Too Many Requests")
+		return false
+	}
+	if !s.validToken(models.PlatformReddit, bearerToken(r)) {
+		writeRedditError(w, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+	w.Header().Set("X-Ratelimit-Used", "4")
+	w.Header().Set("X-Ratelimit-Remaining", "596.0")
+	w.Header().Set("X-Ratelimit-Reset", "420")
+	return true
+}
+
+// redditUserAgentValid reports whether a request has a descriptive user agent; Reddit throttles
+// generic ones, like the default of Go's HTTP client
+func redditUserAgentValid(r *http.Request) bool {
+	userAgent := r.Header.Get("User-Agent")
+	return userAgent != "" && !strings.HasPrefix(userAgent, "Go-http-client")
+}
+
+// redditAuthorize approves the authorization request and redirects back with a code
+func (s *Server) redditAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformReddit)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	if query.Get("duration") != "permanent" {
+		http.Error(w, "only permanent authorizations come with a refresh token", http.StatusBadRequest)
+		return
+	}
+	scopes := strings.Fields(query.Get("scope"))
+	if !contains(scopes, "identity") || !contains(scopes, "submit") {
+		http.Error(w, "the identity and submit scopes are required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformReddit, ""), query.Get("state"))
+}
+
+// redditToken exchanges authorization codes and refresh tokens
+// The app authenticates with HTTP Basic auth. Rejected grants are answered with status 200 and
+// an error, and refreshing keeps the refresh token, like Reddit does
+func (s *Server) redditToken(w http.ResponseWriter, r *http.Request) {
+	if !redditUserAgentValid(r) {
+		writeRedditError(w, http.StatusTooManyRequests, "Too Many Requests")
+		return
+	}
+	app := s.client(models.PlatformReddit)
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != app.id || secret != app.secret {
+		writeRedditError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		if _, ok := s.takeCode(models.PlatformReddit, r.PostFormValue("code")); !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writeJSON(w, http.StatusOK, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		accessToken, refreshToken := s.IssueToken(models.PlatformReddit)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+			"token_type":    "bearer",
+			"expires_in":    redditTokenLifetime,
+			"scope":         "identity submit read flair mysubreddits",
+		})
+	case "refresh_token":
+		s.mu.Lock()
+		valid := s.refreshTokens[r.PostFormValue("refresh_token")] == models.PlatformReddit
+		var accessToken string
+		if valid {
+			accessToken = fmt.Sprintf("%s-access-%d", models.PlatformReddit, s.newIDLocked())
+			s.accessTokens[accessToken] = models.PlatformReddit
+		}
+		s.mu.Unlock()
+		if !valid {
+			writeJSON(w, http.StatusOK, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": accessToken,
+			"token_type":   "bearer",
+			"expires_in":   redditTokenLifetime,
+			"scope":        "identity submit read flair mysubreddits",
+		})
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"error": "unsupported_grant_type"})
+	}
+}
+
+// redditMe returns the fake account
+func (s *Server) redditMe(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":       RedditUserID,
+		"name":     RedditUsername,
+		"icon_img": s.URL + "/media/reddit-avatar.png",
+	})
+}
+
+// redditSubscribed lists the subreddits the fake account is subscribed to, a limit at a time
+// The after of the next page is the fullname of the last subreddit of the page
+func (s *Server) redditSubscribed(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	limit := 25
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for key, subreddit := range s.redditSubreddits {
+		if subreddit.subscribed {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if after := r.URL.Query().Get("after"); after != "" {
+		for i, name := range names {
+			if "t5_"+name == after {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+limit, len(names))
+
+	children := []map[string]interface{}{}
+	for _, name := range names[start:end] {
+		children = append(children, map[string]interface{}{"kind": "t5", "data": redditAboutJSON(s.redditSubreddits[name])})
+	}
+	var after interface{}
+	if end < len(names) {
+		after = "t5_" + names[end-1]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind": "Listing",
+		"data": map[string]interface{}{"children": children, "after": after},
+	})
+}
+
+// redditSubredditLocked returns the subreddit of the {name} of a request; s.mu must be held
+func (s *Server) redditSubredditLocked(r *http.Request) *redditSubreddit {
+	return s.redditSubreddits[strings.ToLower(r.PathValue("name"))]
+}
+
+// redditAbout returns the about page of a subreddit
+func (s *Server) redditAbout(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subreddit := s.redditSubredditLocked(r)
+	if subreddit == nil {
+		writeRedditError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "t5", "data": redditAboutJSON(subreddit)})
+}
+
+// redditAboutJSON returns a subreddit the way its about page does
+func redditAboutJSON(subreddit *redditSubreddit) map[string]interface{} {
+	return map[string]interface{}{
+		"display_name":        subreddit.name,
+		"title":               "Fake r/" + subreddit.name,
+		"subreddit_type":      subreddit.subredditType,
+		"submission_type":     subreddit.submissionType,
+		"allow_images":        subreddit.allowImages,
+		"allow_videos":        subreddit.allowVideos,
+		"over18":              false,
+		"subscribers":         1200,
+		"user_is_banned":      false,
+		"user_is_contributor": subreddit.userIsContributor,
+		"user_is_moderator":   false,
+	}
+}
+
+// redditPostRequirements returns the post requirements of a subreddit
+func (s *Server) redditPostRequirements(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subreddit := s.redditSubredditLocked(r)
+	if subreddit == nil {
+		writeRedditError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var titleMinLength interface{}
+	if subreddit.titleMinLength > 0 {
+		titleMinLength = subreddit.titleMinLength
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"title_text_min_length":     titleMinLength,
+		"title_text_max_length":     nil,
+		"title_required_strings":    []string{},
+		"title_blacklisted_strings": nonNilStrings(subreddit.titleBlacklist),
+		"body_restriction_policy":   subreddit.bodyRestriction,
+		"body_text_min_length":      nil,
+		"body_text_max_length":      nil,
+		"body_blacklisted_strings":  []string{},
+		"link_restriction_policy":   "none",
+		"domain_whitelist":          []string{},
+		"domain_blacklist":          nonNilStrings(subreddit.domainBlacklist),
+		"is_flair_required":         subreddit.isFlairRequired,
+	})
+}
+
+// nonNilStrings returns values, or an empty slice so it is encoded as [] instead of null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// redditFlairs returns the post flair templates of a subreddit
+// Subreddits that don't let users choose flair answer with 403
+func (s *Server) redditFlairs(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subreddit := s.redditSubredditLocked(r)
+	if subreddit == nil {
+		writeRedditError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if !subreddit.canAssignFlair {
+		writeRedditError(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	flairs := []map[string]interface{}{}
+	for _, flair := range subreddit.flairs {
+		flairs = append(flairs, map[string]interface{}{
+			"id":            flair.id,
+			"text":          flair.text,
+			"text_editable": flair.textEditable,
+			"mod_only":      flair.modOnly,
+			"type":          "text",
+		})
+	}
+	writeJSON(w, http.StatusOK, flairs)
+}
+
+// redditMediaAsset registers an upload to the media store and returns the form to upload it with
+func (s *Server) redditMediaAsset(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	mimeType := r.PostFormValue("mimetype")
+	if r.PostFormValue("filepath") == "" || !(strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "video/")) {
+		writeRedditError(w, http.StatusBadRequest, "filepath and an image or video mimetype are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	asset := &RedditAsset{
+		ID:       strconv.FormatInt(s.newIDLocked(), 36),
+		MIMEType: mimeType,
+	}
+	asset.key = "rte_images/" + asset.ID + "/" + r.PostFormValue("filepath")
+	asset.URL = s.URL + redditPrefix + "/media-store/" + asset.key
+	s.redditAssets[asset.key] = asset
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"args": map[string]interface{}{
+			"action": s.URL + redditPrefix + "/media-store",
+			"fields": []map[string]string{
+				{"name": "acl", "value": "private"},
+				{"name": "key", "value": asset.key},
+				{"name": "Content-Type", "value": mimeType},
+				{"name": "x-amz-algorithm", "value": "AWS4-HMAC-SHA256"},
+				{"name": "x-amz-credential", "value": "fake-credential"},
+				{"name": "x-amz-date", "value": "20260101T000000Z"},
+				{"name": "success_action_status", "value": "201"},
+				{"name": "policy", "value": "fake-policy"},
+				{"name": "x-amz-signature", "value": "fake-signature"},
+			},
+		},
+		"asset": map[string]interface{}{
+			"asset_id":         asset.ID,
+			"processing_state": "incomplete",
+			"payload":          map[string]string{"filepath": r.PostFormValue("filepath")},
+		},
+	})
+}
+
+// redditUpload receives a file at the media store, like S3 does: the form fields of the
+// registration come first, then the file; the stored file's URL is the Location of the answer
+func (s *Server) redditUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(redditMaxUploadSize); err != nil {
+		http.Error(w, "request body is not valid multipart form data", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	asset, ok := s.redditAssets[r.FormValue("key")]
+	if !ok || r.FormValue("x-amz-signature") == "" || r.FormValue("policy") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>Invalid according to Policy</Message></Error>", http.StatusForbidden)
+		return
+	}
+	if len(data) == 0 {
+		http.Error(w, "<Error><Code>EntityTooSmall</Code></Error>", http.StatusBadRequest)
+		return
+	}
+
+	asset.Data = data
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "<PostResponse><Location>%s</Location><Bucket>fake-media</Bucket><Key>%s</Key><ETag>\"fake\"</ETag></PostResponse>",
+		asset.URL, asset.key)
+}
+
+// redditAssetLocked returns the uploaded file stored at a URL; s.mu must be held
+func (s *Server) redditAssetLocked(assetURL string) *RedditAsset {
+	for _, asset := range s.redditAssets {
+		if asset.URL == assetURL && asset.Data != nil {
+			return asset
+		}
+	}
+	return nil
+}
+
+// redditSubmit submits a post, enforcing the rules of its subreddit like Reddit does
+// Self and link posts are answered with their ID; image and video posts only with the page of the
+// account's submissions, where they appear once processed
+func (s *Server) redditSubmit(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	if r.PostFormValue("api_type") != "json" {
+		writeRedditError(w, http.StatusBadRequest, "api_type=json is expected")
+		return
+	}
+
+	post := &RedditPost{
+		Subreddit: r.PostFormValue("sr"),
+		Kind:      r.PostFormValue("kind"),
+		Title:     r.PostFormValue("title"),
+		FlairID:   r.PostFormValue("flair_id"),
+		FlairText: r.PostFormValue("flair_text"),
+		NSFW:      r.PostFormValue("nsfw") == "true",
+		Spoiler:   r.PostFormValue("spoiler") == "true",
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subreddit := s.redditSubreddits[strings.ToLower(post.Subreddit)]
+	switch {
+	case subreddit == nil:
+		writeRedditFormErrors(w, "SUBREDDIT_NOEXIST", "that subreddit doesn't exist", "sr")
+		return
+	case subreddit.subredditType == "restricted" && !subreddit.userIsContributor:
+		writeRedditFormErrors(w, "SUBREDDIT_NOTALLOWED", "you aren't allowed to post there.", "sr")
+		return
+	case strings.TrimSpace(post.Title) == "":
+		writeRedditFormErrors(w, "NO_TEXT", "we need something here", "title")
+		return
+	case utf8.RuneCountInString(post.Title) > redditMaxTitleLength:
+		writeRedditFormErrors(w, "TOO_LONG", "this is too long (max: 300)", "title")
+		return
+	case subreddit.isFlairRequired && post.FlairID == "":
+		writeRedditFormErrors(w, "SUBMIT_VALIDATION_FLAIR_REQUIRED", "Your post must contain post flair.", "flair")
+		return
+	}
+	post.Subreddit = subreddit.name
+
+	switch post.Kind {
+	case "self":
+		if subreddit.submissionType == "link" {
+			writeRedditFormErrors(w, "NO_SELFS", "This community doesn't allow text posts", "sr")
+			return
+		}
+		post.Text = r.PostFormValue("text")
+		if subreddit.bodyRestriction == "required" && strings.TrimSpace(post.Text) == "" {
+			writeRedditFormErrors(w, "SUBMIT_VALIDATION_BODY_REQUIRED", "Your post must contain body text.", "text")
+			return
+		}
+	case "link":
+		if subreddit.submissionType == "self" {
+			writeRedditFormErrors(w, "NO_LINKS", "This community only allows text posts", "sr")
+			return
+		}
+		post.URL = r.PostFormValue("url")
+		if link, err := url.Parse(post.URL); err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			writeRedditFormErrors(w, "BAD_URL", "you should check that url", "url")
+			return
+		}
+		for _, existing := range s.redditPosts {
+			if existing.Kind == "link" && existing.URL == post.URL && existing.Subreddit == post.Subreddit {
+				writeRedditFormErrors(w, "ALREADY_SUB", "that link has already been submitted", "url")
+				return
+			}
+		}
+	case "image", "video":
+		if subreddit.submissionType == "self" || (post.Kind == "image" && !subreddit.allowImages) || (post.Kind == "video" && !subreddit.allowVideos) {
+			writeRedditFormErrors(w, "NO_LINKS", "This community doesn't allow "+post.Kind+" posts", "sr")
+			return
+		}
+		asset := s.redditAssetLocked(r.PostFormValue("url"))
+		if asset == nil || !strings.HasPrefix(asset.MIMEType, post.Kind+"/") {
+			writeRedditFormErrors(w, "BAD_URL", "the "+post.Kind+" was not uploaded", "url")
+			return
+		}
+		post.URL = asset.URL
+		post.MediaType = asset.MIMEType
+		if post.Kind == "video" {
+			poster := s.redditAssetLocked(r.PostFormValue("video_poster_url"))
+			if poster == nil || !strings.HasPrefix(poster.MIMEType, "image/") {
+				writeRedditFormErrors(w, "BAD_URL", "video posts need an uploaded poster image", "video_poster_url")
+				return
+			}
+			post.VideoPosterURL = poster.URL
+		}
+		post.processing = s.processing[models.PlatformReddit]
+	default:
+		writeRedditFormErrors(w, "INVALID_OPTION", "that option is not valid", "kind")
+		return
+	}
+
+	if post.FlairID != "" {
+		var flair *redditFlair
+		for i := range subreddit.flairs {
+			if subreddit.flairs[i].id == post.FlairID {
+				flair = &subreddit.flairs[i]
+			}
+		}
+		if flair == nil || flair.modOnly || !subreddit.canAssignFlair {
+			writeRedditFormErrors(w, "BAD_FLAIR_TARGET", "that flair can't be used here", "flair")
+			return
+		}
+		if post.FlairText != "" && !flair.textEditable {
+			post.FlairText = ""
+		}
+	}
+
+	post.ID = strconv.FormatInt(s.newIDLocked(), 36)
+	post.createdAt = time.Now()
+	s.redditPosts = append(s.redditPosts, post)
+
+	if post.Kind == "image" || post.Kind == "video" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"json": map[string]interface{}{
+				"errors": []interface{}{},
+				"data": map[string]string{
+					"user_submitted_page": "https://www.reddit.com/user/" + RedditUsername + "/submitted/",
+					"websocket_url":       "wss://ws-fake.redditmedia.com/rte_images/" + post.ID,
+				},
+			},
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"json": map[string]interface{}{
+			"errors": []interface{}{},
+			"data": map[string]interface{}{
+				"url":          "https://www.reddit.com" + redditPermalink(post),
+				"drafts_count": 0,
+				"id":           post.ID,
+				"name":         "t3_" + post.ID,
+			},
+		},
+	})
+}
+
+// redditPublishedLocked reports whether a post is published yet; s.mu must be held
+// Image and video posts are published after their Processing, once per listing of the submissions
+func redditPublishedLocked(post *RedditPost) bool {
+	if post.Kind != "image" && post.Kind != "video" {
+		return true
+	}
+	return post.polls > post.processing.Polls && post.processing.FailReason == ""
+}
+
+// redditPermalink returns the path of a post
+func redditPermalink(post *RedditPost) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(post.Title))
+	if len(slug) > 50 {
+		slug = slug[:50]
+	}
+	return "/r/" + post.Subreddit + "/comments/" + post.ID + "/" + slug + "/"
+}
+
+// redditSubmitted lists the published posts of the fake account, newest first
+func (s *Server) redditSubmitted(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	if !strings.EqualFold(r.PathValue("name"), RedditUsername) {
+		writeRedditError(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+	limit := 25
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	children := []map[string]interface{}{}
+	for i := len(s.redditPosts) - 1; i >= 0 && len(children) < limit; i-- {
+		post := s.redditPosts[i]
+		post.polls++
+		if !redditPublishedLocked(post) {
+			continue
+		}
+		children = append(children, map[string]interface{}{
+			"kind": "t3",
+			"data": map[string]interface{}{
+				"id":          post.ID,
+				"name":        "t3_" + post.ID,
+				"title":       post.Title,
+				"subreddit":   post.Subreddit,
+				"permalink":   redditPermalink(post),
+				"url":         post.URL,
+				"over_18":     post.NSFW,
+				"spoiler":     post.Spoiler,
+				"created_utc": float64(post.createdAt.Unix()),
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind": "Listing",
+		"data": map[string]interface{}{"children": children, "after": nil},
+	})
+}
+
+// redditComment comments on a post of the fake account
+func (s *Server) redditComment(w http.ResponseWriter, r *http.Request) {
+	if !s.redditAuthorized(w, r) {
+		return
+	}
+	text := r.PostFormValue("text")
+	if strings.TrimSpace(text) == "" {
+		writeRedditFormErrors(w, "NO_TEXT", "we need something here", "text")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, post := range s.redditPosts {
+		if "t3_"+post.ID == r.PostFormValue("thing_id") && redditPublishedLocked(post) {
+			post.Comments = append(post.Comments, text)
+			commentID := strconv.FormatInt(s.newIDLocked(), 36)
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"json": map[string]interface{}{
+					"errors": []interface{}{},
+					"data": map[string]interface{}{
+						"things": []map[string]interface{}{{"kind": "t1", "data": map[string]string{"id": commentID, "name": "t1_" + commentID, "parent_id": "t3_" + post.ID}}},
+					},
+				},
+			})
+			return
+		}
+	}
+	writeRedditFormErrors(w, "NO_THING_ID", "that thing doesn't exist", "parent")
+}
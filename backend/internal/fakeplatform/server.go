@@ -0,0 +1,929 @@
+// Package fakeplatform is an in-process fake of the TikTok, X, Instagram, Threads, Facebook, LinkedIn, YouTube,
+// Pinterest, Reddit and Telegram Bot APIs, of a Mastodon instance, of a Bluesky PDS, of Discord and Slack incoming
+// webhooks and of an endpoint receiving signed webhook deliveries
+// It simulates OAuth, media uploads, asynchronous processing, rate limits and failures
+// so the posting flow can be exercised end to end without reaching the real platforms
+package fakeplatform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// Op identifies one fake endpoint for failure injection, latency and call counts
+type Op string
+
+// TikTok operations
+const (
+	OpTikTokAuthorize   Op = "tiktok.authorize"
+	OpTikTokToken       Op = "tiktok.token"
+	OpTikTokUserInfo    Op = "tiktok.user_info"
+	OpTikTokCreatorInfo Op = "tiktok.creator_info"
+	OpTikTokPublish     Op = "tiktok.publish" // Video, inbox and photo init
+	OpTikTokStatus      Op = "tiktok.status"
+)
+
+// X operations
+const (
+	OpXAuthorize     Op = "x.authorize"
+	OpXToken         Op = "x.token"
+	OpXUserInfo      Op = "x.user_info"
+	OpXMediaInit     Op = "x.media_init"
+	OpXMediaAppend   Op = "x.media_append"
+	OpXMediaFinalize Op = "x.media_finalize"
+	OpXMediaStatus   Op = "x.media_status"
+	OpXTweet         Op = "x.tweet"
+)
+
+// Instagram operations
+const (
+	OpInstagramAuthorize       Op = "instagram.authorize"
+	OpInstagramToken           Op = "instagram.token" // Short-lived token exchange
+	OpInstagramLongLivedToken  Op = "instagram.long_lived_token"
+	OpInstagramRefreshToken    Op = "instagram.refresh_token"
+	OpInstagramUserInfo        Op = "instagram.user_info"
+	OpInstagramCreateContainer Op = "instagram.create_container"
+	OpInstagramObject          Op = "instagram.object" // Container status and permalink lookups
+	OpInstagramPublish         Op = "instagram.publish"
+)
+
+// Threads operations
+const (
+	OpThreadsAuthorize       Op = "threads.authorize"
+	OpThreadsToken           Op = "threads.token" // Short-lived token exchange
+	OpThreadsLongLivedToken  Op = "threads.long_lived_token"
+	OpThreadsRefreshToken    Op = "threads.refresh_token"
+	OpThreadsUserInfo        Op = "threads.user_info"
+	OpThreadsCreateContainer Op = "threads.create_container"
+	OpThreadsObject          Op = "threads.object" // Container status and permalink lookups
+	OpThreadsPublish         Op = "threads.publish"
+)
+
+// Facebook operations
+const (
+	OpFacebookAuthorize      Op = "facebook.authorize"
+	OpFacebookToken          Op = "facebook.token" // Short-lived token exchange
+	OpFacebookLongLivedToken Op = "facebook.long_lived_token"
+	OpFacebookUserInfo       Op = "facebook.user_info"
+	OpFacebookPages          Op = "facebook.pages"
+	OpFacebookFeed           Op = "facebook.feed"
+	OpFacebookPhotos         Op = "facebook.photos"
+	OpFacebookVideos         Op = "facebook.videos"
+	OpFacebookObject         Op = "facebook.object" // Video status lookups
+)
+
+// LinkedIn operations
+const (
+	OpLinkedInAuthorize     Op = "linkedin.authorize"
+	OpLinkedInToken         Op = "linkedin.token"
+	OpLinkedInUserInfo      Op = "linkedin.user_info"
+	OpLinkedInOrganizations Op = "linkedin.organizations" // Organization roles and lookups
+	OpLinkedInMediaInit     Op = "linkedin.media_init"    // Image and video initializeUpload and finalizeUpload
+	OpLinkedInMediaUpload   Op = "linkedin.media_upload"
+	OpLinkedInMediaStatus   Op = "linkedin.media_status"
+	OpLinkedInPost          Op = "linkedin.post"
+)
+
+// YouTube operations
+const (
+	OpYouTubeAuthorize   Op = "youtube.authorize"
+	OpYouTubeToken       Op = "youtube.token"
+	OpYouTubeChannels    Op = "youtube.channels"
+	OpYouTubeUploadInit  Op = "youtube.upload_init"
+	OpYouTubeUpload      Op = "youtube.upload" // Chunks and upload status queries
+	OpYouTubeVideoStatus Op = "youtube.video_status"
+)
+
+// Mastodon operations
+const (
+	OpMastodonApps        Op = "mastodon.apps"
+	OpMastodonAuthorize   Op = "mastodon.authorize"
+	OpMastodonToken       Op = "mastodon.token"
+	OpMastodonAccount     Op = "mastodon.account"
+	OpMastodonMediaUpload Op = "mastodon.media_upload"
+	OpMastodonMediaStatus Op = "mastodon.media_status"
+	OpMastodonStatus      Op = "mastodon.status" // Status creation and lookups
+)
+
+// Bluesky operations
+const (
+	OpBlueskySession       Op = "bluesky.session" // createSession, refreshSession and getSession
+	OpBlueskyProfile       Op = "bluesky.profile"
+	OpBlueskyResolveHandle Op = "bluesky.resolve_handle"
+	OpBlueskyUploadBlob    Op = "bluesky.upload_blob"
+	OpBlueskyCreateRecord  Op = "bluesky.create_record"
+	OpBlueskyGetPosts      Op = "bluesky.get_posts"
+)
+
+// Pinterest operations
+const (
+	OpPinterestAuthorize     Op = "pinterest.authorize"
+	OpPinterestToken         Op = "pinterest.token"
+	OpPinterestUserAccount   Op = "pinterest.user_account"
+	OpPinterestBoards        Op = "pinterest.boards" // Board listing and creation
+	OpPinterestMediaRegister Op = "pinterest.media_register"
+	OpPinterestMediaUpload   Op = "pinterest.media_upload" // Uploads to the pre-signed upload URL
+	OpPinterestMediaStatus   Op = "pinterest.media_status"
+	OpPinterestPin           Op = "pinterest.pin"
+)
+
+// Reddit operations
+const (
+	OpRedditAuthorize        Op = "reddit.authorize"
+	OpRedditToken            Op = "reddit.token"
+	OpRedditMe               Op = "reddit.me"
+	OpRedditSubreddits       Op = "reddit.subreddits" // Subreddits the account is subscribed to
+	OpRedditAbout            Op = "reddit.about"
+	OpRedditPostRequirements Op = "reddit.post_requirements"
+	OpRedditFlairs           Op = "reddit.flairs"
+	OpRedditMediaAsset       Op = "reddit.media_asset"
+	OpRedditMediaUpload      Op = "reddit.media_upload" // Uploads to the media store
+	OpRedditSubmit           Op = "reddit.submit"
+	OpRedditSubmitted        Op = "reddit.submitted" // Submissions of the account
+	OpRedditComment          Op = "reddit.comment"
+)
+
+// Telegram operations
+const (
+	OpTelegramGetMe   Op = "telegram.get_me"
+	OpTelegramGetChat Op = "telegram.get_chat" // getChat and getChatMember
+	OpTelegramSend    Op = "telegram.send"     // sendMessage, sendPhoto, sendVideo and sendMediaGroup
+	OpTelegramDelete  Op = "telegram.delete"
+)
+
+// Discord operations
+const (
+	OpDiscordWebhook       Op = "discord.webhook"
+	OpDiscordExecute       Op = "discord.execute"
+	OpDiscordDeleteMessage Op = "discord.delete_message"
+)
+
+// Slack operations
+const (
+	OpSlackWebhook Op = "slack.webhook"
+)
+
+// Webhook operations
+const (
+	OpWebhookDeliver Op = "webhook.deliver"
+)
+
+// OpMedia serves the media files added with AddMedia
+const OpMedia Op = "media"
+
+// Identities of the fake accounts
+const (
+	TikTokOpenID      = "fake-tiktok-open-id"
+	TikTokDisplayName = "Fake TikTok Creator"
+	XUserID           = "1500000000000000001"
+	XUsername         = "fake_x_user"
+	InstagramUserID   = "17841400000000001"
+	InstagramUsername = "fake_ig_user"
+	ThreadsUserID     = "27000000000000001"
+	ThreadsUsername   = "fake_threads_user"
+	ThreadsName       = "Fake Threads User"
+
+	FacebookUserID          = "10000000000000001"
+	FacebookUserName        = "Fake Facebook User"
+	FacebookPageID          = "20000000000000001"
+	FacebookPageName        = "Fake Bakery"
+	FacebookSecondPageID    = "20000000000000002"
+	FacebookSecondPageName  = "Fake Coffee Shop"
+	FacebookAnalystPageID   = "20000000000000003" // The user is an analyst and cannot post
+	FacebookAnalystPageName = "Fake Restaurant"
+
+	LinkedInMemberID              = "fakeLinkedInMember"
+	LinkedInMemberName            = "Fake LinkedIn Member"
+	LinkedInOrganizationID        = "10000001" // The member is an administrator
+	LinkedInOrganizationName      = "Fake Organization"
+	LinkedInAnalystOrganizationID = "10000002" // The member is an analyst and cannot post
+
+	YouTubeChannelID    = "UCfakeYouTubeChannel00001"
+	YouTubeChannelTitle = "Fake YouTube Channel"
+	YouTubeHandle       = "@fake_youtube_user"
+
+	MastodonAccountID   = "109000000000000001"
+	MastodonUsername    = "fake_mastodon_user"
+	MastodonDisplayName = "Fake Mastodon User"
+
+	BlueskyDID          = "did:plc:fakeblueskyuser000000001"
+	BlueskyHandle       = "fake-user.bsky.social"
+	BlueskyEmail        = "fake-user@example.com"
+	BlueskyDisplayName  = "Fake Bluesky User"
+	BlueskyAppPassword  = "fake-abcd-efgh-ijkl"
+	BlueskyFriendDID    = "did:plc:fakeblueskyfriend00000001" // Another account, which can be mentioned
+	BlueskyFriendHandle = "fake-friend.bsky.social"
+
+	PinterestUserID          = "1100000000000000001"
+	PinterestUsername        = "fake_pinterest_user"
+	PinterestBusinessName    = "Fake Pinterest Shop"
+	PinterestBoardID         = "1200000000000000001"
+	PinterestBoardName       = "Fake Products"
+	PinterestSecretBoardID   = "1200000000000000002"
+	PinterestSecretBoardName = "Fake Drafts"
+	PinterestOtherBoardID    = "1200000000000000003" // Owned by another account, pins cannot be saved to it
+
+	RedditUserID                 = "1fake0usr"
+	RedditUsername               = "fake_reddit_user"
+	RedditSubreddit              = "fakegolang" // Allows every kind of post, with flairs
+	RedditTitleMinLength         = 10
+	RedditBlockedDomain          = "spam.example.com" // Links to it are not allowed in RedditSubreddit
+	RedditFlairID                = "f1a1r000-0000-0000-0000-000000000001"
+	RedditEditableFlairID        = "f1a1r000-0000-0000-0000-000000000002" // Its text can be replaced
+	RedditModFlairID             = "f1a1r000-0000-0000-0000-000000000003" // Only moderators may use it
+	RedditTextOnlySubreddit      = "fakewriting"                          // Only allows text posts, which need a body
+	RedditFlairRequiredSubreddit = "fakeflaired"
+	RedditRequiredFlairID        = "f1a1r000-0000-0000-0000-000000000004"
+	RedditRestrictedSubreddit    = "fakeannouncements" // Only approved users may post
+
+	TelegramBotID                = 7000000001
+	TelegramBotToken             = "7000000001:fake-telegram-bot-token"
+	TelegramBotName              = "Fake Sosyal Bot"
+	TelegramBotUsername          = "fake_sosyal_bot"
+	TelegramChannelID            = -1001000000001 // The bot is an administrator that can post
+	TelegramChannelTitle         = "Fake Channel"
+	TelegramChannelUsername      = "fake_channel"
+	TelegramGroupID              = -1001000000002 // A private supergroup the bot is a member of
+	TelegramGroupTitle           = "Fake Group"
+	TelegramReadOnlyChannelID    = -1001000000003 // The bot is an administrator without the right to post
+	TelegramReadOnlyChannelTitle = "Fake Read-Only Channel"
+
+	DiscordWebhookID     = "1300000000000000001"
+	DiscordWebhookToken  = "fake-discord-webhook-token"
+	DiscordWebhookName   = "Fake Announcements"
+	DiscordWebhookAvatar = "fakeavatarhash"
+	DiscordGuildID       = "1300000000000000002"
+	DiscordChannelID     = "1300000000000000003"
+
+	SlackTeamID       = "T0FAKE0001"
+	SlackWebhookBotID = "B0FAKE0001"
+	SlackWebhookToken = "fakeSlackWebhookToken0001"
+
+	WebhookSecret = "fake-webhook-signing-secret"
+)
+
+// Lifetimes of the tokens the fake issues, in seconds, matching the real platforms
+const (
+	tiktokTokenLifetime      = 24 * 60 * 60
+	xTokenLifetime           = 2 * 60 * 60
+	instagramShortLifetime   = 60 * 60
+	instagramLongLifetime    = 60 * 24 * 60 * 60
+	threadsLongLifetime      = 60 * 24 * 60 * 60
+	facebookShortLifetime    = 2 * 60 * 60
+	facebookLongLifetime     = 60 * 24 * 60 * 60
+	linkedinTokenLifetime    = 60 * 24 * 60 * 60
+	linkedinRefreshLifetime  = 365 * 24 * 60 * 60
+	youtubeTokenLifetime     = 60*60 - 1
+	pinterestTokenLifetime   = 30 * 24 * 60 * 60
+	pinterestRefreshLifetime = 365 * 24 * 60 * 60
+	redditTokenLifetime      = 60 * 60
+)
+
+// Failure is an error response returned instead of the normal one
+type Failure struct {
+	Status int
+	Body   string // Raw response body; a platform-style error is generated when empty
+	Header http.Header
+
+	pass bool // Placeholder that lets a call through, queued by FailAfter
+}
+
+// Processing controls how asynchronous platform processing of new media finishes
+type Processing struct {
+	Polls      int    // Status checks that report the media as still processing
+	FailReason string // If set, processing fails with this reason instead of succeeding
+}
+
+// client holds the app credentials a platform expects
+type client struct {
+	id          string
+	secret      string
+	redirectURI string
+}
+
+// authCode is an authorization code that has not been exchanged yet
+type authCode struct {
+	platform      models.Platform
+	codeChallenge string // X only (PKCE)
+	clientID      string // Mastodon only, where every registered app has its own client
+}
+
+// Server is a fake of the platforms served from a single httptest.Server, plus a Mastodon
+// instance served from a second one at MastodonURL
+// Point every base URL of the config at it with Configure
+type Server struct {
+	*httptest.Server
+	mastodon *httptest.Server
+
+	mu         sync.Mutex
+	nextID     int64
+	calls      map[Op]int
+	failures   map[Op][]Failure
+	latency    map[Op]time.Duration
+	processing map[models.Platform]Processing
+	clients    map[models.Platform]client
+
+	codes         map[string]authCode
+	accessTokens  map[string]models.Platform
+	refreshTokens map[string]models.Platform
+
+	media map[string]mediaFile
+
+	tiktokPublishes    []*TikTokPublish
+	xUploads           map[string]*XUpload
+	tweets             []*Tweet
+	igContainers       map[string]*InstagramContainer
+	igPosts            []*InstagramPost
+	threadsContainers  map[string]*ThreadsContainer
+	threadsPosts       []*ThreadsPost
+	facebookPageTokens map[string]string // Page token to page ID
+	facebookPhotos     map[string]*FacebookPhoto
+	facebookPosts      []*FacebookPost
+	facebookVideos     []*FacebookVideo
+	linkedinMedia      map[string]*LinkedInMedia
+	linkedinPosts      []*LinkedInPost
+	youtubeUploads     map[string]*youtubeUpload
+	youtubeVideos      []*YouTubeVideo
+
+	mastodonApps     map[string]*MastodonApp
+	mastodonMedia    map[string]*MastodonMedia
+	mastodonStatuses []*MastodonStatus
+
+	blueskyBlobs map[string]*BlueskyBlob
+	blueskyPosts []*BlueskyPost
+
+	pinterestBoards []*PinterestBoard
+	pinterestMedia  map[string]*PinterestMedia
+	pinterestPins   []*PinterestPin
+
+	redditSubreddits map[string]*redditSubreddit
+	redditAssets     map[string]*RedditAsset
+	redditPosts      []*RedditPost
+
+	telegramRevoked  bool
+	telegramMessages []*TelegramMessage
+
+	discordWebhookDeleted bool
+	discordMessages       []*DiscordMessage
+
+	slackWebhookRemoved bool
+	slackMessages       []*SlackMessage
+
+	webhookDeliveries []*WebhookDelivery
+}
+
+// NewServer starts a fake platform server; close it with Close
+func NewServer() *Server {
+	s := &Server{
+		nextID:             1000,
+		calls:              make(map[Op]int),
+		failures:           make(map[Op][]Failure),
+		latency:            make(map[Op]time.Duration),
+		processing:         make(map[models.Platform]Processing),
+		clients:            make(map[models.Platform]client),
+		codes:              make(map[string]authCode),
+		accessTokens:       make(map[string]models.Platform),
+		refreshTokens:      make(map[string]models.Platform),
+		media:              make(map[string]mediaFile),
+		xUploads:           make(map[string]*XUpload),
+		igContainers:       make(map[string]*InstagramContainer),
+		threadsContainers:  make(map[string]*ThreadsContainer),
+		facebookPageTokens: make(map[string]string),
+		facebookPhotos:     make(map[string]*FacebookPhoto),
+		linkedinMedia:      make(map[string]*LinkedInMedia),
+		youtubeUploads:     make(map[string]*youtubeUpload),
+		mastodonApps:       make(map[string]*MastodonApp),
+		mastodonMedia:      make(map[string]*MastodonMedia),
+		blueskyBlobs:       make(map[string]*BlueskyBlob),
+		pinterestBoards:    pinterestBoards(),
+		pinterestMedia:     make(map[string]*PinterestMedia),
+		redditSubreddits:   redditSubreddits(),
+		redditAssets:       make(map[string]*RedditAsset),
+	}
+
+	mux := http.NewServeMux()
+	s.registerTikTok(mux)
+	s.registerX(mux)
+	s.registerInstagram(mux)
+	s.registerThreads(mux)
+	s.registerFacebook(mux)
+	s.registerLinkedIn(mux)
+	s.registerYouTube(mux)
+	s.registerBluesky(mux)
+	s.registerPinterest(mux)
+	s.registerReddit(mux)
+	s.registerTelegram(mux)
+	s.registerDiscord(mux)
+	s.registerSlack(mux)
+	s.registerWebhook(mux)
+	mux.HandleFunc("GET /media/{name}", s.handle(OpMedia, s.serveMedia))
+
+	s.Server = httptest.NewServer(mux)
+
+	mastodonMux := http.NewServeMux()
+	s.registerMastodon(mastodonMux)
+	s.mastodon = httptest.NewServer(mastodonMux)
+	return s
+}
+
+// Close shuts down the fake platforms and the fake Mastodon instance
+func (s *Server) Close() {
+	s.mastodon.Close()
+	s.Server.Close()
+}
+
+// Configure points every platform in cfg at the fake server
+// Client credentials and redirect URIs that are not set are filled in, and the fake
+// only accepts the credentials that end up in cfg
+func (s *Server) Configure(cfg *config.Config) {
+	setDefault := func(value *string, def string) {
+		if *value == "" {
+			*value = def
+		}
+	}
+
+	setDefault(&cfg.TikTok.ClientKey, "fake-tiktok-client-key")
+	setDefault(&cfg.TikTok.ClientSecret, "fake-tiktok-client-secret")
+	setDefault(&cfg.TikTok.RedirectURI, "http://localhost:8080/api/v1/auth/tiktok/callback")
+	if len(cfg.TikTok.Scopes) == 0 {
+		cfg.TikTok.Scopes = []string{"user.info.basic", "video.publish"}
+	}
+	cfg.TikTok.AuthBaseURL = s.URL
+	cfg.TikTok.APIBaseURL = s.URL
+
+	setDefault(&cfg.X.ClientID, "fake-x-client-id")
+	setDefault(&cfg.X.ClientSecret, "fake-x-client-secret")
+	setDefault(&cfg.X.RedirectURI, "http://localhost:8080/api/v1/auth/x/callback")
+	cfg.X.AuthBaseURL = s.URL
+	cfg.X.APIBaseURL = s.URL
+	cfg.X.UploadBaseURL = s.URL
+
+	setDefault(&cfg.Instagram.AppID, "fake-instagram-app-id")
+	setDefault(&cfg.Instagram.AppSecret, "fake-instagram-app-secret")
+	setDefault(&cfg.Instagram.RedirectURI, "http://localhost:8080/api/v1/auth/instagram/callback")
+	cfg.Instagram.AuthBaseURL = s.URL
+	cfg.Instagram.APIBaseURL = s.URL
+	cfg.Instagram.GraphBaseURL = s.URL
+
+	setDefault(&cfg.Threads.AppID, "fake-threads-app-id")
+	setDefault(&cfg.Threads.AppSecret, "fake-threads-app-secret")
+	setDefault(&cfg.Threads.RedirectURI, "http://localhost:8080/api/v1/auth/threads/callback")
+	cfg.Threads.AuthBaseURL = s.URL + threadsPrefix
+	cfg.Threads.GraphBaseURL = s.URL + threadsPrefix
+
+	setDefault(&cfg.Facebook.AppID, "fake-facebook-app-id")
+	setDefault(&cfg.Facebook.AppSecret, "fake-facebook-app-secret")
+	setDefault(&cfg.Facebook.RedirectURI, "http://localhost:8080/api/v1/auth/facebook/callback")
+	if len(cfg.Facebook.Scopes) == 0 {
+		cfg.Facebook.Scopes = []string{"pages_show_list", "pages_read_engagement", "pages_manage_posts"}
+	}
+	cfg.Facebook.GraphVersion = facebookGraphVersion
+	cfg.Facebook.AuthBaseURL = s.URL + facebookPrefix
+	cfg.Facebook.GraphBaseURL = s.URL + facebookPrefix
+
+	setDefault(&cfg.LinkedIn.ClientID, "fake-linkedin-client-id")
+	setDefault(&cfg.LinkedIn.ClientSecret, "fake-linkedin-client-secret")
+	setDefault(&cfg.LinkedIn.RedirectURI, "http://localhost:8080/api/v1/auth/linkedin/callback")
+	setDefault(&cfg.LinkedIn.APIVersion, "202509")
+	if len(cfg.LinkedIn.Scopes) == 0 {
+		cfg.LinkedIn.Scopes = []string{"openid", "profile", "email", "w_member_social", "r_organization_admin", "w_organization_social"}
+	}
+	cfg.LinkedIn.AuthBaseURL = s.URL
+	cfg.LinkedIn.APIBaseURL = s.URL
+
+	setDefault(&cfg.YouTube.ClientID, "fake-youtube-client-id.apps.googleusercontent.com")
+	setDefault(&cfg.YouTube.ClientSecret, "fake-youtube-client-secret")
+	setDefault(&cfg.YouTube.RedirectURI, "http://localhost:8080/api/v1/auth/youtube/callback")
+	if len(cfg.YouTube.Scopes) == 0 {
+		cfg.YouTube.Scopes = []string{"https://www.googleapis.com/auth/youtube.upload", "https://www.googleapis.com/auth/youtube.readonly"}
+	}
+	cfg.YouTube.AuthBaseURL = s.URL
+	cfg.YouTube.TokenBaseURL = s.URL
+	cfg.YouTube.APIBaseURL = s.URL
+
+	// Mastodon has no base URL to configure: accounts log in with the instance at MastodonURL,
+	// which runs on localhost and so is a private instance
+	setDefault(&cfg.Mastodon.RedirectURI, "http://localhost:8080/api/v1/auth/mastodon/callback")
+	setDefault(&cfg.Mastodon.ClientName, "Sosyal")
+	if len(cfg.Mastodon.Scopes) == 0 {
+		cfg.Mastodon.Scopes = []string{"read:accounts", "write:statuses", "write:media"}
+	}
+	cfg.Mastodon.AllowPrivateInstances = true
+
+	// Bluesky accounts log in with an app password on the fake, which is served over plain http
+	// from localhost like a local PDS
+	cfg.Bluesky.Enabled = true
+	cfg.Bluesky.PDSURL = s.URL
+	cfg.Bluesky.AllowPrivatePDS = true
+
+	setDefault(&cfg.Pinterest.AppID, "fake-pinterest-app-id")
+	setDefault(&cfg.Pinterest.AppSecret, "fake-pinterest-app-secret")
+	setDefault(&cfg.Pinterest.RedirectURI, "http://localhost:8080/api/v1/auth/pinterest/callback")
+	if len(cfg.Pinterest.Scopes) == 0 {
+		cfg.Pinterest.Scopes = []string{"user_accounts:read", "boards:read", "boards:write", "pins:read", "pins:write"}
+	}
+	cfg.Pinterest.AuthBaseURL = s.URL + pinterestPrefix
+	cfg.Pinterest.APIBaseURL = s.URL + pinterestPrefix
+
+	setDefault(&cfg.Reddit.ClientID, "fake-reddit-client-id")
+	setDefault(&cfg.Reddit.ClientSecret, "fake-reddit-client-secret")
+	setDefault(&cfg.Reddit.RedirectURI, "http://localhost:8080/api/v1/auth/reddit/callback")
+	setDefault(&cfg.Reddit.UserAgent, "test:sosyal:1.0 (by /u/fake_reddit_user)")
+	if len(cfg.Reddit.Scopes) == 0 {
+		cfg.Reddit.Scopes = []string{"identity", "submit", "read", "flair", "mysubreddits"}
+	}
+	cfg.Reddit.AuthBaseURL = s.URL + redditPrefix
+	cfg.Reddit.APIBaseURL = s.URL + redditPrefix
+
+	// Telegram chats are connected with the fake bot's token, Discord and Slack channels with the
+	// fake webhooks, so none of them has app credentials
+	cfg.Telegram.Enabled = true
+	cfg.Telegram.APIBaseURL = s.URL + telegramPrefix
+	cfg.Discord.Enabled = true
+	cfg.Discord.WebhookBaseURL = s.URL + discordPrefix
+	cfg.Slack.Enabled = true
+	cfg.Slack.WebhookBaseURL = s.URL + slackPrefix
+
+	// The fake endpoint is on loopback and plain http, which only development setups allow
+	cfg.Webhook.Enabled = true
+	cfg.Webhook.AllowPrivateURLs = true
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[models.PlatformTikTok] = client{cfg.TikTok.ClientKey, cfg.TikTok.ClientSecret, cfg.TikTok.RedirectURI}
+	s.clients[models.PlatformX] = client{cfg.X.ClientID, cfg.X.ClientSecret, cfg.X.RedirectURI}
+	s.clients[models.PlatformInstagram] = client{cfg.Instagram.AppID, cfg.Instagram.AppSecret, cfg.Instagram.RedirectURI}
+	s.clients[models.PlatformThreads] = client{cfg.Threads.AppID, cfg.Threads.AppSecret, cfg.Threads.RedirectURI}
+	s.clients[models.PlatformFacebook] = client{cfg.Facebook.AppID, cfg.Facebook.AppSecret, cfg.Facebook.RedirectURI}
+	s.clients[models.PlatformLinkedIn] = client{cfg.LinkedIn.ClientID, cfg.LinkedIn.ClientSecret, cfg.LinkedIn.RedirectURI}
+	s.clients[models.PlatformYouTube] = client{cfg.YouTube.ClientID, cfg.YouTube.ClientSecret, cfg.YouTube.RedirectURI}
+	s.clients[models.PlatformPinterest] = client{cfg.Pinterest.AppID, cfg.Pinterest.AppSecret, cfg.Pinterest.RedirectURI}
+	s.clients[models.PlatformReddit] = client{cfg.Reddit.ClientID, cfg.Reddit.ClientSecret, cfg.Reddit.RedirectURI}
+}
+
+// FailNext makes the next times calls of op return failure
+// Failures queue up behind any that are already pending for op
+func (s *Server) FailNext(op Op, times int, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures[op] = append(s.failures[op], failure)
+	}
+}
+
+// FailAfter lets the next calls calls of op through and makes the one after them return failure
+func (s *Server) FailAfter(op Op, calls int, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < calls; i++ {
+		s.failures[op] = append(s.failures[op], Failure{pass: true})
+	}
+	s.failures[op] = append(s.failures[op], failure)
+}
+
+// RateLimitNext makes the next times calls of op fail the way the platform reports rate limiting
+func (s *Server) RateLimitNext(op Op, times int) {
+	s.FailNext(op, times, rateLimitFailure(op))
+}
+
+// SetLatency delays every response of op by d, or until the client gives up
+func (s *Server) SetLatency(op Op, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[op] = d
+}
+
+// SetProcessing controls how media created on platform from now on is processed
+func (s *Server) SetProcessing(platform models.Platform, processing Processing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processing[platform] = processing
+}
+
+// Calls returns how many requests op has received, including failed ones
+func (s *Server) Calls(op Op) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[op]
+}
+
+// IssueToken returns a valid access and refresh token for platform without going through OAuth
+func (s *Server) IssueToken(platform models.Platform) (accessToken, refreshToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueTokenLocked(platform)
+}
+
+// RevokeToken makes an access token invalid, as if it had expired on the platform
+func (s *Server) RevokeToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.accessTokens, accessToken)
+}
+
+// issueTokenLocked creates a token pair; s.mu must be held
+func (s *Server) issueTokenLocked(platform models.Platform) (accessToken, refreshToken string) {
+	id := s.newIDLocked()
+	accessToken = fmt.Sprintf("%s-access-%d", platform, id)
+	refreshToken = fmt.Sprintf("%s-refresh-%d", platform, id)
+	s.accessTokens[accessToken] = platform
+	s.refreshTokens[refreshToken] = platform
+	return accessToken, refreshToken
+}
+
+// newCode creates an authorization code for platform
+func (s *Server) newCode(platform models.Platform, codeChallenge string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := fmt.Sprintf("%s-code-%d", platform, s.newIDLocked())
+	s.codes[code] = authCode{platform: platform, codeChallenge: codeChallenge}
+	return code
+}
+
+// takeCode consumes an authorization code; codes can only be exchanged once
+func (s *Server) takeCode(platform models.Platform, code string) (authCode, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auth, ok := s.codes[code]
+	if !ok || auth.platform != platform {
+		return authCode{}, false
+	}
+	delete(s.codes, code)
+	return auth, true
+}
+
+// takeRefreshToken consumes a refresh token and issues a new token pair
+func (s *Server) takeRefreshToken(platform models.Platform, refreshToken string) (string, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refreshTokens[refreshToken] != platform {
+		return "", "", false
+	}
+	delete(s.refreshTokens, refreshToken)
+	accessToken, newRefreshToken := s.issueTokenLocked(platform)
+	return accessToken, newRefreshToken, true
+}
+
+// validToken reports whether accessToken was issued for platform and is still valid
+func (s *Server) validToken(platform models.Platform, accessToken string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return accessToken != "" && s.accessTokens[accessToken] == platform
+}
+
+// client returns the app credentials configured for platform
+func (s *Server) client(platform models.Platform) client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clients[platform]
+}
+
+// newID returns a new unique numeric ID
+func (s *Server) newID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newIDLocked()
+}
+
+func (s *Server) newIDLocked() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// currentProcessing returns the processing behaviour new media on platform gets
+func (s *Server) currentProcessing(platform models.Platform) Processing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processing[platform]
+}
+
+// handle wraps the handler of op with call counting, latency and failure injection
+func (s *Server) handle(op Op, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.calls[op]++
+		delay := s.latency[op]
+		var failure *Failure
+		if queued := s.failures[op]; len(queued) > 0 {
+			failure = &queued[0]
+			s.failures[op] = queued[1:]
+		}
+		s.mu.Unlock()
+
+		if delay > 0 {
+			// Read the body first: the server only notices that a client gave up once it has
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if failure != nil && !failure.pass {
+			writeFailure(w, op, *failure)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// opPlatform returns the platform an operation belongs to
+func opPlatform(op Op) models.Platform {
+	name, _, _ := strings.Cut(string(op), ".")
+	return models.Platform(name)
+}
+
+// writeFailure writes an injected failure, generating a platform-style body if it has none
+func writeFailure(w http.ResponseWriter, op Op, failure Failure) {
+	for key, values := range failure.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	status := failure.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	if failure.Body != "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(failure.Body))
+		return
+	}
+
+	message := http.StatusText(status)
+	switch opPlatform(op) {
+	case models.PlatformTikTok:
+		writeTikTokError(w, status, "internal_error", message)
+	case models.PlatformX:
+		writeXError(w, status, message, message)
+	case models.PlatformInstagram, models.PlatformThreads, models.PlatformFacebook:
+		writeInstagramError(w, status, 1, message)
+	case models.PlatformLinkedIn:
+		writeLinkedInError(w, status, "SERVER_ERROR", message)
+	case models.PlatformYouTube:
+		writeYouTubeError(w, status, "backendError", message)
+	case models.PlatformMastodon:
+		writeMastodonError(w, status, message)
+	case models.PlatformBluesky:
+		writeBlueskyError(w, status, "InternalServerError", message)
+	case models.PlatformPinterest:
+		writePinterestError(w, status, 1, message)
+	case models.PlatformReddit:
+		writeRedditError(w, status, message)
+	case models.PlatformTelegram:
+		writeTelegramError(w, status, message)
+	case models.PlatformDiscord:
+		writeDiscordError(w, status, 0, message)
+	case models.PlatformSlack:
+		writeSlackError(w, status, "internal_error")
+	case models.PlatformWebhook:
+		writeWebhookError(w, status, "", message)
+	default:
+		http.Error(w, message, status)
+	}
+}
+
+// rateLimitFailure returns the response each platform sends when a rate limit is hit
+func rateLimitFailure(op Op) Failure {
+	switch opPlatform(op) {
+	case models.PlatformTikTok:
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"error":{"code":"rate_limit_exceeded","message":"API rate limit was exceeded. Please try again later.","log_id":"fake"}}`,
+		}
+	case models.PlatformX:
+		header := http.Header{}
+		header.Set("x-rate-limit-limit", "300")
+		header.Set("x-rate-limit-remaining", "0")
+		header.Set("x-rate-limit-reset", fmt.Sprintf("%d", time.Now().Add(15*time.Minute).Unix()))
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"title":"Too Many Requests","detail":"Too Many Requests","type":"about:blank","status":429}`,
+			Header: header,
+		}
+	case models.PlatformInstagram, models.PlatformThreads, models.PlatformFacebook:
+		header := http.Header{}
+		header.Set("X-App-Usage", `{"call_count":100,"total_cputime":25,"total_time":25}`)
+		return Failure{
+			Status: http.StatusForbidden,
+			Body:   `{"error":{"message":"(#4) Application request limit reached","type":"OAuthException","code":4,"fbtrace_id":"fake"}}`,
+			Header: header,
+		}
+	case models.PlatformLinkedIn:
+		header := http.Header{}
+		header.Set("Retry-After", "3600")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"status":429,"serviceErrorCode":101,"code":"TOO_MANY_REQUESTS","message":"Resource level throttle APPLICATION DAY limit for calls to this resource is reached."}`,
+			Header: header,
+		}
+	case models.PlatformYouTube:
+		return Failure{
+			Status: http.StatusForbidden,
+			Body:   `{"error":{"code":403,"message":"The request cannot be completed because you have exceeded your quota.","errors":[{"message":"The request cannot be completed because you have exceeded your quota.","domain":"youtube.quota","reason":"quotaExceeded"}]}}`,
+		}
+	case models.PlatformMastodon:
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "300")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", time.Now().Add(5*time.Minute).UTC().Format(time.RFC3339))
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"error":"Too many requests"}`,
+			Header: header,
+		}
+	case models.PlatformBluesky:
+		header := http.Header{}
+		header.Set("RateLimit-Limit", fmt.Sprintf("%d", blueskyWriteLimit))
+		header.Set("RateLimit-Remaining", "0")
+		header.Set("RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=3600", blueskyWriteLimit))
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"error":"RateLimitExceeded","message":"Rate Limit Exceeded"}`,
+			Header: header,
+		}
+	case models.PlatformPinterest:
+		header := http.Header{}
+		header.Set("Retry-After", "60")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"code":8,"message":"You have exceeded your rate limit. Try again later."}`,
+			Header: header,
+		}
+	case models.PlatformReddit:
+		header := http.Header{}
+		header.Set("X-Ratelimit-Used", "600")
+		header.Set("X-Ratelimit-Remaining", "0.0")
+		header.Set("X-Ratelimit-Reset", "420")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"message":"Too Many Requests","error":429}`,
+			Header: header,
+		}
+	case models.PlatformTelegram:
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 35","parameters":{"retry_after":35}}`,
+		}
+	case models.PlatformDiscord:
+		header := http.Header{}
+		header.Set("Retry-After", "2")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`,
+			Header: header,
+		}
+	case models.PlatformSlack:
+		header := http.Header{}
+		header.Set("Retry-After", "30")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   "rate_limited",
+			Header: header,
+		}
+	case models.PlatformWebhook:
+		header := http.Header{}
+		header.Set("Retry-After", "120")
+		return Failure{
+			Status: http.StatusTooManyRequests,
+			Body:   `{"error":"Too many deliveries","error_code":"rate_limited"}`,
+			Header: header,
+		}
+	}
+	return Failure{Status: http.StatusTooManyRequests}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
@@ -0,0 +1,134 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"net/http"
+	"unicode/utf8"
+)
+
+// slackPrefix is where the fake's webhooks are served, so their routes don't clash with other platforms
+const slackPrefix = "/slack/services"
+
+// slackSectionTextLimit is how many characters the text of a section block may have
+const slackSectionTextLimit = 3000
+
+// SlackMessage is a message sent with the fake webhook
+type SlackMessage struct {
+	Text        string // Notification text
+	Sections    []string
+	Images      []SlackImage
+	UnfurlLinks *bool
+	UnfurlMedia *bool
+}
+
+// SlackImage is an image block of a message
+type SlackImage struct {
+	URL     string
+	AltText string
+}
+
+// SlackWebhookURL returns the URL of the fake channel's webhook
+func (s *Server) SlackWebhookURL() string {
+	return s.URL + slackPrefix + "/" + SlackTeamID + "/" + SlackWebhookBotID + "/" + SlackWebhookToken
+}
+
+// SlackMessages returns the messages sent with the webhook, in order
+func (s *Server) SlackMessages() []SlackMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]SlackMessage, 0, len(s.slackMessages))
+	for _, message := range s.slackMessages {
+		result = append(result, *message)
+	}
+	return result
+}
+
+// RemoveSlackWebhook removes the fake webhook, as if the app that owns it was uninstalled
+func (s *Server) RemoveSlackWebhook() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slackWebhookRemoved = true
+}
+
+// registerSlack registers the webhook endpoint
+func (s *Server) registerSlack(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+slackPrefix+"/{team}/{bot}/{token}", s.handle(OpSlackWebhook, s.slackWebhook))
+}
+
+// writeSlackError writes a webhook error, which Slack sends as plain text
+func writeSlackError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	w.Write([]byte(code))
+}
+
+// slackWebhook serves POST /services/{team}/{bot}/{token}
+func (s *Server) slackWebhook(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	removed := s.slackWebhookRemoved
+	s.mu.Unlock()
+
+	if r.PathValue("team") != SlackTeamID || r.PathValue("bot") != SlackWebhookBotID {
+		writeSlackError(w, http.StatusNotFound, "no_service")
+		return
+	}
+	if r.PathValue("token") != SlackWebhookToken || removed {
+		writeSlackError(w, http.StatusForbidden, "invalid_token")
+		return
+	}
+
+	var payload struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type string `json:"type"`
+			Text *struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"text"`
+			ImageURL string `json:"image_url"`
+			AltText  string `json:"alt_text"`
+		} `json:"blocks"`
+		UnfurlLinks *bool `json:"unfurl_links"`
+		UnfurlMedia *bool `json:"unfurl_media"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeSlackError(w, http.StatusBadRequest, "invalid_payload")
+		return
+	}
+	if payload.Text == "" && len(payload.Blocks) == 0 {
+		writeSlackError(w, http.StatusBadRequest, "no_text")
+		return
+	}
+
+	message := &SlackMessage{
+		Text:        payload.Text,
+		UnfurlLinks: payload.UnfurlLinks,
+		UnfurlMedia: payload.UnfurlMedia,
+	}
+	for _, block := range payload.Blocks {
+		switch block.Type {
+		case "section":
+			if block.Text == nil || block.Text.Text == "" || utf8.RuneCountInString(block.Text.Text) > slackSectionTextLimit {
+				writeSlackError(w, http.StatusBadRequest, "invalid_blocks")
+				return
+			}
+			message.Sections = append(message.Sections, block.Text.Text)
+		case "image":
+			if block.ImageURL == "" || block.AltText == "" {
+				writeSlackError(w, http.StatusBadRequest, "invalid_blocks")
+				return
+			}
+			message.Images = append(message.Images, SlackImage{URL: block.ImageURL, AltText: block.AltText})
+		default:
+			writeSlackError(w, http.StatusBadRequest, "invalid_blocks")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.slackMessages = append(s.slackMessages, message)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok"))
+}
@@ -0,0 +1,392 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// telegramPrefix is where the fake Bot API is served, so its routes don't clash with other platforms
+const telegramPrefix = "/telegram"
+
+// Limits of the fake Bot API, matching Telegram
+const (
+	telegramTextLimit       = 4096 // Characters of a text message
+	telegramCaptionLimit    = 1024 // Characters of a media caption
+	telegramMediaGroupLimit = 10   // Items of a media group
+)
+
+// TelegramMessage is a message a bot sent
+type TelegramMessage struct {
+	ChatID       int64
+	MessageID    int64
+	Method       string // sendMessage, sendPhoto, sendVideo or sendMediaGroup
+	Text         string // Text of a text message, caption of media
+	MediaType    string // photo or video, empty for text messages
+	MediaURL     string
+	MediaGroupID string // Shared by the messages of an album
+
+	DisableNotification bool
+	ProtectContent      bool
+	LinkPreviewDisabled bool
+	Deleted             bool
+}
+
+// telegramChat is a chat the fake bot knows
+type telegramChat struct {
+	id       int64
+	kind     string // channel, supergroup or private
+	title    string
+	username string
+	status   string // Membership of the fake bot
+	canPost  bool
+}
+
+// TelegramMessages returns the messages bots sent, in order, including deleted ones
+func (s *Server) TelegramMessages() []TelegramMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]TelegramMessage, 0, len(s.telegramMessages))
+	for _, message := range s.telegramMessages {
+		result = append(result, *message)
+	}
+	return result
+}
+
+// RevokeTelegramBot makes the fake bot's token invalid, as if it was revoked with @BotFather
+func (s *Server) RevokeTelegramBot() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.telegramRevoked = true
+}
+
+// telegramChats returns the chats the fake bot knows
+func telegramChats() []telegramChat {
+	return []telegramChat{
+		{id: TelegramChannelID, kind: "channel", title: TelegramChannelTitle, username: TelegramChannelUsername, status: "administrator", canPost: true},
+		{id: TelegramGroupID, kind: "supergroup", title: TelegramGroupTitle, status: "member", canPost: true},
+		{id: TelegramReadOnlyChannelID, kind: "channel", title: TelegramReadOnlyChannelTitle, status: "administrator", canPost: false},
+		{id: TelegramBotID + 1, kind: "private", title: "", username: "fake_telegram_user", status: "member", canPost: true},
+	}
+}
+
+// registerTelegram registers the Bot API; every method is called as POST /bot<token>/<method>
+func (s *Server) registerTelegram(mux *http.ServeMux) {
+	methods := map[string]struct {
+		op Op
+		fn http.HandlerFunc
+	}{
+		"getMe":          {OpTelegramGetMe, s.telegramGetMe},
+		"getChat":        {OpTelegramGetChat, s.telegramGetChat},
+		"getChatMember":  {OpTelegramGetChat, s.telegramGetChatMember},
+		"sendMessage":    {OpTelegramSend, s.telegramSend},
+		"sendPhoto":      {OpTelegramSend, s.telegramSend},
+		"sendVideo":      {OpTelegramSend, s.telegramSend},
+		"sendMediaGroup": {OpTelegramSend, s.telegramSend},
+		"deleteMessages": {OpTelegramDelete, s.telegramDeleteMessages},
+	}
+	handlers := make(map[string]http.HandlerFunc, len(methods))
+	for name, method := range methods {
+		handlers[name] = s.handle(method.op, method.fn)
+	}
+
+	mux.HandleFunc("POST "+telegramPrefix+"/{bot}/{method}", func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.PathValue("method")]
+		if !ok {
+			writeTelegramError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// writeTelegramError writes a Bot API error
+func writeTelegramError(w http.ResponseWriter, status int, description string) {
+	writeJSON(w, status, map[string]interface{}{
+		"ok":          false,
+		"error_code":  status,
+		"description": description,
+	})
+}
+
+// writeTelegramResult writes the result of a successful method call
+func writeTelegramResult(w http.ResponseWriter, result interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": result})
+}
+
+// telegramAuthorized checks the bot token in the path, writing an error if it is wrong
+// Tokens that don't belong to a bot are answered with 404, revoked ones with 401, like Telegram does
+func (s *Server) telegramAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.PathValue("bot"), "bot")
+	if !ok || token != TelegramBotToken {
+		writeTelegramError(w, http.StatusNotFound, "Not Found")
+		return false
+	}
+	s.mu.Lock()
+	revoked := s.telegramRevoked
+	s.mu.Unlock()
+	if revoked {
+		writeTelegramError(w, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+	return true
+}
+
+// telegramRequest decodes the JSON parameters of a method call
+func telegramRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: invalid JSON")
+		return false
+	}
+	return true
+}
+
+// findTelegramChat finds a chat by numeric ID or @username
+func findTelegramChat(chatID json.RawMessage) (telegramChat, bool) {
+	var id int64
+	var username string
+	if json.Unmarshal(chatID, &id) != nil {
+		if json.Unmarshal(chatID, &username) != nil {
+			return telegramChat{}, false
+		}
+		if _, err := fmt.Sscan(username, &id); err != nil {
+			id = 0
+		}
+	}
+	for _, chat := range telegramChats() {
+		if (id != 0 && chat.id == id) || (username != "" && chat.username != "" && strings.EqualFold(username, "@"+chat.username)) {
+			return chat, true
+		}
+	}
+	return telegramChat{}, false
+}
+
+// telegramChatJSON returns the Chat object of a chat
+func telegramChatJSON(chat telegramChat) map[string]interface{} {
+	result := map[string]interface{}{"id": chat.id, "type": chat.kind}
+	if chat.title != "" {
+		result["title"] = chat.title
+	}
+	if chat.username != "" {
+		result["username"] = chat.username
+	}
+	return result
+}
+
+// telegramGetMe serves getMe
+func (s *Server) telegramGetMe(w http.ResponseWriter, r *http.Request) {
+	if !s.telegramAuthorized(w, r) {
+		return
+	}
+	writeTelegramResult(w, map[string]interface{}{
+		"id":         TelegramBotID,
+		"is_bot":     true,
+		"first_name": TelegramBotName,
+		"username":   TelegramBotUsername,
+	})
+}
+
+// telegramGetChat serves getChat
+func (s *Server) telegramGetChat(w http.ResponseWriter, r *http.Request) {
+	if !s.telegramAuthorized(w, r) {
+		return
+	}
+	var req struct {
+		ChatID json.RawMessage `json:"chat_id"`
+	}
+	if !telegramRequest(w, r, &req) {
+		return
+	}
+	chat, ok := findTelegramChat(req.ChatID)
+	if !ok {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: chat not found")
+		return
+	}
+	writeTelegramResult(w, telegramChatJSON(chat))
+}
+
+// telegramGetChatMember serves getChatMember; only the fake bot's membership is known
+func (s *Server) telegramGetChatMember(w http.ResponseWriter, r *http.Request) {
+	if !s.telegramAuthorized(w, r) {
+		return
+	}
+	var req struct {
+		ChatID json.RawMessage `json:"chat_id"`
+		UserID int64           `json:"user_id"`
+	}
+	if !telegramRequest(w, r, &req) {
+		return
+	}
+	chat, ok := findTelegramChat(req.ChatID)
+	if !ok {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: chat not found")
+		return
+	}
+	if req.UserID != TelegramBotID {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: user not found")
+		return
+	}
+
+	member := map[string]interface{}{
+		"status": chat.status,
+		"user":   map[string]interface{}{"id": TelegramBotID, "is_bot": true, "first_name": TelegramBotName},
+	}
+	if chat.status == "administrator" && chat.kind == "channel" {
+		member["can_post_messages"] = chat.canPost
+	}
+	writeTelegramResult(w, member)
+}
+
+// telegramSend serves sendMessage, sendPhoto, sendVideo and sendMediaGroup
+func (s *Server) telegramSend(w http.ResponseWriter, r *http.Request) {
+	if !s.telegramAuthorized(w, r) {
+		return
+	}
+	var req struct {
+		ChatID              json.RawMessage `json:"chat_id"`
+		Text                string          `json:"text"`
+		Caption             string          `json:"caption"`
+		Photo               string          `json:"photo"`
+		Video               string          `json:"video"`
+		DisableNotification bool            `json:"disable_notification"`
+		ProtectContent      bool            `json:"protect_content"`
+		LinkPreviewOptions  struct {
+			IsDisabled bool `json:"is_disabled"`
+		} `json:"link_preview_options"`
+		Media []struct {
+			Type    string `json:"type"`
+			Media   string `json:"media"`
+			Caption string `json:"caption"`
+		} `json:"media"`
+	}
+	if !telegramRequest(w, r, &req) {
+		return
+	}
+	chat, ok := findTelegramChat(req.ChatID)
+	if !ok || chat.kind == "private" {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: chat not found")
+		return
+	}
+	if !chat.canPost {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: need administrator rights in the channel chat")
+		return
+	}
+
+	base := TelegramMessage{
+		ChatID:              chat.id,
+		Method:              r.PathValue("method"),
+		DisableNotification: req.DisableNotification,
+		ProtectContent:      req.ProtectContent,
+		LinkPreviewDisabled: req.LinkPreviewOptions.IsDisabled,
+	}
+
+	var messages []TelegramMessage
+	switch base.Method {
+	case "sendMessage":
+		if req.Text == "" {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: message text is empty")
+			return
+		}
+		if utf8.RuneCountInString(req.Text) > telegramTextLimit {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: message is too long")
+			return
+		}
+		message := base
+		message.Text = req.Text
+		messages = append(messages, message)
+	case "sendPhoto", "sendVideo":
+		message := base
+		message.Text = req.Caption
+		message.MediaType, message.MediaURL = "photo", req.Photo
+		if base.Method == "sendVideo" {
+			message.MediaType, message.MediaURL = "video", req.Video
+		}
+		messages = append(messages, message)
+	case "sendMediaGroup":
+		if len(req.Media) < 2 || len(req.Media) > telegramMediaGroupLimit {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: wrong number of media in the group")
+			return
+		}
+		groupID := fmt.Sprint(s.newID())
+		for _, item := range req.Media {
+			if item.Type != "photo" && item.Type != "video" {
+				writeTelegramError(w, http.StatusBadRequest, "Bad Request: unsupported media type in the group")
+				return
+			}
+			message := base
+			message.Text = item.Caption
+			message.MediaType, message.MediaURL = item.Type, item.Media
+			message.MediaGroupID = groupID
+			messages = append(messages, message)
+		}
+	}
+
+	for _, message := range messages {
+		if utf8.RuneCountInString(message.Text) > telegramCaptionLimit && message.MediaType != "" {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: message caption is too long")
+			return
+		}
+		if message.MediaType != "" && !strings.HasPrefix(message.MediaURL, "http") {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: wrong HTTP URL specified")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	results := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		message.MessageID = s.newIDLocked()
+		stored := message
+		s.telegramMessages = append(s.telegramMessages, &stored)
+		results = append(results, map[string]interface{}{
+			"message_id": message.MessageID,
+			"chat":       telegramChatJSON(chat),
+			"date":       1700000000,
+		})
+	}
+	s.mu.Unlock()
+
+	if base.Method == "sendMediaGroup" {
+		writeTelegramResult(w, results)
+		return
+	}
+	writeTelegramResult(w, results[0])
+}
+
+// telegramDeleteMessages serves deleteMessages
+func (s *Server) telegramDeleteMessages(w http.ResponseWriter, r *http.Request) {
+	if !s.telegramAuthorized(w, r) {
+		return
+	}
+	var req struct {
+		ChatID     json.RawMessage `json:"chat_id"`
+		MessageIDs []int64         `json:"message_ids"`
+	}
+	if !telegramRequest(w, r, &req) {
+		return
+	}
+	chat, ok := findTelegramChat(req.ChatID)
+	if !ok {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: chat not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for _, message := range s.telegramMessages {
+		for _, id := range req.MessageIDs {
+			if message.ChatID == chat.id && message.MessageID == id && !message.Deleted {
+				message.Deleted = true
+				deleted++
+			}
+		}
+	}
+	if deleted == 0 {
+		writeTelegramError(w, http.StatusBadRequest, "Bad Request: message to delete not found")
+		return
+	}
+	writeTelegramResult(w, true)
+}
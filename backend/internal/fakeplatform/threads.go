@@ -0,0 +1,358 @@
+package fakeplatform
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// threadsPrefix is the path the fake Threads endpoints are served under, so they don't collide
+// with the Instagram Graph API, whose paths they mirror
+const threadsPrefix = "/threads"
+
+// threadsMaxTextLength is the most characters the text of a post can have
+const threadsMaxTextLength = 500
+
+// ThreadsContainer is a media container created with POST /{threads-user-id}/threads
+type ThreadsContainer struct {
+	ID             string
+	MediaType      string // TEXT, IMAGE, VIDEO or CAROUSEL
+	MediaURL       string
+	Text           string
+	IsCarouselItem bool
+	Children       []string
+	ReplyToID      string
+	ReplyControl   string
+
+	processing Processing
+	polls      int
+}
+
+// ThreadsPost is a container published with POST /{threads-user-id}/threads_publish
+type ThreadsPost struct {
+	ID           string
+	ContainerID  string
+	MediaType    string
+	Text         string
+	MediaURLs    []string
+	ReplyToID    string
+	ReplyControl string
+	Permalink    string
+}
+
+// ThreadsPosts returns the posts published on Threads in order
+func (s *Server) ThreadsPosts() []ThreadsPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]ThreadsPost, 0, len(s.threadsPosts))
+	for _, post := range s.threadsPosts {
+		result = append(result, *post)
+	}
+	return result
+}
+
+// registerThreads adds the Threads endpoints to mux
+// The OAuth endpoints are unversioned, the others are served under /v1.0
+func (s *Server) registerThreads(mux *http.ServeMux) {
+	api := threadsPrefix + "/v1.0"
+	mux.HandleFunc("GET "+threadsPrefix+"/oauth/authorize", s.handle(OpThreadsAuthorize, s.threadsAuthorize))
+	mux.HandleFunc("POST "+threadsPrefix+"/oauth/access_token", s.handle(OpThreadsToken, s.threadsToken))
+	mux.HandleFunc("GET "+threadsPrefix+"/access_token", s.handle(OpThreadsLongLivedToken, s.threadsLongLivedToken))
+	mux.HandleFunc("GET "+threadsPrefix+"/refresh_access_token", s.handle(OpThreadsRefreshToken, s.threadsRefreshToken))
+	mux.HandleFunc("GET "+api+"/me", s.handle(OpThreadsUserInfo, s.threadsUserInfo))
+	mux.HandleFunc("POST "+api+"/{id}/threads", s.handle(OpThreadsCreateContainer, s.threadsCreateContainer))
+	mux.HandleFunc("POST "+api+"/{id}/threads_publish", s.handle(OpThreadsPublish, s.threadsPublish))
+	mux.HandleFunc("GET "+api+"/{id}", s.handle(OpThreadsObject, s.threadsObject))
+}
+
+// threadsAuthorized checks the access_token parameter and writes a Graph API error if it is not valid
+func (s *Server) threadsAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformThreads, r.FormValue("access_token")) {
+		writeInstagramError(w, http.StatusBadRequest, 190, "Invalid OAuth access token - Cannot parse access token")
+		return false
+	}
+	return true
+}
+
+// threadsAuthorize approves the authorization request and redirects back with a code
+func (s *Server) threadsAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformThreads)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	if !contains(strings.Split(query.Get("scope"), ","), "threads_content_publish") {
+		http.Error(w, "the threads_content_publish scope is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformThreads, ""), query.Get("state"))
+}
+
+// threadsToken exchanges an authorization code for a short-lived token
+func (s *Server) threadsToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformThreads)
+	if r.PostFormValue("client_id") != app.id || r.PostFormValue("client_secret") != app.secret {
+		writeInstagramError(w, http.StatusBadRequest, 101, "Error validating client secret")
+		return
+	}
+	if _, ok := s.takeCode(models.PlatformThreads, r.PostFormValue("code")); !ok ||
+		r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != app.redirectURI {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid verification code format")
+		return
+	}
+
+	accessToken, _ := s.IssueToken(models.PlatformThreads)
+	userID, _ := strconv.ParseInt(ThreadsUserID, 10, 64)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"user_id":      userID,
+	})
+}
+
+// threadsLongLivedToken exchanges a short-lived token for a long-lived one
+func (s *Server) threadsLongLivedToken(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("grant_type") != "th_exchange_token" || r.FormValue("client_secret") != s.client(models.PlatformThreads).secret {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid grant_type or client_secret")
+		return
+	}
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+
+	// The short-lived token stays valid until it expires
+	accessToken, _ := s.IssueToken(models.PlatformThreads)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   threadsLongLifetime,
+	})
+}
+
+// threadsRefreshToken extends a long-lived token
+// Threads has no separate refresh token; the long-lived token itself is refreshed
+func (s *Server) threadsRefreshToken(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("grant_type") != "th_refresh_token" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Invalid grant_type")
+		return
+	}
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+
+	s.RevokeToken(r.FormValue("access_token"))
+	accessToken, _ := s.IssueToken(models.PlatformThreads)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "bearer",
+		"expires_in":   threadsLongLifetime,
+	})
+}
+
+// threadsUserInfo returns the fake Threads profile
+func (s *Server) threadsUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"id":                          ThreadsUserID,
+		"username":                    ThreadsUsername,
+		"name":                        ThreadsName,
+		"threads_profile_picture_url": s.URL + "/media/threads-avatar.jpg",
+	})
+}
+
+// threadsCreateContainer creates a text, image, video or carousel container
+func (s *Server) threadsCreateContainer(w http.ResponseWriter, r *http.Request) {
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+	if r.PathValue("id") != ThreadsUserID && r.PathValue("id") != "me" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Unsupported post request. Object does not exist")
+		return
+	}
+
+	container := &ThreadsContainer{
+		MediaType:      r.PostFormValue("media_type"),
+		Text:           r.PostFormValue("text"),
+		IsCarouselItem: r.PostFormValue("is_carousel_item") == "true",
+		ReplyToID:      r.PostFormValue("reply_to_id"),
+		ReplyControl:   r.PostFormValue("reply_control"),
+	}
+
+	// Unlike Instagram, Threads needs the media_type of every container
+	switch container.MediaType {
+	case "TEXT":
+		if container.Text == "" {
+			writeInstagramError(w, http.StatusBadRequest, 100, "The text is required for a TEXT post")
+			return
+		}
+	case "IMAGE":
+		container.MediaURL = r.PostFormValue("image_url")
+	case "VIDEO":
+		container.MediaURL = r.PostFormValue("video_url")
+		container.processing = s.currentProcessing(models.PlatformThreads)
+	case "CAROUSEL":
+		container.Children = strings.Split(r.PostFormValue("children"), ",")
+	default:
+		writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Invalid media_type %q", container.MediaType))
+		return
+	}
+	if len([]rune(container.Text)) > threadsMaxTextLength {
+		writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("The text is longer than %d characters", threadsMaxTextLength))
+		return
+	}
+	switch container.ReplyControl {
+	case "", "everyone", "accounts_you_follow", "mentioned_only":
+	default:
+		writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Invalid reply_control %q", container.ReplyControl))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case container.MediaType == "CAROUSEL":
+		if len(container.Children) < 2 || len(container.Children) > 20 {
+			writeInstagramError(w, http.StatusBadRequest, 100, "A carousel needs between 2 and 20 children")
+			return
+		}
+		for _, childID := range container.Children {
+			child, ok := s.threadsContainers[childID]
+			if !ok || !child.IsCarouselItem {
+				writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Child %s is not a carousel item", childID))
+				return
+			}
+		}
+	case container.MediaType != "TEXT" && container.MediaURL == "":
+		writeInstagramError(w, http.StatusBadRequest, 100, "The media URL is required")
+		return
+	}
+	if container.ReplyToID != "" && s.threadsPostLocked(container.ReplyToID) == nil {
+		writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("The post %s to reply to does not exist", container.ReplyToID))
+		return
+	}
+
+	container.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.threadsContainers[container.ID] = container
+	writeJSON(w, http.StatusOK, map[string]string{"id": container.ID})
+}
+
+// threadsObject returns fields of a container or published post
+func (s *Server) threadsObject(w http.ResponseWriter, r *http.Request) {
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	fields := strings.Split(r.FormValue("fields"), ",")
+	result := map[string]string{"id": id}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if container, ok := s.threadsContainers[id]; ok {
+		container.polls++
+		status, message := s.threadsStatusLocked(container)
+		if contains(fields, "status") {
+			result["status"] = status
+		}
+		if contains(fields, "error_message") && message != "" {
+			result["error_message"] = message
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	if post := s.threadsPostLocked(id); post != nil {
+		if contains(fields, "permalink") {
+			result["permalink"] = post.Permalink
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	writeInstagramError(w, http.StatusBadRequest, 100, fmt.Sprintf("Object with ID '%s' does not exist", id))
+}
+
+// threadsPostLocked returns the published post with id, or nil; s.mu must be held
+func (s *Server) threadsPostLocked(id string) *ThreadsPost {
+	for _, post := range s.threadsPosts {
+		if post.ID == id {
+			return post
+		}
+	}
+	return nil
+}
+
+// threadsStatusLocked returns the status of a container; s.mu must be held
+// Text and images are ready immediately, videos follow their Processing and carousels wait for their children
+func (s *Server) threadsStatusLocked(container *ThreadsContainer) (string, string) {
+	switch container.MediaType {
+	case "TEXT", "IMAGE":
+		return instagramFinished, ""
+	case "CAROUSEL":
+		for _, childID := range container.Children {
+			if status, message := s.threadsStatusLocked(s.threadsContainers[childID]); status != instagramFinished {
+				return status, message
+			}
+		}
+		return instagramFinished, ""
+	}
+
+	switch {
+	case container.polls <= container.processing.Polls:
+		return instagramInProgress, ""
+	case container.processing.FailReason != "":
+		return instagramError, container.processing.FailReason
+	default:
+		return instagramFinished, ""
+	}
+}
+
+// threadsPublish publishes a finished container
+func (s *Server) threadsPublish(w http.ResponseWriter, r *http.Request) {
+	if !s.threadsAuthorized(w, r) {
+		return
+	}
+	if r.PathValue("id") != ThreadsUserID && r.PathValue("id") != "me" {
+		writeInstagramError(w, http.StatusBadRequest, 100, "Unsupported post request. Object does not exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	container, ok := s.threadsContainers[r.PostFormValue("creation_id")]
+	if !ok || container.IsCarouselItem {
+		writeInstagramError(w, http.StatusBadRequest, 100, "The creation_id is not a publishable container")
+		return
+	}
+	if status, _ := s.threadsStatusLocked(container); status != instagramFinished {
+		writeInstagramError(w, http.StatusBadRequest, 9007, "The media is not ready for publishing, please wait for a moment")
+		return
+	}
+
+	post := &ThreadsPost{
+		ContainerID:  container.ID,
+		MediaType:    container.MediaType,
+		Text:         container.Text,
+		ReplyToID:    container.ReplyToID,
+		ReplyControl: container.ReplyControl,
+	}
+	switch container.MediaType {
+	case "CAROUSEL":
+		for _, childID := range container.Children {
+			post.MediaURLs = append(post.MediaURLs, s.threadsContainers[childID].MediaURL)
+		}
+	case "IMAGE", "VIDEO":
+		post.MediaURLs = []string{container.MediaURL}
+	}
+	post.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	post.Permalink = fmt.Sprintf("https://www.threads.net/@%s/post/fake%s", ThreadsUsername, post.ID)
+	s.threadsPosts = append(s.threadsPosts, post)
+	// A container can only be published once
+	delete(s.threadsContainers, container.ID)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": post.ID})
+}
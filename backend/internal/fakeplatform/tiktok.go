@@ -0,0 +1,309 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// tiktokPrivacyLevels are the privacy levels the fake creator can post with
+var tiktokPrivacyLevels = []string{"PUBLIC_TO_EVERYONE", "MUTUAL_FOLLOW_FRIENDS", "FOLLOWER_OF_CREATOR", "SELF_ONLY"}
+
+// TikTokPublish is a post initialized through the Content Posting API
+type TikTokPublish struct {
+	PublishID    string
+	Mode         string // direct or inbox
+	MediaType    string // video or photo
+	Title        string
+	PrivacyLevel string
+	MediaURLs    []string
+
+	processing Processing
+	polls      int
+}
+
+// TikTokPublishes returns the posts initialized on TikTok in order
+func (s *Server) TikTokPublishes() []TikTokPublish {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]TikTokPublish, 0, len(s.tiktokPublishes))
+	for _, publish := range s.tiktokPublishes {
+		result = append(result, *publish)
+	}
+	return result
+}
+
+// registerTikTok adds the TikTok endpoints to mux
+func (s *Server) registerTikTok(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v2/auth/authorize/", s.handle(OpTikTokAuthorize, s.tiktokAuthorize))
+	mux.HandleFunc("POST /v2/oauth/token/", s.handle(OpTikTokToken, s.tiktokToken))
+	mux.HandleFunc("GET /v2/user/info/", s.handle(OpTikTokUserInfo, s.tiktokUserInfo))
+	mux.HandleFunc("POST /v2/post/publish/creator_info/query/", s.handle(OpTikTokCreatorInfo, s.tiktokCreatorInfo))
+	mux.HandleFunc("POST /v2/post/publish/video/init/", s.handle(OpTikTokPublish, s.tiktokPublish("direct", "video")))
+	mux.HandleFunc("POST /v2/post/publish/inbox/video/init/", s.handle(OpTikTokPublish, s.tiktokPublish("inbox", "video")))
+	mux.HandleFunc("POST /v2/post/publish/content/init/", s.handle(OpTikTokPublish, s.tiktokPublish("", "photo")))
+	mux.HandleFunc("POST /v2/post/publish/status/fetch/", s.handle(OpTikTokStatus, s.tiktokStatus))
+}
+
+// writeTikTokError writes an error in the format of the TikTok Open API
+func writeTikTokError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message, "log_id": "fake"},
+	})
+}
+
+// writeTikTokData writes a successful TikTok Open API response
+func writeTikTokData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  data,
+		"error": map[string]string{"code": "ok", "message": "", "log_id": "fake"},
+	})
+}
+
+// tiktokAuthorized checks the request's bearer token and writes a 401 if it is not valid
+func (s *Server) tiktokAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformTikTok, bearerToken(r)) {
+		writeTikTokError(w, http.StatusUnauthorized, "access_token_invalid", "The access token is invalid or not found in the request.")
+		return false
+	}
+	return true
+}
+
+// tiktokAuthorize approves the authorization request and redirects back with a code
+func (s *Server) tiktokAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformTikTok)
+	if query.Get("client_key") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_key, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformTikTok, ""), query.Get("state"))
+}
+
+// tiktokToken exchanges authorization codes and refresh tokens
+func (s *Server) tiktokToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformTikTok)
+	if r.PostFormValue("client_key") != app.id || r.PostFormValue("client_secret") != app.secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client", "error_description": "Client key or secret is incorrect."})
+		return
+	}
+
+	var accessToken, refreshToken string
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		if _, ok := s.takeCode(models.PlatformTikTok, r.PostFormValue("code")); !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Authorization code is expired."})
+			return
+		}
+		accessToken, refreshToken = s.IssueToken(models.PlatformTikTok)
+	case "refresh_token":
+		var ok bool
+		accessToken, refreshToken, ok = s.takeRefreshToken(models.PlatformTikTok, r.PostFormValue("refresh_token"))
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Refresh token is invalid or expired."})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "Grant type is not supported."})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":       accessToken,
+		"refresh_token":      refreshToken,
+		"expires_in":         tiktokTokenLifetime,
+		"refresh_expires_in": 365 * 24 * 60 * 60,
+		"open_id":            TikTokOpenID,
+		"scope":              "user.info.basic,video.publish",
+		"token_type":         "Bearer",
+	})
+}
+
+// tiktokUserInfo returns the fake creator's profile
+func (s *Server) tiktokUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.tiktokAuthorized(w, r) {
+		return
+	}
+	writeTikTokData(w, map[string]interface{}{
+		"user": map[string]string{
+			"open_id":      TikTokOpenID,
+			"display_name": TikTokDisplayName,
+			"avatar_url":   s.URL + "/media/avatar.jpg",
+		},
+	})
+}
+
+// tiktokCreatorInfo returns what the fake creator is allowed to post
+func (s *Server) tiktokCreatorInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.tiktokAuthorized(w, r) {
+		return
+	}
+	writeTikTokData(w, map[string]interface{}{
+		"creator_username":            TikTokOpenID,
+		"creator_nickname":            TikTokDisplayName,
+		"privacy_level_options":       tiktokPrivacyLevels,
+		"comment_disabled":            false,
+		"duet_disabled":               false,
+		"stitch_disabled":             false,
+		"max_video_post_duration_sec": 600,
+	})
+}
+
+// tiktokPublishRequest is the body of the video and photo init endpoints
+type tiktokPublishRequest struct {
+	PostInfo struct {
+		Title        string `json:"title"`
+		PrivacyLevel string `json:"privacy_level"`
+	} `json:"post_info"`
+	SourceInfo struct {
+		Source      string   `json:"source"`
+		VideoURL    string   `json:"video_url"`
+		PhotoImages []string `json:"photo_images"`
+	} `json:"source_info"`
+	PostMode  string `json:"post_mode"`
+	MediaType string `json:"media_type"`
+}
+
+// tiktokPublish initializes a video or photo post
+// Photo posts choose between direct and inbox with post_mode instead of the endpoint
+func (s *Server) tiktokPublish(mode, mediaType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.tiktokAuthorized(w, r) {
+			return
+		}
+
+		var req tiktokPublishRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeTikTokError(w, http.StatusBadRequest, "invalid_params", "Request body is not valid JSON.")
+			return
+		}
+		if req.SourceInfo.Source != "PULL_FROM_URL" {
+			writeTikTokError(w, http.StatusBadRequest, "invalid_params", "source must be PULL_FROM_URL.")
+			return
+		}
+
+		publish := &TikTokPublish{
+			Mode:         mode,
+			MediaType:    mediaType,
+			Title:        req.PostInfo.Title,
+			PrivacyLevel: req.PostInfo.PrivacyLevel,
+		}
+
+		if mediaType == "photo" {
+			switch req.PostMode {
+			case "DIRECT_POST":
+				publish.Mode = "direct"
+			case "MEDIA_UPLOAD":
+				publish.Mode = "inbox"
+			default:
+				writeTikTokError(w, http.StatusBadRequest, "invalid_params", "post_mode must be DIRECT_POST or MEDIA_UPLOAD.")
+				return
+			}
+			if req.MediaType != "PHOTO" || len(req.SourceInfo.PhotoImages) == 0 || len(req.SourceInfo.PhotoImages) > 35 {
+				writeTikTokError(w, http.StatusBadRequest, "invalid_params", "photo_images must contain between 1 and 35 images.")
+				return
+			}
+			publish.MediaURLs = req.SourceInfo.PhotoImages
+		} else {
+			if req.SourceInfo.VideoURL == "" {
+				writeTikTokError(w, http.StatusBadRequest, "invalid_params", "video_url is required.")
+				return
+			}
+			publish.MediaURLs = []string{req.SourceInfo.VideoURL}
+		}
+
+		if publish.Mode == "direct" && !contains(tiktokPrivacyLevels, publish.PrivacyLevel) {
+			writeTikTokError(w, http.StatusBadRequest, "invalid_params", "privacy_level must be one of the creator's privacy_level_options.")
+			return
+		}
+		for _, mediaURL := range publish.MediaURLs {
+			if _, err := url.ParseRequestURI(mediaURL); err != nil {
+				writeTikTokError(w, http.StatusBadRequest, "invalid_params", fmt.Sprintf("%s is not a valid URL.", mediaURL))
+				return
+			}
+		}
+
+		publish.processing = s.currentProcessing(models.PlatformTikTok)
+		s.mu.Lock()
+		publish.PublishID = fmt.Sprintf("v_pub_url~v2-1.%d", s.newIDLocked())
+		s.tiktokPublishes = append(s.tiktokPublishes, publish)
+		s.mu.Unlock()
+
+		writeTikTokData(w, map[string]string{"publish_id": publish.PublishID})
+	}
+}
+
+// tiktokStatus reports the processing status of a post
+func (s *Server) tiktokStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.tiktokAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		PublishID string `json:"publish_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeTikTokError(w, http.StatusBadRequest, "invalid_params", "Request body is not valid JSON.")
+		return
+	}
+
+	s.mu.Lock()
+	var publish *TikTokPublish
+	for _, p := range s.tiktokPublishes {
+		if p.PublishID == req.PublishID {
+			publish = p
+			break
+		}
+	}
+	if publish == nil {
+		s.mu.Unlock()
+		writeTikTokError(w, http.StatusBadRequest, "invalid_publish_id", "The publish_id does not exist.")
+		return
+	}
+	publish.polls++
+	polls, processing, mode := publish.polls, publish.processing, publish.Mode
+	s.mu.Unlock()
+
+	data := map[string]interface{}{"publish_id": req.PublishID}
+	switch {
+	case polls <= processing.Polls:
+		data["status"] = "PROCESSING_DOWNLOAD"
+	case processing.FailReason != "":
+		data["status"] = "FAILED"
+		data["fail_reason"] = processing.FailReason
+	case mode == "inbox":
+		data["status"] = "SEND_TO_USER_INBOX"
+	default:
+		data["status"] = "PUBLISH_COMPLETE"
+		data["publicaly_available_post_id"] = []int64{s.newID()}
+	}
+	writeTikTokData(w, data)
+}
+
+// redirectWithCode sends the browser back to the app with an authorization code
+func redirectWithCode(w http.ResponseWriter, r *http.Request, redirectURI, code, state string) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", state)
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// contains reports whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
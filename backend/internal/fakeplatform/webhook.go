@@ -0,0 +1,159 @@
+package fakeplatform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// webhookPrefix is where the fake endpoint is served, so its routes don't clash with other platforms
+const webhookPrefix = "/webhook"
+
+// webhookTimestampTolerance is how old a delivery's timestamp may be, as a receiver would check to stop replays
+const webhookTimestampTolerance = 5 * time.Minute
+
+// WebhookDelivery is a delivery the fake endpoint accepted
+type WebhookDelivery struct {
+	Event      string
+	DeliveryID string
+	Timestamp  int64  // Unix time the delivery was signed at
+	Signature  string // HMAC-SHA256 of the timestamp and the body, checked before the delivery is recorded
+	Body       []byte
+	Post       *WebhookPost // Nil for pings
+	Attempts   int          // How many times the delivery was received; repeats are answered with the first result
+}
+
+// WebhookPost is the post of a delivery
+type WebhookPost struct {
+	ID            int64
+	PublicationID string
+	Caption       string
+	MediaURLs     []string
+	EntryID       string // ID the endpoint answered with
+	EntryURL      string
+}
+
+// WebhookURL returns the URL of the fake endpoint
+func (s *Server) WebhookURL() string {
+	return s.URL + webhookPrefix + "/receiver"
+}
+
+// WebhookDeliveries returns the deliveries the endpoint accepted, in order
+func (s *Server) WebhookDeliveries() []WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]WebhookDelivery, 0, len(s.webhookDeliveries))
+	for _, delivery := range s.webhookDeliveries {
+		result = append(result, *delivery)
+	}
+	return result
+}
+
+// registerWebhook registers the endpoint
+func (s *Server) registerWebhook(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+webhookPrefix+"/receiver", s.handle(OpWebhookDeliver, s.webhookDeliver))
+}
+
+// writeWebhookError writes an error in the format endpoints may classify their failures with
+func writeWebhookError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message, "error_code": code})
+}
+
+// webhookDeliver serves POST /receiver, verifying the signature like a receiver is told to
+func (s *Server) webhookDeliver(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeWebhookError(w, http.StatusBadRequest, "", "Unreadable body")
+		return
+	}
+
+	timestamp, err := strconv.ParseInt(r.Header.Get("X-Sosyal-Timestamp"), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)).Abs() > webhookTimestampTolerance {
+		writeWebhookError(w, http.StatusUnauthorized, "", "Missing or stale timestamp")
+		return
+	}
+	mac := hmac.New(sha256.New, []byte(WebhookSecret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	signature := r.Header.Get("X-Sosyal-Signature")
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		writeWebhookError(w, http.StatusUnauthorized, "", "Invalid signature")
+		return
+	}
+
+	var payload struct {
+		Event      string `json:"event"`
+		DeliveryID string `json:"delivery_id"`
+		Post       *struct {
+			ID            int64    `json:"id"`
+			PublicationID string   `json:"publication_id"`
+			Caption       string   `json:"caption"`
+			MediaURLs     []string `json:"media_urls"`
+		} `json:"post"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeWebhookError(w, http.StatusBadRequest, "", "Invalid JSON")
+		return
+	}
+	if payload.Event != r.Header.Get("X-Sosyal-Event") || payload.DeliveryID != r.Header.Get("X-Sosyal-Delivery") {
+		writeWebhookError(w, http.StatusBadRequest, "", "Headers don't match the body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range s.webhookDeliveries {
+		if delivery.DeliveryID == payload.DeliveryID {
+			delivery.Attempts++
+			writeWebhookResult(w, delivery)
+			return
+		}
+	}
+
+	delivery := &WebhookDelivery{
+		Event:      payload.Event,
+		DeliveryID: payload.DeliveryID,
+		Timestamp:  timestamp,
+		Signature:  signature,
+		Body:       body,
+		Attempts:   1,
+	}
+	switch payload.Event {
+	case "ping":
+	case "post.publish":
+		if payload.Post == nil {
+			writeWebhookError(w, http.StatusBadRequest, "", "Missing post")
+			return
+		}
+		entryID := strconv.FormatInt(s.newIDLocked(), 10)
+		delivery.Post = &WebhookPost{
+			ID:            payload.Post.ID,
+			PublicationID: payload.Post.PublicationID,
+			Caption:       payload.Post.Caption,
+			MediaURLs:     payload.Post.MediaURLs,
+			EntryID:       entryID,
+			EntryURL:      s.URL + webhookPrefix + "/entries/" + entryID,
+		}
+	default:
+		writeWebhookError(w, http.StatusBadRequest, "", "Unknown event")
+		return
+	}
+	s.webhookDeliveries = append(s.webhookDeliveries, delivery)
+	writeWebhookResult(w, delivery)
+}
+
+// writeWebhookResult answers a delivery with what was published, if anything
+func writeWebhookResult(w http.ResponseWriter, delivery *WebhookDelivery) {
+	if delivery.Post == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"id": delivery.Post.EntryID, "url": delivery.Post.EntryURL})
+}
@@ -0,0 +1,407 @@
+package fakeplatform
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// xMaxSegmentSize is the largest segment X accepts in one APPEND
+const xMaxSegmentSize = 5 * 1024 * 1024
+
+// XUpload is a media upload started on X
+type XUpload struct {
+	MediaID       string
+	MediaType     string
+	MediaCategory string
+	TotalBytes    int64
+	Segments      map[int][]byte // Appended segments by segment index
+	Finalized     bool
+
+	processing Processing
+	polls      int
+}
+
+// Data returns the uploaded bytes in segment order
+func (u XUpload) Data() []byte {
+	var data []byte
+	for i := 0; i < len(u.Segments); i++ {
+		data = append(data, u.Segments[i]...)
+	}
+	return data
+}
+
+// Tweet is a post created on X
+type Tweet struct {
+	ID       string
+	Text     string
+	MediaIDs []string
+}
+
+// XUpload returns the upload with the given media ID
+func (s *Server) XUpload(mediaID string) (XUpload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.xUploads[mediaID]
+	if !ok {
+		return XUpload{}, false
+	}
+	return copyXUpload(upload), true
+}
+
+// XUploads returns every upload started on X
+func (s *Server) XUploads() []XUpload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]XUpload, 0, len(s.xUploads))
+	for _, upload := range s.xUploads {
+		result = append(result, copyXUpload(upload))
+	}
+	return result
+}
+
+// Tweets returns the tweets created on X in order
+func (s *Server) Tweets() []Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Tweet, 0, len(s.tweets))
+	for _, tweet := range s.tweets {
+		result = append(result, *tweet)
+	}
+	return result
+}
+
+// copyXUpload copies an upload so callers cannot race with the handlers; s.mu must be held
+func copyXUpload(upload *XUpload) XUpload {
+	result := *upload
+	result.Segments = make(map[int][]byte, len(upload.Segments))
+	for index, segment := range upload.Segments {
+		result.Segments[index] = segment
+	}
+	return result
+}
+
+// registerX adds the X endpoints to mux
+func (s *Server) registerX(mux *http.ServeMux) {
+	mux.HandleFunc("GET /i/oauth2/authorize", s.handle(OpXAuthorize, s.xAuthorize))
+	mux.HandleFunc("POST /2/oauth2/token", s.handle(OpXToken, s.xToken))
+	mux.HandleFunc("GET /2/users/me", s.handle(OpXUserInfo, s.xUserInfo))
+	mux.HandleFunc("POST /2/media/upload/initialize", s.handle(OpXMediaInit, s.xMediaInit))
+	mux.HandleFunc("POST /2/media/upload/{id}/append", s.handle(OpXMediaAppend, s.xMediaAppend))
+	mux.HandleFunc("POST /2/media/upload/{id}/finalize", s.handle(OpXMediaFinalize, s.xMediaFinalize))
+	mux.HandleFunc("GET /2/media/upload", s.handle(OpXMediaStatus, s.xMediaStatus))
+	mux.HandleFunc("POST /2/tweets", s.handle(OpXTweet, s.xTweet))
+}
+
+// writeXError writes an error in the problem format of the X API v2
+func writeXError(w http.ResponseWriter, status int, title, detail string) {
+	writeJSON(w, status, map[string]interface{}{
+		"title":  title,
+		"detail": detail,
+		"type":   "about:blank",
+		"status": status,
+	})
+}
+
+// xAuthorized checks the request's bearer token and writes a 401 if it is not valid
+func (s *Server) xAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformX, bearerToken(r)) {
+		writeXError(w, http.StatusUnauthorized, "Unauthorized", "Unauthorized")
+		return false
+	}
+	return true
+}
+
+// xAuthorize approves the authorization request and redirects back with a code
+func (s *Server) xAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformX)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "a S256 code_challenge is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformX, query.Get("code_challenge")), query.Get("state"))
+}
+
+// xToken exchanges authorization codes and refresh tokens
+func (s *Server) xToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformX)
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != app.id || secret != app.secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized_client", "error_description": "Missing valid authorization header"})
+		return
+	}
+
+	var accessToken, refreshToken string
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		auth, ok := s.takeCode(models.PlatformX, r.PostFormValue("code"))
+		if !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "Value passed for the authorization code was invalid."})
+			return
+		}
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(verifier[:]) != auth.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "Value passed for the code_verifier was invalid."})
+			return
+		}
+		accessToken, refreshToken = s.IssueToken(models.PlatformX)
+	case "refresh_token":
+		accessToken, refreshToken, ok = s.takeRefreshToken(models.PlatformX, r.PostFormValue("refresh_token"))
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "Value passed for the token was invalid."})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "grant_type is not supported"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"token_type":    "bearer",
+		"expires_in":    xTokenLifetime,
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"scope":         "tweet.read tweet.write users.read media.write offline.access",
+	})
+}
+
+// xUserInfo returns the fake account's profile
+func (s *Server) xUserInfo(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]string{
+			"id":                XUserID,
+			"name":              "Fake X User",
+			"username":          XUsername,
+			"profile_image_url": s.URL + "/media/avatar.jpg",
+		},
+	})
+}
+
+// xMediaInit starts a chunked media upload
+func (s *Server) xMediaInit(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		MediaType     string `json:"media_type"`
+		TotalBytes    int64  `json:"total_bytes"`
+		MediaCategory string `json:"media_category"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "Request body is not valid JSON")
+		return
+	}
+	if req.MediaType == "" || req.TotalBytes <= 0 {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media_type and a positive total_bytes are required")
+		return
+	}
+
+	upload := &XUpload{
+		MediaType:     req.MediaType,
+		MediaCategory: req.MediaCategory,
+		TotalBytes:    req.TotalBytes,
+		Segments:      make(map[int][]byte),
+	}
+	if req.MediaCategory == "amplify_video" {
+		upload.processing = s.currentProcessing(models.PlatformX)
+	}
+
+	s.mu.Lock()
+	upload.MediaID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.xUploads[upload.MediaID] = upload
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":                 upload.MediaID,
+			"media_key":          "7_" + upload.MediaID,
+			"expires_after_secs": 86400,
+		},
+	})
+}
+
+// xMediaAppend stores one segment of an upload; segments may arrive in any order
+func (s *Server) xMediaAppend(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+
+	if err := r.ParseMultipartForm(xMaxSegmentSize); err != nil {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "Request body is not valid multipart form data")
+		return
+	}
+	segmentIndex, err := strconv.Atoi(r.FormValue("segment_index"))
+	if err != nil || segmentIndex < 0 {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "segment_index must be a non-negative integer")
+		return
+	}
+	file, _, err := r.FormFile("media")
+	if err != nil {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media is required")
+		return
+	}
+	defer file.Close()
+	segment, err := io.ReadAll(file)
+	if err != nil || len(segment) > xMaxSegmentSize {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media segment is too large")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	upload, ok := s.xUploads[r.PathValue("id")]
+	if !ok || upload.Finalized {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media_id is not an upload in progress")
+		return
+	}
+	upload.Segments[segmentIndex] = segment
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// xMediaFinalize completes an upload once every byte has arrived
+func (s *Server) xMediaFinalize(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	upload, ok := s.xUploads[r.PathValue("id")]
+	if !ok {
+		s.mu.Unlock()
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media_id was not found")
+		return
+	}
+	if size := int64(len(upload.Data())); size != upload.TotalBytes {
+		s.mu.Unlock()
+		writeXError(w, http.StatusBadRequest, "Invalid Request", fmt.Sprintf("received %d of %d bytes", size, upload.TotalBytes))
+		return
+	}
+	upload.Finalized = true
+	data := map[string]interface{}{
+		"id":        upload.MediaID,
+		"media_key": "7_" + upload.MediaID,
+		"size":      upload.TotalBytes,
+	}
+	if info := xProcessingInfo(upload); info != nil {
+		data["processing_info"] = info
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// xMediaStatus reports the processing status of an upload
+func (s *Server) xMediaStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+	if r.URL.Query().Get("command") != "STATUS" {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "command must be STATUS")
+		return
+	}
+
+	s.mu.Lock()
+	upload, ok := s.xUploads[r.URL.Query().Get("media_id")]
+	if !ok || !upload.Finalized {
+		s.mu.Unlock()
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "media_id was not found")
+		return
+	}
+	upload.polls++
+	data := map[string]interface{}{"id": upload.MediaID}
+	if info := xProcessingInfo(upload); info != nil {
+		data["processing_info"] = info
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// xProcessingInfo describes where processing of a finalized upload stands; s.mu must be held
+// Only videos are processed; other media is ready as soon as it is finalized
+func xProcessingInfo(upload *XUpload) map[string]interface{} {
+	if upload.MediaCategory != "amplify_video" {
+		return nil
+	}
+	switch {
+	case upload.polls < upload.processing.Polls:
+		return map[string]interface{}{
+			"state":            "in_progress",
+			"check_after_secs": 1,
+			"progress_percent": 100 * upload.polls / upload.processing.Polls,
+		}
+	case upload.processing.FailReason != "":
+		return map[string]interface{}{
+			"state": "failed",
+			"error": map[string]string{"name": "InvalidMedia", "message": upload.processing.FailReason},
+		}
+	case upload.polls == 0:
+		// Processing is always reported at least once so clients exercise the status check
+		return map[string]interface{}{"state": "pending", "check_after_secs": 1}
+	default:
+		return map[string]interface{}{"state": "succeeded", "progress_percent": 100}
+	}
+}
+
+// xTweet creates a tweet, checking that its media finished uploading
+func (s *Server) xTweet(w http.ResponseWriter, r *http.Request) {
+	if !s.xAuthorized(w, r) {
+		return
+	}
+
+	var req struct {
+		Text  string `json:"text"`
+		Media *struct {
+			MediaIDs []string `json:"media_ids"`
+		} `json:"media"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "Request body is not valid JSON")
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" && req.Media == nil {
+		writeXError(w, http.StatusBadRequest, "Invalid Request", "text or media is required")
+		return
+	}
+
+	tweet := &Tweet{Text: req.Text}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Media != nil {
+		for _, mediaID := range req.Media.MediaIDs {
+			upload, ok := s.xUploads[mediaID]
+			if !ok || !upload.Finalized {
+				writeXError(w, http.StatusBadRequest, "Invalid Request", fmt.Sprintf("media_id %s is not a finalized upload", mediaID))
+				return
+			}
+			if info := xProcessingInfo(upload); info != nil && info["state"] != "succeeded" {
+				writeXError(w, http.StatusBadRequest, "Invalid Request", fmt.Sprintf("media_id %s is not ready", mediaID))
+				return
+			}
+		}
+		tweet.MediaIDs = req.Media.MediaIDs
+	}
+	tweet.ID = strconv.FormatInt(s.newIDLocked(), 10)
+	s.tweets = append(s.tweets, tweet)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": map[string]string{"id": tweet.ID, "text": tweet.Text},
+	})
+}
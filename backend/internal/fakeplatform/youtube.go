@@ -0,0 +1,407 @@
+package fakeplatform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// youtubeRejectionReasons are the Processing fail reasons that make YouTube reject a video
+// instead of failing its upload
+var youtubeRejectionReasons = map[string]bool{
+	"claim":         true,
+	"copyright":     true,
+	"duplicate":     true,
+	"inappropriate": true,
+	"legal":         true,
+	"length":        true,
+	"termsOfUse":    true,
+	"trademark":     true,
+}
+
+// YouTubeVideo is a video uploaded with the resumable upload protocol
+type YouTubeVideo struct {
+	ID            string
+	Title         string
+	Description   string
+	Tags          []string
+	CategoryID    string
+	PrivacyStatus string
+	MadeForKids   bool
+	PublishAt     string
+	ContentType   string
+	Data          []byte
+	Chunks        int // PUT requests the bytes arrived in
+
+	processing Processing
+	polls      int
+}
+
+// youtubeUpload is a resumable upload session that has not received every byte yet
+type youtubeUpload struct {
+	video *YouTubeVideo
+	size  int64
+}
+
+// YouTubeVideos returns the videos uploaded to YouTube in order
+func (s *Server) YouTubeVideos() []YouTubeVideo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]YouTubeVideo, 0, len(s.youtubeVideos))
+	for _, video := range s.youtubeVideos {
+		result = append(result, *video)
+	}
+	return result
+}
+
+// registerYouTube adds the Google OAuth and YouTube Data API endpoints to mux
+func (s *Server) registerYouTube(mux *http.ServeMux) {
+	mux.HandleFunc("GET /o/oauth2/v2/auth", s.handle(OpYouTubeAuthorize, s.youtubeAuthorize))
+	mux.HandleFunc("POST /token", s.handle(OpYouTubeToken, s.youtubeToken))
+	mux.HandleFunc("GET /youtube/v3/channels", s.handle(OpYouTubeChannels, s.youtubeChannels))
+	mux.HandleFunc("POST /upload/youtube/v3/videos", s.handle(OpYouTubeUploadInit, s.youtubeUploadInit))
+	mux.HandleFunc("PUT /upload/youtube/v3/videos", s.handle(OpYouTubeUpload, s.youtubeUpload))
+	mux.HandleFunc("GET /youtube/v3/videos", s.handle(OpYouTubeVideoStatus, s.youtubeVideoStatus))
+}
+
+// writeYouTubeError writes an error in the format of the Google APIs
+func writeYouTubeError(w http.ResponseWriter, status int, reason, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": message,
+			"errors": []map[string]string{
+				{"message": message, "domain": "youtube.video", "reason": reason},
+			},
+		},
+	})
+}
+
+// writeGoogleOAuthError writes an error of the Google OAuth endpoints
+func writeGoogleOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+// youtubeAuthorize approves the authorization request and redirects back with a code
+// Only offline access is supported, because that is what issues the refresh token
+func (s *Server) youtubeAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	app := s.client(models.PlatformYouTube)
+	if query.Get("client_id") != app.id || query.Get("redirect_uri") != app.redirectURI || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id, redirect_uri or response_type", http.StatusBadRequest)
+		return
+	}
+	if !strings.Contains(query.Get("scope"), "https://www.googleapis.com/auth/youtube.upload") {
+		http.Error(w, "the youtube.upload scope is required", http.StatusBadRequest)
+		return
+	}
+	if query.Get("access_type") != "offline" {
+		http.Error(w, "access_type must be offline", http.StatusBadRequest)
+		return
+	}
+
+	redirectWithCode(w, r, query.Get("redirect_uri"), s.newCode(models.PlatformYouTube, ""), query.Get("state"))
+}
+
+// youtubeToken exchanges authorization codes and refresh tokens
+// Like Google, a refresh doesn't rotate the refresh token and leaves it out of the response
+func (s *Server) youtubeToken(w http.ResponseWriter, r *http.Request) {
+	app := s.client(models.PlatformYouTube)
+	if r.PostFormValue("client_id") != app.id || r.PostFormValue("client_secret") != app.secret {
+		writeGoogleOAuthError(w, http.StatusUnauthorized, "invalid_client", "The OAuth client was not found.")
+		return
+	}
+
+	result := map[string]interface{}{
+		"expires_in": youtubeTokenLifetime,
+		"scope":      "https://www.googleapis.com/auth/youtube.upload https://www.googleapis.com/auth/youtube.readonly",
+		"token_type": "Bearer",
+	}
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		if _, ok := s.takeCode(models.PlatformYouTube, r.PostFormValue("code")); !ok || r.PostFormValue("redirect_uri") != app.redirectURI {
+			writeGoogleOAuthError(w, http.StatusBadRequest, "invalid_grant", "Malformed auth code.")
+			return
+		}
+		result["access_token"], result["refresh_token"] = s.IssueToken(models.PlatformYouTube)
+	case "refresh_token":
+		accessToken, ok := s.youtubeRefresh(r.PostFormValue("refresh_token"))
+		if !ok {
+			writeGoogleOAuthError(w, http.StatusBadRequest, "invalid_grant", "Token has been expired or revoked.")
+			return
+		}
+		result["access_token"] = accessToken
+	default:
+		writeGoogleOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Invalid grant_type: "+r.PostFormValue("grant_type"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// youtubeRefresh issues a new access token for a refresh token, which stays valid
+func (s *Server) youtubeRefresh(refreshToken string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refreshTokens[refreshToken] != models.PlatformYouTube {
+		return "", false
+	}
+	accessToken := fmt.Sprintf("%s-access-%d", models.PlatformYouTube, s.newIDLocked())
+	s.accessTokens[accessToken] = models.PlatformYouTube
+	return accessToken, true
+}
+
+// youtubeAuthorized checks the bearer token, writing a Google error if it is invalid
+func (s *Server) youtubeAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if !s.validToken(models.PlatformYouTube, bearerToken(r)) {
+		writeYouTubeError(w, http.StatusUnauthorized, "authError", "Request had invalid authentication credentials.")
+		return false
+	}
+	return true
+}
+
+// youtubeChannels returns the fake user's channel
+func (s *Server) youtubeChannels(w http.ResponseWriter, r *http.Request) {
+	if !s.youtubeAuthorized(w, r) {
+		return
+	}
+	if r.URL.Query().Get("mine") != "true" {
+		writeYouTubeError(w, http.StatusBadRequest, "missingRequiredParameter", "No filter selected. Expected one of: id, mine, forHandle")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind": "youtube#channelListResponse",
+		"items": []map[string]interface{}{{
+			"kind": "youtube#channel",
+			"id":   YouTubeChannelID,
+			"snippet": map[string]interface{}{
+				"title":     YouTubeChannelTitle,
+				"customUrl": YouTubeHandle,
+				"thumbnails": map[string]interface{}{
+					"default": map[string]string{"url": s.URL + "/media/avatar-88.jpg"},
+					"medium":  map[string]string{"url": s.URL + "/media/avatar-240.jpg"},
+				},
+			},
+		}},
+	})
+}
+
+// youtubeUploadInit starts a resumable upload session for a video with its snippet and status
+func (s *Server) youtubeUploadInit(w http.ResponseWriter, r *http.Request) {
+	if !s.youtubeAuthorized(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("uploadType") != "resumable" {
+		writeYouTubeError(w, http.StatusBadRequest, "invalidParameter", "uploadType must be resumable")
+		return
+	}
+
+	size, err := strconv.ParseInt(r.Header.Get("X-Upload-Content-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		writeYouTubeError(w, http.StatusBadRequest, "badContent", "X-Upload-Content-Length is required")
+		return
+	}
+	contentType := r.Header.Get("X-Upload-Content-Type")
+	if !strings.HasPrefix(contentType, "video/") {
+		writeYouTubeError(w, http.StatusBadRequest, "mediaBodyRequired", "X-Upload-Content-Type must be a video type")
+		return
+	}
+
+	var req struct {
+		Snippet struct {
+			Title       string   `json:"title"`
+			Description string   `json:"description"`
+			Tags        []string `json:"tags"`
+			CategoryID  string   `json:"categoryId"`
+		} `json:"snippet"`
+		Status struct {
+			PrivacyStatus           string `json:"privacyStatus"`
+			SelfDeclaredMadeForKids bool   `json:"selfDeclaredMadeForKids"`
+			PublishAt               string `json:"publishAt"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeYouTubeError(w, http.StatusBadRequest, "parseError", "Parse Error")
+		return
+	}
+	switch {
+	case req.Snippet.Title == "" || len([]rune(req.Snippet.Title)) > 100 || strings.ContainsAny(req.Snippet.Title, "<>"):
+		writeYouTubeError(w, http.StatusBadRequest, "invalidTitle", "The request metadata specifies an invalid video title.")
+		return
+	case len([]byte(req.Snippet.Description)) > 5000:
+		writeYouTubeError(w, http.StatusBadRequest, "invalidDescription", "The request metadata specifies an invalid video description.")
+		return
+	case !contains([]string{"public", "unlisted", "private"}, req.Status.PrivacyStatus):
+		writeYouTubeError(w, http.StatusBadRequest, "invalidVideoMetadata", "The request metadata is invalid.")
+		return
+	case req.Status.PublishAt != "" && req.Status.PrivacyStatus != "private":
+		writeYouTubeError(w, http.StatusBadRequest, "invalidPublishAt", "The request metadata specifies an invalid scheduled publishing time.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.newIDLocked()
+	video := &YouTubeVideo{
+		ID:            fmt.Sprintf("yt%09d", id),
+		Title:         req.Snippet.Title,
+		Description:   req.Snippet.Description,
+		Tags:          req.Snippet.Tags,
+		CategoryID:    req.Snippet.CategoryID,
+		PrivacyStatus: req.Status.PrivacyStatus,
+		MadeForKids:   req.Status.SelfDeclaredMadeForKids,
+		PublishAt:     req.Status.PublishAt,
+		ContentType:   contentType,
+		processing:    s.processing[models.PlatformYouTube],
+	}
+	uploadID := fmt.Sprintf("fake-upload-%d", id)
+	s.youtubeUploads[uploadID] = &youtubeUpload{video: video, size: size}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/upload/youtube/v3/videos?uploadType=resumable&upload_id=%s", s.URL, uploadID))
+	w.WriteHeader(http.StatusOK)
+}
+
+// youtubeUpload receives bytes of an upload session, or reports how many it has
+// Every response but the last is 308 Resume Incomplete with the received bytes in Range; the
+// request that completes the upload creates the video
+func (s *Server) youtubeUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uploadID := r.URL.Query().Get("upload_id")
+	upload, ok := s.youtubeUploads[uploadID]
+	if !ok {
+		writeYouTubeError(w, http.StatusNotFound, "notFound", "Upload session not found")
+		return
+	}
+
+	first, last, total, ok := parseContentRange(r.Header.Get("Content-Range"))
+	if !ok || total != upload.size {
+		writeYouTubeError(w, http.StatusBadRequest, "badContent", "Content-Range is invalid or doesn't match X-Upload-Content-Length")
+		return
+	}
+	received := int64(len(upload.video.Data))
+	if first >= 0 {
+		if first != received || last-first+1 != int64(len(data)) || last >= total {
+			writeYouTubeError(w, http.StatusBadRequest, "badContent", fmt.Sprintf("Expected bytes starting at %d", received))
+			return
+		}
+		upload.video.Data = append(upload.video.Data, data...)
+		upload.video.Chunks++
+		received += int64(len(data))
+	}
+
+	if received < upload.size {
+		if received > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+
+	delete(s.youtubeUploads, uploadID)
+	s.youtubeVideos = append(s.youtubeVideos, upload.video)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"kind": "youtube#video",
+		"id":   upload.video.ID,
+		"status": map[string]interface{}{
+			"uploadStatus":  "uploaded",
+			"privacyStatus": upload.video.PrivacyStatus,
+		},
+	})
+}
+
+// parseContentRange parses "bytes first-last/total", or "bytes */total" with first and last -1
+func parseContentRange(header string) (first, last, total int64, ok bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, 0, false
+	}
+	byteRange, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, false
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	if byteRange == "*" {
+		return -1, -1, total, true
+	}
+
+	from, to, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, 0, false
+	}
+	first, err1 := strconv.ParseInt(from, 10, 64)
+	last, err2 := strconv.ParseInt(to, 10, 64)
+	if err1 != nil || err2 != nil || first > last {
+		return 0, 0, 0, false
+	}
+	return first, last, total, true
+}
+
+// youtubeVideoStatus returns the upload status and processing details of a video
+// Videos are processed after their upload, following their Processing; videos that don't
+// exist are left out of the list, like on YouTube
+func (s *Server) youtubeVideoStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.youtubeAuthorized(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []map[string]interface{}{}
+	for _, video := range s.youtubeVideos {
+		if video.ID != r.URL.Query().Get("id") {
+			continue
+		}
+
+		video.polls++
+		status := map[string]interface{}{"privacyStatus": video.PrivacyStatus}
+		if video.PublishAt != "" {
+			status["publishAt"] = video.PublishAt
+		}
+		details := map[string]interface{}{}
+		switch reason := video.processing.FailReason; {
+		case video.polls <= video.processing.Polls:
+			status["uploadStatus"] = "uploaded"
+			details["processingStatus"] = "processing"
+			details["processingProgress"] = map[string]string{
+				"partsTotal":     "100",
+				"partsProcessed": strconv.Itoa(video.polls * 100 / (video.processing.Polls + 1)),
+			}
+		case youtubeRejectionReasons[reason]:
+			status["uploadStatus"] = "rejected"
+			status["rejectionReason"] = reason
+			details["processingStatus"] = "succeeded"
+		case reason != "":
+			status["uploadStatus"] = "failed"
+			status["failureReason"] = reason
+			details["processingStatus"] = "failed"
+			details["processingFailureReason"] = "uploadFailed"
+		default:
+			status["uploadStatus"] = "processed"
+			details["processingStatus"] = "succeeded"
+		}
+		items = append(items, map[string]interface{}{
+			"kind":              "youtube#video",
+			"id":                video.ID,
+			"status":            status,
+			"processingDetails": details,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "youtube#videoListResponse", "items": items})
+}
@@ -0,0 +1,245 @@
+// Package httpclient builds the HTTP clients the platform integrations use to talk to
+// platform APIs and to fetch media. Every client gets per-request timeouts, retries of
+// idempotent requests, debug logging with access tokens redacted and metrics hooks.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// debugBodyLimit is how much of a request or response body is included in debug logs
+const debugBodyLimit = 2048
+
+// defaultRetryBackoff is the wait before the first retry; it doubles with every attempt
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// debug turns on request/response logging for every client
+var debug atomic.Bool
+
+// SetDebug turns request/response debug logging on or off
+func SetDebug(enabled bool) {
+	debug.Store(enabled)
+}
+
+// Options configures a client
+type Options struct {
+	Name         string            // Integration name used in logs and metrics, e.g. tiktok
+	Timeout      time.Duration     // Deadline of each attempt, including reading the response body; 0 for none
+	MaxRetries   int               // Retries of idempotent requests after network errors and 502, 503 or 504 responses
+	RetryBackoff time.Duration     // Wait before the first retry, doubling with every attempt; defaults to 500ms
+	Transport    http.RoundTripper // Underlying transport; defaults to http.DefaultTransport
+}
+
+// New creates a client with the given options
+// Timeouts apply per attempt, so the client itself has no overall timeout; callers bound
+// the total time with their context
+func New(opts Options) *http.Client {
+	base := opts.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	return &http.Client{
+		Transport: &transport{base: base, opts: opts},
+	}
+}
+
+type idempotentKey struct{}
+
+// MarkIdempotent marks a request that is safe to retry even though its method is not
+// idempotent, e.g. a POST that only reads the status of an upload
+func MarkIdempotent(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), idempotentKey{}, true))
+}
+
+// transport adds timeouts, retries, logging and metrics to a base transport
+type transport struct {
+	base http.RoundTripper
+	opts Options
+}
+
+// RoundTrip sends a request, retrying it if it is idempotent and failed in a way that may
+// go away
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := isIdempotent(req) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.send(attemptReq, attempt)
+		if !retryable || attempt >= t.opts.MaxRetries || !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		backoff := t.opts.RetryBackoff << attempt
+		log.Printf("[%s] %s %s failed (%s), retrying in %s", t.opts.Name, req.Method, RedactURL(req.URL), describe(resp, err), backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt of a request under the per-attempt timeout
+func (t *transport) send(req *http.Request, attempt int) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if t.opts.Timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), t.opts.Timeout)
+		req = req.WithContext(ctx)
+	}
+
+	if debug.Load() {
+		logRequest(t.opts.Name, req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+
+	info := RequestInfo{
+		Client:   t.opts.Name,
+		Method:   req.Method,
+		Host:     req.URL.Host,
+		Path:     redactPath(req.URL.Path),
+		Attempt:  attempt,
+		Duration: duration,
+		Err:      err,
+	}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	notify(info)
+
+	if err != nil {
+		cancel()
+		if debug.Load() {
+			log.Printf("[%s] %s %s failed after %s: %v", t.opts.Name, req.Method, RedactURL(req.URL), duration.Round(time.Millisecond), err)
+		}
+		return nil, err
+	}
+
+	if debug.Load() {
+		logResponse(t.opts.Name, req, resp, duration)
+	}
+
+	// The timeout also covers reading the body, so the context lives until the body is closed
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// isIdempotent reports whether a request may be sent more than once
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// shouldRetry reports whether a failed attempt may succeed if it is made again
+// Rate limit responses are not retried here: the caller decides when to try again
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// Per-attempt timeouts are worth retrying; a cancelled request is not
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// describe summarises the outcome of an attempt for logs
+func describe(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// cancelOnClose releases the per-attempt context once the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// logRequest logs a request with its redacted URL, headers and the start of its body
+func logRequest(name string, req *http.Request) {
+	var body string
+	if req.GetBody != nil && isTextual(req.Header.Get("Content-Type")) {
+		if copy, err := req.GetBody(); err == nil {
+			body = readPrefix(copy)
+			copy.Close()
+		}
+	}
+	log.Printf("[%s] --> %s %s headers=%s body=%s", name, req.Method, RedactURL(req.URL), RedactHeaders(req.Header), Redact(body))
+}
+
+// logResponse logs a response with the start of its body, which stays readable by the caller
+func logResponse(name string, req *http.Request, resp *http.Response, duration time.Duration) {
+	var body string
+	if isTextual(resp.Header.Get("Content-Type")) {
+		prefix := make([]byte, debugBodyLimit)
+		n, _ := io.ReadFull(resp.Body, prefix)
+		prefix = prefix[:n]
+		body = string(prefix)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+	}
+	log.Printf("[%s] <-- %d %s %s (%s) body=%s", name, resp.StatusCode, req.Method, RedactURL(req.URL), duration.Round(time.Millisecond), Redact(body))
+}
+
+// readPrefix reads up to debugBodyLimit bytes of r
+func readPrefix(r io.Reader) string {
+	prefix, _ := io.ReadAll(io.LimitReader(r, debugBodyLimit))
+	return string(prefix)
+}
+
+// isTextual reports whether a content type is worth logging
+func isTextual(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "x-www-form-urlencoded") ||
+		strings.HasPrefix(contentType, "text/")
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes one attempt of a request, passed to metrics hooks
+type RequestInfo struct {
+	Client     string // Name of the client that made the request
+	Method     string
+	Host       string
+	Path       string // Never includes the query or credentials in the path
+	StatusCode int    // 0 if no response was received
+	Attempt    int    // 0 for the first attempt, 1 for the first retry and so on
+	Duration   time.Duration
+	Err        error
+}
+
+// MetricsHook is called after every attempt of every request
+// Hooks run on the request's goroutine, so they must be fast and safe for concurrent use
+type MetricsHook func(info RequestInfo)
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []MetricsHook
+)
+
+// AddMetricsHook registers a hook that is called after every attempt of every request
+func AddMetricsHook(hook MetricsHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+// notify counts a finished attempt and passes it to the registered hooks
+func notify(info RequestInfo) {
+	counters.record(info)
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook(info)
+	}
+}
+
+// ClientStats are the request counters of one client
+type ClientStats struct {
+	Client        string  `json:"client"`
+	Requests      int64   `json:"requests"`        // Attempts, retries included
+	Retries       int64   `json:"retries"`         // Attempts after the first
+	Errors        int64   `json:"errors"`          // Attempts that got no response
+	ServerErrors  int64   `json:"server_errors"`   // 5xx responses
+	ClientErrors  int64   `json:"client_errors"`   // 4xx responses
+	AvgDurationMs float64 `json:"avg_duration_ms"` // Average time to the response headers
+	totalDuration time.Duration
+}
+
+// Metrics returns the request counters of every client since the process started, sorted by client name
+func Metrics() []ClientStats {
+	return counters.snapshot()
+}
+
+// counters counts the requests of every client
+var counters = &stats{clients: make(map[string]*ClientStats)}
+
+// stats counts requests per client
+type stats struct {
+	mu      sync.Mutex
+	clients map[string]*ClientStats
+}
+
+// record counts one attempt
+func (s *stats) record(info RequestInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, ok := s.clients[info.Client]
+	if !ok {
+		client = &ClientStats{Client: info.Client}
+		s.clients[info.Client] = client
+	}
+	client.Requests++
+	client.totalDuration += info.Duration
+	if info.Attempt > 0 {
+		client.Retries++
+	}
+	switch {
+	case info.Err != nil:
+		client.Errors++
+	case info.StatusCode >= 500:
+		client.ServerErrors++
+	case info.StatusCode >= 400:
+		client.ClientErrors++
+	}
+}
+
+// snapshot returns the counters of every client, sorted by client name
+func (s *stats) snapshot() []ClientStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make([]ClientStats, 0, len(s.clients))
+	for _, client := range s.clients {
+		entry := *client
+		if entry.Requests > 0 {
+			entry.AvgDurationMs = float64(entry.totalDuration) / float64(time.Millisecond) / float64(entry.Requests)
+		}
+		snapshot = append(snapshot, entry)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Client < snapshot[j].Client })
+	return snapshot
+}
@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redacted replaces secrets in logs
+const redacted = "[REDACTED]"
+
+// sensitiveParams are the parameter names whose values never appear in logs
+var sensitiveParams = []string{
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"client_secret",
+	"code",
+	"code_verifier",
+	"upload_url",
+	"input_token",
+}
+
+var (
+	// "access_token": "..." in JSON bodies
+	jsonSecretPattern = regexp.MustCompile(`("(?:` + strings.Join(sensitiveParams, "|") + `)"\s*:\s*)"[^"]*"`)
+	// access_token=... in query strings and form bodies
+	formSecretPattern = regexp.MustCompile(`((?:^|[?&\s])(?:` + strings.Join(sensitiveParams, "|") + `)=)[^&\s"]*`)
+	// Bearer and Basic credentials in headers or messages
+	authSchemePattern = regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// secretPathPatterns match the credentials some platforms put in the path of their URLs: Telegram
+// bot tokens, and the tokens of Discord and Slack incoming webhooks
+var secretPathPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(/bot)\d+:[A-Za-z0-9_-]+`),
+	regexp.MustCompile(`(/webhooks/\d+/)[A-Za-z0-9_.-]+`),
+	regexp.MustCompile(`(/services/T[A-Z0-9]+/B[A-Z0-9]+/)[A-Za-z0-9]+`),
+}
+
+// sensitiveHeaders are the headers whose values never appear in logs
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// Redact replaces access tokens, client secrets and other credentials in s
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = jsonSecretPattern.ReplaceAllString(s, `$1"`+redacted+`"`)
+	s = formSecretPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = authSchemePattern.ReplaceAllString(s, "$1 "+redacted)
+	return s
+}
+
+// RedactURL returns u as a string with the credentials in its query and path replaced
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redactedURL := *u
+	if redactedURL.User != nil {
+		redactedURL.User = url.User(redactedURL.User.Username())
+	}
+	if redactedURL.RawQuery != "" {
+		query := redactedURL.Query()
+		for _, param := range sensitiveParams {
+			if query.Has(param) {
+				query.Set(param, redacted)
+			}
+		}
+		redactedURL.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+	}
+	return redactPath(redactedURL.String())
+}
+
+// redactPath replaces the credentials in a URL path, or in a URL
+func redactPath(path string) string {
+	for _, pattern := range secretPathPatterns {
+		path = pattern.ReplaceAllString(path, "${1}"+redacted)
+	}
+	return path
+}
+
+// RedactError returns err with the credentials in the URL of a failed request replaced
+// The errors of http.Client include the request URL, which is where some platforms put their
+// credentials; other errors are returned as they are
+func RedactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return &url.Error{Op: urlErr.Op, URL: redacted, Err: urlErr.Err}
+	}
+	return &url.Error{Op: urlErr.Op, URL: RedactURL(u), Err: urlErr.Err}
+}
+
+// RedactHeaders formats headers for logs with the credentials replaced
+func RedactHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value := strings.Join(header[name], ",")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		b.WriteString(name + ": " + value)
+	}
+	b.WriteString("}")
+	return b.String()
+}
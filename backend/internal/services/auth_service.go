@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
@@ -40,7 +41,7 @@ func (s *AuthService) GenerateAuthURL() (string, string, error) {
 	}
 
 	// Build OAuth URL
-	baseURL := "https://www.tiktok.com/v2/auth/authorize/"
+	baseURL := s.config.TikTok.AuthBaseURL + TikTokAuthorizePath
 	params := url.Values{}
 	params.Add("client_key", s.config.TikTok.ClientKey)
 	params.Add("scope", strings.Join(s.config.TikTok.Scopes, ","))
@@ -53,9 +54,9 @@ func (s *AuthService) GenerateAuthURL() (string, string, error) {
 }
 
 // HandleCallback processes the OAuth callback and creates a user session
-func (s *AuthService) HandleCallback(code, state string) (string, *models.User, error) {
+func (s *AuthService) HandleCallback(ctx context.Context, code, state string) (string, *models.User, error) {
 	// Exchange code for tokens
-	tokenResponse, err := s.tiktokService.ExchangeCodeForTokens(code)
+	tokenResponse, err := s.tiktokService.ExchangeCodeForTokens(ctx, code)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to exchange code for tokens: %w", err)
 	}
@@ -70,7 +71,7 @@ func (s *AuthService) HandleCallback(code, state string) (string, *models.User,
 	log.Printf("DEBUG: Expires in: %d seconds\n", tokenResponse.ExpiresIn)
 
 	// Get user info from TikTok
-	userInfo, err := s.tiktokService.GetUserInfo(tokenResponse.AccessToken)
+	userInfo, err := s.tiktokService.GetUserInfo(ctx, tokenResponse.AccessToken)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// blueskyAPI sends XRPC requests to the PDS of an account
+// https://atproto.com/specs/xrpc
+type blueskyAPI struct {
+	httpClient *http.Client
+}
+
+// newRequest creates a request for the XRPC method nsid on the PDS at pdsURL
+// accessToken may be empty for the methods that don't need one, like createSession
+func (a *blueskyAPI) newRequest(ctx context.Context, method, pdsURL, nsid, accessToken string, body io.Reader, contentType string) (*http.Request, error) {
+	if pdsURL == "" {
+		return nil, fmt.Errorf("no Bluesky PDS given")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, pdsURL+"/xrpc/"+nsid, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends a request and decodes a JSON response into out
+func (a *blueskyAPI) do(req *http.Request, out interface{}) (http.Header, error) {
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.Header, blueskyError(resp, body)
+	}
+
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return resp.Header, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// query calls an XRPC query (GET) with the given parameters
+func (a *blueskyAPI) query(ctx context.Context, pdsURL, nsid, accessToken string, params url.Values, out interface{}) (http.Header, error) {
+	if len(params) > 0 {
+		nsid += "?" + params.Encode()
+	}
+	req, err := a.newRequest(ctx, "GET", pdsURL, nsid, accessToken, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return a.do(req, out)
+}
+
+// procedure calls an XRPC procedure (POST) with an optional JSON input
+func (a *blueskyAPI) procedure(ctx context.Context, pdsURL, nsid, accessToken string, input, out interface{}) (http.Header, error) {
+	var body io.Reader
+	contentType := ""
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := a.newRequest(ctx, "POST", pdsURL, nsid, accessToken, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return a.do(req, out)
+}
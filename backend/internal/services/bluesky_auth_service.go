@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// blueskyDefaultAccessLifetime is assumed for access tokens whose expiry can't be read
+const blueskyDefaultAccessLifetime = 2 * time.Hour
+
+// BlueskyAuthService creates and refreshes sessions on Bluesky PDSes with app passwords
+// https://docs.bsky.app/docs/api/com-atproto-server-create-session
+type BlueskyAuthService struct {
+	pdsURL       string // Server accounts log in on; their own PDS may be another one
+	allowPrivate bool   // Allow PDSes on private networks and plain http
+	api          *blueskyAPI
+}
+
+// NewBlueskyAuthService creates a new Bluesky auth service
+func NewBlueskyAuthService(pdsURL string, allowPrivatePDS bool) *BlueskyAuthService {
+	return &BlueskyAuthService{
+		pdsURL:       strings.TrimSuffix(pdsURL, "/"),
+		allowPrivate: allowPrivatePDS,
+		api:          &blueskyAPI{httpClient: newHTTPClient("bluesky", 30*time.Second)},
+	}
+}
+
+// BlueskySession is a logged-in session of an account
+type BlueskySession struct {
+	AccessJwt  string         `json:"accessJwt"`
+	RefreshJwt string         `json:"refreshJwt"`
+	Handle     string         `json:"handle"`
+	DID        string         `json:"did"`
+	DIDDoc     *blueskyDIDDoc `json:"didDoc,omitempty"`
+	PDSURL     string         `json:"-"` // PDS the account's repository lives on
+}
+
+// blueskyDIDDoc is the part of a DID document that names the account's PDS
+type blueskyDIDDoc struct {
+	Service []struct {
+		ID              string `json:"id"`
+		Type            string `json:"type"`
+		ServiceEndpoint string `json:"serviceEndpoint"`
+	} `json:"service"`
+}
+
+// BlueskyProfile is the profile returned by app.bsky.actor.getProfile
+type BlueskyProfile struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"displayName"`
+	Avatar      string `json:"avatar"`
+}
+
+// PDSURL returns the server accounts log in on
+func (s *BlueskyAuthService) PDSURL() string {
+	return s.pdsURL
+}
+
+// CreateSession logs in with a handle or email and an app password
+// Returns an error wrapping platformapi.ErrInvalidCredentials if they are wrong
+func (s *BlueskyAuthService) CreateSession(ctx context.Context, identifier, password string) (*BlueskySession, error) {
+	identifier = strings.TrimPrefix(strings.TrimSpace(identifier), "@")
+	if identifier == "" || password == "" {
+		return nil, fmt.Errorf("%w: a handle and an app password are required", platformapi.ErrInvalidCredentials)
+	}
+
+	var session BlueskySession
+	_, err := s.api.procedure(ctx, s.pdsURL, "com.atproto.server.createSession", "", map[string]string{
+		"identifier": identifier,
+		"password":   password,
+	}, &session)
+	if err != nil {
+		var platformErr *platformapi.PlatformError
+		if errors.As(err, &platformErr) && platformErr.PlatformCode == "AuthenticationRequired" {
+			return nil, fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+		}
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.completeSession(&session, s.pdsURL)
+}
+
+// RefreshSession exchanges the refresh token of a session on pdsURL for a new session
+// Refresh tokens are single-use: the returned session carries the next one
+func (s *BlueskyAuthService) RefreshSession(ctx context.Context, pdsURL, refreshJwt string) (*BlueskySession, error) {
+	if pdsURL == "" {
+		pdsURL = s.pdsURL
+	}
+
+	var session BlueskySession
+	if _, err := s.api.procedure(ctx, pdsURL, "com.atproto.server.refreshSession", refreshJwt, nil, &session); err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	return s.completeSession(&session, pdsURL)
+}
+
+// completeSession checks a session created on loggedInOn and finds the account's PDS
+// Accounts that logged in on an entryway like bsky.social are served by another PDS, named
+// in their DID document
+func (s *BlueskyAuthService) completeSession(session *BlueskySession, loggedInOn string) (*BlueskySession, error) {
+	if session.AccessJwt == "" || session.DID == "" {
+		return nil, fmt.Errorf("session response has no access token or DID")
+	}
+
+	session.PDSURL = loggedInOn
+	if session.DIDDoc != nil {
+		for _, service := range session.DIDDoc.Service {
+			if service.ID == "#atproto_pds" || service.Type == "AtprotoPersonalDataServer" {
+				session.PDSURL = strings.TrimSuffix(service.ServiceEndpoint, "/")
+				break
+			}
+		}
+	}
+
+	if session.PDSURL != s.pdsURL {
+		if err := s.checkPDS(session.PDSURL); err != nil {
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
+// checkPDS checks that a PDS named in a DID document may be contacted
+// DID documents are controlled by their accounts, so their PDS gets the same checks as media URLs
+func (s *BlueskyAuthService) checkPDS(pdsURL string) error {
+	parsed, err := url.Parse(pdsURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid PDS URL %q", pdsURL)
+	}
+	if s.allowPrivate {
+		return nil
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("PDS %s is not served over https", pdsURL)
+	}
+	if err := ValidateMediaURL(pdsURL); err != nil {
+		return fmt.Errorf("PDS %s may not be contacted: %w", pdsURL, err)
+	}
+	return nil
+}
+
+// GetProfile returns the profile of actor, a handle or DID
+func (s *BlueskyAuthService) GetProfile(ctx context.Context, pdsURL, accessJwt, actor string) (*BlueskyProfile, error) {
+	var profile BlueskyProfile
+	if _, err := s.api.query(ctx, pdsURL, "app.bsky.actor.getProfile", accessJwt, url.Values{"actor": {actor}}, &profile); err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile.DID == "" {
+		return nil, fmt.Errorf("profile response has no DID")
+	}
+	return &profile, nil
+}
+
+// GetSession returns the session an access token belongs to
+func (s *BlueskyAuthService) GetSession(ctx context.Context, pdsURL, accessJwt string) (*BlueskySession, error) {
+	var session BlueskySession
+	if _, err := s.api.query(ctx, pdsURL, "com.atproto.server.getSession", accessJwt, nil, &session); err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.DID == "" {
+		return nil, fmt.Errorf("session response has no DID")
+	}
+	session.PDSURL = pdsURL
+	return &session, nil
+}
+
+// BlueskyTokenLifetime returns the seconds until an access token expires, read from the exp
+// claim of the JWT
+func BlueskyTokenLifetime(accessJwt string) int {
+	lifetime := blueskyDefaultAccessLifetime
+	if expiresAt, ok := jwtExpiry(accessJwt); ok {
+		lifetime = time.Until(expiresAt)
+	}
+	if lifetime < 0 {
+		lifetime = 0
+	}
+	return int(lifetime / time.Second)
+}
+
+// jwtExpiry reads the exp claim of a JWT without verifying it
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
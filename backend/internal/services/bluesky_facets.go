@@ -0,0 +1,128 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Facet feature types
+// https://docs.bsky.app/docs/advanced-guides/post-richtext
+const (
+	BlueskyFacetMention = "app.bsky.richtext.facet#mention"
+	BlueskyFacetLink    = "app.bsky.richtext.facet#link"
+	BlueskyFacetTag     = "app.bsky.richtext.facet#tag"
+)
+
+// blueskyMaxTagLength is the longest hashtag Bluesky accepts, in characters without the #
+const blueskyMaxTagLength = 64
+
+// Mentions, links and hashtags start a post or follow whitespace; mentions and links may
+// also follow an opening parenthesis. The first group is the facet itself
+var (
+	blueskyMentionPattern = regexp.MustCompile(`(?:^|[\s(])(@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+)`)
+	blueskyLinkPattern    = regexp.MustCompile(`(?:^|[\s(])(https?://[^\s]+)`)
+	blueskyTagPattern     = regexp.MustCompile(`(?:^|\s)([#＃][^\s]+)`)
+)
+
+// BlueskyFacet annotates a range of a post's text, such as a mention or a link
+// Ranges are byte offsets into the UTF-8 encoded text, not character offsets
+type BlueskyFacet struct {
+	Index    BlueskyByteSlice      `json:"index"`
+	Features []BlueskyFacetFeature `json:"features"`
+}
+
+// BlueskyByteSlice is the range [ByteStart, ByteEnd) of the UTF-8 encoded text
+type BlueskyByteSlice struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+// BlueskyFacetFeature is what a facet's range is: a mention of DID, a link to URI or the hashtag Tag
+type BlueskyFacetFeature struct {
+	Type string `json:"$type"`
+	DID  string `json:"did,omitempty"`
+	URI  string `json:"uri,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// DetectBlueskyFacets finds the mentions, links and hashtags in text
+// resolveHandle returns the DID of a mentioned handle; mentions of handles it can't resolve
+// are left as plain text, like the Bluesky apps do
+func DetectBlueskyFacets(text string, resolveHandle func(handle string) (string, bool)) []BlueskyFacet {
+	var facets []BlueskyFacet
+
+	for _, match := range blueskyMentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		handle := strings.ToLower(text[start+1 : end])
+		did, ok := resolveHandle(handle)
+		if !ok {
+			continue
+		}
+		facets = append(facets, BlueskyFacet{
+			Index:    BlueskyByteSlice{ByteStart: start, ByteEnd: end},
+			Features: []BlueskyFacetFeature{{Type: BlueskyFacetMention, DID: did}},
+		})
+	}
+
+	for _, match := range blueskyLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], trimLinkEnd(text, match[2], match[3])
+		facets = append(facets, BlueskyFacet{
+			Index:    BlueskyByteSlice{ByteStart: start, ByteEnd: end},
+			Features: []BlueskyFacetFeature{{Type: BlueskyFacetLink, URI: text[start:end]}},
+		})
+	}
+
+	for _, match := range blueskyTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		// Trailing punctuation ends a hashtag, like the full stop in "#golang."
+		for end > start {
+			r, size := utf8.DecodeLastRuneInString(text[start:end])
+			if !unicode.IsPunct(r) {
+				break
+			}
+			end -= size
+		}
+		_, hashSize := utf8.DecodeRuneInString(text[start:])
+		tag := text[start+hashSize : end]
+		if tag == "" || isDigits(tag) || utf8.RuneCountInString(tag) > blueskyMaxTagLength {
+			continue
+		}
+		facets = append(facets, BlueskyFacet{
+			Index:    BlueskyByteSlice{ByteStart: start, ByteEnd: end},
+			Features: []BlueskyFacetFeature{{Type: BlueskyFacetTag, Tag: tag}},
+		})
+	}
+
+	return facets
+}
+
+// trimLinkEnd drops the punctuation that ends a sentence from the end of a link, and a
+// closing parenthesis that isn't part of it, like in "(see https://example.com)"
+func trimLinkEnd(text string, start, end int) int {
+	for end > start {
+		switch text[end-1] {
+		case '.', ',', ';', ':', '!', '?', '"', '\'':
+			end--
+			continue
+		case ')':
+			if strings.Count(text[start:end], "(") < strings.Count(text[start:end], ")") {
+				end--
+				continue
+			}
+		}
+		break
+	}
+	return end
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// blueskyMIMETypes are the media types Bluesky accepts for images and videos
+var blueskyMIMETypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"image/gif":       true,
+	"video/mp4":       true,
+	"video/quicktime": true,
+	"video/webm":      true,
+}
+
+// BlueskyMediaService uploads images and videos to the PDS of an account as blobs
+// https://docs.bsky.app/docs/api/com-atproto-repo-upload-blob
+type BlueskyMediaService struct {
+	uploadAPI      *blueskyAPI
+	downloadClient *http.Client
+}
+
+// NewBlueskyMediaService creates a new Bluesky media service
+func NewBlueskyMediaService() *BlueskyMediaService {
+	return &BlueskyMediaService{
+		uploadAPI:      &blueskyAPI{httpClient: newHTTPClient("bluesky", 5*time.Minute)},
+		downloadClient: newHTTPClient("bluesky-media-download", 5*time.Minute),
+	}
+}
+
+// BlueskyBlob is a reference to an uploaded blob, embedded as is into records
+type BlueskyBlob struct {
+	Type string `json:"$type"`
+	Ref  struct {
+		Link string `json:"$link"` // CID of the blob
+	} `json:"ref"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+}
+
+// BlueskyAspectRatio is the width and height of an image or video, used to lay it out
+// before it has loaded
+type BlueskyAspectRatio struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// BlueskyUploadedMedia is an uploaded image or video
+type BlueskyUploadedMedia struct {
+	Blob        BlueskyBlob
+	Kind        MediaType
+	AspectRatio *BlueskyAspectRatio // nil if the dimensions couldn't be read
+}
+
+// Upload downloads the media at mediaURL and uploads it to the PDS as a blob
+// The blob is only kept by the PDS if a record references it soon after
+func (s *BlueskyMediaService) Upload(ctx context.Context, pdsURL, accessJwt, mediaURL string) (*BlueskyUploadedMedia, error) {
+	file, mimeType, size, err := s.download(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	uploaded := &BlueskyUploadedMedia{Kind: MediaTypeFromMIME(mimeType)}
+	if probe, err := probeReaderAt(file, size); err == nil && probe.Width > 0 && probe.Height > 0 {
+		uploaded.AspectRatio = &BlueskyAspectRatio{Width: probe.Width, Height: probe.Height}
+	}
+
+	req, err := s.uploadAPI.newRequest(ctx, "POST", pdsURL, "com.atproto.repo.uploadBlob", accessJwt, io.NewSectionReader(file, 0, size), mimeType)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+
+	var resp struct {
+		Blob BlueskyBlob `json:"blob"`
+	}
+	header, err := s.uploadAPI.do(req, &resp)
+	if header != nil {
+		observeBlueskyRateLimit(ctx, "upload_blob", header)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload blob: %w", err)
+	}
+	if resp.Blob.Ref.Link == "" {
+		return nil, fmt.Errorf("blob response has no CID")
+	}
+
+	uploaded.Blob = resp.Blob
+	log.Printf("Bluesky %s blob uploaded: %s (%d bytes)", uploaded.Kind, resp.Blob.Ref.Link, resp.Blob.Size)
+	return uploaded, nil
+}
+
+// download saves the media at mediaURL to a temp file and detects its type
+// The caller closes and removes the file
+func (s *BlueskyMediaService) download(ctx context.Context, mediaURL string) (*os.File, string, int64, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", 0, platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download media: status %d", resp.StatusCode))
+	}
+
+	file, err := os.CreateTemp("", "bluesky-media-*.tmp")
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	fail := func(err error) (*os.File, string, int64, error) {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, "", 0, err
+	}
+
+	size, err := io.Copy(file, resp.Body)
+	if err != nil {
+		return fail(fmt.Errorf("failed to save media: %w", err))
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := file.ReadAt(header, 0)
+
+	// Trust the bytes first, then the Content-Type header
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	if !blueskyMIMETypes[mimeType] {
+		return fail(platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported media type %q", mimeType)))
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformBluesky, MediaTypeFromMIME(mimeType)); limit > 0 && size > limit {
+		return fail(platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("media is %.1f MB, Bluesky allows at most %.1f MB", megabytes(size), megabytes(limit))))
+	}
+
+	return file, mimeType, size, nil
+}
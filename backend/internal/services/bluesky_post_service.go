@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Record and embed types of Bluesky posts
+const (
+	blueskyPostCollection = "app.bsky.feed.post"
+	blueskyEmbedImages    = "app.bsky.embed.images"
+	blueskyEmbedVideo     = "app.bsky.embed.video"
+)
+
+// blueskyMaxImages is the most images a post can embed
+const blueskyMaxImages = 4
+
+// blueskyMaxLanguages is the most languages a post can be tagged with
+const blueskyMaxLanguages = 3
+
+// BlueskyPostService publishes posts to the repository of an account on its PDS
+// https://docs.bsky.app/docs/advanced-guides/posts
+type BlueskyPostService struct {
+	mediaService *BlueskyMediaService
+	api          *blueskyAPI
+}
+
+// NewBlueskyPostService creates a new Bluesky post service
+func NewBlueskyPostService(mediaService *BlueskyMediaService) *BlueskyPostService {
+	return &BlueskyPostService{
+		mediaService: mediaService,
+		api:          &blueskyAPI{httpClient: newHTTPClient("bluesky", 30*time.Second)},
+	}
+}
+
+// BlueskyPostRequest is the content of a post
+type BlueskyPostRequest struct {
+	Text      string
+	MediaURLs []string // Up to 4 images, or a single video
+	AltText   string   // Alt text applied to every media item
+	Languages []string // Language codes of the text
+	ReplyTo   string   // Post to reply to, as an at:// URI or a bsky.app post URL
+}
+
+// BlueskyStrongRef references a specific version of a record
+type BlueskyStrongRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+// BlueskyReplyRef is the thread a reply belongs to: the post it answers and the first post of the thread
+type BlueskyReplyRef struct {
+	Root   BlueskyStrongRef `json:"root"`
+	Parent BlueskyStrongRef `json:"parent"`
+}
+
+// BlueskyPost is a published post
+type BlueskyPost struct {
+	URI    string                 `json:"uri"` // at://{did}/app.bsky.feed.post/{rkey}
+	CID    string                 `json:"cid"`
+	Record map[string]interface{} `json:"record,omitempty"`
+}
+
+// BlueskyPostURL returns the bsky.app URL of the post with an at:// URI
+// Returns an empty string if uri is not the URI of a post
+func BlueskyPostURL(uri string) string {
+	repo, rkey, ok := parseBlueskyPostURI(uri)
+	if !ok {
+		return ""
+	}
+	return "https://bsky.app/profile/" + repo + "/post/" + rkey
+}
+
+// parseBlueskyPostURI splits the at:// URI of a post into its repository (a DID or handle) and record key
+func parseBlueskyPostURI(uri string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(uri, "at://")
+	if !ok {
+		return "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] != blueskyPostCollection || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[2], true
+}
+
+// ParseBlueskyPostReference parses a post given as an at:// URI or a bsky.app post URL into
+// its repository (a DID or handle) and record key
+func ParseBlueskyPostReference(ref string) (string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, "at://") {
+		return parseBlueskyPostURI(ref)
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil || parsed.Scheme != "https" || parsed.Host != "bsky.app" {
+		return "", "", false
+	}
+	// /profile/{actor}/post/{rkey}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "profile" || parts[1] == "" || parts[2] != "post" || parts[3] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+// CreatePost uploads the media of a post and publishes it to the account's repository
+// Mentions, links and hashtags in the text are turned into facets
+func (s *BlueskyPostService) CreatePost(ctx context.Context, pdsURL, accessJwt, repo string, req BlueskyPostRequest) (*BlueskyPost, error) {
+	if len(req.MediaURLs) > blueskyMaxImages {
+		return nil, platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("posts can have at most %d images", blueskyMaxImages))
+	}
+	if len(req.Languages) > blueskyMaxLanguages {
+		return nil, fmt.Errorf("posts can have at most %d languages", blueskyMaxLanguages)
+	}
+
+	record := map[string]interface{}{
+		"$type":     blueskyPostCollection,
+		"text":      req.Text,
+		"createdAt": time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+	}
+
+	// Mentioned handles are resolved once per post, however often they are mentioned
+	resolved := make(map[string]string)
+	facets := DetectBlueskyFacets(req.Text, func(handle string) (string, bool) {
+		if did, ok := resolved[handle]; ok {
+			return did, did != ""
+		}
+		did, err := s.ResolveHandle(ctx, pdsURL, accessJwt, handle)
+		if err != nil {
+			log.Printf("Bluesky mention of @%s left as text: %v", handle, err)
+		}
+		resolved[handle] = did
+		return did, did != ""
+	})
+	if len(facets) > 0 {
+		record["facets"] = facets
+	}
+	if len(req.Languages) > 0 {
+		record["langs"] = req.Languages
+	}
+
+	if req.ReplyTo != "" {
+		reply, err := s.replyRef(ctx, pdsURL, accessJwt, req.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		record["reply"] = reply
+	}
+
+	if len(req.MediaURLs) > 0 {
+		embed, err := s.embedMedia(ctx, pdsURL, accessJwt, req.MediaURLs, req.AltText)
+		if err != nil {
+			return nil, err
+		}
+		record["embed"] = embed
+	}
+
+	var post BlueskyPost
+	header, err := s.api.procedure(ctx, pdsURL, "com.atproto.repo.createRecord", accessJwt, map[string]interface{}{
+		"repo":       repo,
+		"collection": blueskyPostCollection,
+		"record":     record,
+	}, &post)
+	if header != nil {
+		observeBlueskyRateLimit(ctx, "create_record", header)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	if post.URI == "" {
+		return nil, fmt.Errorf("post response has no URI")
+	}
+
+	log.Printf("Bluesky post created: %s", post.URI)
+	return &post, nil
+}
+
+// embedMedia uploads the media of a post and returns the embed referencing it
+// A post embeds either up to 4 images or a single video
+func (s *BlueskyPostService) embedMedia(ctx context.Context, pdsURL, accessJwt string, mediaURLs []string, altText string) (map[string]interface{}, error) {
+	images := make([]map[string]interface{}, 0, len(mediaURLs))
+	for i, mediaURL := range mediaURLs {
+		media, err := s.mediaService.Upload(ctx, pdsURL, accessJwt, mediaURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload media %d: %w", i+1, err)
+		}
+
+		if media.Kind == MediaTypeVideo {
+			if len(mediaURLs) > 1 {
+				return nil, platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeMediaRejected, 0, "",
+					"a video can't be combined with other media in a post")
+			}
+			embed := map[string]interface{}{
+				"$type": blueskyEmbedVideo,
+				"video": media.Blob,
+			}
+			if altText != "" {
+				embed["alt"] = altText
+			}
+			if media.AspectRatio != nil {
+				embed["aspectRatio"] = media.AspectRatio
+			}
+			return embed, nil
+		}
+
+		// Alt text is required in image embeds, but may be empty
+		image := map[string]interface{}{
+			"image": media.Blob,
+			"alt":   altText,
+		}
+		if media.AspectRatio != nil {
+			image["aspectRatio"] = media.AspectRatio
+		}
+		images = append(images, image)
+	}
+
+	return map[string]interface{}{
+		"$type":  blueskyEmbedImages,
+		"images": images,
+	}, nil
+}
+
+// replyRef looks up the post to reply to and the root of its thread
+func (s *BlueskyPostService) replyRef(ctx context.Context, pdsURL, accessJwt, replyTo string) (*BlueskyReplyRef, error) {
+	repo, rkey, ok := ParseBlueskyPostReference(replyTo)
+	if !ok {
+		return nil, fmt.Errorf("invalid reply_to %q: must be an at:// URI or a bsky.app post URL", replyTo)
+	}
+
+	// Strong references need the DID; post URLs usually name the author by handle
+	if !strings.HasPrefix(repo, "did:") {
+		did, err := s.ResolveHandle(ctx, pdsURL, accessJwt, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve author of reply_to: %w", err)
+		}
+		repo = did
+	}
+
+	parent, err := s.GetPost(ctx, pdsURL, accessJwt, "at://"+repo+"/"+blueskyPostCollection+"/"+rkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post to reply to: %w", err)
+	}
+
+	ref := &BlueskyReplyRef{
+		Root:   BlueskyStrongRef{URI: parent.URI, CID: parent.CID},
+		Parent: BlueskyStrongRef{URI: parent.URI, CID: parent.CID},
+	}
+	// A reply to a reply belongs to the thread of its parent
+	if parentReply, ok := parent.Record["reply"].(map[string]interface{}); ok {
+		if root, ok := parentReply["root"].(map[string]interface{}); ok {
+			uri, _ := root["uri"].(string)
+			cid, _ := root["cid"].(string)
+			if uri != "" && cid != "" {
+				ref.Root = BlueskyStrongRef{URI: uri, CID: cid}
+			}
+		}
+	}
+	return ref, nil
+}
+
+// GetPost retrieves the post with an at:// URI through the account's PDS
+func (s *BlueskyPostService) GetPost(ctx context.Context, pdsURL, accessJwt, uri string) (*BlueskyPost, error) {
+	var resp struct {
+		Posts []BlueskyPost `json:"posts"`
+	}
+	if _, err := s.api.query(ctx, pdsURL, "app.bsky.feed.getPosts", accessJwt, url.Values{"uris": {uri}}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if len(resp.Posts) == 0 || resp.Posts[0].CID == "" {
+		return nil, platformapi.NewPlatformError(models.PlatformBluesky, platformapi.ErrorCodeUnknown, 0, "NotFound",
+			fmt.Sprintf("post %s not found", uri))
+	}
+	return &resp.Posts[0], nil
+}
+
+// ResolveHandle returns the DID of a handle
+func (s *BlueskyPostService) ResolveHandle(ctx context.Context, pdsURL, accessJwt, handle string) (string, error) {
+	var resp struct {
+		DID string `json:"did"`
+	}
+	params := url.Values{"handle": {strings.TrimPrefix(handle, "@")}}
+	if _, err := s.api.query(ctx, pdsURL, "com.atproto.identity.resolveHandle", accessJwt, params, &resp); err != nil {
+		return "", fmt.Errorf("failed to resolve handle %s: %w", handle, err)
+	}
+	if resp.DID == "" {
+		return "", fmt.Errorf("handle %s has no DID", handle)
+	}
+	return resp.DID, nil
+}
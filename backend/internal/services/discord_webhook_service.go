@@ -0,0 +1,378 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// discordDefaultWebhookBaseURL is where the webhooks of discord.com live
+const discordDefaultWebhookBaseURL = "https://discord.com/api/webhooks"
+
+// discordWebhookAliases are the other hosts the webhook URLs Discord shows are copied from; they
+// are rewritten to discord.com
+var discordWebhookAliases = []string{
+	"https://discordapp.com/api/webhooks",
+	"https://ptb.discord.com/api/webhooks",
+	"https://canary.discord.com/api/webhooks",
+}
+
+// discordWebhookPathPattern matches the part of a webhook URL after the base URL: its ID and token
+var discordWebhookPathPattern = regexp.MustCompile(`^([0-9]+)/([A-Za-z0-9_.-]+)$`)
+
+// discordFlagSuppressEmbeds keeps a message from showing previews of its links
+// https://discord.com/developers/docs/resources/message#message-object-message-flags
+const discordFlagSuppressEmbeds = 1 << 2
+
+// discordWebhookTypeIncoming is the type of webhooks created in a channel's integrations
+const discordWebhookTypeIncoming = 1
+
+// discordFileExtensions are the file names attachments are uploaded as
+// Discord decides how to show an attachment by its extension
+var discordFileExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"video/mp4":       ".mp4",
+	"video/quicktime": ".mov",
+	"video/webm":      ".webm",
+}
+
+// DiscordWebhookService sends messages with the incoming webhooks of Discord channels
+// The webhook URL, which contains the webhook's token, is the only credential
+// https://discord.com/developers/docs/resources/webhook#execute-webhook
+type DiscordWebhookService struct {
+	baseURL        string
+	httpClient     *http.Client
+	uploadClient   *http.Client // Messages with attachments take longer to send
+	downloadClient *http.Client
+}
+
+// NewDiscordWebhookService creates a new Discord webhook service for the webhooks under webhookBaseURL
+func NewDiscordWebhookService(webhookBaseURL string) *DiscordWebhookService {
+	return &DiscordWebhookService{
+		baseURL:        strings.TrimSuffix(webhookBaseURL, "/"),
+		httpClient:     newHTTPClient("discord", 30*time.Second),
+		uploadClient:   newHTTPClient("discord", 5*time.Minute),
+		downloadClient: newHTTPClient("discord-media-download", 5*time.Minute),
+	}
+}
+
+// DiscordWebhook is a webhook, as returned by GET /webhooks/{id}/{token}
+type DiscordWebhook struct {
+	ID        string `json:"id"`
+	Type      int    `json:"type"`
+	Name      string `json:"name"`
+	Avatar    string `json:"avatar"` // Avatar hash
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+// AvatarURL returns the URL of the webhook's avatar, or "" if it has the default one
+func (w *DiscordWebhook) AvatarURL() string {
+	if w.Avatar == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", w.ID, w.Avatar)
+}
+
+// DiscordMessage is a sent message
+type DiscordMessage struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// DiscordMessageRequest is the content of a message
+type DiscordMessageRequest struct {
+	Content   string
+	MediaURLs []string // Uploaded as attachments
+
+	Username       string // Overrides the webhook's name
+	AvatarURL      string // Overrides the webhook's avatar
+	SuppressEmbeds bool
+}
+
+// NormalizeWebhookURL checks that webhookURL is a webhook under the configured base URL
+// Returns the webhook URL without query or trailing slash and the webhook's ID, or an error
+// wrapping platformapi.ErrInvalidCredentials
+func (s *DiscordWebhookService) NormalizeWebhookURL(webhookURL string) (normalized, webhookID string, err error) {
+	webhookURL = strings.TrimSpace(webhookURL)
+	webhookURL, _, _ = strings.Cut(webhookURL, "?")
+	webhookURL = strings.TrimSuffix(webhookURL, "/")
+
+	if s.baseURL == discordDefaultWebhookBaseURL {
+		for _, alias := range discordWebhookAliases {
+			if strings.HasPrefix(webhookURL, alias+"/") {
+				webhookURL = s.baseURL + strings.TrimPrefix(webhookURL, alias)
+				break
+			}
+		}
+	}
+
+	rest, ok := strings.CutPrefix(webhookURL, s.baseURL+"/")
+	match := discordWebhookPathPattern.FindStringSubmatch(rest)
+	if !ok || match == nil {
+		return "", "", fmt.Errorf("%w: not a Discord webhook URL, like %s/123/abc", platformapi.ErrInvalidCredentials, s.baseURL)
+	}
+	return webhookURL, match[1], nil
+}
+
+// GetWebhook retrieves the webhook of a webhook URL
+// Returns an error wrapping platformapi.ErrInvalidCredentials if the webhook doesn't exist or its
+// token is wrong
+func (s *DiscordWebhookService) GetWebhook(ctx context.Context, webhookURL string) (*DiscordWebhook, error) {
+	var webhook DiscordWebhook
+	status, err := s.do(ctx, s.httpClient, "GET", webhookURL, nil, "", &webhook)
+	if err != nil {
+		if status == http.StatusUnauthorized || status == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if webhook.Type != discordWebhookTypeIncoming {
+		return nil, fmt.Errorf("%w: not an incoming webhook", platformapi.ErrInvalidCredentials)
+	}
+	return &webhook, nil
+}
+
+// Execute sends a message with a webhook and waits for Discord to confirm it
+// Media is downloaded and uploaded as attachments
+func (s *DiscordWebhookService) Execute(ctx context.Context, webhookURL string, req DiscordMessageRequest) (*DiscordMessage, error) {
+	payload := map[string]interface{}{
+		"content": req.Content,
+		// Only the users, roles and @everyone of a message a user wrote should be pinged, not those
+		// of text published to many platforms
+		"allowed_mentions": map[string][]string{"parse": {}},
+	}
+	if req.Username != "" {
+		payload["username"] = req.Username
+	}
+	if req.AvatarURL != "" {
+		payload["avatar_url"] = req.AvatarURL
+	}
+	if req.SuppressEmbeds {
+		payload["flags"] = discordFlagSuppressEmbeds
+	}
+
+	var message DiscordMessage
+	if len(req.MediaURLs) == 0 {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		if _, err := s.do(ctx, s.httpClient, "POST", webhookURL+"?wait=true", bytes.NewReader(data), "application/json", &message); err != nil {
+			return nil, fmt.Errorf("failed to execute webhook: %w", err)
+		}
+	} else {
+		if err := s.executeWithAttachments(ctx, webhookURL, payload, req.MediaURLs, &message); err != nil {
+			return nil, err
+		}
+	}
+	if message.ID == "" {
+		return nil, fmt.Errorf("webhook response has no message ID")
+	}
+
+	log.Printf("Discord message sent to channel %s: %s", message.ChannelID, message.ID)
+	return &message, nil
+}
+
+// discordAttachment is media downloaded to a temp file
+type discordAttachment struct {
+	path     string
+	filename string
+	mimeType string
+}
+
+// executeWithAttachments downloads the media and sends the message as multipart/form-data, with
+// the JSON payload in payload_json and the files in files[n]
+// https://discord.com/developers/docs/reference#uploading-files
+func (s *DiscordWebhookService) executeWithAttachments(ctx context.Context, webhookURL string, payload map[string]interface{}, mediaURLs []string, message *DiscordMessage) error {
+	attachments := make([]discordAttachment, 0, len(mediaURLs))
+	defer func() {
+		for _, attachment := range attachments {
+			os.Remove(attachment.path)
+		}
+	}()
+
+	descriptors := make([]map[string]interface{}, 0, len(mediaURLs))
+	for i, mediaURL := range mediaURLs {
+		attachment, err := s.download(ctx, mediaURL, i)
+		if err != nil {
+			return err
+		}
+		attachments = append(attachments, *attachment)
+		descriptors = append(descriptors, map[string]interface{}{"id": i, "filename": attachment.filename})
+	}
+	payload["attachments"] = descriptors
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// The files are streamed into the multipart body instead of being buffered
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeDiscordMessageForm(form, payloadJSON, attachments))
+	}()
+
+	if _, err := s.do(ctx, s.uploadClient, "POST", webhookURL+"?wait=true", body, form.FormDataContentType(), message); err != nil {
+		body.Close()
+		return fmt.Errorf("failed to execute webhook: %w", err)
+	}
+	return nil
+}
+
+// writeDiscordMessageForm writes the payload and the files of a message and closes the form
+func writeDiscordMessageForm(form *multipart.Writer, payloadJSON []byte, attachments []discordAttachment) error {
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", `form-data; name="payload_json"`)
+	partHeader.Set("Content-Type", "application/json")
+	part, err := form.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(payloadJSON); err != nil {
+		return err
+	}
+
+	for i, attachment := range attachments {
+		if err := writeDiscordFile(form, i, attachment); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+// writeDiscordFile writes the files[i] part of an attachment
+func writeDiscordFile(form *multipart.Writer, i int, attachment discordAttachment) error {
+	file, err := os.Open(attachment.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename="%s"`, i, attachment.filename))
+	partHeader.Set("Content-Type", attachment.mimeType)
+	part, err := form.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	return err
+}
+
+// download saves the media at mediaURL to a temp file and checks its type and size
+func (s *DiscordWebhookService) download(ctx context.Context, mediaURL string, index int) (*discordAttachment, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, platformapi.NewPlatformError(models.PlatformDiscord, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download media: status %d", resp.StatusCode))
+	}
+
+	out, err := os.CreateTemp("", "discord-media-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("failed to save media: %w", err)
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := out.ReadAt(header, 0)
+
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	extension, ok := discordFileExtensions[mimeType]
+	if !ok {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformDiscord, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported media type %q", mimeType))
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformDiscord, MediaTypeFromMIME(mimeType)); limit > 0 && size > limit {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformDiscord, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("media is %.1f MB, Discord allows at most %.1f MB", megabytes(size), megabytes(limit)))
+	}
+
+	return &discordAttachment{
+		path:     out.Name(),
+		filename: fmt.Sprintf("media%d%s", index+1, extension),
+		mimeType: mimeType,
+	}, nil
+}
+
+// DeleteMessage deletes a message the webhook sent
+func (s *DiscordWebhookService) DeleteMessage(ctx context.Context, webhookURL, messageID string) error {
+	if _, err := s.do(ctx, s.httpClient, "DELETE", webhookURL+"/messages/"+messageID, nil, "", nil); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	return nil
+}
+
+// do sends a request to a webhook and decodes the response into out
+// Returns the status of the response, 0 if there was none
+func (s *DiscordWebhookService) do(ctx context.Context, client *http.Client, method, requestURL string, body io.Reader, contentType string, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		// The error would include the webhook's token
+		return 0, fmt.Errorf("failed to create webhook request")
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook request: %w", httpclient.RedactError(err))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, discordError(resp, respBody)
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// DiscordMessageURL returns the link of a message in a server's channel
+func DiscordMessageURL(guildID, channelID, messageID string) string {
+	if guildID == "" || channelID == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
@@ -0,0 +1,15 @@
+package services
+
+import "time"
+
+func init() {
+	// Poll the fake platforms quickly instead of waiting as long as the real ones need
+	statusPollInterval = 20 * time.Millisecond
+	instagramStatusCheckInterval = 20 * time.Millisecond
+	linkedinStatusCheckInterval = 20 * time.Millisecond
+	mastodonMediaCheckInterval = 20 * time.Millisecond
+	pinterestMediaCheckInterval = 20 * time.Millisecond
+	redditSubmitCheckInterval = 20 * time.Millisecond
+	outageRecheckInterval = 20 * time.Millisecond
+	httpRetryBackoff = time.Millisecond
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// facebookAPI sends requests to the versioned Facebook Graph API (/{version}/...)
+// The access token is sent as a parameter, like the other Graph API clients do
+type facebookAPI struct {
+	graphBaseURL string // e.g. https://graph.facebook.com
+	version      string // e.g. v23.0
+	httpClient   *http.Client
+}
+
+// get sends a GET request with the given query parameters and decodes the response into out
+func (a *facebookAPI) get(ctx context.Context, path, accessToken string, params url.Values, out interface{}) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("access_token", accessToken)
+
+	resp, err := getWithContext(ctx, a.httpClient, a.url(path)+"?"+query.Encode())
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return a.decode(resp, out)
+}
+
+// post sends a POST request with the given form values and decodes the response into out
+func (a *facebookAPI) post(ctx context.Context, path, accessToken string, form url.Values, out interface{}) error {
+	data := url.Values{}
+	for key, values := range form {
+		data[key] = values
+	}
+	data.Set("access_token", accessToken)
+
+	resp, err := postFormWithContext(ctx, a.httpClient, a.url(path), data)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return a.decode(resp, out)
+}
+
+// url returns the URL of a path of the versioned Graph API, e.g. /me/accounts
+func (a *facebookAPI) url(path string) string {
+	return a.graphBaseURL + "/" + a.version + path
+}
+
+// decode reads a Graph API response into out, or classifies it if it is unsuccessful
+func (a *facebookAPI) decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return facebookError(resp, body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
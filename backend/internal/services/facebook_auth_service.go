@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// facebookCreateContentTask is the page task that allows publishing as the page
+const facebookCreateContentTask = "CREATE_CONTENT"
+
+// facebookPagesPerRequest is how many pages are requested per page of /me/accounts
+const facebookPagesPerRequest = 100
+
+// FacebookAuthService handles Facebook Login, long-lived user tokens and the page tokens they give
+// https://developers.facebook.com/docs/facebook-login/guides/access-tokens/get-long-lived
+type FacebookAuthService struct {
+	appID       string
+	appSecret   string
+	redirectURI string
+	scopes      []string
+	authBaseURL string // Login dialog, e.g. https://www.facebook.com
+	api         *facebookAPI
+}
+
+// NewFacebookAuthService creates a new Facebook auth service
+func NewFacebookAuthService(appID, appSecret, redirectURI string, scopes []string, graphVersion, authBaseURL, graphBaseURL string) *FacebookAuthService {
+	return &FacebookAuthService{
+		appID:       appID,
+		appSecret:   appSecret,
+		redirectURI: redirectURI,
+		scopes:      scopes,
+		authBaseURL: authBaseURL,
+		api: &facebookAPI{
+			graphBaseURL: graphBaseURL,
+			version:      graphVersion,
+			httpClient:   newHTTPClient("facebook", 30*time.Second),
+		},
+	}
+}
+
+// FacebookTokenResponse represents a user access token, from a code or a token exchange
+type FacebookTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // Typically 5184000 (60 days) for long-lived tokens
+}
+
+// FacebookUserInfo is the profile of a Facebook user
+type FacebookUserInfo struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Picture facebookPicture `json:"picture"`
+}
+
+// FacebookManagedPage is a page the user has a role on, with a page access token
+// Page tokens obtained with a long-lived user token don't expire
+type FacebookManagedPage struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Category    string          `json:"category"`
+	AccessToken string          `json:"access_token"`
+	Picture     facebookPicture `json:"picture"`
+	Tasks       []string        `json:"tasks"` // What the user can do on the page, e.g. CREATE_CONTENT
+}
+
+// CanPublish reports whether the user can publish posts as the page
+func (p *FacebookManagedPage) CanPublish() bool {
+	for _, task := range p.Tasks {
+		if task == facebookCreateContentTask {
+			return true
+		}
+	}
+	return false
+}
+
+// facebookPicture is the picture field of a user or page
+type facebookPicture struct {
+	Data struct {
+		URL string `json:"url"`
+	} `json:"data"`
+}
+
+// GenerateAuthURL creates the Facebook Login URL and the state that protects it
+func (s *FacebookAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("client_id", s.appID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("scope", strings.Join(s.scopes, ","))
+	params.Set("response_type", "code")
+	params.Set("state", state)
+
+	return fmt.Sprintf("%s/%s/dialog/oauth?%s", s.authBaseURL, s.api.version, params.Encode()), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for a short-lived user token, valid for 1 to 2 hours
+func (s *FacebookAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*FacebookTokenResponse, error) {
+	params := url.Values{}
+	params.Set("client_id", s.appID)
+	params.Set("client_secret", s.appSecret)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("code", code)
+
+	tokenResp, err := s.requestToken(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return tokenResp, nil
+}
+
+// ExchangeLongLivedToken exchanges a user token for a long-lived user token, valid for 60 days
+// A long-lived token can be exchanged again, which is how it is refreshed
+func (s *FacebookAuthService) ExchangeLongLivedToken(ctx context.Context, userToken string) (*FacebookTokenResponse, error) {
+	params := url.Values{}
+	params.Set("grant_type", "fb_exchange_token")
+	params.Set("client_id", s.appID)
+	params.Set("client_secret", s.appSecret)
+	params.Set("fb_exchange_token", userToken)
+
+	tokenResp, err := s.requestToken(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange for long-lived token: %w", err)
+	}
+
+	log.Printf("Facebook long-lived token obtained, expires in %d seconds (%.1f days)", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
+	return tokenResp, nil
+}
+
+// requestToken requests a user token from the token endpoint
+func (s *FacebookAuthService) requestToken(ctx context.Context, params url.Values) (*FacebookTokenResponse, error) {
+	resp, err := getWithContext(ctx, s.api.httpClient, s.api.url("/oauth/access_token")+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp FacebookTokenResponse
+	if err := s.api.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+	return &tokenResp, nil
+}
+
+// GetUserInfo retrieves the profile of the user the token belongs to
+func (s *FacebookAuthService) GetUserInfo(ctx context.Context, accessToken string) (*FacebookUserInfo, error) {
+	var userInfo FacebookUserInfo
+	if err := s.api.get(ctx, "/me", accessToken, url.Values{"fields": {"id,name,picture{url}"}}, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	return &userInfo, nil
+}
+
+// GetPages retrieves the pages the user has a role on, with their page tokens
+// Only pages the user granted the app access to during login are returned
+func (s *FacebookAuthService) GetPages(ctx context.Context, userToken string) ([]FacebookManagedPage, error) {
+	params := url.Values{}
+	params.Set("fields", "id,name,category,access_token,picture{url},tasks")
+	params.Set("limit", fmt.Sprint(facebookPagesPerRequest))
+
+	var pages []FacebookManagedPage
+	for {
+		var pagesResp struct {
+			Data   []FacebookManagedPage `json:"data"`
+			Paging struct {
+				Cursors struct {
+					After string `json:"after"`
+				} `json:"cursors"`
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := s.api.get(ctx, "/me/accounts", userToken, params, &pagesResp); err != nil {
+			return nil, fmt.Errorf("failed to get pages: %w", err)
+		}
+		pages = append(pages, pagesResp.Data...)
+
+		// The next link is only present while there are more pages
+		if pagesResp.Paging.Next == "" || pagesResp.Paging.Cursors.After == "" {
+			return pages, nil
+		}
+		params.Set("after", pagesResp.Paging.Cursors.After)
+	}
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Facebook video statuses, the status.video_status field of a video
+const (
+	FacebookVideoReady      = "ready"
+	FacebookVideoProcessing = "processing"
+	FacebookVideoError      = "error"
+	FacebookVideoExpired    = "expired"
+)
+
+// Scheduled page posts have to be published between 10 minutes and 30 days after they are created
+const (
+	FacebookMinScheduleDelay = 10 * time.Minute
+	FacebookMaxScheduleDelay = 30 * 24 * time.Hour
+)
+
+// FacebookPostService publishes photo, multi-photo, video and link posts to Facebook Pages
+// Every request is made with the token of the page that publishes the post
+// https://developers.facebook.com/docs/pages-api/posts
+type FacebookPostService struct {
+	api *facebookAPI
+}
+
+// NewFacebookPostService creates a new Facebook post service sharing the Graph API client of authService
+func NewFacebookPostService(authService *FacebookAuthService) *FacebookPostService {
+	return &FacebookPostService{api: authService.api}
+}
+
+// FacebookPostRequest is the content of a page post
+type FacebookPostRequest struct {
+	Text  string
+	Media []MediaItem // None, up to 10 photos, or one video
+	Link  string      // Shared with a preview; only for posts without media
+	Title string      // Title of a video
+
+	// ScheduledPublishTime, if set, has Facebook publish the post at that time instead of now
+	ScheduledPublishTime time.Time
+}
+
+// FacebookPost is a created page post
+// Videos are processed before they are published, which Processing reports
+type FacebookPost struct {
+	ID         string // {page-id}_{object-id}, the object being the post, photo or video
+	ShareURL   string
+	Processing bool
+}
+
+// CreatePost publishes a post as the page pageToken belongs to
+func (s *FacebookPostService) CreatePost(ctx context.Context, pageToken, pageID string, req FacebookPostRequest) (*FacebookPost, error) {
+	if req.Link != "" && len(req.Media) > 0 {
+		return nil, fmt.Errorf("a Facebook link post can't have media")
+	}
+
+	switch {
+	case len(req.Media) == 1 && req.Media[0].IsVideo:
+		return s.createVideoPost(ctx, pageToken, pageID, req)
+	case len(req.Media) == 1:
+		return s.createPhotoPost(ctx, pageToken, pageID, req)
+	case len(req.Media) > 1:
+		for _, item := range req.Media {
+			if item.IsVideo {
+				return nil, fmt.Errorf("a Facebook post can have several photos or one video, not both")
+			}
+		}
+		return s.createMultiPhotoPost(ctx, pageToken, pageID, req)
+	default:
+		return s.createFeedPost(ctx, pageToken, pageID, req, nil)
+	}
+}
+
+// createFeedPost creates a text or link post, or a post of photos that were uploaded unpublished
+func (s *FacebookPostService) createFeedPost(ctx context.Context, pageToken, pageID string, req FacebookPostRequest, photoIDs []string) (*FacebookPost, error) {
+	form := url.Values{}
+	if req.Text != "" {
+		form.Set("message", req.Text)
+	}
+	if req.Link != "" {
+		form.Set("link", req.Link)
+	}
+	for i, photoID := range photoIDs {
+		attachment, err := json.Marshal(map[string]string{"media_fbid": photoID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal attached media: %w", err)
+		}
+		form.Set(fmt.Sprintf("attached_media[%d]", i), string(attachment))
+	}
+	setScheduledPublishTime(form, req.ScheduledPublishTime)
+
+	var postResp struct {
+		ID string `json:"id"`
+	}
+	if err := s.api.post(ctx, "/"+pageID+"/feed", pageToken, form, &postResp); err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	if postResp.ID == "" {
+		return nil, fmt.Errorf("post response has no ID")
+	}
+
+	log.Printf("Facebook post created on page %s: %s", pageID, postResp.ID)
+	return &FacebookPost{ID: postResp.ID, ShareURL: FacebookPostURL(postResp.ID)}, nil
+}
+
+// createPhotoPost publishes a photo with the text as its caption
+func (s *FacebookPostService) createPhotoPost(ctx context.Context, pageToken, pageID string, req FacebookPostRequest) (*FacebookPost, error) {
+	form := url.Values{}
+	form.Set("url", req.Media[0].URL)
+	if req.Text != "" {
+		form.Set("message", req.Text)
+	}
+	setScheduledPublishTime(form, req.ScheduledPublishTime)
+
+	var photoResp struct {
+		ID     string `json:"id"`
+		PostID string `json:"post_id"`
+	}
+	if err := s.api.post(ctx, "/"+pageID+"/photos", pageToken, form, &photoResp); err != nil {
+		return nil, fmt.Errorf("failed to publish photo: %w", err)
+	}
+
+	// Scheduled photos have no post until they are published
+	postID := photoResp.PostID
+	if postID == "" {
+		postID = pageID + "_" + photoResp.ID
+	}
+
+	log.Printf("Facebook photo post created on page %s: %s", pageID, postID)
+	return &FacebookPost{ID: postID, ShareURL: FacebookPostURL(postID)}, nil
+}
+
+// createMultiPhotoPost uploads every photo unpublished, then creates a post attaching them
+func (s *FacebookPostService) createMultiPhotoPost(ctx context.Context, pageToken, pageID string, req FacebookPostRequest) (*FacebookPost, error) {
+	photoIDs := make([]string, 0, len(req.Media))
+	for i, item := range req.Media {
+		form := url.Values{}
+		form.Set("url", item.URL)
+		form.Set("published", "false")
+		// Unpublished photos of a scheduled post have to be temporary
+		if !req.ScheduledPublishTime.IsZero() {
+			form.Set("temporary", "true")
+		}
+
+		var photoResp struct {
+			ID string `json:"id"`
+		}
+		if err := s.api.post(ctx, "/"+pageID+"/photos", pageToken, form, &photoResp); err != nil {
+			return nil, fmt.Errorf("failed to upload photo %d: %w", i+1, err)
+		}
+		photoIDs = append(photoIDs, photoResp.ID)
+	}
+
+	return s.createFeedPost(ctx, pageToken, pageID, req, photoIDs)
+}
+
+// createVideoPost has Facebook download a video from its URL
+// The video is processed before it is published, which GetVideoStatus polls
+func (s *FacebookPostService) createVideoPost(ctx context.Context, pageToken, pageID string, req FacebookPostRequest) (*FacebookPost, error) {
+	form := url.Values{}
+	form.Set("file_url", req.Media[0].URL)
+	if req.Text != "" {
+		form.Set("description", req.Text)
+	}
+	if req.Title != "" {
+		form.Set("title", req.Title)
+	}
+	setScheduledPublishTime(form, req.ScheduledPublishTime)
+
+	var videoResp struct {
+		ID string `json:"id"`
+	}
+	if err := s.api.post(ctx, "/"+pageID+"/videos", pageToken, form, &videoResp); err != nil {
+		return nil, fmt.Errorf("failed to upload video: %w", err)
+	}
+	if videoResp.ID == "" {
+		return nil, fmt.Errorf("video response has no ID")
+	}
+
+	log.Printf("Facebook video uploaded to page %s: %s, waiting for processing", pageID, videoResp.ID)
+	return &FacebookPost{
+		ID:         pageID + "_" + videoResp.ID,
+		ShareURL:   FacebookVideoURL(pageID, videoResp.ID),
+		Processing: true,
+	}, nil
+}
+
+// FacebookVideoStatus is the processing status of a video
+type FacebookVideoStatus struct {
+	VideoStatus  string // ready, processing, error or expired
+	ErrorMessage string // Why processing failed
+}
+
+// GetVideoStatus retrieves the processing status of a video
+func (s *FacebookPostService) GetVideoStatus(ctx context.Context, pageToken, videoID string) (*FacebookVideoStatus, error) {
+	var videoResp struct {
+		Status struct {
+			VideoStatus     string `json:"video_status"`
+			ProcessingPhase struct {
+				Status string `json:"status"`
+				Errors []struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				} `json:"errors"`
+			} `json:"processing_phase"`
+		} `json:"status"`
+	}
+	if err := s.api.get(ctx, "/"+videoID, pageToken, url.Values{"fields": {"status"}}, &videoResp); err != nil {
+		return nil, fmt.Errorf("failed to get video status: %w", err)
+	}
+
+	status := &FacebookVideoStatus{VideoStatus: videoResp.Status.VideoStatus}
+	var messages []string
+	for _, processingError := range videoResp.Status.ProcessingPhase.Errors {
+		messages = append(messages, processingError.Message)
+	}
+	status.ErrorMessage = strings.Join(messages, "; ")
+	return status, nil
+}
+
+// SplitFacebookPostID splits the ID of a page post into the page ID and the ID of the post's object
+func SplitFacebookPostID(postID string) (pageID, objectID string, err error) {
+	pageID, objectID, ok := strings.Cut(postID, "_")
+	if !ok || pageID == "" || objectID == "" {
+		return "", "", fmt.Errorf("%q is not a Facebook page post ID", postID)
+	}
+	return pageID, objectID, nil
+}
+
+// setScheduledPublishTime has a post published at a later time instead of now
+func setScheduledPublishTime(form url.Values, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	form.Set("published", "false")
+	form.Set("scheduled_publish_time", strconv.FormatInt(at.Unix(), 10))
+}
+
+// FacebookPostURL returns the URL of a page post
+func FacebookPostURL(postID string) string {
+	pageID, objectID, err := SplitFacebookPostID(postID)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("https://www.facebook.com/%s/posts/%s", pageID, objectID)
+}
+
+// FacebookVideoURL returns the URL of a page video
+func FacebookVideoURL(pageID, videoID string) string {
+	return fmt.Sprintf("https://www.facebook.com/%s/videos/%s", pageID, videoID)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+)
+
+// Retry settings shared by the clients that talk to platforms and media hosts; tests shorten the backoff
+var (
+	httpMaxRetries   = 2
+	httpRetryBackoff = 500 * time.Millisecond
+)
+
+// newHTTPClient creates the client an integration uses to talk to a platform or media host
+// timeout bounds each attempt; idempotent requests are retried after network errors and 502, 503 or 504 responses
+func newHTTPClient(name string, timeout time.Duration) *http.Client {
+	return httpclient.New(httpclient.Options{
+		Name:         name,
+		Timeout:      timeout,
+		MaxRetries:   httpMaxRetries,
+		RetryBackoff: httpRetryBackoff,
+	})
+}
+
+// getWithContext issues a GET request that is cancelled together with ctx
+func getWithContext(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// postFormWithContext posts URL-encoded form values in a request that is cancelled together with ctx
+func postFormWithContext(ctx context.Context, client *http.Client, url string, data url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return client.Do(req)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+// Returns ctx.Err() if the wait was interrupted
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
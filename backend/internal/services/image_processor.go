@@ -0,0 +1,512 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+const (
+	// ImageFitCrop crops the center of an image to bring it into the allowed aspect ratio range
+	ImageFitCrop = "crop"
+	// ImageFitPad adds white borders to bring an image into the allowed aspect ratio range
+	ImageFitPad = "pad"
+
+	maxImageDownloadSize = 50 * 1024 * 1024 // 50MB
+	minProcessedSide     = 64               // Stop shrinking images below this many pixels
+
+	// maxImagePixels bounds the images Process decodes, as a small file can hold a huge image
+	// that takes gigabytes to decode. It's four times the largest image any platform accepts
+	// (4096x4096), which leaves room for high resolution photos that are scaled down
+	maxImagePixels = 4 * 4096 * 4096
+)
+
+// jpegQualities are tried in order until the encoded image fits the platform's size limit
+var jpegQualities = []int{90, 82, 74, 66, 58}
+
+// ProcessedImage describes an image variant prepared for a specific platform
+type ProcessedImage struct {
+	URL      string `json:"url"`
+	Format   string `json:"format"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size"`
+}
+
+// ImageProcessor fits images to a platform's aspect ratio, dimension and size limits
+// Re-encoding also drops EXIF and other metadata from the original file
+type ImageProcessor struct {
+	httpClient *http.Client
+	store      *MediaStore
+	fitMode    string
+}
+
+// NewImageProcessor creates a new image processor that stores its output in store
+func NewImageProcessor(store *MediaStore, fitMode string) *ImageProcessor {
+	if fitMode != ImageFitPad {
+		fitMode = ImageFitCrop
+	}
+	return &ImageProcessor{
+		httpClient: newHTTPClient("image-processor", 2*time.Minute),
+		store:      store,
+		fitMode:    fitMode,
+	}
+}
+
+// CanProcess reports whether Process is able to fix constraint violations of a probed media file
+func (p *ImageProcessor) CanProcess(probe *MediaProbeResult) bool {
+	if probe == nil || probe.Kind != MediaTypeImage {
+		return false
+	}
+	switch probe.Format {
+	case "jpeg", "png", "gif":
+		return true
+	}
+	return false
+}
+
+// Process prepares the image at mediaURL for a platform
+// Returns nil if the image can be posted as is or is in a format we cannot decode
+func (p *ImageProcessor) Process(ctx context.Context, platform models.Platform, mediaURL string) (*ProcessedImage, error) {
+	constraints, ok := GetMediaConstraints(platform)
+	if !ok {
+		return nil, nil
+	}
+	c := constraints.Image
+
+	data, err := p.download(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the size from the header before decoding the pixels
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image is %dx%d, more than the %d megapixels that can be processed",
+			cfg.Width, cfg.Height, maxImagePixels/1_000_000)
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	// Keep animated GIFs intact where the platform accepts them
+	if format == "gif" && containsFold(c.Formats, "gif") {
+		return nil, nil
+	}
+
+	bounds := src.Bounds()
+	probe := newImageProbeResult(format, int64(len(data)), bounds.Dx(), bounds.Dy())
+	if len(CheckMediaConstraints(platform, probe)) == 0 && !hasImageMetadata(data, format) {
+		return nil, nil
+	}
+
+	img := toRGBA(src)
+	if format == "jpeg" {
+		img = orientImage(img, jpegOrientation(data))
+	}
+	img = p.fitAspectRatio(img, c.MinAspectRatio, c.MaxAspectRatio)
+	img = fitDimensions(img, c.MinWidth, c.MaxWidth, c.MaxHeight)
+
+	outFormat := outputImageFormat(format, c.Formats)
+	encoded, img, outFormat, err := encodeWithinLimit(img, outFormat, c.Formats, c.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := ".jpg"
+	if outFormat == "png" {
+		ext = ".png"
+	}
+	url, err := p.store.Save(encoded, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcessedImage{
+		URL:      url,
+		Format:   outFormat,
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		FileSize: int64(len(encoded)),
+	}
+	log.Printf("Processed image %s for %s: %dx%d %s -> %dx%d %s (%d bytes)",
+		mediaURL, platform, bounds.Dx(), bounds.Dy(), format, result.Width, result.Height, result.Format, result.FileSize)
+	return result, nil
+}
+
+// download fetches the image at mediaURL
+func (p *ImageProcessor) download(ctx context.Context, mediaURL string) ([]byte, error) {
+	resp, err := getWithContext(ctx, p.httpClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxImageDownloadSize {
+		return nil, fmt.Errorf("image exceeds maximum size of %d MB", maxImageDownloadSize/(1024*1024))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageDownloadSize {
+		return nil, fmt.Errorf("image exceeds maximum size of %d MB", maxImageDownloadSize/(1024*1024))
+	}
+	return data, nil
+}
+
+// fitAspectRatio crops or pads an image into the [minRatio, maxRatio] range
+func (p *ImageProcessor) fitAspectRatio(img *image.RGBA, minRatio, maxRatio float64) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	ratio := float64(w) / float64(h)
+
+	target := ratio
+	if minRatio > 0 && ratio < minRatio {
+		target = minRatio
+	}
+	if maxRatio > 0 && ratio > maxRatio {
+		target = maxRatio
+	}
+	if target == ratio {
+		return img
+	}
+
+	if p.fitMode == ImageFitPad {
+		newW, newH := w, h
+		if ratio > target {
+			newH = int(math.Round(float64(w) / target))
+		} else {
+			newW = int(math.Round(float64(h) * target))
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, newW, newH))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		offset := image.Pt((newW-w)/2, (newH-h)/2)
+		draw.Draw(dst, img.Bounds().Add(offset), img, image.Point{}, draw.Src)
+		return dst
+	}
+
+	crop := img.Bounds()
+	if ratio > target {
+		newW := int(math.Round(float64(h) * target))
+		crop.Min.X = (w - newW) / 2
+		crop.Max.X = crop.Min.X + newW
+	} else {
+		newH := int(math.Round(float64(w) / target))
+		crop.Min.Y = (h - newH) / 2
+		crop.Max.Y = crop.Min.Y + newH
+	}
+	return toRGBA(img.SubImage(crop))
+}
+
+// fitDimensions downscales an image to fit maxWidth x maxHeight, or upscales it to minWidth
+func fitDimensions(img *image.RGBA, minWidth, maxWidth, maxHeight int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	scale := 1.0
+	if maxWidth > 0 && w > maxWidth {
+		scale = math.Min(scale, float64(maxWidth)/float64(w))
+	}
+	if maxHeight > 0 && h > maxHeight {
+		scale = math.Min(scale, float64(maxHeight)/float64(h))
+	}
+	if scale == 1.0 && minWidth > 0 && w < minWidth {
+		scale = float64(minWidth) / float64(w)
+	}
+	if scale == 1.0 {
+		return img
+	}
+
+	return scaleImage(img, scale)
+}
+
+// scaleImage resizes an image by a factor, keeping its aspect ratio
+func scaleImage(img *image.RGBA, scale float64) *image.RGBA {
+	w := int(math.Max(1, math.Round(float64(img.Bounds().Dx())*scale)))
+	h := int(math.Max(1, math.Round(float64(img.Bounds().Dy())*scale)))
+	return resizeImage(img, w, h)
+}
+
+// resizeImage resamples an image to width x height
+// Each destination pixel averages the source pixels it covers (box filter), which
+// gives clean results when downscaling; upscaling falls back to nearest neighbour
+func resizeImage(src *image.RGBA, width, height int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xScale := float64(sw) / float64(width)
+	yScale := float64(sh) / float64(height)
+
+	for y := 0; y < height; y++ {
+		y0, y1 := sourceSpan(y, yScale, sh)
+		for x := 0; x < width; x++ {
+			x0, x1 := sourceSpan(x, xScale, sw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				off := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(src.Pix[off])
+					g += uint64(src.Pix[off+1])
+					b += uint64(src.Pix[off+2])
+					a += uint64(src.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+
+			d := y*dst.Stride + x*4
+			dst.Pix[d] = uint8(r / n)
+			dst.Pix[d+1] = uint8(g / n)
+			dst.Pix[d+2] = uint8(b / n)
+			dst.Pix[d+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// sourceSpan returns the range of source pixels covered by destination pixel i
+func sourceSpan(i int, scale float64, limit int) (int, int) {
+	start := int(float64(i) * scale)
+	end := int(float64(i+1) * scale)
+	if start >= limit {
+		start = limit - 1
+	}
+	if end <= start {
+		end = start + 1
+	}
+	if end > limit {
+		end = limit
+	}
+	return start, end
+}
+
+// toRGBA converts any image to an RGBA image whose bounds start at the origin
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// outputImageFormat picks the encoding for a processed image
+// PNG stays PNG where the platform accepts it, everything else becomes JPEG
+func outputImageFormat(sourceFormat string, allowed []string) string {
+	if sourceFormat == "png" && (len(allowed) == 0 || containsFold(allowed, "png")) {
+		return "png"
+	}
+	return "jpeg"
+}
+
+// encodeWithinLimit encodes an image so that it fits maxSize bytes
+// Lowers JPEG quality first, then shrinks the image until it fits
+// Returns the encoded bytes together with the image and format that were actually encoded
+func encodeWithinLimit(img *image.RGBA, format string, allowed []string, maxSize int64) ([]byte, *image.RGBA, string, error) {
+	for {
+		if format == "png" {
+			var buf bytes.Buffer
+			encoder := png.Encoder{CompressionLevel: png.BestCompression}
+			if err := encoder.Encode(&buf, img); err != nil {
+				return nil, nil, "", fmt.Errorf("failed to encode PNG: %w", err)
+			}
+			if maxSize == 0 || int64(buf.Len()) <= maxSize {
+				return buf.Bytes(), img, format, nil
+			}
+			// PNG does not compress photos well; switch to JPEG when the platform allows it
+			if len(allowed) == 0 || containsFold(allowed, "jpeg") {
+				format = "jpeg"
+				continue
+			}
+		} else {
+			flat := flattenAlpha(img)
+			for _, quality := range jpegQualities {
+				var buf bytes.Buffer
+				if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+					return nil, nil, "", fmt.Errorf("failed to encode JPEG: %w", err)
+				}
+				if maxSize == 0 || int64(buf.Len()) <= maxSize {
+					return buf.Bytes(), img, format, nil
+				}
+			}
+		}
+
+		w, h := img.Bounds().Dx(), img.Bounds().Dy()
+		if w <= minProcessedSide || h <= minProcessedSide {
+			return nil, nil, "", fmt.Errorf("image cannot be compressed below %.1f MB", megabytes(maxSize))
+		}
+		img = scaleImage(img, 0.8)
+	}
+}
+
+// flattenAlpha draws an image onto a white background since JPEG has no transparency
+func flattenAlpha(img *image.RGBA) *image.RGBA {
+	if img.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// hasImageMetadata reports whether an encoded image carries EXIF, XMP, comments or text chunks
+func hasImageMetadata(data []byte, format string) bool {
+	switch format {
+	case "jpeg":
+		found := false
+		walkJPEGSegments(data, func(marker byte, payload []byte) bool {
+			// APP1-APP15 hold EXIF, XMP, IPTC etc.; 0xFE is a comment
+			if (marker >= 0xE1 && marker <= 0xEF) || marker == 0xFE {
+				found = true
+				return false
+			}
+			return true
+		})
+		return found
+	case "png":
+		for off := 8; off+8 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[off : off+4]))
+			switch string(data[off+4 : off+8]) {
+			case "eXIf", "tEXt", "iTXt", "zTXt", "tIME":
+				return true
+			case "IEND":
+				return false
+			}
+			off += 12 + length
+		}
+	}
+	return false
+}
+
+// walkJPEGSegments calls fn for every marker segment before the image data
+// Iteration stops when fn returns false or the start of scan is reached
+func walkJPEGSegments(data []byte, fn func(marker byte, payload []byte) bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return
+	}
+	for off := 2; off+4 <= len(data); {
+		if data[off] != 0xFF {
+			return
+		}
+		marker := data[off+1]
+		if marker == 0xDA || marker == 0xD9 { // Start of scan / end of image
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[off+2 : off+4]))
+		if length < 2 || off+2+length > len(data) {
+			return
+		}
+		if !fn(marker, data[off+4:off+2+length]) {
+			return
+		}
+		off += 2 + length
+	}
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, defaulting to 1 (upright)
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	walkJPEGSegments(data, func(marker byte, payload []byte) bool {
+		if marker != 0xE1 || len(payload) < 14 || string(payload[:6]) != "Exif\x00\x00" {
+			return true
+		}
+		tiff := payload[6:]
+
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return false
+		}
+
+		ifd := int(order.Uint32(tiff[4:8]))
+		if ifd+2 > len(tiff) {
+			return false
+		}
+		entries := int(order.Uint16(tiff[ifd : ifd+2]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				break
+			}
+			if order.Uint16(tiff[entry:entry+2]) == 0x0112 { // Orientation
+				orientation = int(order.Uint16(tiff[entry+8 : entry+10]))
+				break
+			}
+		}
+		return false
+	})
+	if orientation < 1 || orientation > 8 {
+		return 1
+	}
+	return orientation
+}
+
+// orientImage applies an EXIF orientation so the pixels are stored upright
+// Needed because re-encoding drops the orientation tag along with the rest of the EXIF data
+func orientImage(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Needs 90° clockwise rotation
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Needs 90° counter-clockwise rotation
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,588 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// solidImage returns a width x height image filled with c
+func solidImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// noisyImage returns an image that compresses badly, like a photo
+func noisyImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = uint8(seed >> 24)
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
+
+// encodeJPEG encodes img as a JPEG with the given segments inserted after the start of image
+func encodeJPEG(t *testing.T, img image.Image, segments ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("failed to encode JPEG: %v", err)
+	}
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+// jpegSegment encodes a JPEG marker segment
+func jpegSegment(marker byte, payload []byte) []byte {
+	out := []byte{0xFF, marker}
+	out = binary.BigEndian.AppendUint16(out, uint16(2+len(payload)))
+	return append(out, payload...)
+}
+
+// exifOrientation encodes an APP1 EXIF payload whose first IFD holds an orientation tag
+func exifOrientation(order binary.AppendByteOrder, orientation uint16) []byte {
+	tiff := []byte("II*\x00")
+	if order == binary.BigEndian {
+		tiff = []byte("MM\x00*")
+	}
+	tiff = order.AppendUint32(tiff, 8) // Offset of the first IFD
+	tiff = order.AppendUint16(tiff, 1) // Entries
+	tiff = order.AppendUint16(tiff, 0x0112)
+	tiff = order.AppendUint16(tiff, 3) // SHORT
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = order.AppendUint32(tiff, 0) // No next IFD
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+// encodePNG encodes img as a PNG with the given chunks inserted after the header
+func encodePNG(t *testing.T, img image.Image, chunks ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	data := buf.Bytes()
+	ihdrEnd := 8 + 12 + 13 // Signature, then the IHDR chunk
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
+
+// pngChunk encodes a PNG chunk
+func pngChunk(chunkType string, payload []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	out = append(out, chunkType...)
+	out = append(out, payload...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[4:]))
+}
+
+// pngHeader returns the start of a PNG file of the given size, which is enough for image.DecodeConfig
+func pngHeader(width, height int) []byte {
+	ihdr := binary.BigEndian.AppendUint32(nil, uint32(width))
+	ihdr = binary.BigEndian.AppendUint32(ihdr, uint32(height))
+	ihdr = append(ihdr, 8, 6, 0, 0, 0) // 8-bit RGBA, no interlacing
+	return append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", ihdr)...)
+}
+
+// encodeGIF encodes a single frame GIF
+func encodeGIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black, color.White})
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode GIF: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFitAspectRatio(t *testing.T) {
+	tests := []struct {
+		name       string
+		fitMode    string
+		width      int
+		height     int
+		minRatio   float64
+		maxRatio   float64
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "within range", fitMode: ImageFitCrop, width: 1000, height: 1000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 1000, wantHeight: 1000},
+		{name: "at the minimum", fitMode: ImageFitCrop, width: 800, height: 1000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 800, wantHeight: 1000},
+		{name: "crop too tall", fitMode: ImageFitCrop, width: 1000, height: 2000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 1000, wantHeight: 1250},
+		{name: "crop too wide", fitMode: ImageFitCrop, width: 3000, height: 1000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 1910, wantHeight: 1000},
+		{name: "pad too tall", fitMode: ImageFitPad, width: 1000, height: 2000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 1600, wantHeight: 2000},
+		{name: "pad too wide", fitMode: ImageFitPad, width: 3000, height: 1000, minRatio: 0.8, maxRatio: 1.91, wantWidth: 3000, wantHeight: 1571},
+		{name: "no limits", fitMode: ImageFitCrop, width: 5000, height: 10, wantWidth: 5000, wantHeight: 10},
+		{name: "only a maximum", fitMode: ImageFitCrop, width: 10, height: 5000, maxRatio: 1.91, wantWidth: 10, wantHeight: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewImageProcessor(nil, tt.fitMode)
+			got := p.fitAspectRatio(solidImage(tt.width, tt.height, color.RGBA{0, 0, 255, 255}), tt.minRatio, tt.maxRatio)
+			if w, h := got.Bounds().Dx(), got.Bounds().Dy(); w != tt.wantWidth || h != tt.wantHeight {
+				t.Errorf("fitAspectRatio() = %dx%d, want %dx%d", w, h, tt.wantWidth, tt.wantHeight)
+			}
+			if got.Bounds().Min != (image.Point{}) {
+				t.Errorf("bounds start at %v, want the origin", got.Bounds().Min)
+			}
+		})
+	}
+}
+
+func TestFitAspectRatioPadsWithWhite(t *testing.T) {
+	p := NewImageProcessor(nil, ImageFitPad)
+	got := p.fitAspectRatio(solidImage(100, 200, color.RGBA{0, 0, 255, 255}), 0.8, 0)
+
+	if c := got.RGBAAt(0, 100); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("border pixel = %v, want white", c)
+	}
+	if c := got.RGBAAt(80, 100); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("center pixel = %v, want the original blue", c)
+	}
+}
+
+func TestFitDimensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		minWidth   int
+		maxWidth   int
+		maxHeight  int
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "within limits", width: 1000, height: 800, minWidth: 320, maxWidth: 1440, maxHeight: 1920, wantWidth: 1000, wantHeight: 800},
+		{name: "exactly the maximum", width: 1440, height: 1920, maxWidth: 1440, maxHeight: 1920, wantWidth: 1440, wantHeight: 1920},
+		{name: "too wide", width: 2880, height: 1000, maxWidth: 1440, wantWidth: 1440, wantHeight: 500},
+		{name: "too tall", width: 1000, height: 3840, maxWidth: 1440, maxHeight: 1920, wantWidth: 500, wantHeight: 1920},
+		{name: "too wide and too tall", width: 4000, height: 4000, maxWidth: 1440, maxHeight: 1000, wantWidth: 1000, wantHeight: 1000},
+		{name: "too narrow", width: 160, height: 100, minWidth: 320, maxWidth: 1440, wantWidth: 320, wantHeight: 200},
+		{name: "no limits", width: 10, height: 10, wantWidth: 10, wantHeight: 10},
+		{name: "never below one pixel", width: 10000, height: 1, maxWidth: 100, wantWidth: 100, wantHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitDimensions(solidImage(tt.width, tt.height, color.RGBA{255, 0, 0, 255}), tt.minWidth, tt.maxWidth, tt.maxHeight)
+			if w, h := got.Bounds().Dx(), got.Bounds().Dy(); w != tt.wantWidth || h != tt.wantHeight {
+				t.Errorf("fitDimensions() = %dx%d, want %dx%d", w, h, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestResizeImageAverages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{0, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{200, 100, 0, 255})
+	src.SetRGBA(0, 1, color.RGBA{0, 100, 200, 255})
+	src.SetRGBA(1, 1, color.RGBA{200, 200, 200, 255})
+
+	got := resizeImage(src, 1, 1)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{100, 100, 100, 255}) {
+		t.Errorf("downscaled pixel = %v, want the average of the source pixels", c)
+	}
+
+	up := resizeImage(src, 4, 4)
+	if c := up.RGBAAt(3, 3); c != (color.RGBA{200, 200, 200, 255}) {
+		t.Errorf("upscaled corner = %v, want the nearest source pixel", c)
+	}
+}
+
+func TestSourceSpan(t *testing.T) {
+	tests := []struct {
+		name      string
+		i         int
+		scale     float64
+		limit     int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "downscale by two", i: 1, scale: 2, limit: 10, wantStart: 2, wantEnd: 4},
+		{name: "last pixel", i: 4, scale: 2, limit: 10, wantStart: 8, wantEnd: 10},
+		{name: "upscale covers one pixel", i: 3, scale: 0.5, limit: 10, wantStart: 1, wantEnd: 2},
+		{name: "clamped to the limit", i: 5, scale: 2, limit: 10, wantStart: 9, wantEnd: 10},
+		{name: "fractional scale", i: 2, scale: 1.5, limit: 10, wantStart: 3, wantEnd: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := sourceSpan(tt.i, tt.scale, tt.limit)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("sourceSpan(%d, %v, %d) = [%d, %d), want [%d, %d)", tt.i, tt.scale, tt.limit, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestOutputImageFormat(t *testing.T) {
+	tests := []struct {
+		source  string
+		allowed []string
+		want    string
+	}{
+		{source: "png", allowed: []string{"jpeg", "png"}, want: "png"},
+		{source: "png", allowed: []string{"JPEG", "PNG"}, want: "png"},
+		{source: "png", allowed: nil, want: "png"},
+		{source: "png", allowed: []string{"jpeg"}, want: "jpeg"},
+		{source: "jpeg", allowed: []string{"jpeg", "png"}, want: "jpeg"},
+		{source: "gif", allowed: []string{"jpeg", "png"}, want: "jpeg"},
+	}
+
+	for _, tt := range tests {
+		if got := outputImageFormat(tt.source, tt.allowed); got != tt.want {
+			t.Errorf("outputImageFormat(%q, %v) = %q, want %q", tt.source, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeWithinLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		img        *image.RGBA
+		format     string
+		allowed    []string
+		maxSize    int64
+		wantErr    bool
+		wantFormat string
+		wantShrunk bool
+	}{
+		{name: "png within the limit", img: solidImage(200, 200, color.RGBA{0, 128, 0, 255}), format: "png", allowed: []string{"jpeg", "png"}, maxSize: 1 << 20, wantFormat: "png"},
+		{name: "no limit", img: noisyImage(200, 200), format: "png", wantFormat: "png"},
+		{name: "png too large becomes jpeg", img: noisyImage(400, 400), format: "png", allowed: []string{"jpeg", "png"}, maxSize: 200 * 1024, wantFormat: "jpeg"},
+		{name: "png only platform shrinks the image", img: noisyImage(400, 400), format: "png", allowed: []string{"png"}, maxSize: 200 * 1024, wantFormat: "png", wantShrunk: true},
+		{name: "jpeg shrunk to fit", img: noisyImage(800, 800), format: "jpeg", allowed: []string{"jpeg"}, maxSize: 40 * 1024, wantFormat: "jpeg", wantShrunk: true},
+		{name: "limit below what the smallest image needs", img: noisyImage(400, 400), format: "jpeg", allowed: []string{"jpeg"}, maxSize: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, img, format, err := encodeWithinLimit(tt.img, tt.format, tt.allowed, tt.maxSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("encodeWithinLimit() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			if tt.maxSize > 0 && int64(len(data)) > tt.maxSize {
+				t.Errorf("encoded %d bytes, want at most %d", len(data), tt.maxSize)
+			}
+			if shrunk := img.Bounds().Dx() < tt.img.Bounds().Dx(); shrunk != tt.wantShrunk {
+				t.Errorf("encoded a %dx%d image, want shrunk %v", img.Bounds().Dx(), img.Bounds().Dy(), tt.wantShrunk)
+			}
+
+			decoded, decodedFormat, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode output: %v", err)
+			}
+			if decodedFormat != format || decoded.Bounds().Dx() != img.Bounds().Dx() {
+				t.Errorf("output is a %dx%d %s, want the %dx%d %s that was reported", decoded.Bounds().Dx(), decoded.Bounds().Dy(), decodedFormat, img.Bounds().Dx(), img.Bounds().Dy(), format)
+			}
+		})
+	}
+}
+
+func TestFlattenAlpha(t *testing.T) {
+	img := solidImage(2, 1, color.RGBA{0, 0, 0, 0})
+	img.SetRGBA(1, 0, color.RGBA{255, 0, 0, 255})
+
+	got := flattenAlpha(img)
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", c)
+	}
+	if c := got.RGBAAt(1, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("opaque pixel = %v, want it unchanged", c)
+	}
+
+	opaque := solidImage(2, 2, color.RGBA{1, 2, 3, 255})
+	if flattenAlpha(opaque) != opaque {
+		t.Errorf("opaque image was copied")
+	}
+}
+
+func TestHasImageMetadata(t *testing.T) {
+	img := solidImage(16, 16, color.RGBA{10, 20, 30, 255})
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		want   bool
+	}{
+		{name: "plain jpeg", data: encodeJPEG(t, img), format: "jpeg"},
+		{name: "jpeg with exif", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 1))), format: "jpeg", want: true},
+		{name: "jpeg with xmp in app1", data: encodeJPEG(t, img, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x/>"))), format: "jpeg", want: true},
+		{name: "jpeg with iptc in app13", data: encodeJPEG(t, img, jpegSegment(0xED, []byte("Photoshop 3.0\x00"))), format: "jpeg", want: true},
+		{name: "jpeg with comment", data: encodeJPEG(t, img, jpegSegment(0xFE, []byte("hello"))), format: "jpeg", want: true},
+		{name: "jpeg segment longer than the file", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E'}, format: "jpeg"},
+		{name: "jpeg segment length below two", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xFE, 0x00, 0x02}, format: "jpeg"},
+		{name: "not a jpeg", data: []byte("GIF89a"), format: "jpeg"},
+		{name: "plain png", data: encodePNG(t, img), format: "png"},
+		{name: "png with text", data: encodePNG(t, img, pngChunk("tEXt", []byte("Author\x00me"))), format: "png", want: true},
+		{name: "png with exif", data: encodePNG(t, img, pngChunk("eXIf", []byte("MM\x00*"))), format: "png", want: true},
+		{name: "png with time", data: encodePNG(t, img, pngChunk("tIME", make([]byte, 7))), format: "png", want: true},
+		{name: "png chunk longer than the file", data: append(encodePNG(t, img)[:33], pngChunk("IDAT", nil)[:4]...), format: "png"},
+		{name: "truncated png", data: encodePNG(t, img)[:12], format: "png"},
+		{name: "gif", data: []byte("GIF89a"), format: "gif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasImageMetadata(tt.data, tt.format); got != tt.want {
+				t.Errorf("hasImageMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := solidImage(8, 8, color.RGBA{10, 20, 30, 255})
+	truncatedIFD := exifOrientation(binary.LittleEndian, 6)[:6+8+2+6]
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no exif", data: encodeJPEG(t, img), want: 1},
+		{name: "little endian", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 6))), want: 6},
+		{name: "big endian", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.BigEndian, 8))), want: 8},
+		{name: "upright", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.BigEndian, 1))), want: 1},
+		{name: "out of range", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 9))), want: 1},
+		{name: "zero", data: encodeJPEG(t, img, jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 0))), want: 1},
+		{name: "unknown byte order", data: encodeJPEG(t, img, jpegSegment(0xE1, append([]byte("Exif\x00\x00XX"), make([]byte, 12)...))), want: 1},
+		{name: "ifd beyond the segment", data: encodeJPEG(t, img, jpegSegment(0xE1, append([]byte("Exif\x00\x00II*\x00\xff\xff\x00\x00"), make([]byte, 4)...))), want: 1},
+		{name: "truncated ifd entry", data: encodeJPEG(t, img, jpegSegment(0xE1, truncatedIFD)), want: 1},
+		{name: "short exif segment", data: encodeJPEG(t, img, jpegSegment(0xE1, []byte("Exif\x00\x00II"))), want: 1},
+		{name: "xmp before exif", data: encodeJPEG(t, img, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x/>")), jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 3))), want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrientImage(t *testing.T) {
+	// A 3x2 image with a red top-left pixel
+	red := color.RGBA{255, 0, 0, 255}
+	src := solidImage(3, 2, color.RGBA{0, 0, 0, 255})
+	src.SetRGBA(0, 0, red)
+
+	tests := []struct {
+		orientation int
+		wantWidth   int
+		wantHeight  int
+		wantRed     image.Point // Where the red pixel ends up
+	}{
+		{orientation: 1, wantWidth: 3, wantHeight: 2, wantRed: image.Pt(0, 0)},
+		{orientation: 2, wantWidth: 3, wantHeight: 2, wantRed: image.Pt(2, 0)},
+		{orientation: 3, wantWidth: 3, wantHeight: 2, wantRed: image.Pt(2, 1)},
+		{orientation: 4, wantWidth: 3, wantHeight: 2, wantRed: image.Pt(0, 1)},
+		{orientation: 5, wantWidth: 2, wantHeight: 3, wantRed: image.Pt(0, 0)},
+		{orientation: 6, wantWidth: 2, wantHeight: 3, wantRed: image.Pt(1, 0)},
+		{orientation: 7, wantWidth: 2, wantHeight: 3, wantRed: image.Pt(1, 2)},
+		{orientation: 8, wantWidth: 2, wantHeight: 3, wantRed: image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		got := orientImage(src, tt.orientation)
+		if w, h := got.Bounds().Dx(), got.Bounds().Dy(); w != tt.wantWidth || h != tt.wantHeight {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, w, h, tt.wantWidth, tt.wantHeight)
+			continue
+		}
+		if c := got.RGBAAt(tt.wantRed.X, tt.wantRed.Y); c != red {
+			t.Errorf("orientation %d: pixel at %v = %v, want red", tt.orientation, tt.wantRed, c)
+		}
+	}
+}
+
+func TestImageProcessorProcess(t *testing.T) {
+	compliant := encodeJPEG(t, solidImage(1080, 1080, color.RGBA{50, 100, 150, 255}))
+	files := map[string][]byte{
+		"/compliant.jpg":         compliant,
+		"/with-exif.jpg":         encodeJPEG(t, solidImage(1080, 1080, color.RGBA{50, 100, 150, 255}), jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 1))),
+		"/rotated.jpg":           encodeJPEG(t, solidImage(1350, 1080, color.RGBA{50, 100, 150, 255}), jpegSegment(0xE1, exifOrientation(binary.LittleEndian, 6))),
+		"/panorama.png":          encodePNG(t, solidImage(3000, 1000, color.RGBA{0, 200, 0, 255})),
+		"/tall.png":              encodePNG(t, solidImage(1000, 3000, color.RGBA{0, 200, 0, 255})),
+		"/with-text.png":         encodePNG(t, solidImage(1000, 3000, color.RGBA{0, 200, 0, 255}), pngChunk("tEXt", []byte("Author\x00me"))),
+		"/tiny.jpg":              encodeJPEG(t, solidImage(100, 100, color.RGBA{0, 0, 0, 255})),
+		"/animation.gif":         encodeGIF(t, 1, 1),
+		"/not-an-image":          []byte("definitely not an image"),
+		"/truncated.png":         encodePNG(t, solidImage(100, 100, color.RGBA{0, 0, 0, 255}))[:40],
+		"/unknown-format":        webpFile("VP8L", []byte{0x2f, 0, 0, 0, 0, 0}),
+		"/another-compliant.png": encodePNG(t, solidImage(1080, 1080, color.RGBA{0, 0, 0, 255})),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		platform   models.Platform
+		fitMode    string
+		file       string
+		wantErr    bool
+		wantNil    bool // Posted as is
+		wantFormat string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "already fits", platform: models.PlatformInstagram, file: "/compliant.jpg", wantNil: true},
+		{name: "metadata is stripped", platform: models.PlatformInstagram, file: "/with-exif.jpg", wantFormat: "jpeg", wantWidth: 1080, wantHeight: 1080},
+		{name: "exif orientation is applied", platform: models.PlatformInstagram, file: "/rotated.jpg", wantFormat: "jpeg", wantWidth: 1080, wantHeight: 1350},
+		{name: "panorama is cropped and converted", platform: models.PlatformInstagram, fitMode: ImageFitCrop, file: "/panorama.png", wantFormat: "jpeg", wantWidth: 1440, wantHeight: 754},
+		{name: "tall image is padded", platform: models.PlatformInstagram, fitMode: ImageFitPad, file: "/tall.png", wantFormat: "jpeg", wantWidth: 1440, wantHeight: 1800},
+		{name: "png kept where allowed", platform: models.PlatformThreads, file: "/with-text.png", wantFormat: "png", wantWidth: 1000, wantHeight: 3000},
+		{name: "too small is upscaled", platform: models.PlatformInstagram, file: "/tiny.jpg", wantFormat: "jpeg", wantWidth: 320, wantHeight: 320},
+		{name: "gif kept where allowed", platform: models.PlatformX, file: "/animation.gif", wantNil: true},
+		{name: "gif converted where not allowed", platform: models.PlatformInstagram, file: "/animation.gif", wantFormat: "jpeg", wantWidth: 320, wantHeight: 320},
+		{name: "format without decoder", platform: models.PlatformInstagram, file: "/unknown-format", wantNil: true},
+		{name: "not an image", platform: models.PlatformInstagram, file: "/not-an-image", wantNil: true},
+		{name: "truncated image", platform: models.PlatformInstagram, file: "/truncated.png", wantErr: true},
+		{name: "missing file", platform: models.PlatformInstagram, file: "/missing.jpg", wantErr: true},
+		{name: "platform without constraints", platform: models.Platform("unknown"), file: "/panorama.png", wantNil: true},
+		{name: "png that fits", platform: models.PlatformThreads, file: "/another-compliant.png", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			p := NewImageProcessor(NewMediaStore(dir, "https://sosyal.example.com/"), tt.fitMode)
+
+			got, err := p.Process(context.Background(), tt.platform, server.URL+tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("Process() = %+v, want the image posted as is", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("Process() = nil, want a processed image")
+			}
+
+			if got.Format != tt.wantFormat || got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("Process() = %dx%d %s, want %dx%d %s", got.Width, got.Height, got.Format, tt.wantWidth, tt.wantHeight, tt.wantFormat)
+			}
+
+			// The stored file is what was reported, without metadata
+			data, err := os.ReadFile(filepath.Join(dir, path.Base(got.URL)))
+			if err != nil {
+				t.Fatalf("failed to read stored image: %v", err)
+			}
+			if int64(len(data)) != got.FileSize {
+				t.Errorf("stored %d bytes, reported %d", len(data), got.FileSize)
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil || format != got.Format || cfg.Width != got.Width || cfg.Height != got.Height {
+				t.Errorf("stored image is a %dx%d %s (%v), want the reported one", cfg.Width, cfg.Height, format, err)
+			}
+			if hasImageMetadata(data, format) {
+				t.Errorf("stored image still carries metadata")
+			}
+			if ratio := float64(got.Width) / float64(got.Height); tt.platform == models.PlatformInstagram && (ratio < 0.8-0.01 || ratio > 1.91+0.01 || math.IsNaN(ratio)) {
+				t.Errorf("aspect ratio %.3f is outside Instagram's range", ratio)
+			}
+		})
+	}
+}
+
+func TestImageProcessorRejectsHugeImages(t *testing.T) {
+	// Only the headers, as decoding the pixels is what must be avoided
+	files := map[string][]byte{
+		"/huge.png":    pngHeader(50000, 50000),
+		"/largest.png": pngHeader(8192, 8192),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(files[r.URL.Path])
+	}))
+	defer server.Close()
+	p := NewImageProcessor(NewMediaStore(t.TempDir(), "https://sosyal.example.com/"), "")
+
+	_, err := p.Process(context.Background(), models.PlatformInstagram, server.URL+"/huge.png")
+	if err == nil || !strings.Contains(err.Error(), "megapixels") {
+		t.Errorf("Process(50000x50000) error = %v, want the image rejected by its size", err)
+	}
+
+	// Passes the size check, then fails to decode the missing pixels
+	_, err = p.Process(context.Background(), models.PlatformInstagram, server.URL+"/largest.png")
+	if err == nil || strings.Contains(err.Error(), "megapixels") {
+		t.Errorf("Process(8192x8192) error = %v, want a decoding error", err)
+	}
+}
+
+func TestImageProcessorCanProcess(t *testing.T) {
+	p := NewImageProcessor(nil, "")
+	tests := []struct {
+		probe *MediaProbeResult
+		want  bool
+	}{
+		{probe: nil, want: false},
+		{probe: &MediaProbeResult{Kind: MediaTypeImage, Format: "jpeg"}, want: true},
+		{probe: &MediaProbeResult{Kind: MediaTypeImage, Format: "png"}, want: true},
+		{probe: &MediaProbeResult{Kind: MediaTypeImage, Format: "gif"}, want: true},
+		{probe: &MediaProbeResult{Kind: MediaTypeImage, Format: "webp"}, want: false},
+		{probe: &MediaProbeResult{Kind: MediaTypeVideo, Format: "mp4"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := p.CanProcess(tt.probe); got != tt.want {
+			t.Errorf("CanProcess(%+v) = %v, want %v", tt.probe, got, tt.want)
+		}
+	}
+	if p.fitMode != ImageFitCrop {
+		t.Errorf("default fit mode = %q, want %q", p.fitMode, ImageFitCrop)
+	}
+}
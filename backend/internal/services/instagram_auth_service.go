@@ -1,11 +1,13 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -17,16 +19,25 @@ type InstagramAuthService struct {
 	appID       string
 	appSecret   string
 	redirectURI string
+	endpoints   InstagramEndpoints
 	httpClient  *http.Client
 }
 
+// InstagramEndpoints holds the base URLs of the Instagram APIs
+type InstagramEndpoints struct {
+	AuthBaseURL  string // Authorization page, e.g. https://www.instagram.com
+	APIBaseURL   string // Short-lived token exchange, e.g. https://api.instagram.com
+	GraphBaseURL string // Graph API, e.g. https://graph.instagram.com
+}
+
 // NewInstagramAuthService creates a new Instagram auth service
-func NewInstagramAuthService(appID, appSecret, redirectURI string) *InstagramAuthService {
+func NewInstagramAuthService(appID, appSecret, redirectURI string, endpoints InstagramEndpoints) *InstagramAuthService {
 	return &InstagramAuthService{
 		appID:       appID,
 		appSecret:   appSecret,
 		redirectURI: redirectURI,
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		endpoints:   endpoints,
+		httpClient:  newHTTPClient("instagram", 30*time.Second),
 	}
 }
 
@@ -92,11 +103,8 @@ func (s *InstagramAuthService) GenerateAuthURL() (*InstagramAuthResponse, error)
 	params.Set("response_type", "code")
 	params.Set("state", state)
 
-	fmt.Println(params)
-
-	authURL := fmt.Sprintf("https://www.instagram.com/oauth/authorize?%s", params.Encode())
-
-	fmt.Println(authURL)
+	// Not logged: the URL carries the OAuth state
+	authURL := fmt.Sprintf("%s/oauth/authorize?%s", s.endpoints.AuthBaseURL, params.Encode())
 
 	return &InstagramAuthResponse{
 		URL:   authURL,
@@ -105,8 +113,8 @@ func (s *InstagramAuthService) GenerateAuthURL() (*InstagramAuthResponse, error)
 }
 
 // ExchangeCodeForToken exchanges an authorization code for an access token
-func (s *InstagramAuthService) ExchangeCodeForToken(code string) (*InstagramTokenResponse, error) {
-	tokenURL := "https://api.instagram.com/oauth/access_token"
+func (s *InstagramAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*InstagramTokenResponse, error) {
+	tokenURL := s.endpoints.APIBaseURL + "/oauth/access_token"
 
 	params := url.Values{}
 	params.Set("client_id", s.appID)
@@ -115,7 +123,7 @@ func (s *InstagramAuthService) ExchangeCodeForToken(code string) (*InstagramToke
 	params.Set("grant_type", "authorization_code")
 	params.Set("code", code)
 
-	resp, err := s.httpClient.PostForm(tokenURL, params)
+	resp, err := postFormWithContext(ctx, s.httpClient, tokenURL, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
@@ -127,7 +135,7 @@ func (s *InstagramAuthService) ExchangeCodeForToken(code string) (*InstagramToke
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token exchange failed: %s", string(body))
+		return nil, instagramError(resp, body)
 	}
 
 	var tokenResp InstagramTokenResponse
@@ -147,11 +155,11 @@ type InstagramLongLivedTokenResponse struct {
 
 // ExchangeLongLivedToken exchanges a short-lived token for a long-lived token
 // Short-lived tokens expire in 1 hour, long-lived tokens last 60 days
-func (s *InstagramAuthService) ExchangeLongLivedToken(shortLivedToken string) (*InstagramLongLivedTokenResponse, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/access_token?grant_type=ig_exchange_token&client_secret=%s&access_token=%s",
-		s.appSecret, shortLivedToken)
+func (s *InstagramAuthService) ExchangeLongLivedToken(ctx context.Context, shortLivedToken string) (*InstagramLongLivedTokenResponse, error) {
+	apiURL := fmt.Sprintf("%s/access_token?grant_type=ig_exchange_token&client_secret=%s&access_token=%s",
+		s.endpoints.GraphBaseURL, s.appSecret, shortLivedToken)
 
-	resp, err := s.httpClient.Get(apiURL)
+	resp, err := getWithContext(ctx, s.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange for long-lived token: %w", err)
 	}
@@ -163,7 +171,7 @@ func (s *InstagramAuthService) ExchangeLongLivedToken(shortLivedToken string) (*
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("long-lived token exchange failed: %s", string(body))
+		return nil, instagramError(resp, body)
 	}
 
 	var tokenResp InstagramLongLivedTokenResponse
@@ -171,7 +179,7 @@ func (s *InstagramAuthService) ExchangeLongLivedToken(shortLivedToken string) (*
 		return nil, fmt.Errorf("failed to parse long-lived token response: %w", err)
 	}
 
-	fmt.Printf("DEBUG: Long-lived token obtained, expires in %d seconds (%.1f days)\n", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
+	log.Printf("Long-lived token obtained, expires in %d seconds (%.1f days)", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
 
 	return &tokenResp, nil
 }
@@ -179,11 +187,11 @@ func (s *InstagramAuthService) ExchangeLongLivedToken(shortLivedToken string) (*
 // RefreshLongLivedToken refreshes a long-lived token before it expires
 // Can only be refreshed if the token is at least 24 hours old and not expired
 // Returns a new long-lived token valid for 60 days
-func (s *InstagramAuthService) RefreshLongLivedToken(longLivedToken string) (*InstagramLongLivedTokenResponse, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/refresh_access_token?grant_type=ig_refresh_token&access_token=%s",
-		longLivedToken)
+func (s *InstagramAuthService) RefreshLongLivedToken(ctx context.Context, longLivedToken string) (*InstagramLongLivedTokenResponse, error) {
+	apiURL := fmt.Sprintf("%s/refresh_access_token?grant_type=ig_refresh_token&access_token=%s",
+		s.endpoints.GraphBaseURL, longLivedToken)
 
-	resp, err := s.httpClient.Get(apiURL)
+	resp, err := getWithContext(ctx, s.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh long-lived token: %w", err)
 	}
@@ -195,7 +203,7 @@ func (s *InstagramAuthService) RefreshLongLivedToken(longLivedToken string) (*In
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed: %s", string(body))
+		return nil, instagramError(resp, body)
 	}
 
 	var tokenResp InstagramLongLivedTokenResponse
@@ -203,18 +211,18 @@ func (s *InstagramAuthService) RefreshLongLivedToken(longLivedToken string) (*In
 		return nil, fmt.Errorf("failed to parse refresh token response: %w", err)
 	}
 
-	fmt.Printf("DEBUG: Token refreshed, new expiry in %d seconds (%.1f days)\n", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
+	log.Printf("Token refreshed, new expiry in %d seconds (%.1f days)", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
 
 	return &tokenResp, nil
 }
 
 // GetInstagramUserInfo retrieves Instagram user information using the access token
-func (s *InstagramAuthService) GetInstagramUserInfo(accessToken string) (*InstagramUserInfoResponse, error) {
+func (s *InstagramAuthService) GetInstagramUserInfo(ctx context.Context, accessToken string) (*InstagramUserInfoResponse, error) {
 	// Use "me" endpoint to get current user info
-	apiURL := fmt.Sprintf("https://graph.instagram.com/me?fields=id,username,account_type&access_token=%s",
-		accessToken)
+	apiURL := fmt.Sprintf("%s/me?fields=id,username,account_type&access_token=%s",
+		s.endpoints.GraphBaseURL, accessToken)
 
-	resp, err := s.httpClient.Get(apiURL)
+	resp, err := getWithContext(ctx, s.httpClient, apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -226,7 +234,7 @@ func (s *InstagramAuthService) GetInstagramUserInfo(accessToken string) (*Instag
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get user info failed: %s", string(body))
+		return nil, instagramError(resp, body)
 	}
 
 	var userInfo InstagramUserInfoResponse
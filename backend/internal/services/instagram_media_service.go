@@ -1,23 +1,84 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
+// instagramStatusCheckInterval is how often a media container's status is checked; tests shorten it
+var instagramStatusCheckInterval = 5 * time.Second
+
+// containerAPI names the parts of the container publishing flow of a Graph API
+// Instagram and Threads both create a container, wait for it to be processed and publish it,
+// but call the edges and fields differently
+type containerAPI struct {
+	platform      models.Platform
+	containerEdge string // Edge of the user that creates containers
+	publishEdge   string // Edge of the user that publishes a container
+	statusField   string // Field with the processing status of a container
+	textParam     string // Parameter with the text of a post
+	imageType     string // media_type of image containers; Instagram leaves it out
+	maxCarousel   int    // Most items in a carousel
+	classify      func(resp *http.Response, body []byte) error
+}
+
+// instagramContainerAPI is the container flow of the Instagram Graph API
+var instagramContainerAPI = containerAPI{
+	platform:      models.PlatformInstagram,
+	containerEdge: "media",
+	publishEdge:   "media_publish",
+	statusField:   "status_code",
+	textParam:     "caption",
+	maxCarousel:   10,
+	classify:      instagramError,
+}
+
+// threadsContainerAPI is the container flow of the Threads API
+// https://developers.facebook.com/docs/threads/posts
+var threadsContainerAPI = containerAPI{
+	platform:      models.PlatformThreads,
+	containerEdge: "threads",
+	publishEdge:   "threads_publish",
+	statusField:   "status",
+	textParam:     "text",
+	imageType:     "IMAGE",
+	maxCarousel:   20,
+	classify:      threadsError,
+}
+
 // InstagramMediaService handles Instagram media upload and publishing
+// The same container flow publishes to Threads, see NewThreadsMediaService
 type InstagramMediaService struct {
-	httpClient *http.Client
+	graphBaseURL string // e.g. https://graph.instagram.com
+	httpClient   *http.Client
+	api          containerAPI
 }
 
 // NewInstagramMediaService creates a new Instagram media service
-func NewInstagramMediaService() *InstagramMediaService {
+func NewInstagramMediaService(graphBaseURL string) *InstagramMediaService {
+	return &InstagramMediaService{
+		graphBaseURL: graphBaseURL,
+		httpClient:   newHTTPClient("instagram", 60*time.Second),
+		api:          instagramContainerAPI,
+	}
+}
+
+// NewThreadsMediaService creates a media service that creates, processes and publishes
+// containers through the Threads API at graphBaseURL, e.g. https://graph.threads.net
+func NewThreadsMediaService(graphBaseURL string) *InstagramMediaService {
 	return &InstagramMediaService{
-		httpClient: &http.Client{Timeout: 60 * time.Second},
+		graphBaseURL: graphBaseURL + "/" + threadsAPIVersion,
+		httpClient:   newHTTPClient("threads", 60*time.Second),
+		api:          threadsContainerAPI,
 	}
 }
 
@@ -29,7 +90,8 @@ type CreateMediaContainerResponse struct {
 // MediaStatusResponse represents the status of a media container
 type MediaStatusResponse struct {
 	ID           string `json:"id"`
-	StatusCode   string `json:"status_code"`
+	StatusCode   string `json:"status_code"`             // Instagram
+	Status       string `json:"status,omitempty"`        // Threads
 	ErrorMessage string `json:"error_message,omitempty"` // Error details when status is ERROR
 }
 
@@ -47,26 +109,42 @@ type PermalinkResponse struct {
 // CreateMediaContainer creates a container for Instagram Reels/Stories
 // This is step 1 of the publishing process
 func (s *InstagramMediaService) CreateMediaContainer(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	videoURL string,
 	caption string,
-	mediaType string, // "REELS" or "STORIES"
+	mediaType string, // "REELS" or "STORIES"; "VIDEO" on Threads
 ) (string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s/media", igUserID)
-
 	params := url.Values{}
 	params.Set("media_type", mediaType)
 	params.Set("video_url", videoURL)
 	if caption != "" {
-		params.Set("caption", caption)
+		params.Set(s.api.textParam, caption)
 	}
-	params.Set("access_token", accessToken)
 
-	resp, err := s.httpClient.PostForm(apiURL, params)
+	containerID, err := s.CreateContainer(ctx, accessToken, igUserID, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to create media container: %w", err)
 	}
+	return containerID, nil
+}
+
+// CreateContainer creates a container with the given parameters, adding the access token
+// The other Create*Container methods build their parameters and call it
+func (s *InstagramMediaService) CreateContainer(ctx context.Context, accessToken string, userID string, params url.Values) (string, error) {
+	apiURL := fmt.Sprintf("%s/%s/%s", s.graphBaseURL, userID, s.api.containerEdge)
+
+	form := url.Values{}
+	for key, values := range params {
+		form[key] = values
+	}
+	form.Set("access_token", accessToken)
+
+	resp, err := postFormWithContext(ctx, s.httpClient, apiURL, form)
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
@@ -75,13 +153,16 @@ func (s *InstagramMediaService) CreateMediaContainer(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("create media container failed (%d): %s", resp.StatusCode, string(body))
+		return "", s.api.classify(resp, body)
 	}
 
 	var containerResp CreateMediaContainerResponse
 	if err := json.Unmarshal(body, &containerResp); err != nil {
 		return "", fmt.Errorf("failed to parse container response: %w", err)
 	}
+	if containerResp.ID == "" {
+		return "", fmt.Errorf("container response has no ID")
+	}
 
 	return containerResp.ID, nil
 }
@@ -89,50 +170,35 @@ func (s *InstagramMediaService) CreateMediaContainer(
 // CreatePhotoContainer creates a container for Instagram photo posts
 // Photos are processed immediately and don't require status polling
 func (s *InstagramMediaService) CreatePhotoContainer(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	imageURL string,
 	caption string,
 ) (string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s/media", igUserID)
-
 	params := url.Values{}
 	params.Set("image_url", imageURL)
 	if caption != "" {
-		params.Set("caption", caption)
+		params.Set(s.api.textParam, caption)
 	}
-	params.Set("access_token", accessToken)
-
-	resp, err := s.httpClient.PostForm(apiURL, params)
-	if err != nil {
-		return "", fmt.Errorf("failed to create photo container: %w", err)
+	if s.api.imageType != "" {
+		params.Set("media_type", s.api.imageType)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	containerID, err := s.CreateContainer(ctx, accessToken, igUserID, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("create photo container failed (%d): %s", resp.StatusCode, string(body))
-	}
-
-	var containerResp CreateMediaContainerResponse
-	if err := json.Unmarshal(body, &containerResp); err != nil {
-		return "", fmt.Errorf("failed to parse container response: %w", err)
+		return "", fmt.Errorf("failed to create photo container: %w", err)
 	}
-
-	return containerResp.ID, nil
+	return containerID, nil
 }
 
 // CheckMediaStatus checks the upload status of a media container
 // Returns the status code (FINISHED, IN_PROGRESS, ERROR, etc.) and error message if any
-func (s *InstagramMediaService) CheckMediaStatus(accessToken string, containerID string) (string, string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s?fields=status_code,error_message&access_token=%s",
-		containerID, accessToken)
+func (s *InstagramMediaService) CheckMediaStatus(ctx context.Context, accessToken string, containerID string) (string, string, error) {
+	apiURL := fmt.Sprintf("%s/%s?fields=%s,error_message&access_token=%s",
+		s.graphBaseURL, containerID, s.api.statusField, accessToken)
 
-	resp, err := s.httpClient.Get(apiURL)
+	resp, err := getWithContext(ctx, s.httpClient, apiURL)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to check media status: %w", err)
 	}
@@ -144,7 +210,7 @@ func (s *InstagramMediaService) CheckMediaStatus(accessToken string, containerID
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("check media status failed (%d): %s", resp.StatusCode, string(body))
+		return "", "", s.api.classify(resp, body)
 	}
 
 	var statusResp MediaStatusResponse
@@ -152,25 +218,29 @@ func (s *InstagramMediaService) CheckMediaStatus(accessToken string, containerID
 		return "", "", fmt.Errorf("failed to parse status response: %w", err)
 	}
 
+	if statusResp.StatusCode == "" {
+		return statusResp.Status, statusResp.ErrorMessage, nil
+	}
 	return statusResp.StatusCode, statusResp.ErrorMessage, nil
 }
 
 // WaitForMediaProcessing polls the media status until it's finished or times out
 func (s *InstagramMediaService) WaitForMediaProcessing(
+	ctx context.Context,
 	accessToken string,
 	containerID string,
 	maxWaitSeconds int,
 ) (bool, error) {
 	startTime := time.Now()
-	checkInterval := 5 * time.Second
 
 	for {
 		// Check if we've exceeded max wait time
 		if time.Since(startTime).Seconds() > float64(maxWaitSeconds) {
-			return false, fmt.Errorf("media processing timeout after %d seconds", maxWaitSeconds)
+			return false, platformapi.NewPlatformError(s.api.platform, platformapi.ErrorCodeTransient, 0, "",
+				fmt.Sprintf("media processing timeout after %d seconds", maxWaitSeconds))
 		}
 
-		status, errorMsg, err := s.CheckMediaStatus(accessToken, containerID)
+		status, errorMsg, err := s.CheckMediaStatus(ctx, accessToken, containerID)
 		if err != nil {
 			return false, err
 		}
@@ -179,16 +249,18 @@ func (s *InstagramMediaService) WaitForMediaProcessing(
 		case "FINISHED":
 			return true, nil
 		case "ERROR":
-			if errorMsg != "" {
-				return false, fmt.Errorf("Instagram media processing failed: %s", errorMsg)
+			if errorMsg == "" {
+				errorMsg = "media processing failed with status ERROR"
 			}
-			return false, fmt.Errorf("Instagram media processing failed with status: ERROR")
-		case "IN_PROGRESS":
-			// Continue waiting
-			time.Sleep(checkInterval)
-		default:
-			// Unknown status, continue waiting
-			time.Sleep(checkInterval)
+			return false, platformapi.NewPlatformError(s.api.platform, platformapi.ErrorCodeMediaRejected, 0, "ERROR", errorMsg)
+		case "EXPIRED":
+			// Containers that are not published within 24 hours expire; a retry creates a new one
+			return false, platformapi.NewPlatformError(s.api.platform, platformapi.ErrorCodeTransient, 0, "EXPIRED", "media container expired before it was published")
+		}
+
+		// IN_PROGRESS or an unknown status: keep waiting
+		if err := sleepContext(ctx, instagramStatusCheckInterval); err != nil {
+			return false, fmt.Errorf("stopped waiting for media processing: %w", err)
 		}
 	}
 }
@@ -196,17 +268,18 @@ func (s *InstagramMediaService) WaitForMediaProcessing(
 // PublishMedia publishes a media container to Instagram
 // This is step 3 of the publishing process (after creation and processing)
 func (s *InstagramMediaService) PublishMedia(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	containerID string,
 ) (string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s/media_publish", igUserID)
+	apiURL := fmt.Sprintf("%s/%s/%s", s.graphBaseURL, igUserID, s.api.publishEdge)
 
 	params := url.Values{}
 	params.Set("creation_id", containerID)
 	params.Set("access_token", accessToken)
 
-	resp, err := s.httpClient.PostForm(apiURL, params)
+	resp, err := postFormWithContext(ctx, s.httpClient, apiURL, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to publish media: %w", err)
 	}
@@ -218,7 +291,7 @@ func (s *InstagramMediaService) PublishMedia(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("publish media failed (%d): %s", resp.StatusCode, string(body))
+		return "", s.api.classify(resp, body)
 	}
 
 	var publishResp PublishMediaResponse
@@ -230,11 +303,11 @@ func (s *InstagramMediaService) PublishMedia(
 }
 
 // GetPermalink retrieves the permalink (share URL) for a published media
-func (s *InstagramMediaService) GetPermalink(accessToken string, mediaID string) (string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s?fields=permalink&access_token=%s",
-		mediaID, accessToken)
+func (s *InstagramMediaService) GetPermalink(ctx context.Context, accessToken string, mediaID string) (string, error) {
+	apiURL := fmt.Sprintf("%s/%s?fields=permalink&access_token=%s",
+		s.graphBaseURL, mediaID, accessToken)
 
-	resp, err := s.httpClient.Get(apiURL)
+	resp, err := getWithContext(ctx, s.httpClient, apiURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to get permalink: %w", err)
 	}
@@ -246,7 +319,7 @@ func (s *InstagramMediaService) GetPermalink(accessToken string, mediaID string)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("get permalink failed (%d): %s", resp.StatusCode, string(body))
+		return "", s.api.classify(resp, body)
 	}
 
 	var permalinkResp PermalinkResponse
@@ -259,19 +332,20 @@ func (s *InstagramMediaService) GetPermalink(accessToken string, mediaID string)
 
 // UploadAndPublishReel is a complete flow for uploading and publishing a reel
 func (s *InstagramMediaService) UploadAndPublishReel(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	videoURL string,
 	caption string,
 ) (string, string, error) {
 	// Step 1: Create media container
-	containerID, err := s.CreateMediaContainer(accessToken, igUserID, videoURL, caption, "REELS")
+	containerID, err := s.CreateMediaContainer(ctx, accessToken, igUserID, videoURL, caption, "REELS")
 	if err != nil {
 		return "", "", fmt.Errorf("create container failed: %w", err)
 	}
 
 	// Step 2: Wait for processing (max 5 minutes)
-	success, err := s.WaitForMediaProcessing(accessToken, containerID, 300)
+	success, err := s.WaitForMediaProcessing(ctx, accessToken, containerID, 300)
 	if err != nil {
 		return "", "", fmt.Errorf("processing failed: %w", err)
 	}
@@ -280,13 +354,13 @@ func (s *InstagramMediaService) UploadAndPublishReel(
 	}
 
 	// Step 3: Publish
-	mediaID, err := s.PublishMedia(accessToken, igUserID, containerID)
+	mediaID, err := s.PublishMedia(ctx, accessToken, igUserID, containerID)
 	if err != nil {
 		return "", "", fmt.Errorf("publish failed: %w", err)
 	}
 
 	// Step 4: Get permalink
-	permalink, err := s.GetPermalink(accessToken, mediaID)
+	permalink, err := s.GetPermalink(ctx, accessToken, mediaID)
 	if err != nil {
 		// Don't fail if we can't get permalink, just return empty string
 		permalink = ""
@@ -298,20 +372,21 @@ func (s *InstagramMediaService) UploadAndPublishReel(
 // UploadAndPublishPhoto is a complete flow for uploading and publishing a photo
 // Photos don't require async processing like videos do
 func (s *InstagramMediaService) UploadAndPublishPhoto(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	imageURL string,
 	caption string,
 ) (string, string, error) {
 	// Step 1: Create photo container
-	containerID, err := s.CreatePhotoContainer(accessToken, igUserID, imageURL, caption)
+	containerID, err := s.CreatePhotoContainer(ctx, accessToken, igUserID, imageURL, caption)
 	if err != nil {
 		return "", "", fmt.Errorf("create photo container failed: %w", err)
 	}
 
 	// Step 2: Wait briefly for container to be ready (photos process quickly)
 	// Instagram recommends checking status even for photos
-	success, err := s.WaitForMediaProcessing(accessToken, containerID, 60)
+	success, err := s.WaitForMediaProcessing(ctx, accessToken, containerID, 60)
 	if err != nil {
 		return "", "", fmt.Errorf("photo processing failed: %w", err)
 	}
@@ -320,13 +395,13 @@ func (s *InstagramMediaService) UploadAndPublishPhoto(
 	}
 
 	// Step 3: Publish
-	mediaID, err := s.PublishMedia(accessToken, igUserID, containerID)
+	mediaID, err := s.PublishMedia(ctx, accessToken, igUserID, containerID)
 	if err != nil {
 		return "", "", fmt.Errorf("publish failed: %w", err)
 	}
 
 	// Step 4: Get permalink
-	permalink, err := s.GetPermalink(accessToken, mediaID)
+	permalink, err := s.GetPermalink(ctx, accessToken, mediaID)
 	if err != nil {
 		// Don't fail if we can't get permalink, just return empty string
 		permalink = ""
@@ -338,103 +413,78 @@ func (s *InstagramMediaService) UploadAndPublishPhoto(
 // CreateCarouselItemContainer creates a container for a single item in a carousel
 // This marks the media as a carousel item (is_carousel_item=true)
 func (s *InstagramMediaService) CreateCarouselItemContainer(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	mediaURL string,
 	isVideo bool,
 ) (string, error) {
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s/media", igUserID)
-
 	params := url.Values{}
 	params.Set("is_carousel_item", "true")
-	params.Set("access_token", accessToken)
 
 	if isVideo {
 		params.Set("media_type", "VIDEO")
 		params.Set("video_url", mediaURL)
 	} else {
 		params.Set("image_url", mediaURL)
+		if s.api.imageType != "" {
+			params.Set("media_type", s.api.imageType)
+		}
 	}
 
-	resp, err := s.httpClient.PostForm(apiURL, params)
+	containerID, err := s.CreateContainer(ctx, accessToken, igUserID, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to create carousel item container: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("create carousel item container failed (%d): %s", resp.StatusCode, string(body))
-	}
-
-	var containerResp CreateMediaContainerResponse
-	if err := json.Unmarshal(body, &containerResp); err != nil {
-		return "", fmt.Errorf("failed to parse container response: %w", err)
-	}
-
-	return containerResp.ID, nil
+	return containerID, nil
 }
 
 // CreateCarouselContainer creates a carousel container with multiple children
 // This is the main container that references all the individual item containers
 func (s *InstagramMediaService) CreateCarouselContainer(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	childrenIDs []string,
 	caption string,
+) (string, error) {
+	return s.createCarouselContainer(ctx, accessToken, igUserID, childrenIDs, caption, nil)
+}
+
+// createCarouselContainer creates a carousel container, adding extra to its parameters
+func (s *InstagramMediaService) createCarouselContainer(
+	ctx context.Context,
+	accessToken string,
+	userID string,
+	childrenIDs []string,
+	caption string,
+	extra url.Values,
 ) (string, error) {
 	if len(childrenIDs) < 2 {
 		return "", fmt.Errorf("carousel requires at least 2 items, got %d", len(childrenIDs))
 	}
-	if len(childrenIDs) > 10 {
-		return "", fmt.Errorf("carousel supports maximum 10 items, got %d", len(childrenIDs))
+	if len(childrenIDs) > s.api.maxCarousel {
+		return "", fmt.Errorf("carousel supports maximum %d items, got %d", s.api.maxCarousel, len(childrenIDs))
 	}
 
-	apiURL := fmt.Sprintf("https://graph.instagram.com/%s/media", igUserID)
-
 	params := url.Values{}
+	for key, values := range extra {
+		params[key] = values
+	}
 	params.Set("media_type", "CAROUSEL")
-	params.Set("access_token", accessToken)
 
 	if caption != "" {
-		params.Set("caption", caption)
+		params.Set(s.api.textParam, caption)
 	}
 
 	// Children is a comma-separated list of container IDs
-	childrenStr := ""
-	for i, id := range childrenIDs {
-		if i > 0 {
-			childrenStr += ","
-		}
-		childrenStr += id
-	}
-	params.Set("children", childrenStr)
+	params.Set("children", strings.Join(childrenIDs, ","))
 
-	resp, err := s.httpClient.PostForm(apiURL, params)
+	containerID, err := s.CreateContainer(ctx, accessToken, userID, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to create carousel container: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("create carousel container failed (%d): %s", resp.StatusCode, string(body))
-	}
-
-	var containerResp CreateMediaContainerResponse
-	if err := json.Unmarshal(body, &containerResp); err != nil {
-		return "", fmt.Errorf("failed to parse container response: %w", err)
-	}
-
-	return containerResp.ID, nil
+	return containerID, nil
 }
 
 // MediaItem represents a single media item in a carousel
@@ -445,6 +495,7 @@ type MediaItem struct {
 
 // UploadAndPublishCarousel is a complete flow for uploading and publishing a carousel
 func (s *InstagramMediaService) UploadAndPublishCarousel(
+	ctx context.Context,
 	accessToken string,
 	igUserID string,
 	mediaItems []MediaItem,
@@ -453,14 +504,14 @@ func (s *InstagramMediaService) UploadAndPublishCarousel(
 	if len(mediaItems) < 2 {
 		return "", "", fmt.Errorf("carousel requires at least 2 items")
 	}
-	if len(mediaItems) > 10 {
-		return "", "", fmt.Errorf("carousel supports maximum 10 items")
+	if len(mediaItems) > s.api.maxCarousel {
+		return "", "", fmt.Errorf("carousel supports maximum %d items", s.api.maxCarousel)
 	}
 
 	// Step 1: Create individual containers for each media item
 	var childrenIDs []string
 	for i, item := range mediaItems {
-		containerID, err := s.CreateCarouselItemContainer(accessToken, igUserID, item.URL, item.IsVideo)
+		containerID, err := s.CreateCarouselItemContainer(ctx, accessToken, igUserID, item.URL, item.IsVideo)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to create container for item %d: %w", i, err)
 		}
@@ -468,7 +519,7 @@ func (s *InstagramMediaService) UploadAndPublishCarousel(
 
 		// Wait for each video item to process before creating carousel container
 		if item.IsVideo {
-			success, err := s.WaitForMediaProcessing(accessToken, containerID, 300)
+			success, err := s.WaitForMediaProcessing(ctx, accessToken, containerID, 300)
 			if err != nil {
 				return "", "", fmt.Errorf("processing failed for video item %d: %w", i, err)
 			}
@@ -479,13 +530,13 @@ func (s *InstagramMediaService) UploadAndPublishCarousel(
 	}
 
 	// Step 2: Create the carousel container with all children
-	carouselID, err := s.CreateCarouselContainer(accessToken, igUserID, childrenIDs, caption)
+	carouselID, err := s.CreateCarouselContainer(ctx, accessToken, igUserID, childrenIDs, caption)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create carousel container: %w", err)
 	}
 
 	// Step 3: Wait for carousel to be ready
-	success, err := s.WaitForMediaProcessing(accessToken, carouselID, 300)
+	success, err := s.WaitForMediaProcessing(ctx, accessToken, carouselID, 300)
 	if err != nil {
 		return "", "", fmt.Errorf("carousel processing failed: %w", err)
 	}
@@ -494,13 +545,13 @@ func (s *InstagramMediaService) UploadAndPublishCarousel(
 	}
 
 	// Step 4: Publish
-	mediaID, err := s.PublishMedia(accessToken, igUserID, carouselID)
+	mediaID, err := s.PublishMedia(ctx, accessToken, igUserID, carouselID)
 	if err != nil {
 		return "", "", fmt.Errorf("publish failed: %w", err)
 	}
 
 	// Step 5: Get permalink
-	permalink, err := s.GetPermalink(accessToken, mediaID)
+	permalink, err := s.GetPermalink(ctx, accessToken, mediaID)
 	if err != nil {
 		// Don't fail if we can't get permalink, just return empty string
 		permalink = ""
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 )
@@ -20,9 +21,9 @@ func NewInstagramPostService(authService *InstagramAuthService, mediaService *In
 }
 
 // CreatePost creates and publishes a post to Instagram (video as Reel)
-func (s *InstagramPostService) CreatePost(accessToken string, videoURL string, caption string) (string, string, error) {
+func (s *InstagramPostService) CreatePost(ctx context.Context, accessToken string, videoURL string, caption string) (string, string, error) {
 	// Get Instagram user info (need IG user ID for posting)
-	userInfo, err := s.authService.GetInstagramUserInfo(accessToken)
+	userInfo, err := s.authService.GetInstagramUserInfo(ctx, accessToken)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get Instagram user info: %w", err)
 	}
@@ -31,6 +32,7 @@ func (s *InstagramPostService) CreatePost(accessToken string, videoURL string, c
 
 	// Upload and publish the reel
 	mediaID, permalink, err := s.mediaService.UploadAndPublishReel(
+		ctx,
 		accessToken,
 		userInfo.ID,
 		videoURL,
@@ -44,9 +46,9 @@ func (s *InstagramPostService) CreatePost(accessToken string, videoURL string, c
 }
 
 // CreatePhotoPost creates and publishes a photo post to Instagram
-func (s *InstagramPostService) CreatePhotoPost(accessToken string, imageURL string, caption string) (string, string, error) {
+func (s *InstagramPostService) CreatePhotoPost(ctx context.Context, accessToken string, imageURL string, caption string) (string, string, error) {
 	// Get Instagram user info (need IG user ID for posting)
-	userInfo, err := s.authService.GetInstagramUserInfo(accessToken)
+	userInfo, err := s.authService.GetInstagramUserInfo(ctx, accessToken)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get Instagram user info: %w", err)
 	}
@@ -55,6 +57,7 @@ func (s *InstagramPostService) CreatePhotoPost(accessToken string, imageURL stri
 
 	// Upload and publish the photo
 	mediaID, permalink, err := s.mediaService.UploadAndPublishPhoto(
+		ctx,
 		accessToken,
 		userInfo.ID,
 		imageURL,
@@ -69,7 +72,7 @@ func (s *InstagramPostService) CreatePhotoPost(accessToken string, imageURL stri
 
 // CreateCarouselPost creates and publishes a carousel post to Instagram
 // Requires at least 2 media items and at most 10
-func (s *InstagramPostService) CreateCarouselPost(accessToken string, mediaItems []MediaItem, caption string) (string, string, error) {
+func (s *InstagramPostService) CreateCarouselPost(ctx context.Context, accessToken string, mediaItems []MediaItem, caption string) (string, string, error) {
 	if len(mediaItems) < 2 {
 		return "", "", fmt.Errorf("carousel requires at least 2 items, got %d", len(mediaItems))
 	}
@@ -78,7 +81,7 @@ func (s *InstagramPostService) CreateCarouselPost(accessToken string, mediaItems
 	}
 
 	// Get Instagram user info (need IG user ID for posting)
-	userInfo, err := s.authService.GetInstagramUserInfo(accessToken)
+	userInfo, err := s.authService.GetInstagramUserInfo(ctx, accessToken)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get Instagram user info: %w", err)
 	}
@@ -87,6 +90,7 @@ func (s *InstagramPostService) CreateCarouselPost(accessToken string, mediaItems
 
 	// Upload and publish the carousel
 	mediaID, permalink, err := s.mediaService.UploadAndPublishCarousel(
+		ctx,
 		accessToken,
 		userInfo.ID,
 		mediaItems,
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// linkedinAPI sends requests to the versioned LinkedIn REST API (/rest/...)
+// Every request carries the LinkedIn-Version and Rest.li protocol headers the API requires
+type linkedinAPI struct {
+	baseURL    string // e.g. https://api.linkedin.com
+	version    string // YYYYMM
+	httpClient *http.Client
+}
+
+// do sends a request with an optional JSON payload and decodes a JSON response into out
+// Returns the response headers; LinkedIn returns the ID of created entities in x-restli-id
+func (a *linkedinAPI) do(ctx context.Context, method, path, accessToken string, payload, out interface{}) (http.Header, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("LinkedIn-Version", a.version)
+	req.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, linkedinError(resp, responseBody)
+	}
+
+	if out != nil && len(responseBody) > 0 {
+		if err := json.Unmarshal(responseBody, out); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// linkedinEntityPath returns the path of an entity of a REST resource, e.g. /rest/videos/{urn}
+// URNs contain colons, which Rest.li requires to be escaped in paths
+func linkedinEntityPath(resource, urn string) string {
+	return "/rest/" + resource + "/" + strings.ReplaceAll(url.PathEscape(urn), ":", "%3A")
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LinkedInAuthService handles LinkedIn 3-legged OAuth and member profiles
+type LinkedInAuthService struct {
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	scopes       []string
+	authBaseURL  string // Authorization page and token exchange, e.g. https://www.linkedin.com
+	apiBaseURL   string // Userinfo API, e.g. https://api.linkedin.com
+	httpClient   *http.Client
+}
+
+// NewLinkedInAuthService creates a new LinkedIn auth service
+func NewLinkedInAuthService(clientID, clientSecret, redirectURI string, scopes []string, authBaseURL, apiBaseURL string) *LinkedInAuthService {
+	return &LinkedInAuthService{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		scopes:       scopes,
+		authBaseURL:  authBaseURL,
+		apiBaseURL:   apiBaseURL,
+		httpClient:   newHTTPClient("linkedin", 30*time.Second),
+	}
+}
+
+// LinkedInTokenResponse represents the OAuth token response from LinkedIn
+// Refresh tokens are only issued to apps approved for programmatic refresh
+type LinkedInTokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope                 string `json:"scope"`
+}
+
+// LinkedInUserInfo is the member profile returned by the OpenID Connect userinfo endpoint
+type LinkedInUserInfo struct {
+	Sub     string `json:"sub"` // Member ID, the person URN is urn:li:person:{sub}
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+	Email   string `json:"email"`
+}
+
+// PersonURN returns the URN that identifies the member as the author of posts and owner of media
+func (u *LinkedInUserInfo) PersonURN() string {
+	return "urn:li:person:" + u.Sub
+}
+
+// GenerateAuthURL creates the OAuth authorization URL and the state that protects it
+func (s *LinkedInAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", s.clientID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("state", state)
+	params.Set("scope", strings.Join(s.scopes, " "))
+
+	return s.authBaseURL + "/oauth/v2/authorization?" + params.Encode(), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for an access token
+func (s *LinkedInAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*LinkedInTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "authorization_code")
+	formData.Set("code", code)
+	formData.Set("redirect_uri", s.redirectURI)
+	return s.requestToken(ctx, formData)
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token
+func (s *LinkedInAuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*LinkedInTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("refresh_token", refreshToken)
+	return s.requestToken(ctx, formData)
+}
+
+// requestToken posts a grant with the client credentials to the token endpoint
+func (s *LinkedInAuthService) requestToken(ctx context.Context, formData url.Values) (*LinkedInTokenResponse, error) {
+	formData.Set("client_id", s.clientID)
+	formData.Set("client_secret", s.clientSecret)
+
+	resp, err := postFormWithContext(ctx, s.httpClient, s.authBaseURL+"/oauth/v2/accessToken", formData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, linkedinError(resp, body)
+	}
+
+	var tokenResp LinkedInTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	return &tokenResp, nil
+}
+
+// GetUserInfo fetches the profile of the member who authorized the app
+func (s *LinkedInAuthService) GetUserInfo(ctx context.Context, accessToken string) (*LinkedInUserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.apiBaseURL+"/v2/userinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, linkedinError(resp, body)
+	}
+
+	var userInfo LinkedInUserInfo
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse user info: %w", err)
+	}
+	if userInfo.Sub == "" {
+		return nil, fmt.Errorf("user info response has no member ID")
+	}
+
+	return &userInfo, nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// linkedinStatusCheckInterval is how often the processing status of uploaded media is checked; tests shorten it
+var linkedinStatusCheckInterval = 5 * time.Second
+
+// Statuses of LinkedIn images and videos
+const (
+	linkedinMediaAvailable        = "AVAILABLE"
+	linkedinMediaProcessingFailed = "PROCESSING_FAILED"
+)
+
+// LinkedInMediaService uploads images and videos through the LinkedIn Images and Videos APIs
+// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/images-api
+// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/videos-api
+type LinkedInMediaService struct {
+	api            *linkedinAPI
+	uploadClient   *http.Client // Uploads to the pre-signed upload URLs, which take longer than API calls
+	downloadClient *http.Client
+}
+
+// NewLinkedInMediaService creates a new LinkedIn media service for the given REST API version
+func NewLinkedInMediaService(apiBaseURL, apiVersion string) *LinkedInMediaService {
+	return &LinkedInMediaService{
+		api: &linkedinAPI{
+			baseURL:    apiBaseURL,
+			version:    apiVersion,
+			httpClient: newHTTPClient("linkedin", 30*time.Second),
+		},
+		uploadClient:   newHTTPClient("linkedin", 5*time.Minute),
+		downloadClient: newHTTPClient("linkedin-media-download", 5*time.Minute),
+	}
+}
+
+// LinkedInMedia is a media file uploaded to LinkedIn
+type LinkedInMedia struct {
+	URN  string    // urn:li:image:... or urn:li:video:...
+	Kind MediaType // image or video
+}
+
+// linkedinImageUpload is the response of POST /rest/images?action=initializeUpload
+type linkedinImageUpload struct {
+	Value struct {
+		UploadURL string `json:"uploadUrl"`
+		Image     string `json:"image"`
+	} `json:"value"`
+}
+
+// linkedinVideoUpload is the response of POST /rest/videos?action=initializeUpload
+// Large videos are split into parts, each uploaded to its own URL
+type linkedinVideoUpload struct {
+	Value struct {
+		Video              string `json:"video"`
+		UploadToken        string `json:"uploadToken"`
+		UploadInstructions []struct {
+			UploadURL string `json:"uploadUrl"`
+			FirstByte int64  `json:"firstByte"`
+			LastByte  int64  `json:"lastByte"`
+		} `json:"uploadInstructions"`
+	} `json:"value"`
+}
+
+// linkedinMediaStatus is the processing status of an image or video
+type linkedinMediaStatus struct {
+	Status                  string `json:"status"`
+	ProcessingFailureReason string `json:"processingFailureReason"`
+}
+
+// linkedinDownload is media downloaded to a temp file; LinkedIn needs the size of videos up front
+type linkedinDownload struct {
+	path     string
+	size     int64
+	mimeType string
+}
+
+// Upload downloads the media at mediaURL, uploads it as an image or video owned by owner
+// (a person or organization URN) and waits until LinkedIn has processed it
+func (s *LinkedInMediaService) Upload(ctx context.Context, accessToken, owner, mediaURL string, progress platformapi.UploadProgressFunc) (*LinkedInMedia, error) {
+	download, err := s.download(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(download.path)
+
+	media := &LinkedInMedia{Kind: MediaTypeFromMIME(download.mimeType)}
+	resource := "images"
+	switch media.Kind {
+	case MediaTypeImage:
+		media.URN, err = s.uploadImage(ctx, accessToken, owner, download)
+	case MediaTypeVideo:
+		resource = "videos"
+		media.URN, err = s.uploadVideo(ctx, accessToken, owner, download, progress)
+	default:
+		return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported media type %q", download.mimeType))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if progress != nil {
+		progress(platformapi.UploadStageProcessing, 0)
+	}
+	if err := s.waitUntilAvailable(ctx, accessToken, resource, media.URN); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(platformapi.UploadStageProcessing, 100)
+	}
+
+	log.Printf("LinkedIn %s uploaded: %s", media.Kind, media.URN)
+	return media, nil
+}
+
+// download saves the media at mediaURL to a temp file and detects its type
+func (s *LinkedInMediaService) download(ctx context.Context, mediaURL string) (*linkedinDownload, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download media: status %d", resp.StatusCode))
+	}
+
+	out, err := os.CreateTemp("", "linkedin-media-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("failed to save media: %w", err)
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := out.ReadAt(header, 0)
+
+	// Trust the bytes first, then the Content-Type header, then the URL's extension
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	if mimeType == "" {
+		switch DetectMediaTypeFromURL(mediaURL) {
+		case MediaTypeImage:
+			mimeType = "image/jpeg"
+		case MediaTypeVideo:
+			mimeType = "video/mp4"
+		}
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformLinkedIn, MediaTypeFromMIME(mimeType)); limit > 0 && size > limit {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("media is %.1f MB, LinkedIn allows at most %.1f MB", megabytes(size), megabytes(limit)))
+	}
+
+	return &linkedinDownload{path: out.Name(), size: size, mimeType: mimeType}, nil
+}
+
+// uploadImage registers an image upload and sends the image to the returned upload URL
+func (s *LinkedInMediaService) uploadImage(ctx context.Context, accessToken, owner string, download *linkedinDownload) (string, error) {
+	var upload linkedinImageUpload
+	payload := map[string]interface{}{
+		"initializeUploadRequest": map[string]string{"owner": owner},
+	}
+	if _, err := s.api.do(ctx, "POST", "/rest/images?action=initializeUpload", accessToken, payload, &upload); err != nil {
+		return "", fmt.Errorf("failed to initialize image upload: %w", err)
+	}
+
+	if _, err := s.uploadPart(ctx, upload.Value.UploadURL, accessToken, download, 0, download.size-1); err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+	return upload.Value.Image, nil
+}
+
+// uploadVideo registers a video upload, sends every part and finalizes the upload
+func (s *LinkedInMediaService) uploadVideo(ctx context.Context, accessToken, owner string, download *linkedinDownload, progress platformapi.UploadProgressFunc) (string, error) {
+	var upload linkedinVideoUpload
+	payload := map[string]interface{}{
+		"initializeUploadRequest": map[string]interface{}{
+			"owner":           owner,
+			"fileSizeBytes":   download.size,
+			"uploadCaptions":  false,
+			"uploadThumbnail": false,
+		},
+	}
+	if _, err := s.api.do(ctx, "POST", "/rest/videos?action=initializeUpload", accessToken, payload, &upload); err != nil {
+		return "", fmt.Errorf("failed to initialize video upload: %w", err)
+	}
+
+	// The ETag of every part proves to finalizeUpload that the part arrived
+	partIDs := make([]string, 0, len(upload.Value.UploadInstructions))
+	for i, instruction := range upload.Value.UploadInstructions {
+		etag, err := s.uploadPart(ctx, instruction.UploadURL, "", download, instruction.FirstByte, instruction.LastByte)
+		if err != nil {
+			return "", fmt.Errorf("failed to upload video part %d: %w", i+1, err)
+		}
+		partIDs = append(partIDs, etag)
+		if progress != nil {
+			progress(platformapi.UploadStageUploading, (i+1)*100/len(upload.Value.UploadInstructions))
+		}
+	}
+
+	payload = map[string]interface{}{
+		"finalizeUploadRequest": map[string]interface{}{
+			"video":           upload.Value.Video,
+			"uploadToken":     upload.Value.UploadToken,
+			"uploadedPartIds": partIDs,
+		},
+	}
+	if _, err := s.api.do(ctx, "POST", "/rest/videos?action=finalizeUpload", accessToken, payload, nil); err != nil {
+		return "", fmt.Errorf("failed to finalize video upload: %w", err)
+	}
+	return upload.Value.Video, nil
+}
+
+// uploadPart PUTs bytes first to last of a download to an upload URL and returns the part's ETag
+// Image upload URLs need the access token; the pre-signed video part URLs must not get one
+func (s *LinkedInMediaService) uploadPart(ctx context.Context, uploadURL, accessToken string, download *linkedinDownload, first, last int64) (string, error) {
+	file, err := os.Open(download.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer file.Close()
+
+	length := last - first + 1
+	req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, io.NewSectionReader(file, first, length))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = length
+	// The part can be re-read from the file, so the client may retry it
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(file, first, length)), nil
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := s.uploadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", linkedinError(resp, body)
+	}
+	return strings.Trim(resp.Header.Get("ETag"), `"`), nil
+}
+
+// waitUntilAvailable polls an uploaded image or video until LinkedIn has processed it
+// Posts that reference media that is still processing are rejected
+func (s *LinkedInMediaService) waitUntilAvailable(ctx context.Context, accessToken, resource, urn string) error {
+	for {
+		var status linkedinMediaStatus
+		if _, err := s.api.do(ctx, "GET", linkedinEntityPath(resource, urn), accessToken, nil, &status); err != nil {
+			return fmt.Errorf("failed to check media status: %w", err)
+		}
+
+		switch status.Status {
+		case linkedinMediaAvailable:
+			return nil
+		case linkedinMediaProcessingFailed:
+			reason := status.ProcessingFailureReason
+			if reason == "" {
+				reason = "media processing failed"
+			}
+			return platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, status.Status, reason)
+		}
+
+		// WAITING_UPLOAD, PROCESSING or an unknown status: keep waiting
+		if err := sleepContext(ctx, linkedinStatusCheckInterval); err != nil {
+			return fmt.Errorf("stopped waiting for media processing: %w", err)
+		}
+	}
+}
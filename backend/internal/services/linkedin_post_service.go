@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// LinkedIn post visibilities
+const (
+	LinkedInVisibilityPublic      = "PUBLIC"
+	LinkedInVisibilityConnections = "CONNECTIONS" // Members only; organizations always post publicly
+)
+
+// linkedinOrganizationURNPrefix prefixes the numeric ID of an organization
+const linkedinOrganizationURNPrefix = "urn:li:organization:"
+
+// linkedinMaxImages is the most images a multi-image post can have
+const linkedinMaxImages = 20
+
+// linkedinPostingRoles are the organization roles that can post as the organization
+var linkedinPostingRoles = map[string]bool{
+	"ADMINISTRATOR":         true,
+	"CONTENT_ADMINISTRATOR": true,
+}
+
+// linkedinOrganizationIDPattern matches a numeric organization ID
+var linkedinOrganizationIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// linkedinReservedCharacters are escaped in commentary, which LinkedIn parses as "little text"
+// Unescaped, they can make LinkedIn drop the rest of the text
+var linkedinReservedCharacters = strings.NewReplacer(
+	`\`, `\\`, `|`, `\|`, `{`, `\{`, `}`, `\}`, `@`, `\@`, `[`, `\[`, `]`, `\]`,
+	`(`, `\(`, `)`, `\)`, `<`, `\<`, `>`, `\>`, `#`, `\#`, `*`, `\*`, `_`, `\_`, `~`, `\~`,
+)
+
+// LinkedInOrganizationURN turns an organization ID or URN into an organization URN
+func LinkedInOrganizationURN(organization string) (string, error) {
+	id := strings.TrimPrefix(organization, linkedinOrganizationURNPrefix)
+	if !linkedinOrganizationIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%q is not a LinkedIn organization ID", organization)
+	}
+	return linkedinOrganizationURNPrefix + id, nil
+}
+
+// LinkedInPostService creates posts with the LinkedIn Posts API
+// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/posts-api
+type LinkedInPostService struct {
+	authService  *LinkedInAuthService
+	mediaService *LinkedInMediaService
+	api          *linkedinAPI
+}
+
+// NewLinkedInPostService creates a new LinkedIn post service
+func NewLinkedInPostService(authService *LinkedInAuthService, mediaService *LinkedInMediaService) *LinkedInPostService {
+	return &LinkedInPostService{
+		authService:  authService,
+		mediaService: mediaService,
+		api:          mediaService.api,
+	}
+}
+
+// LinkedInPostRequest is the content of a LinkedIn post
+type LinkedInPostRequest struct {
+	Text      string
+	MediaURLs []string // A video, or 1 to 20 images; with ArticleURL, at most one image used as the thumbnail
+
+	// OrganizationID posts as an organization the member administers instead of as the member
+	OrganizationID string
+	Visibility     string // PUBLIC or CONNECTIONS
+	Title          string // Title of a single image or video
+
+	ArticleURL         string
+	ArticleTitle       string
+	ArticleDescription string
+}
+
+// LinkedInOrganization is an organization page the member can post as
+type LinkedInOrganization struct {
+	ID         string `json:"id"`
+	URN        string `json:"urn"`
+	Name       string `json:"name"`
+	VanityName string `json:"vanity_name,omitempty"`
+	Role       string `json:"role"`
+}
+
+// linkedinOrganizationACLs is the response of GET /rest/organizationAcls?q=roleAssignee
+type linkedinOrganizationACLs struct {
+	Elements []struct {
+		Organization string `json:"organization"`
+		Role         string `json:"role"`
+		State        string `json:"state"`
+	} `json:"elements"`
+}
+
+// CreatePost uploads the media of a post and publishes it
+// Returns the post URN and the URL of the post
+func (s *LinkedInPostService) CreatePost(ctx context.Context, accessToken string, req LinkedInPostRequest, progress platformapi.UploadProgressFunc) (string, string, error) {
+	author, err := s.author(ctx, accessToken, req.OrganizationID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if req.ArticleURL != "" && len(req.MediaURLs) > 1 {
+		return "", "", platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+			"article posts can have at most one image, which is used as the thumbnail")
+	}
+	if len(req.MediaURLs) > linkedinMaxImages {
+		return "", "", platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("posts can have at most %d images", linkedinMaxImages))
+	}
+
+	// Media has to be owned by the post's author
+	media := make([]*LinkedInMedia, 0, len(req.MediaURLs))
+	for i, mediaURL := range req.MediaURLs {
+		item, err := s.mediaService.Upload(ctx, accessToken, author, mediaURL, progress)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to upload media %d: %w", i+1, err)
+		}
+		media = append(media, item)
+	}
+
+	content, err := linkedinPostContent(req, media)
+	if err != nil {
+		return "", "", err
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = LinkedInVisibilityPublic
+	}
+
+	payload := map[string]interface{}{
+		"author":     author,
+		"commentary": linkedinReservedCharacters.Replace(req.Text),
+		"visibility": visibility,
+		"distribution": map[string]interface{}{
+			"feedDistribution":               "MAIN_FEED",
+			"targetEntities":                 []string{},
+			"thirdPartyDistributionChannels": []string{},
+		},
+		"lifecycleState":            "PUBLISHED",
+		"isReshareDisabledByAuthor": false,
+	}
+	if content != nil {
+		payload["content"] = content
+	}
+
+	header, err := s.api.do(ctx, "POST", "/rest/posts", accessToken, payload, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create post: %w", err)
+	}
+
+	postURN := header.Get("x-restli-id")
+	if postURN == "" {
+		return "", "", fmt.Errorf("post response has no post ID")
+	}
+
+	log.Printf("LinkedIn post created: %s (author: %s)", postURN, author)
+	return postURN, "https://www.linkedin.com/feed/update/" + postURN, nil
+}
+
+// author returns the URN the post is published as: the member, or an organization the member
+// is allowed to post as
+func (s *LinkedInPostService) author(ctx context.Context, accessToken, organizationID string) (string, error) {
+	if organizationID == "" {
+		userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+		if err != nil {
+			return "", fmt.Errorf("failed to get LinkedIn member: %w", err)
+		}
+		return userInfo.PersonURN(), nil
+	}
+
+	organizationURN, err := LinkedInOrganizationURN(organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	acls, err := s.organizationACLs(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+	for _, acl := range acls.Elements {
+		if acl.Organization == organizationURN && linkedinPostingRoles[acl.Role] {
+			return organizationURN, nil
+		}
+	}
+	return "", platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeInsufficientScope, 0, "",
+		fmt.Sprintf("the connected member is not an administrator of organization %s", organizationID))
+}
+
+// organizationACLs returns the approved organization roles of the member
+// Needs the r_organization_admin scope
+func (s *LinkedInPostService) organizationACLs(ctx context.Context, accessToken string) (*linkedinOrganizationACLs, error) {
+	var acls linkedinOrganizationACLs
+	if _, err := s.api.do(ctx, "GET", "/rest/organizationAcls?q=roleAssignee&state=APPROVED&count=100", accessToken, nil, &acls); err != nil {
+		return nil, fmt.Errorf("failed to get organization roles: %w", err)
+	}
+	return &acls, nil
+}
+
+// ListOrganizations returns the organizations the member can post as
+func (s *LinkedInPostService) ListOrganizations(ctx context.Context, accessToken string) ([]LinkedInOrganization, error) {
+	acls, err := s.organizationACLs(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make([]LinkedInOrganization, 0, len(acls.Elements))
+	for _, acl := range acls.Elements {
+		if !linkedinPostingRoles[acl.Role] {
+			continue
+		}
+		id := strings.TrimPrefix(acl.Organization, linkedinOrganizationURNPrefix)
+
+		var organization struct {
+			LocalizedName string `json:"localizedName"`
+			VanityName    string `json:"vanityName"`
+		}
+		if _, err := s.api.do(ctx, "GET", "/rest/organizations/"+url.PathEscape(id), accessToken, nil, &organization); err != nil {
+			return nil, fmt.Errorf("failed to get organization %s: %w", id, err)
+		}
+
+		organizations = append(organizations, LinkedInOrganization{
+			ID:         id,
+			URN:        acl.Organization,
+			Name:       organization.LocalizedName,
+			VanityName: organization.VanityName,
+			Role:       acl.Role,
+		})
+	}
+	return organizations, nil
+}
+
+// linkedinPostContent builds the content of a post from its uploaded media
+// Returns nil for text-only posts
+func linkedinPostContent(req LinkedInPostRequest, media []*LinkedInMedia) (map[string]interface{}, error) {
+	if req.ArticleURL != "" {
+		article := map[string]interface{}{
+			"source": req.ArticleURL,
+			"title":  req.ArticleTitle,
+		}
+		if req.ArticleDescription != "" {
+			article["description"] = req.ArticleDescription
+		}
+		if len(media) > 0 {
+			if media[0].Kind != MediaTypeImage {
+				return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+					"the thumbnail of an article must be an image")
+			}
+			article["thumbnail"] = media[0].URN
+		}
+		return map[string]interface{}{"article": article}, nil
+	}
+
+	switch len(media) {
+	case 0:
+		return nil, nil
+	case 1:
+		item := map[string]interface{}{"id": media[0].URN}
+		if req.Title != "" {
+			item["title"] = req.Title
+		}
+		return map[string]interface{}{"media": item}, nil
+	}
+
+	// Several media items make a multi-image post, which cannot contain videos
+	images := make([]map[string]string, 0, len(media))
+	for _, item := range media {
+		if item.Kind != MediaTypeImage {
+			return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeMediaRejected, 0, "",
+				"a video cannot be combined with other media")
+		}
+		images = append(images, map[string]string{"id": item.URN})
+	}
+	return map[string]interface{}{"multiImage": map[string]interface{}{"images": images}}, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// mastodonAPI sends requests to the REST API of Mastodon instances
+// One app talks to many instances, so every request names the instance it goes to
+type mastodonAPI struct {
+	httpClient *http.Client
+}
+
+// newRequest creates a request to path on the instance at instanceURL
+// accessToken may be empty for the endpoints that don't need one, like app registration
+func (a *mastodonAPI) newRequest(ctx context.Context, method, instanceURL, path, accessToken string, body io.Reader, contentType string) (*http.Request, error) {
+	if instanceURL == "" {
+		return nil, fmt.Errorf("no Mastodon instance given")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, instanceURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do sends a request and decodes a JSON response into out
+// Returns the response status, since some endpoints answer differently with 200 and 202
+func (a *mastodonAPI) do(req *http.Request, out interface{}) (int, http.Header, error) {
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, resp.Header, mastodonError(resp, body)
+	}
+
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return resp.StatusCode, resp.Header, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, resp.Header, nil
+}
+
+// doJSON sends a request with an optional JSON payload and decodes a JSON response into out
+func (a *mastodonAPI) doJSON(ctx context.Context, method, instanceURL, path, accessToken string, payload, out interface{}) (int, http.Header, error) {
+	var body io.Reader
+	contentType := ""
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := a.newRequest(ctx, method, instanceURL, path, accessToken, body, contentType)
+	if err != nil {
+		return 0, nil, err
+	}
+	return a.do(req, out)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// MastodonAuthService registers the app on Mastodon instances and runs their OAuth flow
+// https://docs.joinmastodon.org/client/token/
+type MastodonAuthService struct {
+	clientName       string
+	website          string
+	redirectURI      string
+	scopes           []string
+	allowedInstances map[string]bool // Hosts users may log in with; empty allows any
+	allowPrivate     bool            // Allow instances on private networks and plain http
+	apps             *models.MastodonAppRepository
+	api              *mastodonAPI
+
+	registerMu sync.Mutex // Serializes app registration, so concurrent logins register an instance once
+}
+
+// NewMastodonAuthService creates a new Mastodon auth service
+func NewMastodonAuthService(clientName, website, redirectURI string, scopes, allowedInstances []string, allowPrivateInstances bool, apps *models.MastodonAppRepository) *MastodonAuthService {
+	allowed := make(map[string]bool, len(allowedInstances))
+	for _, host := range allowedInstances {
+		allowed[strings.ToLower(host)] = true
+	}
+
+	return &MastodonAuthService{
+		clientName:       clientName,
+		website:          website,
+		redirectURI:      redirectURI,
+		scopes:           scopes,
+		allowedInstances: allowed,
+		allowPrivate:     allowPrivateInstances,
+		apps:             apps,
+		api:              &mastodonAPI{httpClient: newHTTPClient("mastodon", 30*time.Second)},
+	}
+}
+
+// MastodonTokenResponse represents the OAuth token response of an instance
+// Mastodon access tokens don't expire and no refresh token is issued
+type MastodonTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// MastodonAccount is the account returned by GET /api/v1/accounts/verify_credentials
+type MastodonAccount struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Acct        string `json:"acct"` // username, without the domain of the account's own instance
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
+	URL         string `json:"url"`
+}
+
+// mastodonAppRegistration is the response of POST /api/v1/apps
+type mastodonAppRegistration struct {
+	ID           string `json:"id"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// ResolveInstance turns what a user entered (a domain, an instance or profile URL, or a handle
+// like @user@mastodon.social) into the base URL of the instance, and checks that the
+// instance may be contacted
+// Returns an error wrapping platformapi.ErrInvalidInstance if it may not
+func (s *MastodonAuthService) ResolveInstance(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("%w: no instance given", platformapi.ErrInvalidInstance)
+	}
+	if !strings.Contains(input, "://") {
+		// A handle: the instance is the part after the last @
+		if at := strings.LastIndex(input, "@"); at >= 0 {
+			input = input[at+1:]
+		}
+		input = "https://" + input
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil || parsed.Hostname() == "" || parsed.User != nil {
+		return "", fmt.Errorf("%w: %q is not the address of an instance, like mastodon.social", platformapi.ErrInvalidInstance, input)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "https" && !(scheme == "http" && s.allowPrivate) {
+		return "", fmt.Errorf("%w: instances must be reached over https", platformapi.ErrInvalidInstance)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if len(s.allowedInstances) > 0 && !s.allowedInstances[host] {
+		return "", fmt.Errorf("%w: %s is not one of the allowed instances", platformapi.ErrInvalidInstance, host)
+	}
+
+	// Only scheme and host identify the instance; paths of profile URLs are dropped
+	instanceURL := scheme + "://" + strings.ToLower(parsed.Host)
+	if !s.allowPrivate {
+		if err := ValidateMediaURL(instanceURL); err != nil {
+			return "", fmt.Errorf("%w: %v", platformapi.ErrInvalidInstance, err)
+		}
+	}
+	return instanceURL, nil
+}
+
+// GenerateAuthURL creates the authorization URL of an instance and the state that protects it
+// The app is registered on the instance first if it isn't yet
+func (s *MastodonAuthService) GenerateAuthURL(ctx context.Context, instanceURL string) (authURL, state string, err error) {
+	app, err := s.app(ctx, instanceURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err = generateRandomState()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", app.ClientID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("scope", strings.Join(s.scopes, " "))
+	params.Set("state", state)
+
+	return instanceURL + "/oauth/authorize?" + params.Encode(), state, nil
+}
+
+// app returns the app registered on an instance, registering it if there is none or if the
+// redirect URI or scopes changed since it was registered
+func (s *MastodonAuthService) app(ctx context.Context, instanceURL string) (*models.MastodonApp, error) {
+	s.registerMu.Lock()
+	defer s.registerMu.Unlock()
+
+	scopes := strings.Join(s.scopes, " ")
+	app, err := s.apps.GetByInstance(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	if app != nil && app.RedirectURI == s.redirectURI && app.Scopes == scopes {
+		return app, nil
+	}
+
+	payload := map[string]string{
+		"client_name":   s.clientName,
+		"redirect_uris": s.redirectURI,
+		"scopes":        scopes,
+	}
+	if s.website != "" {
+		payload["website"] = s.website
+	}
+
+	var registration mastodonAppRegistration
+	if _, _, err := s.api.doJSON(ctx, "POST", instanceURL, "/api/v1/apps", "", payload, &registration); err != nil {
+		return nil, fmt.Errorf("failed to register app on %s: %w", instanceURL, err)
+	}
+	if registration.ClientID == "" || registration.ClientSecret == "" {
+		return nil, fmt.Errorf("app registration on %s returned no client credentials", instanceURL)
+	}
+
+	app = &models.MastodonApp{
+		InstanceURL:  instanceURL,
+		ClientID:     registration.ClientID,
+		ClientSecret: registration.ClientSecret,
+		RedirectURI:  s.redirectURI,
+		Scopes:       scopes,
+	}
+	if err := s.apps.Save(app); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Registered Mastodon app on %s", instanceURL)
+	return app, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for an access token on an instance
+func (s *MastodonAuthService) ExchangeCodeForToken(ctx context.Context, instanceURL, code string) (*MastodonTokenResponse, error) {
+	app, err := s.apps.GetByInstance(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, fmt.Errorf("no app is registered on %s", instanceURL)
+	}
+
+	formData := url.Values{}
+	formData.Set("grant_type", "authorization_code")
+	formData.Set("code", code)
+	formData.Set("client_id", app.ClientID)
+	formData.Set("client_secret", app.ClientSecret)
+	formData.Set("redirect_uri", app.RedirectURI)
+	formData.Set("scope", app.Scopes)
+
+	req, err := s.api.newRequest(ctx, "POST", instanceURL, "/oauth/token", "", strings.NewReader(formData.Encode()), "application/x-www-form-urlencoded")
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenResp MastodonTokenResponse
+	if _, _, err := s.api.do(req, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+	return &tokenResp, nil
+}
+
+// VerifyCredentials returns the account the access token belongs to
+func (s *MastodonAuthService) VerifyCredentials(ctx context.Context, instanceURL, accessToken string) (*MastodonAccount, error) {
+	var account MastodonAccount
+	if _, _, err := s.api.doJSON(ctx, "GET", instanceURL, "/api/v1/accounts/verify_credentials", accessToken, nil, &account); err != nil {
+		return nil, err
+	}
+	if account.ID == "" {
+		return nil, fmt.Errorf("account response has no account ID")
+	}
+	return &account, nil
+}
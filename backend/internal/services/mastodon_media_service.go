@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// mastodonMediaCheckInterval is how often the processing status of uploaded media is checked; tests shorten it
+var mastodonMediaCheckInterval = 5 * time.Second
+
+// mastodonMaxProcessingWait is how long to wait for an instance to process uploaded media
+const mastodonMaxProcessingWait = 5 * time.Minute
+
+// mastodonFileExtensions are the file names media is uploaded as
+// Mastodon rejects files whose name doesn't match their content
+var mastodonFileExtensions = map[string]string{
+	"image/jpeg":      "media.jpg",
+	"image/png":       "media.png",
+	"image/gif":       "media.gif",
+	"image/webp":      "media.webp",
+	"image/heic":      "media.heic",
+	"image/avif":      "media.avif",
+	"video/mp4":       "media.mp4",
+	"video/quicktime": "media.mov",
+	"video/webm":      "media.webm",
+}
+
+// MastodonMediaService uploads media attachments to Mastodon instances
+// https://docs.joinmastodon.org/methods/media/
+type MastodonMediaService struct {
+	api            *mastodonAPI
+	uploadAPI      *mastodonAPI // Uploads take longer than API calls
+	downloadClient *http.Client
+}
+
+// NewMastodonMediaService creates a new Mastodon media service
+func NewMastodonMediaService() *MastodonMediaService {
+	return &MastodonMediaService{
+		api:            &mastodonAPI{httpClient: newHTTPClient("mastodon", 30*time.Second)},
+		uploadAPI:      &mastodonAPI{httpClient: newHTTPClient("mastodon", 5*time.Minute)},
+		downloadClient: newHTTPClient("mastodon-media-download", 5*time.Minute),
+	}
+}
+
+// MastodonMediaAttachment is the response of the media endpoints
+type MastodonMediaAttachment struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"` // image, gifv, video, audio or unknown
+	URL         string `json:"url"`  // Empty until the instance has processed the media
+	Description string `json:"description"`
+}
+
+// MastodonMediaOptions are the optional attributes of an uploaded media attachment
+type MastodonMediaOptions struct {
+	Description string // Alt text, read out by screen readers
+	Focus       string // Focal point as "x,y" between -1.0 and 1.0, used when the preview is cropped
+}
+
+// mastodonDownload is media downloaded to a temp file
+type mastodonDownload struct {
+	path     string
+	mimeType string
+}
+
+// Upload downloads the media at mediaURL, uploads it to the instance and waits until the
+// instance has processed it
+// Returns the ID of the media attachment
+func (s *MastodonMediaService) Upload(ctx context.Context, instanceURL, accessToken, mediaURL string, options MastodonMediaOptions) (string, error) {
+	download, err := s.download(ctx, mediaURL)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(download.path)
+
+	attachment, processed, err := s.uploadFile(ctx, instanceURL, accessToken, download, options)
+	if err != nil {
+		return "", err
+	}
+
+	if !processed {
+		if err := s.WaitForProcessing(ctx, instanceURL, accessToken, attachment.ID, mastodonMaxProcessingWait); err != nil {
+			return "", err
+		}
+	}
+
+	log.Printf("Mastodon %s uploaded to %s: %s", attachment.Type, instanceURL, attachment.ID)
+	return attachment.ID, nil
+}
+
+// download saves the media at mediaURL to a temp file and detects its type
+func (s *MastodonMediaService) download(ctx context.Context, mediaURL string) (*mastodonDownload, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download media: status %d", resp.StatusCode))
+	}
+
+	out, err := os.CreateTemp("", "mastodon-media-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("failed to save media: %w", err)
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := out.ReadAt(header, 0)
+
+	// Trust the bytes first, then the Content-Type header, then the URL's extension
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	if mimeType == "" {
+		switch DetectMediaTypeFromURL(mediaURL) {
+		case MediaTypeImage:
+			mimeType = "image/jpeg"
+		case MediaTypeVideo:
+			mimeType = "video/mp4"
+		}
+	}
+	if _, ok := mastodonFileExtensions[mimeType]; !ok {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported media type %q", mimeType))
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformMastodon, MediaTypeFromMIME(mimeType)); limit > 0 && size > limit {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("media is %.1f MB, Mastodon allows at most %.1f MB", megabytes(size), megabytes(limit)))
+	}
+
+	return &mastodonDownload{path: out.Name(), mimeType: mimeType}, nil
+}
+
+// uploadFile sends a downloaded file to POST /api/v2/media
+// Instances process small images right away and answer 200; anything else is processed in
+// the background and answered with 202, in which case processed is false
+func (s *MastodonMediaService) uploadFile(ctx context.Context, instanceURL, accessToken string, download *mastodonDownload, options MastodonMediaOptions) (attachment *MastodonMediaAttachment, processed bool, err error) {
+	file, err := os.Open(download.path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer file.Close()
+
+	// The file is streamed into the multipart body instead of being buffered, videos can be large
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeMastodonMediaForm(form, file, download.mimeType, options))
+	}()
+
+	req, err := s.api.newRequest(ctx, "POST", instanceURL, "/api/v2/media", accessToken, body, form.FormDataContentType())
+	if err != nil {
+		body.Close()
+		return nil, false, err
+	}
+
+	var uploaded MastodonMediaAttachment
+	status, header, err := s.uploadAPI.do(req, &uploaded)
+	if header != nil {
+		observeMastodonRateLimit(ctx, "media", header)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to upload media: %w", err)
+	}
+	if uploaded.ID == "" {
+		return nil, false, fmt.Errorf("media response has no media ID")
+	}
+	return &uploaded, status == http.StatusOK && uploaded.URL != "", nil
+}
+
+// writeMastodonMediaForm writes the fields of a media upload and closes the form
+func writeMastodonMediaForm(form *multipart.Writer, file io.Reader, mimeType string, options MastodonMediaOptions) error {
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, mastodonFileExtensions[mimeType]))
+	partHeader.Set("Content-Type", mimeType)
+	part, err := form.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+
+	if options.Description != "" {
+		if err := form.WriteField("description", options.Description); err != nil {
+			return err
+		}
+	}
+	if options.Focus != "" {
+		if err := form.WriteField("focus", options.Focus); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
+
+// WaitForProcessing polls a media attachment until the instance has processed it
+// GET /api/v1/media/:id answers 206 while the media is processing and 200 once it is ready
+func (s *MastodonMediaService) WaitForProcessing(ctx context.Context, instanceURL, accessToken, mediaID string, maxWait time.Duration) error {
+	startTime := time.Now()
+
+	for {
+		var attachment MastodonMediaAttachment
+		status, _, err := s.api.doJSON(ctx, "GET", instanceURL, "/api/v1/media/"+mediaID, accessToken, nil, &attachment)
+		if err != nil {
+			return fmt.Errorf("failed to check media status: %w", err)
+		}
+
+		if status == http.StatusOK && attachment.URL != "" {
+			return nil
+		}
+
+		// Check timeout
+		if time.Since(startTime) > maxWait {
+			return platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeTransient, 0, "",
+				fmt.Sprintf("media processing timeout after %s", maxWait))
+		}
+
+		log.Printf("Mastodon media %s is processing, checking again in %s...", mediaID, mastodonMediaCheckInterval)
+
+		if err := sleepContext(ctx, mastodonMediaCheckInterval); err != nil {
+			return fmt.Errorf("stopped waiting for media processing: %w", err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Mastodon status visibilities
+const (
+	MastodonVisibilityPublic   = "public"
+	MastodonVisibilityUnlisted = "unlisted" // Public, but left out of public timelines
+	MastodonVisibilityPrivate  = "private"  // Followers only
+	MastodonVisibilityDirect   = "direct"   // Mentioned accounts only
+)
+
+// mastodonMaxMedia is the most media attachments a status can have
+const mastodonMaxMedia = 4
+
+// MastodonStatusURL returns the URL of a status of the account with the given handle (user@instance)
+// Returns an empty string if handle is not a full handle
+func MastodonStatusURL(handle, statusID string) string {
+	user, host, ok := strings.Cut(strings.TrimPrefix(handle, "@"), "@")
+	if !ok || user == "" || host == "" {
+		return ""
+	}
+	return "https://" + host + "/@" + user + "/" + statusID
+}
+
+// MastodonPostService publishes statuses on Mastodon instances
+// https://docs.joinmastodon.org/methods/statuses/
+type MastodonPostService struct {
+	mediaService *MastodonMediaService
+	api          *mastodonAPI
+}
+
+// NewMastodonPostService creates a new Mastodon post service
+func NewMastodonPostService(mediaService *MastodonMediaService) *MastodonPostService {
+	return &MastodonPostService{
+		mediaService: mediaService,
+		api:          mediaService.api,
+	}
+}
+
+// MastodonStatusRequest is the content of a status
+type MastodonStatusRequest struct {
+	Text      string
+	MediaURLs []string             // Up to 4 images, or a single video
+	Media     MastodonMediaOptions // Description and focus applied to every media item
+
+	Visibility  string // public, unlisted, private or direct
+	SpoilerText string // Content warning shown in place of the text until the reader expands it
+	Sensitive   bool   // Hide the media behind a warning; implied by a content warning
+	Language    string // ISO 639 language code of the text
+}
+
+// MastodonStatus is a published status
+type MastodonStatus struct {
+	ID  string `json:"id"`
+	URL string `json:"url"` // Web page of the status on the author's instance
+	URI string `json:"uri"`
+}
+
+// CreateStatus uploads the media of a status and publishes it
+func (s *MastodonPostService) CreateStatus(ctx context.Context, instanceURL, accessToken string, req MastodonStatusRequest) (*MastodonStatus, error) {
+	if len(req.MediaURLs) > mastodonMaxMedia {
+		return nil, platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("statuses can have at most %d media attachments", mastodonMaxMedia))
+	}
+
+	mediaIDs := make([]string, 0, len(req.MediaURLs))
+	for i, mediaURL := range req.MediaURLs {
+		mediaID, err := s.mediaService.Upload(ctx, instanceURL, accessToken, mediaURL, req.Media)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload media %d: %w", i+1, err)
+		}
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = MastodonVisibilityPublic
+	}
+
+	payload := map[string]interface{}{
+		"status":     req.Text,
+		"visibility": visibility,
+	}
+	if len(mediaIDs) > 0 {
+		payload["media_ids"] = mediaIDs
+	}
+	if req.SpoilerText != "" {
+		payload["spoiler_text"] = req.SpoilerText
+	}
+	if req.Sensitive {
+		payload["sensitive"] = true
+	}
+	if req.Language != "" {
+		payload["language"] = req.Language
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	httpReq, err := s.api.newRequest(ctx, "POST", instanceURL, "/api/v1/statuses", accessToken, bytes.NewReader(data), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	// The instance publishes a status only once per idempotency key, so a request that is
+	// retried after a network error can't publish it twice
+	idempotencyKey, err := generateRandomState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	httpReq = httpclient.MarkIdempotent(httpReq)
+
+	var status MastodonStatus
+	_, header, err := s.api.do(httpReq, &status)
+	if header != nil {
+		observeMastodonRateLimit(ctx, "statuses", header)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status: %w", err)
+	}
+	if status.ID == "" {
+		return nil, fmt.Errorf("status response has no status ID")
+	}
+
+	log.Printf("Mastodon status created on %s: %s", instanceURL, status.ID)
+	return &status, nil
+}
+
+// GetStatus retrieves a status
+func (s *MastodonPostService) GetStatus(ctx context.Context, instanceURL, accessToken, statusID string) (*MastodonStatus, error) {
+	var status MastodonStatus
+	if _, _, err := s.api.doJSON(ctx, "GET", instanceURL, "/api/v1/statuses/"+statusID, accessToken, nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	return &status, nil
+}
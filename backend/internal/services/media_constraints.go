@@ -0,0 +1,406 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// VideoConstraints describes what a platform accepts for video uploads
+// Zero values mean "no limit"
+type VideoConstraints struct {
+	Formats        []string // Container formats (mp4, mov, webm)
+	Codecs         []string // Video codec fourccs (avc1, hvc1, ...)
+	MaxFileSize    int64    // Bytes
+	MinDurationSec float64
+	MaxDurationSec float64
+	MinWidth       int
+	MinHeight      int
+	MaxWidth       int
+	MaxHeight      int
+	MinAspectRatio float64 // Width / Height
+	MaxAspectRatio float64 // Width / Height
+	MaxBitrate     int64   // Bits per second
+}
+
+// ImageConstraints describes what a platform accepts for image uploads
+// Zero values mean "no limit"
+type ImageConstraints struct {
+	Formats        []string // jpeg, png, gif, webp
+	MaxFileSize    int64    // Bytes
+	MinWidth       int
+	MaxWidth       int
+	MaxHeight      int
+	MinAspectRatio float64 // Width / Height
+	MaxAspectRatio float64 // Width / Height
+}
+
+// MediaConstraints groups the video and image limits of a platform
+type MediaConstraints struct {
+	Video VideoConstraints
+	Image ImageConstraints
+}
+
+// platformMediaConstraints contains the documented media limits of each platform
+var platformMediaConstraints = map[models.Platform]MediaConstraints{
+	// https://developers.tiktok.com/doc/content-posting-api-media-transfer-guide
+	models.PlatformTikTok: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov", "webm"},
+			Codecs:         []string{"avc1", "hvc1", "hev1", "vp08", "vp09"},
+			MaxFileSize:    maxVideoSize,
+			MinDurationSec: minVideoDuration,
+			MaxDurationSec: maxVideoDuration,
+			MinWidth:       360,
+			MinHeight:      360,
+			MaxWidth:       4096,
+			MaxHeight:      4096,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "webp"},
+			MaxFileSize: 20 * 1024 * 1024,
+			MaxWidth:    1080,
+			MaxHeight:   1920,
+		},
+	},
+	// https://developer.x.com/en/docs/x-api/v1/media/upload-media/uploading-media/media-best-practices
+	models.PlatformX: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov"},
+			Codecs:         []string{"avc1"},
+			MaxFileSize:    512 * 1024 * 1024,
+			MinDurationSec: 0.5,
+			MaxDurationSec: 140,
+			MinWidth:       32,
+			MinHeight:      32,
+			MinAspectRatio: 1.0 / 3.0,
+			MaxAspectRatio: 3.0,
+			MaxBitrate:     25 * 1000 * 1000,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp"},
+			MaxFileSize: 5 * 1024 * 1024,
+		},
+	},
+	// https://developers.facebook.com/docs/instagram-platform/instagram-graph-api/reference/ig-user/media
+	models.PlatformInstagram: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov"},
+			Codecs:         []string{"avc1", "hvc1", "hev1"},
+			MaxFileSize:    300 * 1024 * 1024,
+			MinDurationSec: 3,
+			MaxDurationSec: 900,
+			MaxWidth:       1920,
+			MinAspectRatio: 0.01,
+			MaxAspectRatio: 10.0,
+			MaxBitrate:     25 * 1000 * 1000,
+		},
+		Image: ImageConstraints{
+			Formats:        []string{"jpeg"},
+			MaxFileSize:    8 * 1024 * 1024,
+			MinWidth:       320,
+			MaxWidth:       1440,
+			MinAspectRatio: 4.0 / 5.0,
+			MaxAspectRatio: 1.91,
+		},
+	},
+	// https://developers.facebook.com/docs/threads/overview#media-specifications
+	models.PlatformThreads: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov"},
+			Codecs:         []string{"avc1", "hvc1", "hev1"},
+			MaxFileSize:    1024 * 1024 * 1024,
+			MaxDurationSec: 300,
+			MaxWidth:       1920,
+			MinAspectRatio: 0.01,
+			MaxAspectRatio: 10.0,
+			MaxBitrate:     100 * 1000 * 1000,
+		},
+		Image: ImageConstraints{
+			Formats:        []string{"jpeg", "png"},
+			MaxFileSize:    8 * 1024 * 1024,
+			MinWidth:       320,
+			MaxWidth:       1440,
+			MaxAspectRatio: 10.0,
+		},
+	},
+	// https://developers.facebook.com/docs/graph-api/reference/page/photos/#upload
+	// https://developers.facebook.com/docs/video-api/guides/publishing
+	models.PlatformFacebook: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov"},
+			MaxFileSize:    maxVideoSize,
+			MaxDurationSec: 4 * 60 * 60,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp"},
+			MaxFileSize: 4 * 1024 * 1024,
+		},
+	},
+	// https://support.google.com/youtube/answer/15424877
+	// Shorts are square or vertical and at most 3 minutes long; YouTube accepts much larger files
+	// than the videos VideoService downloads for the upload
+	models.PlatformYouTube: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov", "webm"},
+			MaxFileSize:    maxVideoSize,
+			MaxDurationSec: 3 * 60,
+			MaxAspectRatio: 1.0,
+		},
+	},
+	// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/videos-api
+	// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/images-api
+	models.PlatformLinkedIn: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4"},
+			MaxFileSize:    500 * 1024 * 1024,
+			MinDurationSec: 3,
+			MaxDurationSec: 30 * 60,
+			MinAspectRatio: 1.0 / 2.4,
+			MaxAspectRatio: 2.4,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif"},
+			MaxFileSize: 36 * 1024 * 1024,
+		},
+	},
+	// https://docs.joinmastodon.org/user/posting/#media
+	// These are the defaults of Mastodon 4; instances may lower them, which
+	// MEDIA_MAX_MASTODON_IMAGE_MB and MEDIA_MAX_MASTODON_VIDEO_MB account for
+	models.PlatformMastodon: {
+		Video: VideoConstraints{
+			Formats:     []string{"mp4", "mov", "webm"},
+			MaxFileSize: 99 * 1024 * 1024,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp", "heic", "avif"},
+			MaxFileSize: 16 * 1024 * 1024,
+		},
+	},
+	// https://docs.bsky.app/docs/advanced-guides/posts#images-embeds
+	// https://docs.bsky.app/docs/tutorials/video
+	models.PlatformBluesky: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov", "webm"},
+			MaxFileSize:    100 * 1000 * 1000,
+			MaxDurationSec: 3 * 60,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp"},
+			MaxFileSize: 1000 * 1000, // Blobs in app.bsky.embed.images
+		},
+	},
+	// https://help.pinterest.com/en/business/article/pinterest-product-specs
+	// Video pins are uploaded to Pinterest, which takes files up to 2 GB
+	models.PlatformPinterest: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov", "m4v"},
+			MaxFileSize:    2 * 1024 * 1024 * 1024,
+			MinDurationSec: 4,
+			MaxDurationSec: 15 * 60,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp", "bmp", "tiff"},
+			MaxFileSize: 20 * 1024 * 1024,
+		},
+	},
+	// https://support.reddithelp.com/hc/en-us/articles/360043033952
+	// Media is uploaded to Reddit's media store before the post is submitted
+	models.PlatformReddit: {
+		Video: VideoConstraints{
+			Formats:        []string{"mp4", "mov"},
+			MaxFileSize:    1024 * 1024 * 1024,
+			MaxDurationSec: 15 * 60,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif"},
+			MaxFileSize: 20 * 1024 * 1024,
+		},
+	},
+	// https://core.telegram.org/bots/api#sending-files
+	// Telegram fetches media from its URL itself, which it does for photos up to 5 MB and other
+	// files up to 20 MB
+	models.PlatformTelegram: {
+		Video: VideoConstraints{
+			Formats:     []string{"mp4"},
+			MaxFileSize: 20 * 1024 * 1024,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp"},
+			MaxFileSize: 5 * 1024 * 1024,
+		},
+	},
+	// https://support.discord.com/hc/en-us/articles/25444343291031-File-Attachments-FAQ
+	// Attachments of servers without boosts
+	models.PlatformDiscord: {
+		Video: VideoConstraints{
+			Formats:     []string{"mp4", "mov", "webm"},
+			MaxFileSize: 10 * 1024 * 1024,
+		},
+		Image: ImageConstraints{
+			Formats:     []string{"jpeg", "png", "gif", "webp"},
+			MaxFileSize: 10 * 1024 * 1024,
+		},
+	},
+	// Slack shows images of image blocks from their URL
+	models.PlatformSlack: {
+		Image: ImageConstraints{
+			Formats: []string{"jpeg", "png", "gif"},
+		},
+	},
+}
+
+// ApplyMediaSizeLimits overrides the built-in maximum file sizes with configured values
+// Must be called during startup, before any posts are processed
+func ApplyMediaSizeLimits(limits map[string]config.MediaSizeLimit) {
+	for name, limit := range limits {
+		platform := models.Platform(name)
+		constraints := platformMediaConstraints[platform]
+		if limit.ImageBytes > 0 {
+			constraints.Image.MaxFileSize = limit.ImageBytes
+		}
+		if limit.VideoBytes > 0 {
+			constraints.Video.MaxFileSize = limit.VideoBytes
+		}
+		platformMediaConstraints[platform] = constraints
+		log.Printf("Media size limits for %s: image %.1f MB, video %.1f MB",
+			platform, megabytes(constraints.Image.MaxFileSize), megabytes(constraints.Video.MaxFileSize))
+	}
+}
+
+// MaxMediaFileSize returns the maximum file size a platform accepts for a kind of media
+// Returns 0 if there is no known limit
+func MaxMediaFileSize(platform models.Platform, kind MediaType) int64 {
+	constraints, ok := GetMediaConstraints(platform)
+	if !ok {
+		return 0
+	}
+	switch kind {
+	case MediaTypeImage:
+		return constraints.Image.MaxFileSize
+	case MediaTypeVideo:
+		return constraints.Video.MaxFileSize
+	}
+	return 0
+}
+
+// GetMediaConstraints returns the media constraints for a platform
+// The second return value is false if no constraints are known for the platform
+func GetMediaConstraints(platform models.Platform) (MediaConstraints, bool) {
+	constraints, ok := platformMediaConstraints[platform]
+	return constraints, ok
+}
+
+// ConstraintViolation describes a single way a media file breaks a platform limit
+type ConstraintViolation struct {
+	MediaURL string `json:"media_url"`
+	Field    string `json:"field"` // format, codec, file_size, duration, width, height, aspect_ratio, bitrate
+	Message  string `json:"message"`
+	AutoFix  bool   `json:"auto_fix,omitempty"` // Fixed by image processing before publishing
+}
+
+// CheckMediaConstraints validates a probed media file against a platform's constraints
+// Returns nil if the media is acceptable or the platform has no known constraints
+func CheckMediaConstraints(platform models.Platform, probe *MediaProbeResult) []ConstraintViolation {
+	constraints, ok := GetMediaConstraints(platform)
+	if !ok || probe == nil {
+		return nil
+	}
+
+	var violations []ConstraintViolation
+	add := func(field, format string, args ...interface{}) {
+		violations = append(violations, ConstraintViolation{
+			MediaURL: probe.URL,
+			Field:    field,
+			Message:  fmt.Sprintf("%s: %s", platform, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	switch probe.Kind {
+	case MediaTypeVideo:
+		c := constraints.Video
+		if len(c.Formats) > 0 && !containsFold(c.Formats, probe.Format) {
+			add("format", "video format %s is not supported (supported: %s)", probe.Format, strings.Join(c.Formats, ", "))
+		}
+		if len(c.Codecs) > 0 && probe.VideoCodec != "" && !containsFold(c.Codecs, probe.VideoCodec) {
+			add("codec", "video codec %s is not supported (supported: %s)", probe.VideoCodec, strings.Join(c.Codecs, ", "))
+		}
+		if c.MaxFileSize > 0 && probe.FileSize > c.MaxFileSize {
+			add("file_size", "video is %.1f MB, maximum is %.1f MB", megabytes(probe.FileSize), megabytes(c.MaxFileSize))
+		}
+		if c.MinDurationSec > 0 && probe.DurationSec > 0 && probe.DurationSec < c.MinDurationSec {
+			add("duration", "video is %.1fs long, minimum is %.1fs", probe.DurationSec, c.MinDurationSec)
+		}
+		if c.MaxDurationSec > 0 && probe.DurationSec > c.MaxDurationSec {
+			add("duration", "video is %.1fs long, maximum is %.1fs", probe.DurationSec, c.MaxDurationSec)
+		}
+		checkDimensions(add, probe, c.MinWidth, c.MinHeight, c.MaxWidth, c.MaxHeight)
+		checkAspectRatio(add, probe, c.MinAspectRatio, c.MaxAspectRatio)
+		if c.MaxBitrate > 0 && probe.Bitrate > c.MaxBitrate {
+			add("bitrate", "video bitrate is %.1f Mbps, maximum is %.1f Mbps", float64(probe.Bitrate)/1e6, float64(c.MaxBitrate)/1e6)
+		}
+
+	case MediaTypeImage:
+		c := constraints.Image
+		if len(c.Formats) > 0 && !containsFold(c.Formats, probe.Format) {
+			add("format", "image format %s is not supported (supported: %s)", probe.Format, strings.Join(c.Formats, ", "))
+		}
+		if c.MaxFileSize > 0 && probe.FileSize > c.MaxFileSize {
+			add("file_size", "image is %.1f MB, maximum is %.1f MB", megabytes(probe.FileSize), megabytes(c.MaxFileSize))
+		}
+		checkDimensions(add, probe, c.MinWidth, 0, c.MaxWidth, c.MaxHeight)
+		checkAspectRatio(add, probe, c.MinAspectRatio, c.MaxAspectRatio)
+	}
+
+	return violations
+}
+
+// checkDimensions reports width/height violations
+func checkDimensions(add func(field, format string, args ...interface{}), probe *MediaProbeResult, minWidth, minHeight, maxWidth, maxHeight int) {
+	if probe.Width == 0 || probe.Height == 0 {
+		return
+	}
+	if minWidth > 0 && probe.Width < minWidth {
+		add("width", "width is %dpx, minimum is %dpx", probe.Width, minWidth)
+	}
+	if minHeight > 0 && probe.Height < minHeight {
+		add("height", "height is %dpx, minimum is %dpx", probe.Height, minHeight)
+	}
+	if maxWidth > 0 && probe.Width > maxWidth {
+		add("width", "width is %dpx, maximum is %dpx", probe.Width, maxWidth)
+	}
+	if maxHeight > 0 && probe.Height > maxHeight {
+		add("height", "height is %dpx, maximum is %dpx", probe.Height, maxHeight)
+	}
+}
+
+// checkAspectRatio reports aspect ratio violations
+func checkAspectRatio(add func(field, format string, args ...interface{}), probe *MediaProbeResult, minRatio, maxRatio float64) {
+	if probe.AspectRatio == 0 {
+		return
+	}
+	if minRatio > 0 && probe.AspectRatio < minRatio-0.005 {
+		add("aspect_ratio", "aspect ratio is %.2f:1, minimum is %.2f:1", probe.AspectRatio, minRatio)
+	}
+	if maxRatio > 0 && probe.AspectRatio > maxRatio+0.005 {
+		add("aspect_ratio", "aspect ratio is %.2f:1, maximum is %.2f:1", probe.AspectRatio, maxRatio)
+	}
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// megabytes converts bytes to megabytes
+func megabytes(size int64) float64 {
+	return float64(size) / (1024 * 1024)
+}
@@ -0,0 +1,640 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // Register JPEG decoder for image.DecodeConfig
+	_ "image/png"  // Register PNG decoder for image.DecodeConfig
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	probeBlockSize      = 64 * 1024       // Size of each ranged read when probing remote media
+	maxProbeHeadSize    = 2 * 1024 * 1024 // Leading bytes read when the server doesn't support ranges
+	maxProbeBoxDepth    = 8               // Maximum MP4 box nesting depth we walk
+	maxProbeSampleEntry = 256             // Bytes read from an stsd sample entry
+	maxConcurrentProbes = 8               // Probes running at once, across all requests
+)
+
+// MediaProbeResult describes the technical properties of a media file
+// as read from its container/image headers (no decoding of frames)
+type MediaProbeResult struct {
+	URL         string    `json:"url"`
+	Kind        MediaType `json:"kind"`                   // video or image
+	Format      string    `json:"format"`                 // mp4, mov, jpeg, png, gif, webp
+	MIMEType    string    `json:"mime_type"`              // Sniffed from the leading bytes
+	FileSize    int64     `json:"file_size"`              // Size in bytes
+	Width       int       `json:"width"`                  // Display width in pixels (rotation applied)
+	Height      int       `json:"height"`                 // Display height in pixels (rotation applied)
+	AspectRatio float64   `json:"aspect_ratio"`           // Width / Height
+	DurationSec float64   `json:"duration_sec,omitempty"` // Video duration in seconds
+	VideoCodec  string    `json:"video_codec,omitempty"`  // Sample entry fourcc (avc1, hvc1, ...)
+	AudioCodec  string    `json:"audio_codec,omitempty"`  // Sample entry fourcc (mp4a, ...)
+	Bitrate     int64     `json:"bitrate,omitempty"`      // Average bitrate in bits per second
+}
+
+// MediaProber reads media headers from remote URLs to learn their properties
+// before we hand them to a platform
+type MediaProber struct {
+	httpClient *http.Client
+	slots      chan struct{} // Holds a token for every probe running, up to maxConcurrentProbes
+}
+
+// NewMediaProber creates a new media prober
+func NewMediaProber() *MediaProber {
+	return &MediaProber{
+		httpClient: newHTTPClient("media-probe", 2*time.Minute),
+		slots:      make(chan struct{}, maxConcurrentProbes),
+	}
+}
+
+// Probe fetches the headers of the media at mediaURL and returns its properties
+// Uses HTTP range requests when the server supports them so that only the
+// container metadata is downloaded, not the whole file
+// If the headers cannot be parsed but the type is still recognisable from the
+// leading bytes or Content-Type, a result without dimensions is returned
+// At most maxConcurrentProbes run at once; others wait for their turn until ctx is done
+func (p *MediaProber) Probe(ctx context.Context, mediaURL string) (*MediaProbeResult, error) {
+	select {
+	case p.slots <- struct{}{}:
+		defer func() { <-p.slots }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to probe media: %w", ctx.Err())
+	}
+
+	src, contentType, err := p.open(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	header := make([]byte, sniffHeaderSize)
+	n, err := src.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read media header: %w", err)
+	}
+	mimeType := DetectMediaMIMEType(header[:n], contentType)
+
+	result, err := probeReaderAt(src, src.Size())
+	if err != nil {
+		if mimeType == "" {
+			return nil, err
+		}
+		result = &MediaProbeResult{
+			Kind:     MediaTypeFromMIME(mimeType),
+			Format:   mediaFormatFromMIME(mimeType),
+			FileSize: src.Size(),
+		}
+	}
+	result.URL = mediaURL
+	result.MIMEType = mimeType
+	return result, nil
+}
+
+// probeReaderAt detects the media format and parses its headers
+func probeReaderAt(r io.ReaderAt, size int64) (*MediaProbeResult, error) {
+	header := make([]byte, 16)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read media header: %w", err)
+	}
+	header = header[:n]
+
+	switch {
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return probeMP4(r, size)
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return probeWebP(r, size)
+	default:
+		return probeImage(r, size)
+	}
+}
+
+// probeImage reads image dimensions using the standard library decoders
+func probeImage(r io.ReaderAt, size int64) (*MediaProbeResult, error) {
+	cfg, format, err := image.DecodeConfig(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported or corrupt media file: %w", err)
+	}
+
+	return newImageProbeResult(format, size, cfg.Width, cfg.Height), nil
+}
+
+// probeWebP reads WebP dimensions from the VP8/VP8L/VP8X chunk header
+// The standard library has no WebP decoder, so we parse the header manually
+func probeWebP(r io.ReaderAt, size int64) (*MediaProbeResult, error) {
+	buf := make([]byte, 30)
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read WebP header: %w", err)
+	}
+	if n < 16 {
+		return nil, fmt.Errorf("truncated WebP header: %d bytes", n)
+	}
+
+	// Bytes of the file each chunk's dimensions are read from
+	need := map[string]int{"VP8 ": 30, "VP8L": 25, "VP8X": 30}[string(buf[12:16])]
+	if n < need {
+		return nil, fmt.Errorf("truncated WebP header: %d bytes", n)
+	}
+
+	var width, height int
+	switch string(buf[12:16]) {
+	case "VP8 ":
+		// Lossy: 14-bit width/height after the 3-byte frame tag and start code
+		width = int(binary.LittleEndian.Uint16(buf[26:28]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(buf[28:30]) & 0x3fff)
+	case "VP8L":
+		// Lossless: 14-bit (width-1) and (height-1) packed after the signature byte
+		bits := binary.LittleEndian.Uint32(buf[21:25])
+		width = int(bits&0x3fff) + 1
+		height = int((bits>>14)&0x3fff) + 1
+	case "VP8X":
+		// Extended: 24-bit (width-1) and (height-1)
+		width = int(uint32(buf[24])|uint32(buf[25])<<8|uint32(buf[26])<<16) + 1
+		height = int(uint32(buf[27])|uint32(buf[28])<<8|uint32(buf[29])<<16) + 1
+	default:
+		return nil, fmt.Errorf("unsupported WebP chunk: %q", string(buf[12:16]))
+	}
+
+	return newImageProbeResult("webp", size, width, height), nil
+}
+
+func newImageProbeResult(format string, size int64, width, height int) *MediaProbeResult {
+	result := &MediaProbeResult{
+		Kind:     MediaTypeImage,
+		Format:   format,
+		FileSize: size,
+		Width:    width,
+		Height:   height,
+	}
+	if height > 0 {
+		result.AspectRatio = float64(width) / float64(height)
+	}
+	return result
+}
+
+// mp4Box is a single ISO BMFF box header
+type mp4Box struct {
+	Type       string
+	Offset     int64 // Offset of the box header
+	DataOffset int64 // Offset of the box payload
+	Size       int64 // Total box size including header
+}
+
+// mp4Track collects the fields we need from a single trak box
+type mp4Track struct {
+	Handler  string // vide or soun
+	Codec    string
+	Width    int
+	Height   int
+	Rotated  bool // True if the track matrix rotates by 90 or 270 degrees
+	Duration float64
+}
+
+// probeMP4 walks the MP4/MOV box tree and extracts duration, dimensions and codecs
+func probeMP4(r io.ReaderAt, size int64) (*MediaProbeResult, error) {
+	result := &MediaProbeResult{
+		Kind:     MediaTypeVideo,
+		Format:   "mp4",
+		FileSize: size,
+	}
+
+	topLevel, err := readMP4Boxes(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var moov *mp4Box
+	for i := range topLevel {
+		switch topLevel[i].Type {
+		case "ftyp":
+			brand := make([]byte, 4)
+			if _, err := r.ReadAt(brand, topLevel[i].DataOffset); err == nil && string(brand) == "qt  " {
+				result.Format = "mov"
+			}
+		case "moov":
+			moov = &topLevel[i]
+		}
+	}
+
+	if moov == nil {
+		return nil, fmt.Errorf("invalid MP4 file: moov box not found")
+	}
+
+	children, err := readMP4Boxes(r, moov.DataOffset, moov.Offset+moov.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, box := range children {
+		switch box.Type {
+		case "mvhd":
+			duration, err := readMP4Duration(r, box, 12, 16)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse mvhd: %w", err)
+			}
+			result.DurationSec = duration
+		case "trak":
+			track, err := readMP4Track(r, box)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse trak: %w", err)
+			}
+			switch track.Handler {
+			case "vide":
+				if result.VideoCodec == "" {
+					result.VideoCodec = track.Codec
+					result.Width, result.Height = track.Width, track.Height
+					if track.Rotated {
+						result.Width, result.Height = track.Height, track.Width
+					}
+				}
+				if result.DurationSec == 0 {
+					result.DurationSec = track.Duration
+				}
+			case "soun":
+				if result.AudioCodec == "" {
+					result.AudioCodec = track.Codec
+				}
+			}
+		}
+	}
+
+	if result.Height > 0 {
+		result.AspectRatio = float64(result.Width) / float64(result.Height)
+	}
+	if result.DurationSec > 0 {
+		result.Bitrate = int64(float64(size*8) / result.DurationSec)
+	}
+
+	return result, nil
+}
+
+// readMP4Boxes reads the sibling box headers in the range [start, end)
+func readMP4Boxes(r io.ReaderAt, start, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	header := make([]byte, 16)
+
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return nil, fmt.Errorf("failed to read box header at %d: %w", offset, err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		boxType := string(header[4:8])
+		headerSize := int64(8)
+
+		switch size {
+		case 0:
+			// Box extends to the end of the enclosing range
+			size = end - offset
+		case 1:
+			// 64-bit extended size follows the type
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return nil, fmt.Errorf("failed to read extended box size: %w", err)
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+
+		if size < headerSize || size > end-offset {
+			return nil, fmt.Errorf("invalid MP4 box %q at offset %d", boxType, offset)
+		}
+
+		boxes = append(boxes, mp4Box{
+			Type:       boxType,
+			Offset:     offset,
+			DataOffset: offset + headerSize,
+			Size:       size,
+		})
+		offset += size
+	}
+
+	return boxes, nil
+}
+
+// findMP4Box descends through the given path of box types and returns the last one
+func findMP4Box(r io.ReaderAt, parent mp4Box, path ...string) (*mp4Box, error) {
+	if len(path) > maxProbeBoxDepth {
+		return nil, fmt.Errorf("MP4 box path too deep")
+	}
+
+	current := parent
+	for _, boxType := range path {
+		children, err := readMP4Boxes(r, current.DataOffset, current.Offset+current.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, child := range children {
+			if child.Type == boxType {
+				current = child
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+	return &current, nil
+}
+
+// readMP4Duration reads a (timescale, duration) pair from a full box such as mvhd or mdhd
+// v0Offset/v1Offset are the offsets of the timescale field for version 0 and 1 boxes
+func readMP4Duration(r io.ReaderAt, box mp4Box, v0Offset, v1Offset int64) (float64, error) {
+	buf := make([]byte, 32)
+	if _, err := r.ReadAt(buf, box.DataOffset); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	var timescale uint32
+	var duration uint64
+	if buf[0] == 1 {
+		timescale = binary.BigEndian.Uint32(buf[v1Offset+4 : v1Offset+8])
+		duration = binary.BigEndian.Uint64(buf[v1Offset+8 : v1Offset+16])
+	} else {
+		timescale = binary.BigEndian.Uint32(buf[v0Offset : v0Offset+4])
+		duration = uint64(binary.BigEndian.Uint32(buf[v0Offset+4 : v0Offset+8]))
+	}
+
+	if timescale == 0 {
+		return 0, nil
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+// readMP4Track extracts handler type, codec, dimensions and duration from a trak box
+func readMP4Track(r io.ReaderAt, trak mp4Box) (*mp4Track, error) {
+	track := &mp4Track{}
+
+	// tkhd: display dimensions (16.16 fixed point) and transformation matrix
+	if tkhd, err := findMP4Box(r, trak, "tkhd"); err != nil {
+		return nil, err
+	} else if tkhd != nil {
+		buf := make([]byte, 96)
+		if _, err := r.ReadAt(buf, tkhd.DataOffset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		// Version 1 has 64-bit creation/modification/duration fields (12 extra bytes)
+		base := 0
+		if buf[0] == 1 {
+			base = 12
+		}
+		if len(buf) >= base+84 {
+			matrixA := int32(binary.BigEndian.Uint32(buf[base+40 : base+44]))
+			matrixB := int32(binary.BigEndian.Uint32(buf[base+44 : base+48]))
+			track.Rotated = matrixA == 0 && (matrixB == 0x10000 || matrixB == -0x10000)
+			track.Width = int(binary.BigEndian.Uint32(buf[base+76:base+80]) >> 16)
+			track.Height = int(binary.BigEndian.Uint32(buf[base+80:base+84]) >> 16)
+		}
+	}
+
+	// mdia/hdlr: track type
+	if hdlr, err := findMP4Box(r, trak, "mdia", "hdlr"); err != nil {
+		return nil, err
+	} else if hdlr != nil {
+		buf := make([]byte, 12)
+		if _, err := r.ReadAt(buf, hdlr.DataOffset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		track.Handler = string(buf[8:12])
+	}
+
+	// mdia/mdhd: track duration
+	if mdhd, err := findMP4Box(r, trak, "mdia", "mdhd"); err != nil {
+		return nil, err
+	} else if mdhd != nil {
+		duration, err := readMP4Duration(r, *mdhd, 12, 16)
+		if err != nil {
+			return nil, err
+		}
+		track.Duration = duration
+	}
+
+	// mdia/minf/stbl/stsd: first sample entry carries the codec fourcc
+	if stsd, err := findMP4Box(r, trak, "mdia", "minf", "stbl", "stsd"); err != nil {
+		return nil, err
+	} else if stsd != nil {
+		buf := make([]byte, maxProbeSampleEntry)
+		n, err := r.ReadAt(buf, stsd.DataOffset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		// version/flags (4) + entry count (4) + entry size (4) + entry type (4)
+		if n >= 16 {
+			track.Codec = strings.TrimSpace(string(buf[12:16]))
+		}
+		// Fall back to coded dimensions from the visual sample entry if tkhd had none
+		if track.Handler == "vide" && track.Width == 0 && n >= 44 {
+			track.Width = int(binary.BigEndian.Uint16(buf[40:42]))
+			track.Height = int(binary.BigEndian.Uint16(buf[42:44]))
+		}
+	}
+
+	return track, nil
+}
+
+// probeSource is a random-access view of remote media
+type probeSource interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// open returns a random-access view of the media at mediaURL together with its Content-Type
+// Prefers HTTP range requests; falls back to the leading bytes of the body
+func (p *MediaProber) open(ctx context.Context, mediaURL string) (probeSource, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", probeBlockSize-1))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch media: %w", err)
+	}
+	defer resp.Body.Close()
+	contentType := resp.Header.Get("Content-Type")
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		total, err := parseContentRangeTotal(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, "", err
+		}
+		first, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read media: %w", err)
+		}
+		src := &httpRangeSource{
+			ctx:    ctx,
+			client: p.httpClient,
+			url:    mediaURL,
+			size:   total,
+			blocks: map[int64][]byte{},
+		}
+		if int64(len(first)) == min64(probeBlockSize, total) {
+			src.blocks[0] = first
+		}
+		return src, contentType, nil
+
+	case http.StatusOK:
+		// Only the leading bytes are read: they hold the headers of images and of MP4s with
+		// the moov box first, and Content-Length is enough to reject the file's size
+		src, err := headProbeSource(resp.Body, resp.ContentLength)
+		return src, contentType, err
+
+	default:
+		return nil, "", fmt.Errorf("failed to fetch media: status %d", resp.StatusCode)
+	}
+}
+
+// parseContentRangeTotal extracts the complete length from a "bytes a-b/total" header
+func parseContentRangeTotal(contentRange string) (int64, error) {
+	idx := strings.LastIndex(contentRange, "/")
+	if idx == -1 || contentRange[idx+1:] == "*" {
+		return 0, fmt.Errorf("server did not report media size")
+	}
+	total, err := strconv.ParseInt(contentRange[idx+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range header: %w", err)
+	}
+	return total, nil
+}
+
+// httpRangeSource implements io.ReaderAt with cached HTTP range requests
+// ctx is the context of the Probe call the source belongs to
+type httpRangeSource struct {
+	ctx    context.Context
+	client *http.Client
+	url    string
+	size   int64
+	mu     sync.Mutex
+	blocks map[int64][]byte
+}
+
+func (s *httpRangeSource) Size() int64  { return s.size }
+func (s *httpRangeSource) Close() error { return nil }
+
+// ReadAt reads len(p) bytes starting at off, fetching missing blocks on demand
+func (s *httpRangeSource) ReadAt(p []byte, off int64) (int, error) {
+	if off >= s.size {
+		return 0, io.EOF
+	}
+
+	read := 0
+	for read < len(p) && off+int64(read) < s.size {
+		pos := off + int64(read)
+		blockIndex := pos / probeBlockSize
+		block, err := s.block(blockIndex)
+		if err != nil {
+			return read, err
+		}
+		read += copy(p[read:], block[pos-blockIndex*probeBlockSize:])
+	}
+
+	if read < len(p) {
+		return read, io.EOF
+	}
+	return read, nil
+}
+
+// block returns the cached block at index, fetching it with a range request if needed
+func (s *httpRangeSource) block(index int64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if block, ok := s.blocks[index]; ok {
+		return block, nil
+	}
+
+	start := index * probeBlockSize
+	end := min64(start+probeBlockSize, s.size) - 1
+
+	req, err := http.NewRequestWithContext(s.ctx, "GET", s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch media range: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("failed to fetch media range: status %d", resp.StatusCode)
+	}
+
+	block, err := io.ReadAll(io.LimitReader(resp.Body, end-start+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media range: %w", err)
+	}
+	if int64(len(block)) != end-start+1 {
+		return nil, fmt.Errorf("short media range: got %d bytes, want %d", len(block), end-start+1)
+	}
+
+	s.blocks[index] = block
+	return block, nil
+}
+
+// headSource holds only the leading bytes of a media file whose full size is known
+type headSource struct {
+	head []byte
+	size int64
+}
+
+func (s *headSource) Size() int64  { return s.size }
+func (s *headSource) Close() error { return nil }
+
+// ReadAt serves reads from the leading bytes; anything beyond them is unavailable
+func (s *headSource) ReadAt(p []byte, off int64) (int, error) {
+	if off >= s.size {
+		return 0, io.EOF
+	}
+	if off >= int64(len(s.head)) {
+		return 0, fmt.Errorf("media headers are beyond the first %d bytes and the server does not support range requests", len(s.head))
+	}
+	n := copy(p, s.head[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// headProbeSource reads up to maxProbeHeadSize leading bytes of body
+// size is the Content-Length, or -1 if the server didn't send one; media that ends within the
+// leading bytes is read whole, so its size is known either way
+func headProbeSource(body io.Reader, size int64) (probeSource, error) {
+	head, err := io.ReadAll(io.LimitReader(body, maxProbeHeadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	if len(head) <= maxProbeHeadSize {
+		return &headSource{head: head, size: int64(len(head))}, nil
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("server reported no media size and does not support range requests")
+	}
+	return &headSource{head: head[:maxProbeHeadSize], size: size}, nil
+}
+
+// min64 returns the minimum of two int64 values
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// samplePNG encodes a blank PNG of the given size
+func samplePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHeadProbeSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		bodySize      int64
+		contentLength int64
+		wantErr       bool
+		wantSize      int64
+		wantHead      int
+	}{
+		{name: "small body without length", bodySize: 100, contentLength: -1, wantSize: 100, wantHead: 100},
+		{name: "small body with length", bodySize: 100, contentLength: 100, wantSize: 100, wantHead: 100},
+		{name: "exactly the head size", bodySize: maxProbeHeadSize, contentLength: -1, wantSize: maxProbeHeadSize, wantHead: maxProbeHeadSize},
+		{name: "large body with length", bodySize: 64 * maxProbeHeadSize, contentLength: 64 * maxProbeHeadSize, wantSize: 64 * maxProbeHeadSize, wantHead: maxProbeHeadSize},
+		{name: "large body without length", bodySize: maxProbeHeadSize + 1, contentLength: -1, wantErr: true},
+		{name: "empty body", bodySize: 0, contentLength: 0, wantSize: 0, wantHead: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &countingReader{r: io.LimitReader(neverEnding('x'), tt.bodySize)}
+
+			src, err := headProbeSource(body, tt.contentLength)
+			if body.n > maxProbeHeadSize+1 {
+				t.Errorf("read %d bytes of the body, want at most %d", body.n, maxProbeHeadSize+1)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("headProbeSource() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer src.Close()
+
+			if src.Size() != tt.wantSize {
+				t.Errorf("size = %d, want %d", src.Size(), tt.wantSize)
+			}
+			if head := len(src.(*headSource).head); head != tt.wantHead {
+				t.Errorf("head = %d bytes, want %d", head, tt.wantHead)
+			}
+		})
+	}
+}
+
+func TestHeadSourceReadAt(t *testing.T) {
+	src := &headSource{head: []byte("0123456789"), size: 100}
+
+	tests := []struct {
+		name    string
+		off     int64
+		n       int
+		want    string
+		wantEOF bool
+		wantErr bool // Data beyond the head can't be read
+	}{
+		{name: "within the head", off: 2, n: 3, want: "234"},
+		{name: "up to the end of the head", off: 7, n: 5, want: "789", wantEOF: true},
+		{name: "beyond the head", off: 10, n: 1, wantErr: true},
+		{name: "beyond the file", off: 100, n: 1, wantEOF: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]byte, tt.n)
+			n, err := src.ReadAt(p, tt.off)
+			if got := string(p[:n]); got != tt.want {
+				t.Errorf("read %q, want %q", got, tt.want)
+			}
+			switch {
+			case tt.wantErr:
+				if err == nil || err == io.EOF {
+					t.Errorf("error = %v, want one for data beyond the head", err)
+				}
+			case tt.wantEOF:
+				if err != io.EOF {
+					t.Errorf("error = %v, want EOF", err)
+				}
+			case err != nil:
+				t.Errorf("error = %v, want none", err)
+			}
+		})
+	}
+}
+
+func TestProbeWithoutRangeSupport(t *testing.T) {
+	img := samplePNG(t, 40, 30)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ignores Range and sends the whole file
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(img)
+	}))
+	defer server.Close()
+
+	result, err := NewMediaProber().Probe(context.Background(), server.URL+"/image.png")
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if result.Format != "png" || result.Width != 40 || result.Height != 30 || result.FileSize != int64(len(img)) {
+		t.Errorf("result = %+v, want a 40x30 png of %d bytes", result, len(img))
+	}
+}
+
+func TestProbeLimitsConcurrency(t *testing.T) {
+	img := samplePNG(t, 10, 10)
+	release := make(chan struct{})
+	var running, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		<-release
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(img)
+	}))
+	defer server.Close()
+
+	prober := NewMediaProber()
+	var wg sync.WaitGroup
+	for i := 0; i < 3*maxConcurrentProbes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := prober.Probe(context.Background(), server.URL+"/image.png"); err != nil {
+				t.Errorf("Probe() error = %v", err)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for running.Load() < maxConcurrentProbes && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := peak.Load(); got != maxConcurrentProbes {
+		t.Errorf("at most %d probes ran at once, want %d", got, maxConcurrentProbes)
+	}
+}
+
+func TestProbeWaitingForSlotStopsWithContext(t *testing.T) {
+	prober := NewMediaProber()
+	for i := 0; i < maxConcurrentProbes; i++ {
+		prober.slots <- struct{}{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := prober.Probe(ctx, "http://127.0.0.1:1/never.png"); err == nil {
+		t.Errorf("Probe() succeeded without a free slot")
+	}
+}
+
+// mp4Atom encodes an MP4 box with the given payload
+func mp4Atom(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	out = append(out, boxType...)
+	return append(out, body...)
+}
+
+// be32 encodes big-endian uint32 values
+func be32(values ...uint32) []byte {
+	var out []byte
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint32(out, v)
+	}
+	return out
+}
+
+// mvhdV0 encodes a version 0 movie header
+func mvhdV0(timescale, duration uint32) []byte {
+	return mp4Atom("mvhd", be32(0, 0, 0, timescale, duration))
+}
+
+// tkhdV0 encodes a version 0 track header with the given matrix a and b values and display size
+func tkhdV0(matrixA, matrixB int32, width, height uint32) []byte {
+	payload := make([]byte, 84)
+	binary.BigEndian.PutUint32(payload[40:], uint32(matrixA))
+	binary.BigEndian.PutUint32(payload[44:], uint32(matrixB))
+	binary.BigEndian.PutUint32(payload[76:], width<<16)
+	binary.BigEndian.PutUint32(payload[80:], height<<16)
+	return mp4Atom("tkhd", payload)
+}
+
+// mp4Trak encodes a track with a handler, duration, codec and coded size in its sample entry
+func mp4Trak(tkhd []byte, handler, codec string, timescale, duration uint32, codedWidth, codedHeight uint16) []byte {
+	entry := make([]byte, 36)
+	binary.BigEndian.PutUint32(entry[0:], 36)
+	copy(entry[4:], codec)
+	binary.BigEndian.PutUint16(entry[32:], codedWidth)
+	binary.BigEndian.PutUint16(entry[34:], codedHeight)
+
+	return mp4Atom("trak",
+		tkhd,
+		mp4Atom("mdia",
+			mp4Atom("mdhd", be32(0, 0, 0, timescale, duration)),
+			mp4Atom("hdlr", be32(0, 0), []byte(handler), make([]byte, 12)),
+			mp4Atom("minf", mp4Atom("stbl", mp4Atom("stsd", be32(0, 1), entry))),
+		),
+	)
+}
+
+// sampleMP4 is a 10 second 1920x1080 H.264 video with AAC audio
+func sampleMP4(brand string, moov ...[]byte) []byte {
+	if moov == nil {
+		moov = [][]byte{
+			mvhdV0(1000, 10000),
+			mp4Trak(tkhdV0(0x10000, 0, 1920, 1080), "vide", "avc1", 1000, 10000, 1920, 1080),
+			mp4Trak(tkhdV0(0x10000, 0, 0, 0), "soun", "mp4a", 44100, 441000, 0, 0),
+		}
+	}
+	return bytes.Join([][]byte{
+		mp4Atom("ftyp", []byte(brand), be32(0)),
+		mp4Atom("moov", moov...),
+		mp4Atom("mdat", make([]byte, 64)),
+	}, nil)
+}
+
+func TestProbeMP4(t *testing.T) {
+	extendedSize := append(be32(1), "mdat"...)
+	extendedSize = binary.BigEndian.AppendUint64(extendedSize, 1<<62)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+		want    MediaProbeResult
+	}{
+		{
+			name: "mp4 with video and audio",
+			data: sampleMP4("isom"),
+			want: MediaProbeResult{Format: "mp4", Width: 1920, Height: 1080, DurationSec: 10, VideoCodec: "avc1", AudioCodec: "mp4a"},
+		},
+		{
+			name: "quicktime brand",
+			data: sampleMP4("qt  "),
+			want: MediaProbeResult{Format: "mov", Width: 1920, Height: 1080, DurationSec: 10, VideoCodec: "avc1", AudioCodec: "mp4a"},
+		},
+		{
+			name: "rotated by 90 degrees",
+			data: sampleMP4("isom",
+				mvhdV0(600, 3000),
+				mp4Trak(tkhdV0(0, 0x10000, 1920, 1080), "vide", "hvc1", 600, 3000, 1920, 1080),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 1080, Height: 1920, DurationSec: 5, VideoCodec: "hvc1"},
+		},
+		{
+			name: "rotated by 270 degrees",
+			data: sampleMP4("isom",
+				mvhdV0(600, 3000),
+				mp4Trak(tkhdV0(0, -0x10000, 1280, 720), "vide", "avc1", 600, 3000, 1280, 720),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 720, Height: 1280, DurationSec: 5, VideoCodec: "avc1"},
+		},
+		{
+			name: "version 1 movie header",
+			data: sampleMP4("isom",
+				mp4Atom("mvhd", be32(1<<24, 0, 0, 0, 0, 90000, 0, 90000*7)),
+				mp4Trak(tkhdV0(0x10000, 0, 640, 480), "vide", "avc1", 90000, 90000*7, 640, 480),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 640, Height: 480, DurationSec: 7, VideoCodec: "avc1"},
+		},
+		{
+			name: "duration from the track without movie header",
+			data: sampleMP4("isom",
+				mp4Trak(tkhdV0(0x10000, 0, 640, 480), "vide", "avc1", 25, 50, 640, 480),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 640, Height: 480, DurationSec: 2, VideoCodec: "avc1"},
+		},
+		{
+			name: "coded size when the track header has none",
+			data: sampleMP4("isom",
+				mvhdV0(1000, 1000),
+				mp4Trak(tkhdV0(0x10000, 0, 0, 0), "vide", "avc1", 1000, 1000, 720, 1280),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 720, Height: 1280, DurationSec: 1, VideoCodec: "avc1"},
+		},
+		{
+			name: "zero timescale",
+			data: sampleMP4("isom",
+				mvhdV0(0, 1000),
+				mp4Trak(tkhdV0(0x10000, 0, 640, 480), "vide", "avc1", 0, 1000, 640, 480),
+			),
+			want: MediaProbeResult{Format: "mp4", Width: 640, Height: 480, VideoCodec: "avc1"},
+		},
+		{
+			name: "box extending to the end of the file",
+			data: append(append(mp4Atom("ftyp", []byte("isom"), be32(0)), mp4Atom("moov", mvhdV0(1000, 4000))...), append(be32(0), "mdat\x00\x00"...)...),
+			want: MediaProbeResult{Format: "mp4", DurationSec: 4},
+		},
+		{
+			name: "trailing bytes shorter than a box header",
+			data: append(sampleMP4("isom"), 0, 0, 0),
+			want: MediaProbeResult{Format: "mp4", Width: 1920, Height: 1080, DurationSec: 10, VideoCodec: "avc1", AudioCodec: "mp4a"},
+		},
+		{
+			name:    "no moov box",
+			data:    append(mp4Atom("ftyp", []byte("isom"), be32(0)), mp4Atom("mdat", make([]byte, 16))...),
+			wantErr: true,
+		},
+		{
+			name:    "truncated file",
+			data:    sampleMP4("isom")[:200],
+			wantErr: true,
+		},
+		{
+			name:    "box smaller than its header",
+			data:    append(mp4Atom("ftyp", []byte("isom"), be32(0)), append(be32(4), "moov"...)...),
+			wantErr: true,
+		},
+		{
+			name:    "extended size beyond the file",
+			data:    append(mp4Atom("ftyp", []byte("isom"), be32(0)), extendedSize...),
+			wantErr: true,
+		},
+		{
+			name:    "box size overflowing the offset",
+			data:    append(mp4Atom("ftyp", []byte("isom"), be32(0)), append(append(be32(1), "moov"...), binary.BigEndian.AppendUint64(nil, 1<<63-1)...)...),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeReaderAt(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeReaderAt() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := tt.want
+			want.Kind = MediaTypeVideo
+			want.FileSize = int64(len(tt.data))
+			if want.Height > 0 {
+				want.AspectRatio = float64(want.Width) / float64(want.Height)
+			}
+			if want.DurationSec > 0 {
+				want.Bitrate = int64(float64(len(tt.data)*8) / want.DurationSec)
+			}
+			if *got != want {
+				t.Errorf("probeReaderAt() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+// webpFile encodes a WebP file with a single chunk
+func webpFile(chunk string, payload []byte) []byte {
+	out := []byte("RIFF")
+	out = binary.LittleEndian.AppendUint32(out, uint32(12+len(payload)))
+	out = append(out, "WEBP"...)
+	out = append(out, chunk...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(payload)))
+	return append(out, payload...)
+}
+
+func TestProbeWebP(t *testing.T) {
+	// Lossy: frame tag, start code, then 14-bit width and height with 2-bit scale
+	vp8 := []byte{0x9d, 0x01, 0x2a, 0x9d, 0x01, 0x2a}
+	vp8 = binary.LittleEndian.AppendUint16(vp8, 1080|0xc000)
+	vp8 = binary.LittleEndian.AppendUint16(vp8, 1350|0x4000)
+
+	// Lossless: signature, then width-1 and height-1 packed in 14 bits each
+	vp8l := []byte{0x2f}
+	vp8l = binary.LittleEndian.AppendUint32(vp8l, uint32(800-1)|uint32(600-1)<<14)
+
+	// Extended: flags and reserved bytes, then 24-bit width-1 and height-1
+	vp8x := []byte{0x10, 0, 0, 0, 0xff, 0x0f, 0x00, 0x37, 0x04, 0x00}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantErr    bool
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "lossy", data: webpFile("VP8 ", vp8), wantWidth: 1080, wantHeight: 1350},
+		{name: "lossless", data: webpFile("VP8L", vp8l), wantWidth: 800, wantHeight: 600},
+		{name: "extended", data: webpFile("VP8X", vp8x), wantWidth: 4096, wantHeight: 1080},
+		{name: "lossless at the smallest size", data: webpFile("VP8L", []byte{0x2f, 0, 0, 0, 0, 0}), wantWidth: 1, wantHeight: 1},
+		{name: "unknown chunk", data: webpFile("ALPH", make([]byte, 10)), wantErr: true},
+		{name: "truncated lossy header", data: webpFile("VP8 ", vp8)[:27], wantErr: true},
+		{name: "truncated extended header", data: webpFile("VP8X", vp8x)[:25], wantErr: true},
+		{name: "truncated lossless header", data: webpFile("VP8L", vp8l)[:24], wantErr: true},
+		{name: "only the RIFF header", data: webpFile("VP8 ", nil)[:12], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeReaderAt(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeReaderAt() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Format != "webp" || got.Kind != MediaTypeImage || got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("probeReaderAt() = %+v, want a %dx%d webp image", got, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestProbeImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantErr    bool
+		wantFormat string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "png", data: samplePNG(t, 1080, 1350), wantFormat: "png", wantWidth: 1080, wantHeight: 1350},
+		{name: "gif", data: []byte("GIF89a\x40\x01\xf0\x00\x00\x00\x00;"), wantFormat: "gif", wantWidth: 320, wantHeight: 240},
+		{name: "truncated png", data: samplePNG(t, 10, 10)[:12], wantErr: true},
+		{name: "unknown data", data: []byte("not an image at all"), wantErr: true},
+		{name: "empty", data: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeReaderAt(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeReaderAt() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Format != tt.wantFormat || got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("probeReaderAt() = %+v, want a %dx%d %s", got, tt.wantWidth, tt.wantHeight, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestParseContentRangeTotal(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{header: "bytes 0-65535/1048576", want: 1048576},
+		{header: "bytes 0-9/10", want: 10},
+		{header: "bytes 0-65535/*", wantErr: true},
+		{header: "bytes 0-65535/big", wantErr: true},
+		{header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseContentRangeTotal(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseContentRangeTotal(%q) error = %v, want error %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseContentRangeTotal(%q) = %d, want %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+)
+
+// sniffHeaderSize is the number of leading bytes needed to recognise every signature below
+const sniffHeaderSize = 512
+
+// mediaFormats maps the MIME types we recognise to the short format names used in constraints
+var mediaFormats = map[string]string{
+	"image/jpeg":       "jpeg",
+	"image/png":        "png",
+	"image/gif":        "gif",
+	"image/webp":       "webp",
+	"image/bmp":        "bmp",
+	"image/tiff":       "tiff",
+	"image/heic":       "heic",
+	"image/avif":       "avif",
+	"video/mp4":        "mp4",
+	"video/quicktime":  "mov",
+	"video/webm":       "webm",
+	"video/x-matroska": "mkv",
+	"video/mp2t":       "ts",
+	"video/x-msvideo":  "avi",
+	"video/3gpp":       "3gp",
+}
+
+// SniffMediaMIMEType detects the MIME type of media from its leading bytes
+// Returns an empty string if the bytes do not match a known image or video signature
+func SniffMediaMIMEType(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "image/gif"
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return "image/webp"
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "AVI ":
+		return "video/x-msvideo"
+	case bytes.HasPrefix(header, []byte("BM")) && len(header) >= 14:
+		return "image/bmp"
+	case bytes.HasPrefix(header, []byte("II*\x00")), bytes.HasPrefix(header, []byte("MM\x00*")):
+		return "image/tiff"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// EBML header; the DocType tells WebM and Matroska apart
+		if bytes.Contains(header[:min(len(header), 64)], []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case len(header) >= 189 && header[0] == 0x47 && header[188] == 0x47:
+		return "video/mp2t"
+	case len(header) >= 12 && string(header[4:8]) == "ftyp":
+		return sniffFtypBrand(string(header[8:12]))
+	case len(header) >= 8 && (string(header[4:8]) == "moov" || string(header[4:8]) == "mdat" ||
+		string(header[4:8]) == "wide" || string(header[4:8]) == "free"):
+		// Old QuickTime files start without an ftyp box
+		return "video/quicktime"
+	}
+	return ""
+}
+
+// sniffFtypBrand maps the major brand of an ISO BMFF file to a MIME type
+func sniffFtypBrand(brand string) string {
+	switch brand {
+	case "qt  ":
+		return "video/quicktime"
+	case "heic", "heix", "heim", "heis", "mif1", "msf1":
+		return "image/heic"
+	case "avif", "avis":
+		return "image/avif"
+	case "3gp4", "3gp5", "3gp6", "3g2a":
+		return "video/3gpp"
+	}
+	return "video/mp4"
+}
+
+// MediaTypeFromMIME returns whether a MIME type is an image or a video
+func MediaTypeFromMIME(mimeType string) MediaType {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return MediaTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return MediaTypeVideo
+	}
+	return MediaTypeUnknown
+}
+
+// mediaFormatFromMIME returns the short format name (mp4, jpeg, ...) of a MIME type
+func mediaFormatFromMIME(mimeType string) string {
+	if format, ok := mediaFormats[mimeType]; ok {
+		return format
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(mimeType, "image/"), "video/")
+}
+
+// DetectMediaMIMEType determines the MIME type of media, trusting the bytes over the headers
+// Order: magic bytes, then the Content-Type header if it names an image or video
+// Returns an empty string if neither identifies the media
+func DetectMediaMIMEType(header []byte, contentType string) string {
+	if sniffed := SniffMediaMIMEType(header); sniffed != "" {
+		return sniffed
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && MediaTypeFromMIME(mediaType) != MediaTypeUnknown {
+		return mediaType
+	}
+	return ""
+}
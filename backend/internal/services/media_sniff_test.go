@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+)
+
+// withPadding appends n zero bytes to a signature
+func withPadding(signature string, n int) []byte {
+	return append([]byte(signature), make([]byte, n)...)
+}
+
+// mpegTS returns two transport stream packets' worth of sync bytes
+func mpegTS() []byte {
+	data := make([]byte, 2*188)
+	data[0], data[188] = 0x47, 0x47
+	return data
+}
+
+func TestSniffMediaMIMEType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{name: "jpeg", header: withPadding("\xFF\xD8\xFF\xE0", 16), want: "image/jpeg"},
+		{name: "jpeg signature only", header: []byte{0xFF, 0xD8, 0xFF}, want: "image/jpeg"},
+		{name: "truncated jpeg signature", header: []byte{0xFF, 0xD8}, want: ""},
+		{name: "png", header: withPadding("\x89PNG\r\n\x1a\n", 16), want: "image/png"},
+		{name: "truncated png signature", header: []byte("\x89PNG\r\n"), want: ""},
+		{name: "gif87a", header: withPadding("GIF87a", 8), want: "image/gif"},
+		{name: "gif89a", header: withPadding("GIF89a", 8), want: "image/gif"},
+		{name: "unknown gif version", header: withPadding("GIF88a", 8), want: ""},
+		{name: "webp", header: withPadding("RIFF\x00\x00\x00\x00WEBPVP8 ", 16), want: "image/webp"},
+		{name: "avi", header: withPadding("RIFF\x00\x00\x00\x00AVI LIST", 16), want: "video/x-msvideo"},
+		{name: "riff of another kind", header: withPadding("RIFF\x00\x00\x00\x00WAVEfmt ", 16), want: ""},
+		{name: "truncated riff", header: []byte("RIFF\x00\x00\x00\x00WEB"), want: ""},
+		{name: "bmp", header: withPadding("BM", 12), want: "image/bmp"},
+		{name: "bmp shorter than its file header", header: withPadding("BM", 11), want: ""},
+		{name: "little endian tiff", header: withPadding("II*\x00", 8), want: "image/tiff"},
+		{name: "big endian tiff", header: withPadding("MM\x00*", 8), want: "image/tiff"},
+		{name: "webm", header: append([]byte{0x1A, 0x45, 0xDF, 0xA3}, withPadding("\x42\x82\x84webm", 16)...), want: "video/webm"},
+		{name: "matroska", header: append([]byte{0x1A, 0x45, 0xDF, 0xA3}, withPadding("\x42\x82\x88matroska", 16)...), want: "video/x-matroska"},
+		{name: "ebml header only", header: []byte{0x1A, 0x45, 0xDF, 0xA3}, want: "video/x-matroska"},
+		{name: "webm doc type beyond the first 64 bytes", header: append(append([]byte{0x1A, 0x45, 0xDF, 0xA3}, make([]byte, 64)...), "webm"...), want: "video/x-matroska"},
+		{name: "mpeg transport stream", header: mpegTS(), want: "video/mp2t"},
+		{name: "transport stream sync byte without the second packet", header: mpegTS()[:188], want: ""},
+		{name: "mp4", header: withPadding("\x00\x00\x00\x20ftypisom", 16), want: "video/mp4"},
+		{name: "mp4 with another brand", header: withPadding("\x00\x00\x00\x20ftypmp42", 16), want: "video/mp4"},
+		{name: "quicktime brand", header: withPadding("\x00\x00\x00\x14ftypqt  ", 16), want: "video/quicktime"},
+		{name: "heic", header: withPadding("\x00\x00\x00\x18ftypheic", 16), want: "image/heic"},
+		{name: "heif image", header: withPadding("\x00\x00\x00\x18ftypmif1", 16), want: "image/heic"},
+		{name: "avif", header: withPadding("\x00\x00\x00\x1cftypavif", 16), want: "image/avif"},
+		{name: "3gp", header: withPadding("\x00\x00\x00\x14ftyp3gp5", 16), want: "video/3gpp"},
+		{name: "ftyp without brand", header: []byte("\x00\x00\x00\x08ftyp"), want: ""},
+		{name: "quicktime starting with moov", header: withPadding("\x00\x00\x01\x00moov", 8), want: "video/quicktime"},
+		{name: "quicktime starting with mdat", header: withPadding("\x00\x00\x01\x00mdat", 8), want: "video/quicktime"},
+		{name: "quicktime starting with wide", header: withPadding("\x00\x00\x00\x08wide", 8), want: "video/quicktime"},
+		{name: "html error page", header: []byte("<!DOCTYPE html><html><body>Not found</body></html>"), want: ""},
+		{name: "json", header: []byte(`{"error":"not found"}`), want: ""},
+		{name: "empty", header: nil, want: ""},
+		{name: "single byte", header: []byte{0xFF}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SniffMediaMIMEType(tt.header); got != tt.want {
+				t.Errorf("SniffMediaMIMEType(% x) = %q, want %q", tt.header[:min(len(tt.header), 16)], got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMediaMIMEType(t *testing.T) {
+	png := withPadding("\x89PNG\r\n\x1a\n", 16)
+
+	tests := []struct {
+		name        string
+		header      []byte
+		contentType string
+		want        string
+	}{
+		{name: "bytes win over the header", header: png, contentType: "video/mp4", want: "image/png"},
+		{name: "bytes without header", header: png, contentType: "", want: "image/png"},
+		{name: "header when the bytes are unknown", header: []byte("unknown"), contentType: "video/mp4", want: "video/mp4"},
+		{name: "header with parameters", header: nil, contentType: "image/jpeg; charset=binary", want: "image/jpeg"},
+		{name: "header in upper case", header: nil, contentType: "Image/PNG", want: "image/png"},
+		{name: "header that isn't media", header: nil, contentType: "text/html; charset=utf-8", want: ""},
+		{name: "generic binary header", header: nil, contentType: "application/octet-stream", want: ""},
+		{name: "malformed header", header: nil, contentType: "image/", want: ""},
+		{name: "nothing to go on", header: nil, contentType: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMediaMIMEType(tt.header, tt.contentType); got != tt.want {
+				t.Errorf("DetectMediaMIMEType(%q) = %q, want %q", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaTypeFromMIME(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     MediaType
+	}{
+		{mimeType: "image/jpeg", want: MediaTypeImage},
+		{mimeType: "video/quicktime", want: MediaTypeVideo},
+		{mimeType: "audio/mpeg", want: MediaTypeUnknown},
+		{mimeType: "", want: MediaTypeUnknown},
+		{mimeType: "imagery/png", want: MediaTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := MediaTypeFromMIME(tt.mimeType); got != tt.want {
+			t.Errorf("MediaTypeFromMIME(%q) = %q, want %q", tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestMediaFormatFromMIME(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     string
+	}{
+		{mimeType: "image/jpeg", want: "jpeg"},
+		{mimeType: "video/quicktime", want: "mov"},
+		{mimeType: "video/x-matroska", want: "mkv"},
+		{mimeType: "video/mp2t", want: "ts"},
+		{mimeType: "image/x-icon", want: "x-icon"},
+		{mimeType: "video/ogg", want: "ogg"},
+	}
+
+	for _, tt := range tests {
+		if got := mediaFormatFromMIME(tt.mimeType); got != tt.want {
+			t.Errorf("mediaFormatFromMIME(%q) = %q, want %q", tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestSniffHeaderSizeCoversSignatures(t *testing.T) {
+	// The transport stream check reads the furthest into the file
+	if sniffHeaderSize < len(mpegTS()[:189]) {
+		t.Errorf("sniffHeaderSize = %d, too small for the transport stream signature", sniffHeaderSize)
+	}
+	if got := SniffMediaMIMEType(bytes.Repeat([]byte{0}, sniffHeaderSize)); got != "" {
+		t.Errorf("SniffMediaMIMEType(zeros) = %q, want none", got)
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MediaStore keeps processed media variants on local disk so platforms can fetch them
+// Files are served by the /media route under the configured public base URL
+type MediaStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewMediaStore creates a media store that writes to dir and builds URLs from baseURL
+func NewMediaStore(dir, baseURL string) *MediaStore {
+	return &MediaStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Dir returns the directory processed media is written to
+func (s *MediaStore) Dir() string {
+	return s.dir
+}
+
+// Save writes data to a new file with the given extension and returns its public URL
+func (s *MediaStore) Save(data []byte, ext string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	name, err := randomMediaName()
+	if err != nil {
+		return "", err
+	}
+	name += ext
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write media file: %w", err)
+	}
+
+	return fmt.Sprintf("%s/media/%s", s.baseURL, name), nil
+}
+
+// randomMediaName generates an unguessable file name for stored media
+func randomMediaName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate media file name: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
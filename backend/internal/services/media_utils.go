@@ -42,6 +42,8 @@ var videoExtensions = map[string]bool{
 
 // DetectMediaTypeFromURL determines if the URL points to a video or image
 // based on the file extension in the URL path
+// This is only a guess; prefer the probed kind or DetectMediaMIMEType on the downloaded bytes
+// Returns MediaTypeUnknown for URLs without a known extension
 func DetectMediaTypeFromURL(mediaURL string) MediaType {
 	parsedURL, err := url.Parse(mediaURL)
 	if err != nil {
@@ -62,8 +64,22 @@ func DetectMediaTypeFromURL(mediaURL string) MediaType {
 		return MediaTypeVideo
 	}
 
-	// Default to video for backwards compatibility
-	return MediaTypeVideo
+	return MediaTypeUnknown
+}
+
+// ResolveMediaType returns the kind of mediaURL probed from its bytes, as recorded in kinds,
+// falling back to the URL's extension for media that couldn't be probed
+func ResolveMediaType(mediaURL string, kinds map[string]string) MediaType {
+	if kind, ok := kinds[mediaURL]; ok && kind != string(MediaTypeUnknown) {
+		return MediaType(kind)
+	}
+	return DetectMediaTypeFromURL(mediaURL)
+}
+
+// IsImageMedia reports whether mediaURL is an image, see ResolveMediaType
+// Media of unknown kind is treated as video
+func IsImageMedia(mediaURL string, kinds map[string]string) bool {
+	return ResolveMediaType(mediaURL, kinds) == MediaTypeImage
 }
 
 // IsImageURL checks if the URL points to an image
@@ -0,0 +1,57 @@
+package services
+
+import "testing"
+
+func TestDetectMediaTypeFromURL(t *testing.T) {
+	tests := []struct {
+		mediaURL string
+		want     MediaType
+	}{
+		{mediaURL: "https://cdn.example.com/photo.jpg", want: MediaTypeImage},
+		{mediaURL: "https://cdn.example.com/photo.HEIC?w=100", want: MediaTypeImage},
+		{mediaURL: "https://cdn.example.com/clip.mp4", want: MediaTypeVideo},
+		{mediaURL: "https://cdn.example.com/a1b2?sig=abc.jpg", want: MediaTypeUnknown}, // Only the path counts
+		{mediaURL: "https://cdn.example.com/a1b2", want: MediaTypeUnknown},
+		{mediaURL: "https://cdn.example.com/notes.txt", want: MediaTypeUnknown},
+		{mediaURL: "://bad", want: MediaTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := DetectMediaTypeFromURL(tt.mediaURL); got != tt.want {
+			t.Errorf("DetectMediaTypeFromURL(%q) = %q, want %q", tt.mediaURL, got, tt.want)
+		}
+	}
+}
+
+func TestResolveMediaType(t *testing.T) {
+	kinds := map[string]string{
+		"https://cdn.example.com/a1b2?sig=x": string(MediaTypeImage),
+		"https://cdn.example.com/clip.jpg":   string(MediaTypeVideo),
+		"https://cdn.example.com/odd.mp4":    string(MediaTypeUnknown),
+	}
+
+	tests := []struct {
+		name     string
+		mediaURL string
+		kinds    map[string]string
+		want     MediaType
+	}{
+		{name: "probed image without extension", mediaURL: "https://cdn.example.com/a1b2?sig=x", kinds: kinds, want: MediaTypeImage},
+		{name: "probed kind wins over the extension", mediaURL: "https://cdn.example.com/clip.jpg", kinds: kinds, want: MediaTypeVideo},
+		{name: "unknown probed kind falls back to the extension", mediaURL: "https://cdn.example.com/odd.mp4", kinds: kinds, want: MediaTypeVideo},
+		{name: "unprobed image", mediaURL: "https://cdn.example.com/photo.PNG", kinds: kinds, want: MediaTypeImage},
+		{name: "unprobed video without kinds", mediaURL: "https://cdn.example.com/clip.mov?x=1", want: MediaTypeVideo},
+		{name: "unprobed without extension", mediaURL: "https://cdn.example.com/c3d4", kinds: kinds, want: MediaTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveMediaType(tt.mediaURL, tt.kinds); got != tt.want {
+				t.Errorf("ResolveMediaType(%q) = %q, want %q", tt.mediaURL, got, tt.want)
+			}
+			if got := IsImageMedia(tt.mediaURL, tt.kinds); got != (tt.want == MediaTypeImage) {
+				t.Errorf("IsImageMedia(%q) = %v, want %v", tt.mediaURL, got, tt.want == MediaTypeImage)
+			}
+		})
+	}
+}
@@ -1,451 +1,422 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
-// PlatformService interface (duplicated to avoid circular dependency)
-type PlatformService interface {
-	GetPlatformName() models.Platform
-	GetRequiredScopes() []string
-	GenerateAuthURL() (AuthURLResponse, error)
-	ExchangeCodeForTokens(code string, additionalParams map[string]string) (*TokenResponse, error)
-	RefreshAccessToken(refreshToken string) (*TokenResponse, error)
-	GetUserInfo(accessToken string) (*UserInfo, error)
-	UploadMedia(accessToken string, mediaURL string) (string, error)
-	CreatePost(accessToken string, content PostContent) (*PostResponse, error)
-	GetPostStatus(accessToken string, postID string) (*PostStatusResponse, error)
+// PlatformRegistry is the part of platform.PlatformRegistry the post service uses
+type PlatformRegistry interface {
+	Get(platform models.Platform) (platformapi.PlatformService, error)
+	IsSupported(platform models.Platform) bool
+	Breaker(platform models.Platform) *platformapi.CircuitBreaker
 }
 
-// platformServiceAdapter wraps an interface{} and implements PlatformService
-// This allows us to work with platform services without importing the platform package
-type platformServiceAdapter struct {
-	service interface{}
-}
+// Deadlines of the steps of publishing a post
+const (
+	mediaProbeTimeout   = 30 * time.Second // All media of a request are probed in parallel
+	tokenRefreshTimeout = 30 * time.Second
+	mediaUploadTimeout  = 30 * time.Minute // Per media item, including the platform's processing
+	createPostTimeout   = 30 * time.Minute // Instagram waits for each of its media containers inside CreatePost
+	statusPollTimeout   = 5 * time.Minute
+)
 
-func (a *platformServiceAdapter) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
-	svcValue := reflect.ValueOf(a.service)
-	method := svcValue.MethodByName("RefreshAccessToken")
+// Limits of deferring posts until the publishing budget of an account resets
+const (
+	maxQuotaDeferral      = 25 * time.Hour // Long enough for daily posting caps
+	maxRateLimitDeferrals = 3              // Rate limit errors of one post before it fails
+)
 
-	if !method.IsValid() {
-		return nil, fmt.Errorf("service does not implement RefreshAccessToken")
-	}
+// maxOutageHold is how long a post is held while its platform's circuit breaker is open
+// before it fails
+const maxOutageHold = time.Hour
 
-	results := method.Call([]reflect.Value{reflect.ValueOf(refreshToken)})
+// statusPollInterval is how often an asynchronously publishing platform is asked for the
+// status of a post; tests shorten it
+var statusPollInterval = 5 * time.Second
 
-	if len(results) != 2 {
-		return nil, fmt.Errorf("RefreshAccessToken method has wrong number of return values")
-	}
+// outageRecheckInterval is how often held posts check whether the circuit breaker of their
+// platform lets them through; tests shorten it
+var outageRecheckInterval = 15 * time.Second
 
-	if !results[1].IsNil() {
-		err := results[1].Interface().(error)
-		return nil, err
-	}
+// ErrPostNotCancellable is returned when a post is not in progress or was already handed to the platform
+var ErrPostNotCancellable = errors.New("post can no longer be cancelled")
 
-	// The result might be *platform.TokenResponse, so we need to convert it
-	respValue := results[0]
-	if respValue.IsNil() {
-		return nil, nil
-	}
+// ErrPostNotDeletable is returned when a post isn't published or its platform can't delete posts
+var ErrPostNotDeletable = errors.New("post can't be deleted from its platform")
+
+// errPostCancelled is the cancellation cause of posts cancelled by their owner
+var errPostCancelled = errors.New("post cancelled by user")
+
+// errPublishInterrupted is returned by publish when the post was cancelled or the server
+// shut down before the post was handed to the platform
+var errPublishInterrupted = errors.New("publishing was interrupted")
 
-	// Extract fields using reflection
-	respElem := respValue.Elem()
-	return &TokenResponse{
-		AccessToken:  respElem.FieldByName("AccessToken").String(),
-		RefreshToken: respElem.FieldByName("RefreshToken").String(),
-		ExpiresIn:    int(respElem.FieldByName("ExpiresIn").Int()),
-		TokenType:    respElem.FieldByName("TokenType").String(),
-		Scope:        respElem.FieldByName("Scope").String(),
-	}, nil
+type MultiPlatformPostService struct {
+	postRepo               *models.PostRepository
+	tokenRepo              *models.TokenRepository
+	platformConnectionRepo *models.PlatformConnectionRepository
+	platformRegistry       PlatformRegistry
+	mediaItemRepo          *models.PostMediaItemRepository
+	mediaProber            *MediaProber
+	imageProcessor         *ImageProcessor
+	quotas                 *QuotaTracker
+
+	// baseCtx is cancelled when the server shuts down; in-progress posts derive their context from it
+	baseCtx   context.Context
+	stop      context.CancelFunc
+	runningMu sync.Mutex
+	running   map[int64]*runningPost
+	workers   sync.WaitGroup // Posts processed in the background, which Shutdown waits for
 }
 
-func (a *platformServiceAdapter) UploadMedia(accessToken string, mediaURL string) (string, error) {
-	svcValue := reflect.ValueOf(a.service)
-	method := svcValue.MethodByName("UploadMedia")
+// runningPost tracks a post that is being published in the background
+type runningPost struct {
+	cancel    context.CancelCauseFunc
+	submitted bool // The post was handed to the platform; cancelling no longer stops it
+}
 
-	if !method.IsValid() {
-		return "", fmt.Errorf("service does not implement UploadMedia")
+// NewMultiPlatformPostService creates a new multi-platform post service
+// ctx is the lifetime of the server: cancelling it, or calling Shutdown, interrupts every post in
+// progress
+func NewMultiPlatformPostService(
+	ctx context.Context,
+	postRepo *models.PostRepository,
+	tokenRepo *models.TokenRepository,
+	platformConnectionRepo *models.PlatformConnectionRepository,
+	platformRegistry PlatformRegistry,
+	mediaItemRepo *models.PostMediaItemRepository,
+	imageProcessor *ImageProcessor,
+	quotas *QuotaTracker,
+) *MultiPlatformPostService {
+	baseCtx, stop := context.WithCancel(ctx)
+	return &MultiPlatformPostService{
+		postRepo:               postRepo,
+		tokenRepo:              tokenRepo,
+		platformConnectionRepo: platformConnectionRepo,
+		platformRegistry:       platformRegistry,
+		mediaItemRepo:          mediaItemRepo,
+		mediaProber:            NewMediaProber(),
+		imageProcessor:         imageProcessor,
+		quotas:                 quotas,
+		baseCtx:                baseCtx,
+		stop:                   stop,
+		running:                make(map[int64]*runningPost),
 	}
+}
 
-	results := method.Call([]reflect.Value{
-		reflect.ValueOf(accessToken),
-		reflect.ValueOf(mediaURL),
-	})
+// CreateMultiPlatformPostRequest represents a request to create a post on multiple platforms
+type CreateMultiPlatformPostRequest struct {
+	Platforms []models.Platform `json:"platforms"`  // ["tiktok", "x"]
+	MediaURL  string            `json:"media_url"`  // Primary video/image URL (for single media)
+	MediaURLs []string          `json:"media_urls"` // Multiple media URLs (for carousel/multi-image)
+	Caption   string            `json:"caption"`    // Post text/caption
+
+	// Settings holds platform-specific settings keyed by platform, e.g. {"tiktok": {"privacy_level": "SELF_ONLY"}}
+	// Each platform validates its own entry against the schema in its capabilities
+	Settings map[models.Platform]platformapi.Settings `json:"settings,omitempty"`
+
+	// PlatformMedia overrides the shared media for individual platforms
+	// Platforms without an entry use MediaURLs/MediaURL
+	PlatformMedia map[models.Platform][]string `json:"platform_media,omitempty"`
+}
 
-	if len(results) != 2 {
-		return "", fmt.Errorf("UploadMedia method has wrong number of return values")
+// MediaURLsFor returns the media a platform should post, falling back to the shared media
+func (r CreateMultiPlatformPostRequest) MediaURLsFor(plt models.Platform) []string {
+	if urls := r.PlatformMedia[plt]; len(urls) > 0 {
+		return urls
 	}
-
-	if !results[1].IsNil() {
-		err := results[1].Interface().(error)
-		return "", err
+	if len(r.MediaURLs) > 0 {
+		return r.MediaURLs
+	}
+	if r.MediaURL != "" {
+		return []string{r.MediaURL}
 	}
+	return nil
+}
 
-	return results[0].String(), nil
+// CreateMultiPlatformPostResponse represents the response after creating posts
+type CreateMultiPlatformPostResponse struct {
+	PublicationID string            `json:"publication_id"` // Shared by all posts created from the request
+	Posts         []*models.Post    `json:"posts"`
+	Errors        map[string]string `json:"errors,omitempty"`
 }
 
-func (a *platformServiceAdapter) CreatePost(accessToken string, content PostContent) (*PostResponse, error) {
-	// Use reflection to call CreatePost with the correct type
-	svcValue := reflect.ValueOf(a.service)
-	method := svcValue.MethodByName("CreatePost")
+// ValidatePostResponse contains the media probe results and platform constraint checks
+type ValidatePostResponse struct {
+	Valid      bool                             `json:"valid"`
+	Media      []*MediaProbeResult              `json:"media"`
+	Violations map[string][]ConstraintViolation `json:"violations,omitempty"` // Keyed by platform
+	Warnings   []string                         `json:"warnings,omitempty"`   // Media that could not be probed
+}
 
-	if !method.IsValid() {
-		return nil, fmt.Errorf("service does not implement CreatePost")
-	}
+// MediaValidationError is returned when media breaks the limits of one or more platforms
+type MediaValidationError struct {
+	Violations map[string][]ConstraintViolation
+}
 
-	// Get the type of the second parameter (PostContent) from the method
-	methodType := method.Type()
-	if methodType.NumIn() != 2 {
-		return nil, fmt.Errorf("CreatePost method has wrong number of parameters")
+func (e *MediaValidationError) Error() string {
+	platforms := make([]string, 0, len(e.Violations))
+	for plt := range e.Violations {
+		platforms = append(platforms, plt)
 	}
+	sort.Strings(platforms)
+	return fmt.Sprintf("media does not meet the requirements of: %s", strings.Join(platforms, ", "))
+}
 
-	// Create a new instance of the platform's PostContent type
-	contentType := methodType.In(1)
-	contentValue := reflect.New(contentType).Elem()
-
-	// Set fields using reflection
-	contentValue.FieldByName("Text").SetString(content.Text)
-	contentValue.FieldByName("MediaURL").SetString(content.MediaURL)
+// SettingsValidationError is returned when the settings of one or more platforms are invalid
+type SettingsValidationError struct {
+	Errors map[string][]platformapi.FieldError // Keyed by platform
+}
 
-	// Set MediaURLs slice (for carousel/multi-image)
-	mediaURLsField := contentValue.FieldByName("MediaURLs")
-	if mediaURLsField.IsValid() && mediaURLsField.CanSet() {
-		mediaURLsSlice := reflect.MakeSlice(reflect.TypeOf([]string{}), len(content.MediaURLs), len(content.MediaURLs))
-		for i, url := range content.MediaURLs {
-			mediaURLsSlice.Index(i).SetString(url)
-		}
-		mediaURLsField.Set(mediaURLsSlice)
+func (e *SettingsValidationError) Error() string {
+	platforms := make([]string, 0, len(e.Errors))
+	for plt := range e.Errors {
+		platforms = append(platforms, plt)
 	}
+	sort.Strings(platforms)
+	return fmt.Sprintf("invalid settings for: %s", strings.Join(platforms, ", "))
+}
 
-	// Set MediaIDs slice
-	mediaIDsField := contentValue.FieldByName("MediaIDs")
-	mediaIDsSlice := reflect.MakeSlice(reflect.TypeOf([]string{}), len(content.MediaIDs), len(content.MediaIDs))
-	for i, id := range content.MediaIDs {
-		mediaIDsSlice.Index(i).SetString(id)
+// ValidateMultiPlatformPost probes the media of a post request and checks it against
+// each requested platform's constraints without creating any posts
+func (s *MultiPlatformPostService) ValidateMultiPlatformPost(ctx context.Context, userID int64, req CreateMultiPlatformPostRequest) (*ValidatePostResponse, error) {
+	platformMedia, _, err := s.validateRequest(userID, req)
+	if err != nil {
+		return nil, err
 	}
-	mediaIDsField.Set(mediaIDsSlice)
 
-	// Set TikTok settings if provided (for TikTok platform)
-	if content.TikTokSettings != nil {
-		tiktokSettingsField := contentValue.FieldByName("TikTokSettings")
-		if tiktokSettingsField.IsValid() && tiktokSettingsField.CanSet() {
-			// Get the type of TikTokSettings struct in the platform package
-			tiktokSettingsType := tiktokSettingsField.Type().Elem()
-			tiktokSettingsValue := reflect.New(tiktokSettingsType).Elem()
+	result := s.validateMedia(ctx, platformMedia)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-			// Set each field
-			if f := tiktokSettingsValue.FieldByName("Title"); f.IsValid() {
-				f.SetString(content.TikTokSettings.Title)
-			}
-			if f := tiktokSettingsValue.FieldByName("PrivacyLevel"); f.IsValid() {
-				f.SetString(content.TikTokSettings.PrivacyLevel)
-			}
-			if f := tiktokSettingsValue.FieldByName("AllowComment"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.AllowComment)
-			}
-			if f := tiktokSettingsValue.FieldByName("AllowDuet"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.AllowDuet)
-			}
-			if f := tiktokSettingsValue.FieldByName("AllowStitch"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.AllowStitch)
-			}
-			if f := tiktokSettingsValue.FieldByName("IsBrandContent"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.IsBrandContent)
-			}
-			if f := tiktokSettingsValue.FieldByName("IsBrandOrganic"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.IsBrandOrganic)
-			}
-			if f := tiktokSettingsValue.FieldByName("AutoAddMusic"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.AutoAddMusic)
-			}
-			if f := tiktokSettingsValue.FieldByName("DirectPost"); f.IsValid() {
-				f.SetBool(content.TikTokSettings.DirectPost)
-			}
+// validateRequest checks the platforms, media and settings of a request
+// Returns the media URLs each platform posts and each platform's validated settings
+func (s *MultiPlatformPostService) validateRequest(userID int64, req CreateMultiPlatformPostRequest) (map[models.Platform][]string, map[models.Platform]platformapi.Settings, error) {
+	if len(req.Platforms) == 0 {
+		return nil, nil, fmt.Errorf("at least one platform must be specified")
+	}
 
-			tiktokSettingsField.Set(tiktokSettingsValue.Addr())
+	// Platforms that require media need either their own or the shared media
+	platformMedia := make(map[models.Platform][]string, len(req.Platforms))
+	for _, plt := range req.Platforms {
+		mediaURLs := req.MediaURLsFor(plt)
+		if len(mediaURLs) == 0 && s.RequiresMedia(plt) {
+			return nil, nil, fmt.Errorf("media URL is required for %s", plt)
 		}
+		platformMedia[plt] = mediaURLs
 	}
 
-	// Call the method
-	results := method.Call([]reflect.Value{
-		reflect.ValueOf(accessToken),
-		contentValue,
-	})
-
-	// Check for error (second return value)
-	if len(results) != 2 {
-		return nil, fmt.Errorf("CreatePost method has wrong number of return values")
+	// Per-platform media for a platform that is not being posted to is most likely a mistake
+	for plt := range req.PlatformMedia {
+		if _, ok := platformMedia[plt]; !ok {
+			return nil, nil, fmt.Errorf("platform_media contains %s, which is not in platforms", plt)
+		}
 	}
 
-	// Get error if any
-	if !results[1].IsNil() {
-		err := results[1].Interface().(error)
-		return nil, err
+	for plt := range req.Settings {
+		if _, ok := platformMedia[plt]; !ok {
+			return nil, nil, fmt.Errorf("settings contains %s, which is not in platforms", plt)
+		}
 	}
 
-	// Get PostResponse (first return value) and convert it
-	respValue := results[0]
-	if respValue.IsNil() {
-		return nil, nil
+	// Validate that user has connected all requested platforms
+	connectedPlatforms, err := s.platformConnectionRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connected platforms: %w", err)
 	}
 
-	// Extract fields using reflection
-	respElem := respValue.Elem()
-	return &PostResponse{
-		PostID:    respElem.FieldByName("PostID").String(),
-		PublishID: respElem.FieldByName("PublishID").String(),
-		Status:    respElem.FieldByName("Status").String(),
-		ShareURL:  respElem.FieldByName("ShareURL").String(),
-		ErrorMsg:  respElem.FieldByName("ErrorMsg").String(),
-	}, nil
-}
-
-func (a *platformServiceAdapter) GetPlatformName() models.Platform {
-	type namer interface {
-		GetPlatformName() models.Platform
-	}
-	if svc, ok := a.service.(namer); ok {
-		return svc.GetPlatformName()
+	// Create a map of connected platforms for quick lookup
+	platformMap := make(map[models.Platform]bool)
+	for _, conn := range connectedPlatforms {
+		if conn.IsActive {
+			platformMap[conn.Platform] = true
+		}
 	}
-	return ""
-}
 
-func (a *platformServiceAdapter) GetRequiredScopes() []string {
-	type scoper interface {
-		GetRequiredScopes() []string
-	}
-	if svc, ok := a.service.(scoper); ok {
-		return svc.GetRequiredScopes()
+	// Validate all requested platforms are connected
+	var notConnected []string
+	for _, plt := range req.Platforms {
+		if !platformMap[plt] {
+			notConnected = append(notConnected, string(plt))
+		}
 	}
-	return nil
-}
 
-func (a *platformServiceAdapter) GenerateAuthURL() (AuthURLResponse, error) {
-	type authURLGenerator interface {
-		GenerateAuthURL() (AuthURLResponse, error)
+	if len(notConnected) > 0 {
+		return nil, nil, fmt.Errorf("platforms not connected: %v", notConnected)
 	}
-	if svc, ok := a.service.(authURLGenerator); ok {
-		return svc.GenerateAuthURL()
+
+	platformSettings, err := s.validateSettings(req)
+	if err != nil {
+		return nil, nil, err
 	}
-	return AuthURLResponse{}, fmt.Errorf("service does not implement GenerateAuthURL")
+
+	return platformMedia, platformSettings, nil
 }
 
-func (a *platformServiceAdapter) ExchangeCodeForTokens(code string, additionalParams map[string]string) (*TokenResponse, error) {
-	type tokenExchanger interface {
-		ExchangeCodeForTokens(string, map[string]string) (*TokenResponse, error)
-	}
-	if svc, ok := a.service.(tokenExchanger); ok {
-		return svc.ExchangeCodeForTokens(code, additionalParams)
+// RequiresMedia reports whether posts to a platform must contain media
+// Unknown platforms are assumed to require it
+func (s *MultiPlatformPostService) RequiresMedia(plt models.Platform) bool {
+	platformService, err := s.platformRegistry.Get(plt)
+	if err != nil {
+		return true
 	}
-	return nil, fmt.Errorf("service does not implement ExchangeCodeForTokens")
+	return platformService.Capabilities().RequiresMedia
 }
 
-func (a *platformServiceAdapter) GetUserInfo(accessToken string) (*UserInfo, error) {
-	type userInfoGetter interface {
-		GetUserInfo(string) (*UserInfo, error)
+// validateSettings has each requested platform validate its settings
+// Field errors of all platforms are collected into a single *SettingsValidationError
+func (s *MultiPlatformPostService) validateSettings(req CreateMultiPlatformPostRequest) (map[models.Platform]platformapi.Settings, error) {
+	platformSettings := make(map[models.Platform]platformapi.Settings, len(req.Platforms))
+	fieldErrors := make(map[string][]platformapi.FieldError)
+
+	for _, plt := range req.Platforms {
+		platformService, err := s.platformRegistry.Get(plt)
+		if err != nil {
+			return nil, err
+		}
+
+		settings, err := platformService.ValidateSettings(req.Settings[plt])
+		if err != nil {
+			var settingsErr *platformapi.SettingsError
+			if !errors.As(err, &settingsErr) {
+				return nil, fmt.Errorf("failed to validate %s settings: %w", plt, err)
+			}
+			fieldErrors[string(plt)] = settingsErr.Fields
+			continue
+		}
+		platformSettings[plt] = settings
 	}
-	if svc, ok := a.service.(userInfoGetter); ok {
-		return svc.GetUserInfo(accessToken)
+
+	if len(fieldErrors) > 0 {
+		return nil, &SettingsValidationError{Errors: fieldErrors}
 	}
-	return nil, fmt.Errorf("service does not implement GetUserInfo")
+	return platformSettings, nil
 }
 
-func (a *platformServiceAdapter) GetPostStatus(accessToken string, postID string) (*PostStatusResponse, error) {
-	svcValue := reflect.ValueOf(a.service)
-	method := svcValue.MethodByName("GetPostStatus")
-
-	if !method.IsValid() {
-		return nil, fmt.Errorf("service does not implement GetPostStatus")
+// validateMedia probes every media URL once and checks it against the constraints of each platform posting it
+// Media that cannot be probed is reported as a warning and left for the platform to judge
+// Image violations that the image processor fixes before publishing do not make the post invalid
+func (s *MultiPlatformPostService) validateMedia(ctx context.Context, platformMedia map[models.Platform][]string) *ValidatePostResponse {
+	// Collect the distinct URLs and the platforms using each of them
+	var mediaURLs []string
+	usedBy := make(map[string][]models.Platform)
+	for plt, urls := range platformMedia {
+		for _, mediaURL := range urls {
+			if _, seen := usedBy[mediaURL]; !seen {
+				mediaURLs = append(mediaURLs, mediaURL)
+			}
+			usedBy[mediaURL] = append(usedBy[mediaURL], plt)
+		}
 	}
+	sort.Strings(mediaURLs)
 
-	results := method.Call([]reflect.Value{
-		reflect.ValueOf(accessToken),
-		reflect.ValueOf(postID),
-	})
+	probes := make([]*MediaProbeResult, len(mediaURLs))
+	probeErrs := make([]error, len(mediaURLs))
 
-	if len(results) != 2 {
-		return nil, fmt.Errorf("GetPostStatus method has wrong number of return values")
-	}
+	probeCtx, cancel := context.WithTimeout(ctx, mediaProbeTimeout)
+	defer cancel()
 
-	if !results[1].IsNil() {
-		err := results[1].Interface().(error)
-		return nil, err
+	var wg sync.WaitGroup
+	for i, mediaURL := range mediaURLs {
+		wg.Add(1)
+		go func(i int, mediaURL string) {
+			defer wg.Done()
+			probes[i], probeErrs[i] = s.mediaProber.Probe(probeCtx, mediaURL)
+		}(i, mediaURL)
 	}
+	wg.Wait()
 
-	respValue := results[0]
-	if respValue.IsNil() {
-		return nil, nil
-	}
-
-	// Extract fields using reflection to handle cross-package type conversion
-	respElem := respValue.Elem()
-	return &PostStatusResponse{
-		Status:          respElem.FieldByName("Status").String(),
-		PostID:          respElem.FieldByName("PostID").String(),
-		ShareID:         respElem.FieldByName("ShareID").String(),
-		ShareURL:        respElem.FieldByName("ShareURL").String(),
-		FailReason:      respElem.FieldByName("FailReason").String(),
-		ProgressPercent: int(respElem.FieldByName("ProgressPercent").Int()),
-	}, nil
-}
-
-// AuthURLResponse contains the OAuth authorization URL and associated data
-type AuthURLResponse struct {
-	URL          string
-	State        string
-	CodeVerifier string
-}
-
-// TokenResponse contains OAuth tokens received from the platform
-type TokenResponse struct {
-	AccessToken  string
-	RefreshToken string
-	ExpiresIn    int
-	TokenType    string
-	Scope        string
-}
-
-// UserInfo contains basic user information from the platform
-type UserInfo struct {
-	PlatformUserID string
-	Username       string
-	DisplayName    string
-	AvatarURL      string
-	Email          string
-}
-
-// TikTokSettings represents TikTok-specific post settings (required by TikTok UX Guidelines)
-type TikTokSettings struct {
-	Title          string // Video title
-	PrivacyLevel   string // PUBLIC_TO_EVERYONE, MUTUAL_FOLLOW_FRIENDS, FOLLOWER_OF_CREATOR, SELF_ONLY
-	AllowComment   bool   // Allow comments (default: false per UX guidelines)
-	AllowDuet      bool   // Allow duet (default: false per UX guidelines)
-	AllowStitch    bool   // Allow stitch (default: false per UX guidelines)
-	IsBrandContent bool   // Promoting own brand
-	IsBrandOrganic bool   // Paid partnership (branded content)
-	AutoAddMusic   bool   // Auto-add trending music to photo posts (only for photos)
-	DirectPost     bool   // Direct Post (true) vs Send to Inbox (false)
-}
-
-// PostContent represents the content to be posted
-type PostContent struct {
-	Text           string
-	MediaURL       string   // Primary media URL (for single media posts)
-	MediaURLs      []string // Multiple media URLs (for carousel/multi-image posts)
-	MediaIDs       []string
-	TikTokSettings *TikTokSettings // TikTok-specific settings
-}
-
-// PostResponse contains the result of creating a post
-type PostResponse struct {
-	PostID    string
-	PublishID string
-	Status    string
-	ShareURL  string
-	ErrorMsg  string
-}
-
-// PostStatusResponse contains the current status of a post
-type PostStatusResponse struct {
-	Status          string
-	PostID          string
-	ShareID         string
-	ShareURL        string
-	FailReason      string
-	ProgressPercent int
-}
-
-// PlatformRegistry interface to avoid circular dependency
-// Note: This must match the actual platform.PlatformRegistry interface
-type PlatformRegistry interface {
-	Get(platform models.Platform) (interface{}, error)
-	IsSupported(platform models.Platform) bool
-}
+	result := &ValidatePostResponse{
+		Valid: true,
+		Media: make([]*MediaProbeResult, 0, len(mediaURLs)),
+	}
 
-type MultiPlatformPostService struct {
-	postRepo               *models.PostRepository
-	tokenRepo              *models.TokenRepository
-	platformConnectionRepo *models.PlatformConnectionRepository
-	platformRegistry       PlatformRegistry
-}
+	for i, probe := range probes {
+		if probeErrs[i] != nil {
+			log.Printf("Failed to probe media %s: %v", mediaURLs[i], probeErrs[i])
+			result.Warnings = append(result.Warnings, fmt.Sprintf("could not inspect %s: %v", mediaURLs[i], probeErrs[i]))
+			continue
+		}
+		result.Media = append(result.Media, probe)
+		autoFix := s.imageProcessor != nil && s.imageProcessor.CanProcess(probe)
 
-// NewMultiPlatformPostService creates a new multi-platform post service
-func NewMultiPlatformPostService(
-	postRepo *models.PostRepository,
-	tokenRepo *models.TokenRepository,
-	platformConnectionRepo *models.PlatformConnectionRepository,
-	platformRegistry PlatformRegistry,
-) *MultiPlatformPostService {
-	return &MultiPlatformPostService{
-		postRepo:               postRepo,
-		tokenRepo:              tokenRepo,
-		platformConnectionRepo: platformConnectionRepo,
-		platformRegistry:       platformRegistry,
+		for _, plt := range usedBy[mediaURLs[i]] {
+			violations := CheckMediaConstraints(plt, probe)
+			if len(violations) == 0 {
+				continue
+			}
+			for j := range violations {
+				violations[j].AutoFix = autoFix
+			}
+			if result.Violations == nil {
+				result.Violations = make(map[string][]ConstraintViolation)
+			}
+			result.Violations[string(plt)] = append(result.Violations[string(plt)], violations...)
+			if !autoFix {
+				result.Valid = false
+			}
+		}
 	}
-}
 
-// CreateMultiPlatformPostRequest represents a request to create a post on multiple platforms
-type CreateMultiPlatformPostRequest struct {
-	Platforms      []models.Platform `json:"platforms"`                 // ["tiktok", "x"]
-	MediaURL       string            `json:"media_url"`                 // Primary video/image URL (for single media)
-	MediaURLs      []string          `json:"media_urls"`                // Multiple media URLs (for carousel/multi-image)
-	Caption        string            `json:"caption"`                   // Post text/caption
-	TikTokSettings *TikTokSettings   `json:"tiktok_settings,omitempty"` // TikTok-specific settings
-}
-
-// CreateMultiPlatformPostResponse represents the response after creating posts
-type CreateMultiPlatformPostResponse struct {
-	Posts  []*models.Post    `json:"posts"`
-	Errors map[string]string `json:"errors,omitempty"`
+	return result
 }
 
-// CreateMultiPlatformPost creates a post on multiple platforms simultaneously
-func (s *MultiPlatformPostService) CreateMultiPlatformPost(userID int64, req CreateMultiPlatformPostRequest) (*CreateMultiPlatformPostResponse, error) {
-	if len(req.Platforms) == 0 {
-		return nil, fmt.Errorf("at least one platform must be specified")
+// postMediaType classifies a platform's media as text, video, image or carousel
+// Prefers the probed kind over the URL extension
+func postMediaType(mediaURLs []string, mediaKinds map[string]MediaType) string {
+	if len(mediaURLs) == 0 {
+		return "text"
 	}
-
-	// Ensure we have at least one media URL
-	if req.MediaURL == "" && len(req.MediaURLs) == 0 {
-		return nil, fmt.Errorf("media URL is required")
+	mediaType := "video"
+	if kind, ok := mediaKinds[mediaURLs[0]]; ok {
+		mediaType = string(kind)
+	} else if IsImageURL(mediaURLs[0]) {
+		mediaType = "image"
 	}
-
-	// Use MediaURLs if provided, otherwise fall back to MediaURL
-	mediaURLs := req.MediaURLs
-	if len(mediaURLs) == 0 && req.MediaURL != "" {
-		mediaURLs = []string{req.MediaURL}
+	// If multiple images, it's a carousel
+	if len(mediaURLs) > 1 && mediaType == "image" {
+		mediaType = "carousel"
 	}
+	return mediaType
+}
 
-	// Validate that user has connected all requested platforms
-	connectedPlatforms, err := s.platformConnectionRepo.GetByUserID(userID)
+// CreateMultiPlatformPost creates a post on multiple platforms simultaneously
+// Each platform gets its own post record; all of them share a publication ID
+// ctx only covers validation; the posts are published in the background under the server's context
+func (s *MultiPlatformPostService) CreateMultiPlatformPost(ctx context.Context, userID int64, req CreateMultiPlatformPostRequest) (*CreateMultiPlatformPostResponse, error) {
+	platformMedia, platformSettings, err := s.validateRequest(userID, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get connected platforms: %w", err)
+		return nil, err
 	}
 
-	// Create a map of connected platforms for quick lookup
-	platformMap := make(map[models.Platform]bool)
-	for _, conn := range connectedPlatforms {
-		if conn.IsActive {
-			platformMap[conn.Platform] = true
-		}
+	// Check media against platform limits before anything is sent to a platform
+	validation := s.validateMedia(ctx, platformMedia)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-
-	// Validate all requested platforms are connected
-	var notConnected []string
-	for _, plt := range req.Platforms {
-		if !platformMap[plt] {
-			notConnected = append(notConnected, string(plt))
-		}
+	if !validation.Valid {
+		return nil, &MediaValidationError{Violations: validation.Violations}
 	}
 
-	if len(notConnected) > 0 {
-		return nil, fmt.Errorf("platforms not connected: %v", notConnected)
+	publicationID, err := newPublicationID()
+	if err != nil {
+		return nil, err
 	}
 
 	// Create post records for each platform
@@ -453,32 +424,41 @@ func (s *MultiPlatformPostService) CreateMultiPlatformPost(userID int64, req Cre
 	errors := make(map[string]string)
 	var mu sync.Mutex
 
-	// Detect media type from primary URL
-	primaryMediaURL := mediaURLs[0]
-	mediaType := "video"
-	if IsImageURL(primaryMediaURL) {
-		mediaType = "image"
-	}
-	// If multiple images, it's a carousel
-	if len(mediaURLs) > 1 && mediaType == "image" {
-		mediaType = "carousel"
+	// Remember the probed kind of every media file for the media items
+	mediaKinds := make(map[string]MediaType, len(validation.Media))
+	for _, probe := range validation.Media {
+		mediaKinds[probe.URL] = probe.Kind
 	}
 
 	for _, plt := range req.Platforms {
+		mediaURLs := platformMedia[plt]
+		primaryURL := ""
+		if len(mediaURLs) > 0 {
+			primaryURL = mediaURLs[0]
+		}
+
 		// Determine if this is a direct post or send to inbox
 		directPost := true
-		if plt == models.PlatformTikTok && req.TikTokSettings != nil {
-			directPost = req.TikTokSettings.DirectPost
+		if plt == models.PlatformTikTok {
+			directPost = platformSettings[plt].Bool("direct_post")
+		}
+
+		// Settings are stored so a post deferred when the server stops can be resumed
+		settings, err := json.Marshal(platformSettings[plt])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s settings: %w", plt, err)
 		}
 
 		post := &models.Post{
-			UserID:     userID,
-			Platform:   plt,
-			VideoURL:   primaryMediaURL, // Store primary URL in existing field
-			Caption:    req.Caption,
-			Status:     models.PostStatusPending,
-			MediaType:  mediaType,
-			DirectPost: &directPost,
+			UserID:        userID,
+			PublicationID: publicationID,
+			Platform:      plt,
+			VideoURL:      primaryURL, // Store primary URL in existing field; empty for text posts
+			Caption:       req.Caption,
+			Status:        models.PostStatusPending,
+			MediaType:     postMediaType(mediaURLs, mediaKinds),
+			DirectPost:    &directPost,
+			Settings:      string(settings),
 		}
 
 		if err := s.postRepo.Create(post); err != nil {
@@ -487,19 +467,18 @@ func (s *MultiPlatformPostService) CreateMultiPlatformPost(userID int64, req Cre
 			continue
 		}
 
+		s.createMediaItems(post.ID, mediaURLs, mediaKinds)
 		posts = append(posts, post)
 
 		// Process post asynchronously for each platform
-		// Pass TikTok settings only for TikTok platform
-		var tiktokSettings *TikTokSettings
-		if plt == models.PlatformTikTok && req.TikTokSettings != nil {
-			tiktokSettings = req.TikTokSettings
-		}
-		go s.processPlatformPost(post.ID, userID, plt, tiktokSettings, mediaURLs, &mu, errors)
+		s.startPost(post.ID, func(ctx context.Context) {
+			s.processPlatformPost(ctx, post.ID, userID, plt, platformSettings[plt], mediaURLs, &mu, errors)
+		})
 	}
 
 	response := &CreateMultiPlatformPostResponse{
-		Posts: posts,
+		PublicationID: publicationID,
+		Posts:         posts,
 	}
 
 	if len(errors) > 0 {
@@ -509,8 +488,131 @@ func (s *MultiPlatformPostService) CreateMultiPlatformPost(userID int64, req Cre
 	return response, nil
 }
 
+// newPublicationID generates the ID that groups the posts of one publication
+func newPublicationID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate publication id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// createMediaItems records the media files of a post in their posting order
+func (s *MultiPlatformPostService) createMediaItems(postID int64, mediaURLs []string, mediaKinds map[string]MediaType) {
+	if s.mediaItemRepo == nil {
+		return
+	}
+	for i, mediaURL := range mediaURLs {
+		kind, ok := mediaKinds[mediaURL]
+		if !ok {
+			kind = DetectMediaTypeFromURL(mediaURL)
+		}
+		item := &models.PostMediaItem{
+			PostID:    postID,
+			MediaURL:  mediaURL,
+			MediaType: string(kind),
+			Position:  i,
+		}
+		if err := s.mediaItemRepo.Create(item); err != nil {
+			log.Printf("Failed to create media item %d for post %d: %v", i, postID, err)
+		}
+	}
+}
+
+// prepareMedia runs the image processing step for a platform and returns the media URLs to publish
+// Processed variants are stored alongside the original media items; on failure the original is used
+func (s *MultiPlatformPostService) prepareMedia(ctx context.Context, postID int64, plt models.Platform, mediaURLs []string) []string {
+	if s.imageProcessor == nil || s.mediaItemRepo == nil {
+		return mediaURLs
+	}
+
+	items, err := s.mediaItemRepo.GetByPostID(postID)
+	if err != nil {
+		log.Printf("Failed to get media items for post %d: %v", postID, err)
+		return mediaURLs
+	}
+
+	prepared := make([]string, len(mediaURLs))
+	copy(prepared, mediaURLs)
+
+	for _, item := range items {
+		if item.MediaType != string(MediaTypeImage) || item.Position >= len(prepared) {
+			continue
+		}
+		if item.ProcessedURL != "" {
+			prepared[item.Position] = item.ProcessedURL
+			continue
+		}
+
+		processed, err := s.imageProcessor.Process(ctx, plt, item.MediaURL)
+		if err != nil {
+			log.Printf("Failed to process image %s for %s, using original: %v", item.MediaURL, plt, err)
+			continue
+		}
+		if processed == nil {
+			continue
+		}
+
+		if err := s.mediaItemRepo.UpdateProcessed(item.ID, processed.URL, processed.Width, processed.Height, processed.FileSize); err != nil {
+			log.Printf("Failed to store processed image for post %d: %v", postID, err)
+		}
+		prepared[item.Position] = processed.URL
+	}
+
+	return prepared
+}
+
+// mediaKinds returns the kind of every media file of a post, as probed when the post was created
+// Processed images are listed under their own URL too, since they replace the original when published
+func (s *MultiPlatformPostService) mediaKinds(postID int64) map[string]string {
+	if s.mediaItemRepo == nil {
+		return nil
+	}
+
+	items, err := s.mediaItemRepo.GetByPostID(postID)
+	if err != nil {
+		log.Printf("Failed to get media items for post %d: %v", postID, err)
+		return nil
+	}
+
+	kinds := make(map[string]string, len(items))
+	for _, item := range items {
+		if item.MediaType == string(MediaTypeUnknown) {
+			continue
+		}
+		kinds[item.MediaURL] = item.MediaType
+		if item.ProcessedURL != "" {
+			kinds[item.ProcessedURL] = item.MediaType
+		}
+	}
+	return kinds
+}
+
 // processPlatformPost handles posting to a specific platform asynchronously
-func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int64, plt models.Platform, tiktokSettings *TikTokSettings, mediaURLs []string, mu *sync.Mutex, errors map[string]string) {
+// ctx is cancelled when the owner cancels the post or the server shuts down
+func (s *MultiPlatformPostService) processPlatformPost(ctx context.Context, postID int64, userID int64, plt models.Platform, settings platformapi.Settings, mediaURLs []string, mu *sync.Mutex, platformErrors map[string]string) {
+	defer s.untrackPost(postID)
+
+	// fail records a failure on the post and in the request's error map
+	fail := func(code platformapi.ErrorCode, message, detail string) {
+		s.markFailed(ctx, postID, code, message, detail)
+		mu.Lock()
+		platformErrors[string(plt)] = message
+		mu.Unlock()
+	}
+
+	// failPlatform records a failed platform call, classified by its error code
+	failPlatform := func(err error) {
+		platformErr := platformapi.ClassifyError(plt, err)
+		fail(platformErr.Code, platformErr.UserMessage(), err.Error())
+	}
+
+	// The post may have been cancelled before processing started
+	if ctx.Err() != nil {
+		s.markFailed(ctx, postID, platformapi.ErrorCodeTransient, "Publishing was interrupted before it started", "")
+		return
+	}
+
 	// Update status to processing
 	if err := s.postRepo.UpdateStatus(postID, models.PostStatusProcessing, ""); err != nil {
 		log.Printf("Failed to update post %d status to processing: %v", postID, err)
@@ -521,35 +623,296 @@ func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int6
 	post, err := s.postRepo.GetByID(postID)
 	if err != nil {
 		log.Printf("Failed to get post %d: %v", postID, err)
-		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, "Failed to retrieve post details")
+		s.markFailed(ctx, postID, platformapi.ErrorCodeUnknown, "Failed to retrieve post details", err.Error())
 		return
 	}
 
 	// Get platform service
-	rawService, err := s.platformRegistry.Get(plt)
+	platformService, err := s.platformRegistry.Get(plt)
 	if err != nil {
 		log.Printf("Platform %s not found: %v", plt, err)
-		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, fmt.Sprintf("Platform %s not available", plt))
-		mu.Lock()
-		errors[string(plt)] = fmt.Sprintf("Platform not available: %v", err)
-		mu.Unlock()
+		fail(platformapi.ErrorCodeUnknown, fmt.Sprintf("Platform %s is not available", plt), err.Error())
 		return
 	}
 
-	// Wrap rawService with adapter to implement PlatformService interface
-	platformService := &platformServiceAdapter{service: rawService}
+	// Fit images to the platform's limits before they are uploaded or fetched by the platform
+	mediaURLs = s.prepareMedia(ctx, postID, plt, mediaURLs)
+	mediaKinds := s.mediaKinds(postID)
+
+	// Rate limits the platform reports while publishing count against the account's budget
+	ctx = platformapi.WithRateLimitObserver(ctx, func(endpoint string, limit platformapi.RateLimit) {
+		s.quotas.Observe(userID, plt, endpoint, limit)
+	})
+	defer s.quotas.Release(userID, plt, postID)
+
+	// Posts that would exceed the account's budget, or that the platform rejects as rate
+	// limited, wait until the budget resets and are tried again
+	var token *models.Token
+	var postResp *platformapi.PostResponse
+	for deferrals := 0; ; deferrals++ {
+		if err := s.awaitPlatform(ctx, postID, plt); err != nil {
+			if s.keepDeferred(ctx, postID) {
+				return
+			}
+			if ctx.Err() != nil {
+				s.markFailed(ctx, postID, platformapi.ErrorCodeTransient, "Publishing was interrupted while the post was held", err.Error())
+				return
+			}
+			failPlatform(err)
+			return
+		}
+
+		if err := s.awaitQuota(ctx, postID, userID, plt); err != nil {
+			if s.keepDeferred(ctx, postID) {
+				return
+			}
+			if ctx.Err() != nil {
+				s.markFailed(ctx, postID, platformapi.ErrorCodeTransient, "Publishing was interrupted while the post was deferred", err.Error())
+				return
+			}
+			failPlatform(err)
+			return
+		}
+
+		token, err = s.accessToken(ctx, userID, plt, platformService)
+		if err != nil {
+			failPlatform(err)
+			return
+		}
+
+		postResp, err = s.publish(platformapi.WithInstance(ctx, token.InstanceURL), post, plt, mediaURLs, mediaKinds, settings, token.AccessToken, platformService)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, errPublishInterrupted) {
+			s.markFailed(ctx, postID, platformapi.ErrorCodeTransient, "Publishing was interrupted", "")
+			return
+		}
+
+		platformErr := platformapi.ClassifyError(plt, err)
+		if platformErr.Code != platformapi.ErrorCodeRateLimited || deferrals >= maxRateLimitDeferrals {
+			failPlatform(err)
+			return
+		}
+		log.Printf("%s rate limited post %d, deferring it: %v", plt, postID, err)
+		s.quotas.RecordRateLimited(userID, plt, platformErr.RetryAt)
+	}
+
+	log.Printf("Post created on %s with ID: %s (status: %s)", plt, postResp.PostID, postResp.Status)
 
-	// Get valid access token for this platform
+	// Platforms that publish asynchronously report processing and have to be polled
+	switch postResp.Status {
+	case "sent_to_inbox":
+		// Inbox mode: already delivered to the creator's inbox (TikTok)
+		if err := s.postRepo.MarkSentToInboxWithPlatform(postID, postResp.PostID); err != nil {
+			log.Printf("Failed to mark post %d as sent to inbox: %v", postID, err)
+		} else {
+			log.Printf("Post %d sent to %s inbox successfully", postID, plt)
+		}
+	case "processing", "pending":
+		s.pollPostStatus(platformapi.WithInstance(ctx, token.InstanceURL), postID, plt, postResp.PostID, token.AccessToken, platformService)
+	default:
+		// X and Instagram posts are published as soon as they are created
+		if err := s.postRepo.MarkPublishedWithPlatform(postID, postResp.PostID, postResp.ShareURL); err != nil {
+			log.Printf("Failed to mark post %d as published: %v", postID, err)
+		} else {
+			log.Printf("Post %d successfully published to %s (share URL: %s)", postID, plt, postResp.ShareURL)
+		}
+	}
+}
+
+// keepDeferred reports whether a deferred post was interrupted by the server shutting down
+// Such posts stay deferred, with their resume time, and are picked up by ResumePosts on the
+// next start
+func (s *MultiPlatformPostService) keepDeferred(ctx context.Context, postID int64) bool {
+	if ctx.Err() == nil || s.baseCtx.Err() == nil || errors.Is(context.Cause(ctx), errPostCancelled) {
+		return false
+	}
+	log.Printf("Post %d stays deferred until the server starts again", postID)
+	return true
+}
+
+// ResumePosts picks up the posts the server was still working on when it last stopped
+// Deferred posts, including posts held during an outage, wait until their resume time again and
+// can be cancelled meanwhile; pending posts hadn't started and are published now. Posts that were
+// processing may already have reached the platform, so they are failed rather than sent twice
+func (s *MultiPlatformPostService) ResumePosts() {
+	posts, err := s.postRepo.GetByStatus(models.PostStatusDeferred, models.PostStatusPending, models.PostStatusProcessing)
+	if err != nil {
+		log.Printf("Failed to get unfinished posts: %v", err)
+		return
+	}
+
+	var mu sync.Mutex
+	platformErrors := make(map[string]string)
+	resumed := 0
+	for _, post := range posts {
+		if post.Status == models.PostStatusProcessing {
+			log.Printf("Post %d was interrupted by a server restart while processing", post.ID)
+			s.markFailed(s.baseCtx, post.ID, platformapi.ErrorCodeTransient, "Publishing was interrupted by a server restart. The post may still appear on the platform; check before posting it again.", "")
+			continue
+		}
+		s.resumePost(post, &mu, platformErrors)
+		resumed++
+	}
+	if resumed > 0 {
+		log.Printf("Resumed %d unfinished posts", resumed)
+	}
+}
+
+// resumePost processes a deferred post again once its resume time has come
+func (s *MultiPlatformPostService) resumePost(post *models.Post, mu *sync.Mutex, platformErrors map[string]string) {
+	settings, err := s.storedSettings(post)
+	if err != nil {
+		log.Printf("Failed to resume post %d: %v", post.ID, err)
+		s.markFailed(s.baseCtx, post.ID, platformapi.ErrorCodeUnknown, "The post could not be resumed after a server restart", err.Error())
+		return
+	}
+	mediaURLs := s.storedMediaURLs(post)
+
+	s.startPost(post.ID, func(ctx context.Context) {
+		if post.DeferredUntil != nil {
+			if err := sleepContext(ctx, time.Until(*post.DeferredUntil)); err != nil {
+				// Cancelled by its owner, or still deferred for the next start
+				s.untrackPost(post.ID)
+				return
+			}
+		}
+		s.processPlatformPost(ctx, post.ID, post.UserID, post.Platform, settings, mediaURLs, mu, platformErrors)
+	})
+}
+
+// storedSettings returns the settings a post was created with, validated again by its platform
+// so they are normalized as they were when the post was created
+func (s *MultiPlatformPostService) storedSettings(post *models.Post) (platformapi.Settings, error) {
+	platformService, err := s.platformRegistry.Get(post.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings platformapi.Settings
+	if post.Settings != "" {
+		if err := json.Unmarshal([]byte(post.Settings), &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	}
+	return platformService.ValidateSettings(settings)
+}
+
+// storedMediaURLs returns the original media URLs of a post in their posting order
+func (s *MultiPlatformPostService) storedMediaURLs(post *models.Post) []string {
+	if s.mediaItemRepo != nil {
+		items, err := s.mediaItemRepo.GetByPostID(post.ID)
+		if err != nil {
+			log.Printf("Failed to get media items of post %d: %v", post.ID, err)
+		} else if len(items) > 0 {
+			mediaURLs := make([]string, len(items))
+			for i, item := range items {
+				mediaURLs[i] = item.MediaURL
+			}
+			return mediaURLs
+		}
+	}
+	if post.VideoURL == "" {
+		return nil
+	}
+	return []string{post.VideoURL}
+}
+
+// awaitPlatform holds a post while the platform's circuit breaker is open, so posts are not
+// attempted while the platform is failing
+// Held posts are deferred and can be cancelled. Returns a transient error if the platform is
+// still failing after maxOutageHold, or the context's error if the post is interrupted
+func (s *MultiPlatformPostService) awaitPlatform(ctx context.Context, postID int64, plt models.Platform) error {
+	breaker := s.platformRegistry.Breaker(plt)
+	if breaker == nil || breaker.Allow() {
+		return nil
+	}
+
+	health := breaker.Health()
+	log.Printf("Post %d held: %s circuit breaker is %s (error rate %.0f%%)", postID, plt, health.State, health.ErrorRate*100)
+	message := platformapi.UserMessage(plt, platformapi.ErrorCodeTransient, time.Time{}) + " The post will be published when it recovers."
+	if err := s.postRepo.MarkDeferred(postID, breaker.RetryAt(), message); err != nil {
+		log.Printf("Failed to mark post %d as deferred: %v", postID, err)
+	}
+	s.markDeferred(postID)
+
+	heldUntil := time.Now().Add(maxOutageHold)
+	for !breaker.Allow() {
+		if time.Now().After(heldUntil) {
+			return platformapi.NewPlatformError(plt, platformapi.ErrorCodeTransient, 0, "", "platform was unavailable: "+breaker.Health().LastError)
+		}
+		wait := time.Until(breaker.RetryAt())
+		if wait <= 0 || wait > outageRecheckInterval {
+			wait = outageRecheckInterval
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Post %d released: %s circuit breaker let it through", postID, plt)
+	if err := s.postRepo.MarkResumed(postID); err != nil {
+		log.Printf("Failed to resume post %d: %v", postID, err)
+	}
+	return nil
+}
+
+// awaitQuota reserves a slot in the account's publishing budget for a post
+// When the budget is used up the post is deferred until it resets; deferred posts can be
+// cancelled. Returns a rate limit error if the budget resets later than maxQuotaDeferral,
+// or the context's error if the post is interrupted while it waits
+func (s *MultiPlatformPostService) awaitQuota(ctx context.Context, postID int64, userID int64, plt models.Platform) error {
+	deferred := false
+	for {
+		retryAt, err := s.quotas.Reserve(userID, plt, postID)
+		if err != nil {
+			// Publishing without knowing the budget is better than not publishing at all
+			log.Printf("Failed to check %s quota of user %d: %v", plt, userID, err)
+			break
+		}
+		if retryAt.IsZero() {
+			break
+		}
+		if time.Until(retryAt) > maxQuotaDeferral {
+			platformErr := platformapi.NewPlatformError(plt, platformapi.ErrorCodeRateLimited, 0, "", "publishing budget is used up")
+			platformErr.RetryAt = retryAt
+			return platformErr
+		}
+
+		log.Printf("Post %d deferred until %v: %s publishing budget of user %d is used up", postID, retryAt, plt, userID)
+		message := platformapi.UserMessage(plt, platformapi.ErrorCodeRateLimited, retryAt) + " The post will be published then."
+		if err := s.postRepo.MarkDeferred(postID, retryAt, message); err != nil {
+			log.Printf("Failed to mark post %d as deferred: %v", postID, err)
+		}
+		s.markDeferred(postID)
+		deferred = true
+
+		if err := sleepContext(ctx, time.Until(retryAt)); err != nil {
+			return err
+		}
+	}
+
+	if deferred {
+		if err := s.postRepo.MarkResumed(postID); err != nil {
+			log.Printf("Failed to resume post %d: %v", postID, err)
+		}
+	}
+	return nil
+}
+
+// accessToken returns a valid access token of the user for a platform, refreshing it if it
+// has expired or expires soon
+func (s *MultiPlatformPostService) accessToken(ctx context.Context, userID int64, plt models.Platform, platformService platformapi.PlatformService) (*models.Token, error) {
 	token, err := s.tokenRepo.GetByUserIDAndPlatform(userID, plt)
 	if err != nil {
 		log.Printf("Failed to get token for user %d on platform %s: %v", userID, plt, err)
-		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, "Failed to get access token")
-		mu.Lock()
-		errors[string(plt)] = "Failed to get access token"
-		mu.Unlock()
-		return
+		return nil, platformapi.NewPlatformError(plt, platformapi.ErrorCodeAuthExpired, 0, "", err.Error())
 	}
 
+	// Tokens of per-instance platforms are refreshed on the instance that issued them
+	ctx = platformapi.WithInstance(ctx, token.InstanceURL)
+
 	// Check if token needs refresh
 	// Refresh proactively if token will expire within 7 days (for Instagram long-lived tokens)
 	// or if it's already expired
@@ -561,18 +924,13 @@ func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int6
 		log.Printf("Token already expired for user %d on %s at %v", userID, plt, token.ExpiresAt)
 
 		// Try to refresh anyway (works for TikTok, may fail for Instagram)
-		tokenResp, err := platformService.RefreshAccessToken(token.RefreshToken)
+		tokenResp, err := s.refreshToken(ctx, platformService, token.RefreshToken)
 		if err != nil {
 			log.Printf("Failed to refresh expired token: %v", err)
-			errorMsg := "Access token has expired. Please reconnect your account."
-			if plt == models.PlatformInstagram {
-				errorMsg = "Instagram access token has expired. Please disconnect and reconnect your Instagram account to continue posting."
+			if platformapi.ClassifyError(plt, err).Retryable() {
+				return nil, err
 			}
-			s.postRepo.UpdateStatus(postID, models.PostStatusFailed, errorMsg)
-			mu.Lock()
-			errors[string(plt)] = errorMsg
-			mu.Unlock()
-			return
+			return nil, platformapi.NewPlatformError(plt, platformapi.ErrorCodeAuthExpired, 0, "", err.Error())
 		}
 
 		// Token refresh succeeded
@@ -586,7 +944,7 @@ func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int6
 	} else if tokenExpiresSoon {
 		// Token will expire soon - refresh proactively
 		log.Printf("Token expiring soon for user %d on %s (expires at %v), refreshing proactively...", userID, plt, token.ExpiresAt)
-		tokenResp, err := platformService.RefreshAccessToken(token.RefreshToken)
+		tokenResp, err := s.refreshToken(ctx, platformService, token.RefreshToken)
 		if err != nil {
 			// Log warning but continue with existing token since it's still valid
 			log.Printf("Warning: Failed to proactively refresh token for %s: %v", plt, err)
@@ -602,19 +960,22 @@ func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int6
 		}
 	}
 
+	return token, nil
+}
+
+// publish uploads the media of a post if the platform needs it first and creates the post
+// Returns errPublishInterrupted if the post was cancelled before it was handed to the platform
+func (s *MultiPlatformPostService) publish(ctx context.Context, post *models.Post, plt models.Platform, mediaURLs []string, mediaKinds map[string]string, settings platformapi.Settings, accessToken string, platformService platformapi.PlatformService) (*platformapi.PostResponse, error) {
 	// Upload media if needed (for platforms like X that require upload before posting)
 	var mediaIDs []string
-	if plt == models.PlatformX {
-		log.Printf("Uploading %d media file(s) to %s for post %d", len(mediaURLs), plt, postID)
+	if uploadsMediaFirst(plt) {
+		log.Printf("Uploading %d media file(s) to %s for post %d", len(mediaURLs), plt, post.ID)
 		for i, mediaURL := range mediaURLs {
-			mediaID, err := platformService.UploadMedia(token.AccessToken, mediaURL)
+			progress := s.uploadProgressReporter(post.ID, i, len(mediaURLs))
+			mediaID, err := s.uploadMedia(ctx, platformService, accessToken, mediaURL, progress)
 			if err != nil {
 				log.Printf("Failed to upload media %d to %s: %v", i+1, plt, err)
-				s.postRepo.UpdateStatus(postID, models.PostStatusFailed, fmt.Sprintf("Media upload failed: %v", err))
-				mu.Lock()
-				errors[string(plt)] = fmt.Sprintf("Media upload failed: %v", err)
-				mu.Unlock()
-				return
+				return nil, fmt.Errorf("media upload failed: %w", err)
 			}
 			mediaIDs = append(mediaIDs, mediaID)
 			log.Printf("Media %d uploaded to %s: %s", i+1, plt, mediaID)
@@ -622,118 +983,302 @@ func (s *MultiPlatformPostService) processPlatformPost(postID int64, userID int6
 	}
 
 	// Create post on platform
-	postContent := PostContent{
-		Text:           post.Caption,
-		MediaURL:       mediaURLs[0], // Primary URL
-		MediaURLs:      mediaURLs,    // All URLs for carousel/multi-image
-		MediaIDs:       mediaIDs,
-		TikTokSettings: tiktokSettings,
+	postContent := platformapi.PostContent{
+		Text:          post.Caption,
+		MediaURLs:     mediaURLs, // All URLs for carousel/multi-image
+		MediaIDs:      mediaIDs,
+		MediaKinds:    mediaKinds,
+		Settings:      settings,
+		PostID:        post.ID,
+		PublicationID: post.PublicationID,
+	}
+	if len(mediaURLs) > 0 {
+		postContent.MediaURL = mediaURLs[0] // Primary URL
+	}
+
+	// From here on the platform may publish the post, so it can no longer be cancelled
+	if !s.markSubmitted(ctx, post.ID) {
+		return nil, errPublishInterrupted
 	}
 
-	postResp, err := platformService.CreatePost(token.AccessToken, postContent)
+	createCtx, cancel := context.WithTimeout(ctx, createPostTimeout)
+	defer cancel()
+	postResp, err := platformService.CreatePost(createCtx, accessToken, postContent)
 	if err != nil {
 		log.Printf("Failed to create post on %s: %v", plt, err)
-		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, fmt.Sprintf("Post creation failed: %v", err))
-		mu.Lock()
-		errors[string(plt)] = fmt.Sprintf("Post creation failed: %v", err)
-		mu.Unlock()
+		return nil, fmt.Errorf("post creation failed: %w", err)
+	}
+	return postResp, nil
+}
+
+// uploadsMediaFirst reports whether media has to be uploaded to the platform before the post
+// is created, instead of the platform fetching it from its URL
+func uploadsMediaFirst(plt models.Platform) bool {
+	return plt == models.PlatformX || plt == models.PlatformMock
+}
+
+// refreshToken refreshes an access token within tokenRefreshTimeout
+func (s *MultiPlatformPostService) refreshToken(ctx context.Context, platformService platformapi.PlatformService, refreshToken string) (*platformapi.TokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenRefreshTimeout)
+	defer cancel()
+	return platformService.RefreshAccessToken(ctx, refreshToken)
+}
+
+// uploadMedia uploads media within mediaUploadTimeout and reports progress when the platform supports it
+func (s *MultiPlatformPostService) uploadMedia(ctx context.Context, platformService platformapi.PlatformService, accessToken, mediaURL string, progress platformapi.UploadProgressFunc) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, mediaUploadTimeout)
+	defer cancel()
+
+	if uploader, ok := platformService.(platformapi.ProgressUploader); ok {
+		return uploader.UploadMediaWithProgress(ctx, accessToken, mediaURL, progress)
+	}
+	return platformService.UploadMedia(ctx, accessToken, mediaURL)
+}
+
+// startPost processes a post in the background with run, under a context that CancelPost and
+// Shutdown cancel
+func (s *MultiPlatformPostService) startPost(postID int64, run func(ctx context.Context)) {
+	ctx := s.trackPost(postID)
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		run(ctx)
+	}()
+}
+
+// Shutdown interrupts every post in progress and waits until they have recorded the interruption,
+// so the database can be closed after it returns
+// Returns ctx's error if posts are still running when ctx is done
+func (s *MultiPlatformPostService) Shutdown(ctx context.Context) error {
+	s.stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("posts still in progress: %w", ctx.Err())
+	}
+}
+
+// trackPost registers a post that is about to be processed and returns its context
+// The context is cancelled by CancelPost or when the server shuts down
+func (s *MultiPlatformPostService) trackPost(postID int64) context.Context {
+	ctx, cancel := context.WithCancelCause(s.baseCtx)
+
+	s.runningMu.Lock()
+	s.running[postID] = &runningPost{cancel: cancel}
+	s.runningMu.Unlock()
+	return ctx
+}
+
+// untrackPost forgets a post once its processing has finished
+func (s *MultiPlatformPostService) untrackPost(postID int64) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	if running, ok := s.running[postID]; ok {
+		running.cancel(nil)
+		delete(s.running, postID)
+	}
+}
+
+// markSubmitted records that a post is being handed to the platform
+// Returns false if the post was cancelled first
+func (s *MultiPlatformPostService) markSubmitted(ctx context.Context, postID int64) bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	running, ok := s.running[postID]
+	if !ok || ctx.Err() != nil {
+		return false
+	}
+	running.submitted = true
+	return true
+}
+
+// markDeferred records that a post is waiting for the publishing budget to reset
+// Nothing is with the platform while it waits, so it can be cancelled again
+func (s *MultiPlatformPostService) markDeferred(postID int64) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	if running, ok := s.running[postID]; ok {
+		running.submitted = false
+	}
+}
+
+// markFailed marks a post as failed unless its owner cancelled it, in which case CancelPost
+// has already set the status
+// When the server is shutting down the failure is recorded as an interruption
+// message is shown to end users; detail keeps the underlying error for debugging
+func (s *MultiPlatformPostService) markFailed(ctx context.Context, postID int64, code platformapi.ErrorCode, message, detail string) {
+	if errors.Is(context.Cause(ctx), errPostCancelled) {
 		return
 	}
+	if s.baseCtx.Err() != nil {
+		code = platformapi.ErrorCodeTransient
+		message = "Publishing was interrupted because the server shut down"
+	}
+	if err := s.postRepo.MarkFailed(postID, string(code), message, detail); err != nil {
+		log.Printf("Failed to mark post %d as failed: %v", postID, err)
+	}
+}
 
-	log.Printf("Post created on %s with ID: %s (status: %s)", plt, postResp.PostID, postResp.Status)
+// CancelPost stops a post that is still being prepared or uploaded
+// Posts that were already handed to the platform can't be cancelled and return ErrPostNotCancellable
+func (s *MultiPlatformPostService) CancelPost(postID int64, userID int64) (*models.Post, error) {
+	post, err := s.GetPostByID(postID, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Handle platform-specific processing
-	if plt == models.PlatformTikTok && postResp.Status == "sent_to_inbox" {
-		// Inbox mode: already delivered to TikTok inbox, mark as sent_to_inbox
-		if err := s.postRepo.MarkSentToInboxWithPlatform(postID, postResp.PostID); err != nil {
-			log.Printf("Failed to mark post %d as sent to inbox: %v", postID, err)
-		} else {
-			log.Printf("Post %d sent to TikTok inbox successfully", postID)
-		}
-	} else if plt == models.PlatformTikTok && postResp.Status == "published" {
-		// If TikTok reports published immediately, mark as published
-		if err := s.postRepo.MarkPublishedWithPlatform(postID, postResp.PostID); err != nil {
-			log.Printf("Failed to mark post %d as published: %v", postID, err)
-		} else {
-			log.Printf("Post %d successfully published to TikTok", postID)
-		}
-	} else if plt == models.PlatformTikTok {
-		// Direct Post: TikTok requires polling for status
-		s.pollTikTokStatus(postID, userID, postResp.PostID, token.AccessToken, platformService)
-	} else if plt == models.PlatformX {
-		// X posts are published immediately
-		if err := s.postRepo.MarkPublishedWithPlatform(postID, postResp.PostID); err != nil {
-			log.Printf("Failed to mark post %d as published: %v", postID, err)
-		} else {
-			log.Printf("Post %d successfully published to %s", postID, plt)
+	s.runningMu.Lock()
+	running, ok := s.running[postID]
+	if !ok || running.submitted {
+		s.runningMu.Unlock()
+		return nil, ErrPostNotCancellable
+	}
+	running.cancel(errPostCancelled)
+	s.runningMu.Unlock()
+
+	if err := s.postRepo.UpdateStatus(postID, models.PostStatusCancelled, "Cancelled by user"); err != nil {
+		return nil, fmt.Errorf("failed to cancel post: %w", err)
+	}
+	log.Printf("Post %d cancelled by user %d", postID, userID)
+
+	post.Status = models.PostStatusCancelled
+	post.ErrorMessage = "Cancelled by user"
+	return post, nil
+}
+
+// DeletePost deletes a published post from its platform and marks it deleted
+// Posts that aren't published, and posts of platforms that can't delete posts, return
+// ErrPostNotDeletable
+func (s *MultiPlatformPostService) DeletePost(ctx context.Context, postID int64, userID int64) (*models.Post, error) {
+	post, err := s.GetPostByID(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != models.PostStatusPublished || post.PlatformPostID == "" {
+		return nil, ErrPostNotDeletable
+	}
+
+	platformService, err := s.platformRegistry.Get(post.Platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform %s: %w", post.Platform, err)
+	}
+	deleter, ok := platformService.(platformapi.PostDeleter)
+	if !ok || !platformService.Capabilities().SupportsDeletion {
+		return nil, ErrPostNotDeletable
+	}
+
+	token, err := s.accessToken(ctx, userID, post.Platform, platformService)
+	if err != nil {
+		return nil, err
+	}
+	ctx = platformapi.WithInstance(ctx, token.InstanceURL)
+	if err := deleter.DeletePost(ctx, token.AccessToken, post.PlatformPostID); err != nil {
+		return nil, fmt.Errorf("failed to delete post from %s: %w", post.Platform, err)
+	}
+
+	if err := s.postRepo.UpdateStatus(postID, models.PostStatusDeleted, ""); err != nil {
+		return nil, fmt.Errorf("failed to mark post deleted: %w", err)
+	}
+	log.Printf("Post %d deleted from %s by user %d", postID, post.Platform, userID)
+
+	post.Status = models.PostStatusDeleted
+	return post, nil
+}
+
+// uploadProgressReporter returns a progress callback that stores the progress of media item index
+// out of total on the post, so all items together go from 0 to 100 percent per stage
+func (s *MultiPlatformPostService) uploadProgressReporter(postID int64, index, total int) platformapi.UploadProgressFunc {
+	var mu sync.Mutex
+	lastStage, lastPercent := "", -1
+
+	return func(stage string, percent int) {
+		overall := (index*100 + percent) / total
+
+		mu.Lock()
+		defer mu.Unlock()
+		if stage == lastStage && overall == lastPercent {
+			return
 		}
-	} else if plt == models.PlatformInstagram {
-		// Instagram posts are published immediately after successful creation
-		if err := s.postRepo.MarkPublishedWithPlatform(postID, postResp.PostID); err != nil {
-			log.Printf("Failed to mark post %d as published: %v", postID, err)
-		} else {
-			log.Printf("Post %d successfully published to %s with permalink: %s", postID, plt, postResp.ShareURL)
+		lastStage, lastPercent = stage, overall
+
+		if err := s.postRepo.UpdateProgress(postID, stage, overall); err != nil {
+			log.Printf("Failed to update progress of post %d: %v", postID, err)
 		}
 	}
 }
 
-// pollTikTokStatus polls TikTok for post status until complete
-func (s *MultiPlatformPostService) pollTikTokStatus(postID int64, userID int64, publishID string, accessToken string, platformService PlatformService) {
-	maxAttempts := 60 // Poll for up to 5 minutes
-	attempt := 0
+// pollPostStatus polls the platform for the status of an asynchronously published post
+// until it is complete or statusPollTimeout passes
+func (s *MultiPlatformPostService) pollPostStatus(ctx context.Context, postID int64, plt models.Platform, publishID string, accessToken string, platformService platformapi.PlatformService) {
+	pollCtx, cancel := context.WithTimeout(ctx, statusPollTimeout)
+	defer cancel()
 
-	for attempt < maxAttempts {
-		time.Sleep(5 * time.Second)
-		attempt++
+	for {
+		if err := sleepContext(pollCtx, statusPollInterval); err != nil {
+			break
+		}
 
-		statusResp, err := platformService.GetPostStatus(accessToken, publishID)
+		statusResp, err := platformService.GetPostStatus(pollCtx, accessToken, publishID)
 		if err != nil {
 			log.Printf("Failed to get publish status: %v", err)
 			continue
 		}
 
-		log.Printf("TikTok publish status for %s: %s", publishID, statusResp.Status)
+		log.Printf("%s publish status for %s: %s", plt, publishID, statusResp.Status)
 
 		switch statusResp.Status {
 		case "published":
-			// Video successfully published
 			platformPostID := publishID
 			if statusResp.ShareID != "" {
 				platformPostID = statusResp.ShareID
 			}
-			if err := s.postRepo.MarkPublishedWithPlatform(postID, platformPostID); err != nil {
+			if err := s.postRepo.MarkPublishedWithPlatform(postID, platformPostID, statusResp.ShareURL); err != nil {
 				log.Printf("Failed to mark post %d as published: %v", postID, err)
 			} else {
-				log.Printf("Post %d successfully published to TikTok", postID)
+				log.Printf("Post %d successfully published to %s", postID, plt)
 			}
 			return
 
 		case "failed":
 			// Publishing failed
-			failReason := statusResp.FailReason
-			if failReason == "" {
-				failReason = "Unknown error"
+			code := statusResp.ErrorCode
+			if code == "" {
+				code = platformapi.ErrorCodeUnknown
 			}
-			s.postRepo.UpdateStatus(postID, models.PostStatusFailed, fmt.Sprintf("TikTok publish failed: %s", failReason))
-			log.Printf("Post %d failed: %s", postID, failReason)
+			s.markFailed(ctx, postID, code, platformapi.UserMessage(plt, code, time.Time{}), statusResp.FailReason)
+			log.Printf("Post %d failed: %s (%s)", postID, statusResp.FailReason, code)
 			return
 
 		case "sent_to_inbox":
 			if err := s.postRepo.MarkSentToInboxWithPlatform(postID, publishID); err != nil {
 				log.Printf("Failed to mark post %d as sent to inbox: %v", postID, err)
 			} else {
-				log.Printf("Post %d sent to TikTok inbox successfully", postID)
+				log.Printf("Post %d sent to %s inbox successfully", postID, plt)
 			}
 			return
 
 		case "processing":
-			log.Printf("Post %d: TikTok is processing the video (%d%%)", postID, statusResp.ProgressPercent)
+			log.Printf("Post %d: %s is processing the media (%d%%)", postID, plt, statusResp.ProgressPercent)
+			if err := s.postRepo.UpdateProgress(postID, platformapi.UploadStageProcessing, statusResp.ProgressPercent); err != nil {
+				log.Printf("Failed to update progress of post %d: %v", postID, err)
+			}
 		}
 	}
 
-	// Timeout reached
-	s.postRepo.UpdateStatus(postID, models.PostStatusFailed, "Publishing timeout - took too long")
-	log.Printf("Post %d timed out after %d attempts", postID, maxAttempts)
+	// Timeout reached or the server is shutting down; the platform may still publish the post
+	s.markFailed(ctx, postID, platformapi.ErrorCodeTransient, "Publishing timed out; the post may still appear on the platform later", context.Cause(pollCtx).Error())
+	log.Printf("Stopped polling %s status of post %d: %v", plt, postID, context.Cause(pollCtx))
 }
 
 // GetPostByID retrieves a post by ID
@@ -748,6 +1293,15 @@ func (s *MultiPlatformPostService) GetPostByID(postID int64, userID int64) (*mod
 		return nil, fmt.Errorf("post not found")
 	}
 
+	if s.mediaItemRepo != nil {
+		items, err := s.mediaItemRepo.GetByPostID(postID)
+		if err != nil {
+			log.Printf("Failed to get media items for post %d: %v", postID, err)
+		} else {
+			post.MediaItems = items
+		}
+	}
+
 	return post, nil
 }
 
@@ -767,6 +1321,11 @@ func (s *MultiPlatformPostService) GetUserPosts(userID int64, platformFilter mod
 	return s.postRepo.GetByUserID(userID, limit, offset)
 }
 
+// GetPublicationPosts retrieves the per-platform posts of a publication
+func (s *MultiPlatformPostService) GetPublicationPosts(userID int64, publicationID string) ([]*models.Post, error) {
+	return s.postRepo.GetByPublicationID(userID, publicationID)
+}
+
 // GetPostStatus retrieves the current status of a post
 func (s *MultiPlatformPostService) GetPostStatus(postID int64, userID int64) (*models.Post, error) {
 	return s.GetPostByID(postID, userID)
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// pinterestAPI sends requests to the Pinterest v5 API (/v5/...)
+type pinterestAPI struct {
+	baseURL    string // e.g. https://api.pinterest.com
+	httpClient *http.Client
+}
+
+// do sends a request with an optional JSON payload and decodes a JSON response into out
+func (a *pinterestAPI) do(ctx context.Context, method, path, accessToken string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	return a.decode(resp, out)
+}
+
+// get sends a GET request with the given query parameters and decodes the response into out
+func (a *pinterestAPI) get(ctx context.Context, path, accessToken string, params url.Values, out interface{}) error {
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return a.do(ctx, "GET", path, accessToken, nil, out)
+}
+
+// decode reads a v5 API response into out, or classifies it if it is unsuccessful
+func (a *pinterestAPI) decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return pinterestError(resp, body)
+	}
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
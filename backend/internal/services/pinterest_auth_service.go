@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PinterestAuthService handles Pinterest OAuth and user accounts
+// https://developers.pinterest.com/docs/getting-started/set-up-authentication-and-authorization/
+type PinterestAuthService struct {
+	appID       string
+	appSecret   string
+	redirectURI string
+	scopes      []string
+	authBaseURL string // Authorization page, e.g. https://www.pinterest.com
+	api         *pinterestAPI
+}
+
+// NewPinterestAuthService creates a new Pinterest auth service
+func NewPinterestAuthService(appID, appSecret, redirectURI string, scopes []string, authBaseURL, apiBaseURL string) *PinterestAuthService {
+	return &PinterestAuthService{
+		appID:       appID,
+		appSecret:   appSecret,
+		redirectURI: redirectURI,
+		scopes:      scopes,
+		authBaseURL: authBaseURL,
+		api: &pinterestAPI{
+			baseURL:    apiBaseURL,
+			httpClient: newHTTPClient("pinterest", 30*time.Second),
+		},
+	}
+}
+
+// PinterestTokenResponse represents the OAuth token response from Pinterest
+// Access tokens are valid for 30 days and refresh tokens for a year
+type PinterestTokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	RefreshToken          string `json:"refresh_token"` // Only issued with the first token, unless continuous refresh is on
+	TokenType             string `json:"token_type"`
+	ExpiresIn             int    `json:"expires_in"`
+	RefreshTokenExpiresIn int    `json:"refresh_token_expires_in"`
+	Scope                 string `json:"scope"`
+}
+
+// PinterestUserAccount is the account that authorized the app
+type PinterestUserAccount struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	BusinessName string `json:"business_name"`
+	AccountType  string `json:"account_type"` // PINNER or BUSINESS
+	ProfileImage string `json:"profile_image"`
+}
+
+// GenerateAuthURL creates the OAuth authorization URL and the state that protects it
+func (s *PinterestAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("client_id", s.appID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(s.scopes, ","))
+	params.Set("state", state)
+
+	return s.authBaseURL + "/oauth/?" + params.Encode(), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for an access token
+func (s *PinterestAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*PinterestTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "authorization_code")
+	formData.Set("code", code)
+	formData.Set("redirect_uri", s.redirectURI)
+	return s.requestToken(ctx, formData)
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token
+func (s *PinterestAuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*PinterestTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("refresh_token", refreshToken)
+	return s.requestToken(ctx, formData)
+}
+
+// requestToken posts a grant to the token endpoint, authenticating the app with HTTP Basic auth
+func (s *PinterestAuthService) requestToken(ctx context.Context, formData url.Values) (*PinterestTokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.api.baseURL+"/v5/oauth/token", strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.appID, s.appSecret)
+
+	resp, err := s.api.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var tokenResp PinterestTokenResponse
+	if err := s.api.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+	return &tokenResp, nil
+}
+
+// GetUserAccount retrieves the account the token belongs to
+func (s *PinterestAuthService) GetUserAccount(ctx context.Context, accessToken string) (*PinterestUserAccount, error) {
+	var account PinterestUserAccount
+	if err := s.api.get(ctx, "/v5/user_account", accessToken, nil, &account); err != nil {
+		return nil, fmt.Errorf("failed to get user account: %w", err)
+	}
+	if account.Username == "" {
+		return nil, fmt.Errorf("user account response has no username")
+	}
+	return &account, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// pinterestMediaCheckInterval is how often the processing status of uploaded videos is checked; tests shorten it
+var pinterestMediaCheckInterval = 5 * time.Second
+
+// pinterestMaxProcessingWait is how long to wait for Pinterest to process an uploaded video
+const pinterestMaxProcessingWait = 10 * time.Minute
+
+// Statuses of registered Pinterest media
+const (
+	pinterestMediaSucceeded = "succeeded"
+	pinterestMediaFailed    = "failed"
+)
+
+// PinterestMediaService uploads videos for video pins
+// A video is registered first, which returns a pre-signed upload URL and the form fields the
+// upload has to carry; once uploaded and processed, the media ID is used to create the pin
+// https://developers.pinterest.com/docs/api-features/creating-boards-and-pins/#creating-video-pins
+type PinterestMediaService struct {
+	api            *pinterestAPI
+	uploadClient   *http.Client // Uploads to the pre-signed upload URL, which take longer than API calls
+	downloadClient *http.Client
+}
+
+// NewPinterestMediaService creates a new Pinterest media service
+func NewPinterestMediaService(apiBaseURL string) *PinterestMediaService {
+	return &PinterestMediaService{
+		api: &pinterestAPI{
+			baseURL:    apiBaseURL,
+			httpClient: newHTTPClient("pinterest", 30*time.Second),
+		},
+		uploadClient:   newHTTPClient("pinterest", 10*time.Minute),
+		downloadClient: newHTTPClient("pinterest-media-download", 5*time.Minute),
+	}
+}
+
+// pinterestMediaUpload is the response of POST /v5/media
+type pinterestMediaUpload struct {
+	MediaID          string            `json:"media_id"`
+	MediaType        string            `json:"media_type"`
+	UploadURL        string            `json:"upload_url"`
+	UploadParameters map[string]string `json:"upload_parameters"`
+}
+
+// pinterestMediaStatus is the response of GET /v5/media/{media_id}
+type pinterestMediaStatus struct {
+	MediaID   string `json:"media_id"`
+	MediaType string `json:"media_type"`
+	Status    string `json:"status"` // registered, processing, succeeded or failed
+}
+
+// pinterestDownload is a video downloaded to a temp file
+type pinterestDownload struct {
+	path     string
+	mimeType string
+}
+
+// UploadVideo downloads the video at videoURL, registers and uploads it, and waits until
+// Pinterest has processed it
+// Returns the media ID a video pin is created with
+func (s *PinterestMediaService) UploadVideo(ctx context.Context, accessToken, videoURL string) (string, error) {
+	download, err := s.download(ctx, videoURL)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(download.path)
+
+	var upload pinterestMediaUpload
+	if err := s.api.do(ctx, "POST", "/v5/media", accessToken, map[string]string{"media_type": "video"}, &upload); err != nil {
+		return "", fmt.Errorf("failed to register video upload: %w", err)
+	}
+	if upload.MediaID == "" || upload.UploadURL == "" {
+		return "", fmt.Errorf("media registration response has no media ID or upload URL")
+	}
+
+	if err := s.uploadFile(ctx, upload, download); err != nil {
+		return "", fmt.Errorf("failed to upload video: %w", err)
+	}
+
+	if err := s.waitForProcessing(ctx, accessToken, upload.MediaID); err != nil {
+		return "", err
+	}
+
+	log.Printf("Pinterest video uploaded: %s", upload.MediaID)
+	return upload.MediaID, nil
+}
+
+// download saves the video at videoURL to a temp file and checks its type and size
+func (s *PinterestMediaService) download(ctx context.Context, videoURL string) (*pinterestDownload, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, videoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download video: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, platformapi.NewPlatformError(models.PlatformPinterest, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download video: status %d", resp.StatusCode))
+	}
+
+	out, err := os.CreateTemp("", "pinterest-media-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("failed to save video: %w", err)
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := out.ReadAt(header, 0)
+
+	// Trust the bytes first, then the Content-Type header
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	if mimeType == "" {
+		mimeType = "video/mp4"
+	}
+	if MediaTypeFromMIME(mimeType) != MediaTypeVideo {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformPinterest, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported video type %q", mimeType))
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformPinterest, MediaTypeVideo); limit > 0 && size > limit {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformPinterest, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("video is %.1f MB, Pinterest allows at most %.1f MB", megabytes(size), megabytes(limit)))
+	}
+
+	return &pinterestDownload{path: out.Name(), mimeType: mimeType}, nil
+}
+
+// uploadFile posts a downloaded video to the pre-signed upload URL of a registration
+// The upload parameters have to come before the file in the form; the URL takes no access token
+func (s *PinterestMediaService) uploadFile(ctx context.Context, upload pinterestMediaUpload, download *pinterestDownload) error {
+	file, err := os.Open(download.path)
+	if err != nil {
+		return fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer file.Close()
+
+	// The file is streamed into the multipart body instead of being buffered, videos can be large
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writePinterestUploadForm(form, file, upload.UploadParameters))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", upload.UploadURL, body)
+	if err != nil {
+		body.Close()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := s.uploadClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return platformapi.NewPlatformError(models.PlatformPinterest, platformapi.CodeForStatus(resp.StatusCode), resp.StatusCode, "",
+			fmt.Sprintf("upload rejected: %s", string(responseBody)))
+	}
+	return nil
+}
+
+// writePinterestUploadForm writes the upload parameters and the file of a video upload and closes the form
+func writePinterestUploadForm(form *multipart.Writer, file io.Reader, parameters map[string]string) error {
+	for name, value := range parameters {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", "video.mp4")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	return form.Close()
+}
+
+// waitForProcessing polls a registered video until Pinterest has processed it
+// Pins that reference a video that is still processing are rejected
+func (s *PinterestMediaService) waitForProcessing(ctx context.Context, accessToken, mediaID string) error {
+	startTime := time.Now()
+
+	for {
+		var status pinterestMediaStatus
+		if err := s.api.get(ctx, "/v5/media/"+mediaID, accessToken, nil, &status); err != nil {
+			return fmt.Errorf("failed to check video status: %w", err)
+		}
+
+		switch status.Status {
+		case pinterestMediaSucceeded:
+			return nil
+		case pinterestMediaFailed:
+			return platformapi.NewPlatformError(models.PlatformPinterest, platformapi.ErrorCodeMediaRejected, 0, status.Status,
+				"Pinterest could not process the video")
+		}
+
+		if time.Since(startTime) > pinterestMaxProcessingWait {
+			return platformapi.NewPlatformError(models.PlatformPinterest, platformapi.ErrorCodeTransient, 0, "",
+				fmt.Sprintf("video processing timeout after %s", pinterestMaxProcessingWait))
+		}
+
+		// registered, processing or an unknown status: keep waiting
+		if err := sleepContext(ctx, pinterestMediaCheckInterval); err != nil {
+			return fmt.Errorf("stopped waiting for video processing: %w", err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+)
+
+// Pinterest board privacies
+const (
+	PinterestBoardPublic = "PUBLIC"
+	PinterestBoardSecret = "SECRET" // Only the owner and collaborators see the board and its pins
+)
+
+// pinterestBoardsPerRequest is how many boards are requested per page of GET /v5/boards
+const pinterestBoardsPerRequest = 100
+
+// pinterestBoardIDPattern matches a board ID
+var pinterestBoardIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// IsPinterestBoardID reports whether id looks like the ID of a Pinterest board
+func IsPinterestBoardID(id string) bool {
+	return pinterestBoardIDPattern.MatchString(id)
+}
+
+// PinterestPostService creates pins and manages the boards they are saved to
+// https://developers.pinterest.com/docs/api/v5/pins-create
+type PinterestPostService struct {
+	mediaService *PinterestMediaService
+	api          *pinterestAPI
+}
+
+// NewPinterestPostService creates a new Pinterest post service
+func NewPinterestPostService(mediaService *PinterestMediaService) *PinterestPostService {
+	return &PinterestPostService{
+		mediaService: mediaService,
+		api:          mediaService.api,
+	}
+}
+
+// PinterestBoard is a board of the account
+type PinterestBoard struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Privacy     string `json:"privacy"` // PUBLIC, PROTECTED or SECRET
+	PinCount    int    `json:"pin_count"`
+}
+
+// PinterestPinRequest is the content of a pin
+type PinterestPinRequest struct {
+	BoardID     string
+	Title       string
+	Description string
+	Link        string // Destination link the pin opens
+	AltText     string
+
+	MediaURL string
+	IsVideo  bool
+	// CoverImageURL is the cover of a video pin; without one the first frame is used
+	CoverImageURL string
+}
+
+// CreatePin creates an image or video pin on a board
+// Images are fetched by Pinterest from their URL; videos are uploaded first
+// Returns the pin ID and its URL
+func (s *PinterestPostService) CreatePin(ctx context.Context, accessToken string, req PinterestPinRequest) (pinID, pinURL string, err error) {
+	if req.MediaURL == "" {
+		return "", "", fmt.Errorf("a pin needs an image or a video")
+	}
+
+	var mediaSource map[string]interface{}
+	if req.IsVideo {
+		mediaID, err := s.mediaService.UploadVideo(ctx, accessToken, req.MediaURL)
+		if err != nil {
+			return "", "", err
+		}
+		mediaSource = map[string]interface{}{
+			"source_type": "video_id",
+			"media_id":    mediaID,
+		}
+		if req.CoverImageURL != "" {
+			mediaSource["cover_image_url"] = req.CoverImageURL
+		} else {
+			mediaSource["cover_image_key_frame_time"] = 0
+		}
+	} else {
+		mediaSource = map[string]interface{}{
+			"source_type": "image_url",
+			"url":         req.MediaURL,
+		}
+	}
+
+	payload := map[string]interface{}{
+		"board_id":     req.BoardID,
+		"media_source": mediaSource,
+	}
+	if req.Title != "" {
+		payload["title"] = req.Title
+	}
+	if req.Description != "" {
+		payload["description"] = req.Description
+	}
+	if req.Link != "" {
+		payload["link"] = req.Link
+	}
+	if req.AltText != "" {
+		payload["alt_text"] = req.AltText
+	}
+
+	var pin struct {
+		ID string `json:"id"`
+	}
+	if err := s.api.do(ctx, "POST", "/v5/pins", accessToken, payload, &pin); err != nil {
+		return "", "", fmt.Errorf("failed to create pin: %w", err)
+	}
+	if pin.ID == "" {
+		return "", "", fmt.Errorf("pin response has no ID")
+	}
+
+	log.Printf("Pinterest pin created on board %s: %s", req.BoardID, pin.ID)
+	return pin.ID, PinterestPinURL(pin.ID), nil
+}
+
+// ListBoards returns the boards of the account, following the bookmarks of every page
+func (s *PinterestPostService) ListBoards(ctx context.Context, accessToken string) ([]PinterestBoard, error) {
+	params := url.Values{}
+	params.Set("page_size", fmt.Sprint(pinterestBoardsPerRequest))
+
+	boards := []PinterestBoard{}
+	for {
+		var boardsResp struct {
+			Items    []PinterestBoard `json:"items"`
+			Bookmark string           `json:"bookmark"`
+		}
+		if err := s.api.get(ctx, "/v5/boards", accessToken, params, &boardsResp); err != nil {
+			return nil, fmt.Errorf("failed to list boards: %w", err)
+		}
+		boards = append(boards, boardsResp.Items...)
+
+		if boardsResp.Bookmark == "" {
+			return boards, nil
+		}
+		params.Set("bookmark", boardsResp.Bookmark)
+	}
+}
+
+// CreateBoard creates a board with the given privacy, PUBLIC or SECRET
+func (s *PinterestPostService) CreateBoard(ctx context.Context, accessToken, name, description, privacy string) (*PinterestBoard, error) {
+	payload := map[string]string{
+		"name":    name,
+		"privacy": privacy,
+	}
+	if description != "" {
+		payload["description"] = description
+	}
+
+	var board PinterestBoard
+	if err := s.api.do(ctx, "POST", "/v5/boards", accessToken, payload, &board); err != nil {
+		return nil, fmt.Errorf("failed to create board: %w", err)
+	}
+	if board.ID == "" {
+		return nil, fmt.Errorf("board response has no ID")
+	}
+
+	log.Printf("Pinterest board created: %s (ID: %s)", board.Name, board.ID)
+	return &board, nil
+}
+
+// PinterestPinURL returns the URL of a pin
+func PinterestPinURL(pinID string) string {
+	return "https://www.pinterest.com/pin/" + pinID + "/"
+}
@@ -0,0 +1,237 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// blueskyLanguagePattern matches a BCP 47 language tag, like en or pt-BR
+var blueskyLanguagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// BlueskyPlatformService implements PlatformService for Bluesky
+// Accounts are connected with an app password (see CreateSession); every call goes to the PDS
+// of the account, which is passed in the context with platformapi.WithInstance
+type BlueskyPlatformService struct {
+	authService  *services.BlueskyAuthService
+	mediaService *services.BlueskyMediaService
+	postService  *services.BlueskyPostService
+}
+
+// NewBlueskyPlatformService creates a new Bluesky platform service
+func NewBlueskyPlatformService(cfg config.BlueskyConfig) *BlueskyPlatformService {
+	mediaService := services.NewBlueskyMediaService()
+
+	return &BlueskyPlatformService{
+		authService:  services.NewBlueskyAuthService(cfg.PDSURL, cfg.AllowPrivatePDS),
+		mediaService: mediaService,
+		postService:  services.NewBlueskyPostService(mediaService),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *BlueskyPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformBluesky
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+// App passwords have no scopes
+func (s *BlueskyPlatformService) GetRequiredScopes() []string {
+	return nil
+}
+
+// Capabilities describes what can be published to Bluesky
+func (s *BlueskyPlatformService) Capabilities() Capabilities {
+	return blueskyCapabilities
+}
+
+// ValidateSettings checks Bluesky post settings against the schema
+func (s *BlueskyPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformBluesky, blueskyCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if languages := validated.String("languages"); languages != "" {
+		codes := blueskyLanguages(languages)
+		if len(codes) > 3 {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "languages", Message: "at most 3 languages can be given"})
+		}
+		for _, code := range codes {
+			if !blueskyLanguagePattern.MatchString(code) {
+				fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "languages", Message: fmt.Sprintf("%q is not a language code, like en or pt-BR", code)})
+				break
+			}
+		}
+	}
+	if replyTo := validated.String("reply_to"); replyTo != "" {
+		if _, _, ok := services.ParseBlueskyPostReference(replyTo); !ok {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "reply_to", Message: "must be an at:// URI or a bsky.app post URL"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformBluesky, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// blueskyLanguages splits a comma-separated list of language codes
+func blueskyLanguages(languages string) []string {
+	var codes []string
+	for _, code := range strings.Split(languages, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// GenerateAuthURL is not supported: Bluesky accounts are connected with an app password, see CreateSession
+func (s *BlueskyPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("Bluesky accounts are connected with an app password")
+}
+
+// ExchangeCodeForTokens is not supported: Bluesky accounts are connected with an app password, see CreateSession
+func (s *BlueskyPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	return nil, fmt.Errorf("Bluesky accounts are connected with an app password")
+}
+
+// CreateSession logs in with a handle or email and an app password
+// The returned InstanceURL is the PDS of the account, which later calls have to go to
+func (s *BlueskyPlatformService) CreateSession(ctx context.Context, identifier, password string) (*TokenResponse, error) {
+	session, err := s.authService.CreateSession(ctx, identifier, password)
+	if err != nil {
+		return nil, err
+	}
+	return blueskyTokenResponse(session), nil
+}
+
+// RefreshAccessToken exchanges a refresh token for a new session on the PDS of ctx
+func (s *BlueskyPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	session, err := s.authService.RefreshSession(ctx, s.pds(ctx), refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return blueskyTokenResponse(session), nil
+}
+
+// blueskyTokenResponse converts a session into the tokens stored for the account
+func blueskyTokenResponse(session *services.BlueskySession) *TokenResponse {
+	return &TokenResponse{
+		AccessToken:  session.AccessJwt,
+		RefreshToken: session.RefreshJwt,
+		ExpiresIn:    services.BlueskyTokenLifetime(session.AccessJwt),
+		TokenType:    "Bearer",
+		InstanceURL:  session.PDSURL,
+	}
+}
+
+// GetUserInfo retrieves the profile of the account on the PDS of ctx
+// The DID is the user ID: unlike the handle, it doesn't change
+func (s *BlueskyPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	pdsURL := s.pds(ctx)
+	session, err := s.authService.GetSession(ctx, pdsURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Bluesky session: %w", err)
+	}
+
+	profile, err := s.authService.GetProfile(ctx, pdsURL, accessToken, session.DID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Bluesky profile: %w", err)
+	}
+
+	displayName := profile.DisplayName
+	if displayName == "" {
+		displayName = profile.Handle
+	}
+
+	return &UserInfo{
+		PlatformUserID: profile.DID,
+		Username:       profile.Handle,
+		DisplayName:    displayName,
+		AvatarURL:      profile.Avatar,
+	}, nil
+}
+
+// UploadMedia uploads media to the PDS of ctx and returns the CID of the blob
+// Posts upload their media in CreatePost instead, since blobs are only kept if a record
+// references them
+func (s *BlueskyPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	media, err := s.mediaService.Upload(ctx, s.pds(ctx), accessToken, mediaURL)
+	if err != nil {
+		return "", err
+	}
+	return media.Blob.Ref.Link, nil
+}
+
+// CreatePost uploads the media of a post and publishes it to the account's repository
+func (s *BlueskyPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	pdsURL := s.pds(ctx)
+
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	post, err := s.createPost(ctx, pdsURL, accessToken, services.BlueskyPostRequest{
+		Text:      content.Text,
+		MediaURLs: mediaURLs,
+		AltText:   content.Settings.String("media_description"),
+		Languages: blueskyLanguages(content.Settings.String("languages")),
+		ReplyTo:   content.Settings.String("reply_to"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   post.URI,
+		Status:   "published",
+		ShareURL: services.BlueskyPostURL(post.URI),
+	}, nil
+}
+
+// createPost publishes a post to the repository of the account the token belongs to
+func (s *BlueskyPlatformService) createPost(ctx context.Context, pdsURL, accessToken string, req services.BlueskyPostRequest) (*services.BlueskyPost, error) {
+	session, err := s.authService.GetSession(ctx, pdsURL, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.postService.CreatePost(ctx, pdsURL, accessToken, session.DID, req)
+}
+
+// GetPostStatus retrieves the status of a post
+// Posts are published as soon as their record is created
+func (s *BlueskyPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	post, err := s.postService.GetPost(ctx, s.pds(ctx), accessToken, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          post.URI,
+		ShareURL:        services.BlueskyPostURL(post.URI),
+		ProgressPercent: 100,
+	}, nil
+}
+
+// pds returns the PDS of the account in ctx, or the configured PDS for accounts connected
+// before it was stored
+func (s *BlueskyPlatformService) pds(ctx context.Context) string {
+	if pdsURL := platformapi.InstanceFromContext(ctx); pdsURL != "" {
+		return pdsURL
+	}
+	return s.authService.PDSURL()
+}
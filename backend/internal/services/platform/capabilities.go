@@ -0,0 +1,388 @@
+package platform
+
+import (
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// tiktokCapabilities describes TikTok video and photo posts
+// https://developers.tiktok.com/doc/content-posting-api-reference-direct-post
+var tiktokCapabilities = Capabilities{
+	Platform:         models.PlatformTikTok,
+	DisplayName:      "TikTok",
+	MediaTypes:       []string{platformapi.MediaKindVideo, platformapi.MediaKindImage, platformapi.MediaKindCarousel},
+	RequiresMedia:    true,
+	MaxImages:        35,
+	MaxVideos:        1,
+	MaxMediaItems:    35,
+	CaptionMaxLength: 2200,
+	TitleMaxLength:   150,
+	AsyncPublishing:  true,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 15, WindowSeconds: 24 * 60 * 60}, // Per creator
+	Settings: []SettingField{
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Post title, defaults to the caption", MaxLength: 150},
+		{
+			Name:        "privacy_level",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can view the post; must be one of the options the creator has available",
+			Required:    true,
+			Options:     []string{"PUBLIC_TO_EVERYONE", "MUTUAL_FOLLOW_FRIENDS", "FOLLOWER_OF_CREATOR", "SELF_ONLY"},
+		},
+		{Name: "allow_comment", Type: platformapi.SettingTypeBool, Description: "Allow comments", Default: false},
+		{Name: "allow_duet", Type: platformapi.SettingTypeBool, Description: "Allow duets (videos only)", Default: false},
+		{Name: "allow_stitch", Type: platformapi.SettingTypeBool, Description: "Allow stitches (videos only)", Default: false},
+		{Name: "is_brand_content", Type: platformapi.SettingTypeBool, Description: "Promotes a third-party brand (paid partnership)", Default: false},
+		{Name: "is_brand_organic", Type: platformapi.SettingTypeBool, Description: "Promotes the creator's own brand", Default: false},
+		{Name: "auto_add_music", Type: platformapi.SettingTypeBool, Description: "Add recommended music to photo posts", Default: false},
+		{Name: "direct_post", Type: platformapi.SettingTypeBool, Description: "Publish directly instead of sending to the creator's inbox", Default: false},
+	},
+}
+
+// xCapabilities describes X posts with media
+// https://developer.x.com/en/docs/x-api/tweets/manage-tweets/api-reference/post-tweets
+// X has no fixed cap; its limits are tracked from the x-rate-limit-* headers instead
+var xCapabilities = Capabilities{
+	Platform:         models.PlatformX,
+	DisplayName:      "X",
+	MediaTypes:       []string{platformapi.MediaKindVideo, platformapi.MediaKindImage, platformapi.MediaKindCarousel},
+	RequiresMedia:    true,
+	MaxImages:        4,
+	MaxVideos:        1,
+	MaxMediaItems:    4,
+	CaptionMaxLength: 280,
+}
+
+// instagramCapabilities describes Instagram feed posts, reels and carousels
+// https://developers.facebook.com/docs/instagram-platform/content-publishing
+var instagramCapabilities = Capabilities{
+	Platform:         models.PlatformInstagram,
+	DisplayName:      "Instagram",
+	MediaTypes:       []string{platformapi.MediaKindVideo, platformapi.MediaKindImage, platformapi.MediaKindCarousel},
+	RequiresMedia:    true,
+	MaxImages:        10,
+	MaxVideos:        10,
+	MaxMediaItems:    10,
+	MixedMedia:       true,
+	CaptionMaxLength: 2200,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 100, WindowSeconds: 24 * 60 * 60}, // Carousels count as one post
+}
+
+// threadsCapabilities describes Threads text, image, video and carousel posts and replies
+// https://developers.facebook.com/docs/threads/posts
+// Replies have a separate limit of 1000 per 24 hours, which isn't tracked
+var threadsCapabilities = Capabilities{
+	Platform:         models.PlatformThreads,
+	DisplayName:      "Threads",
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        20,
+	MaxVideos:        20,
+	MaxMediaItems:    20,
+	MixedMedia:       true,
+	CaptionMaxLength: 500,
+	SupportsThreads:  true,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 250, WindowSeconds: 24 * 60 * 60}, // Carousels count as one post
+	Settings: []SettingField{
+		{Name: "reply_to_id", Type: platformapi.SettingTypeString, Description: "Publish as a reply to the Threads post with this ID"},
+		{
+			Name:        "reply_control",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can reply to the post",
+			Default:     "everyone",
+			Options:     []string{"everyone", "accounts_you_follow", "mentioned_only"},
+		},
+	},
+}
+
+// facebookCapabilities describes posts on Facebook Pages
+// https://developers.facebook.com/docs/pages-api/posts
+// Pages can post about 200 times an hour before Graph API rate limits set in, which aren't tracked
+var facebookCapabilities = Capabilities{
+	Platform:           models.PlatformFacebook,
+	DisplayName:        "Facebook",
+	MediaTypes:         []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:      false,
+	MaxImages:          10,
+	MaxVideos:          1,
+	MaxMediaItems:      10,
+	CaptionMaxLength:   63206,
+	TitleMaxLength:     255,
+	AsyncPublishing:    true,
+	SupportsScheduling: true,
+	Settings: []SettingField{
+		{Name: "page_id", Type: platformapi.SettingTypeString, Description: "ID of the connected page to post as; may be omitted when a single page is connected"},
+		{Name: "link", Type: platformapi.SettingTypeString, Description: "Share a link with a preview; only for posts without media"},
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Title of a video", MaxLength: 255},
+		{Name: "scheduled_publish_time", Type: platformapi.SettingTypeString, Description: "RFC 3339 time at which Facebook publishes the post, between 10 minutes and 30 days from now"},
+	},
+}
+
+// youtubeCapabilities describes YouTube Shorts: square or vertical videos of up to 3 minutes
+// https://developers.google.com/youtube/v3/docs/videos/insert
+// An upload costs 1600 of the 10,000 quota units a project gets a day by default, so the quota,
+// which all accounts of the project share, allows 6 uploads a day
+var youtubeCapabilities = Capabilities{
+	Platform:           models.PlatformYouTube,
+	DisplayName:        "YouTube",
+	MediaTypes:         []string{platformapi.MediaKindVideo},
+	RequiresMedia:      true,
+	MaxVideos:          1,
+	MaxMediaItems:      1,
+	CaptionMaxLength:   5000,
+	TitleMaxLength:     100,
+	AsyncPublishing:    true,
+	SupportsScheduling: true,
+	PublishLimit:       &platformapi.PublishLimit{Posts: 6, WindowSeconds: 24 * 60 * 60},
+	Settings: []SettingField{
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Video title, defaults to the first line of the caption", MaxLength: 100},
+		{Name: "tags", Type: platformapi.SettingTypeString, Description: "Comma-separated tags", MaxLength: 500},
+		{
+			Name:        "privacy_status",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can watch the video; videos uploaded by unverified API projects are always private",
+			Default:     "public",
+			Options:     []string{"public", "unlisted", "private"},
+		},
+		{Name: "made_for_kids", Type: platformapi.SettingTypeBool, Description: "Declare the video as made for kids, which turns off comments and personalized ads", Default: false},
+		{Name: "publish_at", Type: platformapi.SettingTypeString, Description: "RFC 3339 time at which the video becomes public; requires privacy_status private"},
+		{Name: "category_id", Type: platformapi.SettingTypeString, Description: "ID of the video category, 22 is People & Blogs", Default: "22"},
+	},
+}
+
+// linkedinCapabilities describes LinkedIn posts on member profiles and organization pages
+// https://learn.microsoft.com/en-us/linkedin/marketing/community-management/shares/posts-api
+var linkedinCapabilities = Capabilities{
+	Platform:         models.PlatformLinkedIn,
+	DisplayName:      "LinkedIn",
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        20,
+	MaxVideos:        1,
+	MaxMediaItems:    20,
+	CaptionMaxLength: 3000,
+	TitleMaxLength:   200,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 150, WindowSeconds: 24 * 60 * 60}, // Per member
+	Settings: []SettingField{
+		{
+			Name:        "visibility",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can see the post; organization posts are always public",
+			Default:     "PUBLIC",
+			Options:     []string{"PUBLIC", "CONNECTIONS"},
+		},
+		{Name: "organization_id", Type: platformapi.SettingTypeString, Description: "Post as this organization page instead of the member; the member must be one of its administrators"},
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Title of a single image or video", MaxLength: 200},
+		{Name: "article_url", Type: platformapi.SettingTypeString, Description: "Share a link as an article; an image, if any, becomes its thumbnail"},
+		{Name: "article_title", Type: platformapi.SettingTypeString, Description: "Title of the article, required with article_url", MaxLength: 400},
+		{Name: "article_description", Type: platformapi.SettingTypeString, Description: "Description of the article", MaxLength: 4086},
+	},
+}
+
+// mastodonCapabilities describes Mastodon statuses with the defaults of Mastodon 4
+// https://docs.joinmastodon.org/methods/statuses/#create
+// Instances can raise the character limit; the rate limits are also reported in X-RateLimit-* headers
+var mastodonCapabilities = Capabilities{
+	Platform:         models.PlatformMastodon,
+	DisplayName:      "Mastodon",
+	InstanceRequired: true,
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        4,
+	MaxVideos:        1,
+	MaxMediaItems:    4,
+	CaptionMaxLength: 500,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 300, WindowSeconds: 3 * 60 * 60}, // Per account
+	Settings: []SettingField{
+		{
+			Name:        "visibility",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can see the status: everyone, everyone outside public timelines, followers, or mentioned accounts",
+			Default:     "public",
+			Options:     []string{"public", "unlisted", "private", "direct"},
+		},
+		{Name: "spoiler_text", Type: platformapi.SettingTypeString, Description: "Content warning shown in place of the text until readers expand it", MaxLength: 500},
+		{Name: "sensitive", Type: platformapi.SettingTypeBool, Description: "Hide the media behind a sensitive content warning", Default: false},
+		{Name: "language", Type: platformapi.SettingTypeString, Description: "ISO 639 code of the language of the text"},
+		{Name: "media_description", Type: platformapi.SettingTypeString, Description: "Alt text of the media, for readers who can't see it", MaxLength: 1500},
+		{Name: "media_focus", Type: platformapi.SettingTypeString, Description: "Focal point of the media as \"x,y\" between -1.0 and 1.0, kept in view when previews are cropped"},
+	},
+}
+
+// blueskyCapabilities describes Bluesky posts
+// https://docs.bsky.app/docs/advanced-guides/posts
+// The character limit counts graphemes; the publish limit is the PDS's write limit of 1666 record
+// creations an hour, which are also reported in RateLimit-* headers
+var blueskyCapabilities = Capabilities{
+	Platform:         models.PlatformBluesky,
+	DisplayName:      "Bluesky",
+	PasswordLogin:    true,
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        4,
+	MaxVideos:        1,
+	MaxMediaItems:    4,
+	CaptionMaxLength: 300,
+	SupportsThreads:  true,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 1666, WindowSeconds: 60 * 60}, // Per account
+	Settings: []SettingField{
+		{Name: "reply_to", Type: platformapi.SettingTypeString, Description: "Publish as a reply to this post, given as an at:// URI or a bsky.app post URL"},
+		{Name: "languages", Type: platformapi.SettingTypeString, Description: "Comma-separated codes of up to 3 languages of the text, like en,de"},
+		{Name: "media_description", Type: platformapi.SettingTypeString, Description: "Alt text of the media, for readers who can't see it", MaxLength: 2000},
+	},
+}
+
+// pinterestCapabilities describes image and video pins
+// https://developers.pinterest.com/docs/api/v5/pins-create
+// Every pin is saved to a board the account owns, which the board_id setting chooses
+var pinterestCapabilities = Capabilities{
+	Platform:         models.PlatformPinterest,
+	DisplayName:      "Pinterest",
+	MediaTypes:       []string{platformapi.MediaKindImage, platformapi.MediaKindVideo},
+	RequiresMedia:    true,
+	MaxImages:        1,
+	MaxVideos:        1,
+	MaxMediaItems:    1,
+	CaptionMaxLength: 500,
+	TitleMaxLength:   100,
+	Settings: []SettingField{
+		{Name: "board_id", Type: platformapi.SettingTypeString, Description: "ID of the board to save the pin to", Required: true},
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Title of the pin", MaxLength: 100},
+		{Name: "link", Type: platformapi.SettingTypeString, Description: "Destination link the pin opens, like a product page", MaxLength: 2048},
+		{Name: "alt_text", Type: platformapi.SettingTypeString, Description: "Alt text of the image or video, for readers who can't see it", MaxLength: 500},
+		{Name: "cover_image_url", Type: platformapi.SettingTypeString, Description: "Cover image of a video pin; the first frame is used without one"},
+	},
+}
+
+// redditCapabilities describes text, link, image and video posts to a subreddit
+// https://www.reddit.com/dev/api/#POST_api_submit
+// The subreddit setting chooses where to post; the post is a link post when the url setting is
+// set, and a text post without media. The text is the body of text posts and the first comment
+// of other posts
+var redditCapabilities = Capabilities{
+	Platform:         models.PlatformReddit,
+	DisplayName:      "Reddit",
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo},
+	RequiresMedia:    false,
+	MaxImages:        1,
+	MaxVideos:        1,
+	MaxMediaItems:    1,
+	CaptionMaxLength: 40000,
+	TitleMaxLength:   300,
+	Settings: []SettingField{
+		{Name: "subreddit", Type: platformapi.SettingTypeString, Description: "Subreddit to post to, like r/golang", Required: true, MaxLength: 24},
+		{Name: "title", Type: platformapi.SettingTypeString, Description: "Title of the post", Required: true, MaxLength: 300},
+		{Name: "url", Type: platformapi.SettingTypeString, Description: "Link to submit, which makes the post a link post", MaxLength: 2048},
+		{Name: "flair_id", Type: platformapi.SettingTypeString, Description: "ID of one of the subreddit's post flairs", MaxLength: 64},
+		{Name: "flair_text", Type: platformapi.SettingTypeString, Description: "Text of the flair, if the flair's text can be edited", MaxLength: 64},
+		{Name: "nsfw", Type: platformapi.SettingTypeBool, Description: "Mark the post as NSFW", Default: false},
+		{Name: "spoiler", Type: platformapi.SettingTypeBool, Description: "Mark the post as a spoiler", Default: false},
+		{Name: "poster_image_url", Type: platformapi.SettingTypeString, Description: "Thumbnail image of a video post, required for videos"},
+	},
+}
+
+// telegramCapabilities describes messages a bot sends to a channel or group
+// https://core.telegram.org/bots/api#sendmessage
+// Media captions are limited to 1024 characters; longer captions are sent as a message of their
+// own after the media. Bots can delete their messages for 48 hours after sending them
+var telegramCapabilities = Capabilities{
+	Platform:         models.PlatformTelegram,
+	DisplayName:      "Telegram",
+	PasswordLogin:    true,
+	ConnectOnly:      true,
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        10,
+	MaxVideos:        10,
+	MaxMediaItems:    10,
+	MixedMedia:       true,
+	CaptionMaxLength: 4096,
+	SupportsDeletion: true,
+	Settings: []SettingField{
+		{Name: "disable_notification", Type: platformapi.SettingTypeBool, Description: "Send the message silently, without a notification sound", Default: false},
+		{Name: "protect_content", Type: platformapi.SettingTypeBool, Description: "Keep the message from being forwarded and saved", Default: false},
+		{Name: "disable_link_preview", Type: platformapi.SettingTypeBool, Description: "Don't show a preview of the first link of a text message", Default: false},
+	},
+}
+
+// discordCapabilities describes messages sent with a channel's incoming webhook
+// https://discord.com/developers/docs/resources/webhook#execute-webhook
+// Media is uploaded as attachments, which are limited to 10 MB each without server boosts
+var discordCapabilities = Capabilities{
+	Platform:         models.PlatformDiscord,
+	DisplayName:      "Discord",
+	WebhookLogin:     true,
+	ConnectOnly:      true,
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        10,
+	MaxVideos:        10,
+	MaxMediaItems:    10,
+	MixedMedia:       true,
+	CaptionMaxLength: 2000,
+	SupportsDeletion: true,
+	Settings: []SettingField{
+		{Name: "username", Type: platformapi.SettingTypeString, Description: "Name the message is sent as, instead of the webhook's name", MaxLength: 80},
+		{Name: "avatar_url", Type: platformapi.SettingTypeString, Description: "Avatar the message is sent with, instead of the webhook's avatar"},
+		{Name: "suppress_embeds", Type: platformapi.SettingTypeBool, Description: "Don't show previews of the links in the message", Default: false},
+	},
+}
+
+// slackCapabilities describes messages sent with a channel's incoming webhook
+// https://api.slack.com/messaging/webhooks
+// Incoming webhooks can't upload files, so images are shown from their URL in image blocks, and
+// they return no message ID, so their messages can't be deleted
+var slackCapabilities = Capabilities{
+	Platform:         models.PlatformSlack,
+	DisplayName:      "Slack",
+	WebhookLogin:     true,
+	ConnectOnly:      true,
+	MediaTypes:       []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindCarousel},
+	RequiresMedia:    false,
+	MaxImages:        10,
+	MaxMediaItems:    10,
+	CaptionMaxLength: 3000, // Text of a section block
+	Settings: []SettingField{
+		{Name: "alt_text", Type: platformapi.SettingTypeString, Description: "Alt text of the images, for readers who can't see them", MaxLength: 2000},
+		{Name: "disable_link_preview", Type: platformapi.SettingTypeBool, Description: "Don't unfurl the links in the message", Default: false},
+	},
+}
+
+// webhookCapabilities describes posts sent to an endpoint of the user's own systems
+// The endpoint decides what it accepts, so no limits are known; it fetches media from its URL
+var webhookCapabilities = Capabilities{
+	Platform:      models.PlatformWebhook,
+	DisplayName:   "Webhook",
+	PasswordLogin: true,
+	ConnectOnly:   true,
+	MediaTypes:    []string{platformapi.MediaKindText, platformapi.MediaKindImage, platformapi.MediaKindVideo, platformapi.MediaKindCarousel},
+	RequiresMedia: false,
+	MixedMedia:    true,
+}
+
+// builtinCapabilities lists the capabilities of every platform this backend can post to,
+// whether or not it is configured
+var builtinCapabilities = []Capabilities{
+	tiktokCapabilities,
+	xCapabilities,
+	instagramCapabilities,
+	threadsCapabilities,
+	facebookCapabilities,
+	youtubeCapabilities,
+	linkedinCapabilities,
+	mastodonCapabilities,
+	blueskyCapabilities,
+	pinterestCapabilities,
+	redditCapabilities,
+	telegramCapabilities,
+	discordCapabilities,
+	slackCapabilities,
+	webhookCapabilities,
+}
+
+// KnownCapabilities returns the capabilities of every built-in platform, including
+// platforms that are not registered because they are not configured
+func KnownCapabilities() []Capabilities {
+	result := make([]Capabilities, len(builtinCapabilities))
+	copy(result, builtinCapabilities)
+	return result
+}
@@ -0,0 +1,21 @@
+package platform
+
+import (
+	"time"
+)
+
+// credentialLifetime is the lifetime stored for credentials that don't expire, like bot tokens
+// and webhook URLs
+// They are verified again when they are "refreshed", which happens before a post once they are
+// about to expire, so a revoked credential is found before posting rather than by a failed post
+const credentialLifetime = 365 * 24 * time.Hour
+
+// credentialTokenResponse returns the tokens stored for a credential that doesn't expire
+// The credential is both the access and the refresh token, so that refreshing it returns it again
+func credentialTokenResponse(credential string) *TokenResponse {
+	return &TokenResponse{
+		AccessToken:  credential,
+		RefreshToken: credential,
+		ExpiresIn:    int(credentialLifetime.Seconds()),
+	}
+}
@@ -0,0 +1,162 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// DiscordPlatformService implements PlatformService for Discord channels
+// A channel is connected with one of its incoming webhooks (see ConnectWebhook); the webhook URL
+// is stored as the access token
+type DiscordPlatformService struct {
+	webhookService *services.DiscordWebhookService
+}
+
+// NewDiscordPlatformService creates a new Discord platform service
+func NewDiscordPlatformService(cfg config.DiscordConfig) *DiscordPlatformService {
+	return &DiscordPlatformService{
+		webhookService: services.NewDiscordWebhookService(cfg.WebhookBaseURL),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *DiscordPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformDiscord
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+// Webhooks have no scopes
+func (s *DiscordPlatformService) GetRequiredScopes() []string {
+	return nil
+}
+
+// Capabilities describes what can be published to Discord
+func (s *DiscordPlatformService) Capabilities() Capabilities {
+	return discordCapabilities
+}
+
+// ValidateSettings checks Discord message settings against the schema
+func (s *DiscordPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformDiscord, discordCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+	if avatarURL := validated.String("avatar_url"); avatarURL != "" && !isHTTPURL(avatarURL) {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformDiscord, Fields: []platformapi.FieldError{
+			{Field: "avatar_url", Message: "must be an http or https URL"},
+		}}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL is not supported: Discord channels are connected with a webhook, see ConnectWebhook
+func (s *DiscordPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("Discord channels are connected with a webhook")
+}
+
+// ExchangeCodeForTokens is not supported: Discord channels are connected with a webhook, see ConnectWebhook
+func (s *DiscordPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	return nil, fmt.Errorf("Discord channels are connected with a webhook")
+}
+
+// ConnectWebhook verifies a channel's incoming webhook and returns it as the credential
+func (s *DiscordPlatformService) ConnectWebhook(ctx context.Context, webhookURL string) (*TokenResponse, error) {
+	webhookURL, _, err := s.webhookService.NormalizeWebhookURL(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.webhookService.GetWebhook(ctx, webhookURL); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(webhookURL), nil
+}
+
+// RefreshAccessToken verifies that the webhook still exists
+// Webhooks don't expire, so the same credential is returned
+func (s *DiscordPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	if _, err := s.webhookService.GetWebhook(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(refreshToken), nil
+}
+
+// GetUserInfo retrieves the webhook
+// The webhook ID is the user ID: a channel can have several webhooks, each connected on its own
+func (s *DiscordPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	webhook, err := s.webhookService.GetWebhook(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Discord webhook: %w", err)
+	}
+
+	return &UserInfo{
+		PlatformUserID: webhook.ID,
+		Username:       webhook.Name,
+		DisplayName:    webhook.Name,
+		AvatarURL:      webhook.AvatarURL(),
+	}, nil
+}
+
+// UploadMedia is not applicable for Discord: media is uploaded with the message in CreatePost
+func (s *DiscordPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Discord")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost sends a message with the webhook, with the media as attachments
+func (s *DiscordPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	// The webhook names the server of the channel, which message links need
+	webhook, err := s.webhookService.GetWebhook(ctx, accessToken)
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	message, err := s.webhookService.Execute(ctx, accessToken, services.DiscordMessageRequest{
+		Content:        content.Text,
+		MediaURLs:      mediaURLs,
+		Username:       content.Settings.String("username"),
+		AvatarURL:      content.Settings.String("avatar_url"),
+		SuppressEmbeds: content.Settings.Bool("suppress_embeds"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   message.ID,
+		Status:   "published",
+		ShareURL: services.DiscordMessageURL(webhook.GuildID, message.ChannelID, message.ID),
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a message
+// Messages are published as soon as they are sent
+func (s *DiscordPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ProgressPercent: 100,
+	}, nil
+}
+
+// DeletePost deletes a message the webhook sent
+func (s *DiscordPlatformService) DeletePost(ctx context.Context, accessToken string, postID string) error {
+	return s.webhookService.DeleteMessage(ctx, accessToken, postID)
+}
@@ -0,0 +1,403 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Lifetimes of Facebook user tokens, in seconds
+const (
+	facebookShortLivedTokenLifetime = 60 * 60
+	facebookLongLivedTokenLifetime  = 60 * 24 * 60 * 60
+)
+
+// ErrFacebookPageNotAvailable is returned when connecting a page the user can't publish as
+var ErrFacebookPageNotAvailable = errors.New("facebook page not available")
+
+// FacebookPlatformService implements PlatformService for Facebook Pages
+// The connected account is a Facebook user; posts are published as one of the pages the user
+// chose to connect, with the page token stored for it
+type FacebookPlatformService struct {
+	authService *services.FacebookAuthService
+	postService *services.FacebookPostService
+	pages       *models.FacebookPageRepository
+	scopes      []string
+}
+
+// NewFacebookPlatformService creates a new Facebook platform service
+// pages stores the pages users connected and their page tokens
+func NewFacebookPlatformService(cfg config.FacebookConfig, pages *models.FacebookPageRepository) *FacebookPlatformService {
+	authService := services.NewFacebookAuthService(cfg.AppID, cfg.AppSecret, cfg.RedirectURI, cfg.Scopes, cfg.GraphVersion, cfg.AuthBaseURL, cfg.GraphBaseURL)
+
+	return &FacebookPlatformService{
+		authService: authService,
+		postService: services.NewFacebookPostService(authService),
+		pages:       pages,
+		scopes:      cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *FacebookPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformFacebook
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *FacebookPlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to Facebook Pages
+func (s *FacebookPlatformService) Capabilities() Capabilities {
+	return facebookCapabilities
+}
+
+// ValidateSettings checks Facebook post settings against the schema
+func (s *FacebookPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformFacebook, facebookCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if pageID := validated.String("page_id"); pageID != "" && strings.Trim(pageID, "0123456789") != "" {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "page_id", Message: "must be the numeric ID of a Facebook page"})
+	}
+	if link := validated.String("link"); link != "" {
+		if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "link", Message: "must be an http or https URL"})
+		}
+	}
+	if scheduled := validated.String("scheduled_publish_time"); scheduled != "" {
+		if at, err := time.Parse(time.RFC3339, scheduled); err != nil {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "scheduled_publish_time", Message: "must be an RFC 3339 time"})
+		} else if delay := time.Until(at); delay < services.FacebookMinScheduleDelay || delay > services.FacebookMaxScheduleDelay {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "scheduled_publish_time", Message: "must be between 10 minutes and 30 days from now"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformFacebook, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL generates the Facebook Login URL
+func (s *FacebookPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // Confidential clients authenticate with the app secret instead of PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for a user token
+// The short-lived token is exchanged for a long-lived token right away, so that the page
+// tokens obtained with it don't expire
+func (s *FacebookPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	longLivedResp, err := s.authService.ExchangeLongLivedToken(ctx, tokenResp.AccessToken)
+	if err != nil {
+		log.Printf("Warning: Failed to get long-lived Facebook token, using short-lived: %v", err)
+		expiresIn := tokenResp.ExpiresIn
+		if expiresIn == 0 {
+			expiresIn = facebookShortLivedTokenLifetime
+		}
+		return s.tokenResponse(tokenResp.AccessToken, expiresIn), nil
+	}
+	return s.tokenResponse(longLivedResp.AccessToken, longLivedResp.ExpiresIn), nil
+}
+
+// RefreshAccessToken refreshes a long-lived Facebook user token
+// Facebook has no refresh token: a long-lived token is exchanged for a new one, which is
+// valid for another 60 days
+func (s *FacebookPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	refreshed, err := s.authService.ExchangeLongLivedToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh Facebook token: %w", err)
+	}
+	return s.tokenResponse(refreshed.AccessToken, refreshed.ExpiresIn), nil
+}
+
+// tokenResponse converts a Facebook user token into the tokens stored for the account
+func (s *FacebookPlatformService) tokenResponse(accessToken string, expiresIn int) *TokenResponse {
+	if expiresIn == 0 {
+		expiresIn = facebookLongLivedTokenLifetime
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: accessToken, // Facebook exchanges the token itself for a new one
+		ExpiresIn:    expiresIn,
+		TokenType:    "Bearer",
+		Scope:        strings.Join(s.scopes, ","),
+	}
+}
+
+// GetUserInfo retrieves the Facebook user's profile
+// The pages to post as are chosen after connecting, with ConnectPages
+func (s *FacebookPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Facebook user info: %w", err)
+	}
+
+	return &UserInfo{
+		PlatformUserID: userInfo.ID,
+		Username:       userInfo.Name, // Facebook no longer exposes usernames
+		DisplayName:    userInfo.Name,
+		AvatarURL:      userInfo.Picture.Data.URL,
+		Email:          "", // Not requested, the Pages permissions don't need it
+	}, nil
+}
+
+// UploadMedia is not applicable for Facebook: Facebook downloads the media of a post from its
+// URLs when the post is created
+func (s *FacebookPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Facebook")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost publishes a post as a connected page, or schedules it with scheduled_publish_time
+// Videos are processed by Facebook after they are created, which GetPostStatus polls
+func (s *FacebookPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	// Settings are validated when the post is created; check again in case of direct callers
+	settings, err := s.ValidateSettings(content.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	req := services.FacebookPostRequest{
+		Text:  content.Text,
+		Link:  settings.String("link"),
+		Title: settings.String("title"),
+	}
+	for _, mediaURL := range mediaURLs {
+		req.Media = append(req.Media, services.MediaItem{
+			URL:     mediaURL,
+			IsVideo: !services.IsImageMedia(mediaURL, content.MediaKinds),
+		})
+	}
+	if scheduled := settings.String("scheduled_publish_time"); scheduled != "" {
+		req.ScheduledPublishTime, _ = time.Parse(time.RFC3339, scheduled)
+	}
+
+	post, err := s.createPost(ctx, accessToken, settings.String("page_id"), req)
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	status := "published"
+	if post.Processing {
+		status = string(models.PostStatusProcessing)
+	}
+	return &PostResponse{
+		PostID:   post.ID,
+		Status:   status,
+		ShareURL: post.ShareURL,
+	}, nil
+}
+
+// createPost publishes a post as the page with the given ID, or the only connected page
+func (s *FacebookPlatformService) createPost(ctx context.Context, userToken, pageID string, req services.FacebookPostRequest) (*services.FacebookPost, error) {
+	page, err := s.connectedPage(ctx, userToken, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ScheduledPublishTime.IsZero() {
+		log.Printf("Posting to Facebook page: %s (ID: %s)", page.Name, page.PageID)
+	} else {
+		log.Printf("Scheduling Facebook post on page %s (ID: %s) for %s", page.Name, page.PageID, req.ScheduledPublishTime.Format(time.RFC3339))
+	}
+	return s.postService.CreatePost(ctx, page.AccessToken, page.PageID, req)
+}
+
+// connectedPage finds the connected page with the given ID among the pages connected with the
+// user token; without an ID, the only connected page is used
+func (s *FacebookPlatformService) connectedPage(ctx context.Context, userToken, pageID string) (*models.FacebookPage, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, userToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Facebook user info: %w", err)
+	}
+
+	pages, err := s.pages.GetByFacebookUserID(userInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageID == "" {
+		switch len(pages) {
+		case 0:
+			return nil, fmt.Errorf("no Facebook page is connected; choose the pages to post as first")
+		case 1:
+			return pages[0], nil
+		default:
+			return nil, fmt.Errorf("several Facebook pages are connected; set page_id to the page to post as")
+		}
+	}
+
+	for _, page := range pages {
+		if page.PageID == pageID {
+			return page, nil
+		}
+	}
+	return nil, fmt.Errorf("facebook page %s is not connected", pageID)
+}
+
+// GetPostStatus checks how Facebook's processing of an uploaded video is going
+// Only videos are processed asynchronously; other posts are published when they are created
+func (s *FacebookPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	pageID, videoID, err := services.SplitFacebookPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.connectedPage(ctx, accessToken, pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	videoStatus, err := s.postService.GetVideoStatus(ctx, page.AccessToken, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PostStatusResponse{
+		Status: string(models.PostStatusProcessing),
+		PostID: postID,
+	}
+	switch videoStatus.VideoStatus {
+	case services.FacebookVideoReady:
+		resp.Status = string(models.PostStatusPublished)
+		resp.ShareURL = services.FacebookVideoURL(pageID, videoID)
+		resp.ProgressPercent = 100
+	case services.FacebookVideoError, services.FacebookVideoExpired:
+		resp.Status = string(models.PostStatusFailed)
+		resp.FailReason = videoStatus.ErrorMessage
+		if resp.FailReason == "" {
+			resp.FailReason = "video " + videoStatus.VideoStatus
+		}
+		resp.ErrorCode = platformapi.ErrorCodeMediaRejected
+	}
+	return resp, nil
+}
+
+// FacebookPageOption is a page the user can connect
+type FacebookPageOption struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	PictureURL string `json:"picture_url"`
+	CanPublish bool   `json:"can_publish"` // Whether the user's role on the page allows publishing
+	Connected  bool   `json:"connected"`
+}
+
+// ListPages returns the pages the user granted access to, and whether each one is connected
+func (s *FacebookPlatformService) ListPages(ctx context.Context, userID int64, userToken string) ([]FacebookPageOption, error) {
+	managedPages, err := s.authService.GetPages(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
+
+	connectedPages, err := s.pages.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	connected := make(map[string]bool, len(connectedPages))
+	for _, page := range connectedPages {
+		connected[page.PageID] = true
+	}
+
+	options := make([]FacebookPageOption, 0, len(managedPages))
+	for _, page := range managedPages {
+		options = append(options, FacebookPageOption{
+			ID:         page.ID,
+			Name:       page.Name,
+			Category:   page.Category,
+			PictureURL: page.Picture.Data.URL,
+			CanPublish: page.CanPublish(),
+			Connected:  connected[page.ID],
+		})
+	}
+	return options, nil
+}
+
+// ConnectPages replaces the pages the user posts as with the pages with the given IDs, storing
+// their page tokens; no IDs disconnects every page
+func (s *FacebookPlatformService) ConnectPages(ctx context.Context, userID int64, userToken string, pageIDs []string) ([]*models.FacebookPage, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, userToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Facebook user info: %w", err)
+	}
+
+	managedPages, err := s.authService.GetPages(ctx, userToken)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]services.FacebookManagedPage, len(managedPages))
+	for _, page := range managedPages {
+		byID[page.ID] = page
+	}
+
+	pages := make([]*models.FacebookPage, 0, len(pageIDs))
+	seen := make(map[string]bool, len(pageIDs))
+	for _, pageID := range pageIDs {
+		if seen[pageID] {
+			continue
+		}
+		seen[pageID] = true
+
+		page, ok := byID[pageID]
+		if !ok {
+			return nil, fmt.Errorf("%w: page %s was not granted to the app", ErrFacebookPageNotAvailable, pageID)
+		}
+		if !page.CanPublish() || page.AccessToken == "" {
+			return nil, fmt.Errorf("%w: the user's role on page %s doesn't allow publishing", ErrFacebookPageNotAvailable, pageID)
+		}
+		pages = append(pages, &models.FacebookPage{
+			FacebookUserID: userInfo.ID,
+			PageID:         page.ID,
+			Name:           page.Name,
+			Category:       page.Category,
+			PictureURL:     page.Picture.Data.URL,
+			AccessToken:    page.AccessToken,
+		})
+	}
+
+	if err := s.pages.ReplaceForUser(userID, pages); err != nil {
+		return nil, err
+	}
+
+	log.Printf("User %d connected %d Facebook pages", userID, len(pages))
+	return pages, nil
+}
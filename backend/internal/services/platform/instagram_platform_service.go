@@ -1,10 +1,14 @@
 package platform
 
 import (
+	"context"
 	"fmt"
+	"log"
 
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
 	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 // InstagramPlatformService implements PlatformService for Instagram
@@ -15,9 +19,13 @@ type InstagramPlatformService struct {
 }
 
 // NewInstagramPlatformService creates a new Instagram platform service
-func NewInstagramPlatformService(appID, appSecret, redirectURI string) *InstagramPlatformService {
-	authService := services.NewInstagramAuthService(appID, appSecret, redirectURI)
-	mediaService := services.NewInstagramMediaService()
+func NewInstagramPlatformService(cfg config.InstagramConfig) *InstagramPlatformService {
+	authService := services.NewInstagramAuthService(cfg.AppID, cfg.AppSecret, cfg.RedirectURI, services.InstagramEndpoints{
+		AuthBaseURL:  cfg.AuthBaseURL,
+		APIBaseURL:   cfg.APIBaseURL,
+		GraphBaseURL: cfg.GraphBaseURL,
+	})
+	mediaService := services.NewInstagramMediaService(cfg.GraphBaseURL)
 	postService := services.NewInstagramPostService(authService, mediaService)
 
 	return &InstagramPlatformService{
@@ -43,6 +51,16 @@ func (s *InstagramPlatformService) GetRequiredScopes() []string {
 	}
 }
 
+// Capabilities describes what can be published to Instagram
+func (s *InstagramPlatformService) Capabilities() Capabilities {
+	return instagramCapabilities
+}
+
+// ValidateSettings checks Instagram post settings; Instagram has no platform-specific settings yet
+func (s *InstagramPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformInstagram, instagramCapabilities.Settings, settings)
+}
+
 // GenerateAuthURL generates the Instagram OAuth authorization URL (via Facebook)
 func (s *InstagramPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
 	authResp, err := s.authService.GenerateAuthURL()
@@ -59,18 +77,18 @@ func (s *InstagramPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
 
 // ExchangeCodeForTokens exchanges an authorization code for tokens
 // This automatically exchanges the short-lived token for a long-lived token
-func (s *InstagramPlatformService) ExchangeCodeForTokens(code string, additionalParams map[string]string) (*TokenResponse, error) {
+func (s *InstagramPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
 	// Step 1: Exchange code for short-lived token (valid for 1 hour)
-	tokenResp, err := s.authService.ExchangeCodeForToken(code)
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
 	// Step 2: Exchange short-lived token for long-lived token (valid for 60 days)
-	longLivedResp, err := s.authService.ExchangeLongLivedToken(tokenResp.AccessToken)
+	longLivedResp, err := s.authService.ExchangeLongLivedToken(ctx, tokenResp.AccessToken)
 	if err != nil {
 		// If long-lived exchange fails, fall back to short-lived token
-		fmt.Printf("WARNING: Failed to get long-lived token, using short-lived: %v\n", err)
+		log.Printf("Warning: Failed to get long-lived token, using short-lived: %v", err)
 		expiresIn := tokenResp.ExpiresIn
 		if expiresIn == 0 {
 			expiresIn = 3600 // 1 hour for short-lived token
@@ -102,9 +120,9 @@ func (s *InstagramPlatformService) ExchangeCodeForTokens(code string, additional
 // RefreshAccessToken refreshes an Instagram long-lived access token
 // Instagram long-lived tokens can be refreshed as long as they haven't expired
 // The refreshed token will be valid for another 60 days
-func (s *InstagramPlatformService) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
+func (s *InstagramPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
 	// Refresh the long-lived token
-	refreshedToken, err := s.authService.RefreshLongLivedToken(refreshToken)
+	refreshedToken, err := s.authService.RefreshLongLivedToken(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh Instagram token: %w", err)
 	}
@@ -124,8 +142,8 @@ func (s *InstagramPlatformService) RefreshAccessToken(refreshToken string) (*Tok
 }
 
 // GetUserInfo retrieves user information from Instagram
-func (s *InstagramPlatformService) GetUserInfo(accessToken string) (*UserInfo, error) {
-	userInfo, err := s.authService.GetInstagramUserInfo(accessToken)
+func (s *InstagramPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	userInfo, err := s.authService.GetInstagramUserInfo(ctx, accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Instagram user info: %w", err)
 	}
@@ -140,15 +158,16 @@ func (s *InstagramPlatformService) GetUserInfo(accessToken string) (*UserInfo, e
 }
 
 // UploadMedia uploads media to Instagram (creates container and waits for processing)
-func (s *InstagramPlatformService) UploadMedia(accessToken string, mediaURL string) (string, error) {
+func (s *InstagramPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
 	// Get Instagram user info
-	userInfo, err := s.authService.GetInstagramUserInfo(accessToken)
+	userInfo, err := s.authService.GetInstagramUserInfo(ctx, accessToken)
 	if err != nil {
 		return "", fmt.Errorf("failed to get Instagram user info: %w", err)
 	}
 
 	// Create media container (Step 1)
 	containerID, err := s.mediaService.CreateMediaContainer(
+		ctx,
 		accessToken,
 		userInfo.ID,
 		mediaURL,
@@ -160,7 +179,7 @@ func (s *InstagramPlatformService) UploadMedia(accessToken string, mediaURL stri
 	}
 
 	// Wait for processing (Step 2)
-	success, err := s.mediaService.WaitForMediaProcessing(accessToken, containerID, 300)
+	success, err := s.mediaService.WaitForMediaProcessing(ctx, accessToken, containerID, 300)
 	if err != nil {
 		return "", fmt.Errorf("media processing failed: %w", err)
 	}
@@ -175,7 +194,7 @@ func (s *InstagramPlatformService) UploadMedia(accessToken string, mediaURL stri
 // CreatePost creates and publishes a post to Instagram
 // Automatically detects if media is a photo or video and uses appropriate method
 // Supports carousel posts with multiple images/videos
-func (s *InstagramPlatformService) CreatePost(accessToken string, content PostContent) (*PostResponse, error) {
+func (s *InstagramPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
 	var mediaID, permalink string
 	var err error
 
@@ -186,10 +205,10 @@ func (s *InstagramPlatformService) CreatePost(accessToken string, content PostCo
 		for _, url := range content.MediaURLs {
 			mediaItems = append(mediaItems, services.MediaItem{
 				URL:     url,
-				IsVideo: !services.IsImageURL(url),
+				IsVideo: !services.IsImageMedia(url, content.MediaKinds),
 			})
 		}
-		mediaID, permalink, err = s.postService.CreateCarouselPost(accessToken, mediaItems, content.Text)
+		mediaID, permalink, err = s.postService.CreateCarouselPost(ctx, accessToken, mediaItems, content.Text)
 	} else {
 		// Single media post
 		mediaURL := content.MediaURL
@@ -197,13 +216,13 @@ func (s *InstagramPlatformService) CreatePost(accessToken string, content PostCo
 			mediaURL = content.MediaURLs[0]
 		}
 
-		// Detect media type from URL
-		if services.IsImageURL(mediaURL) {
+		// Use the kind probed from the media's bytes, or else its URL
+		if services.IsImageMedia(mediaURL, content.MediaKinds) {
 			// Photo post
-			mediaID, permalink, err = s.postService.CreatePhotoPost(accessToken, mediaURL, content.Text)
+			mediaID, permalink, err = s.postService.CreatePhotoPost(ctx, accessToken, mediaURL, content.Text)
 		} else {
 			// Video post (Reel)
-			mediaID, permalink, err = s.postService.CreatePost(accessToken, mediaURL, content.Text)
+			mediaID, permalink, err = s.postService.CreatePost(ctx, accessToken, mediaURL, content.Text)
 		}
 	}
 
@@ -224,7 +243,7 @@ func (s *InstagramPlatformService) CreatePost(accessToken string, content PostCo
 }
 
 // GetPostStatus retrieves the status of a post
-func (s *InstagramPlatformService) GetPostStatus(accessToken string, postID string) (*PostStatusResponse, error) {
+func (s *InstagramPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
 	// Instagram doesn't provide detailed status after publishing
 	// Once published, the post is live
 	return &PostStatusResponse{
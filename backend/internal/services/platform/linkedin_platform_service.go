@@ -0,0 +1,215 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// linkedinTokenLifetime is how long LinkedIn member access tokens are valid, in seconds
+const linkedinTokenLifetime = 60 * 24 * 60 * 60
+
+// LinkedInPlatformService implements PlatformService for LinkedIn member profiles and organization pages
+type LinkedInPlatformService struct {
+	authService  *services.LinkedInAuthService
+	mediaService *services.LinkedInMediaService
+	postService  *services.LinkedInPostService
+	scopes       []string
+}
+
+// NewLinkedInPlatformService creates a new LinkedIn platform service
+func NewLinkedInPlatformService(cfg config.LinkedInConfig) *LinkedInPlatformService {
+	authService := services.NewLinkedInAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, cfg.Scopes, cfg.AuthBaseURL, cfg.APIBaseURL)
+	mediaService := services.NewLinkedInMediaService(cfg.APIBaseURL, cfg.APIVersion)
+	postService := services.NewLinkedInPostService(authService, mediaService)
+
+	return &LinkedInPlatformService{
+		authService:  authService,
+		mediaService: mediaService,
+		postService:  postService,
+		scopes:       cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *LinkedInPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformLinkedIn
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *LinkedInPlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to LinkedIn
+func (s *LinkedInPlatformService) Capabilities() Capabilities {
+	return linkedinCapabilities
+}
+
+// ValidateSettings checks LinkedIn post settings against the schema
+// Organizations can only post publicly, and articles need a title
+func (s *LinkedInPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformLinkedIn, linkedinCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if organizationID := validated.String("organization_id"); organizationID != "" {
+		if _, err := services.LinkedInOrganizationURN(organizationID); err != nil {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "organization_id", Message: "must be a numeric organization ID or an urn:li:organization URN"})
+		} else if validated.String("visibility") != services.LinkedInVisibilityPublic {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "visibility", Message: "organization posts are always public"})
+		}
+	}
+	if validated.String("article_url") != "" && validated.String("article_title") == "" {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "article_title", Message: "is required with article_url"})
+	}
+	if validated.String("article_url") == "" && (validated.String("article_title") != "" || validated.String("article_description") != "") {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "article_url", Message: "is required with article_title and article_description"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformLinkedIn, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL generates the LinkedIn OAuth authorization URL
+func (s *LinkedInPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // LinkedIn's 3-legged flow doesn't use PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for tokens
+func (s *LinkedInPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return linkedinTokenResponse(tokenResp), nil
+}
+
+// RefreshAccessToken refreshes a LinkedIn access token
+// Only apps approved for programmatic refresh get refresh tokens; other members have to
+// reconnect when their token expires after 60 days
+func (s *LinkedInPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, platformapi.NewPlatformError(models.PlatformLinkedIn, platformapi.ErrorCodeAuthExpired, 0, "",
+			"LinkedIn did not issue a refresh token; the account has to be reconnected")
+	}
+
+	tokenResp, err := s.authService.RefreshAccessToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh LinkedIn token: %w", err)
+	}
+	return linkedinTokenResponse(tokenResp), nil
+}
+
+// linkedinTokenResponse converts a LinkedIn token response
+func linkedinTokenResponse(tokenResp *services.LinkedInTokenResponse) *TokenResponse {
+	expiresIn := tokenResp.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = linkedinTokenLifetime
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		ExpiresIn:    expiresIn,
+		TokenType:    "Bearer",
+		Scope:        tokenResp.Scope,
+	}
+}
+
+// GetUserInfo retrieves the member's profile from LinkedIn
+func (s *LinkedInPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LinkedIn user info: %w", err)
+	}
+
+	return &UserInfo{
+		PlatformUserID: userInfo.Sub,
+		Username:       userInfo.Name, // LinkedIn doesn't expose the member's vanity name without partner access
+		DisplayName:    userInfo.Name,
+		AvatarURL:      userInfo.Picture,
+		Email:          userInfo.Email,
+	}, nil
+}
+
+// UploadMedia uploads media owned by the member and returns its image or video URN
+// Posts upload their media in CreatePost instead, because it has to be owned by the post's
+// author, which can be an organization
+func (s *LinkedInPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to get LinkedIn user info: %w", err)
+	}
+
+	media, err := s.mediaService.Upload(ctx, accessToken, userInfo.PersonURN(), mediaURL, nil)
+	if err != nil {
+		return "", err
+	}
+	return media.URN, nil
+}
+
+// CreatePost uploads the media of a post and publishes it as the member or an organization
+// Text-only, article, single image, video and multi-image posts are supported
+func (s *LinkedInPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	postURN, shareURL, err := s.postService.CreatePost(ctx, accessToken, services.LinkedInPostRequest{
+		Text:               content.Text,
+		MediaURLs:          mediaURLs,
+		OrganizationID:     content.Settings.String("organization_id"),
+		Visibility:         content.Settings.String("visibility"),
+		Title:              content.Settings.String("title"),
+		ArticleURL:         content.Settings.String("article_url"),
+		ArticleTitle:       content.Settings.String("article_title"),
+		ArticleDescription: content.Settings.String("article_description"),
+	}, nil)
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   postURN,
+		Status:   "published",
+		ShareURL: shareURL,
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a post
+// LinkedIn posts are published as soon as they are created
+func (s *LinkedInPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ShareURL:        "https://www.linkedin.com/feed/update/" + postID,
+		ProgressPercent: 100,
+	}, nil
+}
+
+// ListOrganizations returns the organization pages the member can post as
+func (s *LinkedInPlatformService) ListOrganizations(ctx context.Context, accessToken string) ([]services.LinkedInOrganization, error) {
+	return s.postService.ListOrganizations(ctx, accessToken)
+}
@@ -0,0 +1,262 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// mastodonTokenLifetime is the expiry stored with Mastodon tokens, in seconds
+// Mastodon access tokens are valid until they are revoked, so they are never refreshed
+const mastodonTokenLifetime = 10 * 365 * 24 * 60 * 60
+
+// mastodonLanguagePattern matches an ISO 639-1 or 639-3 language code
+var mastodonLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// MastodonPlatformService implements PlatformService for Mastodon
+// Every call goes to the instance of the account, which is passed in the context with
+// platformapi.WithInstance
+type MastodonPlatformService struct {
+	authService  *services.MastodonAuthService
+	mediaService *services.MastodonMediaService
+	postService  *services.MastodonPostService
+	scopes       []string
+}
+
+// NewMastodonPlatformService creates a new Mastodon platform service
+// apps stores the OAuth app registered on each instance
+func NewMastodonPlatformService(cfg config.MastodonConfig, apps *models.MastodonAppRepository) *MastodonPlatformService {
+	authService := services.NewMastodonAuthService(cfg.ClientName, cfg.Website, cfg.RedirectURI, cfg.Scopes, cfg.AllowedInstances, cfg.AllowPrivateInstances, apps)
+	mediaService := services.NewMastodonMediaService()
+
+	return &MastodonPlatformService{
+		authService:  authService,
+		mediaService: mediaService,
+		postService:  services.NewMastodonPostService(mediaService),
+		scopes:       cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *MastodonPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformMastodon
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *MastodonPlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to Mastodon
+func (s *MastodonPlatformService) Capabilities() Capabilities {
+	return mastodonCapabilities
+}
+
+// ValidateSettings checks Mastodon status settings against the schema
+func (s *MastodonPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformMastodon, mastodonCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if language := validated.String("language"); language != "" && !mastodonLanguagePattern.MatchString(language) {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "language", Message: "must be a lowercase ISO 639 language code, like en"})
+	}
+	if focus := validated.String("media_focus"); focus != "" && !validMastodonFocus(focus) {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "media_focus", Message: `must be "x,y" with both between -1.0 and 1.0`})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformMastodon, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// validMastodonFocus reports whether focus is a focal point "x,y" with both coordinates in [-1, 1]
+func validMastodonFocus(focus string) bool {
+	x, y, ok := strings.Cut(focus, ",")
+	if !ok {
+		return false
+	}
+	for _, coordinate := range []string{x, y} {
+		value, err := strconv.ParseFloat(strings.TrimSpace(coordinate), 64)
+		if err != nil || value < -1 || value > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// GenerateAuthURL is not supported: the flow has to start on the user's instance, see
+// GenerateInstanceAuthURL
+func (s *MastodonPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("Mastodon login needs the user's instance")
+}
+
+// GenerateInstanceAuthURL generates the authorization URL of the instance the user entered,
+// registering the app on the instance first if needed
+func (s *MastodonPlatformService) GenerateInstanceAuthURL(ctx context.Context, instance string) (AuthURLResponse, error) {
+	instanceURL, err := s.authService.ResolveInstance(instance)
+	if err != nil {
+		return AuthURLResponse{}, err
+	}
+
+	authURL, state, err := s.authService.GenerateAuthURL(ctx, instanceURL)
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:         authURL,
+		State:       state,
+		InstanceURL: instanceURL,
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for a token on the instance of ctx
+func (s *MastodonPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	instanceURL, err := mastodonInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, instanceURL, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	return &TokenResponse{
+		AccessToken: tokenResp.AccessToken,
+		ExpiresIn:   mastodonTokenLifetime,
+		TokenType:   "Bearer",
+		Scope:       tokenResp.Scope,
+	}, nil
+}
+
+// RefreshAccessToken is not supported: Mastodon tokens stay valid until they are revoked, so
+// an account whose token stopped working has to be reconnected
+func (s *MastodonPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	return nil, platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeAuthExpired, 0, "",
+		"Mastodon tokens cannot be refreshed; the account has to be reconnected")
+}
+
+// GetUserInfo retrieves the account of the token on the instance of ctx
+// Account IDs are only unique within an instance, so the instance's host is part of the user ID
+func (s *MastodonPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	instanceURL, err := mastodonInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.authService.VerifyCredentials(ctx, instanceURL, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Mastodon account: %w", err)
+	}
+
+	parsed, err := url.Parse(instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance URL %q: %w", instanceURL, err)
+	}
+	host := parsed.Host
+
+	displayName := account.DisplayName
+	if displayName == "" {
+		displayName = account.Username
+	}
+
+	return &UserInfo{
+		PlatformUserID: account.ID + "@" + host,
+		Username:       account.Username + "@" + host,
+		DisplayName:    displayName,
+		AvatarURL:      account.Avatar,
+	}, nil
+}
+
+// UploadMedia uploads media to the instance of ctx and returns the media attachment ID
+// Posts upload their media in CreatePost instead, so the description and focus of the
+// post's settings can be applied
+func (s *MastodonPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	instanceURL, err := mastodonInstance(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.mediaService.Upload(ctx, instanceURL, accessToken, mediaURL, services.MastodonMediaOptions{})
+}
+
+// CreatePost uploads the media of a status and publishes it on the instance of ctx
+func (s *MastodonPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	instanceURL, err := mastodonInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	status, err := s.postService.CreateStatus(ctx, instanceURL, accessToken, services.MastodonStatusRequest{
+		Text:      content.Text,
+		MediaURLs: mediaURLs,
+		Media: services.MastodonMediaOptions{
+			Description: content.Settings.String("media_description"),
+			Focus:       content.Settings.String("media_focus"),
+		},
+		Visibility:  content.Settings.String("visibility"),
+		SpoilerText: content.Settings.String("spoiler_text"),
+		Sensitive:   content.Settings.Bool("sensitive"),
+		Language:    content.Settings.String("language"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   status.ID,
+		Status:   "published",
+		ShareURL: status.URL,
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a post
+// Statuses are published as soon as they are created
+func (s *MastodonPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	instanceURL, err := mastodonInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.postService.GetStatus(ctx, instanceURL, accessToken, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          status.ID,
+		ShareURL:        status.URL,
+		ProgressPercent: 100,
+	}, nil
+}
+
+// mastodonInstance returns the instance URL of ctx
+func mastodonInstance(ctx context.Context) (string, error) {
+	instanceURL := platformapi.InstanceFromContext(ctx)
+	if instanceURL == "" {
+		return "", platformapi.NewPlatformError(models.PlatformMastodon, platformapi.ErrorCodeAuthExpired, 0, "",
+			"the account's Mastodon instance is unknown; the account has to be reconnected")
+	}
+	return instanceURL, nil
+}
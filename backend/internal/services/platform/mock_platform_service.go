@@ -0,0 +1,366 @@
+package platform
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Caption keywords that make the mock platform misbehave on demand
+// They have to appear as separate words, e.g. "Launch day #mock-fail-processing"
+const (
+	MockKeywordFail           = "#mock-fail"            // CreatePost fails
+	MockKeywordFailProcessing = "#mock-fail-processing" // Processing fails after the delay
+	MockKeywordRateLimit      = "#mock-rate-limit"      // CreatePost fails as rate limited
+	MockKeywordInbox          = "#mock-inbox"           // Post is sent to the inbox instead of published
+	MockKeywordSlow           = "#mock-slow"            // Processing takes mockSlowFactor times longer
+)
+
+// MockFailUploadMarker makes the upload of a media file fail when its URL contains it
+const MockFailUploadMarker = "mock-fail-upload"
+
+const (
+	mockTokenLifetime   = 24 * time.Hour
+	mockCodeLifetime    = 5 * time.Minute
+	mockSlowFactor      = 4
+	mockRateLimitWindow = time.Minute
+	mockUploadSteps     = 5
+	mockAccessPrefix    = "mock-access-"
+	mockRefreshPrefix   = "mock-refresh-"
+	mockUserID          = "mock-user"
+	mockUsername        = "mock_creator"
+	mockDisplayName     = "Mock Creator"
+	mockImageDelayDiv   = 5 // Images are processed in a fifth of the video delay
+)
+
+// mockCapabilities describes the mock platform; it accepts anything the real platforms do
+var mockCapabilities = Capabilities{
+	Platform:         models.PlatformMock,
+	DisplayName:      "Mock",
+	MediaTypes:       []string{platformapi.MediaKindVideo, platformapi.MediaKindImage, platformapi.MediaKindCarousel},
+	RequiresMedia:    true,
+	MaxImages:        10,
+	MaxVideos:        1,
+	MaxMediaItems:    10,
+	MixedMedia:       true,
+	CaptionMaxLength: 2200,
+	AsyncPublishing:  true,
+	PublishLimit:     &platformapi.PublishLimit{Posts: 20, WindowSeconds: 60 * 60}, // Small enough to demo deferral
+	Settings: []SettingField{
+		{
+			Name:        "visibility",
+			Type:        platformapi.SettingTypeEnum,
+			Description: "Who can view the post",
+			Options:     []string{"public", "private"},
+			Default:     "public",
+		},
+	},
+}
+
+// mockPublish is a post the mock platform is processing
+type mockPublish struct {
+	createdAt  time.Time
+	duration   time.Duration
+	failReason string
+}
+
+// MockPlatformService implements PlatformService for the sandbox platform used in local
+// development and demos. Nothing leaves the process: the OAuth round-trip redirects straight
+// back to the callback, uploads and processing are simulated and state is kept in memory
+type MockPlatformService struct {
+	redirectURI     string
+	processingDelay time.Duration
+
+	mu    sync.Mutex
+	codes map[string]time.Time    // Authorization codes and when they expire
+	posts map[string]*mockPublish // Posts being processed keyed by publish ID
+}
+
+// NewMockPlatformService creates a new mock platform service
+func NewMockPlatformService(cfg config.MockConfig) *MockPlatformService {
+	return &MockPlatformService{
+		redirectURI:     cfg.RedirectURI,
+		processingDelay: cfg.ProcessingDelay,
+		codes:           make(map[string]time.Time),
+		posts:           make(map[string]*mockPublish),
+	}
+}
+
+// GetPlatformName returns the platform identifier
+func (s *MockPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformMock
+}
+
+// GetRequiredScopes returns the scopes the mock platform pretends to grant
+func (s *MockPlatformService) GetRequiredScopes() []string {
+	return []string{"post.write"}
+}
+
+// Capabilities describes what can be published to the mock platform
+func (s *MockPlatformService) Capabilities() Capabilities {
+	return mockCapabilities
+}
+
+// ValidateSettings checks mock post settings against the schema
+func (s *MockPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformMock, mockCapabilities.Settings, settings)
+}
+
+// GenerateAuthURL returns a URL that redirects straight to the callback with a fresh code,
+// as if the user had approved the app on the platform's consent page
+func (s *MockPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	state, err := generateRandomState()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate state: %w", err)
+	}
+	code, err := randomMockToken("")
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	s.mu.Lock()
+	for issued, expiresAt := range s.codes {
+		if time.Now().After(expiresAt) {
+			delete(s.codes, issued)
+		}
+	}
+	s.codes[code] = time.Now().Add(mockCodeLifetime)
+	s.mu.Unlock()
+
+	params := url.Values{}
+	params.Add("code", code)
+	params.Add("state", state)
+
+	return AuthURLResponse{
+		URL:   s.redirectURI + "?" + params.Encode(),
+		State: state,
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges a code issued by GenerateAuthURL for tokens
+// Each code can be used once
+func (s *MockPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	s.mu.Lock()
+	expiresAt, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("failed to exchange code for tokens: invalid or expired authorization code")
+	}
+	return s.issueTokens()
+}
+
+// RefreshAccessToken issues new tokens for a mock refresh token
+func (s *MockPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	if !strings.HasPrefix(refreshToken, mockRefreshPrefix) {
+		return nil, fmt.Errorf("failed to refresh access token: invalid refresh token")
+	}
+	return s.issueTokens()
+}
+
+// issueTokens creates a new pair of mock tokens
+// Tokens are recognized by their prefix, so they stay valid across restarts
+func (s *MockPlatformService) issueTokens() (*TokenResponse, error) {
+	accessToken, err := randomMockToken(mockAccessPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	refreshToken, err := randomMockToken(mockRefreshPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(mockTokenLifetime.Seconds()),
+		TokenType:    "Bearer",
+		Scope:        strings.Join(s.GetRequiredScopes(), ","),
+	}, nil
+}
+
+// GetUserInfo returns the mock creator every connection belongs to
+func (s *MockPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	if err := checkMockToken(accessToken); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return &UserInfo{
+		PlatformUserID: mockUserID,
+		Username:       mockUsername,
+		DisplayName:    mockDisplayName,
+	}, nil
+}
+
+// UploadMedia pretends to upload a media file
+func (s *MockPlatformService) UploadMedia(ctx context.Context, accessToken, mediaURL string) (string, error) {
+	return s.UploadMediaWithProgress(ctx, accessToken, mediaURL, nil)
+}
+
+// UploadMediaWithProgress pretends to upload a media file over a fifth of the processing delay,
+// reporting progress as it goes. Fails if the URL contains MockFailUploadMarker
+func (s *MockPlatformService) UploadMediaWithProgress(ctx context.Context, accessToken, mediaURL string, progress platformapi.UploadProgressFunc) (string, error) {
+	if err := checkMockToken(accessToken); err != nil {
+		return "", fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	step := s.processingDelay / mockImageDelayDiv / mockUploadSteps
+	for i := 1; i <= mockUploadSteps; i++ {
+		timer := time.NewTimer(step)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("failed to upload media: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		if strings.Contains(mediaURL, MockFailUploadMarker) && i == mockUploadSteps/2+1 {
+			return "", platformapi.NewPlatformError(models.PlatformMock, platformapi.ErrorCodeMediaRejected, 0, MockFailUploadMarker,
+				fmt.Sprintf("the mock platform rejected %s", mediaURL))
+		}
+		if progress != nil {
+			progress(platformapi.UploadStageUploading, i*100/mockUploadSteps)
+		}
+	}
+
+	mediaID, err := randomMockToken("mock-media-")
+	if err != nil {
+		return "", fmt.Errorf("failed to generate media ID: %w", err)
+	}
+	return mediaID, nil
+}
+
+// CreatePost starts publishing a post; its progress is reported by GetPostStatus
+// Caption keywords (see MockKeywordFail and friends) make it fail or behave differently
+func (s *MockPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	if err := checkMockToken(accessToken); err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+
+	keywords := make(map[string]bool)
+	for _, word := range strings.Fields(content.Text) {
+		keywords[strings.ToLower(word)] = true
+	}
+
+	switch {
+	case keywords[MockKeywordRateLimit]:
+		err := platformapi.NewPlatformError(models.PlatformMock, platformapi.ErrorCodeRateLimited, http.StatusTooManyRequests,
+			MockKeywordRateLimit, "rate limit exceeded, try again later")
+		err.RetryAt = time.Now().Add(mockRateLimitWindow)
+		return nil, err
+	case keywords[MockKeywordFail]:
+		return nil, platformapi.NewPlatformError(models.PlatformMock, platformapi.ErrorCodeContentPolicy, http.StatusBadRequest,
+			MockKeywordFail, "the caption contains "+MockKeywordFail)
+	}
+
+	publishID, err := randomMockToken("mock-post-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate post ID: %w", err)
+	}
+
+	if keywords[MockKeywordInbox] {
+		return &PostResponse{
+			PostID:    publishID,
+			PublishID: publishID,
+			Status:    string(models.PostStatusSentToInbox),
+		}, nil
+	}
+
+	publish := &mockPublish{
+		createdAt: time.Now(),
+		duration:  s.processingDelay / mockImageDelayDiv,
+	}
+	for _, mediaURL := range content.MediaURLs {
+		if !services.IsImageMedia(mediaURL, content.MediaKinds) {
+			publish.duration = s.processingDelay
+		}
+	}
+	if keywords[MockKeywordSlow] {
+		publish.duration *= mockSlowFactor
+	}
+	if keywords[MockKeywordFailProcessing] {
+		publish.failReason = "the mock platform could not process the media because the caption contains " + MockKeywordFailProcessing
+	}
+
+	s.mu.Lock()
+	s.posts[publishID] = publish
+	s.mu.Unlock()
+
+	return &PostResponse{
+		PostID:    publishID,
+		PublishID: publishID,
+		Status:    string(models.PostStatusProcessing),
+	}, nil
+}
+
+// GetPostStatus reports the progress of a post created by CreatePost
+// Progress grows with the time since the post was created until its processing duration has passed
+func (s *MockPlatformService) GetPostStatus(ctx context.Context, accessToken, postID string) (*PostStatusResponse, error) {
+	if err := checkMockToken(accessToken); err != nil {
+		return nil, fmt.Errorf("failed to get post status: %w", err)
+	}
+
+	s.mu.Lock()
+	publish, ok := s.posts[postID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to get post status: unknown post %s", postID)
+	}
+
+	elapsed := time.Since(publish.createdAt)
+	if elapsed < publish.duration {
+		return &PostStatusResponse{
+			Status:          string(models.PostStatusProcessing),
+			PostID:          postID,
+			ProgressPercent: int(elapsed * 100 / publish.duration),
+		}, nil
+	}
+
+	s.mu.Lock()
+	delete(s.posts, postID)
+	s.mu.Unlock()
+
+	if publish.failReason != "" {
+		return &PostStatusResponse{
+			Status:     string(models.PostStatusFailed),
+			PostID:     postID,
+			FailReason: publish.failReason,
+			ErrorCode:  platformapi.ErrorCodeMediaRejected,
+		}, nil
+	}
+	return &PostStatusResponse{
+		Status:          string(models.PostStatusPublished),
+		PostID:          postID,
+		ShareID:         postID,
+		ProgressPercent: 100,
+	}, nil
+}
+
+// checkMockToken rejects access tokens the mock platform did not issue
+func checkMockToken(accessToken string) error {
+	if !strings.HasPrefix(accessToken, mockAccessPrefix) {
+		return fmt.Errorf("invalid mock access token")
+	}
+	return nil
+}
+
+// randomMockToken returns prefix followed by 16 random hex characters
+func randomMockToken(prefix string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,114 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// monitoredService wraps a platform service and feeds the result of every call that talks to
+// the platform into the platform's circuit breaker
+type monitoredService struct {
+	PlatformService
+	breaker *platformapi.CircuitBreaker
+}
+
+// GenerateInstanceAuthURL starts the OAuth flow on the user's instance if the wrapped service
+// has per-instance accounts
+// It doesn't feed the breaker: a single instance the user entered being down or not being
+// an instance at all says nothing about the platform
+func (s *monitoredService) GenerateInstanceAuthURL(ctx context.Context, instance string) (AuthURLResponse, error) {
+	authenticator, ok := s.PlatformService.(platformapi.InstanceAuthenticator)
+	if !ok {
+		return AuthURLResponse{}, fmt.Errorf("%s accounts don't live on instances", s.GetPlatformName())
+	}
+	return authenticator.GenerateInstanceAuthURL(ctx, instance)
+}
+
+// CreateSession logs in with credentials the user entered if the wrapped service supports it
+func (s *monitoredService) CreateSession(ctx context.Context, identifier, password string) (*TokenResponse, error) {
+	authenticator, ok := s.PlatformService.(platformapi.PasswordAuthenticator)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't support logging in with a password", s.GetPlatformName())
+	}
+	resp, err := authenticator.CreateSession(ctx, identifier, password)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// ConnectWebhook verifies and connects an incoming webhook if the wrapped service supports it
+func (s *monitoredService) ConnectWebhook(ctx context.Context, webhookURL string) (*TokenResponse, error) {
+	authenticator, ok := s.PlatformService.(platformapi.WebhookAuthenticator)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't support connecting a webhook", s.GetPlatformName())
+	}
+	resp, err := authenticator.ConnectWebhook(ctx, webhookURL)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for tokens
+func (s *monitoredService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	resp, err := s.PlatformService.ExchangeCodeForTokens(ctx, code, additionalParams)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// RefreshAccessToken refreshes an access token
+func (s *monitoredService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	resp, err := s.PlatformService.RefreshAccessToken(ctx, refreshToken)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// GetUserInfo retrieves the profile of the authenticated user
+func (s *monitoredService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	info, err := s.PlatformService.GetUserInfo(ctx, accessToken)
+	s.breaker.Record(err)
+	return info, err
+}
+
+// UploadMedia uploads media to the platform
+func (s *monitoredService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	mediaID, err := s.PlatformService.UploadMedia(ctx, accessToken, mediaURL)
+	s.breaker.Record(err)
+	return mediaID, err
+}
+
+// UploadMediaWithProgress uploads media with progress reporting if the wrapped service
+// supports it, and without otherwise
+func (s *monitoredService) UploadMediaWithProgress(ctx context.Context, accessToken string, mediaURL string, progress platformapi.UploadProgressFunc) (string, error) {
+	uploader, ok := s.PlatformService.(platformapi.ProgressUploader)
+	if !ok {
+		return s.UploadMedia(ctx, accessToken, mediaURL)
+	}
+	mediaID, err := uploader.UploadMediaWithProgress(ctx, accessToken, mediaURL, progress)
+	s.breaker.Record(err)
+	return mediaID, err
+}
+
+// CreatePost creates a post on the platform
+func (s *monitoredService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	resp, err := s.PlatformService.CreatePost(ctx, accessToken, content)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// GetPostStatus checks the status of a post
+func (s *monitoredService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	resp, err := s.PlatformService.GetPostStatus(ctx, accessToken, postID)
+	s.breaker.Record(err)
+	return resp, err
+}
+
+// DeletePost deletes a published post if the wrapped service supports it
+func (s *monitoredService) DeletePost(ctx context.Context, accessToken string, postID string) error {
+	deleter, ok := s.PlatformService.(platformapi.PostDeleter)
+	if !ok {
+		return fmt.Errorf("%s doesn't support deleting posts", s.GetPlatformName())
+	}
+	err := deleter.DeletePost(ctx, accessToken, postID)
+	s.breaker.Record(err)
+	return err
+}
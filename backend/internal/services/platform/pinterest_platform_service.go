@@ -0,0 +1,228 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// pinterestTokenLifetime is how long Pinterest access tokens are valid, in seconds
+const pinterestTokenLifetime = 30 * 24 * 60 * 60
+
+// PinterestPlatformService implements PlatformService for Pinterest
+// Every pin is saved to one of the account's boards, chosen with the board_id setting
+type PinterestPlatformService struct {
+	authService  *services.PinterestAuthService
+	mediaService *services.PinterestMediaService
+	postService  *services.PinterestPostService
+	scopes       []string
+}
+
+// NewPinterestPlatformService creates a new Pinterest platform service
+func NewPinterestPlatformService(cfg config.PinterestConfig) *PinterestPlatformService {
+	mediaService := services.NewPinterestMediaService(cfg.APIBaseURL)
+
+	return &PinterestPlatformService{
+		authService:  services.NewPinterestAuthService(cfg.AppID, cfg.AppSecret, cfg.RedirectURI, cfg.Scopes, cfg.AuthBaseURL, cfg.APIBaseURL),
+		mediaService: mediaService,
+		postService:  services.NewPinterestPostService(mediaService),
+		scopes:       cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *PinterestPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformPinterest
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *PinterestPlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to Pinterest
+func (s *PinterestPlatformService) Capabilities() Capabilities {
+	return pinterestCapabilities
+}
+
+// ValidateSettings checks Pinterest pin settings against the schema
+func (s *PinterestPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformPinterest, pinterestCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if !services.IsPinterestBoardID(validated.String("board_id")) {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "board_id", Message: "must be the numeric ID of one of the account's boards"})
+	}
+	for _, field := range []string{"link", "cover_image_url"} {
+		if value := validated.String(field); value != "" && !isHTTPURL(value) {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: field, Message: "must be an http or https URL"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformPinterest, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// isHTTPURL reports whether value is an absolute http or https URL
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// GenerateAuthURL generates the Pinterest OAuth authorization URL
+func (s *PinterestPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // Pinterest doesn't use PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for tokens
+func (s *PinterestPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return pinterestTokenResponse(tokenResp, ""), nil
+}
+
+// RefreshAccessToken refreshes a Pinterest access token
+// Pinterest only issues a new refresh token to apps with continuous refresh; otherwise the
+// refresh token stays valid for a year from when the account connected
+func (s *PinterestPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.RefreshAccessToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh Pinterest token: %w", err)
+	}
+	return pinterestTokenResponse(tokenResp, refreshToken), nil
+}
+
+// pinterestTokenResponse converts a Pinterest token response, keeping refreshToken if no new one was issued
+func pinterestTokenResponse(tokenResp *services.PinterestTokenResponse, refreshToken string) *TokenResponse {
+	expiresIn := tokenResp.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = pinterestTokenLifetime
+	}
+	if tokenResp.RefreshToken != "" {
+		refreshToken = tokenResp.RefreshToken
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+		TokenType:    "Bearer",
+		Scope:        tokenResp.Scope,
+	}
+}
+
+// GetUserInfo retrieves the account's profile from Pinterest
+func (s *PinterestPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	account, err := s.authService.GetUserAccount(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Pinterest user info: %w", err)
+	}
+
+	platformUserID := account.ID
+	if platformUserID == "" {
+		platformUserID = account.Username
+	}
+	displayName := account.BusinessName
+	if displayName == "" {
+		displayName = account.Username
+	}
+
+	return &UserInfo{
+		PlatformUserID: platformUserID,
+		Username:       account.Username,
+		DisplayName:    displayName,
+		AvatarURL:      account.ProfileImage,
+	}, nil
+}
+
+// UploadMedia is not applicable for Pinterest: images are fetched from their URL and videos are
+// uploaded in CreatePost, right before the pin that uses them is created
+func (s *PinterestPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Pinterest")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost creates an image or video pin on the board of the board_id setting
+// The text becomes the pin's description
+func (s *PinterestPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	// Settings are validated when the post is created; check again in case of direct callers
+	settings, err := s.ValidateSettings(content.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURL := content.MediaURL
+	if mediaURL == "" && len(content.MediaURLs) > 0 {
+		mediaURL = content.MediaURLs[0]
+	}
+
+	pinID, pinURL, err := s.postService.CreatePin(ctx, accessToken, services.PinterestPinRequest{
+		BoardID:       settings.String("board_id"),
+		Title:         settings.String("title"),
+		Description:   content.Text,
+		Link:          settings.String("link"),
+		AltText:       settings.String("alt_text"),
+		MediaURL:      mediaURL,
+		IsVideo:       !services.IsImageMedia(mediaURL, content.MediaKinds),
+		CoverImageURL: settings.String("cover_image_url"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   pinID,
+		Status:   "published",
+		ShareURL: pinURL,
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a pin
+// Videos are processed before their pin is created, so pins are published as soon as they exist
+func (s *PinterestPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ShareURL:        services.PinterestPinURL(postID),
+		ProgressPercent: 100,
+	}, nil
+}
+
+// ListBoards returns the boards of the account, for the board_id setting
+func (s *PinterestPlatformService) ListBoards(ctx context.Context, accessToken string) ([]services.PinterestBoard, error) {
+	return s.postService.ListBoards(ctx, accessToken)
+}
+
+// CreateBoard creates a board to save pins to
+func (s *PinterestPlatformService) CreateBoard(ctx context.Context, accessToken, name, description, privacy string) (*services.PinterestBoard, error) {
+	if privacy == "" {
+		privacy = services.PinterestBoardPublic
+	}
+	return s.postService.CreateBoard(ctx, accessToken, name, description, privacy)
+}
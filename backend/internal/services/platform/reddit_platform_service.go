@@ -0,0 +1,259 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// redditTokenLifetime is how long Reddit access tokens are valid, in seconds
+const redditTokenLifetime = 60 * 60
+
+// RedditPlatformService implements PlatformService for Reddit
+// Every post is submitted to the subreddit of the subreddit setting, after checking that it
+// follows the subreddit's rules
+type RedditPlatformService struct {
+	authService  *services.RedditAuthService
+	mediaService *services.RedditMediaService
+	postService  *services.RedditPostService
+	scopes       []string
+}
+
+// NewRedditPlatformService creates a new Reddit platform service
+func NewRedditPlatformService(cfg config.RedditConfig) *RedditPlatformService {
+	mediaService := services.NewRedditMediaService(cfg.APIBaseURL, cfg.UserAgent)
+
+	return &RedditPlatformService{
+		authService:  services.NewRedditAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, cfg.Scopes, cfg.UserAgent, cfg.AuthBaseURL, cfg.APIBaseURL),
+		mediaService: mediaService,
+		postService:  services.NewRedditPostService(mediaService),
+		scopes:       cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *RedditPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformReddit
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *RedditPlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to Reddit
+func (s *RedditPlatformService) Capabilities() Capabilities {
+	return redditCapabilities
+}
+
+// ValidateSettings checks Reddit post settings against the schema
+// The subreddit is returned without the r/ it may be written with
+func (s *RedditPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformReddit, redditCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	subreddit := services.NormalizeSubreddit(validated.String("subreddit"))
+	if services.IsRedditSubredditName(subreddit) {
+		validated["subreddit"] = subreddit
+	} else {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "subreddit", Message: "must be the name of a subreddit, like r/golang"})
+	}
+	for _, field := range []string{"url", "poster_image_url"} {
+		if value := validated.String(field); value != "" && !isHTTPURL(value) {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: field, Message: "must be an http or https URL"})
+		}
+	}
+	if validated.String("flair_text") != "" && validated.String("flair_id") == "" {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "flair_text", Message: "needs a flair_id"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformReddit, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL generates the Reddit OAuth authorization URL
+func (s *RedditPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // Reddit doesn't use PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for tokens
+func (s *RedditPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return redditTokenResponse(tokenResp, ""), nil
+}
+
+// RefreshAccessToken refreshes a Reddit access token
+// Reddit keeps the refresh token of a permanent authorization, it isn't issued again
+func (s *RedditPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.RefreshAccessToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh Reddit token: %w", err)
+	}
+	return redditTokenResponse(tokenResp, refreshToken), nil
+}
+
+// redditTokenResponse converts a Reddit token response, keeping refreshToken if no new one was issued
+func redditTokenResponse(tokenResp *services.RedditTokenResponse, refreshToken string) *TokenResponse {
+	expiresIn := tokenResp.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = redditTokenLifetime
+	}
+	if tokenResp.RefreshToken != "" {
+		refreshToken = tokenResp.RefreshToken
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+		TokenType:    "Bearer",
+		Scope:        tokenResp.Scope,
+	}
+}
+
+// GetUserInfo retrieves the account's profile from Reddit
+func (s *RedditPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	user, err := s.authService.GetMe(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Reddit user info: %w", err)
+	}
+
+	platformUserID := user.ID
+	if platformUserID == "" {
+		platformUserID = user.Name
+	}
+
+	return &UserInfo{
+		PlatformUserID: platformUserID,
+		Username:       user.Name,
+		DisplayName:    "u/" + user.Name,
+		AvatarURL:      user.IconImg,
+	}, nil
+}
+
+// UploadMedia is not applicable for Reddit: media is uploaded in CreatePost, after the
+// subreddit's rules are checked
+func (s *RedditPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Reddit")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost submits a post to the subreddit of the subreddit setting
+// With media the post is an image or video post, with the url setting a link post, and a text
+// post otherwise
+func (s *RedditPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	// Settings are validated when the post is created; check again in case of direct callers
+	settings, err := s.ValidateSettings(content.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURL := content.MediaURL
+	if mediaURL == "" && len(content.MediaURLs) > 0 {
+		mediaURL = content.MediaURLs[0]
+	}
+
+	submission := services.RedditSubmission{
+		Subreddit: settings.String("subreddit"),
+		Kind:      services.RedditKindSelf,
+		Title:     settings.String("title"),
+		Text:      content.Text,
+		URL:       settings.String("url"),
+		MediaURL:  mediaURL,
+		PosterURL: settings.String("poster_image_url"),
+		FlairID:   settings.String("flair_id"),
+		FlairText: settings.String("flair_text"),
+		NSFW:      settings.Bool("nsfw"),
+		Spoiler:   settings.Bool("spoiler"),
+	}
+
+	var fieldErrors []platformapi.FieldError
+	switch {
+	case mediaURL != "" && submission.URL != "":
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "url", Message: "can't be combined with media"})
+	case mediaURL != "" && services.IsImageMedia(mediaURL, content.MediaKinds):
+		submission.Kind = services.RedditKindImage
+	case mediaURL != "":
+		submission.Kind = services.RedditKindVideo
+		if submission.PosterURL == "" {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "poster_image_url", Message: "is required for video posts"})
+		}
+	case submission.URL != "":
+		submission.Kind = services.RedditKindLink
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformReddit, Fields: fieldErrors}
+	}
+
+	// Reddit doesn't return the ID of image and video posts, they are found in the account's submissions
+	var username string
+	if submission.Kind == services.RedditKindImage || submission.Kind == services.RedditKindVideo {
+		user, err := s.authService.GetMe(ctx, accessToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Reddit user: %w", err)
+		}
+		username = user.Name
+	}
+
+	post, err := s.postService.Submit(ctx, accessToken, username, submission)
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   post.ID,
+		Status:   "published",
+		ShareURL: post.URL,
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a post
+// Posts are published by the time their ID is known
+func (s *RedditPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ShareURL:        services.RedditPostURL(postID),
+		ProgressPercent: 100,
+	}, nil
+}
+
+// ListSubreddits returns the subreddits the account is subscribed to, for the subreddit setting
+func (s *RedditPlatformService) ListSubreddits(ctx context.Context, accessToken string) ([]services.RedditSubreddit, error) {
+	return s.postService.ListSubreddits(ctx, accessToken)
+}
+
+// ListFlairs returns the post flairs of a subreddit, for the flair_id setting
+func (s *RedditPlatformService) ListFlairs(ctx context.Context, accessToken, subreddit string) ([]services.RedditFlair, error) {
+	name := services.NormalizeSubreddit(subreddit)
+	if !services.IsRedditSubredditName(name) {
+		return nil, fmt.Errorf("invalid subreddit name %q", subreddit)
+	}
+	return s.postService.ListFlairs(ctx, accessToken, name)
+}
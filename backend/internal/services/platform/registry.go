@@ -2,15 +2,18 @@ package platform
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 // PlatformRegistry manages all registered platform services
 // It provides a centralized way to access platform-specific implementations
 type PlatformRegistry struct {
 	services map[models.Platform]PlatformService
+	breakers map[models.Platform]*platformapi.CircuitBreaker
 	mu       sync.RWMutex // Protect concurrent access
 }
 
@@ -18,23 +21,49 @@ type PlatformRegistry struct {
 func NewPlatformRegistry() *PlatformRegistry {
 	return &PlatformRegistry{
 		services: make(map[models.Platform]PlatformService),
+		breakers: make(map[models.Platform]*platformapi.CircuitBreaker),
 	}
 }
 
 // Register registers a platform service
 // This should be called during application initialization for each supported platform
+// The service is wrapped so that its calls feed the platform's circuit breaker
 func (r *PlatformRegistry) Register(service PlatformService) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	platform := service.GetPlatformName()
-	r.services[platform] = service
+	breaker := platformapi.NewCircuitBreaker(platform)
+	r.services[platform] = &monitoredService{PlatformService: service, breaker: breaker}
+	r.breakers[platform] = breaker
+}
+
+// Breaker returns the circuit breaker of a registered platform, nil if it is not registered
+func (r *PlatformRegistry) Breaker(platform models.Platform) *platformapi.CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.breakers[platform]
+}
+
+// Health returns the health of every registered platform sorted by platform name
+func (r *PlatformRegistry) Health() []platformapi.PlatformHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]platformapi.PlatformHealth, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		result = append(result, breaker.Health())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Platform < result[j].Platform
+	})
+	return result
 }
 
 // Get retrieves a platform service by platform name
 // Returns an error if the platform is not supported
-// Returns interface{} to avoid circular dependencies
-func (r *PlatformRegistry) Get(platform models.Platform) (interface{}, error) {
+func (r *PlatformRegistry) Get(platform models.Platform) (PlatformService, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -78,6 +107,31 @@ func (r *PlatformRegistry) GetSupportedPlatforms() []models.Platform {
 	return platforms
 }
 
+// Capabilities returns the capabilities of every known platform sorted by platform name
+// Registered services describe themselves; built-in platforms that are not registered
+// (because they are not configured) are included with their built-in descriptors
+func (r *PlatformRegistry) Capabilities() []Capabilities {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byPlatform := make(map[models.Platform]Capabilities, len(r.services))
+	for _, capabilities := range KnownCapabilities() {
+		byPlatform[capabilities.Platform] = capabilities
+	}
+	for platform, service := range r.services {
+		byPlatform[platform] = service.Capabilities()
+	}
+
+	result := make([]Capabilities, 0, len(byPlatform))
+	for _, capabilities := range byPlatform {
+		result = append(result, capabilities)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Platform < result[j].Platform
+	})
+	return result
+}
+
 // Count returns the number of registered platforms
 func (r *PlatformRegistry) Count() int {
 	r.mu.RLock()
@@ -0,0 +1,137 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// SlackPlatformService implements PlatformService for Slack channels
+// A channel is connected with one of its incoming webhooks (see ConnectWebhook); the webhook URL
+// is stored as the access token
+type SlackPlatformService struct {
+	webhookService *services.SlackWebhookService
+}
+
+// NewSlackPlatformService creates a new Slack platform service
+func NewSlackPlatformService(cfg config.SlackConfig) *SlackPlatformService {
+	return &SlackPlatformService{
+		webhookService: services.NewSlackWebhookService(cfg.WebhookBaseURL),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *SlackPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformSlack
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+// Webhooks have no scopes
+func (s *SlackPlatformService) GetRequiredScopes() []string {
+	return nil
+}
+
+// Capabilities describes what can be published to Slack
+func (s *SlackPlatformService) Capabilities() Capabilities {
+	return slackCapabilities
+}
+
+// ValidateSettings checks Slack message settings against the schema
+func (s *SlackPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformSlack, slackCapabilities.Settings, settings)
+}
+
+// GenerateAuthURL is not supported: Slack channels are connected with a webhook, see ConnectWebhook
+func (s *SlackPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("Slack channels are connected with a webhook")
+}
+
+// ExchangeCodeForTokens is not supported: Slack channels are connected with a webhook, see ConnectWebhook
+func (s *SlackPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	return nil, fmt.Errorf("Slack channels are connected with a webhook")
+}
+
+// ConnectWebhook verifies a channel's incoming webhook and returns it as the credential
+func (s *SlackPlatformService) ConnectWebhook(ctx context.Context, webhookURL string) (*TokenResponse, error) {
+	webhookURL, _, err := s.webhookService.NormalizeWebhookURL(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookService.VerifyWebhook(ctx, webhookURL); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(webhookURL), nil
+}
+
+// RefreshAccessToken verifies that the webhook still exists
+// Webhooks don't expire, so the same credential is returned
+func (s *SlackPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	if err := s.webhookService.VerifyWebhook(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(refreshToken), nil
+}
+
+// GetUserInfo describes the webhook
+// Incoming webhooks can't be looked up, so the webhook's workspace and ID are the user ID and
+// the name; the name the user gave the connection replaces the latter
+func (s *SlackPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	_, webhookID, err := s.webhookService.NormalizeWebhookURL(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserInfo{
+		PlatformUserID: webhookID,
+		Username:       webhookID,
+		DisplayName:    "Slack webhook",
+	}, nil
+}
+
+// UploadMedia is not applicable for Slack: images are shown from their URL
+func (s *SlackPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Slack")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost sends a message with the webhook
+// Incoming webhooks don't return the message they sent, so the post has no ID or link
+func (s *SlackPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	imageURLs := content.MediaURLs
+	if len(imageURLs) == 0 && content.MediaURL != "" {
+		imageURLs = []string{content.MediaURL}
+	}
+
+	err := s.webhookService.SendMessage(ctx, accessToken, services.SlackMessageRequest{
+		Text:               content.Text,
+		ImageURLs:          imageURLs,
+		AltText:            content.Settings.String("alt_text"),
+		DisableLinkPreview: content.Settings.Bool("disable_link_preview"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		Status: "published",
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a message
+// Messages are published as soon as they are sent
+func (s *SlackPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ProgressPercent: 100,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// TelegramPlatformService implements PlatformService for Telegram channels and groups
+// A chat is connected with its ID or username and the token of a bot that was added to it (see
+// CreateSession); the access token stores both, see services.TelegramCredential
+type TelegramPlatformService struct {
+	authService *services.TelegramAuthService
+	postService *services.TelegramPostService
+}
+
+// NewTelegramPlatformService creates a new Telegram platform service
+func NewTelegramPlatformService(cfg config.TelegramConfig) *TelegramPlatformService {
+	authService := services.NewTelegramAuthService(cfg.APIBaseURL)
+
+	return &TelegramPlatformService{
+		authService: authService,
+		postService: services.NewTelegramPostService(authService),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *TelegramPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformTelegram
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+// Bot tokens have no scopes; what a bot may do is decided by its rights in the chat
+func (s *TelegramPlatformService) GetRequiredScopes() []string {
+	return nil
+}
+
+// Capabilities describes what can be published to Telegram
+func (s *TelegramPlatformService) Capabilities() Capabilities {
+	return telegramCapabilities
+}
+
+// ValidateSettings checks Telegram message settings against the schema
+func (s *TelegramPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformTelegram, telegramCapabilities.Settings, settings)
+}
+
+// GenerateAuthURL is not supported: Telegram chats are connected with a bot token, see CreateSession
+func (s *TelegramPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("Telegram chats are connected with a bot token")
+}
+
+// ExchangeCodeForTokens is not supported: Telegram chats are connected with a bot token, see CreateSession
+func (s *TelegramPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	return nil, fmt.Errorf("Telegram chats are connected with a bot token")
+}
+
+// CreateSession connects a chat: identifier is the chat's ID, @username or t.me link, and
+// password the token of a bot that may post to it
+// The bot and its rights in the chat are verified before the credential is returned
+func (s *TelegramPlatformService) CreateSession(ctx context.Context, identifier, password string) (*TokenResponse, error) {
+	connection, err := s.authService.ConnectChat(ctx, identifier, password)
+	if err != nil {
+		return nil, err
+	}
+	credential := services.TelegramCredential{ChatID: connection.Chat.ID, BotToken: password}
+	return credentialTokenResponse(credential.Encode()), nil
+}
+
+// RefreshAccessToken verifies that the bot may still post to the chat
+// Bot tokens don't expire, so the same credential is returned
+func (s *TelegramPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	credential, err := services.ParseTelegramCredential(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authService.VerifyCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(refreshToken), nil
+}
+
+// GetUserInfo retrieves the connected chat
+// The chat ID is the user ID: it stays the same when the chat is renamed
+func (s *TelegramPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	credential, err := services.ParseTelegramCredential(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := s.authService.VerifyCredential(ctx, credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Telegram chat: %w", err)
+	}
+
+	username := connection.Chat.Username
+	if username == "" {
+		username = connection.Chat.Title
+	}
+
+	return &UserInfo{
+		PlatformUserID: strconv.FormatInt(connection.Chat.ID, 10),
+		Username:       username,
+		DisplayName:    connection.Chat.Title,
+	}, nil
+}
+
+// UploadMedia is not applicable for Telegram: media is sent by URL and downloaded by Telegram
+func (s *TelegramPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for Telegram")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost sends a post to the connected chat
+// The post ID is the IDs of the messages it was sent as, see services.TelegramPostID
+func (s *TelegramPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	credential, err := services.ParseTelegramCredential(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	var media []services.MediaItem
+	for _, mediaURL := range mediaURLs {
+		media = append(media, services.MediaItem{
+			URL:     mediaURL,
+			IsVideo: !services.IsImageMedia(mediaURL, content.MediaKinds),
+		})
+	}
+
+	messages, err := s.postService.SendPost(ctx, credential, services.TelegramPostRequest{
+		Text:                content.Text,
+		Media:               media,
+		DisableNotification: content.Settings.Bool("disable_notification"),
+		ProtectContent:      content.Settings.Bool("protect_content"),
+		DisableLinkPreview:  content.Settings.Bool("disable_link_preview"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   services.TelegramPostID(messages),
+		Status:   "published",
+		ShareURL: services.TelegramMessageURL(messages[0]),
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a post
+// Messages are published as soon as they are sent
+func (s *TelegramPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	credential, err := services.ParseTelegramCredential(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	messageIDs, err := services.ParseTelegramPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostStatusResponse{
+		Status: "published",
+		PostID: postID,
+		ShareURL: services.TelegramMessageURL(services.TelegramMessage{
+			MessageID: messageIDs[0],
+			Chat:      services.TelegramChat{ID: credential.ChatID},
+		}),
+		ProgressPercent: 100,
+	}, nil
+}
+
+// DeletePost deletes the messages of a post
+func (s *TelegramPlatformService) DeletePost(ctx context.Context, accessToken string, postID string) error {
+	credential, err := services.ParseTelegramCredential(accessToken)
+	if err != nil {
+		return err
+	}
+	messageIDs, err := services.ParseTelegramPostID(postID)
+	if err != nil {
+		return err
+	}
+	return s.postService.DeleteMessages(ctx, credential, messageIDs)
+}
@@ -0,0 +1,240 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Lifetimes of Threads access tokens, in seconds
+const (
+	threadsShortLivedTokenLifetime = 60 * 60
+	threadsLongLivedTokenLifetime  = 60 * 24 * 60 * 60
+)
+
+// ThreadsPlatformService implements PlatformService for Threads
+// Posts are published with the container flow of InstagramMediaService
+type ThreadsPlatformService struct {
+	authService  *services.ThreadsAuthService
+	mediaService *services.InstagramMediaService
+	postService  *services.ThreadsPostService
+	prober       *services.MediaProber // Tells images from videos in UploadMedia, which only gets a URL
+}
+
+// NewThreadsPlatformService creates a new Threads platform service
+func NewThreadsPlatformService(cfg config.ThreadsConfig) *ThreadsPlatformService {
+	mediaService := services.NewThreadsMediaService(cfg.GraphBaseURL)
+
+	return &ThreadsPlatformService{
+		authService:  services.NewThreadsAuthService(cfg.AppID, cfg.AppSecret, cfg.RedirectURI, cfg.AuthBaseURL, cfg.GraphBaseURL),
+		mediaService: mediaService,
+		postService:  services.NewThreadsPostService(mediaService),
+		prober:       services.NewMediaProber(),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *ThreadsPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformThreads
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *ThreadsPlatformService) GetRequiredScopes() []string {
+	return services.ThreadsScopes
+}
+
+// Capabilities describes what can be published to Threads
+func (s *ThreadsPlatformService) Capabilities() Capabilities {
+	return threadsCapabilities
+}
+
+// ValidateSettings checks Threads post settings against the schema
+func (s *ThreadsPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformThreads, threadsCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if replyToID := validated.String("reply_to_id"); replyToID != "" && strings.Trim(replyToID, "0123456789") != "" {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformThreads, Fields: []platformapi.FieldError{
+			{Field: "reply_to_id", Message: "must be the numeric ID of a Threads post"},
+		}}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL generates the Threads OAuth authorization URL
+func (s *ThreadsPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // Threads doesn't use PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for tokens
+// The short-lived token is exchanged for a long-lived token right away
+func (s *ThreadsPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	longLivedResp, err := s.authService.ExchangeLongLivedToken(ctx, tokenResp.AccessToken)
+	if err != nil {
+		// The short-lived token still works for an hour, and can't be refreshed
+		log.Printf("Warning: Failed to get long-lived Threads token, using short-lived: %v", err)
+		return threadsTokenResponse(tokenResp.AccessToken, threadsShortLivedTokenLifetime), nil
+	}
+	return threadsTokenResponse(longLivedResp.AccessToken, longLivedResp.ExpiresIn), nil
+}
+
+// RefreshAccessToken refreshes a long-lived Threads token
+// Threads has no separate refresh token: the long-lived token itself is refreshed, which
+// gives a new token valid for another 60 days
+func (s *ThreadsPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	refreshed, err := s.authService.RefreshLongLivedToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh Threads token: %w", err)
+	}
+	return threadsTokenResponse(refreshed.AccessToken, refreshed.ExpiresIn), nil
+}
+
+// threadsTokenResponse converts a Threads access token into the tokens stored for the account
+func threadsTokenResponse(accessToken string, expiresIn int) *TokenResponse {
+	if expiresIn == 0 {
+		expiresIn = threadsLongLivedTokenLifetime
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: accessToken, // Threads uses the same token for refresh
+		ExpiresIn:    expiresIn,
+		TokenType:    "Bearer",
+		Scope:        strings.Join(services.ThreadsScopes, ","),
+	}
+}
+
+// GetUserInfo retrieves the user's Threads profile
+func (s *ThreadsPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Threads user info: %w", err)
+	}
+
+	displayName := userInfo.Name
+	if displayName == "" {
+		displayName = userInfo.Username
+	}
+
+	return &UserInfo{
+		PlatformUserID: userInfo.ID,
+		Username:       userInfo.Username,
+		DisplayName:    displayName,
+		AvatarURL:      userInfo.ProfilePictureURL,
+		Email:          "", // Not available through the Threads API
+	}, nil
+}
+
+// UploadMedia creates a container for an image or video and waits for it to be processed
+// Returns the container ID, which can be published within 24 hours
+func (s *ThreadsPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Threads user info: %w", err)
+	}
+
+	// The media's bytes decide whether it is an image, its extension only if they can't be read
+	kind := services.DetectMediaTypeFromURL(mediaURL)
+	if probe, err := s.prober.Probe(ctx, mediaURL); err == nil {
+		kind = probe.Kind
+	}
+
+	var containerID string
+	if kind == services.MediaTypeImage {
+		containerID, err = s.mediaService.CreatePhotoContainer(ctx, accessToken, userInfo.ID, mediaURL, "")
+	} else {
+		containerID, err = s.mediaService.CreateMediaContainer(ctx, accessToken, userInfo.ID, mediaURL, "", "VIDEO")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.mediaService.WaitForMediaProcessing(ctx, accessToken, containerID, 300); err != nil {
+		return "", fmt.Errorf("media processing failed: %w", err)
+	}
+	return containerID, nil
+}
+
+// CreatePost publishes a text, image, video or carousel post, or a reply
+func (s *ThreadsPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	var media []services.MediaItem
+	for _, mediaURL := range mediaURLs {
+		media = append(media, services.MediaItem{
+			URL:     mediaURL,
+			IsVideo: !services.IsImageMedia(mediaURL, content.MediaKinds),
+		})
+	}
+
+	post, err := s.createPost(ctx, accessToken, services.ThreadsPostRequest{
+		Text:         content.Text,
+		Media:        media,
+		ReplyToID:    content.Settings.String("reply_to_id"),
+		ReplyControl: content.Settings.String("reply_control"),
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   post.ID,
+		Status:   "published",
+		ShareURL: post.Permalink,
+	}, nil
+}
+
+// createPost publishes a post as the user the token belongs to
+func (s *ThreadsPlatformService) createPost(ctx context.Context, accessToken string, req services.ThreadsPostRequest) (*services.ThreadsPost, error) {
+	userInfo, err := s.authService.GetUserInfo(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Threads user info: %w", err)
+	}
+
+	log.Printf("Posting to Threads account: @%s (ID: %s)", userInfo.Username, userInfo.ID)
+	return s.postService.CreatePost(ctx, accessToken, userInfo.ID, req)
+}
+
+// GetPostStatus retrieves the status of a post
+// Threads posts are live once published; the permalink is looked up for the share URL
+func (s *ThreadsPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	permalink, err := s.mediaService.GetPermalink(ctx, accessToken, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ShareURL:        permalink,
+		ProgressPercent: 100,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package platform
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"github.com/osmanmertacar/sosyal/backend/internal/config"
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
 	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 // TikTokPlatformService implements PlatformService for TikTok
@@ -36,6 +38,29 @@ func (s *TikTokPlatformService) GetRequiredScopes() []string {
 	return s.config.TikTok.Scopes
 }
 
+// Capabilities describes what can be published to TikTok
+func (s *TikTokPlatformService) Capabilities() Capabilities {
+	return tiktokCapabilities
+}
+
+// ValidateSettings checks TikTok post settings against the schema and the UX Guidelines
+func (s *TikTokPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformTikTok, tiktokCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Branded content cannot be private (Point 3b)
+	if (validated.Bool("is_brand_content") || validated.Bool("is_brand_organic")) && validated.String("privacy_level") == "SELF_ONLY" {
+		return nil, &platformapi.SettingsError{
+			Platform: models.PlatformTikTok,
+			Fields:   []platformapi.FieldError{{Field: "privacy_level", Message: "branded content cannot be set to private visibility"}},
+		}
+	}
+
+	return validated, nil
+}
+
 // GenerateAuthURL generates the OAuth authorization URL
 // Note: TikTok doesn't use PKCE, so CodeVerifier will be empty
 func (s *TikTokPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
@@ -46,7 +71,7 @@ func (s *TikTokPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
 	}
 
 	// Build OAuth URL
-	baseURL := "https://www.tiktok.com/v2/auth/authorize/"
+	baseURL := s.config.TikTok.AuthBaseURL + services.TikTokAuthorizePath
 	params := url.Values{}
 	params.Add("client_key", s.config.TikTok.ClientKey)
 	params.Add("scope", strings.Join(s.config.TikTok.Scopes, ","))
@@ -74,8 +99,8 @@ func generateRandomState() (string, error) {
 
 // ExchangeCodeForTokens exchanges authorization code for access token
 // additionalParams is not used for TikTok (no PKCE)
-func (s *TikTokPlatformService) ExchangeCodeForTokens(code string, additionalParams map[string]string) (*TokenResponse, error) {
-	resp, err := s.tiktokService.ExchangeCodeForTokens(code)
+func (s *TikTokPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	resp, err := s.tiktokService.ExchangeCodeForTokens(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for tokens: %w", err)
 	}
@@ -90,8 +115,8 @@ func (s *TikTokPlatformService) ExchangeCodeForTokens(code string, additionalPar
 }
 
 // RefreshAccessToken refreshes an expired access token
-func (s *TikTokPlatformService) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
-	resp, err := s.tiktokService.RefreshAccessToken(refreshToken)
+func (s *TikTokPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	resp, err := s.tiktokService.RefreshAccessToken(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh access token: %w", err)
 	}
@@ -106,8 +131,8 @@ func (s *TikTokPlatformService) RefreshAccessToken(refreshToken string) (*TokenR
 }
 
 // GetUserInfo fetches user information from TikTok
-func (s *TikTokPlatformService) GetUserInfo(accessToken string) (*UserInfo, error) {
-	resp, err := s.tiktokService.GetUserInfo(accessToken)
+func (s *TikTokPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	resp, err := s.tiktokService.GetUserInfo(ctx, accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -129,7 +154,7 @@ func (s *TikTokPlatformService) GetUserInfo(accessToken string) (*UserInfo, erro
 
 // UploadMedia is not applicable for TikTok (videos are published directly from URL)
 // This method returns the mediaURL as-is for validation
-func (s *TikTokPlatformService) UploadMedia(accessToken, mediaURL string) (string, error) {
+func (s *TikTokPlatformService) UploadMedia(ctx context.Context, accessToken, mediaURL string) (string, error) {
 	// TikTok publishes directly from URL, no separate upload step needed
 	// Just return the URL for use in CreatePost
 	if mediaURL == "" {
@@ -140,7 +165,7 @@ func (s *TikTokPlatformService) UploadMedia(accessToken, mediaURL string) (strin
 
 // CreatePost publishes a video or photo to TikTok
 // Automatically detects media type from URL and uses appropriate method
-func (s *TikTokPlatformService) CreatePost(accessToken string, content PostContent) (*PostResponse, error) {
+func (s *TikTokPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
 	mediaURL := content.MediaURL
 	if mediaURL == "" && len(content.MediaIDs) > 0 {
 		// If MediaIDs provided (from UploadMedia), use the first one as URL
@@ -151,77 +176,38 @@ func (s *TikTokPlatformService) CreatePost(accessToken string, content PostConte
 		return nil, fmt.Errorf("media URL is required for TikTok posts")
 	}
 
-	// Convert platform TikTokSettings to services TikTokSettings
-	var tiktokSettings *services.TikTokPostSettings
-	if content.TikTokSettings != nil {
-		// Validate TikTok settings according to UX Guidelines
-		settings := content.TikTokSettings
-
-		// Privacy level is required (Point 2b)
-		if settings.PrivacyLevel == "" {
-			return nil, fmt.Errorf("privacy level is required for TikTok posts")
-		}
-
-		// Validate privacy level is one of the allowed values
-		validPrivacyLevels := map[string]bool{
-			"PUBLIC_TO_EVERYONE":    true,
-			"MUTUAL_FOLLOW_FRIENDS": true,
-			"FOLLOWER_OF_CREATOR":   true,
-			"SELF_ONLY":             true,
-		}
-		if !validPrivacyLevels[settings.PrivacyLevel] {
-			return nil, fmt.Errorf("invalid privacy level: %s", settings.PrivacyLevel)
-		}
-
-		// Branded content cannot be private (Point 3b)
-		if (settings.IsBrandContent || settings.IsBrandOrganic) && settings.PrivacyLevel == "SELF_ONLY" {
-			return nil, fmt.Errorf("branded content cannot be set to private visibility")
-		}
-
-		// Title max length is 150 characters (Point 2a)
-		if len(settings.Title) > 150 {
-			return nil, fmt.Errorf("title cannot exceed 150 characters")
-		}
-
-		// If DirectPost is enabled, brand_content_toggle must be set (Point 3)
-		isDirectPost := settings.DirectPost
-		if isDirectPost && !settings.IsBrandContent && !settings.IsBrandOrganic {
-			// This is handled by frontend, but we ensure it's false in API
-			settings.IsBrandContent = false
-			settings.IsBrandOrganic = false
-		}
+	// Settings are validated when the post is created; check again in case of direct callers
+	settings, err := s.ValidateSettings(content.Settings)
+	if err != nil {
+		return nil, err
+	}
 
-		tiktokSettings = &services.TikTokPostSettings{
-			Title:          settings.Title,
-			PrivacyLevel:   settings.PrivacyLevel,
-			AllowComment:   settings.AllowComment,
-			AllowDuet:      settings.AllowDuet,
-			AllowStitch:    settings.AllowStitch,
-			IsBrandContent: settings.IsBrandContent,
-			IsBrandOrganic: settings.IsBrandOrganic,
-			AutoAddMusic:   settings.AutoAddMusic,
-			DirectPost:     settings.DirectPost,
-		}
-	} else {
-		// If no settings provided, return error (privacy level is required)
-		return nil, fmt.Errorf("TikTok settings are required including privacy level")
+	tiktokSettings := &services.TikTokPostSettings{
+		Title:          settings.String("title"),
+		PrivacyLevel:   settings.String("privacy_level"),
+		AllowComment:   settings.Bool("allow_comment"),
+		AllowDuet:      settings.Bool("allow_duet"),
+		AllowStitch:    settings.Bool("allow_stitch"),
+		IsBrandContent: settings.Bool("is_brand_content"),
+		IsBrandOrganic: settings.Bool("is_brand_organic"),
+		AutoAddMusic:   settings.Bool("auto_add_music"),
+		DirectPost:     settings.Bool("direct_post"),
 	}
 
 	var resp *services.PublishVideoResponse
-	var err error
 
 	// Detect media type and publish accordingly
-	if services.IsImageURL(mediaURL) {
+	if services.IsImageMedia(mediaURL, content.MediaKinds) {
 		// Photo post - TikTok accepts array of image URLs
 		// Use MediaURLs if provided, otherwise use single mediaURL
 		imageURLs := content.MediaURLs
 		if len(imageURLs) == 0 {
 			imageURLs = []string{mediaURL}
 		}
-		resp, err = s.tiktokService.PublishPhotoFromURL(accessToken, imageURLs, content.Text, tiktokSettings)
+		resp, err = s.tiktokService.PublishPhotoFromURL(ctx, accessToken, imageURLs, content.Text, tiktokSettings)
 	} else {
 		// Video post - TikTok only supports single video
-		resp, err = s.tiktokService.PublishVideoFromURL(accessToken, mediaURL, content.Text, tiktokSettings)
+		resp, err = s.tiktokService.PublishVideoFromURL(ctx, accessToken, mediaURL, content.Text, tiktokSettings)
 	}
 
 	if err != nil {
@@ -230,7 +216,7 @@ func (s *TikTokPlatformService) CreatePost(accessToken string, content PostConte
 
 	// Inbox posts are done once TikTok accepts them; Direct Post needs polling
 	status := string(models.PostStatusProcessing)
-	if content.TikTokSettings != nil && !content.TikTokSettings.DirectPost {
+	if !tiktokSettings.DirectPost {
 		status = string(models.PostStatusSentToInbox)
 	}
 
@@ -241,8 +227,8 @@ func (s *TikTokPlatformService) CreatePost(accessToken string, content PostConte
 }
 
 // GetPostStatus gets the status of a TikTok post
-func (s *TikTokPlatformService) GetPostStatus(accessToken, postID string) (*PostStatusResponse, error) {
-	resp, err := s.tiktokService.GetPublishStatus(accessToken, postID)
+func (s *TikTokPlatformService) GetPostStatus(ctx context.Context, accessToken, postID string) (*PostStatusResponse, error) {
+	resp, err := s.tiktokService.GetPublishStatus(ctx, accessToken, postID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post status: %w", err)
 	}
@@ -252,6 +238,7 @@ func (s *TikTokPlatformService) GetPostStatus(accessToken, postID string) (*Post
 	status := "processing"
 	shareURL := ""
 	shareID := ""
+	var errorCode platformapi.ErrorCode
 
 	switch resp.Data.Status {
 	case "PUBLISH_COMPLETE":
@@ -264,6 +251,7 @@ func (s *TikTokPlatformService) GetPostStatus(accessToken, postID string) (*Post
 		status = string(models.PostStatusSentToInbox)
 	case "FAILED":
 		status = string(models.PostStatusFailed)
+		errorCode = services.TikTokErrorCode(resp.Data.FailReason)
 	case "PROCESSING_UPLOAD", "PROCESSING_DOWNLOAD":
 		status = string(models.PostStatusProcessing)
 	default:
@@ -276,5 +264,6 @@ func (s *TikTokPlatformService) GetPostStatus(accessToken, postID string) (*Post
 		ShareID:    shareID,
 		ShareURL:   shareURL,
 		FailReason: resp.Data.FailReason,
+		ErrorCode:  errorCode,
 	}, nil
 }
@@ -0,0 +1,159 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// WebhookPlatformService implements PlatformService for endpoints of the users' own systems
+// An endpoint is connected with its URL and the secret deliveries are signed with (see
+// CreateSession); the access token stores both, see services.WebhookCredential
+type WebhookPlatformService struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookPlatformService creates a new webhook platform service
+func NewWebhookPlatformService(cfg config.WebhookConfig) *WebhookPlatformService {
+	return &WebhookPlatformService{
+		webhookService: services.NewWebhookService(cfg.AllowedHosts, cfg.AllowPrivateURLs),
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *WebhookPlatformService) GetPlatformName() models.Platform {
+	return models.PlatformWebhook
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+// Endpoints have no scopes
+func (s *WebhookPlatformService) GetRequiredScopes() []string {
+	return nil
+}
+
+// Capabilities describes what can be published to an endpoint
+func (s *WebhookPlatformService) Capabilities() Capabilities {
+	return webhookCapabilities
+}
+
+// ValidateSettings checks webhook post settings against the schema
+func (s *WebhookPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformWebhook, webhookCapabilities.Settings, settings)
+}
+
+// GenerateAuthURL is not supported: endpoints are connected with their URL and secret, see CreateSession
+func (s *WebhookPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	return AuthURLResponse{}, fmt.Errorf("webhook endpoints are connected with their URL and secret")
+}
+
+// ExchangeCodeForTokens is not supported: endpoints are connected with their URL and secret, see CreateSession
+func (s *WebhookPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	return nil, fmt.Errorf("webhook endpoints are connected with their URL and secret")
+}
+
+// CreateSession connects an endpoint: identifier is its URL, and password the secret
+// deliveries are signed with
+// The endpoint is sent a signed ping, which it must accept, before the credential is returned
+func (s *WebhookPlatformService) CreateSession(ctx context.Context, identifier, password string) (*TokenResponse, error) {
+	endpointURL, err := s.webhookService.NormalizeURL(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.CheckWebhookSecret(password); err != nil {
+		return nil, err
+	}
+
+	credential := services.WebhookCredential{URL: endpointURL, Secret: password}
+	if err := s.webhookService.Ping(ctx, credential); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(credential.Encode()), nil
+}
+
+// RefreshAccessToken verifies that the endpoint still accepts deliveries
+// The secret doesn't expire, so the same credential is returned
+func (s *WebhookPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	credential, err := services.ParseWebhookCredential(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookService.Ping(ctx, credential); err != nil {
+		return nil, err
+	}
+	return credentialTokenResponse(refreshToken), nil
+}
+
+// GetUserInfo describes the endpoint
+// The endpoint's URL without its query, which may hold a token, is the user ID and its host the name
+func (s *WebhookPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	credential, err := services.ParseWebhookCredential(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := url.Parse(credential.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	return &UserInfo{
+		PlatformUserID: endpoint.Host + endpoint.Path,
+		Username:       endpoint.Host,
+		DisplayName:    "Webhook to " + endpoint.Host,
+	}, nil
+}
+
+// UploadMedia is not applicable for webhooks: endpoints fetch media from its URL
+func (s *WebhookPlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for webhooks")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost sends a post to the endpoint
+// The post ID and link are whatever the endpoint answered with, and may be empty
+func (s *WebhookPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	credential, err := services.ParseWebhookCredential(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaURLs := content.MediaURLs
+	if len(mediaURLs) == 0 && content.MediaURL != "" {
+		mediaURLs = []string{content.MediaURL}
+	}
+
+	result, err := s.webhookService.Publish(ctx, credential, services.WebhookPost{
+		ID:            content.PostID,
+		PublicationID: content.PublicationID,
+		Caption:       content.Text,
+		MediaURLs:     mediaURLs,
+	})
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	return &PostResponse{
+		PostID:   result.ID,
+		Status:   "published",
+		ShareURL: result.URL,
+	}, nil
+}
+
+// GetPostStatus retrieves the status of a post
+// Posts are published as soon as the endpoint accepts them
+func (s *WebhookPlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	return &PostStatusResponse{
+		Status:          "published",
+		PostID:          postID,
+		ProgressPercent: 100,
+	}, nil
+}
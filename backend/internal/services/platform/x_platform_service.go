@@ -1,10 +1,13 @@
 package platform
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
 	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
 	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 // XPlatformService implements PlatformService for X (Twitter)
@@ -18,14 +21,14 @@ type XPlatformService struct {
 }
 
 // NewXPlatformService creates a new X platform service
-func NewXPlatformService(clientID, clientSecret, redirectURI string) *XPlatformService {
+func NewXPlatformService(cfg config.XConfig) *XPlatformService {
 	return &XPlatformService{
-		authService:  services.NewXAuthService(clientID, clientSecret, redirectURI),
-		mediaService: services.NewXMediaService(),
-		postService:  services.NewXPostService(),
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		redirectURI:  redirectURI,
+		authService:  services.NewXAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, cfg.AuthBaseURL, cfg.APIBaseURL),
+		mediaService: services.NewXMediaService(cfg.UploadBaseURL),
+		postService:  services.NewXPostService(cfg.APIBaseURL),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURI:  cfg.RedirectURI,
 	}
 }
 
@@ -39,6 +42,16 @@ func (s *XPlatformService) GetRequiredScopes() []string {
 	return []string{"tweet.read", "tweet.write", "users.read", "offline.access", "media.write"}
 }
 
+// Capabilities describes what can be published to X
+func (s *XPlatformService) Capabilities() Capabilities {
+	return xCapabilities
+}
+
+// ValidateSettings checks X post settings; X has no platform-specific settings yet
+func (s *XPlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	return platformapi.ValidateSettings(models.PlatformX, xCapabilities.Settings, settings)
+}
+
 // GenerateAuthURL generates the OAuth authorization URL with PKCE
 func (s *XPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
 	url, state, codeVerifier, err := s.authService.GenerateAuthURL()
@@ -55,13 +68,13 @@ func (s *XPlatformService) GenerateAuthURL() (AuthURLResponse, error) {
 
 // ExchangeCodeForTokens exchanges authorization code for access token
 // additionalParams must contain "code_verifier" for PKCE
-func (s *XPlatformService) ExchangeCodeForTokens(code string, additionalParams map[string]string) (*TokenResponse, error) {
+func (s *XPlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
 	codeVerifier, ok := additionalParams["code_verifier"]
 	if !ok {
 		return nil, fmt.Errorf("code_verifier is required for X OAuth")
 	}
 
-	resp, err := s.authService.ExchangeCodeForToken(code, codeVerifier)
+	resp, err := s.authService.ExchangeCodeForToken(ctx, code, codeVerifier)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code for tokens: %w", err)
 	}
@@ -76,8 +89,8 @@ func (s *XPlatformService) ExchangeCodeForTokens(code string, additionalParams m
 }
 
 // RefreshAccessToken refreshes an expired access token
-func (s *XPlatformService) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
-	resp, err := s.authService.RefreshAccessToken(refreshToken)
+func (s *XPlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	resp, err := s.authService.RefreshAccessToken(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh access token: %w", err)
 	}
@@ -92,8 +105,8 @@ func (s *XPlatformService) RefreshAccessToken(refreshToken string) (*TokenRespon
 }
 
 // GetUserInfo fetches user information from X
-func (s *XPlatformService) GetUserInfo(accessToken string) (*UserInfo, error) {
-	resp, err := s.authService.GetUserInfo(accessToken)
+func (s *XPlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	resp, err := s.authService.GetUserInfo(ctx, accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -108,8 +121,17 @@ func (s *XPlatformService) GetUserInfo(accessToken string) (*UserInfo, error) {
 }
 
 // UploadMedia downloads and uploads media to X
-func (s *XPlatformService) UploadMedia(accessToken, mediaURL string) (string, error) {
-	mediaID, err := s.mediaService.UploadFromURL(accessToken, mediaURL)
+func (s *XPlatformService) UploadMedia(ctx context.Context, accessToken, mediaURL string) (string, error) {
+	mediaID, err := s.mediaService.UploadFromURL(ctx, accessToken, mediaURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload media: %w", err)
+	}
+	return mediaID, nil
+}
+
+// UploadMediaWithProgress uploads media to X and reports upload and processing progress
+func (s *XPlatformService) UploadMediaWithProgress(ctx context.Context, accessToken, mediaURL string, progress platformapi.UploadProgressFunc) (string, error) {
+	mediaID, err := s.mediaService.UploadFromURLWithProgress(ctx, accessToken, mediaURL, progress)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload media: %w", err)
 	}
@@ -118,7 +140,7 @@ func (s *XPlatformService) UploadMedia(accessToken, mediaURL string) (string, er
 
 // CreatePost creates a tweet with optional media
 // Supports up to 4 images OR 1 video per tweet
-func (s *XPlatformService) CreatePost(accessToken string, content PostContent) (*PostResponse, error) {
+func (s *XPlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
 	var mediaIDs []string
 
 	// Use pre-uploaded media IDs if provided
@@ -131,7 +153,7 @@ func (s *XPlatformService) CreatePost(accessToken string, content PostContent) (
 			if i >= 4 {
 				break
 			}
-			mediaID, err := s.UploadMedia(accessToken, mediaURL)
+			mediaID, err := s.UploadMedia(ctx, accessToken, mediaURL)
 			if err != nil {
 				return nil, fmt.Errorf("failed to upload media %d: %w", i+1, err)
 			}
@@ -139,7 +161,7 @@ func (s *XPlatformService) CreatePost(accessToken string, content PostContent) (
 		}
 	} else if content.MediaURL != "" {
 		// Single media URL - upload it
-		mediaID, err := s.UploadMedia(accessToken, content.MediaURL)
+		mediaID, err := s.UploadMedia(ctx, accessToken, content.MediaURL)
 		if err != nil {
 			return nil, err
 		}
@@ -147,7 +169,7 @@ func (s *XPlatformService) CreatePost(accessToken string, content PostContent) (
 	}
 
 	// Create tweet
-	resp, err := s.postService.CreatePost(accessToken, content.Text, mediaIDs)
+	resp, err := s.postService.CreatePost(ctx, accessToken, content.Text, mediaIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
@@ -160,7 +182,7 @@ func (s *XPlatformService) CreatePost(accessToken string, content PostContent) (
 }
 
 // GetPostStatus gets the status of a post (X posts are immediate, so always returns published)
-func (s *XPlatformService) GetPostStatus(accessToken, postID string) (*PostStatusResponse, error) {
+func (s *XPlatformService) GetPostStatus(ctx context.Context, accessToken, postID string) (*PostStatusResponse, error) {
 	// X posts are published immediately, no async processing
 	return &PostStatusResponse{
 		Status: "published",
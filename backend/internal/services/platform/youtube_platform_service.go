@@ -0,0 +1,279 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// youtubeShortsTitle is the title of videos whose caption is empty
+const youtubeShortsTitle = "#Shorts"
+
+// YouTubePlatformService implements PlatformService for YouTube Shorts
+// Videos are uploaded with the resumable upload protocol and processed by YouTube afterwards,
+// which GetPostStatus polls
+type YouTubePlatformService struct {
+	authService  *services.YouTubeAuthService
+	videoService *services.YouTubeVideoService
+	scopes       []string
+}
+
+// NewYouTubePlatformService creates a new YouTube platform service
+func NewYouTubePlatformService(cfg config.YouTubeConfig) *YouTubePlatformService {
+	return &YouTubePlatformService{
+		authService:  services.NewYouTubeAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, cfg.Scopes, cfg.AuthBaseURL, cfg.TokenBaseURL, cfg.APIBaseURL),
+		videoService: services.NewYouTubeVideoService(cfg.APIBaseURL, services.NewVideoService()),
+		scopes:       cfg.Scopes,
+	}
+}
+
+// GetPlatformName returns the platform name
+func (s *YouTubePlatformService) GetPlatformName() models.Platform {
+	return models.PlatformYouTube
+}
+
+// GetRequiredScopes returns the required OAuth scopes
+func (s *YouTubePlatformService) GetRequiredScopes() []string {
+	return s.scopes
+}
+
+// Capabilities describes what can be published to YouTube
+func (s *YouTubePlatformService) Capabilities() Capabilities {
+	return youtubeCapabilities
+}
+
+// ValidateSettings checks YouTube video settings against the schema
+// A scheduled video has to be private until publish_at, when YouTube makes it public
+func (s *YouTubePlatformService) ValidateSettings(settings Settings) (Settings, error) {
+	validated, err := platformapi.ValidateSettings(models.PlatformYouTube, youtubeCapabilities.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []platformapi.FieldError
+	if strings.ContainsAny(validated.String("title"), "<>") {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "title", Message: "must not contain < or >"})
+	}
+	if categoryID := validated.String("category_id"); categoryID != "" && strings.Trim(categoryID, "0123456789") != "" {
+		fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "category_id", Message: "must be a numeric video category ID"})
+	}
+	if publishAt := validated.String("publish_at"); publishAt != "" {
+		if at, err := time.Parse(time.RFC3339, publishAt); err != nil {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "publish_at", Message: "must be an RFC 3339 time"})
+		} else if !at.After(time.Now()) {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "publish_at", Message: "must be in the future"})
+		} else if validated.String("privacy_status") != "private" {
+			fieldErrors = append(fieldErrors, platformapi.FieldError{Field: "privacy_status", Message: "must be private when publish_at is set"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &platformapi.SettingsError{Platform: models.PlatformYouTube, Fields: fieldErrors}
+	}
+	return validated, nil
+}
+
+// GenerateAuthURL generates the Google OAuth authorization URL
+func (s *YouTubePlatformService) GenerateAuthURL() (AuthURLResponse, error) {
+	authURL, state, err := s.authService.GenerateAuthURL()
+	if err != nil {
+		return AuthURLResponse{}, fmt.Errorf("failed to generate auth URL: %w", err)
+	}
+
+	return AuthURLResponse{
+		URL:          authURL,
+		State:        state,
+		CodeVerifier: "", // Confidential clients authenticate with the client secret instead of PKCE
+	}, nil
+}
+
+// ExchangeCodeForTokens exchanges an authorization code for an access and refresh token
+func (s *YouTubePlatformService) ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error) {
+	tokenResp, err := s.authService.ExchangeCodeForToken(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return youtubeTokenResponse(tokenResp, ""), nil
+}
+
+// RefreshAccessToken refreshes a Google access token
+// Google doesn't rotate refresh tokens, so the one the token was refreshed with is kept
+func (s *YouTubePlatformService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, platformapi.NewPlatformError(models.PlatformYouTube, platformapi.ErrorCodeAuthExpired, 0, "",
+			"Google did not issue a refresh token; the account has to be reconnected")
+	}
+
+	tokenResp, err := s.authService.RefreshAccessToken(ctx, refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh YouTube token: %w", err)
+	}
+	return youtubeTokenResponse(tokenResp, refreshToken), nil
+}
+
+// youtubeTokenResponse converts a Google token response, falling back to refreshToken when
+// the response has none
+func youtubeTokenResponse(tokenResp *services.YouTubeTokenResponse, refreshToken string) *TokenResponse {
+	if tokenResp.RefreshToken != "" {
+		refreshToken = tokenResp.RefreshToken
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    tokenResp.ExpiresIn,
+		TokenType:    "Bearer",
+		Scope:        tokenResp.Scope,
+	}
+}
+
+// GetUserInfo retrieves the user's YouTube channel, which is the connected account
+func (s *YouTubePlatformService) GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
+	channel, err := s.authService.GetChannel(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTube channel: %w", err)
+	}
+
+	username := channel.CustomURL
+	if username == "" {
+		username = channel.Title
+	}
+
+	return &UserInfo{
+		PlatformUserID: channel.ID,
+		Username:       username,
+		DisplayName:    channel.Title,
+		AvatarURL:      channel.AvatarURL,
+		Email:          "", // Not requested, the upload scopes don't include it
+	}, nil
+}
+
+// UploadMedia is not applicable for YouTube: the video is the post, so it is uploaded in CreatePost
+func (s *YouTubePlatformService) UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error) {
+	if mediaURL == "" {
+		return "", fmt.Errorf("media URL is required for YouTube")
+	}
+	return mediaURL, nil
+}
+
+// CreatePost uploads a video as a Short
+// The text becomes the description, and its first line the title unless one is set
+func (s *YouTubePlatformService) CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error) {
+	mediaURL := content.MediaURL
+	if mediaURL == "" && len(content.MediaURLs) > 0 {
+		mediaURL = content.MediaURLs[0]
+	}
+	if mediaURL == "" {
+		return nil, fmt.Errorf("media URL is required for YouTube posts")
+	}
+
+	// Settings are validated when the post is created; check again in case of direct callers
+	settings, err := s.ValidateSettings(content.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := services.YouTubeVideoMetadata{
+		Title:         youtubeTitle(settings.String("title"), content.Text),
+		Description:   content.Text,
+		Tags:          youtubeTags(settings.String("tags")),
+		CategoryID:    settings.String("category_id"),
+		PrivacyStatus: settings.String("privacy_status"),
+		MadeForKids:   settings.Bool("made_for_kids"),
+	}
+	if publishAt := settings.String("publish_at"); publishAt != "" {
+		metadata.PublishAt, _ = time.Parse(time.RFC3339, publishAt)
+	}
+
+	videoID, err := s.videoService.Upload(ctx, accessToken, mediaURL, metadata, nil)
+	if err != nil {
+		return &PostResponse{
+			Status:   "failed",
+			ErrorMsg: err.Error(),
+		}, err
+	}
+
+	log.Printf("Uploaded YouTube video %s, waiting for processing", videoID)
+	return &PostResponse{
+		PostID:   videoID,
+		Status:   string(models.PostStatusProcessing),
+		ShareURL: services.YouTubeShortURL(videoID),
+	}, nil
+}
+
+// GetPostStatus checks how YouTube's processing of an uploaded video is going
+func (s *YouTubePlatformService) GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error) {
+	videoStatus, err := s.videoService.GetVideoStatus(ctx, accessToken, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video status: %w", err)
+	}
+
+	resp := &PostStatusResponse{
+		Status:          string(models.PostStatusProcessing),
+		PostID:          postID,
+		ProgressPercent: videoStatus.ProgressPercent,
+	}
+
+	switch videoStatus.UploadStatus {
+	case services.YouTubeUploadProcessed:
+		resp.Status = string(models.PostStatusPublished)
+		resp.ShareURL = services.YouTubeShortURL(postID)
+		resp.ProgressPercent = 100
+	case services.YouTubeUploadFailed:
+		resp.Status = string(models.PostStatusFailed)
+		resp.FailReason = videoStatus.FailureReason
+	case services.YouTubeUploadRejected:
+		resp.Status = string(models.PostStatusFailed)
+		resp.FailReason = videoStatus.RejectionReason
+	case services.YouTubeUploadDeleted:
+		resp.Status = string(models.PostStatusFailed)
+		resp.FailReason = services.YouTubeUploadDeleted
+	default:
+		// Processing can fail before the upload status changes
+		if videoStatus.ProcessingStatus == "failed" || videoStatus.ProcessingStatus == "terminated" {
+			resp.Status = string(models.PostStatusFailed)
+			resp.FailReason = videoStatus.ProcessingFailureReason
+		}
+	}
+
+	if resp.Status == string(models.PostStatusFailed) {
+		resp.ErrorCode = services.YouTubeErrorCode(resp.FailReason)
+	}
+	return resp, nil
+}
+
+// youtubeTitle returns the title setting, or the first line of the text cut to 100 characters
+func youtubeTitle(title, text string) string {
+	if title != "" {
+		return title
+	}
+
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	firstLine = strings.NewReplacer("<", "", ">", "").Replace(strings.TrimSpace(firstLine))
+	if utf8.RuneCountInString(firstLine) > youtubeCapabilities.TitleMaxLength {
+		firstLine = string([]rune(firstLine)[:youtubeCapabilities.TitleMaxLength])
+	}
+	if firstLine == "" {
+		return youtubeShortsTitle
+	}
+	return firstLine
+}
+
+// youtubeTags splits the comma-separated tags setting
+func youtubeTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,868 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// tiktokErrorCodes maps TikTok error codes to error codes
+// https://developers.tiktok.com/doc/tiktok-api-v2-error-handling
+var tiktokErrorCodes = map[string]platformapi.ErrorCode{
+	"access_token_invalid":                               platformapi.ErrorCodeAuthExpired,
+	"invalid_grant":                                      platformapi.ErrorCodeAuthExpired,
+	"scope_not_authorized":                               platformapi.ErrorCodeInsufficientScope,
+	"scope_permission_missed":                            platformapi.ErrorCodeInsufficientScope,
+	"rate_limit_exceeded":                                platformapi.ErrorCodeRateLimited,
+	"spam_risk_too_many_posts":                           platformapi.ErrorCodeRateLimited,
+	"spam_risk_too_many_pending_share":                   platformapi.ErrorCodeRateLimited,
+	"spam_risk_user_banned_from_posting":                 platformapi.ErrorCodeContentPolicy,
+	"spam_risk":                                          platformapi.ErrorCodeContentPolicy,
+	"unaudited_client_can_only_post_to_private_accounts": platformapi.ErrorCodeContentPolicy,
+	"reached_active_user_cap":                            platformapi.ErrorCodeRateLimited,
+	"url_ownership_unverified":                           platformapi.ErrorCodeMediaRejected,
+	"file_format_check_failed":                           platformapi.ErrorCodeMediaRejected,
+	"duration_check_failed":                              platformapi.ErrorCodeMediaRejected,
+	"frame_rate_check_failed":                            platformapi.ErrorCodeMediaRejected,
+	"picture_size_check_failed":                          platformapi.ErrorCodeMediaRejected,
+	"video_pull_failed":                                  platformapi.ErrorCodeMediaRejected,
+	"photo_pull_failed":                                  platformapi.ErrorCodeMediaRejected,
+	"internal_error":                                     platformapi.ErrorCodeTransient,
+}
+
+// TikTokErrorCode classifies a TikTok error code or publish fail_reason
+func TikTokErrorCode(code string) platformapi.ErrorCode {
+	if errorCode, ok := tiktokErrorCodes[code]; ok {
+		return errorCode
+	}
+	return platformapi.ErrorCodeUnknown
+}
+
+// tiktokError classifies an unsuccessful TikTok response
+// TikTok errors look like {"error":{"code":"...","message":"...","log_id":"..."}}, except the
+// OAuth endpoints which return {"error":"...","error_description":"..."}
+func tiktokError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Error json.RawMessage `json:"error"`
+	}
+	var apiError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	var oauthError struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+
+	code, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && len(parsed.Error) > 0 {
+		if json.Unmarshal(parsed.Error, &apiError) == nil && apiError.Code != "" {
+			code, message = apiError.Code, apiError.Message
+		} else if json.Unmarshal(body, &oauthError) == nil && oauthError.Error != "" {
+			code, message = oauthError.Error, oauthError.Description
+		}
+	}
+	return newTikTokError(resp.StatusCode, resp.Header, code, message)
+}
+
+// newTikTokError classifies a TikTok error code, falling back to the HTTP status
+func newTikTokError(statusCode int, header http.Header, code, message string) *platformapi.PlatformError {
+	errorCode := TikTokErrorCode(code)
+	if errorCode == platformapi.ErrorCodeUnknown {
+		errorCode = platformapi.CodeForStatus(statusCode)
+	}
+	err := platformapi.NewPlatformError(models.PlatformTikTok, errorCode, statusCode, code, message)
+	if header != nil {
+		err.RetryAt = platformapi.RetryAfter(header)
+	}
+	return err
+}
+
+// xError classifies an unsuccessful X API response
+// X v2 errors are problem details like {"title":"...","detail":"...","type":"...","status":403};
+// older endpoints return {"errors":[{"code":187,"message":"..."}]}
+func xError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+		Type   string `json:"type"`
+		Errors []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil {
+		switch {
+		case parsed.Detail != "":
+			platformCode, message = parsed.Title, parsed.Detail
+		case len(parsed.Errors) > 0:
+			platformCode, message = strconv.Itoa(parsed.Errors[0].Code), parsed.Errors[0].Message
+		case parsed.Error != "":
+			platformCode, message = parsed.Error, parsed.ErrorDescription
+		}
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "duplicate"):
+		code = platformapi.ErrorCodeDuplicateContent
+	case platformCode == "invalid_grant" || (platformCode == "invalid_request" && strings.Contains(lower, "token")):
+		code = platformapi.ErrorCodeAuthExpired
+	case resp.StatusCode == http.StatusForbidden && (strings.Contains(lower, "not permitted") || strings.Contains(lower, "suspended") || strings.Contains(lower, "violat")):
+		code = platformapi.ErrorCodeContentPolicy
+	case resp.StatusCode == http.StatusBadRequest && strings.Contains(lower, "media"):
+		code = platformapi.ErrorCodeMediaRejected
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformX, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		// X reports when the rate limit window resets as a Unix timestamp
+		if reset, parseErr := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64); parseErr == nil {
+			err.RetryAt = time.Unix(reset, 0)
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// observeXRateLimits reports the rate limits in the headers of an X response to the observer of ctx
+// x-rate-limit-* is the limit of the endpoint; x-user-limit-24hour-* is the daily posting
+// limit of the user, sent by POST /2/tweets on some access tiers
+func observeXRateLimits(ctx context.Context, endpoint string, header http.Header) {
+	if limit, ok := xRateLimit(header, "x-rate-limit"); ok {
+		platformapi.ObserveRateLimit(ctx, endpoint, limit)
+	}
+	if limit, ok := xRateLimit(header, "x-user-limit-24hour"); ok {
+		platformapi.ObserveRateLimit(ctx, endpoint+"_24h", limit)
+	}
+}
+
+// xRateLimit parses the -limit, -remaining and -reset headers with the given prefix
+func xRateLimit(header http.Header, prefix string) (platformapi.RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get(prefix + "-remaining"))
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get(prefix+"-reset"), 10, 64)
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get(prefix + "-limit"))
+	return platformapi.RateLimit{Limit: limit, Remaining: remaining, ResetAt: time.Unix(reset, 0)}, true
+}
+
+// graphAPIErrorCodes maps Graph API error codes to error codes
+// https://developers.facebook.com/docs/graph-api/guides/error-handling
+var graphAPIErrorCodes = map[int]platformapi.ErrorCode{
+	1:     platformapi.ErrorCodeTransient,   // Unknown API error, may be temporary
+	2:     platformapi.ErrorCodeTransient,   // Service temporarily unavailable
+	4:     platformapi.ErrorCodeRateLimited, // Application request limit reached
+	9:     platformapi.ErrorCodeRateLimited, // Publishing limit reached
+	10:    platformapi.ErrorCodeInsufficientScope,
+	17:    platformapi.ErrorCodeRateLimited, // User request limit reached
+	32:    platformapi.ErrorCodeRateLimited, // Page request limit reached
+	102:   platformapi.ErrorCodeAuthExpired,
+	190:   platformapi.ErrorCodeAuthExpired,
+	352:   platformapi.ErrorCodeMediaRejected, // Unsupported video format
+	368:   platformapi.ErrorCodeContentPolicy, // Blocked for policy violations
+	506:   platformapi.ErrorCodeDuplicateContent,
+	613:   platformapi.ErrorCodeRateLimited,
+	9004:  platformapi.ErrorCodeMediaRejected, // Media could not be fetched from the URL
+	9007:  platformapi.ErrorCodeTransient,     // Media not ready for publishing yet
+	36000: platformapi.ErrorCodeMediaRejected,
+	36001: platformapi.ErrorCodeMediaRejected,
+	36003: platformapi.ErrorCodeMediaRejected, // Aspect ratio not supported
+	36004: platformapi.ErrorCodeMediaRejected,
+}
+
+// instagramError classifies an unsuccessful Instagram response
+// The Graph API returns {"error":{"message":"...","type":"...","code":190,"error_subcode":463}};
+// the token endpoint on api.instagram.com returns {"error_type":"...","code":400,"error_message":"..."}
+func instagramError(resp *http.Response, body []byte) error {
+	return graphAPIError(models.PlatformInstagram, resp, body)
+}
+
+// threadsError classifies an unsuccessful Threads response
+// The Threads API is a Graph API and shares its error codes with Instagram
+func threadsError(resp *http.Response, body []byte) error {
+	return graphAPIError(models.PlatformThreads, resp, body)
+}
+
+// facebookError classifies an unsuccessful Facebook Graph API response
+func facebookError(resp *http.Response, body []byte) error {
+	return graphAPIError(models.PlatformFacebook, resp, body)
+}
+
+// graphAPIError classifies an unsuccessful response of a Meta Graph API
+func graphAPIError(platform models.Platform, resp *http.Response, body []byte) error {
+	var parsed struct {
+		Error *struct {
+			Message      string `json:"message"`
+			Type         string `json:"type"`
+			Code         int    `json:"code"`
+			ErrorSubcode int    `json:"error_subcode"`
+		} `json:"error"`
+		ErrorType    string `json:"error_type"`
+		ErrorMessage string `json:"error_message"`
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil {
+		switch {
+		case parsed.Error != nil:
+			platformCode, message = strconv.Itoa(parsed.Error.Code), parsed.Error.Message
+			if errorCode, ok := graphAPIErrorCodes[parsed.Error.Code]; ok {
+				code = errorCode
+			} else if parsed.Error.Code >= 200 && parsed.Error.Code <= 299 {
+				code = platformapi.ErrorCodeInsufficientScope // Permission errors
+			}
+			if parsed.Error.ErrorSubcode != 0 {
+				platformCode = fmt.Sprintf("%s/%d", platformCode, parsed.Error.ErrorSubcode)
+			}
+		case parsed.ErrorMessage != "":
+			platformCode, message = parsed.ErrorType, parsed.ErrorMessage
+			if parsed.ErrorType == "OAuthException" {
+				code = platformapi.ErrorCodeAuthExpired
+			}
+		}
+	}
+
+	err := platformapi.NewPlatformError(platform, code, resp.StatusCode, platformCode, message)
+	err.RetryAt = platformapi.RetryAfter(resp.Header)
+	return err
+}
+
+// youtubeErrorCodes maps YouTube Data API error reasons, and the failure and rejection reasons
+// of uploaded videos, to error codes
+// https://developers.google.com/youtube/v3/docs/errors
+var youtubeErrorCodes = map[string]platformapi.ErrorCode{
+	"invalid_grant":           platformapi.ErrorCodeAuthExpired, // Refresh token expired or revoked
+	"authError":               platformapi.ErrorCodeAuthExpired,
+	"insufficientPermissions": platformapi.ErrorCodeInsufficientScope,
+	"youtubeSignupRequired":   platformapi.ErrorCodeInsufficientScope, // The Google account has no channel
+	"quotaExceeded":           platformapi.ErrorCodeRateLimited,       // Daily quota of the project
+	"rateLimitExceeded":       platformapi.ErrorCodeRateLimited,
+	"userRateLimitExceeded":   platformapi.ErrorCodeRateLimited,
+	"uploadLimitExceeded":     platformapi.ErrorCodeRateLimited, // Uploads of the channel
+	"backendError":            platformapi.ErrorCodeTransient,
+
+	// status.failureReason of videos whose upload or processing failed
+	"codec":         platformapi.ErrorCodeMediaRejected,
+	"conversion":    platformapi.ErrorCodeMediaRejected,
+	"emptyFile":     platformapi.ErrorCodeMediaRejected,
+	"invalidFile":   platformapi.ErrorCodeMediaRejected,
+	"tooSmall":      platformapi.ErrorCodeMediaRejected,
+	"uploadAborted": platformapi.ErrorCodeTransient,
+
+	// status.rejectionReason of videos YouTube refused
+	"duplicate":     platformapi.ErrorCodeDuplicateContent,
+	"length":        platformapi.ErrorCodeMediaRejected,
+	"claim":         platformapi.ErrorCodeContentPolicy,
+	"copyright":     platformapi.ErrorCodeContentPolicy,
+	"inappropriate": platformapi.ErrorCodeContentPolicy,
+	"legal":         platformapi.ErrorCodeContentPolicy,
+	"termsOfUse":    platformapi.ErrorCodeContentPolicy,
+	"trademark":     platformapi.ErrorCodeContentPolicy,
+}
+
+// YouTubeErrorCode classifies a YouTube error reason, or the failure or rejection reason of a video
+func YouTubeErrorCode(reason string) platformapi.ErrorCode {
+	if code, ok := youtubeErrorCodes[reason]; ok {
+		return code
+	}
+	return platformapi.ErrorCodeUnknown
+}
+
+// youtubeError classifies an unsuccessful Google API response
+// The Data API returns {"error":{"code":403,"message":"...","errors":[{"reason":"quotaExceeded",...}]}};
+// the OAuth endpoints return {"error":"...","error_description":"..."}
+func youtubeError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Error            json.RawMessage `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	var apiError struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && len(parsed.Error) > 0 {
+		if json.Unmarshal(parsed.Error, &platformCode) == nil {
+			message = parsed.ErrorDescription
+		} else if json.Unmarshal(parsed.Error, &apiError) == nil {
+			message = apiError.Message
+			if len(apiError.Errors) > 0 {
+				platformCode = apiError.Errors[0].Reason
+			}
+		}
+	}
+
+	code, ok := youtubeErrorCodes[platformCode]
+	if !ok {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformYouTube, code, resp.StatusCode, platformCode, message)
+	err.RetryAt = platformapi.RetryAfter(resp.Header)
+	return err
+}
+
+// linkedinErrorCodes maps LinkedIn error codes to error codes
+// https://learn.microsoft.com/en-us/linkedin/shared/api-guide/concepts/error-handling
+var linkedinErrorCodes = map[string]platformapi.ErrorCode{
+	"EXPIRED_ACCESS_TOKEN": platformapi.ErrorCodeAuthExpired,
+	"REVOKED_ACCESS_TOKEN": platformapi.ErrorCodeAuthExpired,
+	"INVALID_ACCESS_TOKEN": platformapi.ErrorCodeAuthExpired,
+	"invalid_grant":        platformapi.ErrorCodeAuthExpired,
+	"ACCESS_DENIED":        platformapi.ErrorCodeInsufficientScope,
+	"TOO_MANY_REQUESTS":    platformapi.ErrorCodeRateLimited,
+	"DUPLICATE_POST":       platformapi.ErrorCodeDuplicateContent,
+}
+
+// linkedinError classifies an unsuccessful LinkedIn response
+// The REST API returns {"status":403,"serviceErrorCode":100,"code":"ACCESS_DENIED","message":"..."};
+// the OAuth endpoints return {"error":"...","error_description":"..."}
+func linkedinError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Code             string `json:"code"`
+		Message          string `json:"message"`
+		ServiceErrorCode int    `json:"serviceErrorCode"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil {
+		switch {
+		case parsed.Message != "":
+			platformCode, message = parsed.Code, parsed.Message
+		case parsed.Error != "":
+			platformCode, message = parsed.Error, parsed.ErrorDescription
+		}
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	lower := strings.ToLower(message)
+	if errorCode, ok := linkedinErrorCodes[platformCode]; ok {
+		code = errorCode
+	} else if strings.Contains(lower, "duplicate") {
+		code = platformapi.ErrorCodeDuplicateContent
+	} else if resp.StatusCode == http.StatusUnprocessableEntity && (strings.Contains(lower, "media") || strings.Contains(lower, "image") || strings.Contains(lower, "video")) {
+		code = platformapi.ErrorCodeMediaRejected
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformLinkedIn, code, resp.StatusCode, platformCode, message)
+	err.RetryAt = platformapi.RetryAfter(resp.Header)
+	return err
+}
+
+// mastodonError classifies an unsuccessful response from a Mastodon instance
+// The REST API returns {"error":"..."}, with the failed validations in the message of 422
+// responses; the OAuth endpoints return {"error":"...","error_description":"..."}
+func mastodonError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		message = parsed.Error
+		if parsed.ErrorDescription != "" {
+			platformCode, message = parsed.Error, parsed.ErrorDescription
+		}
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	lower := strings.ToLower(message)
+	switch {
+	case platformCode == "invalid_grant" || platformCode == "invalid_token":
+		code = platformapi.ErrorCodeAuthExpired
+	case platformCode == "invalid_scope" || strings.Contains(lower, "outside the authorized scopes"):
+		code = platformapi.ErrorCodeInsufficientScope
+	case resp.StatusCode == http.StatusForbidden && (strings.Contains(lower, "disabled") || strings.Contains(lower, "suspended") || strings.Contains(lower, "silenced")):
+		code = platformapi.ErrorCodeContentPolicy
+	case resp.StatusCode == http.StatusUnprocessableEntity && (strings.Contains(lower, "media") || strings.Contains(lower, "file") || strings.Contains(lower, "image") || strings.Contains(lower, "video") || strings.Contains(lower, "processing")):
+		code = platformapi.ErrorCodeMediaRejected
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		code = platformapi.ErrorCodeContentPolicy // Failed validations, e.g. a status over the character limit
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformMastodon, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		// Mastodon reports when the rate limit window resets as an ISO 8601 timestamp
+		if limit, ok := mastodonRateLimit(resp.Header); ok {
+			err.RetryAt = limit.ResetAt
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// observeMastodonRateLimit reports the rate limit in the headers of a Mastodon response to the observer of ctx
+func observeMastodonRateLimit(ctx context.Context, endpoint string, header http.Header) {
+	if limit, ok := mastodonRateLimit(header); ok {
+		platformapi.ObserveRateLimit(ctx, endpoint, limit)
+	}
+}
+
+// mastodonRateLimit parses the X-RateLimit-Limit, -Remaining and -Reset headers
+func mastodonRateLimit(header http.Header) (platformapi.RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	reset, err := time.Parse(time.RFC3339Nano, header.Get("X-RateLimit-Reset"))
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	return platformapi.RateLimit{Limit: limit, Remaining: remaining, ResetAt: reset}, true
+}
+
+// blueskyErrorCodes maps XRPC error names to error codes
+// https://atproto.com/specs/xrpc#error-responses
+var blueskyErrorCodes = map[string]platformapi.ErrorCode{
+	"AuthenticationRequired": platformapi.ErrorCodeAuthExpired, // Wrong identifier or app password
+	"ExpiredToken":           platformapi.ErrorCodeAuthExpired,
+	"InvalidToken":           platformapi.ErrorCodeAuthExpired,
+	"AccountTakedown":        platformapi.ErrorCodeContentPolicy,
+	"AccountDeactivated":     platformapi.ErrorCodeAuthExpired,
+	"RateLimitExceeded":      platformapi.ErrorCodeRateLimited,
+	"BlobTooLarge":           platformapi.ErrorCodeMediaRejected,
+	"InvalidMimeType":        platformapi.ErrorCodeMediaRejected,
+	"InvalidSwap":            platformapi.ErrorCodeTransient, // The repo changed while writing; writing again succeeds
+}
+
+// blueskyError classifies an unsuccessful XRPC response
+// XRPC errors look like {"error":"ExpiredToken","message":"Token has expired"}
+func blueskyError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		platformCode, message = parsed.Error, parsed.Error
+		if parsed.Message != "" {
+			message = parsed.Message
+		}
+	}
+
+	code, ok := blueskyErrorCodes[platformCode]
+	if !ok {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+		// Records that fail validation, like a post over the length limit, are rejected with InvalidRequest
+		if platformCode == "InvalidRequest" && strings.Contains(message, "Invalid app.bsky.feed.post record") {
+			code = platformapi.ErrorCodeContentPolicy
+		}
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformBluesky, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		if limit, ok := blueskyRateLimit(resp.Header); ok {
+			err.RetryAt = limit.ResetAt
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// observeBlueskyRateLimit reports the rate limit in the headers of an XRPC response to the observer of ctx
+func observeBlueskyRateLimit(ctx context.Context, endpoint string, header http.Header) {
+	if limit, ok := blueskyRateLimit(header); ok {
+		platformapi.ObserveRateLimit(ctx, endpoint, limit)
+	}
+}
+
+// blueskyRateLimit parses the RateLimit-Limit, -Remaining and -Reset headers of a PDS
+// The reset is a Unix timestamp in seconds
+func blueskyRateLimit(header http.Header) (platformapi.RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get("RateLimit-Limit"))
+	return platformapi.RateLimit{Limit: limit, Remaining: remaining, ResetAt: time.Unix(reset, 0)}, true
+}
+
+// pinterestErrorCodes maps Pinterest API error codes to error codes
+// https://developers.pinterest.com/docs/reference/errors/
+var pinterestErrorCodes = map[int]platformapi.ErrorCode{
+	2:    platformapi.ErrorCodeAuthExpired,       // Authentication failed
+	3:    platformapi.ErrorCodeInsufficientScope, // Authorization failed
+	8:    platformapi.ErrorCodeRateLimited,       // Too many requests
+	29:   platformapi.ErrorCodeInsufficientScope, // The board belongs to someone else
+	2899: platformapi.ErrorCodeMediaRejected,     // The image could not be fetched from its URL
+	2902: platformapi.ErrorCodeContentPolicy,     // The destination link is blocked
+	2903: platformapi.ErrorCodeMediaRejected,     // The media upload isn't finished
+}
+
+// pinterestError classifies an unsuccessful Pinterest response
+// The v5 API, including its token endpoint, returns {"code":2,"message":"Authentication failed."}
+func pinterestError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		platformCode, message = strconv.Itoa(parsed.Code), parsed.Message
+	}
+
+	code, ok := pinterestErrorCodes[parsed.Code]
+	if !ok {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+		lower := strings.ToLower(message)
+		switch {
+		case strings.Contains(lower, "refresh token") || strings.Contains(lower, "invalid_grant"):
+			code = platformapi.ErrorCodeAuthExpired
+		case resp.StatusCode == http.StatusBadRequest && (strings.Contains(lower, "image") || strings.Contains(lower, "video") || strings.Contains(lower, "media")):
+			code = platformapi.ErrorCodeMediaRejected
+		}
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformPinterest, code, resp.StatusCode, platformCode, message)
+	err.RetryAt = platformapi.RetryAfter(resp.Header)
+	return err
+}
+
+// redditError classifies an unsuccessful Reddit response
+// Most endpoints return {"message":"Forbidden","error":403}, with a reason for subreddits that
+// can't be viewed; the token endpoint returns {"error":"invalid_grant"}
+func redditError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+		Reason  string          `json:"reason"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil {
+		var errorName string
+		if json.Unmarshal(parsed.Error, &errorName) == nil {
+			platformCode = errorName
+		}
+		if parsed.Reason != "" {
+			platformCode = parsed.Reason
+		}
+		if parsed.Message != "" {
+			message = parsed.Message
+		} else if platformCode != "" {
+			message = platformCode
+		}
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	switch platformCode {
+	case "invalid_grant":
+		code = platformapi.ErrorCodeAuthExpired
+	case "private", "banned", "quarantined", "gold_only":
+		code = platformapi.ErrorCodeContentPolicy // The account may not view the subreddit
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformReddit, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		if limit, ok := redditRateLimit(resp.Header); ok {
+			err.RetryAt = limit.ResetAt
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// redditSubmitErrorCodes maps the error names of POST /api/submit and /api/comment to error codes
+// Other SUBMIT_VALIDATION_* errors break a post requirement of the subreddit
+var redditSubmitErrorCodes = map[string]platformapi.ErrorCode{
+	"USER_REQUIRED":                      platformapi.ErrorCodeAuthExpired,
+	"RATELIMIT":                          platformapi.ErrorCodeRateLimited, // The account posts too often
+	"QUOTA_FILLED":                       platformapi.ErrorCodeRateLimited,
+	"ALREADY_SUB":                        platformapi.ErrorCodeDuplicateContent, // The link was already submitted to the subreddit
+	"NO_SELFS":                           platformapi.ErrorCodeContentPolicy,
+	"NO_LINKS":                           platformapi.ErrorCodeContentPolicy,
+	"SUBREDDIT_NOTALLOWED":               platformapi.ErrorCodeContentPolicy,
+	"SUBREDDIT_NOEXIST":                  platformapi.ErrorCodeContentPolicy,
+	"BANNED_FROM_SUBREDDIT":              platformapi.ErrorCodeContentPolicy,
+	"DOMAIN_BANNED":                      platformapi.ErrorCodeContentPolicy,
+	"BAD_URL":                            platformapi.ErrorCodeContentPolicy,
+	"TOO_LONG":                           platformapi.ErrorCodeContentPolicy,
+	"SUBMIT_VALIDATION_FLAIR_REQUIRED":   platformapi.ErrorCodeContentPolicy,
+	"SUBMIT_VALIDATION_BODY_REQUIRED":    platformapi.ErrorCodeContentPolicy,
+	"SUBMIT_VALIDATION_BODY_NOT_ALLOWED": platformapi.ErrorCodeContentPolicy,
+	"SUBMIT_VALIDATION_TITLE_BLACKLISTED_STRING": platformapi.ErrorCodeContentPolicy,
+}
+
+// redditFormError classifies the errors Reddit reports in a successful response to a form
+// submitted with api_type=json: {"json":{"errors":[["RATELIMIT","take a break","ratelimit"]],"ratelimit":540.2}}
+// Only the first error is classified; ratelimit is how many seconds until the account may post again
+func redditFormError(statusCode int, errors [][]interface{}, ratelimit float64) error {
+	var messages []string
+	var platformCode string
+	for _, entry := range errors {
+		// Entries are [name, message, field], any of which may be null
+		var name, message string
+		if len(entry) > 0 && entry[0] != nil {
+			name = fmt.Sprint(entry[0])
+		}
+		if len(entry) > 1 && entry[1] != nil {
+			message = fmt.Sprint(entry[1])
+		}
+		if message == "" {
+			message = name
+		}
+		if message == "" {
+			continue
+		}
+		if platformCode == "" {
+			platformCode = name
+		}
+		messages = append(messages, message)
+	}
+
+	code, ok := redditSubmitErrorCodes[platformCode]
+	switch {
+	case ok:
+	case strings.HasPrefix(platformCode, "SUBMIT_VALIDATION_"):
+		code = platformapi.ErrorCodeContentPolicy // A post requirement of the subreddit
+	default:
+		code = platformapi.ErrorCodeUnknown
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformReddit, code, statusCode, platformCode, strings.Join(messages, "; "))
+	if code == platformapi.ErrorCodeRateLimited && ratelimit > 0 {
+		err.RetryAt = time.Now().Add(time.Duration(ratelimit * float64(time.Second)))
+	}
+	return err
+}
+
+// observeRedditRateLimit reports the rate limit in the headers of a Reddit response to the observer of ctx
+func observeRedditRateLimit(ctx context.Context, endpoint string, header http.Header) {
+	if limit, ok := redditRateLimit(header); ok {
+		platformapi.ObserveRateLimit(ctx, endpoint, limit)
+	}
+}
+
+// redditRateLimit parses the X-Ratelimit-Used, -Remaining and -Reset headers
+// Remaining is a decimal, and the reset is in seconds from now
+func redditRateLimit(header http.Header) (platformapi.RateLimit, bool) {
+	remaining, err := strconv.ParseFloat(header.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	reset, err := strconv.Atoi(header.Get("X-Ratelimit-Reset"))
+	if err != nil {
+		return platformapi.RateLimit{}, false
+	}
+	used, _ := strconv.Atoi(header.Get("X-Ratelimit-Used"))
+	return platformapi.RateLimit{
+		Limit:     used + int(remaining),
+		Remaining: int(remaining),
+		ResetAt:   time.Now().Add(time.Duration(reset) * time.Second),
+	}, true
+}
+
+// telegramError classifies an unsuccessful Bot API response
+// Bot API errors look like {"ok":false,"error_code":400,"description":"Bad Request: ...","parameters":{...}},
+// where the description is the only thing that tells errors of the same status apart
+// https://core.telegram.org/bots/api#making-requests
+func telegramError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		ErrorCode   int    `json:"error_code"`
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Description != "" {
+		platformCode, message = strconv.Itoa(parsed.ErrorCode), parsed.Description
+	}
+
+	code := platformapi.CodeForStatus(resp.StatusCode)
+	lower := strings.ToLower(message)
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		code = platformapi.ErrorCodeAuthExpired // The bot token was revoked
+	case resp.StatusCode == http.StatusForbidden || strings.Contains(lower, "chat not found") || strings.Contains(lower, "not enough rights") ||
+		strings.Contains(lower, "administrator rights"):
+		code = platformapi.ErrorCodeInsufficientScope // The bot was removed from the chat or may not post in it
+	case strings.Contains(lower, "http url") || strings.Contains(lower, "web page content") || strings.Contains(lower, "file is too big") ||
+		strings.Contains(lower, "image_process_failed") || strings.Contains(lower, "photo_invalid_dimensions") || strings.Contains(lower, "remote file"):
+		code = platformapi.ErrorCodeMediaRejected
+	case strings.Contains(lower, "too long"):
+		code = platformapi.ErrorCodeContentPolicy
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformTelegram, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		if parsed.Parameters.RetryAfter > 0 {
+			err.RetryAt = time.Now().Add(time.Duration(parsed.Parameters.RetryAfter) * time.Second)
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// discordErrorCodes maps Discord JSON error codes to error codes
+// https://discord.com/developers/docs/topics/opcodes-and-status-codes#json-json-error-codes
+var discordErrorCodes = map[int]platformapi.ErrorCode{
+	10015: platformapi.ErrorCodeAuthExpired,       // Unknown webhook: it was deleted
+	50027: platformapi.ErrorCodeAuthExpired,       // Invalid webhook token: it was regenerated
+	40005: platformapi.ErrorCodeMediaRejected,     // Request entity too large
+	50035: platformapi.ErrorCodeContentPolicy,     // Invalid form body, e.g. a message over the character limit
+	50006: platformapi.ErrorCodeContentPolicy,     // Cannot send an empty message
+	20016: platformapi.ErrorCodeRateLimited,       // Slowmode
+	50013: platformapi.ErrorCodeInsufficientScope, // Missing permissions
+}
+
+// discordError classifies an unsuccessful Discord response
+// Discord errors look like {"code":10015,"message":"Unknown Webhook"}; rate limits are reported as
+// {"message":"...","retry_after":1.5,"global":false} with a Retry-After header
+func discordError(resp *http.Response, body []byte) error {
+	var parsed struct {
+		Code       int     `json:"code"`
+		Message    string  `json:"message"`
+		RetryAfter float64 `json:"retry_after"`
+	}
+
+	platformCode, message := "", string(body)
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		message = parsed.Message
+		if parsed.Code != 0 {
+			platformCode = strconv.Itoa(parsed.Code)
+		}
+	}
+
+	code, ok := discordErrorCodes[parsed.Code]
+	if !ok {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+		if resp.StatusCode == http.StatusRequestEntityTooLarge {
+			code = platformapi.ErrorCodeMediaRejected
+		}
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformDiscord, code, resp.StatusCode, platformCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		if parsed.RetryAfter > 0 {
+			err.RetryAt = time.Now().Add(time.Duration(parsed.RetryAfter * float64(time.Second)))
+		} else {
+			err.RetryAt = platformapi.RetryAfter(resp.Header)
+		}
+	}
+	return err
+}
+
+// slackWebhookErrorCodes maps the errors of Slack incoming webhooks to error codes
+// https://api.slack.com/messaging/webhooks#handling_errors
+var slackWebhookErrorCodes = map[string]platformapi.ErrorCode{
+	"invalid_token":                     platformapi.ErrorCodeAuthExpired, // The webhook was removed
+	"no_service":                        platformapi.ErrorCodeAuthExpired,
+	"no_team":                           platformapi.ErrorCodeAuthExpired,
+	"team_disabled":                     platformapi.ErrorCodeAuthExpired,
+	"channel_not_found":                 platformapi.ErrorCodeInsufficientScope,
+	"channel_is_archived":               platformapi.ErrorCodeInsufficientScope,
+	"action_prohibited":                 platformapi.ErrorCodeInsufficientScope,
+	"posting_to_general_channel_denied": platformapi.ErrorCodeInsufficientScope,
+	"rate_limited":                      platformapi.ErrorCodeRateLimited,
+	// Slack downloads the images of image blocks when the message is sent; an image it can't
+	// fetch makes the blocks invalid
+	"invalid_blocks": platformapi.ErrorCodeMediaRejected,
+}
+
+// slackWebhookError classifies an unsuccessful response of a Slack incoming webhook
+// Incoming webhooks answer with a plain text error, like invalid_token
+func slackWebhookError(resp *http.Response, body []byte) error {
+	message := strings.TrimSpace(string(body))
+
+	code, ok := slackWebhookErrorCodes[message]
+	if !ok {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformSlack, code, resp.StatusCode, message, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		err.RetryAt = platformapi.RetryAfter(resp.Header)
+	}
+	return err
+}
+
+// webhookErrorCodes are the error codes endpoints may classify their own failures with
+var webhookErrorCodes = map[platformapi.ErrorCode]bool{
+	platformapi.ErrorCodeAuthExpired:       true,
+	platformapi.ErrorCodeInsufficientScope: true,
+	platformapi.ErrorCodeRateLimited:       true,
+	platformapi.ErrorCodeMediaRejected:     true,
+	platformapi.ErrorCodeContentPolicy:     true,
+	platformapi.ErrorCodeDuplicateContent:  true,
+	platformapi.ErrorCodeTransient:         true,
+}
+
+// webhookErrorMessageLimit is how much of an endpoint's error is kept
+const webhookErrorMessageLimit = 500
+
+// webhookError classifies an unsuccessful response of a user's endpoint
+// Endpoints may answer {"error":"...","error_code":"content_policy"} with one of the
+// platformapi.ErrorCode values; other responses are classified by their status, with 401
+// meaning the endpoint rejected the signature. Redirects are not followed and fail the delivery
+func webhookError(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 300 && resp.StatusCode <= 399 {
+		// The location isn't included, as it may contain a token
+		return platformapi.NewPlatformError(models.PlatformWebhook, platformapi.ErrorCodeUnknown, resp.StatusCode, "",
+			"the endpoint redirected to another URL; connect the URL it redirects to instead")
+	}
+
+	var parsed struct {
+		Error     string `json:"error"`
+		ErrorCode string `json:"error_code"`
+	}
+
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		message = parsed.Error
+	}
+	if len(message) > webhookErrorMessageLimit {
+		message = strings.ToValidUTF8(message[:webhookErrorMessageLimit], "") + "..."
+	}
+
+	code := platformapi.ErrorCode(parsed.ErrorCode)
+	if !webhookErrorCodes[code] {
+		code = platformapi.CodeForStatus(resp.StatusCode)
+		if resp.StatusCode == http.StatusConflict {
+			code = platformapi.ErrorCodeDuplicateContent
+		}
+	}
+
+	err := platformapi.NewPlatformError(models.PlatformWebhook, code, resp.StatusCode, parsed.ErrorCode, message)
+	if code == platformapi.ErrorCodeRateLimited {
+		err.RetryAt = platformapi.RetryAfter(resp.Header)
+	}
+	return err
+}
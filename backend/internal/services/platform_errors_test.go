@@ -0,0 +1,329 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+func TestPlatformErrorParsers(t *testing.T) {
+	tests := []struct {
+		name             string
+		parse            func(*http.Response, []byte) error
+		status           int
+		body             string
+		wantPlatform     models.Platform
+		wantCode         platformapi.ErrorCode
+		wantPlatformCode string
+		wantMessage      string
+	}{
+		// TikTok
+		{name: "tiktok api error", parse: tiktokError, status: 403, body: `{"error":{"code":"spam_risk_too_many_posts","message":"slow down","log_id":"1"}}`,
+			wantPlatform: models.PlatformTikTok, wantCode: platformapi.ErrorCodeRateLimited, wantPlatformCode: "spam_risk_too_many_posts", wantMessage: "slow down"},
+		{name: "tiktok oauth error", parse: tiktokError, status: 400, body: `{"error":"invalid_grant","error_description":"Refresh token is invalid"}`,
+			wantPlatform: models.PlatformTikTok, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_grant", wantMessage: "Refresh token is invalid"},
+		{name: "tiktok unknown code falls back to the status", parse: tiktokError, status: 503, body: `{"error":{"code":"something_new","message":"down"}}`,
+			wantPlatform: models.PlatformTikTok, wantCode: platformapi.ErrorCodeTransient, wantPlatformCode: "something_new", wantMessage: "down"},
+		{name: "tiktok ok error code", parse: tiktokError, status: 500, body: `{"error":{"code":"ok","message":""}}`,
+			wantPlatform: models.PlatformTikTok, wantCode: platformapi.ErrorCodeTransient, wantPlatformCode: "ok"},
+		{name: "tiktok html body", parse: tiktokError, status: 502, body: `<html>Bad Gateway</html>`,
+			wantPlatform: models.PlatformTikTok, wantCode: platformapi.ErrorCodeTransient, wantMessage: "<html>Bad Gateway</html>"},
+
+		// X
+		{name: "x problem details", parse: xError, status: 403, body: `{"title":"Forbidden","detail":"You are not permitted to perform this action.","type":"about:blank","status":403}`,
+			wantPlatform: models.PlatformX, wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "Forbidden", wantMessage: "You are not permitted to perform this action."},
+		{name: "x duplicate", parse: xError, status: 403, body: `{"detail":"You are not allowed to create a Tweet with duplicate content.","title":"Forbidden"}`,
+			wantPlatform: models.PlatformX, wantCode: platformapi.ErrorCodeDuplicateContent, wantPlatformCode: "Forbidden"},
+		{name: "x v1.1 errors", parse: xError, status: 400, body: `{"errors":[{"code":324,"message":"Invalid media id"}]}`,
+			wantPlatform: models.PlatformX, wantCode: platformapi.ErrorCodeMediaRejected, wantPlatformCode: "324", wantMessage: "Invalid media id"},
+		{name: "x expired refresh token", parse: xError, status: 400, body: `{"error":"invalid_request","error_description":"Value passed for the token was invalid."}`,
+			wantPlatform: models.PlatformX, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_request"},
+		{name: "x empty errors", parse: xError, status: 403, body: `{"errors":[]}`,
+			wantPlatform: models.PlatformX, wantCode: platformapi.ErrorCodeInsufficientScope, wantMessage: `{"errors":[]}`},
+
+		// Graph API
+		{name: "instagram expired token", parse: instagramError, status: 400, body: `{"error":{"message":"Session has expired","type":"OAuthException","code":190,"error_subcode":463}}`,
+			wantPlatform: models.PlatformInstagram, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "190/463", wantMessage: "Session has expired"},
+		{name: "threads publishing limit", parse: threadsError, status: 400, body: `{"error":{"message":"limit","code":9}}`,
+			wantPlatform: models.PlatformThreads, wantCode: platformapi.ErrorCodeRateLimited, wantPlatformCode: "9"},
+		{name: "facebook permission range", parse: facebookError, status: 403, body: `{"error":{"message":"needs pages_manage_posts","code":200}}`,
+			wantPlatform: models.PlatformFacebook, wantCode: platformapi.ErrorCodeInsufficientScope, wantPlatformCode: "200"},
+		{name: "facebook code just past the permission range", parse: facebookError, status: 400, body: `{"error":{"message":"odd","code":300}}`,
+			wantPlatform: models.PlatformFacebook, wantCode: platformapi.ErrorCodeUnknown, wantPlatformCode: "300"},
+		{name: "instagram token endpoint", parse: instagramError, status: 400, body: `{"error_type":"OAuthException","code":400,"error_message":"Invalid authorization code"}`,
+			wantPlatform: models.PlatformInstagram, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "OAuthException", wantMessage: "Invalid authorization code"},
+		{name: "graph api empty body", parse: facebookError, status: 500, body: ``,
+			wantPlatform: models.PlatformFacebook, wantCode: platformapi.ErrorCodeTransient},
+
+		// YouTube
+		{name: "youtube quota", parse: youtubeError, status: 403, body: `{"error":{"code":403,"message":"quota","errors":[{"reason":"quotaExceeded"}]}}`,
+			wantPlatform: models.PlatformYouTube, wantCode: platformapi.ErrorCodeRateLimited, wantPlatformCode: "quotaExceeded", wantMessage: "quota"},
+		{name: "youtube oauth error", parse: youtubeError, status: 400, body: `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`,
+			wantPlatform: models.PlatformYouTube, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_grant", wantMessage: "Token has been expired or revoked."},
+		{name: "youtube error without reasons", parse: youtubeError, status: 404, body: `{"error":{"code":404,"message":"Not Found","errors":[]}}`,
+			wantPlatform: models.PlatformYouTube, wantCode: platformapi.ErrorCodeUnknown, wantMessage: "Not Found"},
+
+		// LinkedIn
+		{name: "linkedin revoked token", parse: linkedinError, status: 401, body: `{"status":401,"serviceErrorCode":65601,"code":"REVOKED_ACCESS_TOKEN","message":"The token used in the request has been revoked"}`,
+			wantPlatform: models.PlatformLinkedIn, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "REVOKED_ACCESS_TOKEN"},
+		{name: "linkedin duplicate", parse: linkedinError, status: 422, body: `{"status":422,"message":"Content is a duplicate of urn:li:share:1"}`,
+			wantPlatform: models.PlatformLinkedIn, wantCode: platformapi.ErrorCodeDuplicateContent},
+		{name: "linkedin media", parse: linkedinError, status: 422, body: `{"status":422,"message":"Image asset is not ready"}`,
+			wantPlatform: models.PlatformLinkedIn, wantCode: platformapi.ErrorCodeMediaRejected},
+
+		// Mastodon
+		{name: "mastodon revoked token", parse: mastodonError, status: 401, body: `{"error":"invalid_token","error_description":"The access token was revoked"}`,
+			wantPlatform: models.PlatformMastodon, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_token"},
+		{name: "mastodon validation", parse: mastodonError, status: 422, body: `{"error":"Validation failed: Text character limit of 500 exceeded"}`,
+			wantPlatform: models.PlatformMastodon, wantCode: platformapi.ErrorCodeContentPolicy, wantMessage: "Validation failed: Text character limit of 500 exceeded"},
+		{name: "mastodon media still processing", parse: mastodonError, status: 422, body: `{"error":"Cannot attach files that have not finished processing"}`,
+			wantPlatform: models.PlatformMastodon, wantCode: platformapi.ErrorCodeMediaRejected},
+		{name: "mastodon suspended", parse: mastodonError, status: 403, body: `{"error":"Your login is currently disabled"}`,
+			wantPlatform: models.PlatformMastodon, wantCode: platformapi.ErrorCodeContentPolicy},
+
+		// Bluesky
+		{name: "bluesky expired token", parse: blueskyError, status: 400, body: `{"error":"ExpiredToken","message":"Token has expired"}`,
+			wantPlatform: models.PlatformBluesky, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "ExpiredToken", wantMessage: "Token has expired"},
+		{name: "bluesky invalid record", parse: blueskyError, status: 400, body: `{"error":"InvalidRequest","message":"Invalid app.bsky.feed.post record: Record/text must not be longer than 300 graphemes"}`,
+			wantPlatform: models.PlatformBluesky, wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "InvalidRequest"},
+		{name: "bluesky error without message", parse: blueskyError, status: 400, body: `{"error":"BlobTooLarge"}`,
+			wantPlatform: models.PlatformBluesky, wantCode: platformapi.ErrorCodeMediaRejected, wantPlatformCode: "BlobTooLarge", wantMessage: "BlobTooLarge"},
+
+		// Pinterest
+		{name: "pinterest board of someone else", parse: pinterestError, status: 403, body: `{"code":29,"message":"You are not permitted to access that resource."}`,
+			wantPlatform: models.PlatformPinterest, wantCode: platformapi.ErrorCodeInsufficientScope, wantPlatformCode: "29"},
+		{name: "pinterest refresh token", parse: pinterestError, status: 400, body: `{"code":1,"message":"Invalid refresh token"}`,
+			wantPlatform: models.PlatformPinterest, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "1"},
+		{name: "pinterest code without message", parse: pinterestError, status: 401, body: `{"code":0}`,
+			wantPlatform: models.PlatformPinterest, wantCode: platformapi.ErrorCodeAuthExpired, wantMessage: `{"code":0}`},
+
+		// Reddit
+		{name: "reddit private subreddit", parse: redditError, status: 403, body: `{"reason":"private","message":"Forbidden","error":403}`,
+			wantPlatform: models.PlatformReddit, wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "private", wantMessage: "Forbidden"},
+		{name: "reddit token endpoint", parse: redditError, status: 400, body: `{"error":"invalid_grant"}`,
+			wantPlatform: models.PlatformReddit, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_grant", wantMessage: "invalid_grant"},
+		{name: "reddit numeric error", parse: redditError, status: 404, body: `{"message":"Not Found","error":404}`,
+			wantPlatform: models.PlatformReddit, wantCode: platformapi.ErrorCodeUnknown, wantMessage: "Not Found"},
+
+		// Telegram
+		{name: "telegram chat not found", parse: telegramError, status: 400, body: `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`,
+			wantPlatform: models.PlatformTelegram, wantCode: platformapi.ErrorCodeInsufficientScope, wantPlatformCode: "400", wantMessage: "Bad Request: chat not found"},
+		{name: "telegram revoked token", parse: telegramError, status: 401, body: `{"ok":false,"error_code":401,"description":"Unauthorized"}`,
+			wantPlatform: models.PlatformTelegram, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "401"},
+		{name: "telegram media", parse: telegramError, status: 400, body: `{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`,
+			wantPlatform: models.PlatformTelegram, wantCode: platformapi.ErrorCodeMediaRejected, wantPlatformCode: "400"},
+		{name: "telegram caption too long", parse: telegramError, status: 400, body: `{"ok":false,"error_code":400,"description":"Bad Request: message caption is too long"}`,
+			wantPlatform: models.PlatformTelegram, wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "400"},
+
+		// Discord
+		{name: "discord deleted webhook", parse: discordError, status: 404, body: `{"code":10015,"message":"Unknown Webhook"}`,
+			wantPlatform: models.PlatformDiscord, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "10015", wantMessage: "Unknown Webhook"},
+		{name: "discord too large without a code", parse: discordError, status: 413, body: `request entity too large`,
+			wantPlatform: models.PlatformDiscord, wantCode: platformapi.ErrorCodeMediaRejected, wantMessage: "request entity too large"},
+
+		// Slack
+		{name: "slack removed webhook", parse: slackWebhookError, status: 403, body: "invalid_token\n",
+			wantPlatform: models.PlatformSlack, wantCode: platformapi.ErrorCodeAuthExpired, wantPlatformCode: "invalid_token", wantMessage: "invalid_token"},
+		{name: "slack unknown error", parse: slackWebhookError, status: 400, body: "no_text",
+			wantPlatform: models.PlatformSlack, wantCode: platformapi.ErrorCodeUnknown, wantPlatformCode: "no_text", wantMessage: "no_text"},
+
+		// Webhook
+		{name: "webhook classified error", parse: webhookError, status: 400, body: `{"error":"Banned word","error_code":"content_policy"}`,
+			wantPlatform: models.PlatformWebhook, wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "content_policy", wantMessage: "Banned word"},
+		{name: "webhook unsupported error code", parse: webhookError, status: 400, body: `{"error":"nope","error_code":"unknown"}`,
+			wantPlatform: models.PlatformWebhook, wantCode: platformapi.ErrorCodeUnknown, wantPlatformCode: "unknown", wantMessage: "nope"},
+		{name: "webhook conflict", parse: webhookError, status: 409, body: `already have it`,
+			wantPlatform: models.PlatformWebhook, wantCode: platformapi.ErrorCodeDuplicateContent, wantMessage: "already have it"},
+		{name: "webhook rejected signature", parse: webhookError, status: 401, body: ``,
+			wantPlatform: models.PlatformWebhook, wantCode: platformapi.ErrorCodeAuthExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			err := tt.parse(resp, []byte(tt.body))
+
+			var platformErr *platformapi.PlatformError
+			if !errors.As(err, &platformErr) {
+				t.Fatalf("error = %v, want a *PlatformError", err)
+			}
+			if platformErr.Platform != tt.wantPlatform || platformErr.Code != tt.wantCode || platformErr.StatusCode != tt.status {
+				t.Errorf("error = %s/%s/%d, want %s/%s/%d", platformErr.Platform, platformErr.Code, platformErr.StatusCode,
+					tt.wantPlatform, tt.wantCode, tt.status)
+			}
+			if platformErr.PlatformCode != tt.wantPlatformCode {
+				t.Errorf("platform code = %q, want %q", platformErr.PlatformCode, tt.wantPlatformCode)
+			}
+			if tt.wantMessage != "" && platformErr.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", platformErr.Message, tt.wantMessage)
+			}
+			if platformErr.Code != platformapi.ErrorCodeRateLimited && !platformErr.RetryAt.IsZero() {
+				t.Errorf("retry at = %v, want none for %s", platformErr.RetryAt, platformErr.Code)
+			}
+		})
+	}
+}
+
+func TestPlatformErrorRetryAt(t *testing.T) {
+	reset := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		parse     func(*http.Response, []byte) error
+		status    int
+		header    http.Header
+		body      string
+		wantAt    time.Time     // Expected reset time, if the response gives one
+		wantDelay time.Duration // Expected delay from now, if the response gives one
+	}{
+		{name: "x reset header", parse: xError, status: 429,
+			header: http.Header{"X-Rate-Limit-Reset": {"1772368200"}}, wantAt: reset},
+		{name: "x malformed reset falls back to retry-after", parse: xError, status: 429,
+			header: http.Header{"X-Rate-Limit-Reset": {"soon"}, "Retry-After": {"60"}}, wantDelay: time.Minute},
+		{name: "mastodon reset header", parse: mastodonError, status: 429,
+			header: http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"2026-03-01T12:30:00.000Z"}}, wantAt: reset},
+		{name: "mastodon reset without remaining", parse: mastodonError, status: 429,
+			header: http.Header{"X-Ratelimit-Reset": {"2026-03-01T12:30:00.000Z"}}},
+		{name: "bluesky reset header", parse: blueskyError, status: 429, body: `{"error":"RateLimitExceeded"}`,
+			header: http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"1772368200"}}, wantAt: reset},
+		{name: "reddit reset in seconds", parse: redditError, status: 429,
+			header: http.Header{"X-Ratelimit-Remaining": {"0.0"}, "X-Ratelimit-Reset": {"90"}}, wantDelay: 90 * time.Second},
+		{name: "telegram retry_after", parse: telegramError, status: 429,
+			body: `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 30","parameters":{"retry_after":30}}`, wantDelay: 30 * time.Second},
+		{name: "discord fractional retry_after", parse: discordError, status: 429,
+			body: `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`, wantDelay: 1500 * time.Millisecond},
+		{name: "slack retry-after", parse: slackWebhookError, status: 429, body: "rate_limited",
+			header: http.Header{"Retry-After": {"10"}}, wantDelay: 10 * time.Second},
+		{name: "webhook negative retry-after", parse: webhookError, status: 429,
+			header: http.Header{"Retry-After": {"-10"}}},
+		{name: "tiktok without headers", parse: tiktokError, status: 429, body: `{"error":{"code":"rate_limit_exceeded"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			before := time.Now()
+			err := tt.parse(&http.Response{StatusCode: tt.status, Header: header}, []byte(tt.body))
+			after := time.Now()
+
+			var platformErr *platformapi.PlatformError
+			if !errors.As(err, &platformErr) || platformErr.Code != platformapi.ErrorCodeRateLimited {
+				t.Fatalf("error = %v, want a rate limit", err)
+			}
+			got := platformErr.RetryAt
+			switch {
+			case !tt.wantAt.IsZero():
+				if !got.Equal(tt.wantAt) {
+					t.Errorf("retry at = %v, want %v", got, tt.wantAt)
+				}
+			case tt.wantDelay != 0:
+				if got.Before(before.Add(tt.wantDelay)) || got.After(after.Add(tt.wantDelay)) {
+					t.Errorf("retry at = %v, want %v from now", got, tt.wantDelay)
+				}
+			default:
+				if !got.IsZero() {
+					t.Errorf("retry at = %v, want none", got)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookErrorTruncatesMessage(t *testing.T) {
+	// A multi-byte rune straddles the limit, so the cut must not leave half of it behind
+	body := strings.Repeat("a", webhookErrorMessageLimit-1) + "é" + strings.Repeat("b", 100)
+	err := webhookError(&http.Response{StatusCode: 500, Header: http.Header{}}, []byte(body))
+
+	var platformErr *platformapi.PlatformError
+	if !errors.As(err, &platformErr) {
+		t.Fatalf("error = %v, want a *PlatformError", err)
+	}
+	want := strings.Repeat("a", webhookErrorMessageLimit-1) + "..."
+	if platformErr.Message != want {
+		t.Errorf("message = %q (%d bytes), want the first %d bytes cut at a rune", platformErr.Message, len(platformErr.Message), webhookErrorMessageLimit-1)
+	}
+}
+
+func TestRedditFormError(t *testing.T) {
+	tests := []struct {
+		name             string
+		errors           [][]interface{}
+		ratelimit        float64
+		wantCode         platformapi.ErrorCode
+		wantPlatformCode string
+		wantMessage      string
+		wantDelay        time.Duration
+	}{
+		{name: "rate limit with a delay", errors: [][]interface{}{{"RATELIMIT", "take a break", "ratelimit"}}, ratelimit: 540.5,
+			wantCode: platformapi.ErrorCodeRateLimited, wantPlatformCode: "RATELIMIT", wantMessage: "take a break", wantDelay: 540500 * time.Millisecond},
+		{name: "rate limit without a delay", errors: [][]interface{}{{"RATELIMIT", "take a break"}},
+			wantCode: platformapi.ErrorCodeRateLimited, wantPlatformCode: "RATELIMIT", wantMessage: "take a break"},
+		{name: "required flair", errors: [][]interface{}{{"SUBMIT_VALIDATION_FLAIR_REQUIRED", "Your post must contain post flair.", "flair"}},
+			wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "SUBMIT_VALIDATION_FLAIR_REQUIRED", wantMessage: "Your post must contain post flair."},
+		{name: "other subreddit requirement", errors: [][]interface{}{{"SUBMIT_VALIDATION_MIN_LENGTH", "too short"}},
+			wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "SUBMIT_VALIDATION_MIN_LENGTH", wantMessage: "too short"},
+		{name: "first error classifies, every message kept", errors: [][]interface{}{{"NO_LINKS", "no links"}, {"TOO_LONG", "too long", "title"}},
+			wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "NO_LINKS", wantMessage: "no links; too long"},
+		{name: "null and empty entries skipped", errors: [][]interface{}{{}, {nil}, {nil, nil, "title"}, {"ALREADY_SUB", nil, "url"}},
+			wantCode: platformapi.ErrorCodeDuplicateContent, wantPlatformCode: "ALREADY_SUB", wantMessage: "ALREADY_SUB"},
+		{name: "unknown error", errors: [][]interface{}{{"SOMETHING_NEW", "huh"}},
+			wantCode: platformapi.ErrorCodeUnknown, wantPlatformCode: "SOMETHING_NEW", wantMessage: "huh"},
+		{name: "first named error classifies", errors: [][]interface{}{{nil, "something went wrong"}, {"TOO_LONG", "too long"}},
+			wantCode: platformapi.ErrorCodeContentPolicy, wantPlatformCode: "TOO_LONG", wantMessage: "something went wrong; too long"},
+		{name: "no errors", errors: nil,
+			wantCode: platformapi.ErrorCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			err := redditFormError(http.StatusOK, tt.errors, tt.ratelimit)
+			after := time.Now()
+
+			var platformErr *platformapi.PlatformError
+			if !errors.As(err, &platformErr) {
+				t.Fatalf("error = %v, want a *PlatformError", err)
+			}
+			if platformErr.Code != tt.wantCode || platformErr.PlatformCode != tt.wantPlatformCode || platformErr.Message != tt.wantMessage {
+				t.Errorf("error = %s/%q/%q, want %s/%q/%q", platformErr.Code, platformErr.PlatformCode, platformErr.Message,
+					tt.wantCode, tt.wantPlatformCode, tt.wantMessage)
+			}
+			if tt.wantDelay == 0 {
+				if !platformErr.RetryAt.IsZero() {
+					t.Errorf("retry at = %v, want none", platformErr.RetryAt)
+				}
+			} else if platformErr.RetryAt.Before(before.Add(tt.wantDelay)) || platformErr.RetryAt.After(after.Add(tt.wantDelay)) {
+				t.Errorf("retry at = %v, want %v from now", platformErr.RetryAt, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestErrorCodeLookups(t *testing.T) {
+	tests := []struct {
+		name string
+		got  platformapi.ErrorCode
+		want platformapi.ErrorCode
+	}{
+		{name: "tiktok known", got: TikTokErrorCode("video_pull_failed"), want: platformapi.ErrorCodeMediaRejected},
+		{name: "tiktok empty", got: TikTokErrorCode(""), want: platformapi.ErrorCodeUnknown},
+		{name: "tiktok case matters", got: TikTokErrorCode("SPAM_RISK"), want: platformapi.ErrorCodeUnknown},
+		{name: "youtube rejection reason", got: YouTubeErrorCode("duplicate"), want: platformapi.ErrorCodeDuplicateContent},
+		{name: "youtube failure reason", got: YouTubeErrorCode("uploadAborted"), want: platformapi.ErrorCodeTransient},
+		{name: "youtube empty", got: YouTubeErrorCode(""), want: platformapi.ErrorCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
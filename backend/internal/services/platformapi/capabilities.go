@@ -0,0 +1,78 @@
+package platformapi
+
+import (
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// Media types a platform can publish
+const (
+	MediaKindText     = "text"
+	MediaKindImage    = "image"
+	MediaKindVideo    = "video"
+	MediaKindCarousel = "carousel"
+)
+
+// Setting field types used in SettingField.Type
+const (
+	SettingTypeString = "string"
+	SettingTypeBool   = "bool"
+	SettingTypeInt    = "int"
+	SettingTypeEnum   = "enum"
+)
+
+// Capabilities describes what a platform supports so clients don't have to hard-code it
+// Zero limits mean the platform has no limit (or it is not known)
+type Capabilities struct {
+	Platform    models.Platform `json:"platform"`
+	DisplayName string          `json:"display_name"`
+
+	// InstanceRequired means accounts live on a server the user chooses (e.g. a Mastodon
+	// instance), which has to be given when logging in
+	InstanceRequired bool `json:"instance_required,omitempty"`
+	// PasswordLogin means accounts are connected with an identifier and a password the user
+	// enters (e.g. a Bluesky app password) instead of an OAuth redirect
+	PasswordLogin bool `json:"password_login,omitempty"`
+	// WebhookLogin means a destination is connected with an incoming webhook URL the user
+	// creates on the platform (e.g. a Discord channel webhook) instead of an OAuth redirect
+	WebhookLogin bool `json:"webhook_login,omitempty"`
+	// ConnectOnly means the destination (e.g. a Telegram channel) doesn't identify a user, so it
+	// can only be connected to the account of a logged-in user and never signs anyone in
+	ConnectOnly bool `json:"connect_only,omitempty"`
+
+	// Media
+	MediaTypes    []string `json:"media_types"`     // Kinds of post the platform accepts (text, image, video, carousel)
+	RequiresMedia bool     `json:"requires_media"`  // Whether a post must contain media
+	MaxImages     int      `json:"max_images"`      // Maximum images in one post
+	MaxVideos     int      `json:"max_videos"`      // Maximum videos in one post
+	MaxMediaItems int      `json:"max_media_items"` // Maximum media items of any kind in one post
+	MixedMedia    bool     `json:"mixed_media"`     // Whether images and videos can be combined in one post
+
+	// Text
+	CaptionMaxLength int `json:"caption_max_length"`
+	TitleMaxLength   int `json:"title_max_length,omitempty"`
+
+	// Publishing
+	AsyncPublishing    bool `json:"async_publishing"` // Post is processed by the platform and its status has to be polled
+	SupportsScheduling bool `json:"supports_scheduling"`
+	SupportsDeletion   bool `json:"supports_deletion"`
+	SupportsThreads    bool `json:"supports_threads"`
+	SupportsAnalytics  bool `json:"supports_analytics"`
+
+	// PublishLimit is the platform's cap on posts per account, nil if it has none or only
+	// reports its limits in responses
+	PublishLimit *PublishLimit `json:"publish_limit,omitempty"`
+
+	// Settings is the schema of the platform-specific settings a post can carry
+	Settings []SettingField `json:"settings,omitempty"`
+}
+
+// SettingField describes one platform-specific post setting
+type SettingField struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"` // string, bool, int or enum
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Options     []string    `json:"options,omitempty"`    // Allowed values of an enum
+	MaxLength   int         `json:"max_length,omitempty"` // Maximum length of a string
+}
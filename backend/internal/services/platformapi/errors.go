@@ -0,0 +1,194 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// ErrorCode is a stable, platform-independent classification of a failed platform call
+// It is stored with failed posts and returned as error_code by the post APIs
+type ErrorCode string
+
+const (
+	ErrorCodeAuthExpired       ErrorCode = "auth_expired"       // Token expired or was revoked; the account must be reconnected
+	ErrorCodeInsufficientScope ErrorCode = "insufficient_scope" // The app was not granted a permission the call needs
+	ErrorCodeRateLimited       ErrorCode = "rate_limited"       // Too many requests or posts; retry after RetryAt
+	ErrorCodeMediaRejected     ErrorCode = "media_rejected"     // The platform could not fetch, process or accept the media
+	ErrorCodeContentPolicy     ErrorCode = "content_policy"     // The post or account breaks a platform rule
+	ErrorCodeDuplicateContent  ErrorCode = "duplicate_content"  // The same content was already posted
+	ErrorCodeTransient         ErrorCode = "transient"          // Network error, timeout or platform outage; retrying may work
+	ErrorCodeUnknown           ErrorCode = "unknown"
+)
+
+// userMessages are the messages shown to end users for each error code
+var userMessages = map[ErrorCode]string{
+	ErrorCodeAuthExpired:       "Your %s connection has expired. Please reconnect your account.",
+	ErrorCodeInsufficientScope: "%s did not grant the permissions needed to post. Please reconnect your account and approve all permissions.",
+	ErrorCodeRateLimited:       "%s is limiting how often you can post. Please try again later.",
+	ErrorCodeMediaRejected:     "%s could not process your media. Check that it meets the platform's format, size and duration requirements.",
+	ErrorCodeContentPolicy:     "%s rejected the post because it does not meet the platform's content rules.",
+	ErrorCodeDuplicateContent:  "%s rejected the post because the same content was already posted.",
+	ErrorCodeTransient:         "%s is temporarily unavailable. Please try again in a few minutes.",
+	ErrorCodeUnknown:           "%s could not publish the post.",
+}
+
+// PlatformError is a failed platform call classified into an ErrorCode
+// Message keeps the platform's own wording for logs; UserMessage is what end users see
+type PlatformError struct {
+	Platform     models.Platform
+	Code         ErrorCode
+	StatusCode   int       // HTTP status of the response, 0 if the call got no response
+	PlatformCode string    // The platform's own error code, e.g. spam_risk_too_many_posts
+	Message      string    // The platform's error message or response body
+	RetryAt      time.Time // When a rate limit resets, zero if unknown
+}
+
+func (e *PlatformError) Error() string {
+	detail := e.Message
+	if e.PlatformCode != "" {
+		detail = e.PlatformCode + ": " + detail
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s API error (%s, status %d): %s", e.Platform, e.Code, e.StatusCode, detail)
+	}
+	return fmt.Sprintf("%s API error (%s): %s", e.Platform, e.Code, detail)
+}
+
+// UserMessage returns a message that can be shown to end users
+func (e *PlatformError) UserMessage() string {
+	return UserMessage(e.Platform, e.Code, e.RetryAt)
+}
+
+// Retryable reports whether the same call may succeed if it is made again later
+func (e *PlatformError) Retryable() bool {
+	return e.Code == ErrorCodeRateLimited || e.Code == ErrorCodeTransient
+}
+
+// UserMessage returns the end-user message of an error code
+// For rate limits with a known reset time the message says when to retry
+func UserMessage(platform models.Platform, code ErrorCode, retryAt time.Time) string {
+	format, ok := userMessages[code]
+	if !ok {
+		format = userMessages[ErrorCodeUnknown]
+	}
+	message := fmt.Sprintf(format, platformName(platform))
+	if code == ErrorCodeRateLimited && !retryAt.IsZero() {
+		message = fmt.Sprintf("%s is limiting how often you can post. Please try again after %s.",
+			platformName(platform), retryAt.UTC().Format("15:04 MST"))
+	}
+	return message
+}
+
+// platformName returns the name of a platform as written in messages
+func platformName(platform models.Platform) string {
+	switch platform {
+	case models.PlatformTikTok:
+		return "TikTok"
+	case models.PlatformX:
+		return "X"
+	case models.PlatformInstagram:
+		return "Instagram"
+	case models.PlatformThreads:
+		return "Threads"
+	case models.PlatformFacebook:
+		return "Facebook"
+	case models.PlatformYouTube:
+		return "YouTube"
+	case models.PlatformLinkedIn:
+		return "LinkedIn"
+	case models.PlatformMastodon:
+		return "Mastodon"
+	case models.PlatformBluesky:
+		return "Bluesky"
+	case models.PlatformPinterest:
+		return "Pinterest"
+	case models.PlatformReddit:
+		return "Reddit"
+	case models.PlatformTelegram:
+		return "Telegram"
+	case models.PlatformDiscord:
+		return "Discord"
+	case models.PlatformSlack:
+		return "Slack"
+	case models.PlatformWebhook:
+		return "Webhook"
+	case models.PlatformMock:
+		return "Mock"
+	case "":
+		return "The platform"
+	}
+	return string(platform)
+}
+
+// NewPlatformError creates a PlatformError
+func NewPlatformError(platform models.Platform, code ErrorCode, statusCode int, platformCode, message string) *PlatformError {
+	return &PlatformError{
+		Platform:     platform,
+		Code:         code,
+		StatusCode:   statusCode,
+		PlatformCode: platformCode,
+		Message:      message,
+	}
+}
+
+// CodeForStatus classifies an HTTP status the platform did not explain further
+func CodeForStatus(statusCode int) ErrorCode {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return ErrorCodeAuthExpired
+	case statusCode == http.StatusForbidden:
+		return ErrorCodeInsufficientScope
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case statusCode == http.StatusRequestEntityTooLarge || statusCode == http.StatusUnsupportedMediaType:
+		return ErrorCodeMediaRejected
+	case statusCode == http.StatusRequestTimeout || statusCode >= 500:
+		return ErrorCodeTransient
+	}
+	return ErrorCodeUnknown
+}
+
+// RetryAfter returns when a response says the request may be retried, from its
+// Retry-After header in seconds or as an HTTP date. Returns the zero time if there is none
+func RetryAfter(header http.Header) time.Time {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		// Negative delays are malformed, and delays past the range of time.Duration would overflow
+		if seconds < 0 || int64(seconds) > int64(math.MaxInt64/time.Second) {
+			return time.Time{}
+		}
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at
+	}
+	return time.Time{}
+}
+
+// ClassifyError returns err as a *PlatformError
+// Errors that don't wrap one are classified as transient if they are network errors or
+// timeouts, and as unknown otherwise
+func ClassifyError(platform models.Platform, err error) *PlatformError {
+	var platformErr *PlatformError
+	if errors.As(err, &platformErr) {
+		return platformErr
+	}
+
+	code := ErrorCodeUnknown
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		code = ErrorCodeTransient
+	}
+	return NewPlatformError(platform, code, 0, "", err.Error())
+}
@@ -0,0 +1,250 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+func TestCodeForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   ErrorCode
+	}{
+		{status: 0, want: ErrorCodeUnknown},
+		{status: http.StatusOK, want: ErrorCodeUnknown},
+		{status: http.StatusBadRequest, want: ErrorCodeUnknown},
+		{status: http.StatusUnauthorized, want: ErrorCodeAuthExpired},
+		{status: http.StatusForbidden, want: ErrorCodeInsufficientScope},
+		{status: http.StatusNotFound, want: ErrorCodeUnknown},
+		{status: http.StatusRequestTimeout, want: ErrorCodeTransient},
+		{status: http.StatusRequestEntityTooLarge, want: ErrorCodeMediaRejected},
+		{status: http.StatusUnsupportedMediaType, want: ErrorCodeMediaRejected},
+		{status: http.StatusUnprocessableEntity, want: ErrorCodeUnknown},
+		{status: http.StatusTooManyRequests, want: ErrorCodeRateLimited},
+		{status: 499, want: ErrorCodeUnknown},
+		{status: http.StatusInternalServerError, want: ErrorCodeTransient},
+		{status: http.StatusServiceUnavailable, want: ErrorCodeTransient},
+		{status: 599, want: ErrorCodeTransient},
+	}
+
+	for _, tt := range tests {
+		if got := CodeForStatus(tt.status); got != tt.want {
+			t.Errorf("CodeForStatus(%d) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		wantDelay time.Duration // Expected delay from now for a value in seconds
+		wantAt    time.Time     // Expected time for a date
+		wantZero  bool
+	}{
+		{name: "seconds", value: "120", wantDelay: 2 * time.Minute},
+		{name: "zero seconds", value: "0", wantDelay: 0},
+		{name: "http date", value: at.Format(http.TimeFormat), wantAt: at},
+		{name: "rfc 850 date", value: at.Format(time.RFC850), wantAt: at},
+		{name: "missing", value: "", wantZero: true},
+		{name: "negative seconds", value: "-5", wantZero: true},
+		{name: "seconds beyond the duration range", value: "9223372036854775807", wantZero: true},
+		{name: "fractional seconds", value: "1.5", wantZero: true},
+		{name: "garbage", value: "soon", wantZero: true},
+		{name: "date in the wrong format", value: "2026-03-01T12:30:00Z", wantZero: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			before := time.Now()
+			got := RetryAfter(header)
+			after := time.Now()
+
+			switch {
+			case tt.wantZero:
+				if !got.IsZero() {
+					t.Errorf("RetryAfter(%q) = %v, want zero", tt.value, got)
+				}
+			case !tt.wantAt.IsZero():
+				if !got.Equal(tt.wantAt) {
+					t.Errorf("RetryAfter(%q) = %v, want %v", tt.value, got, tt.wantAt)
+				}
+			default:
+				if got.Before(before.Add(tt.wantDelay)) || got.After(after.Add(tt.wantDelay)) {
+					t.Errorf("RetryAfter(%q) = %v, want %v from now", tt.value, got, tt.wantDelay)
+				}
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	rateLimited := NewPlatformError(models.PlatformX, ErrorCodeRateLimited, 429, "", "Too Many Requests")
+
+	tests := []struct {
+		name     string
+		err      error
+		want     ErrorCode
+		wantSame bool // The PlatformError in err is returned as is
+	}{
+		{name: "platform error", err: rateLimited, want: ErrorCodeRateLimited, wantSame: true},
+		{name: "wrapped platform error", err: fmt.Errorf("failed to create tweet: %w", rateLimited), want: ErrorCodeRateLimited, wantSame: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: ErrorCodeTransient},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("failed to upload: %w", context.DeadlineExceeded), want: ErrorCodeTransient},
+		{name: "net error", err: timeoutError{}, want: ErrorCodeTransient},
+		{name: "dial error", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: ErrorCodeTransient},
+		{name: "canceled", err: context.Canceled, want: ErrorCodeUnknown},
+		{name: "plain error", err: errors.New("failed to decode response"), want: ErrorCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(models.PlatformX, tt.err)
+			if got.Code != tt.want {
+				t.Errorf("ClassifyError(%v).Code = %s, want %s", tt.err, got.Code, tt.want)
+			}
+			if tt.wantSame {
+				if got != rateLimited {
+					t.Errorf("ClassifyError(%v) = %p, want the wrapped error %p", tt.err, got, rateLimited)
+				}
+				return
+			}
+			if got.Platform != models.PlatformX || got.StatusCode != 0 || got.Message != tt.err.Error() {
+				t.Errorf("ClassifyError(%v) = %+v, want the platform and message of the error", tt.err, got)
+			}
+		})
+	}
+}
+
+func TestPlatformErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *PlatformError
+		want string
+	}{
+		{
+			name: "status and platform code",
+			err:  NewPlatformError(models.PlatformTikTok, ErrorCodeRateLimited, 429, "spam_risk_too_many_posts", "slow down"),
+			want: "tiktok API error (rate_limited, status 429): spam_risk_too_many_posts: slow down",
+		},
+		{
+			name: "status only",
+			err:  NewPlatformError(models.PlatformX, ErrorCodeTransient, 503, "", "Service Unavailable"),
+			want: "x API error (transient, status 503): Service Unavailable",
+		},
+		{
+			name: "no response",
+			err:  NewPlatformError(models.PlatformYouTube, ErrorCodeTransient, 0, "", "i/o timeout"),
+			want: "youtube API error (transient): i/o timeout",
+		},
+		{
+			name: "empty message",
+			err:  NewPlatformError(models.PlatformReddit, ErrorCodeUnknown, 0, "", ""),
+			want: "reddit API error (unknown): ",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%s: Error() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlatformErrorRetryable(t *testing.T) {
+	retryable := map[ErrorCode]bool{
+		ErrorCodeRateLimited: true,
+		ErrorCodeTransient:   true,
+	}
+	for code := range userMessages {
+		err := NewPlatformError(models.PlatformX, code, 0, "", "")
+		if got := err.Retryable(); got != retryable[code] {
+			t.Errorf("Retryable() of %s = %v, want %v", code, got, retryable[code])
+		}
+	}
+}
+
+func TestUserMessage(t *testing.T) {
+	retryAt := time.Date(2026, 3, 1, 14, 5, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name     string
+		platform models.Platform
+		code     ErrorCode
+		retryAt  time.Time
+		want     string
+	}{
+		{
+			name:     "auth expired",
+			platform: models.PlatformLinkedIn,
+			code:     ErrorCodeAuthExpired,
+			want:     "Your LinkedIn connection has expired. Please reconnect your account.",
+		},
+		{
+			name:     "rate limited without a reset time",
+			platform: models.PlatformTikTok,
+			code:     ErrorCodeRateLimited,
+			want:     "TikTok is limiting how often you can post. Please try again later.",
+		},
+		{
+			name:     "rate limited with a reset time in UTC",
+			platform: models.PlatformTikTok,
+			code:     ErrorCodeRateLimited,
+			retryAt:  retryAt,
+			want:     "TikTok is limiting how often you can post. Please try again after 13:05 UTC.",
+		},
+		{
+			name:     "reset time ignored for other codes",
+			platform: models.PlatformX,
+			code:     ErrorCodeTransient,
+			retryAt:  retryAt,
+			want:     "X is temporarily unavailable. Please try again in a few minutes.",
+		},
+		{
+			name:     "unknown code",
+			platform: models.PlatformBluesky,
+			code:     "made_up",
+			want:     "Bluesky could not publish the post.",
+		},
+		{
+			name:     "no platform",
+			platform: "",
+			code:     ErrorCodeUnknown,
+			want:     "The platform could not publish the post.",
+		},
+		{
+			name:     "platform without a display name",
+			platform: "myspace",
+			code:     ErrorCodeContentPolicy,
+			want:     "myspace rejected the post because it does not meet the platform's content rules.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UserMessage(tt.platform, tt.code, tt.retryAt); got != tt.want {
+				t.Errorf("UserMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// BreakerState is the state of a platform's circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // The platform is healthy; posts go through
+	BreakerOpen     BreakerState = "open"      // Too many calls failed; new posts are held
+	BreakerHalfOpen BreakerState = "half_open" // The cooldown passed; one trial post is let through
+)
+
+// Circuit breaker tuning
+const (
+	breakerWindow      = 5 * time.Minute // Calls older than this don't count towards the error rate
+	breakerMinCalls    = 10              // Calls in the window before the breaker can open
+	breakerFailureRate = 0.5             // Share of failed calls in the window that opens the breaker
+	breakerCooldown    = 2 * time.Minute // How long the breaker stays open before a trial post
+)
+
+// PlatformHealth is the health of a platform as seen by its circuit breaker
+type PlatformHealth struct {
+	Platform  models.Platform `json:"platform"`
+	State     BreakerState    `json:"state"`
+	ErrorRate float64         `json:"error_rate"` // Share of failed calls in the window
+	Calls     int             `json:"calls"`      // Calls in the window
+	Failures  int             `json:"failures"`   // Failed calls in the window
+	OpenedAt  *time.Time      `json:"opened_at,omitempty"`
+	RetryAt   *time.Time      `json:"retry_at,omitempty"` // When held posts are tried again
+	LastError string          `json:"last_error,omitempty"`
+}
+
+// callOutcome is the result of one platform call
+type callOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// CircuitBreaker tracks the error rate of the calls made to a platform and stops new posts
+// while the platform is failing
+// Only failures that say something about the platform count: outages, 5xx responses,
+// network errors and timeouts. Rejections such as expired tokens or policy violations
+// mean the platform is up, so they count as successful calls
+type CircuitBreaker struct {
+	platform models.Platform
+
+	mu        sync.Mutex
+	state     BreakerState
+	outcomes  []callOutcome // Oldest first
+	openedAt  time.Time
+	trialAt   time.Time // When the half-open breaker let a trial post through, zero if none
+	lastError string
+
+	now func() time.Time // The clock, replaced in tests
+}
+
+// NewCircuitBreaker creates a closed circuit breaker for a platform
+func NewCircuitBreaker(platform models.Platform) *CircuitBreaker {
+	return &CircuitBreaker{
+		platform: platform,
+		state:    BreakerClosed,
+		now:      time.Now,
+	}
+}
+
+// Record records the result of a platform call
+func (b *CircuitBreaker) Record(err error) {
+	// Calls cancelled by us say nothing about the platform
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	failed := err != nil && ClassifyError(b.platform, err).Code == ErrorCodeTransient
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.prune(now)
+	b.outcomes = append(b.outcomes, callOutcome{at: now, failed: failed})
+	if failed {
+		b.lastError = err.Error()
+	}
+
+	switch b.state {
+	case BreakerHalfOpen:
+		// The trial decides: the platform either recovered or is still failing
+		if failed {
+			b.open(now)
+		} else {
+			b.close()
+		}
+	case BreakerClosed:
+		calls, failures := b.counts()
+		if calls >= breakerMinCalls && float64(failures)/float64(calls) >= breakerFailureRate {
+			b.open(now)
+		}
+	}
+}
+
+// Allow reports whether a new post may be attempted
+// Once the cooldown of an open breaker has passed, one trial post is allowed per cooldown
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case BreakerOpen:
+		if now.Before(b.openedAt.Add(breakerCooldown)) {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trialAt = now
+		return true
+	case BreakerHalfOpen:
+		// Let another trial through if the last one never made a call
+		if now.Before(b.trialAt.Add(breakerCooldown)) {
+			return false
+		}
+		b.trialAt = now
+		return true
+	}
+	return true
+}
+
+// RetryAt returns when a post held by the breaker may be tried again, zero if it is closed
+func (b *CircuitBreaker) RetryAt() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.retryAt()
+}
+
+// Reset closes the breaker and forgets the recorded calls
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.close()
+}
+
+// Health returns the state and recent error rate of the platform
+func (b *CircuitBreaker) Health() PlatformHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.prune(b.now())
+	calls, failures := b.counts()
+	health := PlatformHealth{
+		Platform:  b.platform,
+		State:     b.state,
+		Calls:     calls,
+		Failures:  failures,
+		LastError: b.lastError,
+	}
+	if calls > 0 {
+		health.ErrorRate = float64(failures) / float64(calls)
+	}
+	if b.state != BreakerClosed {
+		openedAt, retryAt := b.openedAt, b.retryAt()
+		health.OpenedAt = &openedAt
+		health.RetryAt = &retryAt
+	}
+	return health
+}
+
+// retryAt returns when a held post may be tried again. Must be called with b.mu held
+func (b *CircuitBreaker) retryAt() time.Time {
+	switch b.state {
+	case BreakerOpen:
+		return b.openedAt.Add(breakerCooldown)
+	case BreakerHalfOpen:
+		return b.trialAt.Add(breakerCooldown)
+	}
+	return time.Time{}
+}
+
+// open opens the breaker. Must be called with b.mu held
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.trialAt = time.Time{}
+}
+
+// close closes the breaker and starts counting afresh. Must be called with b.mu held
+func (b *CircuitBreaker) close() {
+	b.state = BreakerClosed
+	b.outcomes = nil
+	b.openedAt = time.Time{}
+	b.trialAt = time.Time{}
+	b.lastError = ""
+}
+
+// prune drops the outcomes that have left the window. Must be called with b.mu held
+func (b *CircuitBreaker) prune(now time.Time) {
+	cutoff := now.Add(-breakerWindow)
+	i := 0
+	for i < len(b.outcomes) && b.outcomes[i].at.Before(cutoff) {
+		i++
+	}
+	b.outcomes = b.outcomes[i:]
+}
+
+// counts returns the number of calls and failed calls in the window. Must be called with b.mu held
+func (b *CircuitBreaker) counts() (calls, failures int) {
+	for _, outcome := range b.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+	return len(b.outcomes), failures
+}
@@ -0,0 +1,231 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestBreaker returns a closed breaker driven by a fake clock
+func newTestBreaker() (*CircuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	breaker := NewCircuitBreaker(models.PlatformX)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+var (
+	errOutage   = NewPlatformError(models.PlatformX, ErrorCodeTransient, 503, "", "Service Unavailable")
+	errRejected = NewPlatformError(models.PlatformX, ErrorCodeContentPolicy, 403, "", "not permitted")
+)
+
+// record records n calls ending in err
+func record(b *CircuitBreaker, n int, err error) {
+	for i := 0; i < n; i++ {
+		b.Record(err)
+	}
+}
+
+func TestCircuitBreakerOpening(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		failures  int
+		failure   error
+		want      BreakerState
+	}{
+		{name: "no calls", want: BreakerClosed},
+		{name: "every call failed below the minimum", failures: breakerMinCalls - 1, failure: errOutage, want: BreakerClosed},
+		{name: "every call failed at the minimum", failures: breakerMinCalls, failure: errOutage, want: BreakerOpen},
+		{name: "failure rate at the threshold", successes: 5, failures: 5, failure: errOutage, want: BreakerOpen},
+		{name: "failure rate below the threshold", successes: 6, failures: 5, failure: errOutage, want: BreakerClosed},
+		{name: "rejections count as successes", failures: breakerMinCalls, failure: errRejected, want: BreakerClosed},
+		{name: "network errors count as failures", failures: breakerMinCalls, failure: fmt.Errorf("failed to post: %w", context.DeadlineExceeded), want: BreakerOpen},
+		{name: "unclassified errors count as successes", failures: breakerMinCalls, failure: errors.New("failed to decode response"), want: BreakerClosed},
+		{name: "cancelled calls are ignored", successes: 1, failures: breakerMinCalls, failure: context.Canceled, want: BreakerClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, _ := newTestBreaker()
+			record(breaker, tt.successes, nil)
+			record(breaker, tt.failures, tt.failure)
+
+			if got := breaker.Health().State; got != tt.want {
+				t.Errorf("state = %s, want %s", got, tt.want)
+			}
+			if got := breaker.Allow(); got != (tt.want == BreakerClosed) {
+				t.Errorf("Allow() = %v, want %v", got, tt.want == BreakerClosed)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCancelledCallsDoNotCount(t *testing.T) {
+	breaker, _ := newTestBreaker()
+	record(breaker, breakerMinCalls, context.Canceled)
+	record(breaker, 2, errOutage)
+
+	health := breaker.Health()
+	if health.Calls != 2 || health.Failures != 2 {
+		t.Errorf("calls = %d, failures = %d, want only the 2 outages counted", health.Calls, health.Failures)
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	breaker, clock := newTestBreaker()
+	record(breaker, breakerMinCalls-1, errOutage)
+
+	// The failures leave the window just before the call that would have opened the breaker
+	clock.Advance(breakerWindow + time.Second)
+	breaker.Record(errOutage)
+	if health := breaker.Health(); health.State != BreakerClosed || health.Calls != 1 {
+		t.Fatalf("health = %+v, want closed with the old calls pruned", health)
+	}
+
+	// Calls exactly as old as the window still count
+	breaker, clock = newTestBreaker()
+	record(breaker, breakerMinCalls-1, errOutage)
+	clock.Advance(breakerWindow)
+	breaker.Record(errOutage)
+	if got := breaker.Health().State; got != BreakerOpen {
+		t.Errorf("state = %s, want open with calls at the edge of the window counted", got)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name  string
+		trial error
+		want  BreakerState
+	}{
+		{name: "successful trial closes", trial: nil, want: BreakerClosed},
+		{name: "rejected trial closes", trial: errRejected, want: BreakerClosed},
+		{name: "failed trial reopens", trial: errOutage, want: BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker, clock := newTestBreaker()
+			record(breaker, breakerMinCalls, errOutage)
+			openedAt := clock.Now()
+
+			clock.Advance(breakerCooldown - time.Second)
+			if breaker.Allow() {
+				t.Fatal("Allow() before the cooldown = true, want false")
+			}
+			if got := breaker.RetryAt(); !got.Equal(openedAt.Add(breakerCooldown)) {
+				t.Errorf("RetryAt() = %v, want the end of the cooldown", got)
+			}
+
+			clock.Advance(time.Second)
+			if !breaker.Allow() {
+				t.Fatal("Allow() after the cooldown = false, want a trial")
+			}
+			if got := breaker.Health().State; got != BreakerHalfOpen {
+				t.Fatalf("state after the trial is allowed = %s, want half open", got)
+			}
+			if breaker.Allow() {
+				t.Fatal("second Allow() while the trial runs = true, want false")
+			}
+
+			breaker.Record(tt.trial)
+			if got := breaker.Health().State; got != tt.want {
+				t.Errorf("state after the trial = %s, want %s", got, tt.want)
+			}
+			if tt.want == BreakerOpen {
+				if got := breaker.RetryAt(); !got.Equal(clock.Now().Add(breakerCooldown)) {
+					t.Errorf("RetryAt() after the failed trial = %v, want a new cooldown", got)
+				}
+				return
+			}
+			if health := breaker.Health(); health.Calls != 0 || health.LastError != "" || !breaker.RetryAt().IsZero() {
+				t.Errorf("health after closing = %+v, want the calls forgotten", health)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTrialWithoutCall(t *testing.T) {
+	breaker, clock := newTestBreaker()
+	record(breaker, breakerMinCalls, errOutage)
+	clock.Advance(breakerCooldown)
+	if !breaker.Allow() {
+		t.Fatal("Allow() after the cooldown = false, want a trial")
+	}
+
+	// The trial post never reached the platform, so another one is let through a cooldown later
+	clock.Advance(breakerCooldown - time.Second)
+	if breaker.Allow() {
+		t.Error("Allow() during the trial's cooldown = true, want false")
+	}
+	clock.Advance(time.Second)
+	if !breaker.Allow() {
+		t.Error("Allow() a cooldown after the trial = false, want another trial")
+	}
+	if got := breaker.RetryAt(); !got.Equal(clock.Now().Add(breakerCooldown)) {
+		t.Errorf("RetryAt() = %v, want a cooldown after the second trial", got)
+	}
+}
+
+func TestCircuitBreakerReset(t *testing.T) {
+	breaker, _ := newTestBreaker()
+	record(breaker, breakerMinCalls, errOutage)
+
+	breaker.Reset()
+	health := breaker.Health()
+	if health.State != BreakerClosed || health.Calls != 0 || health.LastError != "" || health.OpenedAt != nil || health.RetryAt != nil {
+		t.Errorf("health after Reset() = %+v, want closed and empty", health)
+	}
+	if !breaker.Allow() {
+		t.Error("Allow() after Reset() = false, want true")
+	}
+}
+
+func TestCircuitBreakerHealth(t *testing.T) {
+	breaker, clock := newTestBreaker()
+	if health := breaker.Health(); health.ErrorRate != 0 || health.Calls != 0 || health.Platform != models.PlatformX {
+		t.Errorf("health without calls = %+v, want an empty X report", health)
+	}
+
+	record(breaker, 2, nil)
+	record(breaker, 1, errRejected)
+	record(breaker, 1, errOutage)
+	health := breaker.Health()
+	if health.Calls != 4 || health.Failures != 1 || health.ErrorRate != 0.25 {
+		t.Errorf("health = %+v, want 1 failure in 4 calls", health)
+	}
+	if health.LastError != errOutage.Error() {
+		t.Errorf("last error = %q, want %q", health.LastError, errOutage.Error())
+	}
+	if health.OpenedAt != nil || health.RetryAt != nil {
+		t.Errorf("closed breaker reports opened_at = %v, retry_at = %v", health.OpenedAt, health.RetryAt)
+	}
+
+	record(breaker, breakerMinCalls, errOutage)
+	openedAt := clock.Now()
+	health = breaker.Health()
+	if health.State != BreakerOpen || health.OpenedAt == nil || !health.OpenedAt.Equal(openedAt) ||
+		health.RetryAt == nil || !health.RetryAt.Equal(openedAt.Add(breakerCooldown)) {
+		t.Errorf("health = %+v, want open since %v until the cooldown ends", health, openedAt)
+	}
+
+	// Health prunes the window itself, without waiting for the next call
+	clock.Advance(breakerWindow + time.Second)
+	if health := breaker.Health(); health.Calls != 0 || health.ErrorRate != 0 {
+		t.Errorf("health after the window = %+v, want no calls", health)
+	}
+}
@@ -0,0 +1,34 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+)
+
+// InstanceAuthenticator is implemented by platform services whose accounts live on a server the
+// user chooses, like a Mastodon instance, so the OAuth flow has to start on that server
+// instance is what the user entered; the normalized URL is returned in AuthURLResponse.InstanceURL
+type InstanceAuthenticator interface {
+	GenerateInstanceAuthURL(ctx context.Context, instance string) (AuthURLResponse, error)
+}
+
+type instanceKey struct{}
+
+// WithInstance returns a context whose platform calls go to the server at instanceURL
+// Per-instance platforms read it in every method, since the PlatformService methods only
+// receive the access token
+func WithInstance(ctx context.Context, instanceURL string) context.Context {
+	if instanceURL == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, instanceKey{}, instanceURL)
+}
+
+// InstanceFromContext returns the server URL of ctx, or "" if it has none
+func InstanceFromContext(ctx context.Context) string {
+	instanceURL, _ := ctx.Value(instanceKey{}).(string)
+	return instanceURL
+}
+
+// ErrInvalidInstance is wrapped by the errors returned for instances users may not log in with
+var ErrInvalidInstance = errors.New("invalid instance")
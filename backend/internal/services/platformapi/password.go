@@ -0,0 +1,26 @@
+package platformapi
+
+import (
+	"context"
+	"errors"
+)
+
+// PasswordAuthenticator is implemented by platform services whose accounts are connected with
+// credentials the user enters, like a Bluesky handle and app password, instead of an OAuth redirect
+// The returned tokens are used like those of ExchangeCodeForTokens; TokenResponse.InstanceURL
+// names the server the account lives on if the platform has more than one
+type PasswordAuthenticator interface {
+	CreateSession(ctx context.Context, identifier, password string) (*TokenResponse, error)
+}
+
+// ErrInvalidCredentials is wrapped by the errors returned when the platform rejects the
+// identifier or password of a login, or the webhook URL of a connection
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// WebhookAuthenticator is implemented by platform services whose destinations are connected with
+// an incoming webhook URL the user created on the platform, like a Discord channel webhook
+// The webhook is verified before the tokens are returned; it is the credential, so it is stored
+// as the access token
+type WebhookAuthenticator interface {
+	ConnectWebhook(ctx context.Context, webhookURL string) (*TokenResponse, error)
+}
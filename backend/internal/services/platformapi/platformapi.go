@@ -0,0 +1,122 @@
+// Package platformapi contains the types shared by the platform implementations and the
+// services that use them. It only depends on models, so both services and services/platform
+// can import it without an import cycle.
+package platformapi
+
+import (
+	"context"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// PlatformService defines the interface that all platform services must implement
+// This allows us to support multiple social media platforms (TikTok, X, Instagram, etc.)
+// in a uniform way without code duplication
+// Every method that talks to the platform takes a context; cancelling it aborts the
+// HTTP requests and any polling the method is doing
+type PlatformService interface {
+	// Auth methods
+	GenerateAuthURL() (AuthURLResponse, error)
+	ExchangeCodeForTokens(ctx context.Context, code string, additionalParams map[string]string) (*TokenResponse, error)
+	RefreshAccessToken(ctx context.Context, refreshToken string) (*TokenResponse, error)
+	GetUserInfo(ctx context.Context, accessToken string) (*UserInfo, error)
+
+	// Media methods
+	UploadMedia(ctx context.Context, accessToken string, mediaURL string) (string, error)
+
+	// Post methods
+	CreatePost(ctx context.Context, accessToken string, content PostContent) (*PostResponse, error)
+	GetPostStatus(ctx context.Context, accessToken string, postID string) (*PostStatusResponse, error)
+
+	// Metadata
+	GetPlatformName() models.Platform
+	GetRequiredScopes() []string
+	Capabilities() Capabilities
+
+	// ValidateSettings checks platform-specific post settings against the platform's schema
+	// Returns the settings with defaults applied, or a *SettingsError
+	ValidateSettings(settings Settings) (Settings, error)
+}
+
+// AuthURLResponse contains the OAuth authorization URL and associated data
+type AuthURLResponse struct {
+	URL          string // The OAuth authorization URL to redirect user to
+	State        string // CSRF protection state parameter
+	CodeVerifier string // PKCE code verifier (for platforms like X that use PKCE)
+	InstanceURL  string // Server the flow runs on (for per-instance platforms like Mastodon)
+}
+
+// TokenResponse contains OAuth tokens received from the platform
+type TokenResponse struct {
+	AccessToken  string // Access token for API requests
+	RefreshToken string // Refresh token for obtaining new access tokens
+	ExpiresIn    int    // Token expiration time in seconds
+	TokenType    string // Token type (usually "Bearer")
+	Scope        string // Granted scopes
+	InstanceURL  string // Server the account lives on, when it is only known after logging in (Bluesky PDS)
+}
+
+// UserInfo contains basic user information from the platform
+type UserInfo struct {
+	PlatformUserID string // Platform-specific user ID
+	Username       string // Username/handle
+	DisplayName    string // Display name
+	AvatarURL      string // Profile picture URL
+	Email          string // Email (if available)
+}
+
+// PostContent represents the content to be posted
+type PostContent struct {
+	Text      string   // Post text/caption
+	MediaURL  string   // Primary URL of media to download and upload
+	MediaURLs []string // Multiple media URLs (for carousel/multi-image)
+	MediaIDs  []string // Pre-uploaded media IDs (for platforms like X)
+	// Kind (MediaKindImage or MediaKindVideo) of each media URL, detected from its leading bytes
+	// Media that couldn't be probed is missing; platforms then go by the URL's extension
+	MediaKinds map[string]string
+	Settings   Settings // Platform-specific settings, already checked by ValidateSettings
+
+	// The post being published, for platforms that pass it on, like the user's own webhooks
+	PostID        int64  // ID of the post in this backend; the same for every attempt
+	PublicationID string // Shared by the posts of the other platforms of the same publication
+}
+
+// PostResponse contains the result of creating a post
+type PostResponse struct {
+	PostID    string // Platform-specific post ID (immediate for X, after processing for TikTok)
+	PublishID string // Async publish ID (for TikTok)
+	Status    string // Post status (pending, processing, published)
+	ShareURL  string // URL to view the post on the platform
+	ErrorMsg  string // Error message if post creation failed
+}
+
+// PostStatusResponse contains the current status of a post
+type PostStatusResponse struct {
+	Status          string    // pending, processing, published, failed
+	PostID          string    // Platform-specific post ID
+	ShareID         string    // Platform-specific share ID (when available)
+	ShareURL        string    // URL to view the post on the platform
+	FailReason      string    // Reason for failure if status is failed
+	ErrorCode       ErrorCode // Classification of FailReason
+	ProgressPercent int       // Progress percentage (for video processing)
+}
+
+// Upload stages reported through UploadProgressFunc
+const (
+	UploadStageUploading  = "uploading"
+	UploadStageProcessing = "processing"
+)
+
+// UploadProgressFunc receives the stage and percentage of a media upload
+type UploadProgressFunc func(stage string, percent int)
+
+// ProgressUploader is implemented by platform services that can report media upload progress
+type ProgressUploader interface {
+	UploadMediaWithProgress(ctx context.Context, accessToken string, mediaURL string, progress UploadProgressFunc) (string, error)
+}
+
+// PostDeleter is implemented by platform services that can delete published posts
+// postID is the PostResponse.PostID the post was published with
+type PostDeleter interface {
+	DeletePost(ctx context.Context, accessToken string, postID string) error
+}
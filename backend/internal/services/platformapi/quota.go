@@ -0,0 +1,44 @@
+package platformapi
+
+import (
+	"context"
+	"time"
+)
+
+// PublishLimit is a documented cap on how many posts one account may publish through the
+// API within a rolling window
+type PublishLimit struct {
+	Posts         int `json:"posts"`
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// Window returns the length of the rolling window
+func (l PublishLimit) Window() time.Duration {
+	return time.Duration(l.WindowSeconds) * time.Second
+}
+
+// RateLimit is the state of a rate limit as reported by the platform, e.g. in the
+// x-rate-limit-* headers of X
+type RateLimit struct {
+	Limit     int       // Calls allowed in the window, 0 if unknown
+	Remaining int       // Calls left until ResetAt
+	ResetAt   time.Time // When the window resets
+}
+
+// RateLimitObserver receives the rate limits a platform reports for an endpoint
+type RateLimitObserver func(endpoint string, limit RateLimit)
+
+type rateLimitObserverKey struct{}
+
+// WithRateLimitObserver returns a context that passes the rate limits reported by the
+// platform calls made with it to observer
+func WithRateLimitObserver(ctx context.Context, observer RateLimitObserver) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, observer)
+}
+
+// ObserveRateLimit reports a rate limit to the observer of ctx, if it has one
+func ObserveRateLimit(ctx context.Context, endpoint string, limit RateLimit) {
+	if observer, ok := ctx.Value(rateLimitObserverKey{}).(RateLimitObserver); ok {
+		observer(endpoint, limit)
+	}
+}
@@ -0,0 +1,155 @@
+package platformapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// Settings holds the platform-specific settings of a post keyed by setting name
+// Values come straight from JSON, so numbers are float64 until ValidateSettings normalizes them
+type Settings map[string]interface{}
+
+// String returns a string setting, or "" if it is not set
+func (s Settings) String(name string) string {
+	v, _ := s[name].(string)
+	return v
+}
+
+// Bool returns a bool setting, or false if it is not set
+func (s Settings) Bool(name string) bool {
+	v, _ := s[name].(bool)
+	return v
+}
+
+// Int returns an int setting, or 0 if it is not set
+func (s Settings) Int(name string) int {
+	switch v := s[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// maxExactInt is the largest whole number a float64 holds exactly
+const maxExactInt = 1 << 53
+
+// FieldError describes why a single setting was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SettingsError lists the invalid settings of a platform
+type SettingsError struct {
+	Platform models.Platform
+	Fields   []FieldError
+}
+
+func (e *SettingsError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+	return fmt.Sprintf("invalid %s settings: %s", e.Platform, strings.Join(messages, "; "))
+}
+
+// ValidateSettings checks settings against a platform's schema
+// Returns a copy with defaults applied and numbers converted to int, or a *SettingsError
+// listing every unknown, missing or invalid field
+func ValidateSettings(platform models.Platform, schema []SettingField, settings Settings) (Settings, error) {
+	fields := make(map[string]SettingField, len(schema))
+	for _, field := range schema {
+		fields[field.Name] = field
+	}
+
+	var errs []FieldError
+	for name := range settings {
+		if _, ok := fields[name]; !ok {
+			errs = append(errs, FieldError{Field: name, Message: "unknown setting"})
+		}
+	}
+
+	result := make(Settings, len(schema))
+	for _, field := range schema {
+		value, ok := settings[field.Name]
+		if !ok || value == nil {
+			if field.Required {
+				errs = append(errs, FieldError{Field: field.Name, Message: "is required"})
+			} else if field.Default != nil {
+				result[field.Name] = field.Default
+			}
+			continue
+		}
+
+		normalized, msg := validateSettingValue(field, value)
+		if msg != "" {
+			errs = append(errs, FieldError{Field: field.Name, Message: msg})
+			continue
+		}
+		result[field.Name] = normalized
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Field < errs[j].Field
+		})
+		return nil, &SettingsError{Platform: platform, Fields: errs}
+	}
+	return result, nil
+}
+
+// validateSettingValue checks a single value against its field
+// Returns the normalized value, or a message describing why it is invalid
+func validateSettingValue(field SettingField, value interface{}) (interface{}, string) {
+	switch field.Type {
+	case SettingTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		if field.MaxLength > 0 && utf8.RuneCountInString(s) > field.MaxLength {
+			return nil, fmt.Sprintf("must be at most %d characters", field.MaxLength)
+		}
+		return s, ""
+	case SettingTypeBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, "must be true or false"
+		}
+		return b, ""
+	case SettingTypeInt:
+		switch n := value.(type) {
+		case int:
+			return n, ""
+		case float64:
+			if n != math.Trunc(n) {
+				return nil, "must be a whole number"
+			}
+			// Beyond this floats skip whole numbers, and int can't hold them all
+			if math.Abs(n) > maxExactInt {
+				return nil, "is out of range"
+			}
+			return int(n), ""
+		}
+		return nil, "must be a number"
+	case SettingTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		for _, option := range field.Options {
+			if s == option {
+				return s, ""
+			}
+		}
+		return nil, fmt.Sprintf("must be one of %s", strings.Join(field.Options, ", "))
+	}
+	return nil, fmt.Sprintf("has unsupported type %s", field.Type)
+}
@@ -0,0 +1,254 @@
+package platformapi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+)
+
+// testSchema has one field of every type
+var testSchema = []SettingField{
+	{Name: "title", Type: SettingTypeString, MaxLength: 5},
+	{Name: "notify", Type: SettingTypeBool, Default: true},
+	{Name: "count", Type: SettingTypeInt},
+	{Name: "privacy", Type: SettingTypeEnum, Options: []string{"public", "private"}, Required: true},
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		settings   Settings
+		want       Settings
+		wantFields []FieldError
+	}{
+		{
+			name:     "defaults applied",
+			settings: Settings{"privacy": "public"},
+			want:     Settings{"privacy": "public", "notify": true},
+		},
+		{
+			name:     "every field set",
+			settings: Settings{"title": "héllo", "notify": false, "count": float64(3), "privacy": "private"},
+			want:     Settings{"title": "héllo", "notify": false, "count": 3, "privacy": "private"},
+		},
+		{
+			name:     "int kept as int",
+			settings: Settings{"privacy": "public", "count": 7},
+			want:     Settings{"privacy": "public", "notify": true, "count": 7},
+		},
+		{
+			name:     "negative and zero numbers",
+			settings: Settings{"privacy": "public", "count": float64(-2)},
+			want:     Settings{"privacy": "public", "notify": true, "count": -2},
+		},
+		{
+			name:     "largest exact number",
+			settings: Settings{"privacy": "public", "count": float64(1 << 53)},
+			want:     Settings{"privacy": "public", "notify": true, "count": 1 << 53},
+		},
+		{
+			name:     "empty string",
+			settings: Settings{"privacy": "public", "title": ""},
+			want:     Settings{"privacy": "public", "notify": true, "title": ""},
+		},
+		{
+			name:     "null treated as unset",
+			settings: Settings{"privacy": "public", "notify": nil},
+			want:     Settings{"privacy": "public", "notify": true},
+		},
+		{
+			name:       "required field missing",
+			settings:   Settings{},
+			wantFields: []FieldError{{Field: "privacy", Message: "is required"}},
+		},
+		{
+			name:       "required field null",
+			settings:   Settings{"privacy": nil},
+			wantFields: []FieldError{{Field: "privacy", Message: "is required"}},
+		},
+		{
+			name:       "nil settings",
+			settings:   nil,
+			wantFields: []FieldError{{Field: "privacy", Message: "is required"}},
+		},
+		{
+			name:       "unknown field",
+			settings:   Settings{"privacy": "public", "colour": "red"},
+			wantFields: []FieldError{{Field: "colour", Message: "unknown setting"}},
+		},
+		{
+			name:       "string one rune too long",
+			settings:   Settings{"privacy": "public", "title": "héllos"},
+			wantFields: []FieldError{{Field: "title", Message: "must be at most 5 characters"}},
+		},
+		{
+			name:       "string of the wrong type",
+			settings:   Settings{"privacy": "public", "title": float64(1)},
+			wantFields: []FieldError{{Field: "title", Message: "must be a string"}},
+		},
+		{
+			name:       "bool as a string",
+			settings:   Settings{"privacy": "public", "notify": "true"},
+			wantFields: []FieldError{{Field: "notify", Message: "must be true or false"}},
+		},
+		{
+			name:       "fractional number",
+			settings:   Settings{"privacy": "public", "count": 1.5},
+			wantFields: []FieldError{{Field: "count", Message: "must be a whole number"}},
+		},
+		{
+			name:       "number beyond the exact range",
+			settings:   Settings{"privacy": "public", "count": float64(1<<53) * 2},
+			wantFields: []FieldError{{Field: "count", Message: "is out of range"}},
+		},
+		{
+			name:       "huge number",
+			settings:   Settings{"privacy": "public", "count": 1e300},
+			wantFields: []FieldError{{Field: "count", Message: "is out of range"}},
+		},
+		{
+			name:       "number as a string",
+			settings:   Settings{"privacy": "public", "count": "3"},
+			wantFields: []FieldError{{Field: "count", Message: "must be a number"}},
+		},
+		{
+			name:       "enum value not allowed",
+			settings:   Settings{"privacy": "Public"},
+			wantFields: []FieldError{{Field: "privacy", Message: "must be one of public, private"}},
+		},
+		{
+			name:       "enum of the wrong type",
+			settings:   Settings{"privacy": true},
+			wantFields: []FieldError{{Field: "privacy", Message: "must be a string"}},
+		},
+		{
+			name:     "every error reported, sorted by field",
+			settings: Settings{"zeta": 1, "title": "too long", "count": 0.5, "alpha": 2},
+			wantFields: []FieldError{
+				{Field: "alpha", Message: "unknown setting"},
+				{Field: "count", Message: "must be a whole number"},
+				{Field: "privacy", Message: "is required"},
+				{Field: "title", Message: "must be at most 5 characters"},
+				{Field: "zeta", Message: "unknown setting"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSettings(models.PlatformYouTube, testSchema, tt.settings)
+
+			if tt.wantFields != nil {
+				var settingsErr *SettingsError
+				if !errors.As(err, &settingsErr) {
+					t.Fatalf("ValidateSettings() error = %v, want a *SettingsError", err)
+				}
+				if settingsErr.Platform != models.PlatformYouTube {
+					t.Errorf("error platform = %s, want %s", settingsErr.Platform, models.PlatformYouTube)
+				}
+				if !reflect.DeepEqual(settingsErr.Fields, tt.wantFields) {
+					t.Errorf("error fields = %+v, want %+v", settingsErr.Fields, tt.wantFields)
+				}
+				if got != nil {
+					t.Errorf("ValidateSettings() = %v, want nil with an error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ValidateSettings() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateSettings() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSettingsDoesNotModifyInput(t *testing.T) {
+	settings := Settings{"privacy": "public", "count": float64(2)}
+	if _, err := ValidateSettings(models.PlatformYouTube, testSchema, settings); err != nil {
+		t.Fatalf("ValidateSettings() error = %v", err)
+	}
+	if want := (Settings{"privacy": "public", "count": float64(2)}); !reflect.DeepEqual(settings, want) {
+		t.Errorf("input = %#v, want it unchanged", settings)
+	}
+}
+
+func TestValidateSettingsIsIdempotent(t *testing.T) {
+	// Posts resumed after a restart validate their stored settings again
+	first, err := ValidateSettings(models.PlatformYouTube, testSchema, Settings{"privacy": "private", "count": float64(4), "title": "hi"})
+	if err != nil {
+		t.Fatalf("ValidateSettings() error = %v", err)
+	}
+	second, err := ValidateSettings(models.PlatformYouTube, testSchema, first)
+	if err != nil {
+		t.Fatalf("ValidateSettings() of validated settings error = %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("validated again = %#v, want %#v", second, first)
+	}
+}
+
+func TestValidateSettingsUnsupportedType(t *testing.T) {
+	schema := []SettingField{{Name: "when", Type: "date"}}
+	_, err := ValidateSettings(models.PlatformYouTube, schema, Settings{"when": "2026-01-01"})
+
+	var settingsErr *SettingsError
+	if !errors.As(err, &settingsErr) || len(settingsErr.Fields) != 1 || settingsErr.Fields[0].Message != "has unsupported type date" {
+		t.Errorf("ValidateSettings() error = %v, want the unsupported type reported", err)
+	}
+}
+
+func TestSettingsErrorMessage(t *testing.T) {
+	err := &SettingsError{
+		Platform: models.PlatformReddit,
+		Fields: []FieldError{
+			{Field: "subreddit", Message: "is required"},
+			{Field: "title", Message: "must be at most 300 characters"},
+		},
+	}
+	want := "invalid reddit settings: subreddit: is required; title: must be at most 300 characters"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix((&SettingsError{Platform: models.PlatformX}).Error(), "invalid x settings") {
+		t.Errorf("Error() without fields = %q", (&SettingsError{Platform: models.PlatformX}).Error())
+	}
+}
+
+func TestSettingsAccessors(t *testing.T) {
+	settings := Settings{
+		"name":    "board",
+		"enabled": true,
+		"int":     3,
+		"float":   float64(4),
+		"wrong":   []string{"a"},
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "string", got: settings.String("name"), want: "board"},
+		{name: "string of another type", got: settings.String("enabled"), want: ""},
+		{name: "missing string", got: settings.String("missing"), want: ""},
+		{name: "bool", got: settings.Bool("enabled"), want: true},
+		{name: "bool of another type", got: settings.Bool("name"), want: false},
+		{name: "int", got: settings.Int("int"), want: 3},
+		{name: "int from a JSON number", got: settings.Int("float"), want: 4},
+		{name: "int of another type", got: settings.Int("wrong"), want: 0},
+		{name: "missing int", got: settings.Int("missing"), want: 0},
+		{name: "nil settings", got: Settings(nil).String("name"), want: ""},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -39,14 +40,14 @@ func (s *PostService) CreatePost(userID int64, videoURL string, caption string)
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
-	// Process post asynchronously
-	go s.processPost(post.ID, userID)
+	// Process post asynchronously; it outlives the request, so it doesn't use the request's context
+	go s.processPost(context.Background(), post.ID, userID)
 
 	return post, nil
 }
 
 // processPost handles the posting workflow asynchronously
-func (s *PostService) processPost(postID int64, userID int64) {
+func (s *PostService) processPost(ctx context.Context, postID int64, userID int64) {
 	// Update status to processing
 	if err := s.postRepo.UpdateStatus(postID, models.PostStatusProcessing, ""); err != nil {
 		log.Printf("Failed to update post %d status to processing: %v", postID, err)
@@ -62,7 +63,7 @@ func (s *PostService) processPost(postID int64, userID int64) {
 	}
 
 	// Get valid access token
-	accessToken, err := s.tokenService.GetValidToken(userID)
+	accessToken, err := s.tokenService.GetValidToken(ctx, userID)
 	if err != nil {
 		log.Printf("Failed to get access token for user %d: %v", userID, err)
 		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, "Failed to get access token")
@@ -70,7 +71,7 @@ func (s *PostService) processPost(postID int64, userID int64) {
 	}
 
 	// Publish video to TikTok (legacy code - uses nil for settings)
-	publishResponse, err := s.tiktokService.PublishVideoFromURL(accessToken, post.VideoURL, post.Caption, nil)
+	publishResponse, err := s.tiktokService.PublishVideoFromURL(ctx, accessToken, post.VideoURL, post.Caption, nil)
 	if err != nil {
 		log.Printf("Failed to publish video to TikTok: %v", err)
 		s.postRepo.UpdateStatus(postID, models.PostStatusFailed, fmt.Sprintf("TikTok error: %v", err))
@@ -85,10 +86,13 @@ func (s *PostService) processPost(postID int64, userID int64) {
 	attempt := 0
 
 	for attempt < maxAttempts {
-		time.Sleep(5 * time.Second)
+		if err := sleepContext(ctx, 5*time.Second); err != nil {
+			s.postRepo.UpdateStatus(postID, models.PostStatusFailed, "Publishing was interrupted")
+			return
+		}
 		attempt++
 
-		statusResponse, err := s.tiktokService.GetPublishStatus(accessToken, publishID)
+		statusResponse, err := s.tiktokService.GetPublishStatus(ctx, accessToken, publishID)
 		if err != nil {
 			log.Printf("Failed to get publish status: %v", err)
 			continue
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// defaultRateLimitBackoff is how long an account is assumed to be rate limited after a
+// rate limit error that did not say when to retry
+const defaultRateLimitBackoff = 15 * time.Minute
+
+// quotaRecheckInterval is how long a post waits when the budget is taken up by posts that are
+// still being published, since it is not known when they finish
+const quotaRecheckInterval = time.Minute
+
+// rateLimitedEndpoint is the endpoint rate limit errors are recorded under
+const rateLimitedEndpoint = "rate_limited"
+
+// Quota is the remaining publishing budget of one account on one platform
+type Quota struct {
+	Limit     int        `json:"limit,omitempty"` // 0 if the platform did not report its limit
+	Remaining int        `json:"remaining"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+	Source    string     `json:"source"` // "platform" when reported by the platform, "cap" for a documented publish limit
+}
+
+// quotaKey identifies the budget of one account on one platform
+type quotaKey struct {
+	userID   int64
+	platform models.Platform
+}
+
+// QuotaTracker tracks the publishing budget of every account per platform from the rate
+// limits platforms report and the documented publish limits in their capabilities
+// Posts reserve a slot before they are sent to the platform, so concurrent posts of one
+// account can't exceed the budget together
+type QuotaTracker struct {
+	postRepo         *models.PostRepository
+	platformRegistry PlatformRegistry
+
+	mu       sync.Mutex
+	reported map[quotaKey]map[string]platformapi.RateLimit // Keyed by endpoint
+	reserved map[quotaKey]map[int64]bool                   // Posts being published
+}
+
+// NewQuotaTracker creates a new quota tracker
+func NewQuotaTracker(postRepo *models.PostRepository, platformRegistry PlatformRegistry) *QuotaTracker {
+	return &QuotaTracker{
+		postRepo:         postRepo,
+		platformRegistry: platformRegistry,
+		reported:         make(map[quotaKey]map[string]platformapi.RateLimit),
+		reserved:         make(map[quotaKey]map[int64]bool),
+	}
+}
+
+// Observe records a rate limit the platform reported for an endpoint
+func (t *QuotaTracker) Observe(userID int64, plt models.Platform, endpoint string, limit platformapi.RateLimit) {
+	key := quotaKey{userID, plt}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reported[key] == nil {
+		t.reported[key] = make(map[string]platformapi.RateLimit)
+	}
+	t.reported[key][endpoint] = limit
+}
+
+// RecordRateLimited records that the platform rejected a call of the account for exceeding
+// a rate limit until retryAt, or for defaultRateLimitBackoff if retryAt is zero
+func (t *QuotaTracker) RecordRateLimited(userID int64, plt models.Platform, retryAt time.Time) {
+	if retryAt.IsZero() {
+		retryAt = time.Now().Add(defaultRateLimitBackoff)
+	}
+	t.Observe(userID, plt, rateLimitedEndpoint, platformapi.RateLimit{Remaining: 0, ResetAt: retryAt})
+}
+
+// Reserve takes a slot in the publishing budget of an account for a post
+// Returns the zero time if the slot was taken, or when to try again if the budget is used up
+func (t *QuotaTracker) Reserve(userID int64, plt models.Platform, postID int64) (time.Time, error) {
+	key := quotaKey{userID, plt}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.reserved[key], postID)
+	pending := len(t.reserved[key])
+
+	quotas, err := t.quotas(key, pending)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var retryAt time.Time
+	for _, quota := range quotas {
+		if quota.Remaining > 0 {
+			continue
+		}
+		resetAt := time.Now().Add(quotaRecheckInterval)
+		if quota.ResetAt != nil {
+			resetAt = *quota.ResetAt
+		}
+		if resetAt.After(retryAt) {
+			retryAt = resetAt
+		}
+	}
+	if !retryAt.IsZero() {
+		return retryAt, nil
+	}
+
+	if t.reserved[key] == nil {
+		t.reserved[key] = make(map[int64]bool)
+	}
+	t.reserved[key][postID] = true
+	return time.Time{}, nil
+}
+
+// Release frees the slot a post reserved once it is published or has failed
+func (t *QuotaTracker) Release(userID int64, plt models.Platform, postID int64) {
+	key := quotaKey{userID, plt}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.reserved[key], postID)
+	if len(t.reserved[key]) == 0 {
+		delete(t.reserved, key)
+	}
+}
+
+// Quota returns the most constrained budget of an account on a platform, or nil if nothing
+// is known about its limits
+func (t *QuotaTracker) Quota(userID int64, plt models.Platform) (*Quota, error) {
+	key := quotaKey{userID, plt}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quotas, err := t.quotas(key, len(t.reserved[key]))
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Quota
+	for _, quota := range quotas {
+		if result == nil || quota.Remaining < result.Remaining {
+			result = quota
+		}
+	}
+	return result, nil
+}
+
+// quotas returns every known budget of an account, with pending posts that have reserved a
+// slot already deducted
+// Reported limits whose window has reset are forgotten. Must be called with t.mu held
+func (t *QuotaTracker) quotas(key quotaKey, pending int) ([]*Quota, error) {
+	now := time.Now()
+	var quotas []*Quota
+
+	for endpoint, limit := range t.reported[key] {
+		if !now.Before(limit.ResetAt) {
+			delete(t.reported[key], endpoint)
+			continue
+		}
+		resetAt := limit.ResetAt
+		quotas = append(quotas, &Quota{
+			Limit:     limit.Limit,
+			Remaining: max(limit.Remaining-pending, 0),
+			ResetAt:   &resetAt,
+			Source:    "platform",
+		})
+	}
+
+	publishLimit := t.publishLimit(key.platform)
+	if publishLimit == nil {
+		return quotas, nil
+	}
+
+	published, err := t.postRepo.GetPublishTimes(key.userID, key.platform, now.Add(-publishLimit.Window()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count published posts: %w", err)
+	}
+	quota := &Quota{
+		Limit:     publishLimit.Posts,
+		Remaining: max(publishLimit.Posts-len(published)-pending, 0),
+		Source:    "cap",
+	}
+	// The window rolls, so a slot frees up when the oldest post in it leaves the window
+	if len(published) > 0 {
+		resetAt := published[0].Add(publishLimit.Window())
+		quota.ResetAt = &resetAt
+	}
+	return append(quotas, quota), nil
+}
+
+// publishLimit returns the documented publish limit of a platform, nil if it has none
+func (t *QuotaTracker) publishLimit(plt models.Platform) *platformapi.PublishLimit {
+	platformService, err := t.platformRegistry.Get(plt)
+	if err != nil {
+		return nil
+	}
+	return platformService.Capabilities().PublishLimit
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redditRateLimitEndpoint is the endpoint Reddit rate limits are reported for
+// Reddit counts every request of an account through the app against the same limit
+const redditRateLimitEndpoint = "api"
+
+// redditAPI sends requests to the Reddit OAuth API
+// Reddit throttles clients with generic user agents, so every request carries the configured one
+type redditAPI struct {
+	baseURL    string // e.g. https://oauth.reddit.com
+	userAgent  string
+	httpClient *http.Client
+}
+
+// get sends a GET request with the given query parameters and decodes the response into out
+// raw_json is always set, without it Reddit escapes &, < and > in the response
+func (a *redditAPI) get(ctx context.Context, path, accessToken string, params url.Values, out interface{}) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("raw_json", "1")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return a.do(ctx, req, accessToken, out)
+}
+
+// post sends a form-encoded POST request and decodes the response into out
+func (a *redditAPI) post(ctx context.Context, path, accessToken string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return a.do(ctx, req, accessToken, out)
+}
+
+// do sends a request with the access token and reports the rate limit of the response
+func (a *redditAPI) do(ctx context.Context, req *http.Request, accessToken string, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", a.userAgent)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	observeRedditRateLimit(ctx, redditRateLimitEndpoint, resp.Header)
+	return a.decode(resp, out)
+}
+
+// decode reads an API response into out, or classifies it if it is unsuccessful
+func (a *redditAPI) decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return redditError(resp, body)
+	}
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// RedditAuthService handles Reddit OAuth and user accounts
+// https://github.com/reddit-archive/reddit/wiki/OAuth2
+type RedditAuthService struct {
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	scopes       []string
+	authBaseURL  string // Authorization page and token endpoint, e.g. https://www.reddit.com
+	api          *redditAPI
+}
+
+// NewRedditAuthService creates a new Reddit auth service
+func NewRedditAuthService(clientID, clientSecret, redirectURI string, scopes []string, userAgent, authBaseURL, apiBaseURL string) *RedditAuthService {
+	return &RedditAuthService{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		scopes:       scopes,
+		authBaseURL:  authBaseURL,
+		api: &redditAPI{
+			baseURL:    apiBaseURL,
+			userAgent:  userAgent,
+			httpClient: newHTTPClient("reddit", 30*time.Second),
+		},
+	}
+}
+
+// RedditTokenResponse represents the OAuth token response from Reddit
+// Access tokens are valid for an hour; permanent authorizations come with a refresh token
+// that doesn't expire until the user revokes the app
+type RedditTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"` // Not issued again when refreshing
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"` // Space-separated
+	Error        string `json:"error"` // Reddit reports rejected grants with status 200
+}
+
+// RedditUser is the account that authorized the app
+type RedditUser struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IconImg string `json:"icon_img"`
+}
+
+// GenerateAuthURL creates the OAuth authorization URL and the state that protects it
+// The authorization is permanent, so the account stays connected after the first access token expires
+func (s *RedditAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("client_id", s.clientID)
+	params.Set("response_type", "code")
+	params.Set("state", state)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("duration", "permanent")
+	params.Set("scope", strings.Join(s.scopes, " "))
+
+	return s.authBaseURL + "/api/v1/authorize?" + params.Encode(), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for an access token
+func (s *RedditAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*RedditTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "authorization_code")
+	formData.Set("code", code)
+	formData.Set("redirect_uri", s.redirectURI)
+	return s.requestToken(ctx, formData)
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token
+func (s *RedditAuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*RedditTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("refresh_token", refreshToken)
+	return s.requestToken(ctx, formData)
+}
+
+// requestToken posts a grant to the token endpoint, authenticating the app with HTTP Basic auth
+func (s *RedditAuthService) requestToken(ctx context.Context, formData url.Values) (*RedditTokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.authBaseURL+"/api/v1/access_token", strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", s.api.userAgent)
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+
+	resp, err := s.api.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	var tokenResp RedditTokenResponse
+	if err := s.api.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+	if tokenResp.Error != "" {
+		code := platformapi.ErrorCodeUnknown
+		if tokenResp.Error == "invalid_grant" {
+			code = platformapi.ErrorCodeAuthExpired // The code was used already, or the app was revoked
+		}
+		return nil, platformapi.NewPlatformError(models.PlatformReddit, code, resp.StatusCode, tokenResp.Error, "token request rejected")
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+	return &tokenResp, nil
+}
+
+// GetMe retrieves the account the token belongs to
+func (s *RedditAuthService) GetMe(ctx context.Context, accessToken string) (*RedditUser, error) {
+	var user RedditUser
+	if err := s.api.get(ctx, "/api/v1/me", accessToken, nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Name == "" {
+		return nil, fmt.Errorf("user response has no name")
+	}
+	return &user, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// RedditMediaService uploads images and videos to Reddit's media store
+// An upload is registered first, which returns the form the file has to be posted with to the
+// store; the URL of the stored file is then submitted as the post's media
+type RedditMediaService struct {
+	api            *redditAPI
+	uploadClient   *http.Client // Uploads to the media store, which take longer than API calls
+	downloadClient *http.Client
+}
+
+// NewRedditMediaService creates a new Reddit media service
+func NewRedditMediaService(apiBaseURL, userAgent string) *RedditMediaService {
+	return &RedditMediaService{
+		api: &redditAPI{
+			baseURL:    apiBaseURL,
+			userAgent:  userAgent,
+			httpClient: newHTTPClient("reddit", 30*time.Second),
+		},
+		uploadClient:   newHTTPClient("reddit", 10*time.Minute),
+		downloadClient: newHTTPClient("reddit-media-download", 5*time.Minute),
+	}
+}
+
+// redditMediaAsset is the response of POST /api/media/asset.json
+type redditMediaAsset struct {
+	Args struct {
+		Action string `json:"action"` // URL of the media store, may be protocol-relative
+		Fields []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"args"`
+	Asset struct {
+		AssetID string `json:"asset_id"`
+	} `json:"asset"`
+}
+
+// redditDownload is media downloaded to a temp file
+type redditDownload struct {
+	path     string
+	mimeType string
+}
+
+// Upload downloads the image or video at mediaURL and uploads it to Reddit's media store
+// Returns the URL an image or video post is submitted with
+func (s *RedditMediaService) Upload(ctx context.Context, accessToken, mediaURL string, mediaType MediaType) (string, error) {
+	download, err := s.download(ctx, mediaURL, mediaType)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(download.path)
+
+	form := url.Values{}
+	form.Set("filepath", "upload."+mediaFormatFromMIME(download.mimeType))
+	form.Set("mimetype", download.mimeType)
+
+	var asset redditMediaAsset
+	if err := s.api.post(ctx, "/api/media/asset.json", accessToken, form, &asset); err != nil {
+		return "", fmt.Errorf("failed to register media upload: %w", err)
+	}
+	if asset.Args.Action == "" {
+		return "", fmt.Errorf("media registration response has no upload URL")
+	}
+
+	uploadedURL, err := s.uploadFile(ctx, asset, download)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", mediaType, err)
+	}
+
+	log.Printf("Reddit %s uploaded: %s", mediaType, asset.Asset.AssetID)
+	return uploadedURL, nil
+}
+
+// download saves the media at mediaURL to a temp file and checks its type and size
+func (s *RedditMediaService) download(ctx context.Context, mediaURL string, mediaType MediaType) (*redditDownload, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", mediaType, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, platformapi.NewPlatformError(models.PlatformReddit, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("failed to download %s: status %d", mediaType, resp.StatusCode))
+	}
+
+	out, err := os.CreateTemp("", "reddit-media-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("failed to save %s: %w", mediaType, err)
+	}
+
+	header := make([]byte, sniffHeaderSize)
+	n, _ := out.ReadAt(header, 0)
+
+	// Trust the bytes first, then the Content-Type header
+	mimeType := DetectMediaMIMEType(header[:n], resp.Header.Get("Content-Type"))
+	if MediaTypeFromMIME(mimeType) != mediaType {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformReddit, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("unsupported %s type %q", mediaType, mimeType))
+	}
+
+	if limit := MaxMediaFileSize(models.PlatformReddit, mediaType); limit > 0 && size > limit {
+		os.Remove(out.Name())
+		return nil, platformapi.NewPlatformError(models.PlatformReddit, platformapi.ErrorCodeMediaRejected, 0, "",
+			fmt.Sprintf("%s is %.1f MB, Reddit allows at most %.1f MB", mediaType, megabytes(size), megabytes(limit)))
+	}
+
+	return &redditDownload{path: out.Name(), mimeType: mimeType}, nil
+}
+
+// uploadFile posts a downloaded file to the media store with the fields of its registration
+// Returns the URL of the stored file, from the Location of the store's response or, without
+// one, from the upload URL and the key the file was stored under
+func (s *RedditMediaService) uploadFile(ctx context.Context, asset redditMediaAsset, download *redditDownload) (string, error) {
+	file, err := os.Open(download.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer file.Close()
+
+	action := asset.Args.Action
+	if strings.HasPrefix(action, "//") {
+		action = "https:" + action
+	}
+
+	var key string
+	fields := make([][2]string, 0, len(asset.Args.Fields))
+	for _, field := range asset.Args.Fields {
+		fields = append(fields, [2]string{field.Name, field.Value})
+		if field.Name == "key" {
+			key = field.Value
+		}
+	}
+
+	// The file is streamed into the multipart body instead of being buffered, videos can be large
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeRedditUploadForm(form, file, fields, download.mimeType))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", action, body)
+	if err != nil {
+		body.Close()
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := s.uploadClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", platformapi.NewPlatformError(models.PlatformReddit, platformapi.CodeForStatus(resp.StatusCode), resp.StatusCode, "",
+			fmt.Sprintf("upload rejected: %s", string(responseBody)))
+	}
+
+	var stored struct {
+		Location string `xml:"Location"`
+	}
+	if xml.Unmarshal(responseBody, &stored) == nil && stored.Location != "" {
+		return stored.Location, nil
+	}
+	if key == "" {
+		return "", fmt.Errorf("media registration has no key")
+	}
+	return action + "/" + key, nil
+}
+
+// writeRedditUploadForm writes the fields and the file of an upload to the media store and closes the form
+// The fields have to come before the file, in the order of the registration
+func writeRedditUploadForm(form *multipart.Writer, file io.Reader, fields [][2]string, mimeType string) error {
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="file"; filename="upload.` + mediaFormatFromMIME(mimeType) + `"`},
+		"Content-Type":        {mimeType},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	return form.Close()
+}
@@ -0,0 +1,580 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Kinds of Reddit posts
+const (
+	RedditKindSelf  = "self" // A text post
+	RedditKindLink  = "link"
+	RedditKindImage = "image"
+	RedditKindVideo = "video"
+)
+
+// Submission types a subreddit allows, as in the submission_type of its about page
+const (
+	redditSubmissionAny  = "any"
+	redditSubmissionLink = "link" // Link, image and video posts
+	redditSubmissionSelf = "self"
+)
+
+// redditSubmitCheckInterval is how often the account's submissions are checked for an image or
+// video post Reddit is still processing; tests shorten it
+var redditSubmitCheckInterval = 2 * time.Second
+
+// redditMaxSubmitWait is how long to wait for Reddit to publish an image or video post
+const redditMaxSubmitWait = 2 * time.Minute
+
+// redditSubmittedPerRequest is how many of the account's newest submissions are searched for
+// an image or video post
+const redditSubmittedPerRequest = 25
+
+// redditSubredditsPerRequest is how many subreddits are requested per page of /subreddits/mine/subscriber
+const redditSubredditsPerRequest = 100
+
+// redditSubredditPattern matches a subreddit name
+var redditSubredditPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_]{1,20}$`)
+
+// NormalizeSubreddit returns a subreddit name without the r/ or /r/ it may be written with
+func NormalizeSubreddit(name string) string {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if len(name) > 2 && strings.EqualFold(name[:2], "r/") {
+		name = name[2:]
+	}
+	return name
+}
+
+// IsRedditSubredditName reports whether name, without r/, is a valid subreddit name
+func IsRedditSubredditName(name string) bool {
+	return redditSubredditPattern.MatchString(name)
+}
+
+// RedditPostService submits posts to subreddits, checking the subreddit's rules first
+// https://www.reddit.com/dev/api/#POST_api_submit
+type RedditPostService struct {
+	mediaService *RedditMediaService
+	api          *redditAPI
+}
+
+// NewRedditPostService creates a new Reddit post service
+func NewRedditPostService(mediaService *RedditMediaService) *RedditPostService {
+	return &RedditPostService{
+		mediaService: mediaService,
+		api:          mediaService.api,
+	}
+}
+
+// RedditSubmission is the content of a post
+type RedditSubmission struct {
+	Subreddit string // Without r/
+	Kind      string // RedditKindSelf, RedditKindLink, RedditKindImage or RedditKindVideo
+	Title     string
+	// Text is the body of a text post; on other kinds it is posted as the first comment
+	Text string
+
+	URL       string // Link of a link post
+	MediaURL  string // Image or video of an image or video post
+	PosterURL string // Thumbnail of a video post
+
+	FlairID   string // ID of a flair template of the subreddit
+	FlairText string // Replaces the flair's text, if the flair is editable
+	NSFW      bool
+	Spoiler   bool
+}
+
+// RedditPost is a submitted post
+type RedditPost struct {
+	ID   string // Without the t3_ prefix
+	Name string // Fullname, e.g. t3_abc123
+	URL  string
+}
+
+// RedditSubreddit is a subreddit as described by its about page
+type RedditSubreddit struct {
+	Name              string `json:"display_name"`
+	Title             string `json:"title"`
+	SubredditType     string `json:"subreddit_type"`  // public, restricted, private, ...
+	SubmissionType    string `json:"submission_type"` // any, link or self
+	AllowImages       bool   `json:"allow_images"`
+	AllowVideos       bool   `json:"allow_videos"`
+	Over18            bool   `json:"over18"`
+	Subscribers       int    `json:"subscribers"`
+	IconImg           string `json:"icon_img"`
+	UserIsBanned      bool   `json:"user_is_banned"`
+	UserIsContributor bool   `json:"user_is_contributor"` // Approved to post in a restricted subreddit
+	UserIsModerator   bool   `json:"user_is_moderator"`
+}
+
+// RedditFlair is a post flair template of a subreddit
+type RedditFlair struct {
+	ID           string `json:"id"`
+	Text         string `json:"text"`
+	TextEditable bool   `json:"text_editable"` // The text can be replaced when posting
+	ModOnly      bool   `json:"mod_only"`
+}
+
+// redditPostRequirements is the response of GET /api/v1/{subreddit}/post_requirements
+// Lengths are null when the subreddit doesn't limit them
+type redditPostRequirements struct {
+	TitleTextMinLength      *int     `json:"title_text_min_length"`
+	TitleTextMaxLength      *int     `json:"title_text_max_length"`
+	TitleRequiredStrings    []string `json:"title_required_strings"`
+	TitleBlacklistedStrings []string `json:"title_blacklisted_strings"`
+	BodyRestrictionPolicy   string   `json:"body_restriction_policy"` // none, required or notAllowed
+	BodyTextMinLength       *int     `json:"body_text_min_length"`
+	BodyTextMaxLength       *int     `json:"body_text_max_length"`
+	BodyBlacklistedStrings  []string `json:"body_blacklisted_strings"`
+	LinkRestrictionPolicy   string   `json:"link_restriction_policy"` // none, whitelist or blacklist
+	DomainWhitelist         []string `json:"domain_whitelist"`
+	DomainBlacklist         []string `json:"domain_blacklist"`
+	IsFlairRequired         bool     `json:"is_flair_required"`
+}
+
+// redditFormResponse is the response of a form posted with api_type=json
+type redditFormResponse struct {
+	JSON struct {
+		Errors    [][]interface{} `json:"errors"`
+		Ratelimit float64         `json:"ratelimit"`
+		Data      struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// redditListing is a page of a listing, like the submissions of an account
+type redditListing struct {
+	Data struct {
+		After    string `json:"after"`
+		Children []struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// redditSubmitted is a post in the submissions of an account
+type redditSubmitted struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Title      string  `json:"title"`
+	Subreddit  string  `json:"subreddit"`
+	Permalink  string  `json:"permalink"`
+	CreatedUTC float64 `json:"created_utc"`
+}
+
+// Submit checks the subreddit's rules and submits a post
+// Images and videos are uploaded first. Reddit doesn't return the ID of image and video posts,
+// which it publishes once it has processed the media, so the account's submissions are searched
+// for them; username is the account's name
+func (s *RedditPostService) Submit(ctx context.Context, accessToken, username string, submission RedditSubmission) (*RedditPost, error) {
+	if err := s.CheckRules(ctx, accessToken, submission); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("api_type", "json")
+	form.Set("sr", submission.Subreddit)
+	form.Set("kind", submission.Kind)
+	form.Set("title", submission.Title)
+	form.Set("nsfw", strconv.FormatBool(submission.NSFW))
+	form.Set("spoiler", strconv.FormatBool(submission.Spoiler))
+	form.Set("sendreplies", "true")
+	if submission.FlairID != "" {
+		form.Set("flair_id", submission.FlairID)
+	}
+	if submission.FlairText != "" {
+		form.Set("flair_text", submission.FlairText)
+	}
+
+	switch submission.Kind {
+	case RedditKindSelf:
+		form.Set("text", submission.Text)
+	case RedditKindLink:
+		form.Set("url", submission.URL)
+	case RedditKindImage:
+		mediaURL, err := s.mediaService.Upload(ctx, accessToken, submission.MediaURL, MediaTypeImage)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("url", mediaURL)
+	case RedditKindVideo:
+		mediaURL, err := s.mediaService.Upload(ctx, accessToken, submission.MediaURL, MediaTypeVideo)
+		if err != nil {
+			return nil, err
+		}
+		posterURL, err := s.mediaService.Upload(ctx, accessToken, submission.PosterURL, MediaTypeImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload video poster: %w", err)
+		}
+		form.Set("url", mediaURL)
+		form.Set("video_poster_url", posterURL)
+	default:
+		return nil, fmt.Errorf("unsupported Reddit post kind %q", submission.Kind)
+	}
+
+	submittedAt := time.Now()
+	var submitResp redditFormResponse
+	if err := s.api.post(ctx, "/api/submit", accessToken, form, &submitResp); err != nil {
+		return nil, fmt.Errorf("failed to submit post: %w", err)
+	}
+	if len(submitResp.JSON.Errors) > 0 {
+		return nil, fmt.Errorf("failed to submit post: %w", redditFormError(http.StatusOK, submitResp.JSON.Errors, submitResp.JSON.Ratelimit))
+	}
+
+	post := &RedditPost{ID: submitResp.JSON.Data.ID, Name: submitResp.JSON.Data.Name, URL: submitResp.JSON.Data.URL}
+	if post.ID == "" {
+		if submission.Kind != RedditKindImage && submission.Kind != RedditKindVideo {
+			return nil, fmt.Errorf("submit response has no post ID")
+		}
+		found, err := s.waitForSubmitted(ctx, accessToken, username, submission, submittedAt)
+		if err != nil {
+			return nil, err
+		}
+		post = found
+	}
+	if post.Name == "" {
+		post.Name = "t3_" + post.ID
+	}
+	if post.URL == "" {
+		post.URL = RedditPostURL(post.ID)
+	}
+	log.Printf("Reddit post submitted to r/%s: %s", submission.Subreddit, post.ID)
+
+	if submission.Kind != RedditKindSelf && strings.TrimSpace(submission.Text) != "" {
+		// The post is already published, so a failed comment doesn't fail it
+		if err := s.Comment(ctx, accessToken, post.Name, submission.Text); err != nil {
+			log.Printf("Failed to add the caption of Reddit post %s as a comment: %v", post.ID, err)
+		}
+	}
+	return post, nil
+}
+
+// waitForSubmitted searches the account's newest submissions for an image or video post until
+// Reddit has published it
+func (s *RedditPostService) waitForSubmitted(ctx context.Context, accessToken, username string, submission RedditSubmission, submittedAt time.Time) (*RedditPost, error) {
+	params := url.Values{}
+	params.Set("sort", "new")
+	params.Set("limit", strconv.Itoa(redditSubmittedPerRequest))
+	// Allow for the clocks of Reddit and this server being apart
+	since := float64(submittedAt.Add(-time.Minute).Unix())
+
+	for {
+		var listing redditListing
+		if err := s.api.get(ctx, "/user/"+url.PathEscape(username)+"/submitted", accessToken, params, &listing); err != nil {
+			return nil, fmt.Errorf("failed to check submitted posts: %w", err)
+		}
+		for _, child := range listing.Data.Children {
+			var submitted redditSubmitted
+			if child.Kind != "t3" || json.Unmarshal(child.Data, &submitted) != nil {
+				continue
+			}
+			if submitted.Title == submission.Title && strings.EqualFold(submitted.Subreddit, submission.Subreddit) && submitted.CreatedUTC >= since {
+				return &RedditPost{ID: submitted.ID, Name: submitted.Name, URL: "https://www.reddit.com" + submitted.Permalink}, nil
+			}
+		}
+
+		if time.Since(submittedAt) > redditMaxSubmitWait {
+			// Reddit drops posts whose media it can't process without reporting it
+			return nil, platformapi.NewPlatformError(models.PlatformReddit, platformapi.ErrorCodeMediaRejected, 0, "",
+				fmt.Sprintf("the %s post did not appear in r/%s within %s", submission.Kind, submission.Subreddit, redditMaxSubmitWait))
+		}
+		if err := sleepContext(ctx, redditSubmitCheckInterval); err != nil {
+			return nil, fmt.Errorf("stopped waiting for the post: %w", err)
+		}
+	}
+}
+
+// Comment replies to a post or comment, identified by its fullname
+func (s *RedditPostService) Comment(ctx context.Context, accessToken, thingName, text string) error {
+	form := url.Values{}
+	form.Set("api_type", "json")
+	form.Set("thing_id", thingName)
+	form.Set("text", text)
+
+	var commentResp redditFormResponse
+	if err := s.api.post(ctx, "/api/comment", accessToken, form, &commentResp); err != nil {
+		return fmt.Errorf("failed to comment: %w", err)
+	}
+	if len(commentResp.JSON.Errors) > 0 {
+		return fmt.Errorf("failed to comment: %w", redditFormError(http.StatusOK, commentResp.JSON.Errors, commentResp.JSON.Ratelimit))
+	}
+	return nil
+}
+
+// CheckRules checks a submission against the rules of its subreddit: the kinds of posts it
+// allows, whether the account may post in it, its post requirements and its flairs
+// Every broken rule is reported in one content policy error, so nothing is submitted that
+// Reddit would reject
+func (s *RedditPostService) CheckRules(ctx context.Context, accessToken string, submission RedditSubmission) error {
+	subreddit, violation, err := s.about(ctx, accessToken, submission.Subreddit)
+	if err != nil {
+		return err
+	}
+	if violation != "" {
+		return redditRulesError(submission.Subreddit, []string{violation})
+	}
+
+	var violations []string
+	violations = append(violations, redditAccessViolations(subreddit)...)
+	violations = append(violations, redditKindViolations(subreddit, submission.Kind)...)
+
+	var requirements redditPostRequirements
+	if err := s.api.get(ctx, "/api/v1/"+url.PathEscape(submission.Subreddit)+"/post_requirements", accessToken, nil, &requirements); err != nil {
+		return fmt.Errorf("failed to get post requirements: %w", err)
+	}
+	violations = append(violations, redditRequirementViolations(requirements, submission)...)
+
+	if submission.FlairID != "" {
+		flairViolations, err := s.flairViolations(ctx, accessToken, subreddit, submission)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, flairViolations...)
+	}
+
+	if len(violations) > 0 {
+		return redditRulesError(submission.Subreddit, violations)
+	}
+	return nil
+}
+
+// about returns the about page of a subreddit
+// A subreddit that doesn't exist or that the account can't view is returned as a violation
+func (s *RedditPostService) about(ctx context.Context, accessToken, name string) (*RedditSubreddit, string, error) {
+	var about struct {
+		Kind string          `json:"kind"`
+		Data RedditSubreddit `json:"data"`
+	}
+	err := s.api.get(ctx, "/r/"+url.PathEscape(name)+"/about", accessToken, nil, &about)
+
+	var platformErr *platformapi.PlatformError
+	switch {
+	case errors.As(err, &platformErr) && platformErr.StatusCode == http.StatusNotFound:
+		return nil, "the subreddit doesn't exist", nil
+	case errors.As(err, &platformErr) && platformErr.StatusCode == http.StatusForbidden:
+		switch platformErr.PlatformCode {
+		case "private":
+			return nil, "the subreddit is private", nil
+		case "banned":
+			return nil, "the subreddit is banned", nil
+		case "quarantined":
+			return nil, "the subreddit is quarantined", nil
+		}
+		return nil, "", fmt.Errorf("failed to get subreddit: %w", err)
+	case err != nil:
+		return nil, "", fmt.Errorf("failed to get subreddit: %w", err)
+	case about.Kind != "t5":
+		// Reddit answers some names that don't exist with search results
+		return nil, "the subreddit doesn't exist", nil
+	}
+	return &about.Data, "", nil
+}
+
+// redditAccessViolations returns why the account may not post in a subreddit, if it may not
+func redditAccessViolations(subreddit *RedditSubreddit) []string {
+	switch {
+	case subreddit.UserIsBanned:
+		return []string{"the account is banned from the subreddit"}
+	case subreddit.UserIsModerator:
+		return nil
+	case subreddit.SubredditType == "restricted" && !subreddit.UserIsContributor:
+		return []string{"only approved users may post in the subreddit"}
+	case subreddit.SubredditType == "archived":
+		return []string{"the subreddit is archived"}
+	}
+	return nil
+}
+
+// redditKindViolations returns why a subreddit doesn't allow a kind of post, if it doesn't
+func redditKindViolations(subreddit *RedditSubreddit, kind string) []string {
+	submissionType := subreddit.SubmissionType
+	if submissionType == "" {
+		submissionType = redditSubmissionAny
+	}
+
+	switch kind {
+	case RedditKindSelf:
+		if submissionType == redditSubmissionLink {
+			return []string{"text posts are not allowed"}
+		}
+	case RedditKindLink:
+		if submissionType == redditSubmissionSelf {
+			return []string{"link posts are not allowed"}
+		}
+	case RedditKindImage:
+		if submissionType == redditSubmissionSelf || !subreddit.AllowImages {
+			return []string{"image posts are not allowed"}
+		}
+	case RedditKindVideo:
+		if submissionType == redditSubmissionSelf || !subreddit.AllowVideos {
+			return []string{"video posts are not allowed"}
+		}
+	}
+	return nil
+}
+
+// redditRequirementViolations returns the post requirements of a subreddit a submission breaks
+func redditRequirementViolations(requirements redditPostRequirements, submission RedditSubmission) []string {
+	var violations []string
+
+	titleLength := utf8.RuneCountInString(submission.Title)
+	if minLength := requirements.TitleTextMinLength; minLength != nil && titleLength < *minLength {
+		violations = append(violations, fmt.Sprintf("the title must be at least %d characters", *minLength))
+	}
+	if maxLength := requirements.TitleTextMaxLength; maxLength != nil && titleLength > *maxLength {
+		violations = append(violations, fmt.Sprintf("the title must be at most %d characters", *maxLength))
+	}
+	if len(requirements.TitleRequiredStrings) > 0 && redditFindString(submission.Title, requirements.TitleRequiredStrings) == "" {
+		violations = append(violations, fmt.Sprintf("the title must contain one of: %s", strings.Join(requirements.TitleRequiredStrings, ", ")))
+	}
+	if found := redditFindString(submission.Title, requirements.TitleBlacklistedStrings); found != "" {
+		violations = append(violations, fmt.Sprintf("the title may not contain %q", found))
+	}
+
+	if submission.Kind == RedditKindSelf {
+		bodyLength := utf8.RuneCountInString(strings.TrimSpace(submission.Text))
+		switch {
+		case requirements.BodyRestrictionPolicy == "required" && bodyLength == 0:
+			violations = append(violations, "text posts must have a body")
+		case requirements.BodyRestrictionPolicy == "notAllowed" && bodyLength > 0:
+			violations = append(violations, "text posts may not have a body")
+		}
+		if minLength := requirements.BodyTextMinLength; minLength != nil && bodyLength > 0 && bodyLength < *minLength {
+			violations = append(violations, fmt.Sprintf("the body must be at least %d characters", *minLength))
+		}
+		if maxLength := requirements.BodyTextMaxLength; maxLength != nil && bodyLength > *maxLength {
+			violations = append(violations, fmt.Sprintf("the body must be at most %d characters", *maxLength))
+		}
+		if found := redditFindString(submission.Text, requirements.BodyBlacklistedStrings); found != "" {
+			violations = append(violations, fmt.Sprintf("the body may not contain %q", found))
+		}
+	}
+
+	if submission.Kind == RedditKindLink {
+		if link, err := url.Parse(submission.URL); err == nil {
+			host := strings.ToLower(link.Hostname())
+			switch {
+			case requirements.LinkRestrictionPolicy == "whitelist" && !redditDomainListed(host, requirements.DomainWhitelist):
+				violations = append(violations, fmt.Sprintf("links to %s are not allowed", host))
+			case redditDomainListed(host, requirements.DomainBlacklist):
+				violations = append(violations, fmt.Sprintf("links to %s are not allowed", host))
+			}
+		}
+	}
+
+	if requirements.IsFlairRequired && submission.FlairID == "" {
+		violations = append(violations, "posts must have a flair")
+	}
+	return violations
+}
+
+// flairViolations checks the flair of a submission against the subreddit's flair templates
+func (s *RedditPostService) flairViolations(ctx context.Context, accessToken string, subreddit *RedditSubreddit, submission RedditSubmission) ([]string, error) {
+	flairs, err := s.ListFlairs(ctx, accessToken, submission.Subreddit)
+	var platformErr *platformapi.PlatformError
+	if errors.As(err, &platformErr) && platformErr.StatusCode == http.StatusForbidden {
+		return []string{"the subreddit doesn't let posts choose a flair"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, flair := range flairs {
+		if flair.ID != submission.FlairID {
+			continue
+		}
+		var violations []string
+		if flair.ModOnly && !subreddit.UserIsModerator {
+			violations = append(violations, fmt.Sprintf("only moderators may use the flair %q", flair.Text))
+		}
+		if submission.FlairText != "" && !flair.TextEditable {
+			violations = append(violations, fmt.Sprintf("the text of the flair %q can't be changed", flair.Text))
+		}
+		return violations, nil
+	}
+	return []string{"the flair is not one of the subreddit's flairs"}, nil
+}
+
+// redditRulesError reports the rules of a subreddit a submission breaks
+func redditRulesError(subreddit string, violations []string) error {
+	return platformapi.NewPlatformError(models.PlatformReddit, platformapi.ErrorCodeContentPolicy, 0, "SUBREDDIT_RULES",
+		fmt.Sprintf("r/%s: %s", subreddit, strings.Join(violations, "; ")))
+}
+
+// redditFindString returns the first of values that text contains, ignoring case
+func redditFindString(text string, values []string) string {
+	lower := strings.ToLower(text)
+	for _, value := range values {
+		if value != "" && strings.Contains(lower, strings.ToLower(value)) {
+			return value
+		}
+	}
+	return ""
+}
+
+// redditDomainListed reports whether host is one of domains or a subdomain of one
+func redditDomainListed(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListFlairs returns the post flair templates of a subreddit
+func (s *RedditPostService) ListFlairs(ctx context.Context, accessToken, subreddit string) ([]RedditFlair, error) {
+	flairs := []RedditFlair{}
+	if err := s.api.get(ctx, "/r/"+url.PathEscape(subreddit)+"/api/link_flair_v2", accessToken, nil, &flairs); err != nil {
+		return nil, fmt.Errorf("failed to list flairs: %w", err)
+	}
+	return flairs, nil
+}
+
+// ListSubreddits returns the subreddits the account is subscribed to, following every page
+func (s *RedditPostService) ListSubreddits(ctx context.Context, accessToken string) ([]RedditSubreddit, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(redditSubredditsPerRequest))
+
+	subreddits := []RedditSubreddit{}
+	for {
+		var listing redditListing
+		if err := s.api.get(ctx, "/subreddits/mine/subscriber", accessToken, params, &listing); err != nil {
+			return nil, fmt.Errorf("failed to list subreddits: %w", err)
+		}
+		for _, child := range listing.Data.Children {
+			var subreddit RedditSubreddit
+			if child.Kind == "t5" && json.Unmarshal(child.Data, &subreddit) == nil {
+				subreddits = append(subreddits, subreddit)
+			}
+		}
+
+		if listing.Data.After == "" {
+			return subreddits, nil
+		}
+		params.Set("after", listing.Data.After)
+	}
+}
+
+// RedditPostURL returns the URL of a post
+func RedditPostURL(postID string) string {
+	return "https://www.reddit.com/comments/" + postID + "/"
+}
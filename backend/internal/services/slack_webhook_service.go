@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// slackWebhookPathPattern matches the part of a webhook URL after the base URL: the IDs of the
+// workspace and the webhook, and the webhook's token
+var slackWebhookPathPattern = regexp.MustCompile(`^(T[A-Z0-9]+)/(B[A-Z0-9]+)/([A-Za-z0-9]+)$`)
+
+// slackMissingTextErrors are the errors a webhook answers an empty message with, which is how a
+// webhook is verified without posting to its channel
+var slackMissingTextErrors = map[string]bool{
+	"no_text": true,
+	"missing_text_or_fallback_or_attachments": true,
+}
+
+// slackTextEscaper escapes the characters Slack reads as markup in mrkdwn text
+// https://api.slack.com/reference/surfaces/formatting#escaping
+var slackTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackWebhookService sends messages with the incoming webhooks of Slack channels
+// The webhook URL, which contains the webhook's token, is the only credential. Incoming webhooks
+// can't upload files and don't return the message they sent
+// https://api.slack.com/messaging/webhooks
+type SlackWebhookService struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSlackWebhookService creates a new Slack webhook service for the webhooks under webhookBaseURL
+func NewSlackWebhookService(webhookBaseURL string) *SlackWebhookService {
+	return &SlackWebhookService{
+		baseURL:    strings.TrimSuffix(webhookBaseURL, "/"),
+		httpClient: newHTTPClient("slack", 30*time.Second),
+	}
+}
+
+// SlackMessageRequest is the content of a message
+type SlackMessageRequest struct {
+	Text      string
+	ImageURLs []string // Shown in image blocks; Slack fetches them from their URL
+	AltText   string
+
+	DisableLinkPreview bool
+}
+
+// NormalizeWebhookURL checks that webhookURL is a webhook under the configured base URL
+// Returns the webhook URL without trailing slash and the webhook's ID, made of the IDs of the
+// workspace and the webhook, or an error wrapping platformapi.ErrInvalidCredentials
+func (s *SlackWebhookService) NormalizeWebhookURL(webhookURL string) (normalized, webhookID string, err error) {
+	webhookURL = strings.TrimSuffix(strings.TrimSpace(webhookURL), "/")
+
+	rest, ok := strings.CutPrefix(webhookURL, s.baseURL+"/")
+	match := slackWebhookPathPattern.FindStringSubmatch(rest)
+	if !ok || match == nil {
+		return "", "", fmt.Errorf("%w: not a Slack webhook URL, like %s/T000/B000/XXXX", platformapi.ErrInvalidCredentials, s.baseURL)
+	}
+	return webhookURL, match[1] + "/" + match[2], nil
+}
+
+// VerifyWebhook checks that a webhook exists without posting to its channel
+// An empty message is rejected with no_text by a working webhook, and with invalid_token or
+// another error by a webhook that was removed. Returns an error wrapping
+// platformapi.ErrInvalidCredentials if the webhook can't be posted with
+func (s *SlackWebhookService) VerifyWebhook(ctx context.Context, webhookURL string) error {
+	err := s.post(ctx, webhookURL, map[string]interface{}{})
+	if err == nil {
+		return nil
+	}
+
+	var platformErr *platformapi.PlatformError
+	if !errors.As(err, &platformErr) {
+		return fmt.Errorf("failed to verify webhook: %w", err)
+	}
+	if slackMissingTextErrors[platformErr.PlatformCode] {
+		return nil
+	}
+	switch platformErr.Code {
+	case platformapi.ErrorCodeAuthExpired, platformapi.ErrorCodeInsufficientScope:
+		return fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+	}
+	if platformErr.StatusCode == http.StatusNotFound || platformErr.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+	}
+	return fmt.Errorf("failed to verify webhook: %w", err)
+}
+
+// SendMessage sends a message with a webhook
+// The text is a section block, followed by an image block per image; the text is also the
+// message's notification text
+func (s *SlackWebhookService) SendMessage(ctx context.Context, webhookURL string, req SlackMessageRequest) error {
+	blocks := []map[string]interface{}{}
+	if req.Text != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": slackTextEscaper.Replace(req.Text)},
+		})
+	}
+
+	altText := req.AltText
+	if altText == "" {
+		altText = "Image"
+	}
+	for _, imageURL := range req.ImageURLs {
+		blocks = append(blocks, map[string]interface{}{
+			"type":      "image",
+			"image_url": imageURL,
+			"alt_text":  altText,
+		})
+	}
+
+	notificationText := slackTextEscaper.Replace(req.Text)
+	if notificationText == "" {
+		notificationText = altText
+	}
+
+	payload := map[string]interface{}{
+		"text":   notificationText,
+		"blocks": blocks,
+	}
+	if req.DisableLinkPreview {
+		payload["unfurl_links"] = false
+		payload["unfurl_media"] = false
+	}
+
+	if err := s.post(ctx, webhookURL, payload); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	log.Printf("Slack message sent with %d image(s)", len(req.ImageURLs))
+	return nil
+}
+
+// post sends a JSON payload to a webhook
+// Webhooks answer "ok" on success and a plain text error otherwise
+func (s *SlackWebhookService) post(ctx context.Context, webhookURL string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(data))
+	if err != nil {
+		// The error would include the webhook's token
+		return fmt.Errorf("failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", httpclient.RedactError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return slackWebhookError(resp, body)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+)
+
+// telegramAPI calls Bot API methods
+// The bot token is part of every method's URL, so errors never include the URL
+// https://core.telegram.org/bots/api#making-requests
+type telegramAPI struct {
+	baseURL    string // e.g. https://api.telegram.org
+	httpClient *http.Client
+}
+
+// call sends a JSON payload to a Bot API method and decodes the method's result into out
+func (a *telegramAPI) call(ctx context.Context, botToken, method string, payload, out interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/bot"+botToken+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request", method)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, httpclient.RedactError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var envelope struct {
+		OK     bool            `json:"ok"`
+		Result json.RawMessage `json:"result"`
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || json.Unmarshal(body, &envelope) != nil || !envelope.OK {
+		return telegramError(resp, body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(envelope.Result, out); err != nil {
+			return fmt.Errorf("failed to parse %s result: %w", method, err)
+		}
+	}
+	return nil
+}
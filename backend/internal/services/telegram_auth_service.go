@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Telegram chat types
+const (
+	TelegramChatChannel    = "channel"
+	TelegramChatSupergroup = "supergroup"
+	TelegramChatGroup      = "group"
+	TelegramChatPrivate    = "private"
+)
+
+// telegramBotTokenPattern matches the token @BotFather gives a bot, like 123456:ABC-DEF1234ghIkl
+var telegramBotTokenPattern = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]+$`)
+
+// telegramChatUsernamePattern matches the public username of a channel or group
+var telegramChatUsernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{3,31}$`)
+
+// TelegramAuthService verifies that a bot may post to a chat
+// Telegram has no OAuth: a chat is connected with the token of a bot that was added to it
+// https://core.telegram.org/bots/api#authorizing-your-bot
+type TelegramAuthService struct {
+	api *telegramAPI
+}
+
+// NewTelegramAuthService creates a new Telegram auth service
+func NewTelegramAuthService(apiBaseURL string) *TelegramAuthService {
+	return &TelegramAuthService{
+		api: &telegramAPI{
+			baseURL:    strings.TrimSuffix(apiBaseURL, "/"),
+			httpClient: newHTTPClient("telegram", 30*time.Second),
+		},
+	}
+}
+
+// TelegramUser is a user or bot, as returned by getMe
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username"`
+}
+
+// TelegramChat is a chat, as returned by getChat
+type TelegramChat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"` // channel, supergroup, group or private
+	Title    string `json:"title"`
+	Username string `json:"username"` // Public chats only
+}
+
+// telegramChatMember is the membership of a user in a chat, as returned by getChatMember
+type telegramChatMember struct {
+	Status          string `json:"status"` // creator, administrator, member, restricted, left or kicked
+	CanPostMessages *bool  `json:"can_post_messages"`
+	CanSendMessages *bool  `json:"can_send_messages"`
+}
+
+// TelegramConnection is a chat a bot was verified to post to
+type TelegramConnection struct {
+	Bot  TelegramUser
+	Chat TelegramChat
+}
+
+// TelegramCredential is what a connected chat is posted to with: the numeric ID of the chat and
+// the token of the bot
+type TelegramCredential struct {
+	ChatID   int64
+	BotToken string
+}
+
+// Encode returns the credential as the access token it is stored as
+func (c TelegramCredential) Encode() string {
+	return strconv.FormatInt(c.ChatID, 10) + "|" + c.BotToken
+}
+
+// ParseTelegramCredential reads a credential stored with Encode
+func ParseTelegramCredential(accessToken string) (TelegramCredential, error) {
+	chatID, botToken, ok := strings.Cut(accessToken, "|")
+	if !ok || botToken == "" {
+		return TelegramCredential{}, fmt.Errorf("invalid Telegram credential")
+	}
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	if err != nil {
+		return TelegramCredential{}, fmt.Errorf("invalid Telegram credential")
+	}
+	return TelegramCredential{ChatID: id, BotToken: botToken}, nil
+}
+
+// NormalizeTelegramChat turns what a user entered for a chat into a chat_id the Bot API accepts:
+// a numeric ID like -1001234567890, or @username for public chats, which may also be given as
+// a t.me link
+func NormalizeTelegramChat(chat string) (string, bool) {
+	chat = strings.TrimSpace(chat)
+	for _, prefix := range []string{"https://t.me/", "http://t.me/", "t.me/"} {
+		if strings.HasPrefix(chat, prefix) {
+			chat = strings.TrimSuffix(strings.TrimPrefix(chat, prefix), "/")
+			break
+		}
+	}
+	if _, err := strconv.ParseInt(chat, 10, 64); err == nil {
+		return chat, true
+	}
+	chat = strings.TrimPrefix(chat, "@")
+	if !telegramChatUsernamePattern.MatchString(chat) {
+		return "", false
+	}
+	return "@" + chat, true
+}
+
+// ConnectChat verifies that the bot the token belongs to may post to chat
+// Returns an error wrapping platformapi.ErrInvalidCredentials if the token is wrong, the chat
+// doesn't exist or the bot may not post to it
+func (s *TelegramAuthService) ConnectChat(ctx context.Context, chat, botToken string) (*TelegramConnection, error) {
+	botToken = strings.TrimSpace(botToken)
+	chatID, ok := NormalizeTelegramChat(chat)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a chat ID or a public channel username", platformapi.ErrInvalidCredentials, chat)
+	}
+	if !telegramBotTokenPattern.MatchString(botToken) {
+		return nil, fmt.Errorf("%w: the bot token is not a token @BotFather gives out", platformapi.ErrInvalidCredentials)
+	}
+	return s.verify(ctx, chatID, botToken)
+}
+
+// VerifyCredential checks that the bot of a stored credential may still post to its chat
+func (s *TelegramAuthService) VerifyCredential(ctx context.Context, credential TelegramCredential) (*TelegramConnection, error) {
+	return s.verify(ctx, strconv.FormatInt(credential.ChatID, 10), credential.BotToken)
+}
+
+// verify looks up the bot and the chat, and checks that the bot may post to the chat
+func (s *TelegramAuthService) verify(ctx context.Context, chatID, botToken string) (*TelegramConnection, error) {
+	var bot TelegramUser
+	if err := s.api.call(ctx, botToken, "getMe", struct{}{}, &bot); err != nil {
+		// Revoked tokens are answered with 401, tokens of bots that don't exist with 404
+		if telegramStatus(err) == http.StatusUnauthorized || telegramStatus(err) == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+		}
+		return nil, fmt.Errorf("failed to get Telegram bot: %w", err)
+	}
+
+	var chat TelegramChat
+	if err := s.api.call(ctx, botToken, "getChat", map[string]string{"chat_id": chatID}, &chat); err != nil {
+		if status := telegramStatus(err); status == http.StatusBadRequest || status == http.StatusForbidden {
+			return nil, fmt.Errorf("%w: the bot can't see chat %s, add it to the chat first: %w", platformapi.ErrInvalidCredentials, chatID, err)
+		}
+		return nil, fmt.Errorf("failed to get Telegram chat: %w", err)
+	}
+	if chat.Type == TelegramChatPrivate {
+		return nil, fmt.Errorf("%w: %s is a private chat, not a channel or group", platformapi.ErrInvalidCredentials, chatID)
+	}
+
+	var member telegramChatMember
+	if err := s.api.call(ctx, botToken, "getChatMember", map[string]interface{}{"chat_id": chat.ID, "user_id": bot.ID}, &member); err != nil {
+		return nil, fmt.Errorf("failed to get the bot's membership of the Telegram chat: %w", err)
+	}
+	if err := checkTelegramPostingRights(chat, member); err != nil {
+		return nil, fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+	}
+
+	return &TelegramConnection{Bot: bot, Chat: chat}, nil
+}
+
+// checkTelegramPostingRights checks that a member may post to a chat
+// Only administrators with the right to post messages can post to channels; groups let members
+// post unless they were restricted
+func checkTelegramPostingRights(chat TelegramChat, member telegramChatMember) error {
+	switch member.Status {
+	case "creator":
+		return nil
+	case "administrator":
+		if chat.Type == TelegramChatChannel && member.CanPostMessages != nil && !*member.CanPostMessages {
+			return fmt.Errorf("the bot is an administrator of the channel without the right to post messages")
+		}
+		return nil
+	case "member":
+		if chat.Type == TelegramChatChannel {
+			return fmt.Errorf("the bot has to be an administrator of the channel to post to it")
+		}
+		return nil
+	case "restricted":
+		if member.CanSendMessages != nil && !*member.CanSendMessages {
+			return fmt.Errorf("the bot may not send messages to the group")
+		}
+		return nil
+	default:
+		return fmt.Errorf("the bot is not a member of the chat")
+	}
+}
+
+// telegramStatus returns the HTTP status of a Bot API error, 0 for other errors
+func telegramStatus(err error) int {
+	var platformErr *platformapi.PlatformError
+	if errors.As(err, &platformErr) {
+		return platformErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// telegramCaptionMaxLength is the longest caption media can be sent with; longer texts are sent
+// as a message of their own
+const telegramCaptionMaxLength = 1024
+
+// TelegramPostService sends messages to the chats bots were added to
+// Media is sent by URL: Telegram downloads it itself
+// https://core.telegram.org/bots/api#sending-files
+type TelegramPostService struct {
+	api *telegramAPI
+}
+
+// NewTelegramPostService creates a new Telegram post service
+func NewTelegramPostService(authService *TelegramAuthService) *TelegramPostService {
+	return &TelegramPostService{api: authService.api}
+}
+
+// TelegramMessage is a sent message
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+}
+
+// TelegramPostRequest is the content of a post
+type TelegramPostRequest struct {
+	Text  string
+	Media []MediaItem
+
+	DisableNotification bool
+	ProtectContent      bool // Keep the messages from being forwarded and saved
+	DisableLinkPreview  bool
+}
+
+// SendPost sends a post to the chat of the credential
+// Text is sent with sendMessage, a single image or video with sendPhoto or sendVideo, and
+// several with sendMediaGroup, which shows them as an album. Texts too long to be a caption are
+// sent after the media
+// Returns the sent messages in order
+func (s *TelegramPostService) SendPost(ctx context.Context, credential TelegramCredential, req TelegramPostRequest) ([]TelegramMessage, error) {
+	if len(req.Media) == 0 {
+		message, err := s.sendText(ctx, credential, req, req.Text)
+		if err != nil {
+			return nil, err
+		}
+		return []TelegramMessage{*message}, nil
+	}
+
+	caption, separateText := req.Text, ""
+	if len([]rune(req.Text)) > telegramCaptionMaxLength {
+		caption, separateText = "", req.Text
+	}
+
+	messages, err := s.sendMedia(ctx, credential, req, caption)
+	if err != nil {
+		return nil, err
+	}
+
+	if separateText != "" {
+		message, err := s.sendText(ctx, credential, req, separateText)
+		if err != nil {
+			// The media is deleted again so that a retry doesn't send it twice
+			if deleteErr := s.DeleteMessages(ctx, credential, TelegramMessageIDs(messages)); deleteErr != nil {
+				log.Printf("Failed to delete Telegram media of a post whose text failed: %v", deleteErr)
+			}
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+
+	log.Printf("Telegram post sent to chat %d: %d message(s)", credential.ChatID, len(messages))
+	return messages, nil
+}
+
+// sendText sends a text message
+func (s *TelegramPostService) sendText(ctx context.Context, credential TelegramCredential, req TelegramPostRequest, text string) (*TelegramMessage, error) {
+	payload := telegramMessageOptions(credential, req)
+	payload["text"] = text
+	if req.DisableLinkPreview {
+		payload["link_preview_options"] = map[string]bool{"is_disabled": true}
+	}
+
+	var message TelegramMessage
+	if err := s.api.call(ctx, credential.BotToken, "sendMessage", payload, &message); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	return &message, nil
+}
+
+// sendMedia sends the media of a post with caption
+func (s *TelegramPostService) sendMedia(ctx context.Context, credential TelegramCredential, req TelegramPostRequest, caption string) ([]TelegramMessage, error) {
+	payload := telegramMessageOptions(credential, req)
+
+	if len(req.Media) == 1 {
+		item := req.Media[0]
+		if caption != "" {
+			payload["caption"] = caption
+		}
+
+		method := "sendPhoto"
+		if item.IsVideo {
+			method = "sendVideo"
+			payload["video"] = item.URL
+			payload["supports_streaming"] = true
+		} else {
+			payload["photo"] = item.URL
+		}
+
+		var message TelegramMessage
+		if err := s.api.call(ctx, credential.BotToken, method, payload, &message); err != nil {
+			return nil, fmt.Errorf("failed to send media: %w", err)
+		}
+		return []TelegramMessage{message}, nil
+	}
+
+	// The caption of the first item is shown as the caption of the album
+	media := make([]map[string]interface{}, 0, len(req.Media))
+	for i, item := range req.Media {
+		entry := map[string]interface{}{"type": "photo", "media": item.URL}
+		if item.IsVideo {
+			entry["type"] = "video"
+			entry["supports_streaming"] = true
+		}
+		if i == 0 && caption != "" {
+			entry["caption"] = caption
+		}
+		media = append(media, entry)
+	}
+	payload["media"] = media
+
+	var messages []TelegramMessage
+	if err := s.api.call(ctx, credential.BotToken, "sendMediaGroup", payload, &messages); err != nil {
+		return nil, fmt.Errorf("failed to send media group: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("media group response has no messages")
+	}
+	return messages, nil
+}
+
+// telegramMessageOptions returns the parameters every message of a post is sent with
+func telegramMessageOptions(credential TelegramCredential, req TelegramPostRequest) map[string]interface{} {
+	payload := map[string]interface{}{"chat_id": credential.ChatID}
+	if req.DisableNotification {
+		payload["disable_notification"] = true
+	}
+	if req.ProtectContent {
+		payload["protect_content"] = true
+	}
+	return payload
+}
+
+// DeleteMessages deletes messages of the chat of the credential
+// Bots can delete their messages for 48 hours after sending them
+func (s *TelegramPostService) DeleteMessages(ctx context.Context, credential TelegramCredential, messageIDs []int64) error {
+	payload := map[string]interface{}{
+		"chat_id":     credential.ChatID,
+		"message_ids": messageIDs,
+	}
+	if err := s.api.call(ctx, credential.BotToken, "deleteMessages", payload, nil); err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+	return nil
+}
+
+// TelegramMessageIDs returns the IDs of messages
+func TelegramMessageIDs(messages []TelegramMessage) []int64 {
+	ids := make([]int64, len(messages))
+	for i, message := range messages {
+		ids[i] = message.MessageID
+	}
+	return ids
+}
+
+// TelegramPostID returns the ID a post is stored with: the IDs of its messages, separated by commas
+func TelegramPostID(messages []TelegramMessage) string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = strconv.FormatInt(message.MessageID, 10)
+	}
+	return strings.Join(ids, ",")
+}
+
+// ParseTelegramPostID reads the message IDs of a post ID made by TelegramPostID
+func ParseTelegramPostID(postID string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(postID, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Telegram post ID %q", postID)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// TelegramMessageURL returns the link of a message
+// Messages of public chats are linked by the chat's username; those of private channels and
+// supergroups by the chat ID without its -100 prefix, which only members can open. Messages of
+// basic groups have no links
+func TelegramMessageURL(message TelegramMessage) string {
+	if message.Chat.Username != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", message.Chat.Username, message.MessageID)
+	}
+	if chatID := strconv.FormatInt(message.Chat.ID, 10); strings.HasPrefix(chatID, "-100") {
+		return fmt.Sprintf("https://t.me/c/%s/%d", strings.TrimPrefix(chatID, "-100"), message.MessageID)
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// threadsAPIVersion is the version of the Threads API the content endpoints are called with
+const threadsAPIVersion = "v1.0"
+
+// ThreadsAuthService handles Threads OAuth and the exchange and refresh of long-lived tokens
+// https://developers.facebook.com/docs/threads/get-started/get-access-tokens-and-permissions
+type ThreadsAuthService struct {
+	appID        string
+	appSecret    string
+	redirectURI  string
+	authBaseURL  string // Authorization page, e.g. https://threads.net
+	graphBaseURL string // Token exchange and Graph API, e.g. https://graph.threads.net
+	httpClient   *http.Client
+}
+
+// NewThreadsAuthService creates a new Threads auth service
+func NewThreadsAuthService(appID, appSecret, redirectURI, authBaseURL, graphBaseURL string) *ThreadsAuthService {
+	return &ThreadsAuthService{
+		appID:        appID,
+		appSecret:    appSecret,
+		redirectURI:  redirectURI,
+		authBaseURL:  authBaseURL,
+		graphBaseURL: graphBaseURL,
+		httpClient:   newHTTPClient("threads", 30*time.Second),
+	}
+}
+
+// ThreadsScopes are the permissions needed to read the profile and publish posts and replies
+var ThreadsScopes = []string{"threads_basic", "threads_content_publish"}
+
+// ThreadsTokenResponse represents the short-lived token returned for an authorization code
+type ThreadsTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	UserID      int64  `json:"user_id"`
+}
+
+// ThreadsLongLivedTokenResponse represents a long-lived token, from an exchange or a refresh
+type ThreadsLongLivedTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // Typically 5184000 (60 days)
+}
+
+// ThreadsUserInfo is the profile of a Threads user
+type ThreadsUserInfo struct {
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	ProfilePictureURL string `json:"threads_profile_picture_url"`
+}
+
+// GenerateAuthURL creates the OAuth authorization URL and the state that protects it
+func (s *ThreadsAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("client_id", s.appID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("scope", strings.Join(ThreadsScopes, ","))
+	params.Set("response_type", "code")
+	params.Set("state", state)
+
+	return s.authBaseURL + "/oauth/authorize?" + params.Encode(), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for a short-lived token, valid for 1 hour
+func (s *ThreadsAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*ThreadsTokenResponse, error) {
+	params := url.Values{}
+	params.Set("client_id", s.appID)
+	params.Set("client_secret", s.appSecret)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+
+	resp, err := postFormWithContext(ctx, s.httpClient, s.graphBaseURL+"/oauth/access_token", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	var tokenResp ThreadsTokenResponse
+	if err := s.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+	return &tokenResp, nil
+}
+
+// ExchangeLongLivedToken exchanges a short-lived token for a long-lived token
+// Short-lived tokens expire in 1 hour, long-lived tokens last 60 days
+func (s *ThreadsAuthService) ExchangeLongLivedToken(ctx context.Context, shortLivedToken string) (*ThreadsLongLivedTokenResponse, error) {
+	params := url.Values{}
+	params.Set("grant_type", "th_exchange_token")
+	params.Set("client_secret", s.appSecret)
+	params.Set("access_token", shortLivedToken)
+
+	resp, err := getWithContext(ctx, s.httpClient, s.graphBaseURL+"/access_token?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange for long-lived token: %w", err)
+	}
+
+	var tokenResp ThreadsLongLivedTokenResponse
+	if err := s.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Threads long-lived token obtained, expires in %d seconds (%.1f days)", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
+	return &tokenResp, nil
+}
+
+// RefreshLongLivedToken refreshes a long-lived token before it expires
+// Can only be refreshed if the token is at least 24 hours old and not expired
+// Returns a new long-lived token valid for 60 days
+func (s *ThreadsAuthService) RefreshLongLivedToken(ctx context.Context, longLivedToken string) (*ThreadsLongLivedTokenResponse, error) {
+	params := url.Values{}
+	params.Set("grant_type", "th_refresh_token")
+	params.Set("access_token", longLivedToken)
+
+	resp, err := getWithContext(ctx, s.httpClient, s.graphBaseURL+"/refresh_access_token?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh long-lived token: %w", err)
+	}
+
+	var tokenResp ThreadsLongLivedTokenResponse
+	if err := s.decode(resp, &tokenResp); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Threads token refreshed, new expiry in %d seconds (%.1f days)", tokenResp.ExpiresIn, float64(tokenResp.ExpiresIn)/86400)
+	return &tokenResp, nil
+}
+
+// GetUserInfo retrieves the profile of the user the token belongs to
+func (s *ThreadsAuthService) GetUserInfo(ctx context.Context, accessToken string) (*ThreadsUserInfo, error) {
+	params := url.Values{}
+	params.Set("fields", "id,username,name,threads_profile_picture_url")
+	params.Set("access_token", accessToken)
+
+	resp, err := getWithContext(ctx, s.httpClient, fmt.Sprintf("%s/%s/me?%s", s.graphBaseURL, threadsAPIVersion, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	var userInfo ThreadsUserInfo
+	if err := s.decode(resp, &userInfo); err != nil {
+		return nil, err
+	}
+	return &userInfo, nil
+}
+
+// decode reads a Threads response into v, or classifies it if it is unsuccessful
+func (s *ThreadsAuthService) decode(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return threadsError(resp, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
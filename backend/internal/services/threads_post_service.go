@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// threadsMaxWaitSeconds is how long a container may take to be processed before giving up
+const threadsMaxWaitSeconds = 300
+
+// ThreadsPostService publishes text, image, video and carousel posts and replies to Threads
+// Posts go through the same container flow as Instagram: every post is a container that is
+// created, processed and then published
+// https://developers.facebook.com/docs/threads/posts
+type ThreadsPostService struct {
+	mediaService *InstagramMediaService
+}
+
+// NewThreadsPostService creates a new Threads post service; mediaService comes from NewThreadsMediaService
+func NewThreadsPostService(mediaService *InstagramMediaService) *ThreadsPostService {
+	return &ThreadsPostService{mediaService: mediaService}
+}
+
+// ThreadsPostRequest is the content of a post
+type ThreadsPostRequest struct {
+	Text         string
+	Media        []MediaItem // None for a text post, one image or video, or a carousel of 2 to 20 items
+	ReplyToID    string      // ID of the post to reply to
+	ReplyControl string      // Who can reply: everyone, accounts_you_follow or mentioned_only
+}
+
+// ThreadsPost is a published post
+type ThreadsPost struct {
+	ID        string
+	Permalink string // Empty if it couldn't be retrieved
+}
+
+// CreatePost creates the container of a post, waits for it to be processed and publishes it
+func (s *ThreadsPostService) CreatePost(ctx context.Context, accessToken, userID string, req ThreadsPostRequest) (*ThreadsPost, error) {
+	// Replies and reply controls are set on the container that is published
+	extra := url.Values{}
+	if req.ReplyToID != "" {
+		extra.Set("reply_to_id", req.ReplyToID)
+	}
+	if req.ReplyControl != "" {
+		extra.Set("reply_control", req.ReplyControl)
+	}
+
+	var containerID string
+	var err error
+	switch len(req.Media) {
+	case 0:
+		containerID, err = s.createSingleContainer(ctx, accessToken, userID, "TEXT", "", "", req.Text, extra)
+	case 1:
+		if req.Media[0].IsVideo {
+			containerID, err = s.createSingleContainer(ctx, accessToken, userID, "VIDEO", "video_url", req.Media[0].URL, req.Text, extra)
+		} else {
+			containerID, err = s.createSingleContainer(ctx, accessToken, userID, "IMAGE", "image_url", req.Media[0].URL, req.Text, extra)
+		}
+	default:
+		containerID, err = s.createCarousel(ctx, accessToken, userID, req.Media, req.Text, extra)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Threads recommends waiting for every container, including text, before publishing it
+	if _, err := s.mediaService.WaitForMediaProcessing(ctx, accessToken, containerID, threadsMaxWaitSeconds); err != nil {
+		return nil, fmt.Errorf("processing failed: %w", err)
+	}
+
+	mediaID, err := s.mediaService.PublishMedia(ctx, accessToken, userID, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("publish failed: %w", err)
+	}
+
+	post := &ThreadsPost{ID: mediaID}
+	if permalink, err := s.mediaService.GetPermalink(ctx, accessToken, mediaID); err != nil {
+		log.Printf("Failed to get permalink of Threads post %s: %v", mediaID, err)
+	} else {
+		post.Permalink = permalink
+	}
+
+	log.Printf("Threads post published: %s", mediaID)
+	return post, nil
+}
+
+// createSingleContainer creates the container of a text, image or video post
+func (s *ThreadsPostService) createSingleContainer(ctx context.Context, accessToken, userID, mediaType, urlParam, mediaURL, text string, extra url.Values) (string, error) {
+	params := url.Values{}
+	for key, values := range extra {
+		params[key] = values
+	}
+	params.Set("media_type", mediaType)
+	if urlParam != "" {
+		params.Set(urlParam, mediaURL)
+	}
+	if text != "" {
+		params.Set("text", text)
+	}
+
+	containerID, err := s.mediaService.CreateContainer(ctx, accessToken, userID, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s container: %w", mediaType, err)
+	}
+	return containerID, nil
+}
+
+// createCarousel creates a container for every item, then the carousel container referencing them
+func (s *ThreadsPostService) createCarousel(ctx context.Context, accessToken, userID string, items []MediaItem, text string, extra url.Values) (string, error) {
+	childrenIDs := make([]string, 0, len(items))
+	for i, item := range items {
+		containerID, err := s.mediaService.CreateCarouselItemContainer(ctx, accessToken, userID, item.URL, item.IsVideo)
+		if err != nil {
+			return "", fmt.Errorf("failed to create container for item %d: %w", i+1, err)
+		}
+		childrenIDs = append(childrenIDs, containerID)
+
+		// Videos have to be processed before the carousel can reference them
+		if item.IsVideo {
+			if _, err := s.mediaService.WaitForMediaProcessing(ctx, accessToken, containerID, threadsMaxWaitSeconds); err != nil {
+				return "", fmt.Errorf("processing failed for video item %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return s.mediaService.createCarouselContainer(ctx, accessToken, userID, childrenIDs, text, extra)
+}
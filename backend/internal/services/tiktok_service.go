@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,18 +11,23 @@ import (
 	"time"
 
 	"github.com/osmanmertacar/sosyal/backend/internal/config"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
 )
 
+// Paths of the TikTok Open API endpoints, relative to TikTokConfig.APIBaseURL
 const (
-	tiktokTokenURL       = "https://open.tiktokapis.com/v2/oauth/token/"
-	tiktokUserInfoURL    = "https://open.tiktokapis.com/v2/user/info/"
-	tiktokPublishURL     = "https://open.tiktokapis.com/v2/post/publish/video/init/"
-	tiktokInboxURL       = "https://open.tiktokapis.com/v2/post/publish/inbox/video/init/"
-	tiktokPublishStatus  = "https://open.tiktokapis.com/v2/post/publish/status/fetch/"
-	tiktokContentInitURL = "https://open.tiktokapis.com/v2/post/publish/content/init/"
-	tiktokCreatorInfoURL = "https://open.tiktokapis.com/v2/post/publish/creator_info/query/"
+	tiktokTokenPath         = "/v2/oauth/token/"
+	tiktokUserInfoPath      = "/v2/user/info/"
+	tiktokPublishPath       = "/v2/post/publish/video/init/"
+	tiktokInboxPath         = "/v2/post/publish/inbox/video/init/"
+	tiktokPublishStatusPath = "/v2/post/publish/status/fetch/"
+	tiktokContentInitPath   = "/v2/post/publish/content/init/"
+	tiktokCreatorInfoPath   = "/v2/post/publish/creator_info/query/"
 )
 
+// TikTokAuthorizePath is the path of the TikTok authorization page, relative to TikTokConfig.AuthBaseURL
+const TikTokAuthorizePath = "/v2/auth/authorize/"
+
 type TikTokService struct {
 	config     *config.Config
 	httpClient *http.Client
@@ -49,15 +55,18 @@ type TikTokUserInfoResponse struct {
 // NewTikTokService creates a new TikTok service
 func NewTikTokService(cfg *config.Config) *TikTokService {
 	return &TikTokService{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		config:     cfg,
+		httpClient: newHTTPClient("tiktok", 30*time.Second),
 	}
 }
 
+// apiURL returns the URL of a TikTok Open API endpoint
+func (s *TikTokService) apiURL(path string) string {
+	return s.config.TikTok.APIBaseURL + path
+}
+
 // ExchangeCodeForTokens exchanges an authorization code for access and refresh tokens
-func (s *TikTokService) ExchangeCodeForTokens(code string) (*TikTokTokenResponse, error) {
+func (s *TikTokService) ExchangeCodeForTokens(ctx context.Context, code string) (*TikTokTokenResponse, error) {
 	// TikTok requires application/x-www-form-urlencoded
 	formData := url.Values{}
 	formData.Set("client_key", s.config.TikTok.ClientKey)
@@ -66,7 +75,7 @@ func (s *TikTokService) ExchangeCodeForTokens(code string) (*TikTokTokenResponse
 	formData.Set("grant_type", "authorization_code")
 	formData.Set("redirect_uri", s.config.TikTok.RedirectURI)
 
-	req, err := http.NewRequest("POST", tiktokTokenURL, bytes.NewBufferString(formData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL(tiktokTokenPath), bytes.NewBufferString(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -85,26 +94,19 @@ func (s *TikTokService) ExchangeCodeForTokens(code string) (*TikTokTokenResponse
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
-	// DEBUG: Log the raw response
-	fmt.Printf("DEBUG: Token exchange raw response: %s\n", string(responseBody))
-
 	var tokenResponse TikTokTokenResponse
 	if err := json.Unmarshal(responseBody, &tokenResponse); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// DEBUG: Log parsed token response
-	fmt.Printf("DEBUG: Parsed token - AccessToken length: %d, RefreshToken length: %d\n",
-		len(tokenResponse.AccessToken), len(tokenResponse.RefreshToken))
-
 	return &tokenResponse, nil
 }
 
 // RefreshAccessToken refreshes an access token using a refresh token
-func (s *TikTokService) RefreshAccessToken(refreshToken string) (*TikTokTokenResponse, error) {
+func (s *TikTokService) RefreshAccessToken(ctx context.Context, refreshToken string) (*TikTokTokenResponse, error) {
 	// TikTok requires application/x-www-form-urlencoded
 	formData := url.Values{}
 	formData.Set("client_key", s.config.TikTok.ClientKey)
@@ -112,7 +114,7 @@ func (s *TikTokService) RefreshAccessToken(refreshToken string) (*TikTokTokenRes
 	formData.Set("grant_type", "refresh_token")
 	formData.Set("refresh_token", refreshToken)
 
-	req, err := http.NewRequest("POST", tiktokTokenURL, bytes.NewBufferString(formData.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL(tiktokTokenPath), bytes.NewBufferString(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -131,7 +133,7 @@ func (s *TikTokService) RefreshAccessToken(refreshToken string) (*TikTokTokenRes
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var tokenResponse TikTokTokenResponse
@@ -143,11 +145,11 @@ func (s *TikTokService) RefreshAccessToken(refreshToken string) (*TikTokTokenRes
 }
 
 // GetUserInfo fetches user information from TikTok
-func (s *TikTokService) GetUserInfo(accessToken string) (*TikTokUserInfo, error) {
+func (s *TikTokService) GetUserInfo(ctx context.Context, accessToken string) (*TikTokUserInfo, error) {
 	// Use query parameters for fields (username is not available in v2 API)
-	url := tiktokUserInfoURL + "?fields=open_id,display_name,avatar_url"
+	url := s.apiURL(tiktokUserInfoPath) + "?fields=open_id,display_name,avatar_url"
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -157,14 +159,6 @@ func (s *TikTokService) GetUserInfo(accessToken string) (*TikTokUserInfo, error)
 	req.Header.Set("Authorization", authHeader)
 	req.Header.Set("Content-Type", "application/json")
 
-	// Log request details for debugging
-	tokenPreview := accessToken
-	if len(tokenPreview) > 10 {
-		tokenPreview = tokenPreview[:10] + "..."
-	}
-	fmt.Printf("DEBUG: Making GET request to: %s\n", url)
-	fmt.Printf("DEBUG: Authorization header: Bearer %s\n", tokenPreview)
-
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -176,12 +170,8 @@ func (s *TikTokService) GetUserInfo(accessToken string) (*TikTokUserInfo, error)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// DEBUG: Log the full response
-	fmt.Printf("DEBUG: User info response status: %d\n", resp.StatusCode)
-	fmt.Printf("DEBUG: User info response body: %s\n", string(responseBody))
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var userInfoResponse TikTokUserInfoResponse
@@ -191,7 +181,7 @@ func (s *TikTokService) GetUserInfo(accessToken string) (*TikTokUserInfo, error)
 
 	// Check for API errors (TikTok uses "ok" to indicate success)
 	if userInfoResponse.Error.Code != "" && userInfoResponse.Error.Code != "ok" {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", userInfoResponse.Error.Code, userInfoResponse.Error.Message)
+		return nil, newTikTokError(resp.StatusCode, resp.Header, userInfoResponse.Error.Code, userInfoResponse.Error.Message)
 	}
 
 	return &userInfoResponse.Data.User, nil
@@ -243,7 +233,7 @@ type PublishStatusResponse struct {
 }
 
 // PublishVideoFromURL publishes a video to TikTok from a URL
-func (s *TikTokService) PublishVideoFromURL(accessToken string, videoURL string, caption string, settings *TikTokPostSettings) (*PublishVideoResponse, error) {
+func (s *TikTokService) PublishVideoFromURL(ctx context.Context, accessToken string, videoURL string, caption string, settings *TikTokPostSettings) (*PublishVideoResponse, error) {
 	// Determine whether to use Direct Post or Send to Inbox
 	useDirectPost := true
 	if settings != nil {
@@ -294,9 +284,9 @@ func (s *TikTokService) PublishVideoFromURL(accessToken string, videoURL string,
 	}
 
 	// Choose endpoint based on publish mode
-	publishURL := tiktokPublishURL
+	publishURL := s.apiURL(tiktokPublishPath)
 	if !useDirectPost {
-		publishURL = tiktokInboxURL
+		publishURL = s.apiURL(tiktokInboxPath)
 	}
 
 	body, err := json.Marshal(requestBody)
@@ -304,9 +294,7 @@ func (s *TikTokService) PublishVideoFromURL(accessToken string, videoURL string,
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	fmt.Printf("DEBUG PublishVideo: POST %s body=%s\n", publishURL, string(body))
-
-	req, err := http.NewRequest("POST", publishURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", publishURL, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -325,10 +313,8 @@ func (s *TikTokService) PublishVideoFromURL(accessToken string, videoURL string,
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	fmt.Printf("DEBUG PublishVideo: status=%d response=%s\n", resp.StatusCode, string(responseBody))
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var publishResponse PublishVideoResponse
@@ -338,14 +324,14 @@ func (s *TikTokService) PublishVideoFromURL(accessToken string, videoURL string,
 
 	// Check for API errors (TikTok uses "ok" to indicate success)
 	if publishResponse.Error.Code != "" && publishResponse.Error.Code != "ok" {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", publishResponse.Error.Code, publishResponse.Error.Message)
+		return nil, newTikTokError(resp.StatusCode, resp.Header, publishResponse.Error.Code, publishResponse.Error.Message)
 	}
 
 	return &publishResponse, nil
 }
 
 // GetPublishStatus checks the status of a video publish
-func (s *TikTokService) GetPublishStatus(accessToken string, publishID string) (*PublishStatusResponse, error) {
+func (s *TikTokService) GetPublishStatus(ctx context.Context, accessToken string, publishID string) (*PublishStatusResponse, error) {
 	requestBody := map[string]interface{}{
 		"publish_id": publishID,
 	}
@@ -355,12 +341,12 @@ func (s *TikTokService) GetPublishStatus(accessToken string, publishID string) (
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	fmt.Printf("DEBUG GetPublishStatus: POST %s publish_id=%s\n", tiktokPublishStatus, publishID)
-
-	req, err := http.NewRequest("POST", tiktokPublishStatus, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL(tiktokPublishStatusPath), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	// Status queries only read, so they are safe to retry
+	req = httpclient.MarkIdempotent(req)
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+accessToken)
@@ -376,10 +362,8 @@ func (s *TikTokService) GetPublishStatus(accessToken string, publishID string) (
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	fmt.Printf("DEBUG GetPublishStatus: status=%d response=%s\n", resp.StatusCode, string(responseBody))
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var statusResponse PublishStatusResponse
@@ -389,7 +373,7 @@ func (s *TikTokService) GetPublishStatus(accessToken string, publishID string) (
 
 	// Check for API errors (TikTok uses "ok" to indicate success)
 	if statusResponse.Error.Code != "" && statusResponse.Error.Code != "ok" {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", statusResponse.Error.Code, statusResponse.Error.Message)
+		return nil, newTikTokError(resp.StatusCode, resp.Header, statusResponse.Error.Code, statusResponse.Error.Message)
 	}
 
 	return &statusResponse, nil
@@ -405,16 +389,18 @@ type CreatorInfoResponse struct {
 }
 
 // GetCreatorInfo fetches creator posting capabilities from TikTok's Creator Info API
-func (s *TikTokService) GetCreatorInfo(accessToken string) (*CreatorInfoResponse, error) {
+func (s *TikTokService) GetCreatorInfo(ctx context.Context, accessToken string) (*CreatorInfoResponse, error) {
 	body, err := json.Marshal(map[string]any{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", tiktokCreatorInfoURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL(tiktokCreatorInfoPath), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	// Creator info queries only read, so they are safe to retry
+	req = httpclient.MarkIdempotent(req)
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+accessToken)
@@ -430,10 +416,8 @@ func (s *TikTokService) GetCreatorInfo(accessToken string) (*CreatorInfoResponse
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	fmt.Printf("DEBUG GetCreatorInfo: status=%d response=%s\n", resp.StatusCode, string(responseBody))
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var raw struct {
@@ -448,14 +432,14 @@ func (s *TikTokService) GetCreatorInfo(accessToken string) (*CreatorInfoResponse
 	}
 
 	if raw.Error.Code != "" && raw.Error.Code != "ok" {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", raw.Error.Code, raw.Error.Message)
+		return nil, newTikTokError(resp.StatusCode, resp.Header, raw.Error.Code, raw.Error.Message)
 	}
 
 	return &raw.Data, nil
 }
 
 // PublishPhotoFromURL publishes a photo post to TikTok from one or more image URLs
-func (s *TikTokService) PublishPhotoFromURL(accessToken string, imageURLs []string, caption string, settings *TikTokPostSettings) (*PublishVideoResponse, error) {
+func (s *TikTokService) PublishPhotoFromURL(ctx context.Context, accessToken string, imageURLs []string, caption string, settings *TikTokPostSettings) (*PublishVideoResponse, error) {
 	if len(imageURLs) == 0 {
 		return nil, fmt.Errorf("at least one image URL is required")
 	}
@@ -525,7 +509,7 @@ func (s *TikTokService) PublishPhotoFromURL(accessToken string, imageURLs []stri
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", tiktokContentInitURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL(tiktokContentInitPath), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -545,7 +529,7 @@ func (s *TikTokService) PublishPhotoFromURL(accessToken string, imageURLs []stri
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", resp.Status, string(responseBody))
+		return nil, tiktokError(resp, responseBody)
 	}
 
 	var publishResponse PublishVideoResponse
@@ -555,7 +539,7 @@ func (s *TikTokService) PublishPhotoFromURL(accessToken string, imageURLs []stri
 
 	// Check for API errors (TikTok uses "ok" to indicate success)
 	if publishResponse.Error.Code != "" && publishResponse.Error.Code != "ok" {
-		return nil, fmt.Errorf("TikTok API error: %s - %s", publishResponse.Error.Code, publishResponse.Error.Message)
+		return nil, newTikTokError(resp.StatusCode, resp.Header, publishResponse.Error.Code, publishResponse.Error.Message)
 	}
 
 	return &publishResponse, nil
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -77,7 +78,7 @@ func (s *TokenService) SaveTokens(userID int64, tokenResponse *TikTokTokenRespon
 }
 
 // GetValidToken retrieves a valid access token for a user (refreshes if expired)
-func (s *TokenService) GetValidToken(userID int64) (string, error) {
+func (s *TokenService) GetValidToken(ctx context.Context, userID int64) (string, error) {
 	// Get token from database
 	token, err := s.tokenRepo.GetByUserID(userID)
 	if err != nil {
@@ -99,7 +100,7 @@ func (s *TokenService) GetValidToken(userID int64) (string, error) {
 		}
 
 		// Refresh the token
-		newTokenResponse, err := s.RefreshToken(refreshToken)
+		newTokenResponse, err := s.RefreshToken(ctx, refreshToken)
 		if err != nil {
 			return "", fmt.Errorf("failed to refresh token: %w", err)
 		}
@@ -116,12 +117,12 @@ func (s *TokenService) GetValidToken(userID int64) (string, error) {
 }
 
 // RefreshToken refreshes an expired access token using the refresh token
-func (s *TokenService) RefreshToken(refreshToken string) (*TikTokTokenResponse, error) {
+func (s *TokenService) RefreshToken(ctx context.Context, refreshToken string) (*TikTokTokenResponse, error) {
 	if s.tiktokService == nil {
 		return nil, fmt.Errorf("TikTok service not initialized")
 	}
 
-	return s.tiktokService.RefreshAccessToken(refreshToken)
+	return s.tiktokService.RefreshAccessToken(ctx, refreshToken)
 }
 
 // DeleteTokens deletes all tokens for a user (logout)
@@ -66,12 +66,24 @@ func ValidateMediaURL(rawURL string) error {
 	}
 
 	for _, ip := range ips {
-		for _, network := range parsedPrivateRanges {
-			if network.Contains(ip) {
-				return fmt.Errorf("URL resolves to a private/internal address")
-			}
+		if isPrivateIP(ip) {
+			return fmt.Errorf("URL resolves to a private/internal address")
 		}
 	}
 
 	return nil
 }
+
+// isPrivateIP reports whether ip is in one of the blocked ranges, or unspecified (0.0.0.0 or ::),
+// which reaches the local host
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range parsedPrivateRanges {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
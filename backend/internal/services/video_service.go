@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -33,22 +34,20 @@ type VideoInfo struct {
 // NewVideoService creates a new video service
 func NewVideoService() *VideoService {
 	return &VideoService{
-		httpClient: &http.Client{
-			Timeout: 5 * time.Minute, // Allow time for large video downloads
-		},
-		tempDir: os.TempDir(),
+		httpClient: newHTTPClient("video-download", 5*time.Minute), // Allow time for large video downloads
+		tempDir:    os.TempDir(),
 	}
 }
 
 // DownloadVideo downloads a video from a URL to a temporary file
-func (s *VideoService) DownloadVideo(url string) (*VideoInfo, error) {
+func (s *VideoService) DownloadVideo(ctx context.Context, url string) (*VideoInfo, error) {
 	// Validate URL
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 		return nil, fmt.Errorf("invalid URL: must start with http:// or https://")
 	}
 
 	// Create request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -78,7 +77,7 @@ func (s *VideoService) DownloadVideo(url string) (*VideoInfo, error) {
 	}
 
 	// Create temporary file
-	tempFile, err := os.CreateTemp(s.tempDir, "tiktok_video_*"+extension)
+	tempFile, err := os.CreateTemp(s.tempDir, "video_*"+extension)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary file: %w", err)
 	}
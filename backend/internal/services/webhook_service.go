@@ -0,0 +1,335 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// Headers sent with every delivery
+const (
+	WebhookEventHeader     = "X-Sosyal-Event"
+	WebhookDeliveryHeader  = "X-Sosyal-Delivery"  // Same for every attempt of a delivery, for deduplication
+	WebhookTimestampHeader = "X-Sosyal-Timestamp" // Unix time the delivery was signed at
+	WebhookSignatureHeader = "X-Sosyal-Signature" // sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+)
+
+// Events deliveries are sent for
+const (
+	WebhookEventPing    = "ping"         // Sent when an endpoint is connected, to verify it
+	WebhookEventPublish = "post.publish" // Asks the endpoint to publish a post
+)
+
+// webhookMinSecretLength is the shortest secret deliveries may be signed with
+const webhookMinSecretLength = 16
+
+// webhookResponseLimit is how much of an endpoint's response is read
+const webhookResponseLimit = 64 * 1024
+
+// WebhookService sends posts to endpoints of the users' own systems, like a website CMS
+// Every delivery is a JSON document POSTed to the endpoint and signed with the secret the user
+// connected it with; the endpoint answers with the ID and link of what it published
+type WebhookService struct {
+	httpClient   *http.Client
+	allowedHosts map[string]bool
+	allowPrivate bool
+}
+
+// NewWebhookService creates a new webhook service
+// allowedHosts limits endpoints to these hosts, which may be on private networks; allowPrivate
+// allows any private endpoint and plain http, for local development
+func NewWebhookService(allowedHosts []string, allowPrivate bool) *WebhookService {
+	hosts := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		hosts[strings.ToLower(host)] = true
+	}
+
+	s := &WebhookService{
+		allowedHosts: hosts,
+		allowPrivate: allowPrivate,
+	}
+
+	// Connections go straight to the address checked by dialContext, never through a proxy
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = s.dialContext
+	s.httpClient = httpclient.New(httpclient.Options{
+		Name:         "webhook",
+		Timeout:      30 * time.Second,
+		MaxRetries:   httpMaxRetries,
+		RetryBackoff: httpRetryBackoff,
+		Transport:    transport,
+	})
+	// Redirects could lead anywhere, including private hosts; they fail the delivery instead
+	s.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return s
+}
+
+// dialContext connects to an endpoint, checking the addresses its host resolves to right before
+// connecting to one of them, so that a host can't pass NormalizeURL and then resolve to a
+// private address. Allowed hosts, and every host in development, are dialed as usual
+func (s *WebhookService) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if s.allowPrivate || s.allowedHosts[strings.ToLower(host)] {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return nil, fmt.Errorf("endpoint host %s resolves to a private/internal address", host)
+		}
+	}
+
+	var dialErr error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	if dialErr == nil {
+		dialErr = fmt.Errorf("host %s has no addresses", host)
+	}
+	return nil, dialErr
+}
+
+// WebhookCredential is what an endpoint is connected with, stored as the access token
+type WebhookCredential struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// Encode returns the credential as stored in the access token
+func (c WebhookCredential) Encode() string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+
+// ParseWebhookCredential parses a credential stored by Encode
+func ParseWebhookCredential(token string) (WebhookCredential, error) {
+	var credential WebhookCredential
+	if err := json.Unmarshal([]byte(token), &credential); err != nil || credential.URL == "" || credential.Secret == "" {
+		return WebhookCredential{}, fmt.Errorf("invalid webhook credential")
+	}
+	return credential, nil
+}
+
+// WebhookDelivery is the JSON document sent to an endpoint
+type WebhookDelivery struct {
+	Event      string       `json:"event"`
+	DeliveryID string       `json:"delivery_id"`
+	Post       *WebhookPost `json:"post,omitempty"` // Not sent with pings
+}
+
+// WebhookPost is a post an endpoint is asked to publish
+type WebhookPost struct {
+	ID            int64    `json:"id"`
+	PublicationID string   `json:"publication_id,omitempty"`
+	Caption       string   `json:"caption"`
+	MediaURLs     []string `json:"media_urls"`
+}
+
+// WebhookResult is what an endpoint answered a delivery with
+type WebhookResult struct {
+	ID  string `json:"id"`  // ID of what the endpoint published, optional
+	URL string `json:"url"` // Link to it, optional
+}
+
+// NormalizeURL checks that an endpoint may be called and returns its URL without fragment
+// Endpoints must use https and be on a public host, or on one of the allowed hosts. Returns an
+// error wrapping platformapi.ErrInvalidCredentials otherwise
+func (s *WebhookService) NormalizeURL(endpointURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpointURL))
+	if err != nil || parsed.Hostname() == "" {
+		return "", fmt.Errorf("%w: not a URL, like https://cms.example.com/hooks/sosyal", platformapi.ErrInvalidCredentials)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "https" && !(scheme == "http" && s.allowPrivate) {
+		return "", fmt.Errorf("%w: endpoints must be reached over https", platformapi.ErrInvalidCredentials)
+	}
+	parsed.Scheme = scheme
+	parsed.Fragment = ""
+
+	host := strings.ToLower(parsed.Hostname())
+	if len(s.allowedHosts) > 0 && !s.allowedHosts[host] {
+		return "", fmt.Errorf("%w: %s is not one of the allowed hosts", platformapi.ErrInvalidCredentials, host)
+	}
+	if !s.allowPrivate && !s.allowedHosts[host] {
+		if err := ValidateMediaURL(parsed.String()); err != nil {
+			return "", fmt.Errorf("%w: %v", platformapi.ErrInvalidCredentials, err)
+		}
+	}
+	return parsed.String(), nil
+}
+
+// CheckWebhookSecret checks that a secret is long enough to sign deliveries with
+func CheckWebhookSecret(secret string) error {
+	if utf8.RuneCountInString(secret) < webhookMinSecretLength {
+		return fmt.Errorf("%w: the secret must be at least %d characters", platformapi.ErrInvalidCredentials, webhookMinSecretLength)
+	}
+	return nil
+}
+
+// Ping sends a ping to an endpoint, which must answer with a 2xx status
+// An endpoint that rejects the signature or doesn't exist gets an error wrapping
+// platformapi.ErrInvalidCredentials
+func (s *WebhookService) Ping(ctx context.Context, credential WebhookCredential) error {
+	deliveryID, err := newPingID()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.deliver(ctx, credential, WebhookDelivery{Event: WebhookEventPing, DeliveryID: deliveryID})
+	if err == nil {
+		return nil
+	}
+
+	var platformErr *platformapi.PlatformError
+	if errors.As(err, &platformErr) && !platformErr.Retryable() {
+		return fmt.Errorf("%w: %w", platformapi.ErrInvalidCredentials, err)
+	}
+	return fmt.Errorf("failed to ping endpoint: %w", err)
+}
+
+// Publish sends a post to an endpoint
+// Every attempt for the same post has the same delivery ID; endpoints should answer a repeat with
+// the result of the first delivery instead of publishing the post again
+func (s *WebhookService) Publish(ctx context.Context, credential WebhookCredential, post WebhookPost) (*WebhookResult, error) {
+	if post.MediaURLs == nil {
+		post.MediaURLs = []string{}
+	}
+
+	result, err := s.deliver(ctx, credential, WebhookDelivery{
+		Event:      WebhookEventPublish,
+		DeliveryID: fmt.Sprintf("post-%d", post.ID),
+		Post:       &post,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to deliver post: %w", err)
+	}
+
+	log.Printf("Post %d delivered to webhook on %s (ID: %s)", post.ID, webhookHost(credential.URL), result.ID)
+	return result, nil
+}
+
+// deliver signs a delivery and POSTs it to the endpoint
+// The endpoint is checked again first, as its host may resolve elsewhere by now
+func (s *WebhookService) deliver(ctx context.Context, credential WebhookCredential, delivery WebhookDelivery) (*WebhookResult, error) {
+	endpointURL, err := s.NormalizeURL(credential.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delivery: %w", err)
+	}
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpointURL, bytes.NewReader(body))
+	if err != nil {
+		// The error would include the URL, which may contain a token
+		return nil, fmt.Errorf("failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(credential.Secret, timestamp, body))
+
+	// Endpoints can drop repeats by delivery ID, so deliveries are retried like idempotent requests
+	resp, err := s.httpClient.Do(httpclient.MarkIdempotent(req))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, webhookError(resp, respBody)
+	}
+
+	result := &WebhookResult{}
+	if len(bytes.TrimSpace(respBody)) > 0 && json.Unmarshal(respBody, result) != nil {
+		// Endpoints don't have to answer with JSON; the post was still published
+		log.Printf("Webhook on %s answered %s with a body that is not JSON", webhookHost(endpointURL), delivery.Event)
+		result = &WebhookResult{}
+	}
+	if result.URL != "" && !isWebURL(result.URL) {
+		result.URL = ""
+	}
+	return result, nil
+}
+
+// SignWebhook returns the signature header of a delivery: the HMAC-SHA256 of the timestamp and
+// the body, joined by a dot, keyed with the secret
+// Endpoints should compare it in constant time and reject old timestamps to stop replays
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newPingID generates the delivery ID of a ping
+func newPingID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate delivery ID: %w", err)
+	}
+	return "ping-" + hex.EncodeToString(b), nil
+}
+
+// webhookHost returns the host of an endpoint, which is all of its URL that is logged
+func webhookHost(endpointURL string) string {
+	parsed, err := url.Parse(endpointURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// isWebURL reports whether value is an http or https URL
+func isWebURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestWebhookDialRejectsPrivateAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	// Hosts that passed NormalizeURL may resolve to a private address by the time they're dialed
+	public := NewWebhookService(nil, false)
+	for _, host := range []string{"127.0.0.1", "localhost", "0.0.0.0", "169.254.169.254"} {
+		conn, err := public.dialContext(context.Background(), "tcp", net.JoinHostPort(host, port))
+		if err == nil {
+			conn.Close()
+			t.Errorf("dialContext(%s) connected, want it rejected", host)
+		}
+	}
+
+	allowed := NewWebhookService([]string{"localhost"}, false)
+	conn, err := allowed.dialContext(context.Background(), "tcp", net.JoinHostPort("localhost", port))
+	if err != nil {
+		t.Fatalf("dialContext(localhost) for an allowed host error = %v", err)
+	}
+	conn.Close()
+}
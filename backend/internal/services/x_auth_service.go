@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -18,18 +19,20 @@ type XAuthService struct {
 	clientID     string
 	clientSecret string
 	redirectURI  string
+	authBaseURL  string // Authorization page, e.g. https://twitter.com
+	apiBaseURL   string // OAuth token and users API, e.g. https://api.twitter.com
 	httpClient   *http.Client
 }
 
 // NewXAuthService creates a new X authentication service
-func NewXAuthService(clientID, clientSecret, redirectURI string) *XAuthService {
+func NewXAuthService(clientID, clientSecret, redirectURI, authBaseURL, apiBaseURL string) *XAuthService {
 	return &XAuthService{
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		redirectURI:  redirectURI,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		authBaseURL:  authBaseURL,
+		apiBaseURL:   apiBaseURL,
+		httpClient:   newHTTPClient("x", 30*time.Second),
 	}
 }
 
@@ -106,13 +109,13 @@ func (s *XAuthService) GenerateAuthURL() (authURL, state, codeVerifier string, e
 	params.Add("code_challenge", codeChallenge)
 	params.Add("code_challenge_method", "S256")
 
-	authURL = "https://twitter.com/i/oauth2/authorize?" + params.Encode()
+	authURL = s.authBaseURL + "/i/oauth2/authorize?" + params.Encode()
 
 	return authURL, state, codeVerifier, nil
 }
 
 // ExchangeCodeForToken exchanges authorization code for access token using PKCE
-func (s *XAuthService) ExchangeCodeForToken(code, codeVerifier string) (*XTokenResponse, error) {
+func (s *XAuthService) ExchangeCodeForToken(ctx context.Context, code, codeVerifier string) (*XTokenResponse, error) {
 	// Prepare form data
 	formData := url.Values{}
 	formData.Set("code", code)
@@ -122,7 +125,7 @@ func (s *XAuthService) ExchangeCodeForToken(code, codeVerifier string) (*XTokenR
 	formData.Set("code_verifier", codeVerifier)
 
 	// Create request
-	req, err := http.NewRequest("POST", "https://api.twitter.com/2/oauth2/token",
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBaseURL+"/2/oauth2/token",
 		strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -145,7 +148,7 @@ func (s *XAuthService) ExchangeCodeForToken(code, codeVerifier string) (*XTokenR
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("X API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, xError(resp, body)
 	}
 
 	// Parse response
@@ -158,7 +161,7 @@ func (s *XAuthService) ExchangeCodeForToken(code, codeVerifier string) (*XTokenR
 }
 
 // RefreshAccessToken refreshes an expired access token
-func (s *XAuthService) RefreshAccessToken(refreshToken string) (*XTokenResponse, error) {
+func (s *XAuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*XTokenResponse, error) {
 	// Prepare form data
 	formData := url.Values{}
 	formData.Set("refresh_token", refreshToken)
@@ -166,7 +169,7 @@ func (s *XAuthService) RefreshAccessToken(refreshToken string) (*XTokenResponse,
 	formData.Set("client_id", s.clientID)
 
 	// Create request
-	req, err := http.NewRequest("POST", "https://api.twitter.com/2/oauth2/token",
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBaseURL+"/2/oauth2/token",
 		strings.NewReader(formData.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -189,7 +192,7 @@ func (s *XAuthService) RefreshAccessToken(refreshToken string) (*XTokenResponse,
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("X API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, xError(resp, body)
 	}
 
 	// Parse response
@@ -202,9 +205,9 @@ func (s *XAuthService) RefreshAccessToken(refreshToken string) (*XTokenResponse,
 }
 
 // GetUserInfo fetches user information from X API
-func (s *XAuthService) GetUserInfo(accessToken string) (*XUserInfo, error) {
+func (s *XAuthService) GetUserInfo(ctx context.Context, accessToken string) (*XUserInfo, error) {
 	// Create request
-	req, err := http.NewRequest("GET", "https://api.twitter.com/2/users/me", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", s.apiBaseURL+"/2/users/me", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -227,7 +230,7 @@ func (s *XAuthService) GetUserInfo(accessToken string) (*XUserInfo, error) {
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("X API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, xError(resp, body)
 	}
 
 	// Parse response
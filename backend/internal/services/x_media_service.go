@@ -1,34 +1,58 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/httpclient"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
 )
 
 const (
-	xMediaUploadURL = "https://api.x.com/2/media/upload"
-	xChunkSize      = 512 * 1024 // 512KB chunks (X API limit)
+	xMediaUploadPath    = "/2/media/upload"
+	xChunkSize          = 512 * 1024 // 512KB chunks (X API limit)
+	xAppendParallelism  = 4          // Segments uploaded concurrently
+	xAppendRetries      = 3          // Attempts per segment before giving up
+	xMaxResumeAttempts  = 3          // Times the source is reopened after a read failure
+	xDownloadHeaderWait = 30 * time.Second
 )
 
 // XMediaService handles media uploads to X (Twitter)
 type XMediaService struct {
-	httpClient *http.Client
+	uploadURL      string
+	httpClient     *http.Client
+	downloadClient *http.Client // No overall timeout: the source is read for as long as the upload runs
 }
 
-// NewXMediaService creates a new X media service
-func NewXMediaService() *XMediaService {
+// NewXMediaService creates a new X media service that uploads to uploadBaseURL, e.g. https://api.x.com
+func NewXMediaService(uploadBaseURL string) *XMediaService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = xDownloadHeaderWait
+
 	return &XMediaService{
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Longer timeout for uploads
-		},
+		uploadURL:  uploadBaseURL + xMediaUploadPath,
+		httpClient: newHTTPClient("x", 60*time.Second), // Longer timeout for uploads
+		downloadClient: httpclient.New(httpclient.Options{
+			Name:         "x-media-download",
+			MaxRetries:   httpMaxRetries,
+			RetryBackoff: httpRetryBackoff,
+			Transport:    transport,
+		}),
 	}
 }
 
@@ -56,6 +80,11 @@ type ProcessingInfo struct {
 	State           string `json:"state"` // pending, in_progress, succeeded, failed
 	CheckAfterSecs  int    `json:"check_after_secs,omitempty"`
 	ProgressPercent int    `json:"progress_percent,omitempty"`
+	Error           *struct {
+		Code    int    `json:"code"`
+		Name    string `json:"name"` // e.g. InvalidMedia
+		Message string `json:"message"`
+	} `json:"error,omitempty"` // Set when the state is failed
 }
 
 // StatusResponse represents the response from checking upload status
@@ -67,6 +96,7 @@ type StatusResponse struct {
 }
 
 // GetMediaType determines media type from file extension
+// Returns an empty string for unknown extensions; the media's bytes have to decide then
 func (s *XMediaService) GetMediaType(filePath string) string {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	typeMap := map[string]string{
@@ -82,10 +112,7 @@ func (s *XMediaService) GetMediaType(filePath string) string {
 		".webm": "video/webm",
 		".ts":   "video/mp2t",
 	}
-	if mediaType, ok := typeMap[ext]; ok {
-		return mediaType
-	}
-	return "video/mp4" // Default
+	return typeMap[ext]
 }
 
 // GetMediaCategory determines X media category from media type
@@ -100,24 +127,18 @@ func (s *XMediaService) GetMediaCategory(mediaType string) string {
 }
 
 // InitUpload initializes a chunked upload
-func (s *XMediaService) InitUpload(accessToken, filePath string) (*InitResponse, error) {
-	fileInfo, err := os.Stat(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
-	}
-
-	mediaType := s.GetMediaType(filePath)
+func (s *XMediaService) InitUpload(ctx context.Context, accessToken, mediaType string, totalBytes int64) (*InitResponse, error) {
 	mediaCategory := s.GetMediaCategory(mediaType)
 
 	requestBody := map[string]interface{}{
 		"media_type":     mediaType,
-		"total_bytes":    fileInfo.Size(),
+		"total_bytes":    totalBytes,
 		"media_category": mediaCategory,
 	}
 
 	body, _ := json.Marshal(requestBody)
 
-	req, err := http.NewRequest("POST", xMediaUploadURL+"/initialize", bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.uploadURL+"/initialize", bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -125,19 +146,19 @@ func (s *XMediaService) InitUpload(accessToken, filePath string) (*InitResponse,
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	fmt.Printf("Initializing upload: %s (%d bytes, %s, %s)\n",
-		filepath.Base(filePath), fileInfo.Size(), mediaType, mediaCategory)
+	log.Printf("Initializing upload: %d bytes, %s, %s", totalBytes, mediaType, mediaCategory)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	observeXRateLimits(ctx, "media_upload", resp.Header)
 
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("X API init error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, xError(resp, respBody)
 	}
 
 	var initResp InitResponse
@@ -145,93 +166,244 @@ func (s *XMediaService) InitUpload(accessToken, filePath string) (*InitResponse,
 		return nil, fmt.Errorf("failed to parse init response: %w", err)
 	}
 
-	fmt.Printf("Upload initialized: media_id=%s\n", initResp.Data.ID)
+	log.Printf("Upload initialized: media_id=%s", initResp.Data.ID)
 	return &initResp, nil
 }
 
-// AppendChunks uploads file in chunks
-// CRITICAL: Read entire file into memory ONCE, then slice for each chunk
-// This is what was broken in the TypeScript implementation before the fix
-func (s *XMediaService) AppendChunks(accessToken, mediaID, filePath string) error {
-	// CRITICAL: Read entire file into buffer ONCE (matching working TypeScript implementation)
-	fileBuffer, err := os.ReadFile(filePath)
+// AppendChunk uploads a single segment of media
+func (s *XMediaService) AppendChunk(ctx context.Context, accessToken, mediaID string, segmentIndex int, chunk []byte) error {
+	// Create multipart form data
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	// Add media chunk
+	part, err := writer.CreateFormFile("media", "blob")
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(chunk); err != nil {
+		return fmt.Errorf("failed to write chunk: %w", err)
 	}
 
-	fileSize := len(fileBuffer)
-	totalChunks := (fileSize + xChunkSize - 1) / xChunkSize
+	// Add segment index
+	if err := writer.WriteField("segment_index", fmt.Sprintf("%d", segmentIndex)); err != nil {
+		return fmt.Errorf("failed to write segment index: %w", err)
+	}
 
-	fmt.Printf("File loaded: %d bytes, uploading %d chunks\n", fileSize, totalChunks)
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close writer: %w", err)
+	}
 
-	for segmentIndex := 0; segmentIndex < totalChunks; segmentIndex++ {
-		start := segmentIndex * xChunkSize
-		end := start + xChunkSize
-		if end > fileSize {
-			end = fileSize
-		}
+	// Upload chunk
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("%s/%s/append", s.uploadURL, mediaID), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
 
-		// CRITICAL: Slice the buffer to get chunk (don't re-read file!)
-		chunk := fileBuffer[start:end]
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-		fmt.Printf("Chunk %d: %d bytes (%d-%d)\n", segmentIndex, len(chunk), start, end)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload chunk %d: %w", segmentIndex, err)
+	}
 
-		// Create multipart form data
-		body := &bytes.Buffer{}
-		writer := multipart.NewWriter(body)
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
 
-		// Add media chunk
-		part, err := writer.CreateFormFile("media", "blob")
-		if err != nil {
-			return fmt.Errorf("failed to create form file: %w", err)
-		}
-		if _, err := part.Write(chunk); err != nil {
-			return fmt.Errorf("failed to write chunk: %w", err)
-		}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("chunk %d upload failed: %w", segmentIndex, xError(resp, respBody))
+	}
 
-		// Add segment index
-		if err := writer.WriteField("segment_index", fmt.Sprintf("%d", segmentIndex)); err != nil {
-			return fmt.Errorf("failed to write segment index: %w", err)
+	return nil
+}
+
+// AppendStream uploads media from src in segments, sending up to xAppendParallelism segments at once
+// If reading the source fails, it is reopened at the first segment X has not acknowledged
+// and the upload resumes from there instead of starting over
+func (s *XMediaService) AppendStream(ctx context.Context, accessToken, mediaID string, src *xUploadSource, progress platformapi.UploadProgressFunc) error {
+	upload := &xChunkedUpload{
+		service:     s,
+		accessToken: accessToken,
+		mediaID:     mediaID,
+		src:         src,
+		totalChunks: int((src.size + xChunkSize - 1) / xChunkSize),
+		progress:    progress,
+	}
+	upload.acked = make([]bool, upload.totalChunks)
+
+	for attempt := 0; ; attempt++ {
+		start := upload.firstUnacked()
+		if start == upload.totalChunks {
+			return nil
 		}
 
-		if err := writer.Close(); err != nil {
-			return fmt.Errorf("failed to close writer: %w", err)
+		err := upload.appendFrom(ctx, start)
+		if err == nil {
+			continue
+		}
+		var appendErr *xAppendError
+		if errors.As(err, &appendErr) || attempt >= xMaxResumeAttempts || ctx.Err() != nil {
+			return err
 		}
 
-		// Upload chunk
-		req, err := http.NewRequest("POST",
-			fmt.Sprintf("%s/%s/append", xMediaUploadURL, mediaID), body)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+		log.Printf("Reading media source failed at segment %d, resuming: %v", upload.firstUnacked(), err)
+	}
+}
+
+// xAppendError is returned when X rejects a segment after all retries
+// Unlike source read errors, these are not resumed
+type xAppendError struct {
+	err error
+}
+
+func (e *xAppendError) Error() string { return e.err.Error() }
+func (e *xAppendError) Unwrap() error { return e.err }
+
+// xChunkedUpload tracks which segments of an upload X has acknowledged
+type xChunkedUpload struct {
+	service     *XMediaService
+	accessToken string
+	mediaID     string
+	src         *xUploadSource
+	totalChunks int
+	progress    platformapi.UploadProgressFunc
+
+	mu         sync.Mutex
+	acked      []bool
+	ackedBytes int64
+}
+
+// firstUnacked returns the lowest segment index X has not acknowledged yet
+func (u *xChunkedUpload) firstUnacked() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for i, ok := range u.acked {
+		if !ok {
+			return i
 		}
+	}
+	return u.totalChunks
+}
 
-		req.Header.Set("Authorization", "Bearer "+accessToken)
-		req.Header.Set("Content-Type", writer.FormDataContentType())
+// isAcked reports whether X has acknowledged a segment
+func (u *xChunkedUpload) isAcked(segmentIndex int) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.acked[segmentIndex]
+}
 
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			return fmt.Errorf("failed to upload chunk %d: %w", segmentIndex, err)
+// ack records an acknowledged segment and reports upload progress
+func (u *xChunkedUpload) ack(segmentIndex, size int) {
+	u.mu.Lock()
+	u.acked[segmentIndex] = true
+	u.ackedBytes += int64(size)
+	percent := int(u.ackedBytes * 100 / u.src.size)
+	u.mu.Unlock()
+
+	if u.progress != nil {
+		u.progress(platformapi.UploadStageUploading, percent)
+	}
+}
+
+// appendFrom streams the source from segment start and uploads every segment not yet acknowledged
+func (u *xChunkedUpload) appendFrom(ctx context.Context, start int) error {
+	body, err := u.src.open(ctx, int64(start)*xChunkSize)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	type segment struct {
+		index int
+		data  []byte
+	}
+	segments := make(chan segment, xAppendParallelism)
+	done := make(chan struct{})
+
+	var (
+		wg        sync.WaitGroup
+		errOnce   sync.Once
+		uploadErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			uploadErr = err
+			close(done)
+		})
+	}
+
+	for w := 0; w < xAppendParallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seg := range segments {
+				if err := u.appendWithRetry(ctx, seg.index, seg.data); err != nil {
+					fail(&xAppendError{err: err})
+					return
+				}
+				u.ack(seg.index, len(seg.data))
+			}
+		}()
+	}
+
+	// Read the source sequentially; only a bounded number of segments is held in memory
+	var readErr error
+read:
+	for index := start; index < u.totalChunks; index++ {
+		size := xChunkSize
+		if remaining := u.src.size - int64(index)*xChunkSize; remaining < int64(size) {
+			size = int(remaining)
 		}
 
-		respBody, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(body, chunk); err != nil {
+			readErr = fmt.Errorf("failed to read media segment %d: %w", index, err)
+			break
+		}
+		if u.isAcked(index) {
+			continue
+		}
 
-		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-			return fmt.Errorf("chunk %d upload failed (status %d): %s",
-				segmentIndex, resp.StatusCode, string(respBody))
+		select {
+		case segments <- segment{index: index, data: chunk}:
+		case <-done:
+			break read
 		}
+	}
+	close(segments)
+	wg.Wait()
 
-		fmt.Printf("Uploaded chunk %d/%d (%.0f%%)\n",
-			segmentIndex+1, totalChunks, float64(end)/float64(fileSize)*100)
+	if uploadErr != nil {
+		return uploadErr
 	}
+	return readErr
+}
 
-	return nil
+// appendWithRetry uploads a segment, retrying transient failures
+func (u *xChunkedUpload) appendWithRetry(ctx context.Context, segmentIndex int, chunk []byte) error {
+	var err error
+	for attempt := 1; attempt <= xAppendRetries; attempt++ {
+		if err = u.service.AppendChunk(ctx, u.accessToken, u.mediaID, segmentIndex, chunk); err == nil {
+			return nil
+		}
+		// Only rate limits, outages and network errors can go away by trying again
+		if !platformapi.ClassifyError(models.PlatformX, err).Retryable() {
+			return err
+		}
+		log.Printf("Chunk %d attempt %d failed: %v", segmentIndex, attempt, err)
+		if sleepErr := sleepContext(ctx, time.Duration(attempt)*time.Second); sleepErr != nil {
+			return err
+		}
+	}
+	return err
 }
 
 // FinalizeUpload finalizes the upload
-func (s *XMediaService) FinalizeUpload(accessToken, mediaID string) (*FinalizeResponse, error) {
-	req, err := http.NewRequest("POST",
-		fmt.Sprintf("%s/%s/finalize", xMediaUploadURL, mediaID), nil)
+func (s *XMediaService) FinalizeUpload(ctx context.Context, accessToken, mediaID string) (*FinalizeResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("%s/%s/finalize", s.uploadURL, mediaID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -248,7 +420,7 @@ func (s *XMediaService) FinalizeUpload(accessToken, mediaID string) (*FinalizeRe
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("finalize error (status %d): %s", resp.StatusCode, string(body))
+		return nil, xError(resp, body)
 	}
 
 	var finalizeResp FinalizeResponse
@@ -256,15 +428,15 @@ func (s *XMediaService) FinalizeUpload(accessToken, mediaID string) (*FinalizeRe
 		return nil, fmt.Errorf("failed to parse finalize response: %w", err)
 	}
 
-	fmt.Printf("Upload finalized: media_id=%s, size=%d\n",
+	log.Printf("Upload finalized: media_id=%s, size=%d",
 		finalizeResp.Data.ID, finalizeResp.Data.Size)
 
 	return &finalizeResp, nil
 }
 
 // CheckStatus checks the processing status of uploaded media
-func (s *XMediaService) CheckStatus(accessToken, mediaID string) (*StatusResponse, error) {
-	req, err := http.NewRequest("GET", xMediaUploadURL, nil)
+func (s *XMediaService) CheckStatus(ctx context.Context, accessToken, mediaID string) (*StatusResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.uploadURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -286,7 +458,7 @@ func (s *XMediaService) CheckStatus(accessToken, mediaID string) (*StatusRespons
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status check error (status %d): %s", resp.StatusCode, string(body))
+		return nil, xError(resp, body)
 	}
 
 	var statusResp StatusResponse
@@ -298,36 +470,48 @@ func (s *XMediaService) CheckStatus(accessToken, mediaID string) (*StatusRespons
 }
 
 // WaitForProcessing polls until video processing completes
-func (s *XMediaService) WaitForProcessing(accessToken, mediaID string, maxWaitSec int) error {
+func (s *XMediaService) WaitForProcessing(ctx context.Context, accessToken, mediaID string, maxWaitSec int, progress platformapi.UploadProgressFunc) error {
 	startTime := time.Now()
 
 	for {
-		statusResp, err := s.CheckStatus(accessToken, mediaID)
+		statusResp, err := s.CheckStatus(ctx, accessToken, mediaID)
 		if err != nil {
 			return err
 		}
 
 		if statusResp.Data.ProcessingInfo == nil {
 			// No processing needed
-			fmt.Println("No processing required")
+			log.Printf("Media %s needs no processing", mediaID)
 			return nil
 		}
 
 		state := statusResp.Data.ProcessingInfo.State
 
 		if state == "succeeded" {
-			fmt.Println("Media processing completed")
+			log.Printf("Media %s processing completed", mediaID)
+			if progress != nil {
+				progress(platformapi.UploadStageProcessing, 100)
+			}
 			return nil
 		}
 
 		if state == "failed" {
-			return fmt.Errorf("media processing failed")
+			name, message := "", "media processing failed"
+			if info := statusResp.Data.ProcessingInfo.Error; info != nil {
+				name, message = info.Name, info.Message
+			}
+			return platformapi.NewPlatformError(models.PlatformX, platformapi.ErrorCodeMediaRejected, 0, name, message)
+		}
+
+		if progress != nil {
+			progress(platformapi.UploadStageProcessing, statusResp.Data.ProcessingInfo.ProgressPercent)
 		}
 
 		// Check timeout
 		elapsed := time.Since(startTime).Seconds()
 		if elapsed > float64(maxWaitSec) {
-			return fmt.Errorf("media processing timeout after %d seconds", maxWaitSec)
+			return platformapi.NewPlatformError(models.PlatformX, platformapi.ErrorCodeTransient, 0, "",
+				fmt.Sprintf("media processing timeout after %d seconds", maxWaitSec))
 		}
 
 		// Wait before next check
@@ -336,46 +520,36 @@ func (s *XMediaService) WaitForProcessing(accessToken, mediaID string, maxWaitSe
 			waitSec = 5
 		}
 
-		fmt.Printf("Processing %s (%d%%), checking again in %ds...\n",
+		log.Printf("Processing %s (%d%%), checking again in %ds...",
 			state, statusResp.Data.ProcessingInfo.ProgressPercent, waitSec)
 
-		time.Sleep(time.Duration(waitSec) * time.Second)
+		if err := sleepContext(ctx, time.Duration(waitSec)*time.Second); err != nil {
+			return fmt.Errorf("stopped waiting for media processing: %w", err)
+		}
 	}
 }
 
 // UploadFromURL downloads media from URL and uploads it to X
-// This is the complete upload flow: download → init → append → finalize → wait
-func (s *XMediaService) UploadFromURL(accessToken, mediaURL string) (string, error) {
-	// Create temp file
-	tempFile := fmt.Sprintf("/tmp/x-media-%d.tmp", time.Now().UnixNano())
-	defer os.Remove(tempFile)
-
-	// Download file
-	fmt.Printf("Downloading media from: %s\n", mediaURL)
-	resp, err := http.Get(mediaURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download media: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download media: status %d", resp.StatusCode)
-	}
+func (s *XMediaService) UploadFromURL(ctx context.Context, accessToken, mediaURL string) (string, error) {
+	return s.UploadFromURLWithProgress(ctx, accessToken, mediaURL, nil)
+}
 
-	out, err := os.Create(tempFile)
+// UploadFromURLWithProgress streams media from URL to X and reports progress
+// This is the complete upload flow: open source → init → append → finalize → wait
+func (s *XMediaService) UploadFromURLWithProgress(ctx context.Context, accessToken, mediaURL string, progress platformapi.UploadProgressFunc) (string, error) {
+	log.Printf("Opening media source: %s", mediaURL)
+	src, err := s.openUploadSource(ctx, mediaURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer out.Close()
-
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return "", fmt.Errorf("failed to save media: %w", err)
+		return "", err
 	}
+	defer src.Close()
+	return s.uploadSource(ctx, accessToken, src, progress)
+}
 
-	fmt.Println("Media downloaded successfully")
-
+// uploadSource uploads an opened media source to X: init → append → finalize → wait
+func (s *XMediaService) uploadSource(ctx context.Context, accessToken string, src *xUploadSource, progress platformapi.UploadProgressFunc) (string, error) {
 	// Initialize upload
-	initResp, err := s.InitUpload(accessToken, tempFile)
+	initResp, err := s.InitUpload(ctx, accessToken, src.mediaType, src.size)
 	if err != nil {
 		return "", err
 	}
@@ -383,42 +557,235 @@ func (s *XMediaService) UploadFromURL(accessToken, mediaURL string) (string, err
 	mediaID := initResp.Data.ID
 
 	// Upload chunks
-	if err := s.AppendChunks(accessToken, mediaID, tempFile); err != nil {
+	if err := s.AppendStream(ctx, accessToken, mediaID, src, progress); err != nil {
 		return "", err
 	}
 
 	// Finalize upload
-	finalizeResp, err := s.FinalizeUpload(accessToken, mediaID)
+	finalizeResp, err := s.FinalizeUpload(ctx, accessToken, mediaID)
 	if err != nil {
 		return "", err
 	}
 
 	// Wait for processing if needed
 	if finalizeResp.Data.ProcessingInfo != nil {
-		if err := s.WaitForProcessing(accessToken, mediaID, 300); err != nil {
+		if err := s.WaitForProcessing(ctx, accessToken, mediaID, 300, progress); err != nil {
 			return "", err
 		}
 	}
 
-	fmt.Printf("Media uploaded successfully: %s\n", mediaID)
+	log.Printf("Media uploaded successfully: %s", mediaID)
 	return mediaID, nil
 }
 
+// xUploadSource is the media being uploaded to X
+// Normally it is read straight from the source URL; servers that do not send a
+// Content-Length are spooled to a temp file first because X needs the total size up front
+// The media type is sniffed from the leading bytes rather than taken from the URL
+type xUploadSource struct {
+	client    *http.Client
+	url       string
+	size      int64
+	mediaType string
+	tempFile  string
+	first     io.ReadCloser // Body of the initial request, used for the first read from offset 0
+}
+
+// openUploadSource starts downloading mediaURL and determines its size and media type
+func (s *XMediaService) openUploadSource(ctx context.Context, mediaURL string) (*xUploadSource, error) {
+	resp, err := getWithContext(ctx, s.downloadClient, mediaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download media: status %d", resp.StatusCode)
+	}
+
+	src := &xUploadSource{
+		client: s.downloadClient,
+		url:    mediaURL,
+		size:   resp.ContentLength,
+	}
+	contentType := resp.Header.Get("Content-Type")
+
+	var header []byte
+	if src.size >= 0 {
+		// Peek at the leading bytes to sniff the type without consuming them
+		buffered := bufio.NewReaderSize(resp.Body, sniffHeaderSize)
+		header, _ = buffered.Peek(sniffHeaderSize)
+		src.first = struct {
+			io.Reader
+			io.Closer
+		}{buffered, resp.Body}
+	} else {
+		// Size unknown: spool to disk so we can tell X the total size
+		// The type isn't known until then either, so up to the most X accepts for any type
+		limit := max(MaxMediaFileSize(models.PlatformX, MediaTypeVideo), MaxMediaFileSize(models.PlatformX, MediaTypeImage))
+		if err := src.spool(resp.Body, limit); err != nil {
+			resp.Body.Close()
+			src.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+		if header, err = src.readHeader(); err != nil {
+			src.Close()
+			return nil, err
+		}
+	}
+
+	// Trust the bytes first, then the Content-Type header, then the URL's extension
+	src.mediaType = DetectMediaMIMEType(header, contentType)
+	if src.mediaType == "" {
+		src.mediaType = s.GetMediaType(mediaURLPath(mediaURL))
+	}
+	if src.mediaType == "" {
+		src.Close()
+		return nil, fmt.Errorf("failed to detect media type: the file is neither a known image nor a known video")
+	}
+
+	// Reject oversized media before INIT so no upload quota is spent on it
+	if limit := MaxMediaFileSize(models.PlatformX, MediaTypeFromMIME(src.mediaType)); limit > 0 && src.size > limit {
+		src.Close()
+		return nil, fmt.Errorf("media is %.1f MB, X allows at most %.1f MB for %s", megabytes(src.size), megabytes(limit), src.mediaType)
+	}
+
+	return src, nil
+}
+
+// spool copies body to a temp file and records its size
+// Stops as soon as body is larger than limit bytes, unless limit is 0
+func (src *xUploadSource) spool(body io.Reader, limit int64) error {
+	out, err := os.CreateTemp("", "x-media-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer out.Close()
+	src.tempFile = out.Name()
+
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+	size, err := io.Copy(out, body)
+	if err != nil {
+		return fmt.Errorf("failed to save media: %w", err)
+	}
+	if limit > 0 && size > limit {
+		return fmt.Errorf("media is larger than the %.1f MB X allows", megabytes(limit))
+	}
+	src.size = size
+	return nil
+}
+
+// readHeader returns the leading bytes of a spooled source
+func (src *xUploadSource) readHeader() ([]byte, error) {
+	f, err := os.Open(src.tempFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, sniffHeaderSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read temp file: %w", err)
+	}
+	return header[:n], nil
+}
+
+// mediaURLPath returns the path of a URL without its query string
+func mediaURLPath(mediaURL string) string {
+	parsed, err := url.Parse(mediaURL)
+	if err != nil {
+		return mediaURL
+	}
+	return parsed.Path
+}
+
+// open returns a reader positioned at offset
+func (src *xUploadSource) open(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	if src.tempFile != "" {
+		f, err := os.Open(src.tempFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open temp file: %w", err)
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to seek temp file: %w", err)
+		}
+		return f, nil
+	}
+
+	if offset == 0 && src.first != nil {
+		body := src.first
+		src.first = nil
+		return body, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", src.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := src.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		return resp.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		// Server ignored the range; skip to the offset ourselves
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to skip to offset %d: %w", offset, err)
+		}
+		return resp.Body, nil
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download media: status %d", resp.StatusCode)
+	}
+}
+
+// Close releases the source's open body and temp file
+func (src *xUploadSource) Close() error {
+	if src.first != nil {
+		src.first.Close()
+		src.first = nil
+	}
+	if src.tempFile != "" {
+		return os.Remove(src.tempFile)
+	}
+	return nil
+}
+
 // UploadMultipleFromURLs downloads and uploads multiple media files to X
 // X allows maximum 4 photos OR 1 video per tweet
-func (s *XMediaService) UploadMultipleFromURLs(accessToken string, mediaURLs []string) ([]string, error) {
+func (s *XMediaService) UploadMultipleFromURLs(ctx context.Context, accessToken string, mediaURLs []string) ([]string, error) {
 	if len(mediaURLs) == 0 {
 		return nil, fmt.Errorf("at least one media URL is required")
 	}
 
-	// Check if any URL is a video - videos can't be mixed with images
+	// Open every source first so their bytes tell whether one is a video, which can't be mixed with images
+	sources := make([]*xUploadSource, 0, len(mediaURLs))
+	defer func() {
+		for _, src := range sources {
+			src.Close()
+		}
+	}()
 	hasVideo := false
-	for _, url := range mediaURLs {
-		lowerURL := strings.ToLower(url)
-		if strings.HasSuffix(lowerURL, ".mp4") || strings.HasSuffix(lowerURL, ".mov") ||
-			strings.HasSuffix(lowerURL, ".webm") || strings.HasSuffix(lowerURL, ".ts") {
+	for i, mediaURL := range mediaURLs {
+		src, err := s.openUploadSource(ctx, mediaURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open media %d: %w", i+1, err)
+		}
+		sources = append(sources, src)
+		if MediaTypeFromMIME(src.mediaType) == MediaTypeVideo {
 			hasVideo = true
-			break
 		}
 	}
 
@@ -432,9 +799,9 @@ func (s *XMediaService) UploadMultipleFromURLs(accessToken string, mediaURLs []s
 
 	// Upload each media item
 	var mediaIDs []string
-	for i, mediaURL := range mediaURLs {
-		fmt.Printf("Uploading media %d/%d: %s\n", i+1, len(mediaURLs), mediaURL)
-		mediaID, err := s.UploadFromURL(accessToken, mediaURL)
+	for i, src := range sources {
+		log.Printf("Uploading media %d/%d: %s", i+1, len(mediaURLs), src.url)
+		mediaID, err := s.uploadSource(ctx, accessToken, src, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to upload media %d: %w", i+1, err)
 		}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestXUploadSourceSpool(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int64
+		limit    int64
+		wantErr  bool
+		wantSize int64
+	}{
+		{name: "empty", size: 0, limit: 10, wantSize: 0},
+		{name: "under the limit", size: 9, limit: 10, wantSize: 9},
+		{name: "at the limit", size: 10, limit: 10, wantSize: 10},
+		{name: "one byte over the limit", size: 11, limit: 10, wantErr: true},
+		{name: "far over the limit", size: 1 << 20, limit: 10, wantErr: true},
+		{name: "no limit", size: 1 << 20, limit: 0, wantSize: 1 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &countingReader{r: io.LimitReader(neverEnding('x'), tt.size)}
+			src := &xUploadSource{}
+			defer src.Close()
+
+			err := src.spool(body, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("spool() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			info, statErr := os.Stat(src.tempFile)
+			if statErr != nil {
+				t.Fatalf("failed to stat temp file: %v", statErr)
+			}
+			if tt.wantErr {
+				// Spooling stops right after the limit instead of reading the whole body
+				if tt.limit > 0 && (body.n > tt.limit+1 || info.Size() > tt.limit+1) {
+					t.Errorf("read %d and spooled %d bytes, want at most %d", body.n, info.Size(), tt.limit+1)
+				}
+				return
+			}
+			if src.size != tt.wantSize || info.Size() != tt.wantSize {
+				t.Errorf("size = %d, spooled %d bytes, want %d", src.size, info.Size(), tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestXGetMediaType(t *testing.T) {
+	s := &XMediaService{}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/media/photo.JPG", want: "image/jpeg"},
+		{path: "/media/clip.mov", want: "video/quicktime"},
+		{path: "/media/a1b2", want: ""},
+		{path: "/media/notes.txt", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := s.GetMediaType(tt.path); got != tt.want {
+			t.Errorf("GetMediaType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// neverEnding is an endless stream of one byte
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	copy(p, bytes.Repeat([]byte{byte(b)}, len(p)))
+	return len(p), nil
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
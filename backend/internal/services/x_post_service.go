@@ -2,24 +2,26 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"time"
 )
 
 // XPostService handles creating posts (tweets) on X (Twitter)
 type XPostService struct {
+	apiBaseURL string // e.g. https://api.twitter.com
 	httpClient *http.Client
 }
 
 // NewXPostService creates a new X post service
-func NewXPostService() *XPostService {
+func NewXPostService(apiBaseURL string) *XPostService {
 	return &XPostService{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		apiBaseURL: apiBaseURL,
+		httpClient: newHTTPClient("x", 30*time.Second),
 	}
 }
 
@@ -38,7 +40,7 @@ type XPostResponse struct {
 }
 
 // CreatePost creates a tweet with optional media
-func (s *XPostService) CreatePost(accessToken, text string, mediaIDs []string) (*XPostResponse, error) {
+func (s *XPostService) CreatePost(ctx context.Context, accessToken, text string, mediaIDs []string) (*XPostResponse, error) {
 	requestBody := map[string]interface{}{
 		"text": text,
 	}
@@ -52,7 +54,7 @@ func (s *XPostService) CreatePost(accessToken, text string, mediaIDs []string) (
 
 	body, _ := json.Marshal(requestBody)
 
-	req, err := http.NewRequest("POST", "https://api.twitter.com/2/tweets", bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBaseURL+"/2/tweets", bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -60,18 +62,19 @@ func (s *XPostService) CreatePost(accessToken, text string, mediaIDs []string) (
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	fmt.Printf("Creating tweet: %s (media: %d files)\n", text[:min(50, len(text))], len(mediaIDs))
+	log.Printf("Creating tweet: %s (media: %d files)", text[:min(50, len(text))], len(mediaIDs))
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	observeXRateLimits(ctx, "tweets", resp.Header)
 
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("X API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, xError(resp, respBody)
 	}
 
 	var postResp XPostResponse
@@ -79,16 +82,16 @@ func (s *XPostService) CreatePost(accessToken, text string, mediaIDs []string) (
 		return nil, fmt.Errorf("failed to parse post response: %w", err)
 	}
 
-	fmt.Printf("Tweet created: %s\n", postResp.Data.ID)
+	log.Printf("Tweet created: %s", postResp.Data.ID)
 	return &postResp, nil
 }
 
 // GetUserTweets retrieves tweets for a user
-func (s *XPostService) GetUserTweets(accessToken, userID string, maxResults int) ([]map[string]interface{}, error) {
-	url := fmt.Sprintf("https://api.twitter.com/2/users/%s/tweets?max_results=%d&tweet.fields=created_at",
-		userID, maxResults)
+func (s *XPostService) GetUserTweets(ctx context.Context, accessToken, userID string, maxResults int) ([]map[string]interface{}, error) {
+	url := fmt.Sprintf("%s/2/users/%s/tweets?max_results=%d&tweet.fields=created_at",
+		s.apiBaseURL, userID, maxResults)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -104,7 +107,7 @@ func (s *XPostService) GetUserTweets(accessToken, userID string, maxResults int)
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("X API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, xError(resp, respBody)
 	}
 
 	var result struct {
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// YouTubeAuthService handles Google OAuth for YouTube and looks up the user's channel
+// Offline access is requested so Google issues a refresh token; access tokens last an hour
+// https://developers.google.com/youtube/v3/guides/auth/server-side-web-apps
+type YouTubeAuthService struct {
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	scopes       []string
+	authBaseURL  string // Authorization page, e.g. https://accounts.google.com
+	tokenBaseURL string // Token exchange and refresh, e.g. https://oauth2.googleapis.com
+	api          *youtubeAPI
+	httpClient   *http.Client
+}
+
+// NewYouTubeAuthService creates a new YouTube auth service
+func NewYouTubeAuthService(clientID, clientSecret, redirectURI string, scopes []string, authBaseURL, tokenBaseURL, apiBaseURL string) *YouTubeAuthService {
+	httpClient := newHTTPClient("youtube", 30*time.Second)
+	return &YouTubeAuthService{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURI:  redirectURI,
+		scopes:       scopes,
+		authBaseURL:  authBaseURL,
+		tokenBaseURL: tokenBaseURL,
+		api:          &youtubeAPI{baseURL: apiBaseURL, httpClient: httpClient},
+		httpClient:   httpClient,
+	}
+}
+
+// YouTubeTokenResponse represents the OAuth token response from Google
+// Refreshing a token returns no refresh token; the original one stays valid
+type YouTubeTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	TokenType    string `json:"token_type"`
+}
+
+// YouTubeChannel is the channel of the authorized user, which videos are uploaded to
+type YouTubeChannel struct {
+	ID        string
+	Title     string
+	CustomURL string // Handle like @name, empty if the channel has none
+	AvatarURL string
+}
+
+// GenerateAuthURL creates the OAuth authorization URL and the state that protects it
+// prompt=consent makes Google issue a refresh token even if the user authorized the app before
+func (s *YouTubeAuthService) GenerateAuthURL() (authURL, state string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	state = base64.RawURLEncoding.EncodeToString(b)
+
+	params := url.Values{}
+	params.Set("client_id", s.clientID)
+	params.Set("redirect_uri", s.redirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(s.scopes, " "))
+	params.Set("access_type", "offline")
+	params.Set("prompt", "consent")
+	params.Set("include_granted_scopes", "true")
+	params.Set("state", state)
+
+	return s.authBaseURL + "/o/oauth2/v2/auth?" + params.Encode(), state, nil
+}
+
+// ExchangeCodeForToken exchanges an authorization code for an access and refresh token
+func (s *YouTubeAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*YouTubeTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "authorization_code")
+	formData.Set("code", code)
+	formData.Set("redirect_uri", s.redirectURI)
+	return s.requestToken(ctx, formData)
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token
+func (s *YouTubeAuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*YouTubeTokenResponse, error) {
+	formData := url.Values{}
+	formData.Set("grant_type", "refresh_token")
+	formData.Set("refresh_token", refreshToken)
+	return s.requestToken(ctx, formData)
+}
+
+// requestToken posts a grant with the client credentials to the token endpoint
+func (s *YouTubeAuthService) requestToken(ctx context.Context, formData url.Values) (*YouTubeTokenResponse, error) {
+	formData.Set("client_id", s.clientID)
+	formData.Set("client_secret", s.clientSecret)
+
+	resp, err := postFormWithContext(ctx, s.httpClient, s.tokenBaseURL+"/token", formData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, youtubeError(resp, body)
+	}
+
+	var tokenResp YouTubeTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+
+	return &tokenResp, nil
+}
+
+// GetChannel retrieves the YouTube channel of the user the token belongs to
+// Google accounts without a channel can't upload and are rejected
+func (s *YouTubeAuthService) GetChannel(ctx context.Context, accessToken string) (*YouTubeChannel, error) {
+	var resp struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title      string `json:"title"`
+				CustomURL  string `json:"customUrl"`
+				Thumbnails map[string]struct {
+					URL string `json:"url"`
+				} `json:"thumbnails"`
+			} `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := s.api.do(ctx, "GET", "/youtube/v3/channels?part=snippet&mine=true", accessToken, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get channel: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return nil, platformapi.NewPlatformError(models.PlatformYouTube, platformapi.ErrorCodeInsufficientScope, 0, "youtubeSignupRequired",
+			"the Google account has no YouTube channel; create one before connecting it")
+	}
+
+	item := resp.Items[0]
+	channel := &YouTubeChannel{
+		ID:        item.ID,
+		Title:     item.Snippet.Title,
+		CustomURL: item.Snippet.CustomURL,
+	}
+	for _, size := range []string{"medium", "default", "high"} {
+		if thumbnail, ok := item.Snippet.Thumbnails[size]; ok && thumbnail.URL != "" {
+			channel.AvatarURL = thumbnail.URL
+			break
+		}
+	}
+	return channel, nil
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osmanmertacar/sosyal/backend/internal/database/models"
+	"github.com/osmanmertacar/sosyal/backend/internal/services/platformapi"
+)
+
+// youtubeMaxUploadResumes is how many times an interrupted upload is resumed before giving up
+const youtubeMaxUploadResumes = 3
+
+// Upload statuses of YouTube videos
+const (
+	YouTubeUploadUploaded  = "uploaded"  // Received, still being processed
+	YouTubeUploadProcessed = "processed" // Processed and available at its privacy status
+	YouTubeUploadFailed    = "failed"
+	YouTubeUploadRejected  = "rejected"
+	YouTubeUploadDeleted   = "deleted"
+)
+
+// YouTubeVideoService uploads videos with the resumable upload protocol of the YouTube Data API
+// and checks how their processing is going
+// https://developers.google.com/youtube/v3/guides/using_resumable_upload_protocol
+type YouTubeVideoService struct {
+	api          *youtubeAPI
+	uploadClient *http.Client // Sends the video chunks, which take longer than API calls
+	videoService *VideoService
+}
+
+// NewYouTubeVideoService creates a new YouTube video service
+// Videos are downloaded and split into chunks by videoService
+func NewYouTubeVideoService(apiBaseURL string, videoService *VideoService) *YouTubeVideoService {
+	return &YouTubeVideoService{
+		api: &youtubeAPI{
+			baseURL:    apiBaseURL,
+			httpClient: newHTTPClient("youtube", 30*time.Second),
+		},
+		uploadClient: newHTTPClient("youtube", 5*time.Minute),
+		videoService: videoService,
+	}
+}
+
+// YouTubeVideoMetadata is the snippet and status a video is uploaded with
+type YouTubeVideoMetadata struct {
+	Title         string
+	Description   string
+	Tags          []string
+	CategoryID    string
+	PrivacyStatus string    // public, unlisted or private
+	MadeForKids   bool      // Declares the video as made for kids, which turns off comments and personalized ads
+	PublishAt     time.Time // When a private video becomes public; zero to keep it at PrivacyStatus
+}
+
+// YouTubeVideoStatus is the upload and processing status of a video
+type YouTubeVideoStatus struct {
+	UploadStatus            string // uploaded, processed, failed, rejected or deleted
+	FailureReason           string // Why the upload failed
+	RejectionReason         string // Why YouTube rejected the video
+	PrivacyStatus           string
+	PublishAt               string
+	ProcessingStatus        string // processing, succeeded, failed or terminated
+	ProcessingFailureReason string
+	ProgressPercent         int // Estimated from the processed parts
+}
+
+// Upload downloads the video at videoURL and uploads it in chunks with the given metadata
+// Returns the ID of the video, which YouTube keeps processing after the upload
+func (s *YouTubeVideoService) Upload(ctx context.Context, accessToken, videoURL string, metadata YouTubeVideoMetadata, progress platformapi.UploadProgressFunc) (string, error) {
+	videoInfo, err := s.videoService.DownloadVideo(ctx, videoURL)
+	if err != nil {
+		return "", err
+	}
+	defer s.videoService.CleanupVideo(videoInfo.Path)
+
+	if err := s.videoService.ValidateVideo(videoInfo); err != nil {
+		return "", platformapi.NewPlatformError(models.PlatformYouTube, platformapi.ErrorCodeMediaRejected, 0, "", err.Error())
+	}
+	data, err := s.videoService.ReadVideoFile(videoInfo.Path)
+	if err != nil {
+		return "", err
+	}
+
+	mimeType := videoInfo.MimeType
+	if !strings.HasPrefix(mimeType, "video/") {
+		mimeType = "video/*"
+	}
+	uploadURL, err := s.startUpload(ctx, accessToken, metadata, int64(len(data)), mimeType)
+	if err != nil {
+		return "", fmt.Errorf("failed to start upload: %w", err)
+	}
+
+	videoID, err := s.uploadChunks(ctx, accessToken, uploadURL, data, progress)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("YouTube video uploaded: %s (%.1f MB)", videoID, megabytes(int64(len(data))))
+	return videoID, nil
+}
+
+// startUpload creates an upload session for a video of size bytes and returns its URL
+func (s *YouTubeVideoService) startUpload(ctx context.Context, accessToken string, metadata YouTubeVideoMetadata, size int64, mimeType string) (string, error) {
+	status := map[string]interface{}{
+		"privacyStatus":           metadata.PrivacyStatus,
+		"selfDeclaredMadeForKids": metadata.MadeForKids,
+	}
+	if !metadata.PublishAt.IsZero() {
+		status["publishAt"] = metadata.PublishAt.UTC().Format(time.RFC3339)
+	}
+	snippet := map[string]interface{}{
+		"title":       metadata.Title,
+		"description": metadata.Description,
+		"categoryId":  metadata.CategoryID,
+	}
+	if len(metadata.Tags) > 0 {
+		snippet["tags"] = metadata.Tags
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"snippet": snippet, "status": status})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("uploadType", "resumable")
+	params.Set("part", "snippet,status")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.api.baseURL+"/upload/youtube/v3/videos?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("X-Upload-Content-Type", mimeType)
+
+	resp, err := s.api.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", youtubeError(resp, responseBody)
+	}
+
+	uploadURL := resp.Header.Get("Location")
+	if uploadURL == "" {
+		return "", fmt.Errorf("upload session has no upload URL")
+	}
+	return uploadURL, nil
+}
+
+// uploadChunks sends the chunks of a video to an upload session and returns the video's ID
+// YouTube reports how many bytes it kept after every chunk; anything it didn't keep is sent
+// again, and an interrupted upload is resumed from what YouTube has
+func (s *YouTubeVideoService) uploadChunks(ctx context.Context, accessToken, uploadURL string, data []byte, progress platformapi.UploadProgressFunc) (string, error) {
+	total := int64(len(data))
+	resumes := 0
+
+	// Chunks are 5 MB, a multiple of the 256 KB YouTube requires of every chunk but the last
+	var offset int64
+	for _, chunk := range s.videoService.SplitIntoChunks(data) {
+		end := offset + int64(len(chunk))
+		for offset < end {
+			part := chunk[int64(len(chunk))-(end-offset):]
+			videoID, committed, err := s.uploadChunk(ctx, accessToken, uploadURL, part, offset, total)
+			if err != nil {
+				if platformapi.ClassifyError(models.PlatformYouTube, err).Code != platformapi.ErrorCodeTransient || resumes >= youtubeMaxUploadResumes {
+					return "", fmt.Errorf("failed to upload video: %w", err)
+				}
+				resumes++
+				log.Printf("YouTube upload interrupted at %d of %d bytes, resuming: %v", offset, total, err)
+				if videoID, committed, err = s.queryUpload(ctx, accessToken, uploadURL, total); err != nil {
+					return "", fmt.Errorf("failed to resume upload: %w", err)
+				}
+			}
+			if videoID != "" {
+				if progress != nil {
+					progress(platformapi.UploadStageUploading, 100)
+				}
+				return videoID, nil
+			}
+			offset = committed
+		}
+
+		if progress != nil {
+			progress(platformapi.UploadStageUploading, int(offset*100/total))
+		}
+	}
+
+	return "", fmt.Errorf("upload of %d bytes completed without creating a video", total)
+}
+
+// uploadChunk sends the bytes of a video starting at offset
+// Returns the video's ID once the last byte has arrived, and otherwise how many bytes YouTube has
+func (s *YouTubeVideoService) uploadChunk(ctx context.Context, accessToken, uploadURL string, chunk []byte, offset, total int64) (string, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, bytes.NewReader(chunk))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, total))
+	return s.sendUpload(req, accessToken)
+}
+
+// queryUpload asks YouTube how many bytes of an interrupted upload it has
+func (s *YouTubeVideoService) queryUpload(ctx context.Context, accessToken, uploadURL string, total int64) (string, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, http.NoBody)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", total))
+	return s.sendUpload(req, accessToken)
+}
+
+// sendUpload sends a request to an upload session
+// 308 Resume Incomplete reports the received bytes in its Range header; 200 or 201 has the video
+func (s *YouTubeVideoService) sendUpload(req *http.Request, accessToken string) (string, int64, error) {
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.uploadClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusPermanentRedirect:
+		return "", youtubeReceivedBytes(resp.Header.Get("Range")), nil
+	case http.StatusOK, http.StatusCreated:
+		var video struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(body, &video); err != nil || video.ID == "" {
+			return "", 0, fmt.Errorf("failed to parse uploaded video: %s", string(body))
+		}
+		return video.ID, 0, nil
+	}
+	return "", 0, youtubeError(resp, body)
+}
+
+// youtubeReceivedBytes parses the Range header of a 308 response, like bytes=0-524287
+// YouTube leaves it out when it has received nothing
+func youtubeReceivedBytes(rangeHeader string) int64 {
+	_, last, ok := strings.Cut(strings.TrimPrefix(rangeHeader, "bytes="), "-")
+	if !ok {
+		return 0
+	}
+	lastByte, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return lastByte + 1
+}
+
+// GetVideoStatus retrieves the upload and processing status of a video
+// Videos that are no longer listed were deleted and are reported as such
+func (s *YouTubeVideoService) GetVideoStatus(ctx context.Context, accessToken, videoID string) (*YouTubeVideoStatus, error) {
+	var resp struct {
+		Items []struct {
+			Status struct {
+				UploadStatus    string `json:"uploadStatus"`
+				FailureReason   string `json:"failureReason"`
+				RejectionReason string `json:"rejectionReason"`
+				PrivacyStatus   string `json:"privacyStatus"`
+				PublishAt       string `json:"publishAt"`
+			} `json:"status"`
+			ProcessingDetails struct {
+				ProcessingStatus        string `json:"processingStatus"`
+				ProcessingFailureReason string `json:"processingFailureReason"`
+				ProcessingProgress      struct {
+					PartsTotal     json.Number `json:"partsTotal"`
+					PartsProcessed json.Number `json:"partsProcessed"`
+				} `json:"processingProgress"`
+			} `json:"processingDetails"`
+		} `json:"items"`
+	}
+
+	params := url.Values{}
+	params.Set("part", "status,processingDetails")
+	params.Set("id", videoID)
+	if err := s.api.do(ctx, "GET", "/youtube/v3/videos?"+params.Encode(), accessToken, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get video status: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return &YouTubeVideoStatus{UploadStatus: YouTubeUploadDeleted}, nil
+	}
+
+	item := resp.Items[0]
+	status := &YouTubeVideoStatus{
+		UploadStatus:            item.Status.UploadStatus,
+		FailureReason:           item.Status.FailureReason,
+		RejectionReason:         item.Status.RejectionReason,
+		PrivacyStatus:           item.Status.PrivacyStatus,
+		PublishAt:               item.Status.PublishAt,
+		ProcessingStatus:        item.ProcessingDetails.ProcessingStatus,
+		ProcessingFailureReason: item.ProcessingDetails.ProcessingFailureReason,
+	}
+	partsTotal, _ := item.ProcessingDetails.ProcessingProgress.PartsTotal.Int64()
+	partsProcessed, _ := item.ProcessingDetails.ProcessingProgress.PartsProcessed.Int64()
+	if partsTotal > 0 {
+		status.ProgressPercent = int(partsProcessed * 100 / partsTotal)
+	}
+	return status, nil
+}
+
+// YouTubeShortURL returns the URL a Short is watched at
+func YouTubeShortURL(videoID string) string {
+	return "https://www.youtube.com/shorts/" + videoID
+}
@@ -4,7 +4,7 @@ import { useState } from 'react'
 import { HeroIllustration } from './HeroIllustration'
 
 export function HeroSection() {
-  const { isAuthenticated, loginTikTok, loginX, loginInstagram } = useAuth()
+  const { isAuthenticated, loginTikTok, loginX, loginInstagram, loginMock } = useAuth()
   const [showAuthModal, setShowAuthModal] = useState(false)
 
   const handleGetStarted = () => {
@@ -128,6 +128,15 @@ export function HeroSection() {
               </svg>
               Continue with Instagram
             </button>
+
+            {import.meta.env.VITE_MOCK_PLATFORM === 'true' && (
+              <button
+                onClick={loginMock}
+                className="w-full flex items-center justify-center gap-3 px-6 py-4 border-2 border-dashed border-gray-400 text-gray-700 rounded-lg hover:bg-gray-50 transition-all font-medium"
+              >
+                Continue with Mock (sandbox)
+              </button>
+            )}
           </div>
 
           <button
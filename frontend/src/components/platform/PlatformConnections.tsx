@@ -1,4 +1,8 @@
 import { useAuth } from '../../context/AuthContext'
+import { Platform } from '../../types/user'
+
+// The mock platform is only offered when the backend runs with MOCK_PLATFORM_ENABLED
+const mockPlatformEnabled = import.meta.env.VITE_MOCK_PLATFORM === 'true'
 
 const PlatformConnections = () => {
   const {
@@ -6,6 +10,19 @@ const PlatformConnections = () => {
     loginTikTok,
     loginX,
     loginInstagram,
+    loginThreads,
+    loginYouTube,
+    loginFacebook,
+    loginPinterest,
+    loginReddit,
+    loginLinkedIn,
+    loginMastodon,
+    loginBluesky,
+    loginTelegram,
+    connectDiscordWebhook,
+    connectSlackWebhook,
+    loginWebhook,
+    loginMock,
     disconnectPlatform,
     isPlatformConnected,
   } = useAuth()
@@ -47,9 +64,255 @@ const PlatformConnections = () => {
       ),
       loginFn: loginInstagram,
     },
+    {
+      id: 'threads' as const,
+      name: 'Threads',
+      color: 'linear-gradient(135deg, #000000 0%, #434343 100%)',
+      hoverShadow: 'rgba(0, 0, 0, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M12.186 24h-.007c-3.581-.024-6.334-1.205-8.184-3.509C2.35 18.44 1.5 15.586 1.472 12.01v-.017c.03-3.579.879-6.43 2.525-8.482C5.845 1.205 8.6.024 12.18 0h.014c2.746.02 5.043.725 6.826 2.098 1.677 1.29 2.858 3.13 3.509 5.467l-2.04.569c-1.104-3.96-3.898-5.984-8.304-6.015-2.91.022-5.11.936-6.54 2.717C4.307 6.504 3.616 8.914 3.589 12c.027 3.086.718 5.496 2.057 7.164 1.43 1.783 3.631 2.698 6.54 2.717 2.623-.02 4.358-.631 5.8-2.045 1.647-1.613 1.618-3.593 1.09-4.798-.31-.71-.873-1.3-1.634-1.75-.192 1.352-.622 2.446-1.284 3.272-.886 1.102-2.14 1.704-3.73 1.79-1.202.065-2.361-.218-3.259-.801-1.063-.689-1.685-1.74-1.752-2.964-.065-1.19.408-2.285 1.33-3.082.88-.76 2.119-1.207 3.583-1.291a13.853 13.853 0 0 1 3.02.142c-.126-.742-.375-1.332-.75-1.757-.513-.586-1.308-.883-2.359-.89h-.029c-.844 0-1.992.232-2.721 1.32L7.734 7.847c.98-1.454 2.568-2.256 4.478-2.256h.044c3.194.02 5.097 1.975 5.287 5.388.108.046.216.094.321.142 1.49.7 2.58 1.761 3.154 3.07.797 1.82.871 4.79-1.548 7.158-1.85 1.81-4.094 2.628-7.277 2.65Zm1.003-11.69c-.242 0-.487.007-.739.021-1.836.103-2.98.946-2.916 2.143.067 1.256 1.452 1.839 2.784 1.767 1.224-.065 2.818-.543 3.086-3.71a10.5 10.5 0 0 0-2.215-.221z" />
+        </svg>
+      ),
+      loginFn: loginThreads,
+    },
+    {
+      id: 'facebook' as const,
+      name: 'Facebook',
+      color: 'linear-gradient(135deg, #1877F2 0%, #0C5DC7 100%)',
+      hoverShadow: 'rgba(24, 119, 242, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M24 12.073c0-6.627-5.373-12-12-12s-12 5.373-12 12c0 5.99 4.388 10.954 10.125 11.854v-8.385H7.078v-3.47h3.047V9.43c0-3.007 1.792-4.669 4.533-4.669 1.312 0 2.686.235 2.686.235v2.953H15.83c-1.491 0-1.956.925-1.956 1.874v2.25h3.328l-.532 3.47h-2.796v8.385C19.612 23.027 24 18.062 24 12.073z" />
+        </svg>
+      ),
+      loginFn: loginFacebook,
+    },
+    {
+      id: 'pinterest' as const,
+      name: 'Pinterest',
+      color: 'linear-gradient(135deg, #E60023 0%, #AD081B 100%)',
+      hoverShadow: 'rgba(230, 0, 35, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M12 0C5.373 0 0 5.372 0 12c0 5.084 3.163 9.426 7.627 11.174-.105-.949-.2-2.405.042-3.441.218-.937 1.407-5.965 1.407-5.965s-.359-.719-.359-1.782c0-1.668.967-2.914 2.171-2.914 1.023 0 1.518.769 1.518 1.69 0 1.029-.655 2.568-.994 3.995-.283 1.194.599 2.169 1.777 2.169 2.133 0 3.772-2.249 3.772-5.495 0-2.873-2.064-4.882-5.012-4.882-3.414 0-5.418 2.561-5.418 5.207 0 1.031.397 2.138.893 2.738a.36.36 0 01.083.345l-.333 1.36c-.053.22-.174.267-.402.161-1.499-.698-2.436-2.889-2.436-4.649 0-3.785 2.75-7.262 7.929-7.262 4.163 0 7.398 2.967 7.398 6.931 0 4.136-2.607 7.464-6.227 7.464-1.216 0-2.359-.631-2.75-1.378l-.748 2.853c-.271 1.043-1.002 2.35-1.492 3.146C9.57 23.812 10.763 24 12 24c6.627 0 12-5.373 12-12 0-6.628-5.373-12-12-12z" />
+        </svg>
+      ),
+      loginFn: loginPinterest,
+    },
+    {
+      id: 'reddit' as const,
+      name: 'Reddit',
+      color: 'linear-gradient(135deg, #FF4500 0%, #CC3700 100%)',
+      hoverShadow: 'rgba(255, 69, 0, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path fillRule="evenodd" d="M4 14a8 5.5 0 1 0 16 0a8 5.5 0 1 0-16 0zm3.7-.5a1.3 1.3 0 1 0 2.6 0a1.3 1.3 0 1 0-2.6 0zm6 0a1.3 1.3 0 1 0 2.6 0a1.3 1.3 0 1 0-2.6 0z" />
+          <circle cx="4.5" cy="10" r="2" />
+          <circle cx="19.5" cy="10" r="2" />
+          <circle cx="17" cy="4" r="1.8" />
+          <path d="M12 8.5l1.2-5 3.8.9" fill="none" stroke="currentColor" strokeWidth="1" />
+        </svg>
+      ),
+      loginFn: loginReddit,
+    },
+    {
+      id: 'youtube' as const,
+      name: 'YouTube',
+      color: 'linear-gradient(135deg, #FF0000 0%, #B91C1C 100%)',
+      hoverShadow: 'rgba(255, 0, 0, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M23.498 6.186a3.016 3.016 0 00-2.122-2.136C19.505 3.545 12 3.545 12 3.545s-7.505 0-9.377.505A3.017 3.017 0 00.502 6.186C0 8.07 0 12 0 12s0 3.93.502 5.814a3.016 3.016 0 002.122 2.136c1.871.505 9.376.505 9.376.505s7.505 0 9.377-.505a3.015 3.015 0 002.122-2.136C24 15.93 24 12 24 12s0-3.93-.502-5.814zM9.545 15.568V8.432L15.818 12l-6.273 3.568z" />
+        </svg>
+      ),
+      loginFn: loginYouTube,
+    },
+    {
+      id: 'linkedin' as const,
+      name: 'LinkedIn',
+      color: 'linear-gradient(135deg, #0A66C2 0%, #004182 100%)',
+      hoverShadow: 'rgba(10, 102, 194, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M20.447 20.452h-3.554v-5.569c0-1.328-.027-3.037-1.852-3.037-1.853 0-2.136 1.445-2.136 2.939v5.667H9.351V9h3.414v1.561h.046c.477-.9 1.637-1.85 3.37-1.85 3.601 0 4.267 2.37 4.267 5.455v6.286zM5.337 7.433a2.062 2.062 0 01-2.063-2.065 2.064 2.064 0 112.063 2.065zm1.782 13.019H3.555V9h3.564v11.452zM22.225 0H1.771C.792 0 0 .774 0 1.729v20.542C0 23.227.792 24 1.771 24h20.451C23.2 24 24 23.227 24 22.271V1.729C24 .774 23.2 0 22.222 0h.003z" />
+        </svg>
+      ),
+      loginFn: loginLinkedIn,
+    },
+    {
+      id: 'mastodon' as const,
+      name: 'Mastodon',
+      color: 'linear-gradient(135deg, #6364FF 0%, #563ACC 100%)',
+      hoverShadow: 'rgba(99, 100, 255, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M23.268 5.313c-.35-2.578-2.617-4.61-5.304-5.004C17.51.242 15.792 0 11.813 0h-.03c-3.98 0-4.835.242-5.288.309C3.882.692 1.496 2.518.917 5.127.64 6.412.61 7.837.661 9.143c.074 1.874.088 3.745.26 5.611.118 1.24.325 2.47.62 3.68.55 2.237 2.777 4.098 4.96 4.857 2.336.792 4.849.923 7.256.38.265-.061.527-.132.786-.213.585-.184 1.27-.39 1.774-.753a.057.057 0 0 0 .023-.043v-1.809a.052.052 0 0 0-.02-.041.053.053 0 0 0-.046-.01 20.282 20.282 0 0 1-4.709.545c-2.73 0-3.463-1.284-3.674-1.818a5.593 5.593 0 0 1-.319-1.433.053.053 0 0 1 .066-.054c1.517.363 3.072.546 4.632.546.376 0 .75 0 1.125-.01 1.57-.044 3.224-.124 4.768-.422.038-.008.077-.015.11-.024 2.435-.464 4.753-1.92 4.989-5.604.008-.145.03-1.52.03-1.67.002-.512.167-3.63-.024-5.545zm-3.748 9.195h-2.561V8.29c0-1.309-.55-1.976-1.67-1.976-1.23 0-1.846.79-1.846 2.35v3.403h-2.546V8.663c0-1.56-.617-2.35-1.848-2.35-1.112 0-1.668.668-1.67 1.977v6.218H4.822V8.102c0-1.31.337-2.35 1.011-3.12.696-.77 1.608-1.164 2.74-1.164 1.311 0 2.302.5 2.962 1.498l.638 1.06.638-1.06c.66-.999 1.65-1.498 2.96-1.498 1.13 0 2.043.395 2.74 1.164.675.77 1.012 1.81 1.012 3.12z" />
+        </svg>
+      ),
+      // Every Mastodon server is separate, so the user picks theirs first
+      loginFn: async () => {
+        const instance = window.prompt('Which Mastodon server is your account on? (for example mastodon.social)')
+        if (!instance || !instance.trim()) {
+          return
+        }
+        try {
+          await loginMastodon(instance.trim())
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect to the Mastodon server. Please try again.')
+        }
+      },
+    },
+    {
+      id: 'bluesky' as const,
+      name: 'Bluesky',
+      color: 'linear-gradient(135deg, #1185FE 0%, #0560C7 100%)',
+      hoverShadow: 'rgba(17, 133, 254, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M12 10.8c-1.087-2.114-4.046-6.053-6.798-7.995C2.566.944 1.561 1.266.902 1.565.139 1.908 0 3.08 0 3.768c0 .69.378 5.65.624 6.479.815 2.736 3.713 3.66 6.383 3.364.136-.02.275-.039.415-.056-.138.022-.276.04-.415.056-3.912.58-7.387 2.005-2.83 7.078 5.013 5.19 6.87-1.113 7.823-4.308.953 3.195 2.05 9.271 7.733 4.308 4.267-4.308 1.172-6.498-2.74-7.078a8.741 8.741 0 0 1-.415-.056c.14.017.279.036.415.056 2.67.297 5.568-.628 6.383-3.364.246-.828.624-5.79.624-6.478 0-.69-.139-1.861-.902-2.206-.659-.298-1.664-.62-4.3 1.24C16.046 4.748 13.087 8.687 12 10.8Z" />
+        </svg>
+      ),
+      // Bluesky accounts are connected with an app password rather than an OAuth redirect
+      loginFn: async () => {
+        const handle = window.prompt('What is your Bluesky handle? (for example alice.bsky.social)')
+        if (!handle || !handle.trim()) {
+          return
+        }
+        const appPassword = window.prompt('Enter an app password, created in Bluesky under Settings > Privacy and security > App passwords')
+        if (!appPassword || !appPassword.trim()) {
+          return
+        }
+        try {
+          await loginBluesky(handle.trim(), appPassword.trim())
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect to Bluesky. Please try again.')
+        }
+      },
+    },
+    {
+      id: 'telegram' as const,
+      name: 'Telegram',
+      color: 'linear-gradient(135deg, #29B6F6 0%, #0088CC 100%)',
+      hoverShadow: 'rgba(0, 136, 204, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M11.944 0A12 12 0 0 0 0 12a12 12 0 0 0 12 12 12 12 0 0 0 12-12A12 12 0 0 0 12 0a12 12 0 0 0-.056 0zm4.962 7.224c.1-.002.321.023.465.14a.506.506 0 0 1 .171.325c.016.093.036.306.02.472-.18 1.898-.962 6.502-1.36 8.627-.168.9-.499 1.201-.82 1.23-.696.065-1.225-.46-1.9-.902-1.056-.693-1.653-1.124-2.678-1.8-1.185-.78-.417-1.21.258-1.91.177-.184 3.247-2.977 3.307-3.23.007-.032.014-.15-.056-.212s-.174-.041-.249-.024c-.106.024-1.793 1.14-5.061 3.345-.48.33-.913.49-1.302.48-.428-.008-1.252-.241-1.865-.44-.752-.245-1.349-.374-1.297-.789.027-.216.325-.437.893-.663 3.498-1.524 5.83-2.529 6.998-3.014 3.332-1.386 4.025-1.627 4.476-1.635z" />
+        </svg>
+      ),
+      // Telegram channels and groups are connected with a bot that was added to them
+      loginFn: async () => {
+        const chat = window.prompt('Which channel or group should be posted to? (its @username, t.me link or ID)')
+        if (!chat || !chat.trim()) {
+          return
+        }
+        const botToken = window.prompt('Enter the token of a bot that may post there, created with @BotFather')
+        if (!botToken || !botToken.trim()) {
+          return
+        }
+        try {
+          await loginTelegram(chat.trim(), botToken.trim())
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect to Telegram. Please try again.')
+        }
+      },
+    },
+    {
+      id: 'discord' as const,
+      name: 'Discord',
+      color: 'linear-gradient(135deg, #5865F2 0%, #404EED 100%)',
+      hoverShadow: 'rgba(88, 101, 242, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M20.317 4.37a19.791 19.791 0 0 0-4.885-1.515.074.074 0 0 0-.079.037c-.21.375-.444.864-.608 1.25a18.27 18.27 0 0 0-5.487 0 12.64 12.64 0 0 0-.617-1.25.077.077 0 0 0-.079-.037A19.736 19.736 0 0 0 3.677 4.37a.07.07 0 0 0-.032.027C.533 9.046-.32 13.58.099 18.057a.082.082 0 0 0 .031.057 19.9 19.9 0 0 0 5.993 3.03.078.078 0 0 0 .084-.028 14.09 14.09 0 0 0 1.226-1.994.076.076 0 0 0-.041-.106 13.107 13.107 0 0 1-1.872-.892.077.077 0 0 1-.008-.128 10.2 10.2 0 0 0 .372-.292.074.074 0 0 1 .077-.01c3.928 1.793 8.18 1.793 12.062 0a.074.074 0 0 1 .078.01c.12.098.246.198.373.292a.077.077 0 0 1-.006.127 12.299 12.299 0 0 1-1.873.892.077.077 0 0 0-.041.107c.36.698.772 1.362 1.225 1.993a.076.076 0 0 0 .084.028 19.839 19.839 0 0 0 6.002-3.03.077.077 0 0 0 .032-.054c.5-5.177-.838-9.674-3.549-13.66a.061.061 0 0 0-.031-.03zM8.02 15.33c-1.183 0-2.157-1.085-2.157-2.419 0-1.333.956-2.419 2.157-2.419 1.21 0 2.176 1.096 2.157 2.42 0 1.333-.956 2.418-2.157 2.418zm7.975 0c-1.183 0-2.157-1.085-2.157-2.419 0-1.333.955-2.419 2.157-2.419 1.21 0 2.176 1.096 2.157 2.42 0 1.333-.946 2.418-2.157 2.418z" />
+        </svg>
+      ),
+      // Discord channels are connected with a webhook, created under Edit Channel > Integrations
+      loginFn: async () => {
+        const webhookURL = window.prompt('Enter the webhook URL of the channel, created under Edit Channel > Integrations > Webhooks')
+        if (!webhookURL || !webhookURL.trim()) {
+          return
+        }
+        try {
+          await connectDiscordWebhook(webhookURL.trim())
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect to Discord. Please try again.')
+        }
+      },
+    },
+    {
+      id: 'slack' as const,
+      name: 'Slack',
+      color: 'linear-gradient(135deg, #E01E5A 0%, #4A154B 100%)',
+      hoverShadow: 'rgba(74, 21, 75, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="currentColor">
+          <path d="M5.042 15.165a2.528 2.528 0 0 1-2.52 2.523A2.528 2.528 0 0 1 0 15.165a2.527 2.527 0 0 1 2.522-2.52h2.52v2.52zm1.271 0a2.527 2.527 0 0 1 2.521-2.52 2.527 2.527 0 0 1 2.521 2.52v6.313A2.528 2.528 0 0 1 8.834 24a2.528 2.528 0 0 1-2.521-2.522v-6.313zM8.834 5.042a2.528 2.528 0 0 1-2.521-2.52A2.528 2.528 0 0 1 8.834 0a2.528 2.528 0 0 1 2.521 2.522v2.52H8.834zm0 1.271a2.528 2.528 0 0 1 2.521 2.521 2.528 2.528 0 0 1-2.521 2.521H2.522A2.528 2.528 0 0 1 0 8.834a2.528 2.528 0 0 1 2.522-2.521h6.312zm10.122 2.521a2.528 2.528 0 0 1 2.522-2.521A2.528 2.528 0 0 1 24 8.834a2.528 2.528 0 0 1-2.522 2.521h-2.522V8.834zm-1.268 0a2.528 2.528 0 0 1-2.523 2.521 2.527 2.527 0 0 1-2.52-2.521V2.522A2.527 2.527 0 0 1 15.165 0a2.528 2.528 0 0 1 2.523 2.522v6.312zm-2.523 10.122a2.528 2.528 0 0 1 2.523 2.522A2.528 2.528 0 0 1 15.165 24a2.527 2.527 0 0 1-2.52-2.522v-2.522h2.52zm0-1.268a2.527 2.527 0 0 1-2.52-2.523 2.526 2.526 0 0 1 2.52-2.52h6.313A2.527 2.527 0 0 1 24 15.165a2.528 2.528 0 0 1-2.522 2.523h-6.313z" />
+        </svg>
+      ),
+      // Slack channels are connected with an incoming webhook, which can't be looked up, so the
+      // user names the connection
+      loginFn: async () => {
+        const webhookURL = window.prompt('Enter the incoming webhook URL of the channel (https://hooks.slack.com/services/...)')
+        if (!webhookURL || !webhookURL.trim()) {
+          return
+        }
+        const name = window.prompt('What should this connection be called? (for example #announcements)')
+        try {
+          await connectSlackWebhook(webhookURL.trim(), name?.trim() || undefined)
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect to Slack. Please try again.')
+        }
+      },
+    },
+    {
+      id: 'webhook' as const,
+      name: 'Webhook',
+      color: 'linear-gradient(135deg, #0f766e 0%, #134e4a 100%)',
+      hoverShadow: 'rgba(15, 118, 110, 0.4)',
+      icon: (
+        <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2" strokeLinecap="round" strokeLinejoin="round">
+          <path d="M10 13a5 5 0 0 0 7.54.54l3-3a5 5 0 0 0-7.07-7.07l-1.72 1.71" />
+          <path d="M14 11a5 5 0 0 0-7.54-.54l-3 3a5 5 0 0 0 7.07 7.07l1.71-1.71" />
+        </svg>
+      ),
+      // Endpoints of the user's own systems are sent a signed test delivery, which they must accept
+      loginFn: async () => {
+        const endpointURL = window.prompt('Enter the URL of the endpoint posts should be sent to (https://...)')
+        if (!endpointURL || !endpointURL.trim()) {
+          return
+        }
+        const secret = window.prompt('Enter the secret deliveries should be signed with (at least 16 characters)')
+        if (!secret) {
+          return
+        }
+        try {
+          await loginWebhook(endpointURL.trim(), secret)
+        } catch (error: any) {
+          alert(error.response?.data?.error || 'Failed to connect the endpoint. Please try again.')
+        }
+      },
+    },
+    ...(mockPlatformEnabled
+      ? [
+          {
+            id: 'mock' as const,
+            name: 'Mock',
+            color: 'linear-gradient(135deg, #6b7280 0%, #111827 100%)',
+            hoverShadow: 'rgba(107, 114, 128, 0.4)',
+            icon: (
+              <svg width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2">
+                <rect x="3" y="3" width="18" height="18" rx="4" strokeDasharray="4 3" />
+                <path d="M8 12h8M12 8v8" />
+              </svg>
+            ),
+            loginFn: loginMock,
+          },
+        ]
+      : []),
   ]
 
-  const handleDisconnect = async (platformId: 'tiktok' | 'x' | 'instagram') => {
+  const handleDisconnect = async (platformId: Platform) => {
     if (
       window.confirm(
         `Are you sure you want to disconnect your ${platformId.toUpperCase()} account?`
@@ -297,7 +297,7 @@ const PostForm = ({ onPostCreated }: PostFormProps) => {
         platforms: selectedPlatforms,
         media_urls: validUrls,
         caption: caption,
-        tiktok_settings: tiktokSettings,
+        settings: tiktokSettings ? { tiktok: tiktokSettings } : undefined,
       })
 
       // Reset form
@@ -540,6 +540,13 @@ const PostForm = ({ onPostCreated }: PostFormProps) => {
                       </svg>
                     )}
                     {connection.platform === 'youtube' && '▶️'}
+                    {connection.platform === 'facebook' && 'f'}
+                    {connection.platform === 'pinterest' && '📌'}
+                    {connection.platform === 'reddit' && '👽'}
+                    {connection.platform === 'telegram' && '✈️'}
+                    {connection.platform === 'discord' && '🎮'}
+                    {connection.platform === 'slack' && '#'}
+                    {connection.platform === 'webhook' && '🔗'}
                   </div>
                   <div className="text-center">
                     <span className="text-sm font-medium text-gray-700 capitalize block">
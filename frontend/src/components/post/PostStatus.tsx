@@ -19,7 +19,7 @@ const PostStatus = ({ status: initialStatus, postId }: PostStatusProps) => {
           setStatus(data.status)
 
           // Stop polling if post is complete, sent to inbox, or failed
-          if (data.status === 'published' || data.status === 'sent_to_inbox' || data.status === 'failed') {
+          if (data.status === 'published' || data.status === 'sent_to_inbox' || data.status === 'failed' || data.status === 'cancelled') {
             clearInterval(interval)
           }
         } catch (error) {
@@ -43,6 +43,12 @@ const PostStatus = ({ status: initialStatus, postId }: PostStatusProps) => {
         return { label: 'Sent to inbox', color: '#2196f3', icon: '' }
       case 'failed':
         return { label: 'Failed', color: '#f44336', icon: '' }
+      case 'cancelled':
+        return { label: 'Cancelled', color: '#9e9e9e', icon: '' }
+      case 'deferred':
+        return { label: 'Deferred', color: '#ffa500', icon: '' }
+      case 'deleted':
+        return { label: 'Deleted', color: '#9e9e9e', icon: '' }
       default:
         return { label: status, color: '#999', icon: '' }
     }
@@ -10,6 +10,19 @@ interface AuthContextType {
   loginTikTok: () => Promise<void>
   loginX: () => Promise<void>
   loginInstagram: () => Promise<void>
+  loginThreads: () => Promise<void>
+  loginYouTube: () => Promise<void>
+  loginFacebook: () => Promise<void>
+  loginPinterest: () => Promise<void>
+  loginReddit: () => Promise<void>
+  loginLinkedIn: () => Promise<void>
+  loginMastodon: (instance: string) => Promise<void>
+  loginBluesky: (identifier: string, appPassword: string) => Promise<void>
+  loginTelegram: (chat: string, botToken: string) => Promise<void>
+  connectDiscordWebhook: (webhookURL: string, name?: string) => Promise<void>
+  connectSlackWebhook: (webhookURL: string, name?: string) => Promise<void>
+  loginWebhook: (endpointURL: string, secret: string) => Promise<void>
+  loginMock: () => Promise<void>
   logout: () => void
   disconnectPlatform: (platform: Platform) => Promise<void>
   refreshPlatforms: () => Promise<void>
@@ -78,6 +91,62 @@ export const AuthProvider = ({ children }: AuthProviderProps) => {
     await authService.initiateInstagramLogin()
   }
 
+  const loginThreads = async () => {
+    await authService.initiateThreadsLogin()
+  }
+
+  const loginYouTube = async () => {
+    await authService.initiateYouTubeLogin()
+  }
+
+  const loginFacebook = async () => {
+    await authService.initiateFacebookLogin()
+  }
+
+  const loginPinterest = async () => {
+    await authService.initiatePinterestLogin()
+  }
+
+  const loginReddit = async () => {
+    await authService.initiateRedditLogin()
+  }
+
+  const loginLinkedIn = async () => {
+    await authService.initiateLinkedInLogin()
+  }
+
+  const loginMastodon = async (instance: string) => {
+    await authService.initiateMastodonLogin(instance)
+  }
+
+  const loginBluesky = async (identifier: string, appPassword: string) => {
+    await authService.loginBluesky(identifier, appPassword)
+  }
+
+  const loginTelegram = async (chat: string, botToken: string) => {
+    await authService.loginTelegram(chat, botToken)
+    await refreshPlatforms()
+  }
+
+  const connectDiscordWebhook = async (webhookURL: string, name?: string) => {
+    await authService.connectDiscordWebhook(webhookURL, name)
+    await refreshPlatforms()
+  }
+
+  const connectSlackWebhook = async (webhookURL: string, name?: string) => {
+    await authService.connectSlackWebhook(webhookURL, name)
+    await refreshPlatforms()
+  }
+
+  const loginWebhook = async (endpointURL: string, secret: string) => {
+    await authService.loginWebhook(endpointURL, secret)
+    await refreshPlatforms()
+  }
+
+  const loginMock = async () => {
+    await authService.initiateMockLogin()
+  }
+
   const logout = async () => {
     try {
       await authService.logout()
@@ -115,6 +184,19 @@ export const AuthProvider = ({ children }: AuthProviderProps) => {
     loginTikTok,
     loginX,
     loginInstagram,
+    loginThreads,
+    loginYouTube,
+    loginFacebook,
+    loginPinterest,
+    loginReddit,
+    loginLinkedIn,
+    loginMastodon,
+    loginBluesky,
+    loginTelegram,
+    connectDiscordWebhook,
+    connectSlackWebhook,
+    loginWebhook,
+    loginMock,
     logout,
     disconnectPlatform,
     refreshPlatforms,
@@ -46,6 +46,172 @@ export const authService = {
     }
   },
 
+  // Initiate Threads OAuth login
+  initiateThreadsLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/threads/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate YouTube (Google) OAuth login
+  initiateYouTubeLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/youtube/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate Facebook Login, for publishing to Facebook Pages
+  initiateFacebookLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/facebook/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate Pinterest OAuth login
+  initiatePinterestLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/pinterest/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate Reddit OAuth login
+  initiateRedditLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/reddit/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate LinkedIn OAuth login
+  initiateLinkedInLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/linkedin/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate Mastodon OAuth login on the user's instance
+  // instance can be a domain like mastodon.social, an instance URL or a handle
+  initiateMastodonLogin: async (instance: string) => {
+    try {
+      const response = await api.get("/api/v1/auth/mastodon/login", {
+        params: { instance },
+      });
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Connect a Bluesky account with its handle and an app password
+  // There is no redirect, so the session token is handed to the callback page directly
+  loginBluesky: async (identifier: string, appPassword: string) => {
+    try {
+      const response = await api.post("/api/v1/auth/bluesky/login", {
+        identifier,
+        password: appPassword,
+      });
+      if (response.data && response.data.token) {
+        window.location.href =
+          "/callback?token=" + encodeURIComponent(response.data.token);
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Connect a Telegram channel or group with its @username or ID and the token of a bot that
+  // may post to it, to the account of the logged-in user
+  loginTelegram: async (chat: string, botToken: string) => {
+    try {
+      await api.post("/api/v1/auth/telegram/login", {
+        identifier: chat,
+        password: botToken,
+      });
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Connect a Discord channel with one of its incoming webhooks, to the account of the logged-in user
+  connectDiscordWebhook: async (webhookURL: string, name?: string) => {
+    try {
+      await api.post("/api/v1/auth/discord/login", {
+        webhook_url: webhookURL,
+        name,
+      });
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Connect a Slack channel with one of its incoming webhooks, to the account of the logged-in user
+  connectSlackWebhook: async (webhookURL: string, name?: string) => {
+    try {
+      await api.post("/api/v1/auth/slack/login", {
+        webhook_url: webhookURL,
+        name,
+      });
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Connect an endpoint of the user's own systems with its URL and the secret deliveries are
+  // signed with, to the account of the logged-in user
+  loginWebhook: async (endpointURL: string, secret: string) => {
+    try {
+      await api.post("/api/v1/auth/webhook/login", {
+        identifier: endpointURL,
+        password: secret,
+      });
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
+  // Initiate the fake OAuth flow of the mock platform (local development only)
+  initiateMockLogin: async () => {
+    try {
+      const response = await api.get("/api/v1/auth/mock/login");
+      if (response.data && response.data.url) {
+        window.location.href = response.data.url;
+      }
+    } catch (error: any) {
+      throw error;
+    }
+  },
+
   // Generic platform login (backward compatibility)
   initiateLogin: (platform: Platform = "tiktok") => {
     if (platform === "x") {
@@ -28,6 +28,11 @@ export const postService = {
     return response.data
   },
 
+  // Delete a published post from its platform, where the platform allows it
+  deletePost: async (id: number): Promise<void> => {
+    await api.delete(`/api/v1/posts/${id}`)
+  },
+
   // Get TikTok creator info (privacy levels, interaction permissions, video duration limits)
   getTikTokCreatorInfo: async (): Promise<TikTokCreatorInfo> => {
     const response = await api.get('/api/v1/tiktok/creator-info')
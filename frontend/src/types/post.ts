@@ -1,7 +1,7 @@
 import { Platform } from './user'
 
-export type PostStatus = 'pending' | 'processing' | 'published' | 'sent_to_inbox' | 'failed'
-export type MediaType = 'video' | 'image'
+export type PostStatus = 'pending' | 'processing' | 'published' | 'sent_to_inbox' | 'failed' | 'cancelled' | 'deferred' | 'deleted'
+export type MediaType = 'video' | 'image' | 'carousel' | 'text'
 
 // TikTok Privacy Level options
 export type TikTokPrivacyLevel = 'PUBLIC_TO_EVERYONE' | 'MUTUAL_FOLLOW_FRIENDS' | 'FOLLOWER_OF_CREATOR' | 'SELF_ONLY'
@@ -37,16 +37,34 @@ export interface Post {
   tiktok_post_id?: string
   tiktok_url?: string
   error_message?: string
+  error_code?: PostErrorCode
+  error_detail?: string
+  deferred_until?: string // When a deferred post will be published
   created_at: string
   published_at?: string
 }
 
+// Stable classification of why a post failed
+export type PostErrorCode =
+  | 'auth_expired'
+  | 'insufficient_scope'
+  | 'rate_limited'
+  | 'media_rejected'
+  | 'content_policy'
+  | 'duplicate_content'
+  | 'transient'
+  | 'unknown'
+
+// Platform-specific settings keyed by platform (schemas are listed by GET /platforms)
+export interface PostSettings {
+  tiktok?: TikTokSettings
+}
+
 export interface CreatePostRequest {
   platforms: Platform[]
   media_urls: string[]              // Multiple media URLs (for carousel/multi-image)
   caption: string
-  // TikTok-specific settings
-  tiktok_settings?: TikTokSettings
+  settings?: PostSettings
 }
 
 export interface PostsResponse {
@@ -1,4 +1,4 @@
-export type Platform = 'tiktok' | 'x' | 'instagram' | 'youtube'
+export type Platform = 'tiktok' | 'x' | 'instagram' | 'threads' | 'linkedin' | 'mastodon' | 'bluesky' | 'youtube' | 'facebook' | 'pinterest' | 'reddit' | 'telegram' | 'discord' | 'slack' | 'webhook' | 'mock'
 
 export interface PlatformConnection {
   platform: Platform
@@ -8,6 +8,15 @@ export interface PlatformConnection {
   is_active: boolean
   connected_at: string
   last_used_at: string
+  quota: PlatformQuota | null // null when nothing is known about the platform's limits
+}
+
+// Remaining publishing budget of a connected account
+export interface PlatformQuota {
+  limit?: number
+  remaining: number
+  reset_at?: string
+  source: 'platform' | 'cap'
 }
 
 export interface User {
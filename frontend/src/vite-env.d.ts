@@ -4,6 +4,7 @@ interface ImportMetaEnv {
   readonly VITE_API_BASE_URL: string
   readonly VITE_APP_NAME: string
   readonly VITE_ENVIRONMENT: string
+  readonly VITE_MOCK_PLATFORM?: string
 }
 
 interface ImportMeta {